@@ -0,0 +1,124 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// StrictActivation returns an Activation for the bindings after validating them against the
+// variables declared within the environment.
+//
+// Each binding must be named for a declared variable, and its value must be assignable to the
+// declared type of the variable once adapted to a CEL value. Lazy bindings, i.e. `func() any` and
+// `func() ref.Val` values, are only validated by name since their values are not known until
+// evaluation. When validation fails, the error lists every undeclared and mistyped binding along
+// with the most similar declared name for each undeclared binding, if any.
+//
+// Bindings which are absent are permitted, since expressions may not refer to every declared
+// variable.
+func (e *Env) StrictActivation(bindings map[string]any) (interpreter.Activation, error) {
+	declared := map[string]*exprpb.Decl{}
+	for _, d := range e.declarations {
+		if d.GetIdent() != nil {
+			declared[d.GetName()] = d
+		}
+	}
+	names := make([]string, 0, len(bindings))
+	for name := range bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var problems []string
+	for _, name := range names {
+		decl, found := declared[name]
+		if !found {
+			problem := fmt.Sprintf("undeclared variable %q", name)
+			if suggestion := closestName(name, declared); suggestion != "" {
+				problem += fmt.Sprintf(" (did you mean %q?)", suggestion)
+			}
+			problems = append(problems, problem)
+			continue
+		}
+		switch bindings[name].(type) {
+		case func() any, func() ref.Val:
+			continue
+		}
+		t, err := ExprTypeToType(decl.GetIdent().GetType())
+		if err != nil {
+			return nil, err
+		}
+		val := e.adapter.NativeToValue(bindings[name])
+		if types.IsError(val) || !t.IsAssignableRuntimeType(val) {
+			problems = append(problems, fmt.Sprintf("variable %q has type %s, got %T", name, t, bindings[name]))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid bindings: %s", strings.Join(problems, ", "))
+	}
+	return interpreter.NewActivation(bindings)
+}
+
+// closestName returns the declared name with the smallest edit distance to the name, provided the
+// distance is small relative to the length of the name.
+func closestName(name string, declared map[string]*exprpb.Decl) string {
+	best, bestDist := "", len(name)/3+1
+	for candidate := range declared {
+		dist := editDistance(name, candidate)
+		if dist < bestDist || (dist == bestDist && best != "" && candidate < best) {
+			best, bestDist = candidate, dist
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(vals ...int) int {
+	m := vals[0]
+	for _, v := range vals[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
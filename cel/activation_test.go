@@ -0,0 +1,57 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"testing"
+)
+
+func TestStrictActivation(t *testing.T) {
+	env, err := NewEnv(
+		Variable("user", StringType),
+		Variable("age", IntType),
+		Variable("tags", ListType(StringType)),
+		Variable("extra", DynType),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	vars, err := env.StrictActivation(map[string]any{
+		"user":  "alice",
+		"age":   int64(42),
+		"tags":  []string{"a"},
+		"extra": 1.5,
+	})
+	if err != nil {
+		t.Fatalf("env.StrictActivation() failed: %v", err)
+	}
+	if val, found := vars.ResolveName("user"); !found || val != "alice" {
+		t.Errorf("vars.ResolveName(user) got %v, %v, wanted alice", val, found)
+	}
+	// Lazy bindings are validated by name only.
+	if _, err := env.StrictActivation(map[string]any{"age": func() any { return "old" }}); err != nil {
+		t.Errorf("env.StrictActivation() with lazy binding failed: %v", err)
+	}
+	_, err = env.StrictActivation(map[string]any{
+		"usr":   "alice",
+		"age":   "42",
+		"other": true,
+	})
+	want := `invalid bindings: variable "age" has type int, got string, ` +
+		`undeclared variable "other", undeclared variable "usr" (did you mean "user"?)`
+	if err == nil || err.Error() != want {
+		t.Errorf("env.StrictActivation() got error %v, wanted %q", err, want)
+	}
+}
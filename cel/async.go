@@ -0,0 +1,228 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+	"github.com/google/cel-go/interpreter/functions"
+)
+
+// AsyncFunctionOp resolves a batch of calls to an asynchronous overload, where each call is given
+// as the list of its argument values. The results must be returned in the order of the calls.
+//
+// A non-nil error aborts the evaluation. Errors which are specific to a call should instead be
+// returned as the types.Err result of the call.
+type AsyncFunctionOp func(ctx context.Context, calls [][]ref.Val) ([]ref.Val, error)
+
+// AsyncFunctionBinding provides the implementation of an asynchronous overload, such as a lookup
+// against a remote feature store, whose calls are batched per evaluation rather than issued one at
+// a time.
+//
+// This feature is experimental. Programs which call asynchronous overloads evaluate in rounds:
+// each call whose result is not yet known suspends as an unknown value while the rest of the
+// expression continues to evaluate, after which all of the suspended calls are resolved with one
+// batch per overload and the expression is evaluated again with the resolved results. Evaluation
+// completes once a round suspends no calls, and the EvalDetails of the final round are returned.
+//
+// Since rounds re-evaluate the expression, the functions called by the expression, including lazy
+// variable bindings, may be invoked more than once per evaluation.
+func AsyncFunctionBinding(binding AsyncFunctionOp) OverloadOpt {
+	return func(o *overloadDecl) (*overloadDecl, error) {
+		if o.hasBinding() {
+			return nil, fmt.Errorf("overload already has a binding: %s", o.id)
+		}
+		o.asyncOp = binding
+		o.contextFunctionOp = asyncCallStub(o.id, binding)
+		return o, nil
+	}
+}
+
+// maxAsyncRounds limits the number of evaluation rounds of a program with asynchronous overloads,
+// which may only be reached when the arguments of asynchronous calls differ between rounds.
+const maxAsyncRounds = 100
+
+// asyncCallStub returns the implementation of an asynchronous overload, which returns the result
+// of the call when it has been resolved by a previous round, and suspends the call otherwise.
+func asyncCallStub(overloadID string, binding AsyncFunctionOp) functions.ContextFunctionOp {
+	return func(ctx context.Context, args ...ref.Val) ref.Val {
+		state, found := ctx.Value(asyncStateKey{}).(*asyncState)
+		key, encoded := interpreter.CallKey(overloadID, args)
+		if !found || !encoded {
+			// Outside of an asynchronous evaluation, or when the arguments cannot identify the call
+			// across rounds, resolve the call as a batch of one.
+			results, err := binding(ctx, [][]ref.Val{args})
+			if err != nil {
+				return types.NewErr("%s: %v", overloadID, err)
+			}
+			if len(results) != 1 {
+				return types.NewErr("%s: got %d results for 1 call", overloadID, len(results))
+			}
+			return results[0]
+		}
+		return state.result(key, overloadID, args)
+	}
+}
+
+// asyncOverloads returns the asynchronous overload implementations of the environment, keyed by
+// overload id.
+func (e *Env) asyncOverloads() map[string]AsyncFunctionOp {
+	var ops map[string]AsyncFunctionOp
+	for _, fn := range e.functions {
+		for _, o := range fn.overloads {
+			if o.asyncOp == nil {
+				continue
+			}
+			if ops == nil {
+				ops = map[string]AsyncFunctionOp{}
+			}
+			ops[o.id] = o.asyncOp
+		}
+	}
+	return ops
+}
+
+type asyncStateKey struct{}
+
+type asyncCall struct {
+	key        string
+	overloadID string
+	args       []ref.Val
+}
+
+// asyncState holds the resolved and suspended asynchronous calls of a single evaluation.
+type asyncState struct {
+	mu      sync.Mutex
+	results map[string]ref.Val
+	pending []*asyncCall
+	queued  map[string]bool
+}
+
+// result returns the result of the call if it is resolved, and otherwise suspends the call.
+func (s *asyncState) result(key, overloadID string, args []ref.Val) ref.Val {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if val, found := s.results[key]; found {
+		return val
+	}
+	if !s.queued[key] {
+		s.queued[key] = true
+		s.pending = append(s.pending, &asyncCall{key: key, overloadID: overloadID, args: args})
+	}
+	return types.Unknown{}
+}
+
+// takePending returns and clears the suspended calls.
+func (s *asyncState) takePending() []*asyncCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending := s.pending
+	s.pending = nil
+	s.queued = map[string]bool{}
+	return pending
+}
+
+// resolve invokes one batch per overload for the suspended calls and records their results.
+func (s *asyncState) resolve(ctx context.Context, bindings map[string]AsyncFunctionOp, calls []*asyncCall) error {
+	var order []string
+	batches := map[string][]*asyncCall{}
+	for _, c := range calls {
+		if _, found := batches[c.overloadID]; !found {
+			order = append(order, c.overloadID)
+		}
+		batches[c.overloadID] = append(batches[c.overloadID], c)
+	}
+	for _, id := range order {
+		batch := batches[id]
+		args := make([][]ref.Val, len(batch))
+		for i, c := range batch {
+			args[i] = c.args
+		}
+		results, err := bindings[id](ctx, args)
+		if err != nil {
+			return fmt.Errorf("%s: %w", id, err)
+		}
+		if len(results) != len(batch) {
+			return fmt.Errorf("%s: got %d results for %d calls", id, len(results), len(batch))
+		}
+		s.mu.Lock()
+		for i, c := range batch {
+			s.results[c.key] = results[i]
+		}
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+// asyncProgram evaluates a program which calls asynchronous overloads in rounds until no calls are
+// suspended.
+type asyncProgram struct {
+	Program
+	bindings map[string]AsyncFunctionOp
+}
+
+// Eval implements the Program interface method.
+func (p *asyncProgram) Eval(input any) (ref.Val, *EvalDetails, error) {
+	return p.ContextEval(context.Background(), input)
+}
+
+// ContextEval implements the Program interface method.
+func (p *asyncProgram) ContextEval(ctx context.Context, input any) (ref.Val, *EvalDetails, error) {
+	if ctx == nil {
+		return nil, nil, fmt.Errorf("context can not be nil")
+	}
+	state := &asyncState{
+		results: map[string]ref.Val{},
+		queued:  map[string]bool{},
+	}
+	evalCtx := context.WithValue(ctx, asyncStateKey{}, state)
+	for round := 1; ; round++ {
+		out, det, err := p.Program.ContextEval(evalCtx, input)
+		calls := state.takePending()
+		if len(calls) == 0 {
+			return out, det, err
+		}
+		if round == maxAsyncRounds {
+			return nil, det, fmt.Errorf("asynchronous calls remain after %d evaluation rounds", round)
+		}
+		if err := state.resolve(ctx, p.bindings, calls); err != nil {
+			return nil, det, err
+		}
+	}
+}
+
+// withFunctions implements the rebinder interface method.
+func (p *asyncProgram) withFunctions(bindings ...*functions.Overload) (Program, error) {
+	prg, err := WithFunctions(p.Program, bindings...)
+	if err != nil {
+		return nil, err
+	}
+	return &asyncProgram{Program: prg, bindings: p.bindings}, nil
+}
+
+// evalSeries implements the seriesEvaluator interface method.
+func (p *asyncProgram) evalSeries(base any) (*Series, error) {
+	return newSeries(p, base)
+}
+
+// warmup implements the warmer interface method.
+func (p *asyncProgram) warmup(sampleVars any) error {
+	return Warmup(p.Program, sampleVars)
+}
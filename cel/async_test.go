@@ -0,0 +1,104 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+func TestAsyncFunctionBinding(t *testing.T) {
+	features := map[string]int64{"a": 1, "b": 2, "c": 3, "3": 3}
+	var batches [][]string
+	env, err := NewEnv(
+		Variable("keys", ListType(StringType)),
+		Function("feature",
+			Overload("feature_string", []*Type{StringType}, IntType,
+				AsyncFunctionBinding(func(ctx context.Context, calls [][]ref.Val) ([]ref.Val, error) {
+					var keys []string
+					results := make([]ref.Val, len(calls))
+					for i, args := range calls {
+						key := string(args[0].(types.String))
+						keys = append(keys, key)
+						val, found := features[key]
+						if !found {
+							results[i] = types.NewErr("no such feature: %s", key)
+							continue
+						}
+						results[i] = types.Int(val)
+					}
+					batches = append(batches, keys)
+					return results, nil
+				})),
+		),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		expr    string
+		out     ref.Val
+		err     string
+		batches [][]string
+	}{
+		{
+			expr:    `feature('a') + feature('b') == feature(string(feature('c')))`,
+			out:     types.True,
+			batches: [][]string{{"a", "b", "c"}, {"3"}},
+		},
+		{
+			expr:    `keys.map(k, feature(k)) == [1, 2, 1]`,
+			out:     types.True,
+			batches: [][]string{{"a", "b"}},
+		},
+		{
+			expr:    `feature('a') == 1 || feature('missing') == 1`,
+			out:     types.True,
+			batches: [][]string{{"a", "missing"}},
+		},
+		{
+			expr:    `feature('missing') == 1`,
+			err:     "no such feature: missing",
+			batches: [][]string{{"missing"}},
+		},
+	}
+	for _, tc := range tests {
+		ast, iss := env.Compile(tc.expr)
+		if iss.Err() != nil {
+			t.Fatalf("env.Compile(%q) failed: %v", tc.expr, iss.Err())
+		}
+		prg, err := env.Program(ast)
+		if err != nil {
+			t.Fatalf("env.Program() failed: %v", err)
+		}
+		batches = nil
+		out, _, err := prg.Eval(map[string]any{"keys": []string{"a", "b", "a"}})
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%s got error %v, wanted %q", tc.expr, err, tc.err)
+			}
+		} else if err != nil || out != tc.out {
+			t.Errorf("%s got %v, %v, wanted %v", tc.expr, out, err, tc.out)
+		}
+		if !reflect.DeepEqual(batches, tc.batches) {
+			t.Errorf("%s resolved batches %v, wanted %v", tc.expr, batches, tc.batches)
+		}
+	}
+}
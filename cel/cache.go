@@ -0,0 +1,197 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+)
+
+// ProgramCacheOption configures a ProgramCache.
+type ProgramCacheOption func(*ProgramCache) (*ProgramCache, error)
+
+// ProgramCacheSize sets the maximum number of programs retained by the cache, after which the
+// least recently used program is evicted. The default size is 1000 programs.
+func ProgramCacheSize(size int) ProgramCacheOption {
+	return func(c *ProgramCache) (*ProgramCache, error) {
+		if size <= 0 {
+			return nil, fmt.Errorf("program cache size must be positive: %d", size)
+		}
+		c.maxEntries = size
+		return c, nil
+	}
+}
+
+// ProgramCacheProgramOptions sets the ProgramOption values used to plan the cached programs.
+func ProgramCacheProgramOptions(opts ...ProgramOption) ProgramCacheOption {
+	return func(c *ProgramCache) (*ProgramCache, error) {
+		c.prgOpts = append(c.prgOpts, opts...)
+		return c, nil
+	}
+}
+
+// ProgramCacheStats reports the activity of a ProgramCache.
+type ProgramCacheStats struct {
+	// Hits is the number of requests served by a cached program, including requests which waited
+	// on the compilation of the same expression by a concurrent request.
+	Hits uint64
+
+	// Misses is the number of requests which compiled the expression.
+	Misses uint64
+
+	// Errors is the number of compilations which failed. Failures are not cached.
+	Errors uint64
+
+	// Evictions is the number of programs removed from the cache to respect its size limit.
+	Evictions uint64
+
+	// Size is the number of programs held by the cache.
+	Size int
+}
+
+// ProgramCache is a concurrency-safe, size-limited cache of programs keyed by a fingerprint of the
+// environment and the text of the expression.
+//
+// The fingerprint covers the declarative configuration of the environment, such as its container,
+// declarations, features, types, and integer overflow policy, so that environments configured
+// alike share programs while, for example, programs compiled under different overflow policies
+// are never shared. The options which configure an environment through functions, such as
+// function bindings, macros, and program options, cannot be compared, and so environments
+// configured by them only share programs with the environments derived from them which add
+// declarative configuration alone. The cache does not retain the environments it has seen.
+//
+// Concurrent requests for the same uncached expression result in a single compilation.
+type ProgramCache struct {
+	maxEntries int
+	prgOpts    []ProgramOption
+
+	mu       sync.Mutex
+	lru      *list.List
+	entries  map[programCacheKey]*list.Element
+	inflight map[programCacheKey]*programCacheCall
+	stats    ProgramCacheStats
+}
+
+// NewProgramCache creates a ProgramCache configured with the given options.
+func NewProgramCache(opts ...ProgramCacheOption) (*ProgramCache, error) {
+	c := &ProgramCache{
+		maxEntries: 1000,
+		lru:        list.New(),
+		entries:    map[programCacheKey]*list.Element{},
+		inflight:   map[programCacheKey]*programCacheCall{},
+	}
+	var err error
+	for _, opt := range opts {
+		c, err = opt(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+type programCacheKey struct {
+	env  envFingerprint
+	hash [sha256.Size]byte
+}
+
+type programCacheEntry struct {
+	key programCacheKey
+	prg Program
+}
+
+// programCacheCall tracks an in-progress compilation shared by concurrent requests.
+type programCacheCall struct {
+	done chan struct{}
+	prg  Program
+	err  error
+}
+
+// Program returns the program for the expression within the environment, compiling and planning
+// the expression when the program is not cached.
+func (c *ProgramCache) Program(env *Env, expr string) (Program, error) {
+	key := programCacheKey{env: env.fingerprint(), hash: sha256.Sum256([]byte(expr))}
+	c.mu.Lock()
+	if elem, found := c.entries[key]; found {
+		c.lru.MoveToFront(elem)
+		c.stats.Hits++
+		c.mu.Unlock()
+		return elem.Value.(*programCacheEntry).prg, nil
+	}
+	if call, found := c.inflight[key]; found {
+		c.stats.Hits++
+		c.mu.Unlock()
+		<-call.done
+		return call.prg, call.err
+	}
+	call := &programCacheCall{done: make(chan struct{})}
+	c.inflight[key] = call
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	// Compilation panics are reported as errors, so the in-flight call is always completed and
+	// the requests waiting on it are released.
+	call.prg, call.err = c.compile(env, expr)
+
+	c.mu.Lock()
+	delete(c.inflight, key)
+	if call.err != nil {
+		c.stats.Errors++
+	} else {
+		c.entries[key] = c.lru.PushFront(&programCacheEntry{key: key, prg: call.prg})
+		for c.lru.Len() > c.maxEntries {
+			oldest := c.lru.Back()
+			c.lru.Remove(oldest)
+			delete(c.entries, oldest.Value.(*programCacheEntry).key)
+			c.stats.Evictions++
+		}
+	}
+	c.mu.Unlock()
+	close(call.done)
+	return call.prg, call.err
+}
+
+// Stats returns a snapshot of the cache activity.
+func (c *ProgramCache) Stats() ProgramCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Size = c.lru.Len()
+	return stats
+}
+
+// Purge removes all programs from the cache.
+func (c *ProgramCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Init()
+	c.entries = map[programCacheKey]*list.Element{}
+}
+
+// compile compiles and plans the expression, reporting a panic during compilation as an error.
+func (c *ProgramCache) compile(env *Env, expr string) (prg Program, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			prg, err = nil, fmt.Errorf("program compilation panicked: %v", r)
+		}
+	}()
+	ast, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	return env.Program(ast, c.prgOpts...)
+}
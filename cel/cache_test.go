@@ -0,0 +1,192 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+)
+
+func TestProgramCache(t *testing.T) {
+	env, err := NewEnv(Variable("x", IntType))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	cache, err := NewProgramCache(ProgramCacheSize(2), ProgramCacheProgramOptions(EvalOptions(OptOptimize)))
+	if err != nil {
+		t.Fatalf("NewProgramCache() failed: %v", err)
+	}
+	prg, err := cache.Program(env, `x + 1`)
+	if err != nil {
+		t.Fatalf("cache.Program() failed: %v", err)
+	}
+	out, _, err := prg.Eval(map[string]any{"x": 1})
+	if err != nil || out != types.Int(2) {
+		t.Errorf("prg.Eval() got %v, %v, wanted 2", out, err)
+	}
+	again, err := cache.Program(env, `x + 1`)
+	if err != nil || again != prg {
+		t.Errorf("cache.Program() got %v, %v, wanted the cached program", again, err)
+	}
+
+	// A derived environment must not share the programs of its parent.
+	ext, err := env.Extend(Variable("y", IntType))
+	if err != nil {
+		t.Fatalf("env.Extend() failed: %v", err)
+	}
+	extPrg, err := cache.Program(ext, `x + 1`)
+	if err != nil || extPrg == prg {
+		t.Errorf("cache.Program(ext) got %v, %v, wanted a distinct program", extPrg, err)
+	}
+
+	// Compile errors are reported and not cached.
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Program(env, `x + 'a'`); err == nil {
+			t.Error("cache.Program() with a type error succeeded, wanted error")
+		}
+	}
+
+	// The least recently used program is evicted.
+	if _, err := cache.Program(env, `x + 2`); err != nil {
+		t.Fatalf("cache.Program() failed: %v", err)
+	}
+	evicted, err := cache.Program(env, `x + 1`)
+	if err != nil || evicted == prg {
+		t.Errorf("cache.Program() got %v, %v, wanted a newly compiled program", evicted, err)
+	}
+
+	got := cache.Stats()
+	want := ProgramCacheStats{Hits: 1, Misses: 6, Errors: 2, Evictions: 2, Size: 2}
+	if got != want {
+		t.Errorf("cache.Stats() got %+v, wanted %+v", got, want)
+	}
+	cache.Purge()
+	if size := cache.Stats().Size; size != 0 {
+		t.Errorf("cache.Stats().Size got %d after Purge(), wanted 0", size)
+	}
+}
+
+func TestProgramCacheSingleFlight(t *testing.T) {
+	env, err := NewEnv(Variable("x", IntType))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	cache, err := NewProgramCache()
+	if err != nil {
+		t.Fatalf("NewProgramCache() failed: %v", err)
+	}
+	const requests = 16
+	prgs := make([]Program, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			prg, err := cache.Program(env, `[1, 2, 3].exists(i, i == x)`)
+			if err != nil {
+				t.Errorf("cache.Program() failed: %v", err)
+			}
+			prgs[i] = prg
+		}(i)
+	}
+	wg.Wait()
+	for _, prg := range prgs[1:] {
+		if prg != prgs[0] {
+			t.Fatal("cache.Program() returned distinct programs for concurrent requests")
+		}
+	}
+	if stats := cache.Stats(); stats.Misses != 1 || stats.Hits != requests-1 {
+		t.Errorf("cache.Stats() got %+v, wanted 1 miss and %d hits", stats, requests-1)
+	}
+}
+
+func TestProgramCacheSizeInvalid(t *testing.T) {
+	if _, err := NewProgramCache(ProgramCacheSize(0)); err == nil {
+		t.Error("NewProgramCache(ProgramCacheSize(0)) succeeded, wanted error")
+	}
+}
+
+func TestProgramCacheFingerprint(t *testing.T) {
+	cache, err := NewProgramCache()
+	if err != nil {
+		t.Fatalf("NewProgramCache() failed: %v", err)
+	}
+	newEnv := func(opts ...EnvOption) *Env {
+		t.Helper()
+		env, err := NewEnv(opts...)
+		if err != nil {
+			t.Fatalf("NewEnv() failed: %v", err)
+		}
+		return env
+	}
+	prg, err := cache.Program(newEnv(Variable("x", IntType)), `x + 1`)
+	if err != nil {
+		t.Fatalf("cache.Program() failed: %v", err)
+	}
+	// Environments which are configured alike share programs.
+	same, err := cache.Program(newEnv(Variable("x", IntType)), `x + 1`)
+	if err != nil || same != prg {
+		t.Errorf("cache.Program() of a structurally identical env got %v, %v, wanted the cached program", same, err)
+	}
+	tests := []struct {
+		name string
+		env  *Env
+	}{
+		{name: "variable type", env: newEnv(Variable("x", DynType))},
+		{name: "container", env: newEnv(Container("google.expr"), Variable("x", IntType))},
+		{name: "feature", env: newEnv(Variable("x", IntType), CrossTypeNumericComparisons(true))},
+		{name: "default", env: newEnv(Variable("x", IntType, DefaultValue(1)))},
+		{name: "overflow policy", env: newEnv(Variable("x", IntType), IntegerOverflowPolicy(interpreter.OverflowWrap))},
+		{name: "binding", env: newEnv(Variable("x", IntType),
+			Function("f", Overload("f_int", []*Type{IntType}, IntType,
+				UnaryBinding(func(arg ref.Val) ref.Val { return arg }))))},
+		{name: "parser option", env: newEnv(Variable("x", IntType), ParserRecursionLimit(10))},
+	}
+	for _, tc := range tests {
+		other, err := cache.Program(tc.env, `x + 1`)
+		if err != nil || other == prg {
+			t.Errorf("cache.Program() with a different %s got %v, %v, wanted a distinct program", tc.name, other, err)
+		}
+	}
+}
+
+func TestProgramCachePanic(t *testing.T) {
+	env, err := NewEnv(Variable("x", IntType), Linters(func(*Ast) []Warning {
+		panic("linter failure")
+	}))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	cache, err := NewProgramCache()
+	if err != nil {
+		t.Fatalf("NewProgramCache() failed: %v", err)
+	}
+	// A panic during compilation is reported as an error, and does not leave the expression
+	// in flight for the following requests.
+	for i := 0; i < 2; i++ {
+		_, err := cache.Program(env, `x + 1`)
+		if err == nil || !strings.Contains(err.Error(), "linter failure") {
+			t.Errorf("cache.Program() got %v, wanted the panic as an error", err)
+		}
+	}
+	if stats := cache.Stats(); stats.Misses != 2 || stats.Errors != 2 {
+		t.Errorf("cache.Stats() got %+v, wanted 2 misses and 2 errors", stats)
+	}
+}
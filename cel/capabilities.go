@@ -0,0 +1,538 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/overloads"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Capabilities describes the set of environment features referenced by a checked expression.
+type Capabilities struct {
+	// Functions maps each function name referenced by the expression to the sorted list of
+	// overload ids which the type-checker determined may be invoked at runtime.
+	//
+	// Type conversions are reported separately in Conversions.
+	Functions map[string][]string
+
+	// Variables is the sorted list of top-level variables referenced by the expression.
+	Variables []string
+
+	// FieldPaths is the sorted list of dotted field paths rooted at a variable, e.g. `a.b.c`.
+	// Presence tests are also reported as field paths.
+	//
+	// Accesses which cannot be resolved statically, such as an index with a computed key or a
+	// selection from a function result, are reported with a wildcard, e.g. `a.*`.
+	FieldPaths []string
+
+	// Conversions is the sorted list of type conversion functions used, e.g. `int` or `dyn`.
+	Conversions []string
+
+	// ComprehensionDepth is the maximum nesting depth of comprehensions within the expression.
+	ComprehensionDepth int
+}
+
+// AnalyzeCapabilities reports the complete set of capabilities used by a checked Ast.
+//
+// The analysis relies on the reference map produced by the type-checker, so an error is returned
+// when the input Ast has only been parsed.
+func AnalyzeCapabilities(ast *Ast) (*Capabilities, error) {
+	if !ast.IsChecked() {
+		return nil, errors.New("cannot analyze capabilities of an unchecked ast")
+	}
+	a := &capabilityAnalyzer{
+		refMap:      ast.refMap,
+		functions:   map[string]map[string]struct{}{},
+		variables:   map[string]struct{}{},
+		fieldPaths:  map[string]struct{}{},
+		conversions: map[string]struct{}{},
+		scopes:      map[string][][]string{},
+	}
+	a.visit(ast.Expr(), 0)
+	caps := &Capabilities{
+		Functions:          make(map[string][]string, len(a.functions)),
+		Variables:          sortedKeys(a.variables),
+		FieldPaths:         sortedKeys(a.fieldPaths),
+		Conversions:        sortedKeys(a.conversions),
+		ComprehensionDepth: a.maxDepth,
+	}
+	for fn, ids := range a.functions {
+		caps.Functions[fn] = sortedKeys(ids)
+	}
+	return caps, nil
+}
+
+// CapabilityPolicy declares the capabilities an expression is permitted to use.
+//
+// A nil allow-list places no restriction on the corresponding capability, whereas an empty,
+// non-nil allow-list forbids the capability entirely.
+type CapabilityPolicy struct {
+	// AllowedFunctions lists the function names or overload ids which may be used.
+	AllowedFunctions []string
+
+	// DeniedFunctions lists function names or overload ids which must not be used. Denials take
+	// precedence over AllowedFunctions.
+	DeniedFunctions []string
+
+	// AllowedVariables lists the top-level variables which may be referenced.
+	AllowedVariables []string
+
+	// DeniedFieldPaths lists field paths which must not be accessed. A denied path also denies
+	// access to all of the fields nested beneath it, and a wildcard access is denied when it may
+	// stand for a denied path.
+	DeniedFieldPaths []string
+
+	// AllowedConversions lists the type conversion functions which may be used.
+	AllowedConversions []string
+
+	// MaxComprehensionDepth limits the nesting depth of comprehensions when greater than zero.
+	MaxComprehensionDepth int
+
+	// DisallowComprehensions rejects any expression which contains a comprehension.
+	DisallowComprehensions bool
+}
+
+// Validate returns an error describing every way in which the capabilities violate the policy,
+// or nil if the capabilities are permitted.
+func (p *CapabilityPolicy) Validate(caps *Capabilities) error {
+	var violations []string
+	allowedFns := toSet(p.AllowedFunctions)
+	deniedFns := toSet(p.DeniedFunctions)
+	fnNames := make([]string, 0, len(caps.Functions))
+	for fn := range caps.Functions {
+		fnNames = append(fnNames, fn)
+	}
+	sort.Strings(fnNames)
+	for _, fn := range fnNames {
+		for _, id := range caps.Functions[fn] {
+			_, fnDenied := deniedFns[fn]
+			_, idDenied := deniedFns[id]
+			if fnDenied || idDenied {
+				violations = append(violations, fmt.Sprintf("function denied: %s (%s)", fn, id))
+				continue
+			}
+			if allowedFns == nil {
+				continue
+			}
+			_, fnAllowed := allowedFns[fn]
+			_, idAllowed := allowedFns[id]
+			if !fnAllowed && !idAllowed {
+				violations = append(violations, fmt.Sprintf("function not allowed: %s (%s)", fn, id))
+			}
+		}
+	}
+	if allowedVars := toSet(p.AllowedVariables); allowedVars != nil {
+		for _, v := range caps.Variables {
+			if _, found := allowedVars[v]; !found {
+				violations = append(violations, fmt.Sprintf("variable not allowed: %s", v))
+			}
+		}
+	}
+	for _, path := range caps.FieldPaths {
+		for _, denied := range p.DeniedFieldPaths {
+			if deniedPath(path, denied) {
+				violations = append(violations, fmt.Sprintf("field path denied: %s", path))
+				break
+			}
+		}
+	}
+	if allowedConvs := toSet(p.AllowedConversions); allowedConvs != nil {
+		for _, conv := range caps.Conversions {
+			if _, found := allowedConvs[conv]; !found {
+				violations = append(violations, fmt.Sprintf("conversion not allowed: %s", conv))
+			}
+		}
+	}
+	if p.DisallowComprehensions && caps.ComprehensionDepth > 0 {
+		violations = append(violations, "comprehensions not allowed")
+	} else if p.MaxComprehensionDepth > 0 && caps.ComprehensionDepth > p.MaxComprehensionDepth {
+		violations = append(violations,
+			fmt.Sprintf("comprehension depth %d exceeds limit %d",
+				caps.ComprehensionDepth, p.MaxComprehensionDepth))
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("capability policy violated: %s", strings.Join(violations, "; "))
+}
+
+// Enforce analyzes the capabilities of the checked Ast and validates them against the policy.
+func (p *CapabilityPolicy) Enforce(ast *Ast) error {
+	caps, err := AnalyzeCapabilities(ast)
+	if err != nil {
+		return err
+	}
+	return p.Validate(caps)
+}
+
+type capabilityAnalyzer struct {
+	refMap      map[int64]*exprpb.Reference
+	functions   map[string]map[string]struct{}
+	variables   map[string]struct{}
+	fieldPaths  map[string]struct{}
+	conversions map[string]struct{}
+	// scopes tracks the comprehension variables currently in scope, as these shadow the
+	// variables declared within the environment, along with the field paths each one aliases.
+	scopes   map[string][][]string
+	maxDepth int
+}
+
+func (a *capabilityAnalyzer) visit(e *exprpb.Expr, depth int) {
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		a.visitIdent(e)
+	case *exprpb.Expr_SelectExpr:
+		a.visitSelect(e, depth, false)
+	case *exprpb.Expr_CallExpr:
+		a.visitCall(e, depth, false)
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range e.GetListExpr().GetElements() {
+			a.visit(elem, depth)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			if entry.GetMapKey() != nil {
+				a.visit(entry.GetMapKey(), depth)
+			}
+			a.visit(entry.GetValue(), depth)
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		depth++
+		if depth > a.maxDepth {
+			a.maxDepth = depth
+		}
+		a.visit(comp.GetIterRange(), depth)
+		a.visit(comp.GetAccuInit(), depth)
+		a.pushScope(comp.GetAccuVar(), a.valuePaths(comp.GetAccuInit()))
+		a.pushScope(comp.GetIterVar(), wildcardPaths(a.valuePaths(comp.GetIterRange())))
+		a.visit(comp.GetLoopCondition(), depth)
+		a.visit(comp.GetLoopStep(), depth)
+		a.popScope(comp.GetIterVar())
+		a.visit(comp.GetResult(), depth)
+		a.popScope(comp.GetAccuVar())
+	}
+}
+
+func (a *capabilityAnalyzer) visitIdent(e *exprpb.Expr) {
+	if name, isVar := a.variableName(e); isVar {
+		a.variables[name] = struct{}{}
+	}
+}
+
+// visitSelect records the field path of the outermost selection in a chain, as the paths of
+// nested selections and field accesses are prefixes of it.
+func (a *capabilityAnalyzer) visitSelect(e *exprpb.Expr, depth int, nested bool) {
+	if name, isVar := a.variableName(e); isVar {
+		a.variables[name] = struct{}{}
+		return
+	}
+	a.recordFieldPaths(e, e.GetSelectExpr().GetOperand(), nested)
+	a.visitOperand(e.GetSelectExpr().GetOperand(), depth)
+}
+
+func (a *capabilityAnalyzer) visitOperand(e *exprpb.Expr, depth int) {
+	if e.GetSelectExpr() != nil {
+		a.visitSelect(e, depth, true)
+		return
+	}
+	if _, isField := fieldAccess(e.GetCallExpr()); isField {
+		a.visitCall(e, depth, true)
+		return
+	}
+	a.visit(e, depth)
+}
+
+func (a *capabilityAnalyzer) visitCall(e *exprpb.Expr, depth int, nested bool) {
+	call := e.GetCallExpr()
+	fn := call.GetFunction()
+	if call.GetTarget() != nil {
+		a.visit(call.GetTarget(), depth)
+	}
+	_, isField := fieldAccess(call)
+	for i, arg := range call.GetArgs() {
+		if isField && i == 0 {
+			a.visitOperand(arg, depth)
+			continue
+		}
+		a.visit(arg, depth)
+	}
+	switch fn {
+	case operators.NotStrictlyFalse, operators.OldNotStrictlyFalse:
+		// Internal helper function introduced by macro expansion.
+		return
+	case operators.OptSelect, operators.Index, operators.OptIndex:
+		if len(call.GetArgs()) == 2 {
+			a.recordFieldPaths(e, call.GetArgs()[0], nested)
+		}
+	}
+	if _, isConv := conversionFunctions[fn]; isConv && len(call.GetArgs()) == 1 && call.GetTarget() == nil {
+		a.conversions[fn] = struct{}{}
+		return
+	}
+	ids, found := a.functions[fn]
+	if !found {
+		ids = map[string]struct{}{}
+		a.functions[fn] = ids
+	}
+	for _, id := range a.refMap[e.GetId()].GetOverloadId() {
+		ids[id] = struct{}{}
+	}
+}
+
+// recordFieldPaths records the field paths accessed by a field selection or index.
+//
+// When the access cannot be resolved to a path rooted at a variable, e.g. when the key is not a
+// constant or the operand is a function result, the access is recorded as a wildcard access of
+// every path from which the operand may derive its value, e.g. `a.*`.
+func (a *capabilityAnalyzer) recordFieldPaths(e, operand *exprpb.Expr, nested bool) {
+	paths, found := a.fieldPath(e)
+	if !found {
+		paths = wildcardPaths(a.derivedPaths(operand))
+	} else if nested {
+		return
+	}
+	for _, path := range paths {
+		a.fieldPaths[path] = struct{}{}
+	}
+}
+
+// variableName returns the name of the environment variable referenced by the expression, if any.
+func (a *capabilityAnalyzer) variableName(e *exprpb.Expr) (string, bool) {
+	ref, found := a.refMap[e.GetId()]
+	if !found || ref.GetName() == "" || ref.GetValue() != nil {
+		return "", false
+	}
+	if e.GetIdentExpr() != nil && len(a.scopes[e.GetIdentExpr().GetName()]) > 0 {
+		return "", false
+	}
+	return ref.GetName(), true
+}
+
+// aliases returns the field paths aliased by the comprehension variable in scope with the given
+// name, if any.
+func (a *capabilityAnalyzer) aliases(name string) []string {
+	scope := a.scopes[name]
+	if len(scope) == 0 {
+		return nil
+	}
+	return scope[len(scope)-1]
+}
+
+func (a *capabilityAnalyzer) pushScope(name string, paths []string) {
+	a.scopes[name] = append(a.scopes[name], paths)
+}
+
+func (a *capabilityAnalyzer) popScope(name string) {
+	scope := a.scopes[name]
+	a.scopes[name] = scope[:len(scope)-1]
+}
+
+// fieldPath returns the dotted field paths for a chain of field selections rooted at a variable
+// or at a comprehension variable which aliases one or more paths. Indexing with a constant string
+// key selects a field in the same way as a field selection.
+func (a *capabilityAnalyzer) fieldPath(e *exprpb.Expr) ([]string, bool) {
+	paths, found := a.rootedPaths(e)
+	if !found || e.GetIdentExpr() != nil {
+		return nil, false
+	}
+	if _, isVar := a.variableName(e); isVar {
+		return nil, false
+	}
+	return paths, true
+}
+
+// rootedPaths returns the dotted paths which the expression may refer to when it is a variable,
+// a comprehension variable which aliases one or more paths, or a chain of field selections and
+// constant string indexes rooted at either.
+func (a *capabilityAnalyzer) rootedPaths(e *exprpb.Expr) ([]string, bool) {
+	var fields []string
+	for {
+		var roots []string
+		if name, isVar := a.variableName(e); isVar {
+			roots = []string{name}
+		} else if ident := e.GetIdentExpr(); ident != nil {
+			roots = a.aliases(ident.GetName())
+			if len(roots) == 0 {
+				return nil, false
+			}
+		}
+		if roots != nil {
+			for i, j := 0, len(fields)-1; i < j; i, j = i+1, j-1 {
+				fields[i], fields[j] = fields[j], fields[i]
+			}
+			paths := make([]string, len(roots))
+			for i, root := range roots {
+				paths[i] = strings.Join(append([]string{root}, fields...), ".")
+			}
+			return paths, true
+		}
+		switch e.GetExprKind().(type) {
+		case *exprpb.Expr_SelectExpr:
+			sel := e.GetSelectExpr()
+			fields = append(fields, sel.GetField())
+			e = sel.GetOperand()
+		case *exprpb.Expr_CallExpr:
+			field, found := fieldAccess(e.GetCallExpr())
+			if !found {
+				return nil, false
+			}
+			fields = append(fields, field)
+			e = e.GetCallExpr().GetArgs()[0]
+		default:
+			return nil, false
+		}
+	}
+}
+
+// valuePaths returns the paths which the expression may refer to, or the wildcard paths from which
+// the expression may derive its value when it does not refer to a rooted path.
+func (a *capabilityAnalyzer) valuePaths(e *exprpb.Expr) []string {
+	if paths, found := a.rootedPaths(e); found {
+		return paths
+	}
+	return wildcardPaths(a.derivedPaths(e))
+}
+
+// derivedPaths returns the paths of the variables and comprehension variable aliases referenced
+// anywhere within the expression.
+func (a *capabilityAnalyzer) derivedPaths(e *exprpb.Expr) []string {
+	if name, isVar := a.variableName(e); isVar {
+		return []string{name}
+	}
+	var paths []string
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		paths = a.aliases(e.GetIdentExpr().GetName())
+	case *exprpb.Expr_SelectExpr:
+		paths = a.derivedPaths(e.GetSelectExpr().GetOperand())
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		if call.GetTarget() != nil {
+			paths = a.derivedPaths(call.GetTarget())
+		}
+		for _, arg := range call.GetArgs() {
+			paths = append(paths, a.derivedPaths(arg)...)
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range e.GetListExpr().GetElements() {
+			paths = append(paths, a.derivedPaths(elem)...)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			if entry.GetMapKey() != nil {
+				paths = append(paths, a.derivedPaths(entry.GetMapKey())...)
+			}
+			paths = append(paths, a.derivedPaths(entry.GetValue())...)
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		paths = append(a.derivedPaths(comp.GetIterRange()), a.derivedPaths(comp.GetAccuInit())...)
+		a.pushScope(comp.GetAccuVar(), a.valuePaths(comp.GetAccuInit()))
+		a.pushScope(comp.GetIterVar(), wildcardPaths(a.valuePaths(comp.GetIterRange())))
+		paths = append(paths, a.derivedPaths(comp.GetLoopCondition())...)
+		paths = append(paths, a.derivedPaths(comp.GetLoopStep())...)
+		a.popScope(comp.GetIterVar())
+		paths = append(paths, a.derivedPaths(comp.GetResult())...)
+		a.popScope(comp.GetAccuVar())
+	}
+	return paths
+}
+
+// wildcardPaths returns the paths with a trailing wildcard which stands for any field, key, or
+// index beneath the path.
+func wildcardPaths(paths []string) []string {
+	wildcards := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if !strings.HasSuffix(path, ".*") {
+			path += ".*"
+		}
+		wildcards = append(wildcards, path)
+	}
+	return wildcards
+}
+
+// deniedPath returns whether the field path is the denied path, is nested beneath it, or contains
+// a wildcard which may stand for the denied path or one of its parents.
+func deniedPath(path, denied string) bool {
+	fields := strings.Split(path, ".")
+	for i, field := range strings.Split(denied, ".") {
+		if i >= len(fields) {
+			return false
+		}
+		if fields[i] == "*" {
+			return true
+		}
+		if fields[i] != field {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldAccess returns the field accessed by an optional field selection or by indexing with a
+// constant string key.
+func fieldAccess(call *exprpb.Expr_Call) (string, bool) {
+	switch call.GetFunction() {
+	case operators.OptSelect, operators.Index, operators.OptIndex:
+	default:
+		return "", false
+	}
+	if len(call.GetArgs()) != 2 {
+		return "", false
+	}
+	field := call.GetArgs()[1].GetConstExpr().GetStringValue()
+	return field, field != ""
+}
+
+var conversionFunctions = map[string]struct{}{
+	overloads.TypeConvertInt:       {},
+	overloads.TypeConvertUint:      {},
+	overloads.TypeConvertDouble:    {},
+	overloads.TypeConvertBool:      {},
+	overloads.TypeConvertString:    {},
+	overloads.TypeConvertBytes:     {},
+	overloads.TypeConvertTimestamp: {},
+	overloads.TypeConvertDuration:  {},
+	overloads.TypeConvertType:      {},
+	overloads.TypeConvertDyn:       {},
+}
+
+func toSet(vals []string) map[string]struct{} {
+	if vals == nil {
+		return nil
+	}
+	set := make(map[string]struct{}, len(vals))
+	for _, v := range vals {
+		set[v] = struct{}{}
+	}
+	return set
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
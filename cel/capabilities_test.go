@@ -0,0 +1,198 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/common"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestAnalyzeCapabilities(t *testing.T) {
+	env, err := NewEnv(
+		Variable("req", MapType(StringType, DynType)),
+		Variable("items", ListType(MapType(StringType, IntType))),
+		Variable("unused", StringType),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`has(req.auth.token) && int(req.auth.level) > 2
+		&& items.all(i, i.exists(k, k.startsWith('a') && [1].all(x, x > 0)))`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	caps, err := AnalyzeCapabilities(ast)
+	if err != nil {
+		t.Fatalf("AnalyzeCapabilities() failed: %v", err)
+	}
+	want := &Capabilities{
+		Functions: map[string][]string{
+			"!_":         {"logical_not"},
+			"_&&_":       {"logical_and"},
+			"_>_":        {"greater_int64"},
+			"_||_":       {"logical_or"},
+			"startsWith": {"starts_with_string"},
+		},
+		Variables:          []string{"items", "req"},
+		FieldPaths:         []string{"req.auth.level", "req.auth.token"},
+		Conversions:        []string{"int"},
+		ComprehensionDepth: 3,
+	}
+	if !reflect.DeepEqual(caps, want) {
+		t.Errorf("AnalyzeCapabilities() got %+v, wanted %+v", caps, want)
+	}
+
+	unchecked, iss := env.Parse("req")
+	if iss.Err() != nil {
+		t.Fatalf("env.Parse() failed: %v", iss.Err())
+	}
+	if _, err := AnalyzeCapabilities(unchecked); err == nil {
+		t.Error("AnalyzeCapabilities() of a parsed-only ast succeeded, wanted error")
+	}
+}
+
+func TestCapabilityPolicy(t *testing.T) {
+	env, err := NewEnv(
+		Variable("a", MapType(StringType, DynType)),
+		Variable("b", StringType),
+		// bind(var, init, expr) mirrors the cel.bind() macro of the ext package.
+		Macros(NewGlobalMacro("bind", 3,
+			func(meh MacroExprHelper, _ *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+				name := args[0].GetIdentExpr().GetName()
+				return meh.Fold("#unused", meh.NewList(), name, args[1],
+					meh.LiteralBool(false), meh.Ident(name), args[2]), nil
+			})),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		expr   string
+		policy *CapabilityPolicy
+		errs   []string
+	}{
+		{
+			expr:   `a.x.y == b`,
+			policy: &CapabilityPolicy{},
+		},
+		{
+			expr:   `a.x.y == b`,
+			policy: &CapabilityPolicy{AllowedVariables: []string{"a"}},
+			errs:   []string{"variable not allowed: b"},
+		},
+		{
+			expr:   `a.x.y == b`,
+			policy: &CapabilityPolicy{DeniedFieldPaths: []string{"a.x"}},
+			errs:   []string{"field path denied: a.x.y"},
+		},
+		{
+			expr:   `a.xy == b`,
+			policy: &CapabilityPolicy{DeniedFieldPaths: []string{"a.x"}},
+		},
+		{
+			expr:   `a['secret'] == b`,
+			policy: &CapabilityPolicy{DeniedFieldPaths: []string{"a.secret"}},
+			errs:   []string{"field path denied: a.secret"},
+		},
+		{
+			expr:   `a['x'].y == b`,
+			policy: &CapabilityPolicy{DeniedFieldPaths: []string{"a.x"}},
+			errs:   []string{"field path denied: a.x.y"},
+		},
+		{
+			expr:   `bind(x, a, x.secret) == b`,
+			policy: &CapabilityPolicy{DeniedFieldPaths: []string{"a.secret"}},
+			errs:   []string{"field path denied: a.secret"},
+		},
+		{
+			expr:   `dyn(a).secret == b`,
+			policy: &CapabilityPolicy{DeniedFieldPaths: []string{"a.secret"}},
+			errs:   []string{"field path denied: a.*"},
+		},
+		{
+			expr:   `(true ? a : a).secret == b`,
+			policy: &CapabilityPolicy{DeniedFieldPaths: []string{"a.secret"}},
+			errs:   []string{"field path denied: a.*"},
+		},
+		{
+			expr:   `a['sec' + 'ret'] == b`,
+			policy: &CapabilityPolicy{DeniedFieldPaths: []string{"a.secret"}},
+			errs:   []string{"field path denied: a.*"},
+		},
+		{
+			expr:   `a.exists(k, a[k] == b)`,
+			policy: &CapabilityPolicy{DeniedFieldPaths: []string{"a.secret"}},
+			errs:   []string{"field path denied: a.*"},
+		},
+		{
+			expr:   `dyn(a).x == b`,
+			policy: &CapabilityPolicy{DeniedFieldPaths: []string{"b.secret"}},
+		},
+		{
+			expr:   `b.matches('x') || b.size() > 1`,
+			policy: &CapabilityPolicy{AllowedFunctions: []string{"_||_", "_>_", "string_size"}},
+			errs:   []string{"function not allowed: matches (matches_string)"},
+		},
+		{
+			expr:   `b.size() > 1`,
+			policy: &CapabilityPolicy{DeniedFunctions: []string{"size"}},
+			errs:   []string{"function denied: size (string_size)"},
+		},
+		{
+			expr:   `string(a.x) == dyn(b)`,
+			policy: &CapabilityPolicy{AllowedConversions: []string{"dyn"}},
+			errs:   []string{"conversion not allowed: string"},
+		},
+		{
+			expr:   `[1, 2].all(x, [3].exists(y, y > x))`,
+			policy: &CapabilityPolicy{MaxComprehensionDepth: 1},
+			errs:   []string{"comprehension depth 2 exceeds limit 1"},
+		},
+		{
+			expr:   `[1, 2].all(x, x > 0)`,
+			policy: &CapabilityPolicy{DisallowComprehensions: true},
+			errs:   []string{"comprehensions not allowed"},
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%q) failed: %v", tc.expr, iss.Err())
+			}
+			err := tc.policy.Enforce(ast)
+			if len(tc.errs) == 0 {
+				if err != nil {
+					t.Errorf("Enforce() failed: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Enforce() succeeded, wanted errors %v", tc.errs)
+			}
+			for _, e := range tc.errs {
+				if !strings.Contains(err.Error(), e) {
+					t.Errorf("Enforce() got %v, wanted error containing %q", err, e)
+				}
+			}
+		})
+	}
+}
@@ -16,11 +16,14 @@ package cel
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -29,6 +32,7 @@ import (
 	"google.golang.org/protobuf/reflect/protoreflect"
 
 	"github.com/google/cel-go/checker"
+	"github.com/google/cel-go/checker/decls"
 	"github.com/google/cel-go/common"
 	"github.com/google/cel-go/common/operators"
 	"github.com/google/cel-go/common/overloads"
@@ -36,11 +40,14 @@ import (
 	"github.com/google/cel-go/common/types/ref"
 	"github.com/google/cel-go/common/types/traits"
 	"github.com/google/cel-go/interpreter"
+	"github.com/google/cel-go/interpreter/functions"
+	"github.com/google/cel-go/parser"
 	"github.com/google/cel-go/test"
 
 	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 	descpb "google.golang.org/protobuf/types/descriptorpb"
 	dynamicpb "google.golang.org/protobuf/types/dynamicpb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
 
 	proto2pb "github.com/google/cel-go/test/proto2pb"
 	proto3pb "github.com/google/cel-go/test/proto3pb"
@@ -1188,6 +1195,7 @@ func BenchmarkEvalOptions(b *testing.B) {
 		"track-state":     OptTrackState,
 		"exhaustive-eval": OptExhaustiveEval,
 		"optimize":        OptOptimize,
+		"bytecode":        OptBytecode,
 	}
 	for k, opt := range opts {
 		b.Run(k, func(bb *testing.B) {
@@ -2322,6 +2330,68 @@ func TestOptionalValues(t *testing.T) {
 	}
 }
 
+func TestOptionalCombinators(t *testing.T) {
+	env, err := NewEnv(
+		OptionalTypes(),
+		Variable("m", MapType(StringType, DynType)),
+		Variable("x", OptionalType(IntType)),
+		Variable("y", OptionalType(IntType)),
+		Variable("l", ListType(OptionalType(IntType))),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		expr string
+		out  any
+	}{
+		{expr: `optional.ofNullable(m.?missing.orValue(null)) == optional.none()`, out: true},
+		{expr: `optional.ofNullable(m.nil).hasValue()`, out: false},
+		{expr: `optional.ofNullable(m.name).value()`, out: "alice"},
+		{expr: `x.optMap(v, v * 2).value()`, out: 84},
+		{expr: `y.optMap(v, v * 2).hasValue()`, out: false},
+		{expr: `x.optMap(v, string(v)).optMap(s, s + '!').orValue('')`, out: "42!"},
+		{expr: `x.optFilter(v, v > 40).value()`, out: 42},
+		{expr: `x.optFilter(v, v > 50).hasValue()`, out: false},
+		{expr: `y.optFilter(v, v > 40).hasValue()`, out: false},
+		{expr: `optional.firstPresent([y, m.?missing, x, optional.of(1 / 0)]).value()`, out: 42},
+		{expr: `optional.firstPresent([y, optional.none()]).hasValue()`, out: false},
+		{expr: `optional.firstPresent([]).hasValue()`, out: false},
+		{expr: `optional.firstPresent(l).value()`, out: 42},
+	}
+	vars := map[string]any{
+		"m": map[string]any{"name": "alice", "nil": structpb.NullValue_NULL_VALUE},
+		"x": types.OptionalOf(types.Int(42)),
+		"y": types.OptionalNone,
+		"l": []ref.Val{types.OptionalNone, types.OptionalOf(types.Int(42))},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%q) failed: %v", tc.expr, iss.Err())
+			}
+			prg, err := env.Program(ast)
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			out, _, err := prg.Eval(vars)
+			if err != nil {
+				t.Fatalf("prg.Eval() failed: %v", err)
+			}
+			want := env.TypeAdapter().NativeToValue(tc.out)
+			if out.Equal(want) != types.True {
+				t.Errorf("prg.Eval() got %v, wanted %v", out, want)
+			}
+		})
+	}
+	if _, iss := env.Compile(`x.optMap(v.w, v)`); iss.Err() == nil ||
+		!strings.Contains(iss.Err().Error(), "optMap() variable name must be a simple identifier") {
+		t.Errorf("env.Compile() got %v, wanted variable name error", iss.Err())
+	}
+}
+
 func BenchmarkOptionalValues(b *testing.B) {
 	env, err := NewEnv(
 		OptionalTypes(),
@@ -2421,6 +2491,229 @@ func BenchmarkDynamicDispatch(b *testing.B) {
 	})
 }
 
+func BenchmarkProtoFieldSelection(b *testing.B) {
+	env, err := NewEnv(
+		Types(&proto3pb.TestAllTypes{}),
+		Variable("msg", ObjectType("google.expr.proto3.test.TestAllTypes")),
+		Variable("dynMsg", DynType),
+	)
+	if err != nil {
+		b.Fatalf("NewEnv() failed: %v", err)
+	}
+	expr := `%[1]s.single_int64 + %[1]s.single_int32 + %[1]s.single_sfixed64 > 0
+		&& %[1]s.single_uint64 + %[1]s.single_fixed32 > 0u
+		&& %[1]s.single_double + %[1]s.single_float > 0.0
+		&& %[1]s.single_string != '' && %[1]s.single_bytes != b'' && %[1]s.single_bool`
+	// Selections from the statically typed message use the getters resolved when the program is
+	// planned, whereas selections from the dynamically typed message are resolved by reflection.
+	prg := compile(b, env, fmt.Sprintf(expr, "msg"))
+	prgDyn := compile(b, env, fmt.Sprintf(expr, "dynMsg"))
+	msg := &proto3pb.TestAllTypes{
+		SingleInt64:    1,
+		SingleInt32:    2,
+		SingleSfixed64: 3,
+		SingleUint64:   4,
+		SingleFixed32:  5,
+		SingleDouble:   6.5,
+		SingleFloat:    7.5,
+		SingleString:   "eight",
+		SingleBytes:    []byte("nine"),
+		SingleBool:     true,
+	}
+	vars := map[string]any{"msg": msg, "dynMsg": msg}
+	if out, _, err := prg.Eval(vars); out != types.True {
+		b.Fatalf("prg.Eval() got %v, %v, wanted true", out, err)
+	}
+	b.ResetTimer()
+	b.Run("Checked", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			prg.Eval(vars)
+		}
+	})
+	b.Run("Dyn", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			prgDyn.Eval(vars)
+		}
+	})
+}
+
+func TestBytecodeEval(t *testing.T) {
+	env, err := NewEnv(
+		Variable("x", IntType),
+		Variable("m", MapType(StringType, IntType)),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		expr string
+		out  ref.Val
+		err  string
+	}{
+		{expr: `x * 2 + m.a > 10 && [1, 2, x].exists(i, i == 3)`, out: types.True},
+		{expr: `x == 3 ? 'three' : 'other'`, out: types.String("three")},
+		{expr: `m.missing == 1 || x / 0 == 1`, err: "no such key"},
+		{expr: `m.missing == 1 || x > 0`, out: types.True},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%q) failed: %v", tc.expr, iss.Err())
+			}
+			for _, opts := range []EvalOption{OptBytecode, OptBytecode | OptOptimize} {
+				prg, err := env.Program(ast, EvalOptions(opts))
+				if err != nil {
+					t.Fatalf("env.Program() failed: %v", err)
+				}
+				out, _, err := prg.Eval(map[string]any{"x": 3, "m": map[string]int{"a": 5}})
+				if tc.err != "" {
+					if err == nil || !strings.Contains(err.Error(), tc.err) {
+						t.Errorf("prg.Eval() got %v, %v, wanted error %q", out, err, tc.err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("prg.Eval() failed: %v", err)
+				}
+				if out.Equal(tc.out) != types.True {
+					t.Errorf("prg.Eval() got %v, wanted %v", out, tc.out)
+				}
+			}
+		})
+	}
+}
+
+func TestScalarEval(t *testing.T) {
+	env, err := NewEnv(
+		Variable("x", IntType),
+		Variable("name", StringType),
+		Variable("m", MapType(StringType, IntType)),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		expr string
+		out  ref.Val
+		err  string
+	}{
+		{expr: `x * 2 + 1 > 6 && name.startsWith("req")`, out: types.True},
+		{expr: `x == 3 ? name + "!" : name`, out: types.String("request!")},
+		{expr: `x / 0 == 1 || x > 0`, out: types.True},
+		{expr: `x / (x - 3)`, err: "division by zero"},
+		{expr: `m.a + x`, out: types.Int(8)},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%q) failed: %v", tc.expr, iss.Err())
+			}
+			for _, opts := range []EvalOption{OptScalarEval, OptScalarEval | OptOptimize, OptScalarEval | OptTrackState} {
+				prg, err := env.Program(ast, EvalOptions(opts))
+				if err != nil {
+					t.Fatalf("env.Program() failed: %v", err)
+				}
+				out, _, err := prg.Eval(map[string]any{"x": 3, "name": "request", "m": map[string]int{"a": 5}})
+				if tc.err != "" {
+					if err == nil || !strings.Contains(err.Error(), tc.err) {
+						t.Errorf("prg.Eval() got %v, %v, wanted error %q", out, err, tc.err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("prg.Eval() failed: %v", err)
+				}
+				if out.Equal(tc.out) != types.True {
+					t.Errorf("prg.Eval() got %v, wanted %v", out, tc.out)
+				}
+			}
+		})
+	}
+}
+
+func TestScalarEvalDecorators(t *testing.T) {
+	env, err := NewEnv(Variable("x", IntType))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`x + 1 > 0`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	// The node timings are collected by a decorator which scalar evaluation must not bypass,
+	// whether or not the program is planned by a factory.
+	for _, opts := range []EvalOption{OptScalarEval, OptScalarEval | OptExhaustiveEval} {
+		timings := interpreter.NewNodeTimings(16)
+		prg, err := env.Program(ast, CollectNodeTimings(timings), EvalOptions(opts))
+		if err != nil {
+			t.Fatalf("env.Program() failed: %v", err)
+		}
+		if out, _, err := prg.Eval(map[string]any{"x": 1}); err != nil || out != types.True {
+			t.Fatalf("prg.Eval() got %v, %v, wanted true", out, err)
+		}
+		report := env.FormatNodeTimings(ast, timings, 1)
+		if !strings.Contains(report, "x + 1 > 0") {
+			t.Errorf("FormatNodeTimings() got %q, wanted the timing of the root expression", report)
+		}
+	}
+}
+
+func TestCacheAttributes(t *testing.T) {
+	env, err := NewEnv(
+		Variable("request", MapType(StringType, MapType(StringType, DynType))),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`request.user.id == 'alice' &&
+		request.user.roles.exists(r, r == 'admin' && request.user.id.startsWith('a')) &&
+		request.user.missing == 1`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	for _, opts := range []EvalOption{OptCacheAttributes, OptCacheAttributes | OptTrackState | OptPartialEval} {
+		prg, err := env.Program(ast, EvalOptions(opts))
+		if err != nil {
+			t.Fatalf("env.Program() failed: %v", err)
+		}
+		vars := &countingActivation{
+			vars: map[string]any{
+				"request": map[string]map[string]any{
+					"user": {"id": "alice", "roles": []string{"user", "admin"}},
+				},
+			},
+			counts: map[string]int{},
+		}
+		_, _, err = prg.Eval(vars)
+		if err == nil || !strings.Contains(err.Error(), "no such key: missing") {
+			t.Errorf("prg.Eval() got error %v, wanted 'no such key: missing'", err)
+		}
+		// Each distinct path is resolved once, including the path selected within the comprehension.
+		if vars.counts["request"] != 3 {
+			t.Errorf("prg.Eval() resolved 'request' %d times, wanted 3", vars.counts["request"])
+		}
+	}
+}
+
+type countingActivation struct {
+	vars   map[string]any
+	counts map[string]int
+}
+
+func (a *countingActivation) ResolveName(name string) (any, bool) {
+	a.counts[name]++
+	val, found := a.vars[name]
+	return val, found
+}
+
+func (a *countingActivation) Parent() interpreter.Activation {
+	return nil
+}
+
 func compile(t testing.TB, env *Env, expr string) Program {
 	t.Helper()
 	prg, err := compileOrError(t, env, expr)
@@ -2455,3 +2748,1423 @@ func interpret(t testing.TB, env *Env, expr string, vars any) (ref.Val, error) {
 	}
 	return out, nil
 }
+
+func TestWithFunctions(t *testing.T) {
+	env, err := NewEnv(
+		Variable("key", StringType),
+		Function("lookup",
+			Overload("lookup_string", []*Type{StringType}, StringType,
+				UnaryBinding(func(arg ref.Val) ref.Val {
+					return types.String("default")
+				})),
+			Rebindable()),
+		Function("describe",
+			Overload("describe_string", []*Type{StringType}, StringType,
+				UnaryBinding(func(arg ref.Val) ref.Val {
+					return arg
+				}))),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`describe(lookup(key)) + ':' + [key].map(k, lookup(k + '2'))[0]`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	lookup := func(prefix string) *functions.Overload {
+		return &functions.Overload{
+			Operator: "lookup_string",
+			Unary: func(arg ref.Val) ref.Val {
+				return types.String(prefix).Add(arg)
+			},
+		}
+	}
+	for _, opts := range []ProgramOption{
+		EvalOptions(OptOptimize),
+		EvalOptions(OptTrackState),
+		Globals(map[string]any{"key": "global"}),
+	} {
+		base, err := env.Program(ast, opts)
+		if err != nil {
+			t.Fatalf("env.Program() failed: %v", err)
+		}
+		vars := map[string]any{"key": "a"}
+		out, _, err := base.Eval(vars)
+		if err != nil || out.Equal(types.String("default:default")) != types.True {
+			t.Errorf("base.Eval() got %v, %v, wanted 'default:default'", out, err)
+		}
+		alice, err := WithFunctions(base, lookup("alice-"))
+		if err != nil {
+			t.Fatalf("WithFunctions(base) failed: %v", err)
+		}
+		bob, err := WithFunctions(alice, lookup("bob-"))
+		if err != nil {
+			t.Fatalf("WithFunctions(alice) failed: %v", err)
+		}
+		out, _, err = alice.Eval(vars)
+		if err != nil || out.Equal(types.String("alice-a:alice-a2")) != types.True {
+			t.Errorf("alice.Eval() got %v, %v, wanted 'alice-a:alice-a2'", out, err)
+		}
+		out, _, err = bob.ContextEval(context.Background(), vars)
+		if err != nil || out.Equal(types.String("bob-a:bob-a2")) != types.True {
+			t.Errorf("bob.ContextEval() got %v, %v, wanted 'bob-a:bob-a2'", out, err)
+		}
+		out, _, err = base.Eval(vars)
+		if err != nil || out.Equal(types.String("default:default")) != types.True {
+			t.Errorf("base.Eval() after WithFunctions() got %v, %v, wanted 'default:default'", out, err)
+		}
+		_, err = WithFunctions(base, &functions.Overload{
+			Operator: "describe_string",
+			Unary:    func(arg ref.Val) ref.Val { return arg },
+		})
+		if err == nil || !strings.Contains(err.Error(), "overload is not rebindable: describe_string") {
+			t.Errorf("WithFunctions(base, describe_string) got %v, wanted not rebindable error", err)
+		}
+		// Programs implemented outside of the package do not support function bindings.
+		_, err = WithFunctions(struct{ Program }{base}, lookup("carol-"))
+		if err == nil || !strings.Contains(err.Error(), "struct { cel.Program }") {
+			t.Errorf("WithFunctions() of an external program got %v, wanted error naming its type", err)
+		}
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	env, err := NewEnv(Variable("payload", BytesType))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`size(string(payload) + string(payload)) > 0 && [1, 2, 3].map(x, [x, x]).size() == 3`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast, MemoryLimit(1000))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	out, det, err := prg.Eval(map[string]any{"payload": []byte("hello")})
+	if err != nil || out != types.True {
+		t.Fatalf("prg.Eval() got %v, %v, wanted true", out, err)
+	}
+	// 5 + 5 + 10 bytes of strings, three 2-element lists, and the 3-element list literal and result.
+	if mem := det.ActualMemory(); mem == nil || *mem < 20 || *mem > 1000 {
+		t.Errorf("det.ActualMemory() got %v, wanted a value between 20 and 1000", mem)
+	}
+
+	_, _, err = prg.Eval(map[string]any{"payload": make([]byte, 600)})
+	cancelled, ok := err.(interpreter.EvalCancelledError)
+	if !ok || cancelled.Cause != interpreter.MemoryLimitExceeded {
+		t.Fatalf("prg.Eval() got error %v, wanted memory limit exceeded", err)
+	}
+}
+
+func TestCostBudget(t *testing.T) {
+	env, err := NewEnv(Variable("items", ListType(IntType)))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`items.exists(i, i > 100)`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	budget := interpreter.NewCostBudget(100)
+	prg, err := env.Program(ast, CostBudget(budget))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	vars := map[string]any{"items": []int{1, 2, 3}}
+	out, det, err := prg.Eval(vars)
+	if err != nil || out != types.False {
+		t.Fatalf("prg.Eval() got %v, %v, wanted false", out, err)
+	}
+	cost := *det.ActualCost()
+	if budget.Remaining() != 100-cost {
+		t.Errorf("budget.Remaining() got %d, wanted %d", budget.Remaining(), 100-cost)
+	}
+	evals := 1
+	for ; evals < 100; evals++ {
+		_, _, err = prg.Eval(vars)
+		if err != nil {
+			break
+		}
+	}
+	cancelled, ok := err.(interpreter.EvalCancelledError)
+	if !ok || cancelled.Cause != interpreter.CostBudgetExceeded {
+		t.Fatalf("prg.Eval() got error %v, wanted cost budget exceeded", err)
+	}
+	if evals != int(100/cost) {
+		t.Errorf("budget exhausted after %d evaluations, wanted %d", evals, 100/cost)
+	}
+	if budget.Remaining() != 0 {
+		t.Errorf("budget.Remaining() got %d, wanted 0", budget.Remaining())
+	}
+	budget.Reset(100)
+	if _, _, err := prg.Eval(vars); err != nil {
+		t.Errorf("prg.Eval() after budget.Reset() failed: %v", err)
+	}
+	if _, err := env.Program(ast, CostBudget(nil)); err == nil {
+		t.Error("env.Program() with a nil budget succeeded, wanted error")
+	}
+}
+
+func TestParallelExhaustiveEval(t *testing.T) {
+	// The rendezvous function only returns true when the expected number of calls are in flight.
+	const calls = 4
+	var mu sync.Mutex
+	arrived := 0
+	ready := make(chan struct{})
+	rendezvous := func(arg ref.Val) ref.Val {
+		mu.Lock()
+		arrived++
+		if arrived == calls {
+			close(ready)
+		}
+		mu.Unlock()
+		select {
+		case <-ready:
+			return arg
+		case <-time.After(5 * time.Second):
+			return types.NewErr("rendezvous timed out")
+		}
+	}
+	env, err := NewEnv(
+		Variable("x", IntType),
+		Function("rendezvous",
+			Overload("rendezvous_int", []*Type{IntType}, IntType, UnaryBinding(rendezvous))),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`rendezvous(x) == 1 && [rendezvous(x + 1), rendezvous(x + 2)] == [2, 3] ||
+		{'a': rendezvous(x + 3)}.a == 0`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast, EvalOptions(OptExhaustiveEval), ParallelExhaustiveEval(calls))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	out, det, err := prg.Eval(map[string]any{"x": 1})
+	if err != nil || out != types.True {
+		t.Fatalf("prg.Eval() got %v, %v, wanted true", out, err)
+	}
+	// Exhaustive evaluation records the value of each subexpression.
+	if _, found := det.State().Value(ast.Expr().GetCallExpr().GetArgs()[1].GetId()); !found {
+		t.Error("prg.Eval() did not record the state of the right-hand operand")
+	}
+
+	// Lazy variables are resolved once per evaluation, even when referenced concurrently.
+	ast, iss = env.Compile(`[x, x + 1, x + 2, x + 3] == [1, 2, 3, 4]`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err = env.Program(ast, EvalOptions(OptExhaustiveEval), ParallelExhaustiveEval(calls))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		var resolved int32
+		out, _, err := prg.Eval(map[string]any{"x": func() ref.Val {
+			atomic.AddInt32(&resolved, 1)
+			return types.Int(1)
+		}})
+		if err != nil || out != types.True {
+			t.Fatalf("prg.Eval() got %v, %v, wanted true", out, err)
+		}
+		if resolved != 1 {
+			t.Fatalf("lazy variable resolved %d times, wanted 1", resolved)
+		}
+	}
+}
+
+func TestParallelExhaustiveEvalErrors(t *testing.T) {
+	env, err := NewEnv(Variable("s", StringType))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`[s + s, s + s + s].size() == 2 || s == ''`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	invalid := [][]ProgramOption{
+		{ParallelExhaustiveEval(2)},
+		{EvalOptions(OptExhaustiveEval), ParallelExhaustiveEval(0)},
+		{EvalOptions(OptExhaustiveEval, OptTrackCost), ParallelExhaustiveEval(2)},
+	}
+	for _, opts := range invalid {
+		if _, err := env.Program(ast, opts...); err == nil {
+			t.Errorf("env.Program() with invalid parallel evaluation options succeeded, wanted error")
+		}
+	}
+
+	// Cancellation within a concurrently evaluated subexpression is reported by Eval.
+	prg, err := env.Program(ast, EvalOptions(OptExhaustiveEval), ParallelExhaustiveEval(2), MemoryLimit(100))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	_, _, err = prg.Eval(map[string]any{"s": strings.Repeat("x", 20)})
+	cancelled, ok := err.(interpreter.EvalCancelledError)
+	if !ok || cancelled.Cause != interpreter.MemoryLimitExceeded {
+		t.Errorf("prg.Eval() got error %v, wanted memory limit exceeded", err)
+	}
+}
+
+func TestActualCostByOverload(t *testing.T) {
+	env, err := NewEnv(
+		Variable("names", ListType(StringType)),
+		Function("lookup",
+			Overload("lookup_string", []*Type{StringType}, StringType,
+				UnaryBinding(func(arg ref.Val) ref.Val {
+					return arg
+				}))),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`names.all(n, lookup(n).startsWith('a'))`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast, CostTracking(overloadCostEstimator{"lookup_string": 10}))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	_, det, err := prg.Eval(map[string]any{"names": []string{"alice", "anne", "arthur"}})
+	if err != nil {
+		t.Fatalf("prg.Eval() failed: %v", err)
+	}
+	costs := det.ActualCostByOverload()
+	if got, want := costs["lookup_string"], (interpreter.OverloadCost{Function: "lookup", Calls: 3, Cost: 30}); got != want {
+		t.Errorf("ActualCostByOverload()[lookup_string] got %+v, wanted %+v", got, want)
+	}
+	if got := costs[overloads.StartsWithString]; got.Calls != 3 || got.Function != overloads.StartsWith {
+		t.Errorf("ActualCostByOverload()[%s] got %+v, wanted 3 calls", overloads.StartsWithString, got)
+	}
+}
+
+type overloadCostEstimator map[string]uint64
+
+func (e overloadCostEstimator) CallCost(function, overloadID string, args []ref.Val, result ref.Val) *uint64 {
+	if cost, found := e[overloadID]; found {
+		return &cost
+	}
+	return nil
+}
+
+func TestFlatAttributes(t *testing.T) {
+	env, err := NewEnv(
+		Variable("request", MapType(StringType, DynType)),
+		Variable("key", StringType),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		expr      string
+		separator string
+		vars      map[string]any
+		out       ref.Val
+		err       string
+	}{
+		{
+			expr:      `request.user.id == 'alice' && request.user['id'] == 'alice'`,
+			separator: ".",
+			vars:      map[string]any{"request.user.id": "alice"},
+			out:       types.True,
+		},
+		{
+			expr:      `request.user.id`,
+			separator: "/",
+			vars:      map[string]any{"request/user/id": "bob"},
+			out:       types.String("bob"),
+		},
+		{
+			expr:      `request.user.groups[0] + ':' + request.user.claims[key]`,
+			separator: ".",
+			vars: map[string]any{
+				"request.user.groups": []string{"admin"},
+				"request.user.claims": map[string]string{"email": "a@example.com"},
+				"key":                 "email",
+			},
+			out: types.String("admin:a@example.com"),
+		},
+		{
+			expr:      `has(request.user.id) && !has(request.user.name) && has(request.auth.token)`,
+			separator: ".",
+			vars: map[string]any{
+				"request.user.id": "alice",
+				"request.auth":    map[string]string{"token": "t"},
+			},
+			out: types.True,
+		},
+		{
+			expr:      `request.user.name`,
+			separator: ".",
+			vars:      map[string]any{"request.user.id": "alice"},
+			err:       "no such attribute",
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%q) failed: %v", tc.expr, iss.Err())
+			}
+			for _, opts := range [][]ProgramOption{
+				{FlatAttributes(tc.separator)},
+				{FlatAttributes(tc.separator), EvalOptions(OptCacheAttributes, OptOptimize)},
+			} {
+				prg, err := env.Program(ast, opts...)
+				if err != nil {
+					t.Fatalf("env.Program() failed: %v", err)
+				}
+				out, _, err := prg.Eval(tc.vars)
+				if tc.err != "" {
+					if err == nil || !strings.Contains(err.Error(), tc.err) {
+						t.Errorf("prg.Eval() got %v, %v, wanted error containing %q", out, err, tc.err)
+					}
+					continue
+				}
+				if err != nil || out.Equal(tc.out) != types.True {
+					t.Errorf("prg.Eval() got %v, %v, wanted %v", out, err, tc.out)
+				}
+			}
+		})
+	}
+
+	// Unknown attribute patterns apply to the structured attribute path.
+	ast, iss := env.Compile(`request.user.id == 'alice' || request.auth.token == 't'`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast, FlatAttributes("."), EvalOptions(OptPartialEval))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	vars, err := PartialVars(map[string]any{"request.auth.token": "x"},
+		AttributePattern("request").QualString("user"))
+	if err != nil {
+		t.Fatalf("PartialVars() failed: %v", err)
+	}
+	out, _, err := prg.Eval(vars)
+	if err != nil || !types.IsUnknown(out) {
+		t.Errorf("prg.Eval() got %v, %v, wanted unknown", out, err)
+	}
+
+	if _, err := env.Program(ast, FlatAttributes("")); err == nil {
+		t.Error("env.Program() with an empty separator succeeded, wanted error")
+	}
+}
+
+func TestGuardOverloads(t *testing.T) {
+	env, err := NewEnv(
+		Variable("input", StringType),
+		Function("shout",
+			Overload("shout_string", []*Type{StringType}, StringType,
+				UnaryBinding(func(arg ref.Val) ref.Val {
+					return types.String(strings.ToUpper(string(arg.(types.String))))
+				})),
+		),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	type ctxKey struct{}
+	var seenCtx []any
+	sizeGuard := func(ctx context.Context, overloadID string, args []ref.Val) ref.Val {
+		seenCtx = append(seenCtx, ctx.Value(ctxKey{}))
+		if len(args[0].(types.String)) > 5 {
+			return types.NewErr("%s: argument exceeds 5 characters", overloadID)
+		}
+		return nil
+	}
+	checked, iss := env.Compile(`shout(input) + '!'`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	parsed, iss := env.Parse(`shout(input) + '!'`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Parse() failed: %v", iss.Err())
+	}
+	tests := []struct {
+		ast  *Ast
+		opts []ProgramOption
+	}{
+		{ast: checked, opts: []ProgramOption{GuardOverloads(sizeGuard, "shout_string")}},
+		{ast: checked, opts: []ProgramOption{GuardOverloads(sizeGuard, "shout_string"), EvalOptions(OptTrackState)}},
+		{ast: parsed, opts: []ProgramOption{GuardOverloads(sizeGuard, "shout")}},
+	}
+	for _, tc := range tests {
+		prg, err := env.Program(tc.ast, tc.opts...)
+		if err != nil {
+			t.Fatalf("env.Program() failed: %v", err)
+		}
+		seenCtx = nil
+		ctx := context.WithValue(context.Background(), ctxKey{}, "request-1")
+		out, _, err := prg.ContextEval(ctx, map[string]any{"input": "hi"})
+		if err != nil || out.Equal(types.String("HI!")) != types.True {
+			t.Errorf("prg.ContextEval() got %v, %v, wanted HI!", out, err)
+		}
+		_, _, err = prg.Eval(map[string]any{"input": "too long"})
+		if err == nil || !strings.Contains(err.Error(), "argument exceeds 5 characters") {
+			t.Errorf("prg.Eval() got error %v, wanted guard error", err)
+		}
+		if want := []any{"request-1", nil}; !reflect.DeepEqual(seenCtx, want) {
+			t.Errorf("guard observed contexts %v, wanted %v", seenCtx, want)
+		}
+	}
+
+	// The standard library overloads may also be guarded.
+	var calls int
+	countGuard := func(ctx context.Context, overloadID string, args []ref.Val) ref.Val {
+		calls++
+		return nil
+	}
+	prg, err := env.Program(checked, GuardOverloads(countGuard, "add_string"), GuardOverloads(countGuard, "add_string"))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	if _, _, err := prg.Eval(map[string]any{"input": "hi"}); err != nil {
+		t.Fatalf("prg.Eval() failed: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("guards invoked %d times, wanted 2", calls)
+	}
+
+	// Scalar evaluation must not bypass the guards of the standard library overloads.
+	env, err = NewEnv(Variable("x", IntType))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`x + 1 > 0`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	denyGuard := func(ctx context.Context, overloadID string, args []ref.Val) ref.Val {
+		return types.NewErr("%s: denied", overloadID)
+	}
+	prg, err = env.Program(ast, GuardOverloads(denyGuard, "add_int64"), EvalOptions(OptScalarEval))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	if out, _, err := prg.Eval(map[string]any{"x": 1}); err == nil || !strings.Contains(err.Error(), "add_int64: denied") {
+		t.Errorf("prg.Eval() with OptScalarEval got %v, %v, wanted guard error", out, err)
+	}
+}
+
+func TestContextFunctionBinding(t *testing.T) {
+	type tenantKey struct{}
+	tenant := func(ctx context.Context) ref.Val {
+		if err := ctx.Err(); err != nil {
+			return types.NewErr("lookup failed: %v", err)
+		}
+		if t, found := ctx.Value(tenantKey{}).(string); found {
+			return types.String(t)
+		}
+		return types.String("default")
+	}
+	env, err := NewEnv(
+		Variable("key", DynType),
+		Function("lookup",
+			Overload("lookup_string", []*Type{StringType}, StringType,
+				ContextFunctionBinding(func(ctx context.Context, args ...ref.Val) ref.Val {
+					t := tenant(ctx)
+					if types.IsError(t) {
+						return t
+					}
+					return t.(types.String) + "/" + args[0].(types.String)
+				})),
+			Overload("lookup_int", []*Type{IntType}, StringType,
+				UnaryBinding(func(arg ref.Val) ref.Val {
+					return types.String(fmt.Sprintf("id/%d", arg.(types.Int)))
+				})),
+		),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	tests := []struct {
+		expr string
+		ctx  context.Context
+		key  any
+		out  ref.Val
+		err  string
+	}{
+		{expr: `lookup('user')`, ctx: ctx, out: types.String("acme/user")},
+		{expr: `lookup('user')`, out: types.String("default/user")},
+		{expr: `lookup(key)`, ctx: ctx, key: "user", out: types.String("acme/user")},
+		{expr: `lookup(key)`, ctx: ctx, key: 7, out: types.String("id/7")},
+		{expr: `lookup('user')`, ctx: cancelled, err: "context canceled"},
+	}
+	for _, tc := range tests {
+		ast, iss := env.Compile(tc.expr)
+		if iss.Err() != nil {
+			t.Fatalf("env.Compile(%q) failed: %v", tc.expr, iss.Err())
+		}
+		for _, opts := range [][]ProgramOption{{}, {EvalOptions(OptTrackState, OptTrackCost)}} {
+			prg, err := env.Program(ast, opts...)
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			vars := map[string]any{"key": tc.key}
+			var out ref.Val
+			if tc.ctx != nil {
+				out, _, err = prg.ContextEval(tc.ctx, vars)
+			} else {
+				out, _, err = prg.Eval(vars)
+			}
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Errorf("%s got error %v, wanted %q", tc.expr, err, tc.err)
+				}
+				continue
+			}
+			if err != nil || out.Equal(tc.out) != types.True {
+				t.Errorf("%s got %v, %v, wanted %v", tc.expr, out, err, tc.out)
+			}
+		}
+	}
+}
+
+func TestInfixOperators(t *testing.T) {
+	env, err := NewEnv(
+		Variable("name", StringType),
+		InfixOperators(parser.InfixOperator{
+			Symbol:     "=~",
+			Function:   overloads.Matches,
+			Precedence: parser.RelationalPrecedence,
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`name =~ '^[a-z]+$' && !(name + '1' =~ '^[a-z]+$')`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	out, _, err := prg.Eval(map[string]any{"name": "alpha"})
+	if err != nil || out != types.True {
+		t.Errorf("prg.Eval() got %v, %v, wanted true", out, err)
+	}
+	_, iss = env.Compile(`name =~ 1`)
+	wantErr := "ERROR: <input>:1:6: found no matching overload for 'matches' applied to '(string, int)'\n" +
+		" | name =~ 1\n" +
+		" | .....^"
+	if iss.Err() == nil || iss.Err().Error() != wantErr {
+		t.Errorf("env.Compile() got %v, wanted %q", iss.Err(), wantErr)
+	}
+}
+
+func TestInterceptQualifiers(t *testing.T) {
+	env, err := NewEnv(Variable("policy", MapType(StringType, DynType)))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`policy.max_count > 2 && policy.max_count < 10`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	// Qualify version 2 of the policy schema, which renamed `max_count` to `limit`.
+	aliases := interpreter.QualifierInterceptorFunc(func(obj any, qual ref.Val) ref.Val {
+		if qual == types.String("max_count") {
+			return types.String("limit")
+		}
+		return qual
+	})
+	for _, opts := range [][]ProgramOption{
+		{InterceptQualifiers(aliases)},
+		{InterceptQualifiers(aliases), EvalOptions(OptCacheAttributes)},
+	} {
+		prg, err := env.Program(ast, opts...)
+		if err != nil {
+			t.Fatalf("env.Program() failed: %v", err)
+		}
+		out, _, err := prg.Eval(map[string]any{"policy": map[string]any{"limit": 5}})
+		if err != nil || out != types.True {
+			t.Errorf("prg.Eval() got %v, %v, wanted true", out, err)
+		}
+	}
+	if _, err := env.Program(ast, InterceptQualifiers(nil)); err == nil {
+		t.Error("env.Program() with a nil interceptor succeeded, wanted error")
+	}
+}
+
+func TestProgramWarmup(t *testing.T) {
+	calls := 0
+	env, err := NewEnv(
+		Variable("x", BoolType),
+		Function("touch",
+			Overload("touch", []*Type{}, BoolType, OverloadIsPure(),
+				FunctionBinding(func(args ...ref.Val) ref.Val {
+					calls++
+					return types.True
+				}))),
+		Function("boom",
+			Overload("boom", []*Type{}, BoolType,
+				FunctionBinding(func(args ...ref.Val) ref.Val {
+					panic("boom")
+				}))),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`x ? true : touch()`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	for _, opts := range [][]ProgramOption{
+		{},
+		{EvalOptions(OptTrackState)},
+		{EvalOptions(OptOptimize | OptBytecode)},
+	} {
+		prg, err := env.Program(ast, opts...)
+		if err != nil {
+			t.Fatalf("env.Program() failed: %v", err)
+		}
+		// The warm-up evaluation follows the branches taken for the sample input.
+		calls = 0
+		if err := Warmup(prg, map[string]any{"x": false}); err != nil {
+			t.Errorf("Warmup() failed: %v", err)
+		}
+		if err := Warmup(prg, map[string]any{"x": true}); err != nil {
+			t.Errorf("Warmup() failed: %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("Warmup() called touch() %d times, wanted 1", calls)
+		}
+		// Errors produced by the expression are discarded.
+		if err := Warmup(prg, map[string]any{}); err != nil {
+			t.Errorf("Warmup() with missing variables failed: %v", err)
+		}
+		if err := Warmup(prg, "x"); err == nil {
+			t.Error("Warmup() with invalid input succeeded, wanted error")
+		}
+	}
+
+	// Patchers, guards, and memoization are left out of the warm-up evaluation.
+	patches, guarded := 0, 0
+	cache := NewCallCache(time.Hour, 0)
+	prg, err := env.Program(ast,
+		PatchEval(func(id int64, programStep any, val ref.Val) (ref.Val, bool) {
+			patches++
+			return val, false
+		}),
+		GuardOverloads(func(ctx context.Context, overloadID string, args []ref.Val) ref.Val {
+			guarded++
+			return nil
+		}, "touch"),
+		SharedCallCache(cache))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	calls = 0
+	if err := Warmup(prg, map[string]any{"x": false}); err != nil {
+		t.Errorf("Warmup() failed: %v", err)
+	}
+	if calls != 1 || patches != 0 || guarded != 0 {
+		t.Errorf("Warmup() got %d calls, %d patches, %d guards, wanted 1, 0, 0", calls, patches, guarded)
+	}
+	// The call is not memoized by the warm-up evaluation, so the first evaluation calls touch().
+	out, _, err := prg.Eval(map[string]any{"x": false})
+	if err != nil || out != types.True {
+		t.Errorf("prg.Eval() got %v, %v, wanted true", out, err)
+	}
+	if calls != 2 || patches == 0 || guarded != 1 {
+		t.Errorf("prg.Eval() got %d calls, %d patches, %d guards, wanted 2, >0, 1", calls, patches, guarded)
+	}
+
+	// The results of the warm-up evaluation are not cached by incremental programs.
+	incremental, err := env.IncrementalProgram(ast)
+	if err != nil {
+		t.Fatalf("env.IncrementalProgram() failed: %v", err)
+	}
+	calls = 0
+	if err := Warmup(incremental, map[string]any{"x": false}); err != nil {
+		t.Errorf("Warmup() failed: %v", err)
+	}
+	if out, _, err := incremental.Eval(map[string]any{"x": false}); err != nil || out != types.True {
+		t.Errorf("incremental.Eval() got %v, %v, wanted true", out, err)
+	}
+	if calls != 2 {
+		t.Errorf("incremental.Eval() after warm-up called touch() %d times, wanted 2", calls)
+	}
+
+	// The sample input is layered over the default variables of the program.
+	prg, err = env.Program(ast, Globals(map[string]any{"x": false}))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	calls = 0
+	if err := Warmup(prg, map[string]any{}); err != nil {
+		t.Errorf("Warmup() failed: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Warmup() with default variables called touch() %d times, wanted 1", calls)
+	}
+
+	// Panics and cancellations are recovered and returned as errors.
+	boom, iss := env.Compile(`x || boom()`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err = env.Program(boom)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	if err := Warmup(prg, map[string]any{"x": false}); err == nil || !strings.Contains(err.Error(), "internal error: boom") {
+		t.Errorf("Warmup() of a panicking function got %v, wanted internal error", err)
+	}
+	costly, iss := env.Compile(`[1, 2, 3].map(i, i * 2).size() == 3`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err = env.Program(costly, CostLimit(1))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	var cancelled interpreter.EvalCancelledError
+	if err := Warmup(prg, map[string]any{}); !errors.As(err, &cancelled) {
+		t.Errorf("Warmup() exceeding the cost limit got %v, wanted cancellation", err)
+	}
+
+	// Programs implemented outside of the package do not support warm-up.
+	prg, err = env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	wrapped := struct{ Program }{prg}
+	err = Warmup(wrapped, map[string]any{"x": true})
+	if err == nil || !strings.Contains(err.Error(), "struct { cel.Program }") {
+		t.Errorf("Warmup() of an external program got %v, wanted error naming its type", err)
+	}
+}
+
+func TestNullSafeComparisons(t *testing.T) {
+	tests := []struct {
+		expr string
+		out  ref.Val
+	}{
+		{expr: `x < 1`, out: types.False},
+		{expr: `x <= 1`, out: types.False},
+		{expr: `1 > x`, out: types.False},
+		{expr: `x >= x`, out: types.False},
+		{expr: `!(x < 1)`, out: types.True},
+		{expr: `x == null`, out: types.True},
+		{expr: `y < 1`, out: types.True},
+		{expr: `msg.single_int64_wrapper > 0 || msg.single_int32 >= 0`, out: types.True},
+	}
+	env, err := NewEnv(
+		Variable("x", DynType),
+		Variable("y", IntType),
+		Variable("msg", ObjectType("google.expr.proto3.test.TestAllTypes")),
+		Types(&proto3pb.TestAllTypes{}),
+		NullSafeComparisons(true))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	vars := map[string]any{"x": structpb.NullValue_NULL_VALUE, "y": 0, "msg": &proto3pb.TestAllTypes{}}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%q) failed: %v", tc.expr, iss.Err())
+			}
+			for _, opt := range []EvalOption{OptOptimize, OptExhaustiveEval} {
+				prg, err := env.Program(ast, EvalOptions(opt))
+				if err != nil {
+					t.Fatalf("env.Program() failed: %v", err)
+				}
+				out, _, err := prg.Eval(vars)
+				if err != nil {
+					t.Fatalf("prg.Eval() failed: %v", err)
+				}
+				if out.Equal(tc.out) != types.True {
+					t.Errorf("prg.Eval() got %v, wanted %v", out, tc.out)
+				}
+			}
+		})
+	}
+
+	env, err = env.Extend(NullSafeComparisons(false))
+	if err != nil {
+		t.Fatalf("env.Extend() failed: %v", err)
+	}
+	out, err := interpret(t, env, `x < 1`, vars)
+	if err == nil {
+		t.Errorf("x < 1 got %v, wanted a no such overload error", out)
+	}
+}
+
+func (p *opaqueTypeProvider) FindType(typeName string) (*exprpb.Type, bool) {
+	if typeName != p.typeName {
+		return nil, false
+	}
+	return decls.NewTypeType(decls.NewAbstractType(typeName)), true
+}
+
+func TestSortedMapIteration(t *testing.T) {
+	env, err := NewEnv(Variable("m", MapType(StringType, IntType)))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`m.map(k, k + '=' + string(m[k])).exists(s, s == 'a=1') ? m.map(k, k) : []`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast, EvalOptions(OptSortedMapIteration, OptOptimize))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	m := map[string]int64{}
+	for i, k := range strings.Split("qwertyuiopasdfghjklzxcvbnm", "") {
+		m[k] = int64(i)
+	}
+	m["a"] = 1
+	want := strings.Split("abcdefghijklmnopqrstuvwxyz", "")
+	for i := 0; i < 10; i++ {
+		out, _, err := prg.Eval(map[string]any{"m": m})
+		if err != nil {
+			t.Fatalf("prg.Eval() failed: %v", err)
+		}
+		got, err := out.ConvertToNative(reflect.TypeOf([]string{}))
+		if err != nil {
+			t.Fatalf("ConvertToNative() failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("prg.Eval() got %v, wanted %v", got, want)
+		}
+	}
+}
+
+func TestPatchEval(t *testing.T) {
+	env, err := NewEnv(Variable("req", MapType(StringType, IntType)))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`req.size > 10`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	selectID := ast.Expr().GetCallExpr().GetArgs()[0].GetId()
+	prg, err := env.Program(ast, EvalOptions(OptTrackState),
+		PatchEval(func(id int64, programStep any, val ref.Val) (ref.Val, bool) {
+			if id == selectID {
+				return types.Int(42), true
+			}
+			return val, false
+		}))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	out, det, err := prg.Eval(map[string]any{"req": map[string]int{"size": 1}})
+	if err != nil {
+		t.Fatalf("prg.Eval() failed: %v", err)
+	}
+	if out != types.True {
+		t.Errorf("prg.Eval() got %v, wanted true", out)
+	}
+	if val, found := det.State().Value(selectID); !found || val != types.Int(42) {
+		t.Errorf("state.Value(%d) got %v, wanted 42", selectID, val)
+	}
+
+	_, err = env.Program(ast, PatchEval(nil))
+	if err == nil {
+		t.Error("env.Program() with a nil patcher succeeded, wanted error")
+	}
+
+	// Scalar evaluation must not bypass the patchers.
+	env, err = NewEnv(Variable("x", IntType))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss = env.Compile(`x + 1`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	identID := ast.Expr().GetCallExpr().GetArgs()[0].GetId()
+	prg, err = env.Program(ast, EvalOptions(OptScalarEval),
+		PatchEval(func(id int64, programStep any, val ref.Val) (ref.Val, bool) {
+			if id == identID {
+				return types.Int(42), true
+			}
+			return val, false
+		}))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	out, _, err = prg.Eval(map[string]any{"x": 1})
+	if err != nil || out != types.Int(43) {
+		t.Errorf("prg.Eval() with OptScalarEval got %v, %v, wanted 43", out, err)
+	}
+}
+
+func TestIntegerOverflowPolicy(t *testing.T) {
+	tests := []struct {
+		expr     string
+		wrap     ref.Val
+		saturate ref.Val
+	}{
+		{expr: `9223372036854775807 + x`, wrap: types.Int(math.MinInt64), saturate: types.Int(math.MaxInt64)},
+		{expr: `-9223372036854775808 - x`, wrap: types.Int(math.MaxInt64), saturate: types.Int(math.MinInt64)},
+		{expr: `-9223372036854775807 * (x + 1)`, wrap: types.Int(2), saturate: types.Int(math.MinInt64)},
+		{expr: `-(-9223372036854775807 - x)`, wrap: types.Int(math.MinInt64), saturate: types.Int(math.MaxInt64)},
+		{expr: `(-9223372036854775807 - x) / -x`, wrap: types.Int(math.MinInt64), saturate: types.Int(math.MaxInt64)},
+		{expr: `uint(x) - 2u`, wrap: types.Uint(math.MaxUint64), saturate: types.Uint(0)},
+		{expr: `18446744073709551615u * uint(x + 1)`, wrap: types.Uint(math.MaxUint64 - 1), saturate: types.Uint(math.MaxUint64)},
+		{expr: `x + 1`, wrap: types.Int(2), saturate: types.Int(2)},
+		{expr: `1.5 + double(x)`, wrap: types.Double(2.5), saturate: types.Double(2.5)},
+	}
+	policies := map[string]interpreter.OverflowPolicy{
+		"wrap":     interpreter.OverflowWrap,
+		"saturate": interpreter.OverflowSaturate,
+	}
+	for name, policy := range policies {
+		env, err := NewEnv(Variable("x", IntType), IntegerOverflowPolicy(policy))
+		if err != nil {
+			t.Fatalf("NewEnv() failed: %v", err)
+		}
+		for _, tst := range tests {
+			want := tst.wrap
+			if policy == interpreter.OverflowSaturate {
+				want = tst.saturate
+			}
+			ast, iss := env.Compile(tst.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%q) failed: %v", tst.expr, iss.Err())
+			}
+			prg, err := env.Program(ast)
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			out, _, err := prg.Eval(map[string]any{"x": 1})
+			if err != nil {
+				t.Fatalf("%s: prg.Eval(%q) failed: %v", name, tst.expr, err)
+			}
+			if out.Equal(want) != types.True {
+				t.Errorf("%s: prg.Eval(%q) got %v, wanted %v", name, tst.expr, out, want)
+			}
+		}
+	}
+
+	// The default policy produces an error, as does division by zero under every policy.
+	env, err := NewEnv(Variable("x", IntType))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	wrapEnv, err := env.Extend(IntegerOverflowPolicy(interpreter.OverflowWrap))
+	if err != nil {
+		t.Fatalf("env.Extend() failed: %v", err)
+	}
+	errTests := []struct {
+		env  *Env
+		expr string
+	}{
+		{env: env, expr: `9223372036854775807 + x`},
+		{env: wrapEnv, expr: `x / (x - 1)`},
+	}
+	for _, tst := range errTests {
+		ast, iss := tst.env.Compile(tst.expr)
+		if iss.Err() != nil {
+			t.Fatalf("env.Compile(%q) failed: %v", tst.expr, iss.Err())
+		}
+		prg, err := tst.env.Program(ast)
+		if err != nil {
+			t.Fatalf("env.Program() failed: %v", err)
+		}
+		if out, _, err := prg.Eval(map[string]any{"x": 1}); err == nil {
+			t.Errorf("prg.Eval(%q) got %v, wanted error", tst.expr, out)
+		}
+	}
+	if _, err := NewEnv(IntegerOverflowPolicy(interpreter.OverflowPolicy(-1))); err == nil {
+		t.Error("NewEnv() with an unsupported overflow policy succeeded, wanted error")
+	}
+}
+
+func TestStringInputLimits(t *testing.T) {
+	env, err := NewEnv(
+		Variable("s", StringType),
+		Variable("re", StringType),
+		Variable("d", DynType),
+		StringInputLimits(interpreter.StringLimits{MaxInputLength: 8, MaxRegexProgramSize: 32}),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	vars := map[string]any{
+		"s":  "abcdef",
+		"re": "^a",
+		"d":  []string{"abcdefghijk"},
+	}
+	tests := []struct {
+		expr      string
+		out       ref.Val
+		lengthErr bool
+		regexErr  bool
+	}{
+		{expr: `s.contains('cd') && s.startsWith('ab') && s.endsWith('ef')`, out: types.True},
+		{expr: `s.matches(re) && s.matches('b.d')`, out: types.True},
+		{expr: `(s + s).contains('a')`, lengthErr: true},
+		{expr: `s.startsWith(s + s)`, lengthErr: true},
+		{expr: `(s + s).matches('a')`, lengthErr: true},
+		{expr: `s.matches(re + '{1,20}')`, regexErr: true},
+		{expr: `d.exists(x, x in ['abcdefghijk'])`, out: types.True},
+		{expr: `'abcdefghijk'.contains('a')`, out: types.True},
+		{expr: `dyn(d[0]).endsWith('k')`, lengthErr: true},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%q) failed: %v", tc.expr, iss.Err())
+			}
+			for _, opt := range []EvalOption{OptOptimize, OptTrackState, OptScalarEval} {
+				prg, err := env.Program(ast, EvalOptions(opt))
+				if err != nil {
+					t.Fatalf("env.Program() failed: %v", err)
+				}
+				out, _, err := prg.Eval(vars)
+				var lengthErr *interpreter.InputLengthError
+				var regexErr *interpreter.RegexSizeError
+				switch {
+				case tc.lengthErr:
+					if !errors.As(err, &lengthErr) || lengthErr.Limit != 8 {
+						t.Errorf("prg.Eval() got %v, %v, wanted an input length error", out, err)
+					}
+				case tc.regexErr:
+					if !errors.As(err, &regexErr) || regexErr.Limit != 32 {
+						t.Errorf("prg.Eval() got %v, %v, wanted a regex size error", out, err)
+					}
+				case err != nil:
+					t.Errorf("prg.Eval() failed: %v", err)
+				case out.Equal(tc.out) != types.True:
+					t.Errorf("prg.Eval() got %v, wanted %v", out, tc.out)
+				}
+			}
+		})
+	}
+
+	// Constant regular expressions are checked when the program is created.
+	ast, iss := env.Compile(`s.matches('a{1,20}')`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	_, err = env.Program(ast)
+	var regexErr *interpreter.RegexSizeError
+	if !errors.As(err, &regexErr) || regexErr.Pattern != "a{1,20}" {
+		t.Errorf("env.Program() got %v, wanted a regex size error", err)
+	}
+	if _, err := NewEnv(StringInputLimits(interpreter.StringLimits{MaxInputLength: -1})); err == nil {
+		t.Error("NewEnv() with a negative string limit succeeded, wanted error")
+	}
+}
+
+func TestAggregateComparisons(t *testing.T) {
+	env, err := NewEnv(
+		AggregateComparisons(true),
+		Variable("x", DynType),
+		Variable("l", ListType(ListType(IntType))),
+		// A function which orders its arguments through the traits.Comparer interface.
+		Function("least",
+			Overload("least_list", []*Type{ListType(TypeParamType("A"))}, TypeParamType("A"),
+				UnaryBinding(func(arg ref.Val) ref.Val {
+					l := arg.(traits.Lister)
+					least := l.Get(types.IntZero)
+					for it := l.Iterator(); it.HasNext() == types.True; {
+						elem := it.Next()
+						cmp, ok := elem.(traits.Comparer)
+						if !ok {
+							return types.MaybeNoSuchOverloadErr(elem)
+						}
+						if cmp.Compare(least) == types.IntNegOne {
+							least = elem
+						}
+					}
+					return least
+				}))),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		expr string
+		out  ref.Val
+		err  string
+	}{
+		{expr: `[1, 2] < [1, 3]`, out: types.True},
+		{expr: `[1, 2] < [1, 2, 0]`, out: types.True},
+		{expr: `[1, 2] <= [1, 2] && [1, 2] >= [1, 2]`, out: types.True},
+		{expr: `[[1, 2], [0]] > [[1, 1], [9]]`, out: types.True},
+		{expr: `[1, 2.5] < [1u, 3]`, out: types.True},
+		{expr: `{'a': 1, 'b': 2} < {'b': 1, 'a': 2}`, out: types.True},
+		{expr: `{'a': 1} < {'a': 1, 'b': 0}`, out: types.True},
+		{expr: `l[0] < l[1]`, out: types.False},
+		{expr: `x < [1, 2]`, out: types.True},
+		{expr: `1 < 2 && 'a' < 'b'`, out: types.True},
+		{expr: `[1] < [dyn('a')]`, err: "no such overload"},
+		{expr: `[1] < [1.5] && [2u] > [1.5]`, out: types.True},
+		{expr: `{'a': 1} < {'a': 1.5}`, out: types.True},
+		{expr: `[1] < ['a']`, err: "no such overload"},
+		{expr: `least(l) == [1, 5]`, out: types.True},
+		{expr: `least([{'a': 2}, {'a': 1}, {'a': 1, 'b': 0}]) == {'a': 1}`, out: types.True},
+	}
+	vars := map[string]any{"x": []int{1}, "l": [][]int{{2}, {1, 5}}}
+	for _, tc := range tests {
+		ast, iss := env.Compile(tc.expr)
+		if iss.Err() != nil {
+			t.Fatalf("env.Compile(%q) failed: %v", tc.expr, iss.Err())
+		}
+		prg, err := env.Program(ast)
+		if err != nil {
+			t.Fatalf("env.Program(%q) failed: %v", tc.expr, err)
+		}
+		out, _, err := prg.Eval(vars)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("prg.Eval(%q) got %v, %v, wanted error containing %q", tc.expr, out, err, tc.err)
+			}
+			continue
+		}
+		if err != nil || out != tc.out {
+			t.Errorf("prg.Eval(%q) got %v, %v, wanted %v", tc.expr, out, err, tc.out)
+		}
+	}
+
+	// The comparisons are not declared unless the feature is enabled.
+	env, err = NewEnv()
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	if _, iss := env.Compile(`[1] < [2]`); iss.Err() == nil {
+		t.Error("env.Compile() of a list comparison succeeded without AggregateComparisons")
+	}
+	// Nor are they evaluated for dynamically typed operands.
+	env, err = NewEnv(Variable("x", DynType), Variable("y", DynType))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	for _, expr := range []string{`dyn([1]) < dyn([2])`, `x < y`, `dyn({'a': 1}) >= dyn({'a': 2})`} {
+		ast, iss := env.Compile(expr)
+		if iss.Err() != nil {
+			t.Fatalf("env.Compile(%q) failed: %v", expr, iss.Err())
+		}
+		prg, err := env.Program(ast)
+		if err != nil {
+			t.Fatalf("env.Program(%q) failed: %v", expr, err)
+		}
+		out, _, err := prg.Eval(map[string]any{"x": []int{1}, "y": []int{2}})
+		if err == nil || !strings.Contains(err.Error(), "no such overload") {
+			t.Errorf("prg.Eval(%q) got %v, %v, wanted no such overload error", expr, out, err)
+		}
+	}
+}
+
+func TestMaskAttributes(t *testing.T) {
+	env, err := NewEnv(Variable("user", MapType(StringType, StringType)))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`user.email.endsWith('@example.com') && user.name == 'alice'`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	var masked []ref.Val
+	hashDomain := func(val ref.Val) ref.Val {
+		masked = append(masked, val)
+		str := string(val.(types.String))
+		return types.String("#" + str[strings.Index(str, "@"):])
+	}
+	prg, err := env.Program(ast, MaskAttributes(hashDomain, AttributePattern("user").QualString("email")))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	out, _, err := prg.Eval(map[string]any{
+		"user": map[string]string{"name": "alice", "email": "alice@example.com"},
+	})
+	if err != nil || out != types.True {
+		t.Errorf("prg.Eval() got %v, %v, wanted true", out, err)
+	}
+	if len(masked) != 1 || masked[0] != types.String("alice@example.com") {
+		t.Errorf("mask got %v, wanted the email address", masked)
+	}
+	if _, err := env.Program(ast, MaskAttributes(nil)); err == nil {
+		t.Error("env.Program() with a nil mask succeeded, wanted error")
+	}
+
+	// Scalar evaluation must not resolve the masked variables directly.
+	env, err = NewEnv(Variable("s", StringType))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss = env.Compile(`s == 'secret'`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	redact := func(ref.Val) ref.Val { return types.String("<redacted>") }
+	prg, err = env.Program(ast, MaskAttributes(redact, AttributePattern("s")), EvalOptions(OptScalarEval))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	out, _, err = prg.Eval(map[string]any{"s": "secret"})
+	if err != nil || out != types.False {
+		t.Errorf("prg.Eval() with OptScalarEval got %v, %v, wanted false", out, err)
+	}
+}
+
+func TestReplayActivation(t *testing.T) {
+	env, err := NewEnv(
+		Variable("req", MapType(StringType, IntType)),
+		Variable("limit", IntType),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`req.size > limit && [1, 2, 3].all(x, x < req.size) ? req.size - limit : 0`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast, EvalOptions(OptTrackState))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	out, det, err := prg.Eval(map[string]any{"req": map[string]int{"size": 10}, "limit": 4})
+	if err != nil {
+		t.Fatalf("prg.Eval() failed: %v", err)
+	}
+	if out != types.Int(6) {
+		t.Fatalf("prg.Eval() got %v, wanted 6", out)
+	}
+
+	// Replay the evaluation without the variables, including with the state tracked again.
+	replay := interpreter.NewReplayActivation(det.State())
+	for _, p := range []Program{prg, mustProgram(t, env, ast)} {
+		out, _, err = p.Eval(replay)
+		if err != nil {
+			t.Fatalf("prg.Eval(replay) failed: %v", err)
+		}
+		if out != types.Int(6) {
+			t.Errorf("prg.Eval(replay) got %v, wanted 6", out)
+		}
+	}
+
+	// Attributes which were not evaluated cannot be replayed.
+	ast, iss = env.Compile(`limit + 1`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	out, _, err = mustProgram(t, env, ast).Eval(interpreter.NewReplayActivation(interpreter.NewEvalState()))
+	if err == nil {
+		t.Errorf("prg.Eval(replay) got %v, wanted error", out)
+	}
+}
+
+func mustProgram(t *testing.T, env *Env, ast *Ast, opts ...ProgramOption) Program {
+	t.Helper()
+	prg, err := env.Program(ast, opts...)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	return prg
+}
+
+// evalTestCase is an expression to compile and evaluate within a table-driven test.
+//
+// When err is set, either the compilation or the evaluation must fail with an error containing
+// it. Otherwise the output type, when set, and the result must match outType and out, where out
+// is either a ref.Val or a native value.
+type evalTestCase struct {
+	expr    string
+	outType *Type
+	out     any
+	err     string
+}
+
+// runEvalTests compiles each test case within env and evaluates it against the vars with the
+// program options.
+func runEvalTests(t *testing.T, env *Env, tests []evalTestCase, vars any, opts ...ProgramOption) {
+	t.Helper()
+	if vars == nil {
+		vars = NoVars()
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				if tc.err == "" || !strings.Contains(iss.Err().Error(), tc.err) {
+					t.Fatalf("env.Compile(%q) failed: %v", tc.expr, iss.Err())
+				}
+				return
+			}
+			if tc.outType != nil && (!ast.OutputType().IsAssignableType(tc.outType) || !tc.outType.IsAssignableType(ast.OutputType())) {
+				t.Errorf("env.Compile(%q) got output type %v, wanted %v", tc.expr, ast.OutputType(), tc.outType)
+			}
+			out, _, err := mustProgram(t, env, ast, opts...).Eval(vars)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Errorf("prg.Eval(%q) got %v, %v, wanted error containing %q", tc.expr, out, err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("prg.Eval(%q) failed: %v", tc.expr, err)
+			}
+			if want := env.TypeAdapter().NativeToValue(tc.out); out.Equal(want) != types.True {
+				t.Errorf("prg.Eval(%q) got %v, wanted %v", tc.expr, out, want)
+			}
+		})
+	}
+}
+
+func TestVariableDefaultValue(t *testing.T) {
+	env, err := NewEnv(
+		Variable("limit", IntType, DefaultValue(100)),
+		Variable("tags", ListType(StringType), DefaultValue([]string{"default"})),
+		Variable("size", IntType),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`size < limit && 'default' in tags`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	defaults := ast.VariableDefaults()
+	if len(defaults) != 2 || defaults["limit"] != types.Int(100) {
+		t.Errorf("ast.VariableDefaults() got %v, wanted limit and tags", defaults)
+	}
+	prg, err := env.Program(ast, Globals(map[string]any{"tags": []string{"global", "default"}}))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	tests := []struct {
+		vars map[string]any
+		out  ref.Val
+	}{
+		{vars: map[string]any{"size": 10}, out: types.True},
+		{vars: map[string]any{"size": 10, "limit": 5}, out: types.False},
+		{vars: map[string]any{"size": 10, "tags": []string{}}, out: types.False},
+	}
+	for _, tst := range tests {
+		out, _, err := prg.Eval(tst.vars)
+		if err != nil {
+			t.Fatalf("prg.Eval(%v) failed: %v", tst.vars, err)
+		}
+		if out != tst.out {
+			t.Errorf("prg.Eval(%v) got %v, wanted %v", tst.vars, out, tst.out)
+		}
+	}
+
+	_, err = NewEnv(Variable("limit", IntType, DefaultValue("100")))
+	if err == nil || !strings.Contains(err.Error(), "not assignable to int") {
+		t.Errorf("NewEnv() with a mistyped default got %v, wanted error", err)
+	}
+}
@@ -0,0 +1,464 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package celbuild provides a fluent builder for constructing CEL abstract syntax trees
+// programmatically without generating and parsing expression text.
+//
+// Expressions are immutable values which may be freely shared and reused. Expression ids are
+// assigned when the expression is built, so a subexpression which appears multiple times within
+// the same tree receives a distinct id at each position.
+//
+//	expr := celbuild.Var("a").Select("b").Eq(celbuild.Str("x"))
+//	ast, iss := expr.Check(env)
+package celbuild
+
+import (
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/parser"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Expr is an immutable, reusable fragment of a CEL expression.
+type Expr struct {
+	build func(b *builder) *exprpb.Expr
+}
+
+// Build assigns expression ids and returns the parsed cel.Ast for the expression.
+//
+// The resulting Ast does not contain source positions as there is no source text associated
+// with a programmatically constructed expression.
+func (e Expr) Build() *cel.Ast {
+	b := &builder{}
+	expr := e.toExpr(b)
+	info := &exprpb.SourceInfo{
+		Location:    "<celbuild>",
+		LineOffsets: []int32{},
+		Positions:   map[int64]int32{},
+	}
+	return cel.ParsedExprToAstWithSource(
+		&exprpb.ParsedExpr{Expr: expr, SourceInfo: info},
+		common.NewInfoSource(info))
+}
+
+// Check builds the expression and type-checks it against the provided environment.
+func (e Expr) Check(env *cel.Env) (*cel.Ast, *cel.Issues) {
+	return env.Check(e.Build())
+}
+
+// Var returns an identifier reference to the variable with the given (possibly qualified) name.
+func Var(name string) Expr {
+	return Expr{build: func(b *builder) *exprpb.Expr {
+		return b.ident(name)
+	}}
+}
+
+// Str returns a string literal.
+func Str(value string) Expr {
+	return constExpr(&exprpb.Constant{ConstantKind: &exprpb.Constant_StringValue{StringValue: value}})
+}
+
+// Bytes returns a bytes literal.
+func Bytes(value []byte) Expr {
+	return constExpr(&exprpb.Constant{ConstantKind: &exprpb.Constant_BytesValue{BytesValue: value}})
+}
+
+// Int returns an int literal.
+func Int(value int64) Expr {
+	return constExpr(&exprpb.Constant{ConstantKind: &exprpb.Constant_Int64Value{Int64Value: value}})
+}
+
+// Uint returns a uint literal.
+func Uint(value uint64) Expr {
+	return constExpr(&exprpb.Constant{ConstantKind: &exprpb.Constant_Uint64Value{Uint64Value: value}})
+}
+
+// Double returns a double literal.
+func Double(value float64) Expr {
+	return constExpr(&exprpb.Constant{ConstantKind: &exprpb.Constant_DoubleValue{DoubleValue: value}})
+}
+
+// Bool returns a bool literal.
+func Bool(value bool) Expr {
+	return constExpr(&exprpb.Constant{ConstantKind: &exprpb.Constant_BoolValue{BoolValue: value}})
+}
+
+// Null returns the null literal.
+func Null() Expr {
+	return constExpr(&exprpb.Constant{ConstantKind: &exprpb.Constant_NullValue{}})
+}
+
+// List returns a list literal containing the given elements.
+func List(elems ...Expr) Expr {
+	return Expr{build: func(b *builder) *exprpb.Expr {
+		id := b.nextID()
+		return &exprpb.Expr{
+			Id: id,
+			ExprKind: &exprpb.Expr_ListExpr{
+				ListExpr: &exprpb.Expr_CreateList{Elements: b.exprs(elems)},
+			},
+		}
+	}}
+}
+
+// Entry is a key-value pair within a map literal.
+type Entry struct {
+	Key   Expr
+	Value Expr
+}
+
+// Map returns a map literal containing the given entries.
+func Map(entries ...Entry) Expr {
+	return Expr{build: func(b *builder) *exprpb.Expr {
+		id := b.nextID()
+		ents := make([]*exprpb.Expr_CreateStruct_Entry, len(entries))
+		for i, ent := range entries {
+			entID := b.nextID()
+			ents[i] = &exprpb.Expr_CreateStruct_Entry{
+				Id:      entID,
+				KeyKind: &exprpb.Expr_CreateStruct_Entry_MapKey{MapKey: ent.Key.toExpr(b)},
+				Value:   ent.Value.toExpr(b),
+			}
+		}
+		return &exprpb.Expr{
+			Id: id,
+			ExprKind: &exprpb.Expr_StructExpr{
+				StructExpr: &exprpb.Expr_CreateStruct{Entries: ents},
+			},
+		}
+	}}
+}
+
+// Field is a field initializer within a message literal.
+type Field struct {
+	Name  string
+	Value Expr
+}
+
+// Message returns a message literal of the given type name with the given field initializers.
+func Message(typeName string, fields ...Field) Expr {
+	return Expr{build: func(b *builder) *exprpb.Expr {
+		id := b.nextID()
+		ents := make([]*exprpb.Expr_CreateStruct_Entry, len(fields))
+		for i, f := range fields {
+			entID := b.nextID()
+			ents[i] = &exprpb.Expr_CreateStruct_Entry{
+				Id:      entID,
+				KeyKind: &exprpb.Expr_CreateStruct_Entry_FieldKey{FieldKey: f.Name},
+				Value:   f.Value.toExpr(b),
+			}
+		}
+		return &exprpb.Expr{
+			Id: id,
+			ExprKind: &exprpb.Expr_StructExpr{
+				StructExpr: &exprpb.Expr_CreateStruct{MessageName: typeName, Entries: ents},
+			},
+		}
+	}}
+}
+
+// Call returns a global function call expression.
+func Call(function string, args ...Expr) Expr {
+	return Expr{build: func(b *builder) *exprpb.Expr {
+		return b.call(function, nil, args)
+	}}
+}
+
+// Not returns the logical negation of the expression.
+func Not(e Expr) Expr {
+	return Call(operators.LogicalNot, e)
+}
+
+// Cond returns a ternary conditional: `cond ? tExpr : fExpr`.
+func Cond(cond, tExpr, fExpr Expr) Expr {
+	return Call(operators.Conditional, cond, tExpr, fExpr)
+}
+
+// Select returns a field selection on the receiver expression, e.g. `e.field`.
+func (e Expr) Select(field string) Expr {
+	return e.selectExpr(field, false)
+}
+
+// Has returns a presence test of the field on the receiver expression, e.g. `has(e.field)`.
+func (e Expr) Has(field string) Expr {
+	return e.selectExpr(field, true)
+}
+
+// Index returns an index expression, e.g. `e[idx]`.
+func (e Expr) Index(idx Expr) Expr {
+	return Call(operators.Index, e, idx)
+}
+
+// Call returns a receiver-style function call on the expression, e.g. `e.function(args...)`.
+func (e Expr) Call(function string, args ...Expr) Expr {
+	return Expr{build: func(b *builder) *exprpb.Expr {
+		return b.call(function, &e, args)
+	}}
+}
+
+// Eq returns `e == other`.
+func (e Expr) Eq(other Expr) Expr {
+	return Call(operators.Equals, e, other)
+}
+
+// Ne returns `e != other`.
+func (e Expr) Ne(other Expr) Expr {
+	return Call(operators.NotEquals, e, other)
+}
+
+// Lt returns `e < other`.
+func (e Expr) Lt(other Expr) Expr {
+	return Call(operators.Less, e, other)
+}
+
+// Le returns `e <= other`.
+func (e Expr) Le(other Expr) Expr {
+	return Call(operators.LessEquals, e, other)
+}
+
+// Gt returns `e > other`.
+func (e Expr) Gt(other Expr) Expr {
+	return Call(operators.Greater, e, other)
+}
+
+// Ge returns `e >= other`.
+func (e Expr) Ge(other Expr) Expr {
+	return Call(operators.GreaterEquals, e, other)
+}
+
+// And returns `e && other`.
+func (e Expr) And(other Expr) Expr {
+	return Call(operators.LogicalAnd, e, other)
+}
+
+// Or returns `e || other`.
+func (e Expr) Or(other Expr) Expr {
+	return Call(operators.LogicalOr, e, other)
+}
+
+// Add returns `e + other`.
+func (e Expr) Add(other Expr) Expr {
+	return Call(operators.Add, e, other)
+}
+
+// Sub returns `e - other`.
+func (e Expr) Sub(other Expr) Expr {
+	return Call(operators.Subtract, e, other)
+}
+
+// Mul returns `e * other`.
+func (e Expr) Mul(other Expr) Expr {
+	return Call(operators.Multiply, e, other)
+}
+
+// Div returns `e / other`.
+func (e Expr) Div(other Expr) Expr {
+	return Call(operators.Divide, e, other)
+}
+
+// Mod returns `e % other`.
+func (e Expr) Mod(other Expr) Expr {
+	return Call(operators.Modulo, e, other)
+}
+
+// Neg returns the arithmetic negation `-e`.
+func (e Expr) Neg() Expr {
+	return Call(operators.Negate, e)
+}
+
+// In returns `e in container`.
+func (e Expr) In(container Expr) Expr {
+	return Call(operators.In, e, container)
+}
+
+// All returns the expansion of the `e.all(iterVar, pred)` macro.
+func (e Expr) All(iterVar string, pred func(Expr) Expr) Expr {
+	return e.quantifier(iterVar, pred, quantifierAll)
+}
+
+// Exists returns the expansion of the `e.exists(iterVar, pred)` macro.
+func (e Expr) Exists(iterVar string, pred func(Expr) Expr) Expr {
+	return e.quantifier(iterVar, pred, quantifierExists)
+}
+
+// ExistsOne returns the expansion of the `e.exists_one(iterVar, pred)` macro.
+func (e Expr) ExistsOne(iterVar string, pred func(Expr) Expr) Expr {
+	return e.quantifier(iterVar, pred, quantifierExistsOne)
+}
+
+// Map returns the expansion of the `e.map(iterVar, transform)` macro.
+func (e Expr) Map(iterVar string, transform func(Expr) Expr) Expr {
+	return e.mapFilter(iterVar, nil, transform)
+}
+
+// MapFilter returns the expansion of the `e.map(iterVar, filter, transform)` macro.
+func (e Expr) MapFilter(iterVar string, filter, transform func(Expr) Expr) Expr {
+	return e.mapFilter(iterVar, filter, transform)
+}
+
+// Filter returns the expansion of the `e.filter(iterVar, pred)` macro.
+func (e Expr) Filter(iterVar string, pred func(Expr) Expr) Expr {
+	accu := Var(parser.AccumulatorName)
+	iter := Var(iterVar)
+	return comprehension(e, iterVar,
+		List(),
+		Bool(true),
+		Cond(pred(iter), accu.Add(List(iter)), accu),
+		accu)
+}
+
+type quantifierKind int
+
+const (
+	quantifierAll quantifierKind = iota
+	quantifierExists
+	quantifierExistsOne
+)
+
+func (e Expr) quantifier(iterVar string, pred func(Expr) Expr, kind quantifierKind) Expr {
+	accu := Var(parser.AccumulatorName)
+	cond := pred(Var(iterVar))
+	switch kind {
+	case quantifierAll:
+		return comprehension(e, iterVar,
+			Bool(true),
+			Call(operators.NotStrictlyFalse, accu),
+			accu.And(cond),
+			accu)
+	case quantifierExists:
+		return comprehension(e, iterVar,
+			Bool(false),
+			Call(operators.NotStrictlyFalse, Not(accu)),
+			accu.Or(cond),
+			accu)
+	default:
+		return comprehension(e, iterVar,
+			Int(0),
+			Bool(true),
+			Cond(cond, accu.Add(Int(1)), accu),
+			accu.Eq(Int(1)))
+	}
+}
+
+func (e Expr) mapFilter(iterVar string, filter, transform func(Expr) Expr) Expr {
+	accu := Var(parser.AccumulatorName)
+	iter := Var(iterVar)
+	step := accu.Add(List(transform(iter)))
+	if filter != nil {
+		step = Cond(filter(iter), step, accu)
+	}
+	return comprehension(e, iterVar, List(), Bool(true), step, accu)
+}
+
+func (e Expr) selectExpr(field string, testOnly bool) Expr {
+	return Expr{build: func(b *builder) *exprpb.Expr {
+		operand := e.toExpr(b)
+		return &exprpb.Expr{
+			Id: b.nextID(),
+			ExprKind: &exprpb.Expr_SelectExpr{
+				SelectExpr: &exprpb.Expr_Select{
+					Operand:  operand,
+					Field:    field,
+					TestOnly: testOnly,
+				},
+			},
+		}
+	}}
+}
+
+func (e Expr) toExpr(b *builder) *exprpb.Expr {
+	if e.build == nil {
+		// The zero value Expr is treated as the null literal.
+		return Null().build(b)
+	}
+	return e.build(b)
+}
+
+func comprehension(iterRange Expr, iterVar string, init, cond, step, result Expr) Expr {
+	return Expr{build: func(b *builder) *exprpb.Expr {
+		rangeExpr := iterRange.toExpr(b)
+		initExpr := init.toExpr(b)
+		condExpr := cond.toExpr(b)
+		stepExpr := step.toExpr(b)
+		resultExpr := result.toExpr(b)
+		return &exprpb.Expr{
+			Id: b.nextID(),
+			ExprKind: &exprpb.Expr_ComprehensionExpr{
+				ComprehensionExpr: &exprpb.Expr_Comprehension{
+					IterVar:       iterVar,
+					IterRange:     rangeExpr,
+					AccuVar:       parser.AccumulatorName,
+					AccuInit:      initExpr,
+					LoopCondition: condExpr,
+					LoopStep:      stepExpr,
+					Result:        resultExpr,
+				},
+			},
+		}
+	}}
+}
+
+func constExpr(c *exprpb.Constant) Expr {
+	return Expr{build: func(b *builder) *exprpb.Expr {
+		return &exprpb.Expr{
+			Id:       b.nextID(),
+			ExprKind: &exprpb.Expr_ConstExpr{ConstExpr: c},
+		}
+	}}
+}
+
+// builder assigns monotonically increasing expression ids during Build.
+type builder struct {
+	id int64
+}
+
+func (b *builder) nextID() int64 {
+	b.id++
+	return b.id
+}
+
+func (b *builder) ident(name string) *exprpb.Expr {
+	return &exprpb.Expr{
+		Id:       b.nextID(),
+		ExprKind: &exprpb.Expr_IdentExpr{IdentExpr: &exprpb.Expr_Ident{Name: name}},
+	}
+}
+
+func (b *builder) exprs(elems []Expr) []*exprpb.Expr {
+	out := make([]*exprpb.Expr, len(elems))
+	for i, e := range elems {
+		out[i] = e.toExpr(b)
+	}
+	return out
+}
+
+func (b *builder) call(function string, target *Expr, args []Expr) *exprpb.Expr {
+	var targetExpr *exprpb.Expr
+	if target != nil {
+		targetExpr = target.toExpr(b)
+	}
+	argExprs := b.exprs(args)
+	return &exprpb.Expr{
+		Id: b.nextID(),
+		ExprKind: &exprpb.Expr_CallExpr{
+			CallExpr: &exprpb.Expr_Call{
+				Function: function,
+				Target:   targetExpr,
+				Args:     argExprs,
+			},
+		},
+	}
+}
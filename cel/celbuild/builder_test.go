@@ -0,0 +1,131 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package celbuild
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestBuildEval(t *testing.T) {
+	env, err := cel.NewEnv(
+		cel.Variable("a", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("nums", cel.ListType(cel.IntType)),
+	)
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	nums := Var("nums")
+	tests := []struct {
+		name string
+		expr Expr
+		out  any
+	}{
+		{name: "select_eq", expr: Var("a").Select("b").Eq(Str("x")), out: true},
+		{name: "has", expr: Var("a").Has("c"), out: false},
+		{name: "index", expr: Var("a").Index(Str("b")).Ne(Str("y")), out: true},
+		{name: "arith", expr: Int(2).Mul(Int(3)).Add(Int(1)).Sub(Int(7).Mod(Int(4))).Div(Int(2)), out: int64(2)},
+		{name: "neg", expr: Double(1.5).Neg().Lt(Double(0)), out: true},
+		{name: "logic", expr: Not(Bool(false)).And(Bool(true).Or(Bool(false))), out: true},
+		{name: "cond", expr: Cond(Uint(1).Ge(Uint(2)), Str("no"), Str("yes")), out: "yes"},
+		{name: "in_list", expr: Int(2).In(List(Int(1), Int(2))), out: true},
+		{name: "in_map", expr: Str("k").In(Map(Entry{Key: Str("k"), Value: Null()})), out: true},
+		{name: "member_call", expr: Str("hello").Call("startsWith", Str("he")), out: true},
+		{name: "global_call", expr: Call("size", Bytes([]byte("abc"))).Le(Int(3)), out: true},
+		{name: "all", expr: nums.All("n", func(n Expr) Expr { return n.Gt(Int(0)) }), out: true},
+		{name: "exists", expr: nums.Exists("n", func(n Expr) Expr { return n.Eq(Int(3)) }), out: true},
+		{name: "exists_one", expr: nums.ExistsOne("n", func(n Expr) Expr { return n.Gt(Int(1)) }), out: false},
+		{name: "map_size", expr: Call("size", nums.Map("n", func(n Expr) Expr { return n.Mul(n) })), out: int64(3)},
+		{name: "map_filter", expr: nums.MapFilter("n",
+			func(n Expr) Expr { return n.Gt(Int(1)) },
+			func(n Expr) Expr { return n.Mul(Int(10)) }).Eq(List(Int(20), Int(30))), out: true},
+		{name: "filter", expr: nums.Filter("n", func(n Expr) Expr { return n.Lt(Int(3)) }).Eq(List(Int(1), Int(2))), out: true},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.name, func(t *testing.T) {
+			ast, iss := tc.expr.Check(env)
+			if iss.Err() != nil {
+				t.Fatalf("Check() failed: %v", iss.Err())
+			}
+			prg, err := env.Program(ast)
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			out, _, err := prg.Eval(map[string]any{
+				"a":    map[string]string{"b": "x"},
+				"nums": []int64{1, 2, 3},
+			})
+			if err != nil {
+				t.Fatalf("prg.Eval() failed: %v", err)
+			}
+			if out.Value() != tc.out {
+				t.Errorf("prg.Eval() got %v, wanted %v", out.Value(), tc.out)
+			}
+		})
+	}
+}
+
+func TestBuildUniqueIDs(t *testing.T) {
+	shared := Var("x").Select("y")
+	ast := shared.Eq(shared).Build()
+	pe, err := cel.AstToParsedExpr(ast)
+	if err != nil {
+		t.Fatalf("cel.AstToParsedExpr() failed: %v", err)
+	}
+	seen := map[int64]bool{}
+	var visit func(e *exprpb.Expr)
+	visit = func(e *exprpb.Expr) {
+		if seen[e.GetId()] {
+			t.Errorf("duplicate expression id: %d", e.GetId())
+		}
+		seen[e.GetId()] = true
+		if sel := e.GetSelectExpr(); sel != nil {
+			visit(sel.GetOperand())
+		}
+		if call := e.GetCallExpr(); call != nil {
+			for _, arg := range call.GetArgs() {
+				visit(arg)
+			}
+		}
+	}
+	visit(pe.GetExpr())
+	if len(seen) != 5 {
+		t.Errorf("got %d expression ids, wanted 5", len(seen))
+	}
+}
+
+func TestBuildMessage(t *testing.T) {
+	env, err := cel.NewEnv(cel.Container("google.protobuf"))
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	expr := Message("Int64Value", Field{Name: "value", Value: Int(42)}).Eq(Int(42))
+	ast, iss := expr.Check(env)
+	if iss.Err() != nil {
+		t.Fatalf("Check() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	out, _, err := prg.Eval(cel.NoVars())
+	if err != nil || out.Value() != true {
+		t.Errorf("prg.Eval() got %v, %v, wanted true", out, err)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Checkpointing configures the program so that its evaluations by EvalSlice may be suspended
+// between the iterations of its comprehensions and later resumed from an interpreter.Checkpoint,
+// allowing cooperative schedulers to interleave many long-running evaluations fairly.
+//
+// Only the comprehensions which are evaluated at most once per evaluation, i.e. which are not
+// nested within the loop of another comprehension, record their progress. A nested comprehension
+// runs to completion within an iteration of the comprehension which contains it. Comprehensions
+// over maps iterate the map in sorted order, so that a resumed comprehension visits the remaining
+// entries.
+func Checkpointing() ProgramOption {
+	return func(p *prog) (*prog, error) {
+		p.checkpointing = true
+		return p, nil
+	}
+}
+
+// EvalSlice evaluates the program with the input, which may be an interpreter.Activation or a
+// map[string]any, resuming from the progress recorded in the checkpoint.
+//
+// The suspend function is called after each iteration of the comprehensions which record their
+// progress, and when it returns true the evaluation is suspended: the progress is recorded in the
+// checkpoint, and an interpreter.EvalCancelledError whose Cause is interpreter.EvalSuspended is
+// returned. Calling EvalSlice again with the same input and checkpoint resumes the evaluation, and
+// CheckpointToValue serializes the checkpoint for resumption by another process. A suspend function
+// which counts iterations or tests a deadline bounds the cost or the time of each slice of the
+// evaluation.
+//
+// The work outside of the recorded comprehensions is repeated by each slice, so resumed
+// evaluations only produce the result of an uninterrupted evaluation when the input and the
+// functions called by the expression are deterministic.
+func EvalSlice(prg Program, input any, checkpoint *interpreter.Checkpoint, suspend func() bool) (ref.Val, *EvalDetails, error) {
+	vars, err := interpreter.NewActivation(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return prg.Eval(interpreter.NewCheckpointActivation(vars, checkpoint, suspend))
+}
+
+// CheckpointToValue converts the checkpoint into its serialized proto form, so that an evaluation
+// suspended by one process may be resumed by another.
+//
+// The checkpoint is represented as a map from the id of each comprehension to a map with the
+// 'iterations', 'accu', and 'done' fields of its interpreter.FoldCheckpoint, where the accumulator is
+// converted with RefValueToValue.
+func CheckpointToValue(checkpoint *interpreter.Checkpoint) (*exprpb.Value, error) {
+	ids := make([]int64, 0, len(checkpoint.Folds))
+	for id := range checkpoint.Folds {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	entries := make([]*exprpb.MapValue_Entry, 0, len(ids))
+	for _, id := range ids {
+		fold := checkpoint.Folds[id]
+		accu, err := RefValueToValue(fold.Accu)
+		if err != nil {
+			return nil, fmt.Errorf("invalid accumulator of comprehension %d: %w", id, err)
+		}
+		entries = append(entries, &exprpb.MapValue_Entry{
+			Key: &exprpb.Value{Kind: &exprpb.Value_Int64Value{Int64Value: id}},
+			Value: &exprpb.Value{Kind: &exprpb.Value_MapValue{MapValue: &exprpb.MapValue{
+				Entries: []*exprpb.MapValue_Entry{
+					checkpointField("iterations", &exprpb.Value{
+						Kind: &exprpb.Value_Int64Value{Int64Value: int64(fold.Iterations)}}),
+					checkpointField("accu", accu),
+					checkpointField("done", &exprpb.Value{
+						Kind: &exprpb.Value_BoolValue{BoolValue: fold.Done}}),
+				},
+			}}},
+		})
+	}
+	return &exprpb.Value{Kind: &exprpb.Value_MapValue{MapValue: &exprpb.MapValue{Entries: entries}}}, nil
+}
+
+// ValueToCheckpoint converts the serialized proto form produced by CheckpointToValue into an
+// interpreter.Checkpoint, converting the accumulators with ValueToRefValue.
+func ValueToCheckpoint(adapter ref.TypeAdapter, v *exprpb.Value) (*interpreter.Checkpoint, error) {
+	if v.GetMapValue() == nil {
+		return nil, fmt.Errorf("invalid checkpoint: got %v, wanted a map", v)
+	}
+	checkpoint := interpreter.NewCheckpoint()
+	for _, entry := range v.GetMapValue().GetEntries() {
+		id, ok := entry.GetKey().GetKind().(*exprpb.Value_Int64Value)
+		if !ok {
+			return nil, fmt.Errorf("invalid checkpoint: got comprehension id %v, wanted an int", entry.GetKey())
+		}
+		fold := &interpreter.FoldCheckpoint{}
+		for _, field := range entry.GetValue().GetMapValue().GetEntries() {
+			switch field.GetKey().GetStringValue() {
+			case "iterations":
+				fold.Iterations = int(field.GetValue().GetInt64Value())
+			case "accu":
+				accu, err := ValueToRefValue(adapter, field.GetValue())
+				if err != nil {
+					return nil, fmt.Errorf("invalid accumulator of comprehension %d: %w", id.Int64Value, err)
+				}
+				fold.Accu = accu
+			case "done":
+				fold.Done = field.GetValue().GetBoolValue()
+			}
+		}
+		if fold.Accu == nil {
+			return nil, fmt.Errorf("invalid checkpoint: no accumulator for comprehension %d", id.Int64Value)
+		}
+		checkpoint.Folds[id.Int64Value] = fold
+	}
+	return checkpoint, nil
+}
+
+func checkpointField(name string, value *exprpb.Value) *exprpb.MapValue_Entry {
+	return &exprpb.MapValue_Entry{
+		Key:   &exprpb.Value{Kind: &exprpb.Value_StringValue{StringValue: name}},
+		Value: value,
+	}
+}
+
+// checkpointedComprehensions returns the ids of the comprehensions within the expression which are
+// evaluated at most once per evaluation, i.e. those which are not within the loop condition or
+// step of another comprehension.
+func checkpointedComprehensions(e *exprpb.Expr) []int64 {
+	var ids []int64
+	var visit func(e *exprpb.Expr)
+	visit = func(e *exprpb.Expr) {
+		switch e.GetExprKind().(type) {
+		case *exprpb.Expr_SelectExpr:
+			visit(e.GetSelectExpr().GetOperand())
+		case *exprpb.Expr_CallExpr:
+			call := e.GetCallExpr()
+			if call.GetTarget() != nil {
+				visit(call.GetTarget())
+			}
+			for _, arg := range call.GetArgs() {
+				visit(arg)
+			}
+		case *exprpb.Expr_ListExpr:
+			for _, elem := range e.GetListExpr().GetElements() {
+				visit(elem)
+			}
+		case *exprpb.Expr_StructExpr:
+			for _, entry := range e.GetStructExpr().GetEntries() {
+				if entry.GetMapKey() != nil {
+					visit(entry.GetMapKey())
+				}
+				visit(entry.GetValue())
+			}
+		case *exprpb.Expr_ComprehensionExpr:
+			comp := e.GetComprehensionExpr()
+			ids = append(ids, e.GetId())
+			visit(comp.GetIterRange())
+			visit(comp.GetAccuInit())
+			visit(comp.GetResult())
+		}
+	}
+	visit(e)
+	return ids
+}
@@ -0,0 +1,186 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/interpreter"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestEvalSlice(t *testing.T) {
+	env, err := NewEnv(
+		Variable("items", ListType(IntType)),
+		Variable("m", MapType(StringType, IntType)),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	exprs := []string{
+		`items.filter(i, i % 3 == 0).map(i, [i, i].size()) == items.filter(i, i % 3 == 0).map(i, 2)`,
+		`items.map(i, items.exists(j, j == i * 2)).filter(b, b).size()`,
+		`m.map(k, k + string(m[k])).filter(s, s.startsWith('a')).size()`,
+		`[items.filter(i, i > 10)].map(xs, xs.map(x, x * x))`,
+	}
+	items := make([]int, 30)
+	for i := range items {
+		items[i] = i
+	}
+	vars := map[string]any{
+		"items": items,
+		"m":     map[string]int{"a": 1, "b": 2, "ab": 3, "ba": 4, "abc": 5},
+	}
+	for _, tst := range exprs {
+		expr := tst
+		t.Run(expr, func(t *testing.T) {
+			ast, iss := env.Compile(expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%v) failed: %v", expr, iss.Err())
+			}
+			prg, err := env.Program(ast, Checkpointing(), EvalOptions(OptOptimize))
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			want, _, err := prg.Eval(vars)
+			if err != nil {
+				t.Fatalf("prg.Eval() failed: %v", err)
+			}
+			checkpoint := interpreter.NewCheckpoint()
+			slices := 0
+			for {
+				slices++
+				if slices > 100 {
+					t.Fatal("EvalSlice() did not complete within 100 slices")
+				}
+				// Suspend the evaluation after every fourth iteration.
+				iterations := 0
+				out, _, err := EvalSlice(prg, vars, checkpoint, func() bool {
+					iterations++
+					return iterations%4 == 0
+				})
+				if cancelled, ok := err.(interpreter.EvalCancelledError); ok && cancelled.Cause == interpreter.EvalSuspended {
+					// Resume from a serialized checkpoint, as another process would.
+					val, err := CheckpointToValue(checkpoint)
+					if err != nil {
+						t.Fatalf("CheckpointToValue() failed: %v", err)
+					}
+					bytes, err := proto.Marshal(val)
+					if err != nil {
+						t.Fatalf("proto.Marshal() failed: %v", err)
+					}
+					val = &exprpb.Value{}
+					if err := proto.Unmarshal(bytes, val); err != nil {
+						t.Fatalf("proto.Unmarshal() failed: %v", err)
+					}
+					checkpoint, err = ValueToCheckpoint(env.TypeAdapter(), val)
+					if err != nil {
+						t.Fatalf("ValueToCheckpoint() failed: %v", err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("EvalSlice() failed: %v", err)
+				}
+				if out.Equal(want) != types.True {
+					t.Errorf("EvalSlice() got %v, wanted %v", out, want)
+				}
+				break
+			}
+			if slices < 2 {
+				t.Errorf("EvalSlice() completed in %d slices, wanted the evaluation to be suspended", slices)
+			}
+		})
+	}
+
+	// The fold observer is notified once of each comprehension which completes over the slices.
+	ast, iss := env.Compile(`items.all(i, i < 20)`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	type observation struct {
+		iterations int
+		terminated bool
+	}
+	var observed []observation
+	prg, err := env.Program(ast, Checkpointing(), ObserveFolds(func(id int64, iterations int, terminated bool) {
+		observed = append(observed, observation{iterations: iterations, terminated: terminated})
+	}))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	if _, _, err := prg.Eval(vars); err != nil {
+		t.Fatalf("prg.Eval() failed: %v", err)
+	}
+	want := observed
+	observed = nil
+	checkpoint := interpreter.NewCheckpoint()
+	for slices := 0; slices < 100; slices++ {
+		iterations := 0
+		_, _, err := EvalSlice(prg, vars, checkpoint, func() bool {
+			iterations++
+			return iterations%4 == 0
+		})
+		if cancelled, ok := err.(interpreter.EvalCancelledError); !ok || cancelled.Cause != interpreter.EvalSuspended {
+			break
+		}
+	}
+	if len(want) != 1 || !reflect.DeepEqual(observed, want) {
+		t.Errorf("EvalSlice() observed folds %v, wanted %v as observed by Eval()", observed, want)
+	}
+}
+
+func TestCheckpointToValue(t *testing.T) {
+	checkpoint := interpreter.NewCheckpoint()
+	checkpoint.Folds[3] = &interpreter.FoldCheckpoint{Iterations: 2, Accu: types.NewStringList(types.DefaultTypeAdapter, []string{"a", "b"})}
+	checkpoint.Folds[7] = &interpreter.FoldCheckpoint{Iterations: 5, Accu: types.True, Done: true}
+	val, err := CheckpointToValue(checkpoint)
+	if err != nil {
+		t.Fatalf("CheckpointToValue() failed: %v", err)
+	}
+	out, err := ValueToCheckpoint(types.DefaultTypeAdapter, val)
+	if err != nil {
+		t.Fatalf("ValueToCheckpoint() failed: %v", err)
+	}
+	if len(out.Folds) != len(checkpoint.Folds) {
+		t.Fatalf("ValueToCheckpoint() got %d folds, wanted %d", len(out.Folds), len(checkpoint.Folds))
+	}
+	for id, want := range checkpoint.Folds {
+		got := out.Folds[id]
+		if got == nil || got.Iterations != want.Iterations || got.Done != want.Done || got.Accu.Equal(want.Accu) != types.True {
+			t.Errorf("ValueToCheckpoint() got fold %d %v, wanted %v", id, got, want)
+		}
+	}
+
+	invalid := []*exprpb.Value{
+		{Kind: &exprpb.Value_BoolValue{BoolValue: true}},
+		{Kind: &exprpb.Value_MapValue{MapValue: &exprpb.MapValue{Entries: []*exprpb.MapValue_Entry{
+			{Key: &exprpb.Value{Kind: &exprpb.Value_StringValue{StringValue: "1"}}, Value: val.GetMapValue().GetEntries()[0].GetValue()},
+		}}}},
+		{Kind: &exprpb.Value_MapValue{MapValue: &exprpb.MapValue{Entries: []*exprpb.MapValue_Entry{
+			{Key: &exprpb.Value{Kind: &exprpb.Value_Int64Value{Int64Value: 1}}, Value: &exprpb.Value{Kind: &exprpb.Value_NullValue{}}},
+		}}}},
+	}
+	for _, v := range invalid {
+		if _, err := ValueToCheckpoint(types.DefaultTypeAdapter, v); err == nil {
+			t.Errorf("ValueToCheckpoint(%v) succeeded, wanted an error", v)
+		}
+	}
+}
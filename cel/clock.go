@@ -0,0 +1,118 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+)
+
+// ClockVar is the name of the activation binding which supplies the time returned by now() for a
+// single evaluation, taking precedence over the clock configured with the Clock option.
+//
+// The binding must be either a time.Time, which is returned by every call to now() within the
+// evaluation, or a func() time.Time which is called for each call to now(). Bindings of any other
+// type produce an error when now() is evaluated. The name is not a valid identifier, and so the
+// binding cannot be referenced by expressions.
+const ClockVar = "@clock"
+
+const (
+	nowLibraryName = "cel.lib.now"
+	nowFunction    = "now"
+	nowOverload    = "now"
+)
+
+// Clock configures the source of the time returned by now() when the evaluation does not bind
+// ClockVar. By default, now() returns the current wall clock time, unless the environment is
+// configured with DeterministicEval, in which case evaluations must bind ClockVar.
+//
+// The option has no effect unless the environment is configured with NowFunction.
+func Clock(now func() time.Time) ProgramOption {
+	return func(p *prog) (*prog, error) {
+		p.clock = now
+		return p, nil
+	}
+}
+
+type nowLibrary struct{}
+
+// LibraryName implements the SingletonLibrary interface method.
+func (nowLibrary) LibraryName() string {
+	return nowLibraryName
+}
+
+// CompileOptions implements the Library interface method.
+func (nowLibrary) CompileOptions() []EnvOption {
+	return []EnvOption{
+		Function(nowFunction,
+			Overload(nowOverload, []*Type{}, TimestampType,
+				FunctionBinding(func(...ref.Val) ref.Val {
+					return types.Timestamp{Time: time.Now()}
+				})),
+			NonDeterministic(),
+			programSourced()),
+	}
+}
+
+// ProgramOptions implements the Library interface method.
+func (nowLibrary) ProgramOptions() []ProgramOption {
+	return []ProgramOption{}
+}
+
+// clockCalls returns a decorator which evaluates calls to now() using the clock bound within the
+// activation, or the given clock when none is bound. When the clock is nil, the wall clock is used
+// unless evaluation is deterministic, in which case calls without a bound clock produce an error.
+func clockCalls(clock func() time.Time, deterministic bool) interpreter.InterpretableDecorator {
+	if clock == nil && !deterministic {
+		clock = time.Now
+	}
+	return func(i interpreter.Interpretable) (interpreter.Interpretable, error) {
+		call, ok := i.(interpreter.InterpretableCall)
+		if !ok || call.OverloadID() != nowOverload {
+			return i, nil
+		}
+		return &evalNow{InterpretableCall: call, clock: clock}, nil
+	}
+}
+
+// evalNow evaluates a call to now().
+type evalNow struct {
+	interpreter.InterpretableCall
+	clock func() time.Time
+}
+
+// Eval implements the Interpretable interface method.
+func (n *evalNow) Eval(vars interpreter.Activation) ref.Val {
+	clock := n.clock
+	if binding, found := vars.ResolveName(ClockVar); found {
+		switch c := binding.(type) {
+		case time.Time:
+			return types.Timestamp{Time: c}
+		case func() time.Time:
+			clock = c
+		default:
+			return types.NewErr("invalid %s binding of type %T, wanted time.Time or func() time.Time",
+				ClockVar, binding)
+		}
+	}
+	if clock == nil {
+		return types.NewErr("now() requires the Clock option or a %s binding in deterministic evaluation mode",
+			ClockVar)
+	}
+	return types.Timestamp{Time: clock()}
+}
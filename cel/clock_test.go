@@ -0,0 +1,109 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+func TestNowFunction(t *testing.T) {
+	env, err := NewEnv(NowFunction(), Variable("deadline", TimestampType))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`now() < deadline`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	deadline := time.Unix(100, 0).UTC()
+	tests := []struct {
+		opts []ProgramOption
+		vars map[string]any
+		want ref.Val
+	}{
+		{vars: map[string]any{"deadline": deadline}, want: types.False},
+		{
+			opts: []ProgramOption{Clock(func() time.Time { return time.Unix(50, 0) })},
+			vars: map[string]any{"deadline": deadline},
+			want: types.True,
+		},
+		{
+			opts: []ProgramOption{Clock(func() time.Time { return time.Unix(50, 0) })},
+			vars: map[string]any{"deadline": deadline, ClockVar: time.Unix(150, 0)},
+			want: types.False,
+		},
+		{
+			opts: []ProgramOption{EvalOptions(OptOptimize)},
+			vars: map[string]any{"deadline": deadline, ClockVar: func() time.Time { return time.Unix(99, 0) }},
+			want: types.True,
+		},
+	}
+	for i, tc := range tests {
+		prg, err := env.Program(ast, tc.opts...)
+		if err != nil {
+			t.Fatalf("env.Program() failed: %v", err)
+		}
+		out, _, err := prg.Eval(tc.vars)
+		if err != nil {
+			t.Fatalf("prg.Eval() failed: %v", err)
+		}
+		if out != tc.want {
+			t.Errorf("test %d: prg.Eval() got %v, wanted %v", i, out, tc.want)
+		}
+	}
+
+	prg, err := env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	_, _, err = prg.Eval(map[string]any{"deadline": deadline, ClockVar: "yesterday"})
+	if err == nil || !strings.Contains(err.Error(), "invalid @clock binding of type string") {
+		t.Errorf("prg.Eval() got error %v, wanted invalid binding error", err)
+	}
+
+	detEnv, err := env.Extend(DeterministicEval())
+	if err != nil {
+		t.Fatalf("env.Extend() failed: %v", err)
+	}
+	// The time of now() is supplied by the program or the activation in deterministic mode.
+	detAst, iss := detEnv.Compile(`now() < deadline`)
+	if iss.Err() != nil {
+		t.Fatalf("detEnv.Compile() failed: %v", iss.Err())
+	}
+	pinned, err := detEnv.Program(detAst, Clock(func() time.Time { return time.Unix(50, 0) }))
+	if err != nil {
+		t.Fatalf("detEnv.Program() failed: %v", err)
+	}
+	if out, _, err := pinned.Eval(map[string]any{"deadline": deadline}); err != nil || out != types.True {
+		t.Errorf("pinned.Eval() got %v, %v, wanted true", out, err)
+	}
+	prg, err = detEnv.Program(detAst)
+	if err != nil {
+		t.Fatalf("detEnv.Program() failed: %v", err)
+	}
+	out, _, err := prg.Eval(map[string]any{"deadline": deadline, ClockVar: time.Unix(150, 0)})
+	if err != nil || out != types.False {
+		t.Errorf("prg.Eval() with %s got %v, %v, wanted false", ClockVar, out, err)
+	}
+	_, _, err = prg.Eval(map[string]any{"deadline": deadline})
+	if err == nil || !strings.Contains(err.Error(), "now() requires the Clock option or a @clock binding") {
+		t.Errorf("prg.Eval() without a clock got error %v, wanted missing clock error", err)
+	}
+}
@@ -0,0 +1,470 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package codegen emits Go source code for checked CEL expressions so that rarely changing
+// expressions may be compiled ahead-of-time into a binary rather than interpreted.
+//
+// Code generation supports the standard library operators and functions, including macros.
+// Expressions which use custom functions, message construction, optional values, or other
+// features which are not supported by the generator produce an error from Generate, and should
+// be evaluated with a cel.Program instead. Likewise, environments which alter the semantics of the
+// standard library, for example with an integer overflow policy or function bindings, produce an
+// error, as described by cel.Env.CheckStandardEval.
+//
+// Each generated function has the signature of codegen.Func:
+//
+//	func Name(adapter ref.TypeAdapter, vars interpreter.Activation) ref.Val
+//
+// Partial evaluation and unknown attribute patterns are not supported by generated code.
+package codegen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker"
+	"github.com/google/cel-go/common/operators"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Generate emits a Go source file in package `pkg` which contains one function per entry in the
+// programs map, named by the map key, with the semantics of evaluating the programs within env.
+//
+// The Asts must be checked, and the map keys must be valid exported or unexported Go identifiers.
+func Generate(env *cel.Env, pkg string, programs map[string]*cel.Ast) ([]byte, error) {
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("invalid package name: %q", pkg)
+	}
+	names := make([]string, 0, len(programs))
+	for name := range programs {
+		if !token.IsIdentifier(name) {
+			return nil, fmt.Errorf("invalid function name: %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by cel/codegen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	buf.WriteString("import (\n")
+	buf.WriteString("\t\"github.com/google/cel-go/cel/codegen\"\n")
+	buf.WriteString("\t\"github.com/google/cel-go/common/types\"\n")
+	buf.WriteString("\t\"github.com/google/cel-go/common/types/ref\"\n")
+	buf.WriteString("\t\"github.com/google/cel-go/interpreter\"\n")
+	buf.WriteString(")\n\n")
+	buf.WriteString("var (\n")
+	buf.WriteString("\t_ = codegen.Var\n")
+	buf.WriteString("\t_ = types.True\n")
+	buf.WriteString(")\n")
+	for _, name := range names {
+		fn, err := generateFunc(env, name, programs[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		buf.WriteString(fn)
+	}
+	return format.Source(buf.Bytes())
+}
+
+func generateFunc(env *cel.Env, name string, ast *cel.Ast) (string, error) {
+	if !ast.IsChecked() {
+		return "", errors.New("code generation requires a checked ast")
+	}
+	if err := env.CheckStandardEval(ast); err != nil {
+		return "", err
+	}
+	checked, err := cel.AstToCheckedExpr(ast)
+	if err != nil {
+		return "", err
+	}
+	g := &generator{
+		refMap: checked.GetReferenceMap(),
+		scopes: map[string][]string{},
+	}
+	result, err := g.gen(checked.GetExpr())
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if src := ast.Source(); src != nil && src.Content() != "" {
+		fmt.Fprintf(&buf, "\n// %s evaluates the expression:\n//\n", name)
+		for _, line := range strings.Split(src.Content(), "\n") {
+			fmt.Fprintf(&buf, "//\t%s\n", strings.TrimRight(line, " \t"))
+		}
+	} else {
+		fmt.Fprintf(&buf, "\n// %s evaluates a generated CEL expression.\n", name)
+	}
+	fmt.Fprintf(&buf, "func %s(adapter ref.TypeAdapter, vars interpreter.Activation) ref.Val {\n", name)
+	buf.WriteString(g.body.String())
+	fmt.Fprintf(&buf, "return %s\n}\n", result)
+	return buf.String(), nil
+}
+
+type generator struct {
+	refMap map[int64]*exprpb.Reference
+	body   strings.Builder
+	nextID int
+	// scopes maps comprehension variable names to the Go variables which hold their values.
+	scopes map[string][]string
+}
+
+// gen emits the statements required to compute the expression and returns a Go expression which
+// references the computed value.
+func (g *generator) gen(e *exprpb.Expr) (string, error) {
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_ConstExpr:
+		return genConst(e.GetConstExpr())
+	case *exprpb.Expr_IdentExpr:
+		return g.genIdent(e)
+	case *exprpb.Expr_SelectExpr:
+		return g.genSelect(e)
+	case *exprpb.Expr_CallExpr:
+		return g.genCall(e)
+	case *exprpb.Expr_ListExpr:
+		list := e.GetListExpr()
+		if len(list.GetOptionalIndices()) != 0 {
+			return "", unsupported(e, "optional list elements")
+		}
+		elems, err := g.genAll(list.GetElements())
+		if err != nil {
+			return "", err
+		}
+		return g.assign("codegen.List(adapter%s)", joinArgs(elems)), nil
+	case *exprpb.Expr_StructExpr:
+		return g.genMap(e)
+	case *exprpb.Expr_ComprehensionExpr:
+		return g.genComprehension(e)
+	}
+	return "", unsupported(e, "expression kind")
+}
+
+func genConst(c *exprpb.Constant) (string, error) {
+	switch c.GetConstantKind().(type) {
+	case *exprpb.Constant_BoolValue:
+		if c.GetBoolValue() {
+			return "types.True", nil
+		}
+		return "types.False", nil
+	case *exprpb.Constant_BytesValue:
+		return fmt.Sprintf("types.Bytes(%s)", strconv.Quote(string(c.GetBytesValue()))), nil
+	case *exprpb.Constant_DoubleValue:
+		return fmt.Sprintf("types.Double(%s)", strconv.FormatFloat(c.GetDoubleValue(), 'g', -1, 64)), nil
+	case *exprpb.Constant_Int64Value:
+		return fmt.Sprintf("types.Int(%d)", c.GetInt64Value()), nil
+	case *exprpb.Constant_NullValue:
+		return "types.NullValue", nil
+	case *exprpb.Constant_StringValue:
+		return fmt.Sprintf("types.String(%s)", strconv.Quote(c.GetStringValue())), nil
+	case *exprpb.Constant_Uint64Value:
+		return fmt.Sprintf("types.Uint(%d)", c.GetUint64Value()), nil
+	}
+	return "", fmt.Errorf("unsupported constant: %v", c)
+}
+
+func (g *generator) genIdent(e *exprpb.Expr) (string, error) {
+	name := e.GetIdentExpr().GetName()
+	if scope := g.scopes[name]; len(scope) > 0 {
+		return scope[len(scope)-1], nil
+	}
+	return g.genReference(e, name)
+}
+
+// genReference emits the value of a variable or enum constant resolved by the type-checker.
+func (g *generator) genReference(e *exprpb.Expr, name string) (string, error) {
+	ref, found := g.refMap[e.GetId()]
+	if found && ref.GetValue() != nil {
+		return genConst(ref.GetValue())
+	}
+	if found && ref.GetName() != "" {
+		name = ref.GetName()
+	}
+	return g.assign("codegen.Var(adapter, vars, %s)", strconv.Quote(name)), nil
+}
+
+func (g *generator) genSelect(e *exprpb.Expr) (string, error) {
+	sel := e.GetSelectExpr()
+	if ref, found := g.refMap[e.GetId()]; found && ref.GetName() != "" {
+		// The selection was resolved to a qualified variable name by the type-checker.
+		return g.genReference(e, ref.GetName())
+	}
+	operand, err := g.gen(sel.GetOperand())
+	if err != nil {
+		return "", err
+	}
+	if sel.GetTestOnly() {
+		return g.assign("codegen.Has(%s, %s)", operand, strconv.Quote(sel.GetField())), nil
+	}
+	return g.assign("codegen.Select(%s, %s)", operand, strconv.Quote(sel.GetField())), nil
+}
+
+func (g *generator) genMap(e *exprpb.Expr) (string, error) {
+	st := e.GetStructExpr()
+	if st.GetMessageName() != "" {
+		return "", unsupported(e, "message construction")
+	}
+	var args []string
+	for _, entry := range st.GetEntries() {
+		if entry.GetOptionalEntry() {
+			return "", unsupported(e, "optional map entries")
+		}
+		key, err := g.gen(entry.GetMapKey())
+		if err != nil {
+			return "", err
+		}
+		val, err := g.gen(entry.GetValue())
+		if err != nil {
+			return "", err
+		}
+		args = append(args, key, val)
+	}
+	return g.assign("codegen.Map(adapter%s)", joinArgs(args)), nil
+}
+
+func (g *generator) genCall(e *exprpb.Expr) (string, error) {
+	call := e.GetCallExpr()
+	fn := call.GetFunction()
+	args := call.GetArgs()
+	switch fn {
+	case operators.LogicalAnd, operators.LogicalOr:
+		return g.genLogical(fn, args)
+	case operators.Conditional:
+		return g.genConditional(args)
+	}
+	if err := g.checkStandard(e); err != nil {
+		return "", err
+	}
+	var argExprs []string
+	if call.GetTarget() != nil {
+		target, err := g.gen(call.GetTarget())
+		if err != nil {
+			return "", err
+		}
+		argExprs = append(argExprs, target)
+	}
+	rest, err := g.genAll(args)
+	if err != nil {
+		return "", err
+	}
+	argExprs = append(argExprs, rest...)
+	if helper, found := operatorHelpers[fn]; found && len(argExprs) == helper.arity {
+		return g.assign("codegen.%s(%s)", helper.name, strings.Join(argExprs, ", ")), nil
+	}
+	overload := ""
+	if ids := g.refMap[e.GetId()].GetOverloadId(); len(ids) == 1 {
+		overload = ids[0]
+	}
+	return g.assign("codegen.Call(%s, %s%s)", strconv.Quote(fn), strconv.Quote(overload), joinArgs(argExprs)), nil
+}
+
+// checkStandard ensures that the function call resolves only to standard library overloads, as
+// the implementations of custom functions are not available to generated code.
+func (g *generator) checkStandard(e *exprpb.Expr) error {
+	ids := g.refMap[e.GetId()].GetOverloadId()
+	if len(ids) == 0 {
+		return unsupported(e, fmt.Sprintf("unresolved function '%s'", e.GetCallExpr().GetFunction()))
+	}
+	for _, id := range ids {
+		if _, found := standardOverloadIDs[id]; !found {
+			return unsupported(e, fmt.Sprintf("custom function '%s' (overload %s)", e.GetCallExpr().GetFunction(), id))
+		}
+	}
+	return nil
+}
+
+func (g *generator) genLogical(fn string, args []*exprpb.Expr) (string, error) {
+	lhs, err := g.gen(args[0])
+	if err != nil {
+		return "", err
+	}
+	result := g.declare()
+	shortCircuit, merge := "types.False", "codegen.LogicalAnd"
+	if fn == operators.LogicalOr {
+		shortCircuit, merge = "types.True", "codegen.LogicalOr"
+	}
+	fmt.Fprintf(&g.body, "if %s == %s {\n%s = %s\n} else {\n", lhs, shortCircuit, result, shortCircuit)
+	rhs, err := g.gen(args[1])
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(&g.body, "%s = %s(%s, %s)\n}\n", result, merge, lhs, rhs)
+	return result, nil
+}
+
+func (g *generator) genConditional(args []*exprpb.Expr) (string, error) {
+	cond, err := g.gen(args[0])
+	if err != nil {
+		return "", err
+	}
+	result := g.declare()
+	fmt.Fprintf(&g.body, "switch %s {\ncase types.True:\n", cond)
+	t, err := g.gen(args[1])
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(&g.body, "%s = %s\ncase types.False:\n", result, t)
+	f, err := g.gen(args[2])
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(&g.body, "%s = %s\ndefault:\n%s = codegen.Conditional(%s)\n}\n", result, f, result, cond)
+	return result, nil
+}
+
+func (g *generator) genComprehension(e *exprpb.Expr) (string, error) {
+	comp := e.GetComprehensionExpr()
+	iterRange, err := g.gen(comp.GetIterRange())
+	if err != nil {
+		return "", err
+	}
+	accuInit, err := g.gen(comp.GetAccuInit())
+	if err != nil {
+		return "", err
+	}
+	result := g.declare()
+	accu := g.declare()
+	iter := g.declare()
+	it := g.tempName()
+	errVal := g.tempName()
+	fmt.Fprintf(&g.body, "%s = %s\n", accu, accuInit)
+	fmt.Fprintf(&g.body, "if %s, %s := codegen.Iterator(%s); %s != nil {\n%s = %s\n} else {\n",
+		it, errVal, iterRange, errVal, result, errVal)
+	fmt.Fprintf(&g.body, "for %s.HasNext() == types.True {\n%s = %s.Next()\n", it, iter, it)
+
+	g.pushScope(comp.GetAccuVar(), accu)
+	g.pushScope(comp.GetIterVar(), iter)
+	cond, err := g.gen(comp.GetLoopCondition())
+	if err != nil {
+		return "", err
+	}
+	switch cond {
+	case "types.True":
+		// The loop condition of macros which never short-circuit, such as map() and filter().
+	case "types.False":
+		g.body.WriteString("break\n")
+	default:
+		fmt.Fprintf(&g.body, "if c, ok := %s.(types.Bool); ok && c != types.True {\nbreak\n}\n", cond)
+	}
+	step, err := g.gen(comp.GetLoopStep())
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(&g.body, "%s = %s\n}\n", accu, step)
+	g.popScope(comp.GetIterVar())
+	res, err := g.gen(comp.GetResult())
+	if err != nil {
+		return "", err
+	}
+	g.popScope(comp.GetAccuVar())
+	fmt.Fprintf(&g.body, "%s = %s\n}\n", result, res)
+	// The iteration variable may not be referenced within the loop.
+	fmt.Fprintf(&g.body, "_ = %s\n", iter)
+	return result, nil
+}
+
+func (g *generator) genAll(exprs []*exprpb.Expr) ([]string, error) {
+	out := make([]string, len(exprs))
+	for i, e := range exprs {
+		val, err := g.gen(e)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = val
+	}
+	return out, nil
+}
+
+// assign emits a statement which assigns the formatted expression to a new variable.
+func (g *generator) assign(format string, args ...any) string {
+	name := g.tempName()
+	fmt.Fprintf(&g.body, "%s := %s\n", name, fmt.Sprintf(format, args...))
+	return name
+}
+
+// declare emits the declaration of a new variable which is assigned by subsequent statements.
+func (g *generator) declare() string {
+	name := g.tempName()
+	fmt.Fprintf(&g.body, "var %s ref.Val\n", name)
+	return name
+}
+
+func (g *generator) tempName() string {
+	g.nextID++
+	return fmt.Sprintf("v%d", g.nextID)
+}
+
+func (g *generator) pushScope(name, goName string) {
+	g.scopes[name] = append(g.scopes[name], goName)
+}
+
+func (g *generator) popScope(name string) {
+	scope := g.scopes[name]
+	g.scopes[name] = scope[:len(scope)-1]
+}
+
+func joinArgs(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return ", " + strings.Join(args, ", ")
+}
+
+func unsupported(e *exprpb.Expr, feature string) error {
+	return fmt.Errorf("expression id %d: unsupported by code generation: %s", e.GetId(), feature)
+}
+
+type operatorHelper struct {
+	name  string
+	arity int
+}
+
+var (
+	operatorHelpers = map[string]operatorHelper{
+		operators.Add:              {name: "Add", arity: 2},
+		operators.Subtract:         {name: "Subtract", arity: 2},
+		operators.Multiply:         {name: "Multiply", arity: 2},
+		operators.Divide:           {name: "Divide", arity: 2},
+		operators.Modulo:           {name: "Modulo", arity: 2},
+		operators.Less:             {name: "Less", arity: 2},
+		operators.LessEquals:       {name: "LessEquals", arity: 2},
+		operators.Greater:          {name: "Greater", arity: 2},
+		operators.GreaterEquals:    {name: "GreaterEquals", arity: 2},
+		operators.Equals:           {name: "Equal", arity: 2},
+		operators.NotEquals:        {name: "NotEqual", arity: 2},
+		operators.Index:            {name: "Index", arity: 2},
+		operators.In:               {name: "In", arity: 2},
+		operators.OldIn:            {name: "In", arity: 2},
+		operators.Negate:           {name: "Negate", arity: 1},
+		operators.LogicalNot:       {name: "Not", arity: 1},
+		operators.NotStrictlyFalse: {name: "NotStrictlyFalse", arity: 1},
+	}
+
+	standardOverloadIDs = map[string]struct{}{}
+)
+
+func init() {
+	for _, decl := range checker.StandardDeclarations() {
+		for _, o := range decl.GetFunction().GetOverloads() {
+			standardOverloadIDs[o.GetOverloadId()] = struct{}{}
+		}
+	}
+}
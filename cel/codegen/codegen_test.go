@@ -0,0 +1,272 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/cel/codegen"
+	"github.com/google/cel-go/common/overloads"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+)
+
+// generatedFile contains the output of Generate for the testPrograms. When the generator changes,
+// the file may be regenerated by running the tests with CEL_CODEGEN_UPDATE=1.
+const generatedFile = "zz_generated_test.go"
+
+var testPrograms = map[string]string{
+	"arithmetic":    `(x + 2) * 3 - y % 5`,
+	"logicalAnd":    `x > 0 && name.startsWith("a")`,
+	"logicalOr":     `x < 0 || y / x == 1`,
+	"ternary":       `x > y ? "greater" : name`,
+	"listLiteral":   `[x, y, x + y][2]`,
+	"mapLiteral":    `{"a": x, "b": y}[name] == 1`,
+	"membership":    `name in ["alice", "bob"] && !(x in [1, 2, 3])`,
+	"selection":     `attrs.role == "admin" && has(attrs.role)`,
+	"allMacro":      `items.all(i, i > 0)`,
+	"existsMacro":   `items.exists(i, i == x)`,
+	"mapMacro":      `items.map(i, i * 2).filter(j, j > x)`,
+	"nestedMacro":   `items.all(i, items.exists(j, i <= j))`,
+	"conversions":   `string(x) + ":" + string(double(y)) + ":" + string(uint(x) + 1u)`,
+	"sizeAndMethod": `size(name) > 2 && name.contains("li") && size(items) == 3`,
+	"constants":     `b"\x00abc" != b"" && 1.5e3 > 2.0 && null == null && -x < 0`,
+}
+
+func testEnv(t testing.TB) *cel.Env {
+	t.Helper()
+	env, err := cel.NewEnv(
+		cel.Variable("x", cel.IntType),
+		cel.Variable("y", cel.IntType),
+		cel.Variable("name", cel.StringType),
+		cel.Variable("items", cel.ListType(cel.IntType)),
+		cel.Variable("attrs", cel.MapType(cel.StringType, cel.StringType)),
+	)
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	return env
+}
+
+func compileTestPrograms(t testing.TB, env *cel.Env) map[string]*cel.Ast {
+	t.Helper()
+	asts := make(map[string]*cel.Ast, len(testPrograms))
+	for name, expr := range testPrograms {
+		ast, iss := env.Compile(expr)
+		if iss.Err() != nil {
+			t.Fatalf("env.Compile(%q) failed: %v", expr, iss.Err())
+		}
+		asts[name] = ast
+	}
+	return asts
+}
+
+func TestGenerate(t *testing.T) {
+	env := testEnv(t)
+	out, err := codegen.Generate(env, "codegen_test", compileTestPrograms(t, env))
+	if err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+	if os.Getenv("CEL_CODEGEN_UPDATE") != "" {
+		if err := os.WriteFile(generatedFile, out, 0644); err != nil {
+			t.Fatalf("os.WriteFile() failed: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(generatedFile)
+	if err != nil {
+		t.Fatalf("os.ReadFile() failed: %v", err)
+	}
+	if string(out) != string(want) {
+		t.Errorf("Generate() output differs from %s, rerun with CEL_CODEGEN_UPDATE=1", generatedFile)
+	}
+}
+
+func TestGeneratedEval(t *testing.T) {
+	env := testEnv(t)
+	asts := compileTestPrograms(t, env)
+	funcs := map[string]codegen.Func{
+		"arithmetic":    arithmetic,
+		"logicalAnd":    logicalAnd,
+		"logicalOr":     logicalOr,
+		"ternary":       ternary,
+		"listLiteral":   listLiteral,
+		"mapLiteral":    mapLiteral,
+		"membership":    membership,
+		"selection":     selection,
+		"allMacro":      allMacro,
+		"existsMacro":   existsMacro,
+		"mapMacro":      mapMacro,
+		"nestedMacro":   nestedMacro,
+		"conversions":   conversions,
+		"sizeAndMethod": sizeAndMethod,
+		"constants":     constants,
+	}
+	inputs := []map[string]any{
+		{"x": 1, "y": 2, "name": "alice", "items": []int64{1, 2, 3}, "attrs": map[string]string{"role": "admin"}},
+		{"x": 0, "y": 7, "name": "bob", "items": []int64{-1, 0, 5}, "attrs": map[string]string{}},
+		{"x": -4, "y": -4, "name": "al", "items": []int64{}, "attrs": map[string]string{"role": "user"}},
+		// Missing variables produce errors in both the interpreter and generated code.
+		{"x": 3, "name": "carol"},
+	}
+	for name, ast := range asts {
+		fn, found := funcs[name]
+		if !found {
+			t.Fatalf("no generated function for program %q", name)
+		}
+		prg, err := env.Program(ast)
+		if err != nil {
+			t.Fatalf("env.Program(%q) failed: %v", name, err)
+		}
+		for _, in := range inputs {
+			vars, err := interpreter.NewActivation(in)
+			if err != nil {
+				t.Fatalf("interpreter.NewActivation() failed: %v", err)
+			}
+			want, _, _ := prg.Eval(vars)
+			got := fn(types.DefaultTypeAdapter, vars)
+			if !sameResult(got, want) {
+				t.Errorf("%s(%v) got %v, wanted %v", name, in, got, want)
+			}
+		}
+	}
+}
+
+func sameResult(got, want ref.Val) bool {
+	if types.IsError(want) {
+		return types.IsError(got)
+	}
+	return got.Equal(want) == types.True
+}
+
+func TestGenerateErrors(t *testing.T) {
+	env, err := cel.NewEnv(
+		cel.Variable("x", cel.IntType),
+		cel.Function("double_it",
+			cel.Overload("double_it_int", []*cel.Type{cel.IntType}, cel.IntType)),
+	)
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	checked, iss := env.Compile(`double_it(x) > 2`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	parsed, iss := env.Parse(`x > 2`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Parse() failed: %v", iss.Err())
+	}
+	wrapEnv, err := env.Extend(cel.IntegerOverflowPolicy(interpreter.OverflowWrap))
+	if err != nil {
+		t.Fatalf("env.Extend() failed: %v", err)
+	}
+	sum, iss := wrapEnv.Compile(`x + 1`)
+	if iss.Err() != nil {
+		t.Fatalf("wrapEnv.Compile() failed: %v", iss.Err())
+	}
+	boundEnv, err := cel.NewEnv(
+		cel.Variable("x", cel.StringType),
+		cel.Function(overloads.Size,
+			cel.MemberOverload(overloads.SizeStringInst, []*cel.Type{cel.StringType}, cel.IntType,
+				cel.UnaryBinding(func(ref.Val) ref.Val { return types.Int(0) }))),
+	)
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	size, iss := boundEnv.Compile(`x.size()`)
+	if iss.Err() != nil {
+		t.Fatalf("boundEnv.Compile() failed: %v", iss.Err())
+	}
+	containerEnv, err := cel.NewEnv(
+		cel.Container("com.example"),
+		cel.Variable("com.example.x", cel.IntType),
+	)
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	resolved, iss := containerEnv.Compile(`x > 2`)
+	if iss.Err() != nil {
+		t.Fatalf("containerEnv.Compile() failed: %v", iss.Err())
+	}
+	tests := []struct {
+		name     string
+		env      *cel.Env
+		programs map[string]*cel.Ast
+		pkg      string
+		err      string
+	}{
+		{
+			name:     "custom function",
+			env:      env,
+			programs: map[string]*cel.Ast{"Custom": checked},
+			pkg:      "policies",
+			err:      "custom function 'double_it'",
+		},
+		{
+			name:     "unchecked",
+			env:      env,
+			programs: map[string]*cel.Ast{"Parsed": parsed},
+			pkg:      "policies",
+			err:      "requires a checked ast",
+		},
+		{
+			name:     "invalid function name",
+			env:      env,
+			programs: map[string]*cel.Ast{"not-valid": checked},
+			pkg:      "policies",
+			err:      "invalid function name",
+		},
+		{
+			name: "invalid package name",
+			env:  env,
+			pkg:  "1policies",
+			err:  "invalid package name",
+		},
+		{
+			name:     "overflow policy",
+			env:      wrapEnv,
+			programs: map[string]*cel.Ast{"Sum": sum},
+			pkg:      "policies",
+			err:      "unsupported integer overflow policy",
+		},
+		{
+			name:     "function binding",
+			env:      boundEnv,
+			programs: map[string]*cel.Ast{"Size": size},
+			pkg:      "policies",
+			err:      "unsupported binding of overload 'string_size'",
+		},
+		{
+			name:     "container resolution",
+			env:      env,
+			programs: map[string]*cel.Ast{"Resolved": resolved},
+			pkg:      "policies",
+			err:      "outside of container",
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := codegen.Generate(tc.env, tc.pkg, tc.programs)
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("Generate() got error %v, wanted error containing %q", err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,364 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/interpreter"
+	"github.com/google/cel-go/interpreter/functions"
+)
+
+// The functions within this file are the runtime support library referenced by generated code.
+// They implement the same semantics as the interpreter's evaluation of the standard library.
+
+// Func is the signature of a generated program.
+type Func func(adapter ref.TypeAdapter, vars interpreter.Activation) ref.Val
+
+// Var resolves a variable from the activation.
+func Var(adapter ref.TypeAdapter, vars interpreter.Activation, name string) ref.Val {
+	val, found := vars.ResolveName(name)
+	if !found {
+		return types.NewErr("no such attribute: %s", name)
+	}
+	return adapter.NativeToValue(val)
+}
+
+// Select returns the value of a field on a map or message.
+func Select(operand ref.Val, field string) ref.Val {
+	if types.IsUnknownOrError(operand) {
+		return operand
+	}
+	indexer, ok := operand.(traits.Indexer)
+	if !ok {
+		return types.NewErr("no such overload: select of field '%s' on type %s", field, operand.Type().TypeName())
+	}
+	return indexer.Get(types.String(field))
+}
+
+// Has tests for the presence of a field on a map or message.
+func Has(operand ref.Val, field string) ref.Val {
+	if types.IsUnknownOrError(operand) {
+		return operand
+	}
+	switch o := operand.(type) {
+	case traits.FieldTester:
+		return o.IsSet(types.String(field))
+	case traits.Mapper:
+		return o.Contains(types.String(field))
+	}
+	return types.NewErr("no such overload: has() of field '%s' on type %s", field, operand.Type().TypeName())
+}
+
+// List creates a list literal from the element values.
+func List(adapter ref.TypeAdapter, elems ...ref.Val) ref.Val {
+	for _, elem := range elems {
+		if types.IsUnknownOrError(elem) {
+			return elem
+		}
+	}
+	return adapter.NativeToValue(elems)
+}
+
+// Map creates a map literal from alternating key and value arguments.
+func Map(adapter ref.TypeAdapter, keyValues ...ref.Val) ref.Val {
+	entries := make(map[ref.Val]ref.Val, len(keyValues)/2)
+	for i := 0; i < len(keyValues); i += 2 {
+		key, val := keyValues[i], keyValues[i+1]
+		if types.IsUnknownOrError(key) {
+			return key
+		}
+		if types.IsUnknownOrError(val) {
+			return val
+		}
+		entries[key] = val
+	}
+	return adapter.NativeToValue(entries)
+}
+
+// Equal implements the `==` operator.
+func Equal(lhs, rhs ref.Val) ref.Val {
+	if types.IsUnknownOrError(lhs) {
+		return lhs
+	}
+	if types.IsUnknownOrError(rhs) {
+		return rhs
+	}
+	return types.Equal(lhs, rhs)
+}
+
+// NotEqual implements the `!=` operator.
+func NotEqual(lhs, rhs ref.Val) ref.Val {
+	if types.IsUnknownOrError(lhs) {
+		return lhs
+	}
+	if types.IsUnknownOrError(rhs) {
+		return rhs
+	}
+	return types.Bool(types.Equal(lhs, rhs) != types.True)
+}
+
+// LogicalAnd computes the result of `lhs && rhs` once the left-hand side is known not to be false.
+func LogicalAnd(lhs, rhs ref.Val) ref.Val {
+	if rhs == types.False {
+		return types.False
+	}
+	_, lok := lhs.(types.Bool)
+	_, rok := rhs.(types.Bool)
+	if lok && rok {
+		return types.True
+	}
+	return logicalErr(lhs, rhs)
+}
+
+// LogicalOr computes the result of `lhs || rhs` once the left-hand side is known not to be true.
+func LogicalOr(lhs, rhs ref.Val) ref.Val {
+	if rhs == types.True {
+		return types.True
+	}
+	_, lok := lhs.(types.Bool)
+	_, rok := rhs.(types.Bool)
+	if lok && rok {
+		return types.False
+	}
+	return logicalErr(lhs, rhs)
+}
+
+func logicalErr(lhs, rhs ref.Val) ref.Val {
+	if types.IsUnknown(lhs) {
+		return lhs
+	}
+	if types.IsUnknown(rhs) {
+		return rhs
+	}
+	if types.IsError(lhs) {
+		return lhs
+	}
+	return types.ValOrErr(rhs, "no such overload")
+}
+
+// Conditional returns the error or unknown value for a non-boolean ternary condition.
+func Conditional(cond ref.Val) ref.Val {
+	return types.ValOrErr(cond, "no such overload")
+}
+
+// Iterator returns an iterator over the comprehension range, or an error value if the range
+// is not iterable.
+func Iterator(iterRange ref.Val) (traits.Iterator, ref.Val) {
+	iterable, ok := iterRange.(traits.Iterable)
+	if !ok {
+		return nil, types.ValOrErr(iterRange, "got '%T', expected iterable type", iterRange)
+	}
+	return iterable.Iterator(), nil
+}
+
+// Add implements the `+` operator.
+func Add(lhs, rhs ref.Val) ref.Val {
+	return call2(addOp, operators.Add, lhs, rhs)
+}
+
+// Subtract implements the binary `-` operator.
+func Subtract(lhs, rhs ref.Val) ref.Val {
+	return call2(subtractOp, operators.Subtract, lhs, rhs)
+}
+
+// Multiply implements the `*` operator.
+func Multiply(lhs, rhs ref.Val) ref.Val {
+	return call2(multiplyOp, operators.Multiply, lhs, rhs)
+}
+
+// Divide implements the `/` operator.
+func Divide(lhs, rhs ref.Val) ref.Val {
+	return call2(divideOp, operators.Divide, lhs, rhs)
+}
+
+// Modulo implements the `%` operator.
+func Modulo(lhs, rhs ref.Val) ref.Val {
+	return call2(moduloOp, operators.Modulo, lhs, rhs)
+}
+
+// Less implements the `<` operator.
+func Less(lhs, rhs ref.Val) ref.Val {
+	return call2(lessOp, operators.Less, lhs, rhs)
+}
+
+// LessEquals implements the `<=` operator.
+func LessEquals(lhs, rhs ref.Val) ref.Val {
+	return call2(lessEqualsOp, operators.LessEquals, lhs, rhs)
+}
+
+// Greater implements the `>` operator.
+func Greater(lhs, rhs ref.Val) ref.Val {
+	return call2(greaterOp, operators.Greater, lhs, rhs)
+}
+
+// GreaterEquals implements the `>=` operator.
+func GreaterEquals(lhs, rhs ref.Val) ref.Val {
+	return call2(greaterEqualsOp, operators.GreaterEquals, lhs, rhs)
+}
+
+// Index implements the `[]` operator.
+func Index(operand, index ref.Val) ref.Val {
+	return call2(indexOp, operators.Index, operand, index)
+}
+
+// In implements the `in` operator.
+func In(elem, container ref.Val) ref.Val {
+	return call2(inOp, operators.In, elem, container)
+}
+
+// Negate implements the unary `-` operator.
+func Negate(val ref.Val) ref.Val {
+	return call1(negateOp, operators.Negate, val)
+}
+
+// Not implements the `!` operator.
+func Not(val ref.Val) ref.Val {
+	return call1(notOp, operators.LogicalNot, val)
+}
+
+// NotStrictlyFalse implements the internal function used by comprehension loop conditions.
+func NotStrictlyFalse(val ref.Val) ref.Val {
+	return call1(notStrictlyFalseOp, operators.NotStrictlyFalse, val)
+}
+
+// Call invokes a standard library function by name, falling back to the receiver-style
+// implementation on the first argument when the standard library does not define the function.
+func Call(function, overload string, args ...ref.Val) ref.Val {
+	fn := standardOverloads[function]
+	switch len(args) {
+	case 0:
+		if fn != nil && fn.Function != nil {
+			return fn.Function()
+		}
+		return types.NewErr("no such overload: %s", function)
+	case 1:
+		return callUnary(fn, function, overload, args[0])
+	case 2:
+		return callBinary(fn, function, overload, args[0], args[1])
+	}
+	strict := fn == nil || !fn.NonStrict
+	if strict {
+		for _, arg := range args {
+			if types.IsUnknownOrError(arg) {
+				return arg
+			}
+		}
+	}
+	if fn != nil && fn.Function != nil && matchesTrait(fn, strict, args[0]) {
+		return fn.Function(args...)
+	}
+	return receive(function, overload, args[0], args[1:]...)
+}
+
+func call1(fn *functions.Overload, function string, arg ref.Val) ref.Val {
+	return callUnary(fn, function, "", arg)
+}
+
+func call2(fn *functions.Overload, function string, lhs, rhs ref.Val) ref.Val {
+	return callBinary(fn, function, "", lhs, rhs)
+}
+
+func callUnary(fn *functions.Overload, function, overload string, arg ref.Val) ref.Val {
+	strict := fn == nil || !fn.NonStrict
+	if strict && types.IsUnknownOrError(arg) {
+		return arg
+	}
+	if fn != nil && matchesTrait(fn, strict, arg) {
+		if fn.Unary != nil {
+			return fn.Unary(arg)
+		}
+		if fn.Function != nil {
+			return fn.Function(arg)
+		}
+	}
+	return receive(function, overload, arg)
+}
+
+func callBinary(fn *functions.Overload, function, overload string, lhs, rhs ref.Val) ref.Val {
+	strict := fn == nil || !fn.NonStrict
+	if strict {
+		if types.IsUnknownOrError(lhs) {
+			return lhs
+		}
+		if types.IsUnknownOrError(rhs) {
+			return rhs
+		}
+	}
+	if fn != nil && matchesTrait(fn, strict, lhs) {
+		if fn.Binary != nil {
+			return fn.Binary(lhs, rhs)
+		}
+		if fn.Function != nil {
+			return fn.Function(lhs, rhs)
+		}
+	}
+	return receive(function, overload, lhs, rhs)
+}
+
+func matchesTrait(fn *functions.Overload, strict bool, arg0 ref.Val) bool {
+	return fn.OperandTrait == 0 || (!strict && types.IsUnknownOrError(arg0)) ||
+		arg0.Type().HasTrait(fn.OperandTrait)
+}
+
+// receive invokes the function as a method on the receiver, if the receiver supports it.
+func receive(function, overload string, receiver ref.Val, args ...ref.Val) ref.Val {
+	if receiver.Type().HasTrait(traits.ReceiverType) {
+		return receiver.(traits.Receiver).Receive(function, overload, args)
+	}
+	return types.NewErr("no such overload: %s", function)
+}
+
+var (
+	standardOverloads map[string]*functions.Overload
+
+	addOp              *functions.Overload
+	subtractOp         *functions.Overload
+	multiplyOp         *functions.Overload
+	divideOp           *functions.Overload
+	moduloOp           *functions.Overload
+	lessOp             *functions.Overload
+	lessEqualsOp       *functions.Overload
+	greaterOp          *functions.Overload
+	greaterEqualsOp    *functions.Overload
+	indexOp            *functions.Overload
+	inOp               *functions.Overload
+	negateOp           *functions.Overload
+	notOp              *functions.Overload
+	notStrictlyFalseOp *functions.Overload
+)
+
+func init() {
+	standardOverloads = map[string]*functions.Overload{}
+	for _, o := range functions.StandardOverloads() {
+		standardOverloads[o.Operator] = o
+	}
+	addOp = standardOverloads[operators.Add]
+	subtractOp = standardOverloads[operators.Subtract]
+	multiplyOp = standardOverloads[operators.Multiply]
+	divideOp = standardOverloads[operators.Divide]
+	moduloOp = standardOverloads[operators.Modulo]
+	lessOp = standardOverloads[operators.Less]
+	lessEqualsOp = standardOverloads[operators.LessEquals]
+	greaterOp = standardOverloads[operators.Greater]
+	greaterEqualsOp = standardOverloads[operators.GreaterEquals]
+	indexOp = standardOverloads[operators.Index]
+	inOp = standardOverloads[operators.In]
+	negateOp = standardOverloads[operators.Negate]
+	notOp = standardOverloads[operators.LogicalNot]
+	notStrictlyFalseOp = standardOverloads[operators.NotStrictlyFalse]
+}
@@ -0,0 +1,461 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/cel-go/interpreter"
+	"github.com/google/cel-go/parser"
+)
+
+// EnvConfig is the declarative form of an environment, which may be reviewed as configuration and
+// shared between tools and services evaluating the same expressions.
+//
+// A configuration is written in YAML or JSON and parsed by the cel/envconfig package, e.g.
+//
+//	name: "policy"
+//	container: "google.example"
+//	stdlib: [comparisons_only]   # omit to configure the complete standard library
+//	libraries:
+//	  - name: cel.lib.optional
+//	  - name: cel.lib.ext.strings
+//	    version: 1
+//	features:
+//	  cross_type_numeric_comparisons: true
+//	limits:
+//	  parser_recursion_limit: 50
+//	  cost_limit: 1000
+//	variables:
+//	  - name: request
+//	    type: map(string, dyn)
+//	    description: "The request being authorized."
+//	  - name: now
+//	    type: timestamp
+//	functions:
+//	  - name: isAdmin
+//	    overloads:
+//	      - id: isAdmin_string
+//	        args: [string]
+//	        result: bool
+//	  - name: first
+//	    overloads:
+//	      - id: list_first
+//	        member: true
+//	        args: [list(T)]
+//	        result: T
+//	        type_params: [T]
+//
+// Types are written as they are printed by the type-checker: `bool`, `bytes`, `double`, `duration`,
+// `dyn`, `int`, `null_type`, `string`, `timestamp`, `type`, `uint`, `any`, `list(T)`,
+// `map(K, V)`, and `optional_type(T)`. Other names refer to type parameters when they are listed
+// in the `type_params` of an overload, to protobuf messages when used without parameters, and to
+// opaque types when used with parameters.
+//
+// Member overloads take the receiver as their first argument. The functions of a configuration
+// are declared without implementations, which may be supplied by redeclaring the overloads with
+// bindings using the ConfigEnvOptions option, e.g.
+// `ConfigEnvOptions(Function("isAdmin", Overload("isAdmin_string", ..., UnaryBinding(...))))`.
+//
+// The supported libraries are `cel.lib.optional` along with the libraries registered using the
+// ConfigLibrary option. The features are named after the corresponding options:
+// cross_type_numeric_comparisons, default_utc_time_zone, deterministic_eval,
+// eagerly_validate_declarations, homogeneous_aggregate_literals, macro_call_tracking, and
+// null_safe_comparisons. The limits are parser_recursion_limit,
+// expression_size_code_point_limit, and cost_limit. The standard library profiles are
+// comparisons_only, no_string_construction, and no_conversions. The overflow_policy is one of
+// error, wrap, or saturate, as described by the IntegerOverflowPolicy option.
+//
+// Configurations may not be written as textproto, since the schema is not defined by a protobuf
+// message.
+type EnvConfig struct {
+	Name           string            `json:"name,omitempty" yaml:"name,omitempty"`
+	Container      string            `json:"container,omitempty" yaml:"container,omitempty"`
+	StdLib         []string          `json:"stdlib,omitempty" yaml:"stdlib,omitempty"`
+	Libraries      []*LibraryConfig  `json:"libraries,omitempty" yaml:"libraries,omitempty"`
+	Features       map[string]bool   `json:"features,omitempty" yaml:"features,omitempty"`
+	Limits         map[string]int64  `json:"limits,omitempty" yaml:"limits,omitempty"`
+	OverflowPolicy string            `json:"overflow_policy,omitempty" yaml:"overflow_policy,omitempty"`
+	Variables      []*VariableConfig `json:"variables,omitempty" yaml:"variables,omitempty"`
+	Functions      []*FunctionConfig `json:"functions,omitempty" yaml:"functions,omitempty"`
+}
+
+// LibraryConfig selects a library, and optionally the version of the library, by name.
+type LibraryConfig struct {
+	Name    string `json:"name" yaml:"name"`
+	Version int    `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// VariableConfig declares a variable of the given type.
+type VariableConfig struct {
+	Name        string `json:"name" yaml:"name"`
+	Type        string `json:"type" yaml:"type"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// FunctionConfig declares a function with the given overloads.
+type FunctionConfig struct {
+	Name        string            `json:"name" yaml:"name"`
+	Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Overloads   []*OverloadConfig `json:"overloads" yaml:"overloads"`
+}
+
+// OverloadConfig declares an overload of a function.
+type OverloadConfig struct {
+	ID         string   `json:"id" yaml:"id"`
+	Member     bool     `json:"member,omitempty" yaml:"member,omitempty"`
+	Args       []string `json:"args,omitempty" yaml:"args,omitempty"`
+	Result     string   `json:"result" yaml:"result"`
+	TypeParams []string `json:"type_params,omitempty" yaml:"type_params,omitempty"`
+}
+
+// ConfigOption is a functional interface for configuring how an environment is created from an
+// EnvConfig.
+type ConfigOption func(l *configLoader) error
+
+// ConfigLibrary registers a library which configurations may enable by name. The given function
+// returns the EnvOption for the library at the version selected by the configuration, or an error
+// if the version is not supported.
+func ConfigLibrary(name string, lib func(version int) (EnvOption, error)) ConfigOption {
+	return func(l *configLoader) error {
+		l.libraries[name] = lib
+		return nil
+	}
+}
+
+// ConfigEnvOptions appends options to the environment created from the configuration. The options
+// are applied after the configuration, and so may supply the implementations of the functions
+// declared by the configuration.
+func ConfigEnvOptions(opts ...EnvOption) ConfigOption {
+	return func(l *configLoader) error {
+		l.envOpts = append(l.envOpts, opts...)
+		return nil
+	}
+}
+
+// NewEnv creates an environment from the configuration.
+func (c *EnvConfig) NewEnv(opts ...ConfigOption) (*Env, error) {
+	l := &configLoader{
+		libraries: map[string]func(int) (EnvOption, error){
+			optionalLibrary{}.LibraryName(): func(version int) (EnvOption, error) {
+				if version != 0 {
+					return nil, fmt.Errorf("unsupported version: %d", version)
+				}
+				return OptionalTypes(), nil
+			},
+		},
+	}
+	for _, opt := range opts {
+		if err := opt(l); err != nil {
+			return nil, err
+		}
+	}
+	envOpts, err := l.envOptions(c)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.StdLib) != 0 {
+		return NewCustomEnv(envOpts...)
+	}
+	return NewEnv(envOpts...)
+}
+
+type configLoader struct {
+	libraries map[string]func(version int) (EnvOption, error)
+	envOpts   []EnvOption
+}
+
+var (
+	configFeatures = map[string]int{
+		"aggregate_comparisons":          featureAggregateComparisons,
+		"cross_type_numeric_comparisons": featureCrossTypeNumericComparisons,
+		"default_utc_time_zone":          featureDefaultUTCTimeZone,
+		"deterministic_eval":             featureDeterministicEval,
+		"eagerly_validate_declarations":  featureEagerlyValidateDeclarations,
+		"homogeneous_aggregate_literals": featureDisableDynamicAggregateLiterals,
+		"macro_call_tracking":            featureEnableMacroCallTracking,
+		"null_safe_comparisons":          featureNullSafeComparisons,
+	}
+
+	configOverflowPolicies = map[string]interpreter.OverflowPolicy{
+		"error":    interpreter.OverflowError,
+		"wrap":     interpreter.OverflowWrap,
+		"saturate": interpreter.OverflowSaturate,
+	}
+
+	configStdLibProfiles = map[string]StdLibProfile{
+		"comparisons_only":       ComparisonsOnly,
+		"no_string_construction": NoStringConstruction,
+		"no_conversions":         NoConversions,
+	}
+
+	configTypes = map[string]*Type{
+		"any":                       AnyType,
+		"bool":                      BoolType,
+		"bytes":                     BytesType,
+		"double":                    DoubleType,
+		"duration":                  DurationType,
+		"dyn":                       DynType,
+		"google.protobuf.Any":       AnyType,
+		"google.protobuf.Duration":  DurationType,
+		"google.protobuf.Timestamp": TimestampType,
+		"int":                       IntType,
+		"null_type":                 NullType,
+		"string":                    StringType,
+		"timestamp":                 TimestampType,
+		"type":                      TypeType,
+		"uint":                      UintType,
+	}
+)
+
+// envOptions converts the configuration into environment options.
+func (l *configLoader) envOptions(c *EnvConfig) ([]EnvOption, error) {
+	var opts []EnvOption
+	if len(c.StdLib) != 0 {
+		profiles := make([]StdLibProfile, len(c.StdLib))
+		for i, name := range c.StdLib {
+			profile, found := configStdLibProfiles[name]
+			if !found {
+				return nil, fmt.Errorf("unknown stdlib profile: %s", name)
+			}
+			profiles[i] = profile
+		}
+		opts = append(opts, StdLibSubset(profiles...))
+	}
+	if c.Container != "" {
+		opts = append(opts, Container(c.Container))
+	}
+	for _, lib := range c.Libraries {
+		resolve, found := l.libraries[lib.Name]
+		if !found {
+			return nil, fmt.Errorf("unknown library: %s", lib.Name)
+		}
+		opt, err := resolve(lib.Version)
+		if err != nil {
+			return nil, fmt.Errorf("library %s: %w", lib.Name, err)
+		}
+		opts = append(opts, opt)
+	}
+	// Sort the features and limits so that the options are applied in a stable order.
+	for _, name := range sortedConfigKeys(c.Features) {
+		flag, found := configFeatures[name]
+		if !found {
+			return nil, fmt.Errorf("unknown feature: %s", name)
+		}
+		opts = append(opts, features(flag, c.Features[name]))
+	}
+	var progOpts []ProgramOption
+	for _, name := range sortedConfigKeys(c.Limits) {
+		limit := c.Limits[name]
+		if limit < 0 {
+			return nil, fmt.Errorf("limit %s must not be negative: %d", name, limit)
+		}
+		switch name {
+		case "parser_recursion_limit":
+			opts = append(opts, ParserRecursionLimit(int(limit)))
+		case "expression_size_code_point_limit":
+			opts = append(opts, func(e *Env) (*Env, error) {
+				e.prsrOpts = append(e.prsrOpts, parser.ExpressionSizeCodePointLimit(int(limit)))
+				return e, nil
+			})
+		case "cost_limit":
+			progOpts = append(progOpts, CostLimit(uint64(limit)))
+		default:
+			return nil, fmt.Errorf("unknown limit: %s", name)
+		}
+	}
+	if len(progOpts) != 0 {
+		opts = append(opts, Lib(configProgramOptions(progOpts)))
+	}
+	if c.OverflowPolicy != "" {
+		policy, found := configOverflowPolicies[c.OverflowPolicy]
+		if !found {
+			return nil, fmt.Errorf("unknown overflow policy: %s", c.OverflowPolicy)
+		}
+		opts = append(opts, IntegerOverflowPolicy(policy))
+	}
+	for _, v := range c.Variables {
+		t, err := parseConfigType(v.Type, nil)
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %w", v.Name, err)
+		}
+		var varOpts []VariableOpt
+		if v.Description != "" {
+			varOpts = append(varOpts, VariableDoc(v.Description))
+		}
+		opts = append(opts, Variable(v.Name, t, varOpts...))
+	}
+	for _, fn := range c.Functions {
+		if len(fn.Overloads) == 0 {
+			return nil, fmt.Errorf("function %s: no overloads declared", fn.Name)
+		}
+		var fnOpts []FunctionOpt
+		if fn.Description != "" {
+			fnOpts = append(fnOpts, FunctionDoc(fn.Description))
+		}
+		for _, o := range fn.Overloads {
+			fnOpt, err := o.functionOpt()
+			if err != nil {
+				return nil, fmt.Errorf("function %s: overload %s: %w", fn.Name, o.ID, err)
+			}
+			fnOpts = append(fnOpts, fnOpt)
+		}
+		opts = append(opts, Function(fn.Name, fnOpts...))
+	}
+	return append(opts, l.envOpts...), nil
+}
+
+func (o *OverloadConfig) functionOpt() (FunctionOpt, error) {
+	params := map[string]bool{}
+	for _, p := range o.TypeParams {
+		params[p] = true
+	}
+	args := make([]*Type, len(o.Args))
+	for i, arg := range o.Args {
+		t, err := parseConfigType(arg, params)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = t
+	}
+	result, err := parseConfigType(o.Result, params)
+	if err != nil {
+		return nil, err
+	}
+	if o.Member {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("member overloads require a receiver argument")
+		}
+		return MemberOverload(o.ID, args, result), nil
+	}
+	return Overload(o.ID, args, result), nil
+}
+
+// configProgramOptions implements the Library interface for the program options of a
+// configuration.
+type configProgramOptions []ProgramOption
+
+// CompileOptions implements the Library interface method.
+func (configProgramOptions) CompileOptions() []EnvOption {
+	return []EnvOption{}
+}
+
+// ProgramOptions implements the Library interface method.
+func (opts configProgramOptions) ProgramOptions() []ProgramOption {
+	return opts
+}
+
+func sortedConfigKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// parseConfigType parses a type name, where the given names refer to type parameters.
+func parseConfigType(text string, params map[string]bool) (*Type, error) {
+	p := &configTypeParser{text: text}
+	t, err := p.parse(params)
+	if err == nil && p.pos < len(p.text) {
+		err = fmt.Errorf("unexpected %q", p.text[p.pos:])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid type %q: %w", text, err)
+	}
+	return t, nil
+}
+
+type configTypeParser struct {
+	text string
+	pos  int
+}
+
+func (p *configTypeParser) parse(params map[string]bool) (*Type, error) {
+	name := p.name()
+	if name == "" {
+		return nil, fmt.Errorf("expected a type name")
+	}
+	var typeParams []*Type
+	if p.consume('(') {
+		for {
+			t, err := p.parse(params)
+			if err != nil {
+				return nil, err
+			}
+			typeParams = append(typeParams, t)
+			if p.consume(')') {
+				break
+			}
+			if !p.consume(',') {
+				return nil, fmt.Errorf("expected ',' or ')'")
+			}
+		}
+	}
+	arity := map[string]int{"list": 1, "map": 2, "optional_type": 1}
+	if want, found := arity[name]; found && len(typeParams) != want {
+		return nil, fmt.Errorf("%s requires %d type parameters", name, want)
+	}
+	switch name {
+	case "list":
+		return ListType(typeParams[0]), nil
+	case "map":
+		return MapType(typeParams[0], typeParams[1]), nil
+	case "optional_type":
+		return OptionalType(typeParams[0]), nil
+	}
+	if len(typeParams) != 0 {
+		return OpaqueType(name, typeParams...), nil
+	}
+	if params[name] {
+		return TypeParamType(name), nil
+	}
+	if t, found := configTypes[name]; found {
+		return t, nil
+	}
+	return ObjectType(name), nil
+}
+
+func (p *configTypeParser) skipSpace() {
+	for p.pos < len(p.text) && p.text[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *configTypeParser) name() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.text) {
+		r := rune(p.text[p.pos])
+		if r != '.' && r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		p.pos++
+	}
+	return strings.TrimPrefix(p.text[start:p.pos], ".")
+}
+
+func (p *configTypeParser) consume(c byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.text) && p.text[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
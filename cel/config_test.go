@@ -0,0 +1,84 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnvConfigNewEnv(t *testing.T) {
+	config := &EnvConfig{
+		Container: "google.expr.proto3.test",
+		StdLib:    []string{"comparisons_only"},
+		Libraries: []*LibraryConfig{{Name: "cel.lib.optional"}},
+		Features:  map[string]bool{"cross_type_numeric_comparisons": true},
+		Variables: []*VariableConfig{{Name: "x", Type: "int"}},
+	}
+	env, err := config.NewEnv()
+	if err != nil {
+		t.Fatalf("config.NewEnv() failed: %v", err)
+	}
+	if env.Container.Name() != "google.expr.proto3.test" {
+		t.Errorf("env.Container = %q, wanted google.expr.proto3.test", env.Container.Name())
+	}
+	if !env.HasFeature(featureCrossTypeNumericComparisons) || !env.HasLibrary("cel.lib.optional") {
+		t.Error("env missing configured feature or library")
+	}
+	if _, iss := env.Compile(`x < 10.5`); iss.Err() != nil {
+		t.Errorf("env.Compile(x < 10.5) failed: %v", iss.Err())
+	}
+	if _, iss := env.Compile(`x + 1 < 10`); iss.Err() == nil {
+		t.Error("env.Compile(x + 1 < 10) succeeded, wanted the stdlib subset to exclude addition")
+	}
+}
+
+func TestEnvConfigNewEnvErrors(t *testing.T) {
+	tests := []struct {
+		config *EnvConfig
+		err    string
+	}{
+		{
+			config: &EnvConfig{Variables: []*VariableConfig{{Name: "x", Type: "list(int"}}},
+			err:    `invalid type "list(int"`,
+		},
+		{
+			config: &EnvConfig{Variables: []*VariableConfig{{Name: "x", Type: "map(int)"}}},
+			err:    "map requires 2 type parameters",
+		},
+		{
+			config: &EnvConfig{Libraries: []*LibraryConfig{{Name: "cel.lib.unknown"}}},
+			err:    "unknown library: cel.lib.unknown",
+		},
+		{
+			config: &EnvConfig{Features: map[string]bool{"unknown": true}},
+			err:    "unknown feature: unknown",
+		},
+		{
+			config: &EnvConfig{OverflowPolicy: "clamp"},
+			err:    "unknown overflow policy: clamp",
+		},
+		{
+			config: &EnvConfig{Functions: []*FunctionConfig{{Name: "f"}}},
+			err:    "function f: no overloads declared",
+		},
+	}
+	for _, tst := range tests {
+		_, err := tst.config.NewEnv()
+		if err == nil || !strings.Contains(err.Error(), tst.err) {
+			t.Errorf("config.NewEnv() got error %v, wanted %q", err, tst.err)
+		}
+	}
+}
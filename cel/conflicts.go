@@ -0,0 +1,212 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/checker"
+	"github.com/google/cel-go/checker/decls"
+	"google.golang.org/protobuf/proto"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// MergeDeclarations is a variant of Declarations which validates the declarations against the
+// declarations of the environment and against each other before adding them to the environment.
+//
+// Rather than failing on the first conflict, every conflict is collected into a single
+// *DeclarationConflictError. A conflict is either a variable which is redeclared with a different
+// type, or an overload id which is redeclared with a different signature or for a different
+// function. Identical redeclarations are not conflicts.
+//
+// Overlapping overload signatures with distinct overload ids are reported by the type-checker as
+// usual.
+func MergeDeclarations(decls ...*exprpb.Decl) EnvOption {
+	return func(e *Env) (*Env, error) {
+		merger, err := newDeclMerger(e)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range decls {
+			merger.add(d)
+		}
+		if len(merger.conflicts) != 0 {
+			return nil, &DeclarationConflictError{Conflicts: merger.conflicts}
+		}
+		e.declarations = append(e.declarations, decls...)
+		return e, nil
+	}
+}
+
+// DeclarationConflict describes a declaration which conflicts with an earlier declaration of the
+// same variable or overload id.
+type DeclarationConflict struct {
+	// Name is the name of the conflicting variable or function.
+	Name string
+
+	// OverloadID is the id of the conflicting overload, or empty for conflicting variables.
+	OverloadID string
+
+	// Existing is the earlier declaration. Function declarations contain only the overload
+	// which is in conflict.
+	Existing *exprpb.Decl
+
+	// Conflicting is the declaration which conflicts with the earlier declaration. Function
+	// declarations contain only the overload which is in conflict.
+	Conflicting *exprpb.Decl
+}
+
+// String returns a description of the conflict.
+func (c DeclarationConflict) String() string {
+	if c.OverloadID == "" {
+		return fmt.Sprintf("variable %s redeclared with type %s, previously declared with type %s",
+			c.Name,
+			checker.FormatCheckedType(c.Conflicting.GetIdent().GetType()),
+			checker.FormatCheckedType(c.Existing.GetIdent().GetType()))
+	}
+	return fmt.Sprintf("overload %s redeclared as %s, previously declared as %s",
+		c.OverloadID, formatOverloadDecl(c.Conflicting), formatOverloadDecl(c.Existing))
+}
+
+// DeclarationConflictError lists every conflict found while merging declarations.
+type DeclarationConflictError struct {
+	Conflicts []DeclarationConflict
+}
+
+// Error implements the error interface method.
+func (e *DeclarationConflictError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d declaration conflict(s):", len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		sb.WriteString("\n  ")
+		sb.WriteString(c.String())
+	}
+	return sb.String()
+}
+
+// declMerger indexes declarations by variable name and overload id in order to detect conflicts.
+type declMerger struct {
+	idents    map[string]*exprpb.Decl
+	overloads map[string]*exprpb.Decl
+	conflicts []DeclarationConflict
+}
+
+func newDeclMerger(e *Env) (*declMerger, error) {
+	m := &declMerger{
+		idents:    map[string]*exprpb.Decl{},
+		overloads: map[string]*exprpb.Decl{},
+	}
+	// Declarations which have already been validated are only available from the checker.
+	ce, err := checker.NewEnv(e.Container, e.provider, e.chkOpts...)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range ce.Declarations() {
+		m.index(d)
+	}
+	for _, d := range e.declarations {
+		m.index(d)
+	}
+	for _, fn := range e.functions {
+		d, err := functionDeclToExprDecl(fn)
+		if err != nil {
+			return nil, err
+		}
+		m.index(d)
+	}
+	return m, nil
+}
+
+// index records the declaration without checking it for conflicts.
+func (m *declMerger) index(d *exprpb.Decl) {
+	switch d.GetDeclKind().(type) {
+	case *exprpb.Decl_Ident:
+		m.idents[d.GetName()] = d
+	case *exprpb.Decl_Function:
+		for _, o := range d.GetFunction().GetOverloads() {
+			m.overloads[o.GetOverloadId()] = decls.NewFunction(d.GetName(), o)
+		}
+	}
+}
+
+// add records the declaration, collecting its conflicts with previously recorded declarations.
+func (m *declMerger) add(d *exprpb.Decl) {
+	switch d.GetDeclKind().(type) {
+	case *exprpb.Decl_Ident:
+		existing, found := m.idents[d.GetName()]
+		if found && !proto.Equal(existing.GetIdent().GetType(), d.GetIdent().GetType()) {
+			m.conflicts = append(m.conflicts, DeclarationConflict{
+				Name:        d.GetName(),
+				Existing:    existing,
+				Conflicting: d,
+			})
+			return
+		}
+		m.idents[d.GetName()] = d
+	case *exprpb.Decl_Function:
+		for _, o := range d.GetFunction().GetOverloads() {
+			overload := decls.NewFunction(d.GetName(), o)
+			existing, found := m.overloads[o.GetOverloadId()]
+			if found && !sameOverload(existing, overload) {
+				m.conflicts = append(m.conflicts, DeclarationConflict{
+					Name:        d.GetName(),
+					OverloadID:  o.GetOverloadId(),
+					Existing:    existing,
+					Conflicting: overload,
+				})
+				continue
+			}
+			m.overloads[o.GetOverloadId()] = overload
+		}
+	}
+}
+
+// sameOverload returns whether two single-overload function declarations declare the same
+// function and signature, ignoring documentation.
+func sameOverload(d1, d2 *exprpb.Decl) bool {
+	if d1.GetName() != d2.GetName() {
+		return false
+	}
+	o1 := proto.Clone(d1.GetFunction().GetOverloads()[0]).(*exprpb.Decl_FunctionDecl_Overload)
+	o2 := proto.Clone(d2.GetFunction().GetOverloads()[0]).(*exprpb.Decl_FunctionDecl_Overload)
+	o1.Doc = ""
+	o2.Doc = ""
+	return proto.Equal(o1, o2)
+}
+
+// formatOverloadDecl formats the signature of a single-overload function declaration.
+func formatOverloadDecl(d *exprpb.Decl) string {
+	o := d.GetFunction().GetOverloads()[0]
+	params := o.GetParams()
+	var sb strings.Builder
+	if o.GetIsInstanceFunction() && len(params) > 0 {
+		sb.WriteString(checker.FormatCheckedType(params[0]))
+		sb.WriteString(".")
+		params = params[1:]
+	}
+	sb.WriteString(d.GetName())
+	sb.WriteString("(")
+	for i, p := range params {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(checker.FormatCheckedType(p))
+	}
+	sb.WriteString(") -> ")
+	sb.WriteString(checker.FormatCheckedType(o.GetResultType()))
+	return sb.String()
+}
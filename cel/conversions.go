@@ -0,0 +1,173 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+	"github.com/google/cel-go/interpreter/functions"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// OverloadIsConversion declares that the overload converts its argument into a value of another
+// type, in the manner of the standard `int(string)` or `timestamp(string)` conversions, such as a
+// custom `ip(string)` or `decimal(string)` overload.
+//
+// Calls to a conversion with a literal argument are evaluated when the expression is type-checked,
+// so that invalid literals such as `ip('999.1.1.1')` are reported as compile errors rather than
+// evaluation errors. Programs created with OptOptimize fold such calls into constants.
+//
+// Conversions must accept exactly one argument and are implicitly pure, see OverloadIsPure.
+func OverloadIsConversion() OverloadOpt {
+	return func(o *overloadDecl) (*overloadDecl, error) {
+		if len(o.argTypes) != 1 {
+			return nil, fmt.Errorf("conversion overload must accept exactly one argument: %s", o.id)
+		}
+		o.conversion = true
+		o.pure = true
+		return o, nil
+	}
+}
+
+// conversionBindings returns the bindings of the conversion overloads of the environment, keyed by
+// overload id.
+func (e *Env) conversionBindings() (map[string]*functions.Overload, error) {
+	convs := map[string]*functions.Overload{}
+	for _, fn := range e.functions {
+		ids := map[string]bool{}
+		for _, o := range fn.overloads {
+			if o.conversion {
+				ids[o.id] = true
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		bindings, err := fn.bindings()
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range bindings {
+			if ids[b.Operator] {
+				convs[b.Operator] = b
+			}
+		}
+	}
+	return convs, nil
+}
+
+// checkConversions reports an error for each call to a conversion overload whose literal argument
+// cannot be converted.
+func (e *Env) checkConversions(ast *Ast) *common.Errors {
+	errs := common.NewErrors(ast.Source())
+	convs, err := e.conversionBindings()
+	if err != nil {
+		errs.ReportError(common.NoLocation, err.Error())
+		return errs
+	}
+	if len(convs) == 0 {
+		return errs
+	}
+	visitExpr(ast.Expr(), func(expr *exprpb.Expr) {
+		call := expr.GetCallExpr()
+		ref, found := ast.refMap[expr.GetId()]
+		if call == nil || !found || len(ref.GetOverloadId()) != 1 {
+			return
+		}
+		conv, found := convs[ref.GetOverloadId()[0]]
+		if !found {
+			return
+		}
+		arg := call.GetTarget()
+		if arg == nil && len(call.GetArgs()) == 1 {
+			arg = call.GetArgs()[0]
+		}
+		val, isLiteral := literalValue(arg.GetConstExpr())
+		if !isLiteral {
+			return
+		}
+		if out, invoked := invokeConversion(conv, val); invoked && types.IsError(out) {
+			errs.ReportError(exprLocation(ast, expr.GetId()),
+				"invalid argument to conversion '%s': %v", call.GetFunction(), out)
+		}
+	})
+	return errs
+}
+
+// foldConversions returns a decorator which replaces the calls to conversion overloads with
+// literal arguments with the result of the conversion.
+func foldConversions(convs map[string]*functions.Overload) interpreter.InterpretableDecorator {
+	return func(i interpreter.Interpretable) (interpreter.Interpretable, error) {
+		call, ok := i.(interpreter.InterpretableCall)
+		if !ok {
+			return i, nil
+		}
+		conv, found := convs[call.OverloadID()]
+		if !found || len(call.Args()) != 1 {
+			return i, nil
+		}
+		arg, isConst := call.Args()[0].(interpreter.InterpretableConst)
+		if !isConst {
+			return i, nil
+		}
+		out, invoked := invokeConversion(conv, arg.Value())
+		if !invoked {
+			return i, nil
+		}
+		if types.IsError(out) {
+			return nil, out.(*types.Err)
+		}
+		return interpreter.NewConstValue(call.ID(), out), nil
+	}
+}
+
+// invokeConversion applies the conversion binding to the argument, returning false if the
+// conversion has no binding.
+func invokeConversion(conv *functions.Overload, arg ref.Val) (ref.Val, bool) {
+	switch {
+	case conv.Unary != nil:
+		return conv.Unary(arg), true
+	case conv.Function != nil:
+		return conv.Function(arg), true
+	case conv.ContextFunction != nil:
+		return conv.ContextFunction(context.Background(), arg), true
+	}
+	return nil, false
+}
+
+// literalValue returns the value of a literal other than null.
+func literalValue(c *exprpb.Constant) (ref.Val, bool) {
+	switch c.GetConstantKind().(type) {
+	case *exprpb.Constant_BoolValue:
+		return types.Bool(c.GetBoolValue()), true
+	case *exprpb.Constant_BytesValue:
+		return types.Bytes(c.GetBytesValue()), true
+	case *exprpb.Constant_DoubleValue:
+		return types.Double(c.GetDoubleValue()), true
+	case *exprpb.Constant_Int64Value:
+		return types.Int(c.GetInt64Value()), true
+	case *exprpb.Constant_StringValue:
+		return types.String(c.GetStringValue()), true
+	case *exprpb.Constant_Uint64Value:
+		return types.Uint(c.GetUint64Value()), true
+	}
+	return nil, false
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+func TestOverloadIsConversion(t *testing.T) {
+	calls := 0
+	parseIP := func(arg ref.Val) ref.Val {
+		calls++
+		ip := net.ParseIP(string(arg.(types.String)))
+		if ip == nil {
+			return types.NewErr("invalid ip address: %s", arg)
+		}
+		return types.String(ip.String())
+	}
+	env, err := NewEnv(
+		Variable("addr", StringType),
+		Function("ip",
+			Overload("ip_string", []*Type{StringType}, StringType,
+				UnaryBinding(parseIP), OverloadIsConversion())),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	_, iss := env.Compile(`ip(addr) == ip('999.1.1.1')`)
+	wantErr := "ERROR: <input>:1:15: invalid argument to conversion 'ip': invalid ip address: 999.1.1.1"
+	if iss.Err() == nil || !strings.Contains(iss.Err().Error(), wantErr) {
+		t.Errorf("env.Compile() got %v, wanted error %q", iss.Err(), wantErr)
+	}
+
+	ast, iss := env.Compile(`ip(addr) == ip('::ffff:10.0.0.1')`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	calls = 0
+	prg, err := env.Program(ast, EvalOptions(OptOptimize))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		out, _, err := prg.Eval(map[string]any{"addr": "10.0.0.1"})
+		if err != nil || out != types.True {
+			t.Errorf("prg.Eval() got %v, %v, wanted true", out, err)
+		}
+	}
+	// The literal is converted once when the program is planned.
+	if calls != 3 {
+		t.Errorf("got %d calls to ip(), wanted 3", calls)
+	}
+
+	_, err = NewEnv(Function("ip",
+		Overload("ip_string_string", []*Type{StringType, StringType}, StringType,
+			OverloadIsConversion())))
+	if err == nil || !strings.Contains(err.Error(), "must accept exactly one argument") {
+		t.Errorf("NewEnv() got %v, wanted error for a binary conversion", err)
+	}
+}
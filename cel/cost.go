@@ -0,0 +1,138 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/checker"
+)
+
+// SizeEstimates registers the expected sizes of the strings, bytes, lists, and maps reachable from
+// variables, which are used in place of the worst-case sizes when estimating the cost of
+// expressions with Env.EstimateCost and when reordering logical operands by cost.
+//
+// Sizes are keyed by the dot-separated path of the value, as reported by checker.AstNode.Path,
+// where the first element is the variable name and subsequent elements are field names, or
+// `@items`, `@keys`, and `@values` for the elements, keys, and values of lists and maps:
+//
+//	cel.SizeEstimates(map[string]checker.SizeEstimate{
+//		"request.items":             {Min: 0, Max: 100},
+//		"request.items.@items.name": {Min: 1, Max: 64},
+//		"request.labels":            {Min: 0, Max: 20},
+//	})
+//
+// Registered sizes take precedence over the sizes estimated by the checker.CostEstimator given to
+// Env.EstimateCost. Sizes registered for the same path by a later option replace earlier sizes.
+func SizeEstimates(sizes map[string]checker.SizeEstimate) EnvOption {
+	return sizeEstimateProfile("", sizes)
+}
+
+// SizeEstimateProfile registers a named set of size estimates, e.g. the median or 99th percentile
+// sizes of the inputs of an expression, for use with Env.EstimateCostProfiles.
+//
+// The sizes are keyed by path, as with SizeEstimates, and take precedence over the sizes
+// registered with SizeEstimates, which apply to all profiles.
+func SizeEstimateProfile(name string, sizes map[string]checker.SizeEstimate) EnvOption {
+	if name == "" {
+		return func(e *Env) (*Env, error) {
+			return nil, errors.New("size estimate profiles require a name")
+		}
+	}
+	return sizeEstimateProfile(name, sizes)
+}
+
+func sizeEstimateProfile(name string, sizes map[string]checker.SizeEstimate) EnvOption {
+	return func(e *Env) (*Env, error) {
+		profile := map[string]checker.SizeEstimate{}
+		for path, size := range e.sizeProfiles[name] {
+			profile[path] = size
+		}
+		for path, size := range sizes {
+			if path == "" {
+				return nil, errors.New("size estimates require a path")
+			}
+			if size.Min > size.Max {
+				return nil, fmt.Errorf("invalid size estimate for %s: min %d exceeds max %d", path, size.Min, size.Max)
+			}
+			profile[path] = size
+		}
+		e.sizeProfiles[name] = profile
+		return e, nil
+	}
+}
+
+// EstimateCostProfiles estimates the cost of a type checked CEL expression once for each of the
+// profiles registered with SizeEstimateProfile, returning the estimates keyed by profile name.
+//
+// Comparing the estimates of profiles for typical and extreme inputs, e.g. `p50` and `p99`, makes
+// it possible to set cost limits which reflect realistic inputs rather than worst-case sizes. The
+// estimator, if non-nil, provides the sizes which are not covered by the profiles and the costs
+// of calls, as with Env.EstimateCost.
+func (e *Env) EstimateCostProfiles(ast *Ast, estimator checker.CostEstimator) (map[string]checker.CostEstimate, error) {
+	checked, err := AstToCheckedExpr(ast)
+	if err != nil {
+		return nil, fmt.Errorf("EstimateCostProfiles could not inspect Ast: %v", err)
+	}
+	var names []string
+	for name := range e.sizeProfiles {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, errors.New("no size estimate profiles configured")
+	}
+	sort.Strings(names)
+	estimates := make(map[string]checker.CostEstimate, len(names))
+	for _, name := range names {
+		estimates[name] = checker.Cost(checked, e.sizeEstimator(name, estimator))
+	}
+	return estimates, nil
+}
+
+// sizeEstimator layers the sizes of the named profile and the default sizes over the estimator.
+func (e *Env) sizeEstimator(name string, estimator checker.CostEstimator) checker.CostEstimator {
+	if estimator == nil {
+		estimator = defaultCostEstimator{}
+	}
+	if sizes, found := e.sizeProfiles[""]; found {
+		estimator = &pathSizeEstimator{CostEstimator: estimator, sizes: sizes}
+	}
+	if sizes, found := e.sizeProfiles[name]; found && name != "" {
+		estimator = &pathSizeEstimator{CostEstimator: estimator, sizes: sizes}
+	}
+	return estimator
+}
+
+// pathSizeEstimator estimates the sizes of values from the sizes registered for their paths,
+// deferring to the wrapped estimator for other values and for the costs of calls.
+type pathSizeEstimator struct {
+	checker.CostEstimator
+	sizes map[string]checker.SizeEstimate
+}
+
+// EstimateSize implements the checker.CostEstimator interface method.
+func (e *pathSizeEstimator) EstimateSize(element checker.AstNode) *checker.SizeEstimate {
+	if path := element.Path(); len(path) != 0 {
+		if size, found := e.sizes[strings.Join(path, ".")]; found {
+			return &size
+		}
+	}
+	return e.CostEstimator.EstimateSize(element)
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/checker"
+)
+
+func TestSizeEstimates(t *testing.T) {
+	env, err := NewEnv(
+		Variable("items", ListType(StringType)),
+		Variable("name", StringType),
+		SizeEstimates(map[string]checker.SizeEstimate{
+			"items": {Min: 0, Max: 100},
+			"name":  {Min: 1, Max: 16},
+		}),
+		SizeEstimateProfile("p50", map[string]checker.SizeEstimate{
+			"items":        {Min: 0, Max: 10},
+			"items.@items": {Min: 0, Max: 8},
+		}),
+		SizeEstimateProfile("p99", map[string]checker.SizeEstimate{
+			"items": {Min: 0, Max: 50},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`items.exists(i, i.startsWith(name))`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	est, err := env.EstimateCost(ast, nil)
+	if err != nil {
+		t.Fatalf("env.EstimateCost() failed: %v", err)
+	}
+	unbounded, err := env.EstimateCost(ast, testCostEstimator{})
+	if err != nil {
+		t.Fatalf("env.EstimateCost() failed: %v", err)
+	}
+	if est != unbounded {
+		t.Errorf("env.EstimateCost() got %v with an estimator, wanted the registered sizes to apply: %v", unbounded, est)
+	}
+	profiles, err := env.EstimateCostProfiles(ast, nil)
+	if err != nil {
+		t.Fatalf("env.EstimateCostProfiles() failed: %v", err)
+	}
+	p50, p99 := profiles["p50"], profiles["p99"]
+	if len(profiles) != 2 || p50.Max >= p99.Max || p99.Max >= est.Max {
+		t.Errorf("env.EstimateCostProfiles() got %v, wanted p50 < p99 < %v", profiles, est)
+	}
+
+	for _, opt := range []EnvOption{
+		SizeEstimateProfile("", nil),
+		SizeEstimates(map[string]checker.SizeEstimate{"": {}}),
+		SizeEstimates(map[string]checker.SizeEstimate{"items": {Min: 2, Max: 1}}),
+	} {
+		if _, err := NewEnv(opt); err == nil {
+			t.Error("NewEnv() succeeded with an invalid size estimate, wanted error")
+		}
+	}
+	noProfiles, err := NewEnv(Variable("items", ListType(StringType)))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	if _, err := noProfiles.EstimateCostProfiles(ast, nil); err == nil {
+		t.Error("env.EstimateCostProfiles() succeeded without profiles, wanted error")
+	}
+}
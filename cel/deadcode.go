@@ -0,0 +1,218 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+
+	"google.golang.org/protobuf/proto"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// EliminateDeadBranches returns a copy of the Ast in which each conditional expression whose
+// condition is constant is replaced by the branch selected by the condition.
+//
+// A condition is constant when it is a boolean literal, or when it is composed solely of literals
+// and calls to deterministic functions and evaluates to a boolean within the environment, e.g.
+// `1 + 1 == 2`. The expression ids of the discarded condition and branch are removed from the
+// type, reference, and source maps of the returned Ast, so serialized forms of the Ast shrink
+// accordingly.
+//
+// The input Ast is not modified.
+func (e *Env) EliminateDeadBranches(ast *Ast) *Ast {
+	d := &deadBranchEliminator{
+		env:     e,
+		ast:     ast,
+		removed: map[int64]bool{},
+	}
+	expr := d.visit(proto.Clone(ast.Expr()).(*exprpb.Expr))
+	if len(d.removed) == 0 {
+		return ast
+	}
+	return withoutIDs(ast, expr, d.removed)
+}
+
+// withoutIDs returns an Ast for the expression, which replaces that of the input Ast, in which the
+// removed expression ids are omitted from the type, reference, and source maps of the input Ast.
+func withoutIDs(ast *Ast, expr *exprpb.Expr, removed map[int64]bool) *Ast {
+	info := ast.SourceInfo()
+	if info != nil {
+		info = proto.Clone(info).(*exprpb.SourceInfo)
+		for id := range removed {
+			delete(info.GetPositions(), id)
+			delete(info.GetMacroCalls(), id)
+		}
+	}
+	var refMap map[int64]*exprpb.Reference
+	if ast.refMap != nil {
+		refMap = make(map[int64]*exprpb.Reference, len(ast.refMap))
+		for id, ref := range ast.refMap {
+			if !removed[id] {
+				refMap[id] = ref
+			}
+		}
+	}
+	var typeMap map[int64]*exprpb.Type
+	if ast.typeMap != nil {
+		typeMap = make(map[int64]*exprpb.Type, len(ast.typeMap))
+		for id, t := range ast.typeMap {
+			if !removed[id] {
+				typeMap[id] = t
+			}
+		}
+	}
+	var resolutions map[int64]*ContainerResolution
+	for id, r := range ast.resolutions {
+		if !removed[id] {
+			if resolutions == nil {
+				resolutions = map[int64]*ContainerResolution{}
+			}
+			resolutions[id] = r
+		}
+	}
+	return &Ast{
+		expr:        expr,
+		info:        info,
+		source:      ast.source,
+		refMap:      refMap,
+		typeMap:     typeMap,
+		resolutions: resolutions,
+		defaults:    ast.defaults,
+	}
+}
+
+type deadBranchEliminator struct {
+	env     *Env
+	ast     *Ast
+	removed map[int64]bool
+}
+
+// visit eliminates the dead branches within the expression graph, returning the expression which
+// replaces the input expression.
+func (d *deadBranchEliminator) visit(e *exprpb.Expr) *exprpb.Expr {
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		sel := e.GetSelectExpr()
+		sel.Operand = d.visit(sel.GetOperand())
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		if call.GetTarget() != nil {
+			call.Target = d.visit(call.GetTarget())
+		}
+		for i, arg := range call.GetArgs() {
+			call.Args[i] = d.visit(arg)
+		}
+		if call.GetFunction() == operators.Conditional && len(call.GetArgs()) == 3 {
+			return d.maybeEliminate(e)
+		}
+	case *exprpb.Expr_ListExpr:
+		list := e.GetListExpr()
+		for i, elem := range list.GetElements() {
+			list.Elements[i] = d.visit(elem)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			if entry.GetMapKey() != nil {
+				entry.KeyKind = &exprpb.Expr_CreateStruct_Entry_MapKey{MapKey: d.visit(entry.GetMapKey())}
+			}
+			entry.Value = d.visit(entry.GetValue())
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		comp.IterRange = d.visit(comp.GetIterRange())
+		comp.AccuInit = d.visit(comp.GetAccuInit())
+		comp.LoopCondition = d.visit(comp.GetLoopCondition())
+		comp.LoopStep = d.visit(comp.GetLoopStep())
+		comp.Result = d.visit(comp.GetResult())
+	}
+	return e
+}
+
+// maybeEliminate replaces the conditional expression with the branch selected by its condition
+// when the condition is constant.
+func (d *deadBranchEliminator) maybeEliminate(e *exprpb.Expr) *exprpb.Expr {
+	args := e.GetCallExpr().GetArgs()
+	cond, found := d.constantCondition(args[0])
+	if !found {
+		return e
+	}
+	live, dead := args[1], args[2]
+	if !cond {
+		live, dead = dead, live
+	}
+	d.removed[e.GetId()] = true
+	d.remove(args[0])
+	d.remove(dead)
+	return live
+}
+
+// constantCondition returns the value of the condition when it is known to be constant.
+func (d *deadBranchEliminator) constantCondition(cond *exprpb.Expr) (bool, bool) {
+	if c := cond.GetConstExpr(); c != nil {
+		if b, isBool := c.GetConstantKind().(*exprpb.Constant_BoolValue); isBool {
+			return b.BoolValue, true
+		}
+		return false, false
+	}
+	if !d.isFoldable(cond) {
+		return false, false
+	}
+	prg, err := d.env.Program(&Ast{
+		expr:    cond,
+		info:    d.ast.info,
+		source:  d.ast.source,
+		refMap:  d.ast.refMap,
+		typeMap: d.ast.typeMap,
+	})
+	if err != nil {
+		return false, false
+	}
+	out, _, err := prg.Eval(NoVars())
+	if err != nil {
+		return false, false
+	}
+	b, isBool := out.(types.Bool)
+	return bool(b), isBool
+}
+
+// isFoldable returns whether the expression is composed solely of literals and calls to
+// deterministic functions.
+func (d *deadBranchEliminator) isFoldable(e *exprpb.Expr) bool {
+	foldable := true
+	visitExpr(e, func(expr *exprpb.Expr) {
+		switch expr.GetExprKind().(type) {
+		case *exprpb.Expr_ConstExpr, *exprpb.Expr_ListExpr:
+		case *exprpb.Expr_StructExpr:
+			// Only map literals are folded.
+			foldable = foldable && expr.GetStructExpr().GetMessageName() == ""
+		case *exprpb.Expr_CallExpr:
+			if fn, found := d.env.functions[expr.GetCallExpr().GetFunction()]; found && fn.nondeterministic {
+				foldable = false
+			}
+		default:
+			foldable = false
+		}
+	})
+	return foldable
+}
+
+// remove records the ids of the expression graph as removed.
+func (d *deadBranchEliminator) remove(e *exprpb.Expr) {
+	visitExpr(e, func(expr *exprpb.Expr) {
+		d.removed[expr.GetId()] = true
+	})
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestEliminateDeadBranches(t *testing.T) {
+	env, err := NewEnv(
+		EnableMacroCallTracking(),
+		Variable("x", IntType),
+		Function("roll",
+			Overload("roll", []*Type{}, BoolType,
+				FunctionBinding(func(args ...ref.Val) ref.Val { return types.True })),
+			NonDeterministic()),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		expr string
+		out  string
+	}{
+		{
+			expr: `(1 + 1 == 2 ? x : x + 100) + (false ? [1, 2].map(i, i * x)[0] : 0)`,
+			out:  `x + 0`,
+		},
+		{
+			expr: `[true ? x : 0, x > 1 ? 1 : 2, 'a' in {'a': true} ? 3 : 4]`,
+			out:  `[x, (x > 1) ? 1 : 2, 3]`,
+		},
+		{
+			expr: `roll() ? x : 0`,
+			out:  `roll() ? x : 0`,
+		},
+		{
+			expr: `1 / 0 == 1 ? x : 0`,
+			out:  `(1 / 0 == 1) ? x : 0`,
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile() failed: %v", iss.Err())
+			}
+			before := proto.Clone(ast.Expr())
+			pruned := env.EliminateDeadBranches(ast)
+			if !proto.Equal(ast.Expr(), before) {
+				t.Error("env.EliminateDeadBranches() modified the input ast")
+			}
+			out, err := AstToString(pruned)
+			if err != nil {
+				t.Fatalf("AstToString() failed: %v", err)
+			}
+			if out != tc.out {
+				t.Errorf("env.EliminateDeadBranches() got %s, wanted %s", out, tc.out)
+			}
+			ids := map[int64]bool{}
+			visitExpr(pruned.Expr(), func(e *exprpb.Expr) { ids[e.GetId()] = true })
+			for id := range pruned.typeMap {
+				if !ids[id] {
+					t.Errorf("env.EliminateDeadBranches() retained the type of removed expression %d", id)
+				}
+			}
+			want, _, _ := mustProgram(t, env, ast).Eval(map[string]any{"x": 2})
+			got, _, _ := mustProgram(t, env, pruned).Eval(map[string]any{"x": 2})
+			if got.Equal(want) != types.True && !(types.IsError(got) && types.IsError(want)) {
+				t.Errorf("pruned program got %v, wanted %v", got, want)
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@
 package cel
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -318,6 +319,11 @@ func OptionalType(param *Type) *Type {
 	return OpaqueType("optional", param)
 }
 
+// PairType creates a parameterized pair type with the types of its first and second elements.
+func PairType(first, second *Type) *Type {
+	return OpaqueType("pair", first, second)
+}
+
 // OpaqueType creates an abstract parameterized type with a given name.
 func OpaqueType(name string, params ...*Type) *Type {
 	return &Type{
@@ -335,6 +341,16 @@ func ObjectType(typeName string) *Type {
 	}
 }
 
+// TypeTypeWithParam creates a type instance which describes the type of values of the parameter
+// type, e.g. the type of the identifier `int` is `type(int)`.
+func TypeTypeWithParam(param *Type) *Type {
+	return &Type{
+		kind:        TypeKind,
+		runtimeType: types.TypeType,
+		parameters:  []*Type{param},
+	}
+}
+
 // TypeParamType creates a parameterized type instance.
 func TypeParamType(paramName string) *Type {
 	return &Type{
@@ -344,17 +360,98 @@ func TypeParamType(paramName string) *Type {
 }
 
 // Variable creates an instance of a variable declaration with a variable name and type.
-func Variable(name string, t *Type) EnvOption {
+func Variable(name string, t *Type, opts ...VariableOpt) EnvOption {
 	return func(e *Env) (*Env, error) {
 		et, err := TypeToExprType(t)
 		if err != nil {
 			return nil, err
 		}
+		v := &variableDecl{name: name, t: t}
+		for _, opt := range opts {
+			v, err = opt(v)
+			if err != nil {
+				return nil, fmt.Errorf("variable %s: %w", name, err)
+			}
+		}
+		if v.defaultValue != nil {
+			val := e.adapter.NativeToValue(v.defaultValue)
+			if types.IsError(val) || !t.IsAssignableRuntimeType(val) {
+				return nil, fmt.Errorf("variable %s: default value of type %T is not assignable to %s",
+					name, v.defaultValue, t)
+			}
+			e.variableDefaults[name] = val
+		}
+		if v.unit != "" {
+			e.variableUnits[name] = v.unit
+		}
+		if v.doc != nil {
+			e.variableDocs[name] = v.doc
+		}
 		e.declarations = append(e.declarations, decls.NewVar(name, et))
 		return e, nil
 	}
 }
 
+// VariableOpt defines a functional option for configuring a variable declaration.
+type VariableOpt func(*variableDecl) (*variableDecl, error)
+
+// DefaultValue sets the value of a variable when the activation provided to the evaluation does
+// not bind the variable, e.g. `Variable("limit", IntType, DefaultValue(100))`.
+//
+// The value must be assignable to the declared type of the variable. The defaults of the variables
+// referenced by an expression are recorded in the checked Ast, see Ast.VariableDefaults.
+func DefaultValue(value any) VariableOpt {
+	return func(v *variableDecl) (*variableDecl, error) {
+		if value == nil {
+			return nil, fmt.Errorf("default value must not be nil")
+		}
+		v.defaultValue = value
+		return v, nil
+	}
+}
+
+type variableDecl struct {
+	name         string
+	t            *Type
+	defaultValue any
+	unit         string
+	doc          *Doc
+}
+
+// SensitiveVariable creates a variable declaration whose value is redacted from the error messages,
+// evaluation state, and observer callbacks produced during evaluation.
+//
+// See SensitiveFields for details on how redaction is applied.
+func SensitiveVariable(name string, t *Type) EnvOption {
+	return func(e *Env) (*Env, error) {
+		e, err := Variable(name, t)(e)
+		if err != nil {
+			return nil, err
+		}
+		return SensitiveFields(name)(e)
+	}
+}
+
+// SensitiveFields marks variables or dotted field paths rooted at a variable, e.g. `req.auth.token`,
+// as containing sensitive values.
+//
+// Any expression which references a sensitive path, a field nested beneath it, or an object which
+// contains it is considered sensitive, as are the non-boolean results computed from sensitive
+// values. The values of sensitive expressions are replaced with a redacted error value within the
+// EvalState and observer callbacks, and errors raised by functions which receive a sensitive
+// argument are replaced with a generic error which omits the original message.
+//
+// Boolean results are not considered sensitive since they typically represent the decision being
+// computed by the expression.
+func SensitiveFields(paths ...string) EnvOption {
+	return func(e *Env) (*Env, error) {
+		for _, path := range paths {
+			e.sensitivePaths[path] = true
+		}
+		return e, nil
+	}
+}
+
 // Function defines a function and overloads with optional singleton or per-overload bindings.
 //
 // Using Function is roughly equivalent to calling Declarations() to declare the function signatures
@@ -499,6 +596,50 @@ func SingletonFunctionBinding(fn functions.FunctionOp, traits ...int) FunctionOp
 	}
 }
 
+// NonDeterministic marks the function as producing results which may vary between invocations with the
+// same arguments, for example because the function reads the wall clock or a random number source.
+//
+// Nondeterministic functions are rejected by environments configured with DeterministicEval.
+func NonDeterministic() FunctionOpt {
+	return func(f *functionDecl) (*functionDecl, error) {
+		f.nondeterministic = true
+		return f, nil
+	}
+}
+
+// programSourced marks the nondeterministic function as one whose values are supplied by the
+// program or its activation when the environment is configured with DeterministicEval, so that
+// its calls are permitted.
+func programSourced() FunctionOpt {
+	return func(f *functionDecl) (*functionDecl, error) {
+		f.programSourced = true
+		return f, nil
+	}
+}
+
+// Rebindable marks the function's overloads as eligible for replacement on a per-program basis
+// with WithFunctions, for example to supply a request-scoped implementation of a lookup
+// function without planning the program again for each request.
+//
+// The overloads may still be bound within the environment, in which case the environment binding is
+// used by programs which do not replace it.
+func Rebindable() FunctionOpt {
+	return func(f *functionDecl) (*functionDecl, error) {
+		f.rebindable = true
+		return f, nil
+	}
+}
+
+// DisplayName sets the name by which type-check errors refer to the function, such as the name of
+// the macro which expands to calls of an internal function, so that the internal name does not
+// appear in the errors reported to users.
+func DisplayName(name string) FunctionOpt {
+	return func(f *functionDecl) (*functionDecl, error) {
+		f.displayName = name
+		return f, nil
+	}
+}
+
 // Overload defines a new global overload with an overload id, argument types, and result type. Through the
 // use of OverloadOpt options, the overload may also be configured with a binding, an operand trait, and to
 // be non-strict.
@@ -564,6 +705,23 @@ func FunctionBinding(binding functions.FunctionOp) OverloadOpt {
 	}
 }
 
+// ContextFunctionBinding provides the implementation of an overload which receives the
+// context.Context of the evaluation, such as an implementation which performs an RPC. The context
+// is the one provided to Program.ContextEval, or context.Background() when the program is
+// evaluated via Program.Eval.
+//
+// The provided function is protected by a runtime type-guard which ensures runtime type agreement
+// between the overload signature and runtime argument types.
+func ContextFunctionBinding(binding functions.ContextFunctionOp) OverloadOpt {
+	return func(o *overloadDecl) (*overloadDecl, error) {
+		if o.hasBinding() {
+			return nil, fmt.Errorf("overload already has a binding: %s", o.id)
+		}
+		o.contextFunctionOp = binding
+		return o, nil
+	}
+}
+
 // OverloadIsNonStrict enables the function to be called with error and unknown argument values.
 //
 // Note: do not use this option unless absoluately necessary as it should be an uncommon feature.
@@ -584,11 +742,16 @@ func OverloadOperandTrait(trait int) OverloadOpt {
 }
 
 type functionDecl struct {
-	name        string
-	overloads   []*overloadDecl
-	options     []FunctionOpt
-	singleton   *functions.Overload
-	initialized bool
+	name             string
+	overloads        []*overloadDecl
+	options          []FunctionOpt
+	singleton        *functions.Overload
+	nondeterministic bool
+	programSourced   bool
+	rebindable       bool
+	displayName      string
+	initialized      bool
+	doc              *Doc
 }
 
 // init ensures that a function's options have been applied.
@@ -617,18 +780,21 @@ func (f *functionDecl) init() error {
 func (f *functionDecl) bindings() ([]*functions.Overload, error) {
 	overloads := []*functions.Overload{}
 	nonStrict := false
+	withContext := false
 	for _, o := range f.overloads {
 		if o.hasBinding() {
 			overload := &functions.Overload{
-				Operator:     o.id,
-				Unary:        o.guardedUnaryOp(f.name),
-				Binary:       o.guardedBinaryOp(f.name),
-				Function:     o.guardedFunctionOp(f.name),
-				OperandTrait: o.operandTrait,
-				NonStrict:    o.nonStrict,
+				Operator:        o.id,
+				Unary:           o.guardedUnaryOp(f.name),
+				Binary:          o.guardedBinaryOp(f.name),
+				Function:        o.guardedFunctionOp(f.name),
+				OperandTrait:    o.operandTrait,
+				NonStrict:       o.nonStrict,
+				ContextFunction: o.guardedContextFunctionOp(f.name),
 			}
 			overloads = append(overloads, overload)
 			nonStrict = nonStrict || o.nonStrict
+			withContext = withContext || o.contextFunctionOp != nil
 		}
 	}
 	if f.singleton != nil {
@@ -654,22 +820,26 @@ func (f *functionDecl) bindings() ([]*functions.Overload, error) {
 			return overloads, nil
 		}
 		return append(overloads, &functions.Overload{
-			Operator:     f.name,
-			Unary:        overloads[0].Unary,
-			Binary:       overloads[0].Binary,
-			Function:     overloads[0].Function,
-			NonStrict:    overloads[0].NonStrict,
-			OperandTrait: overloads[0].OperandTrait,
+			Operator:        f.name,
+			Unary:           overloads[0].Unary,
+			Binary:          overloads[0].Binary,
+			Function:        overloads[0].Function,
+			NonStrict:       overloads[0].NonStrict,
+			OperandTrait:    overloads[0].OperandTrait,
+			ContextFunction: overloads[0].ContextFunction,
 		}), nil
 	}
 	// All of the defined overloads are wrapped into a top-level function which
 	// performs dynamic dispatch to the proper overload based on the argument types.
 	bindings := append([]*functions.Overload{}, overloads...)
-	funcDispatch := func(args ...ref.Val) ref.Val {
+	dispatch := func(ctx context.Context, args ...ref.Val) ref.Val {
 		for _, o := range f.overloads {
 			if !o.matchesRuntimeSignature(args...) {
 				continue
 			}
+			if o.contextFunctionOp != nil {
+				return o.contextFunctionOp(ctx, args...)
+			}
 			switch len(args) {
 			case 1:
 				if o.unaryOp != nil {
@@ -689,9 +859,17 @@ func (f *functionDecl) bindings() ([]*functions.Overload, error) {
 	}
 	function := &functions.Overload{
 		Operator:  f.name,
-		Function:  funcDispatch,
 		NonStrict: nonStrict,
 	}
+	// The context of the evaluation is only supplied to the dispatcher when one of the overloads
+	// requires it.
+	if withContext {
+		function.ContextFunction = dispatch
+	} else {
+		function.Function = func(args ...ref.Val) ref.Val {
+			return dispatch(context.Background(), args...)
+		}
+	}
 	return append(bindings, function), nil
 }
 
@@ -713,11 +891,22 @@ func (f *functionDecl) merge(other *functionDecl) (*functionDecl, error) {
 		return nil, err
 	}
 	merged := &functionDecl{
-		name:        f.name,
-		overloads:   make([]*overloadDecl, len(f.overloads)),
-		options:     []FunctionOpt{},
-		initialized: true,
-		singleton:   f.singleton,
+		name:             f.name,
+		overloads:        make([]*overloadDecl, len(f.overloads)),
+		options:          []FunctionOpt{},
+		initialized:      true,
+		singleton:        f.singleton,
+		nondeterministic: f.nondeterministic || other.nondeterministic,
+		programSourced:   f.programSourced || other.programSourced,
+		rebindable:       f.rebindable || other.rebindable,
+		displayName:      f.displayName,
+		doc:              f.doc,
+	}
+	if other.doc != nil {
+		merged.doc = other.doc
+	}
+	if other.displayName != "" {
+		merged.displayName = other.displayName
 	}
 	copy(merged.overloads, f.overloads)
 	for _, o := range other.overloads {
@@ -773,17 +962,21 @@ type overloadDecl struct {
 	memberFunction bool
 
 	// binding options, optional but encouraged.
-	unaryOp    functions.UnaryOp
-	binaryOp   functions.BinaryOp
-	functionOp functions.FunctionOp
+	unaryOp           functions.UnaryOp
+	binaryOp          functions.BinaryOp
+	functionOp        functions.FunctionOp
+	contextFunctionOp functions.ContextFunctionOp
+	asyncOp           AsyncFunctionOp
 
 	// behavioral options, uncommon
 	nonStrict    bool
 	operandTrait int
+	pure         bool
+	conversion   bool
 }
 
 func (o *overloadDecl) hasBinding() bool {
-	return o.unaryOp != nil || o.binaryOp != nil || o.functionOp != nil
+	return o.unaryOp != nil || o.binaryOp != nil || o.functionOp != nil || o.contextFunctionOp != nil
 }
 
 // guardedUnaryOp creates an invocation guard around the provided unary operator, if one is defined.
@@ -825,6 +1018,20 @@ func (o *overloadDecl) guardedFunctionOp(funcName string) functions.FunctionOp {
 	}
 }
 
+// guardedContextFunctionOp creates an invocation guard around the provided context-aware function
+// binding, if one is provided.
+func (o *overloadDecl) guardedContextFunctionOp(funcName string) functions.ContextFunctionOp {
+	if o.contextFunctionOp == nil {
+		return nil
+	}
+	return func(ctx context.Context, args ...ref.Val) ref.Val {
+		if !o.matchesRuntimeSignature(args...) {
+			return noSuchOverload(funcName, args...)
+		}
+		return o.contextFunctionOp(ctx, args...)
+	}
+}
+
 // matchesRuntimeUnarySignature indicates whether the argument type is runtime assiganble to the overload's expected argument.
 func (o *overloadDecl) matchesRuntimeUnarySignature(arg ref.Val) bool {
 	if o.nonStrict && types.IsUnknownOrError(arg) {
@@ -1009,6 +1216,13 @@ func TypeToExprType(t *Type) (*exprpb.Type, error) {
 	case TypeParamKind:
 		return decls.NewTypeParamType(t.runtimeType.TypeName()), nil
 	case TypeKind:
+		if len(t.parameters) == 1 {
+			param, err := TypeToExprType(t.parameters[0])
+			if err != nil {
+				return nil, err
+			}
+			return decls.NewTypeType(param), nil
+		}
 		return decls.NewTypeType(decls.Dyn), nil
 	case UintKind:
 		return maybeWrapper(t, decls.Uint), nil
@@ -1098,7 +1312,14 @@ func ExprTypeToType(t *exprpb.Type) (*Type, error) {
 	case *exprpb.Type_TypeParam:
 		return TypeParamType(t.GetTypeParam()), nil
 	case *exprpb.Type_Type:
-		return TypeType, nil
+		if t.GetType() == nil || t.GetType().GetDyn() != nil {
+			return TypeType, nil
+		}
+		param, err := ExprTypeToType(t.GetType())
+		if err != nil {
+			return nil, err
+		}
+		return TypeTypeWithParam(param), nil
 	case *exprpb.Type_WellKnown:
 		switch t.GetWellKnown() {
 		case exprpb.Type_ANY:
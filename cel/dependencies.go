@@ -0,0 +1,249 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/common"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// NamedExpr is an expression which may be referenced by name from other named expressions.
+type NamedExpr struct {
+	// Name is the identifier, optionally qualified, by which other expressions refer to the
+	// expression.
+	Name string
+
+	// Expr is the source of the expression.
+	Expr string
+}
+
+// DependencyGraph describes the dependencies between a set of named expressions, as returned by
+// Env.DependencyGraph.
+type DependencyGraph struct {
+	// Order lists the expression names such that every expression follows the expressions it
+	// depends on.
+	Order []string
+
+	// Levels groups the expression names such that every expression depends only on expressions
+	// within earlier levels. The expressions within a level are independent of one another, and
+	// so may be evaluated in parallel once the preceding levels have been evaluated.
+	Levels [][]string
+
+	deps map[string][]string
+}
+
+// Dependencies returns the names of the expressions which the named expression refers to, in the
+// order of their first reference.
+func (g *DependencyGraph) Dependencies(name string) []string {
+	return g.deps[name]
+}
+
+// DependencyCycle describes a cycle of references between named expressions.
+type DependencyCycle struct {
+	// Path lists the names of the expressions within the cycle, starting and ending with the same
+	// name.
+	Path []string
+
+	// Location is the location of the reference which closes the cycle, within the source of the
+	// expression which is second to last in the path. The source is described by the name of the
+	// expression.
+	Location common.Location
+}
+
+// String returns a description of the cycle prefixed with the location of the reference which
+// closes it.
+func (c DependencyCycle) String() string {
+	return fmt.Sprintf("%s:%d:%d: dependency cycle: %s",
+		c.Path[len(c.Path)-2], c.Location.Line(), c.Location.Column()+1, strings.Join(c.Path, " -> "))
+}
+
+// DependencyCycleError lists every cycle found while building a dependency graph.
+type DependencyCycleError struct {
+	Cycles []DependencyCycle
+}
+
+// Error implements the error interface method.
+func (e *DependencyCycleError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d dependency cycle(s):", len(e.Cycles))
+	for _, c := range e.Cycles {
+		sb.WriteString("\n  ")
+		sb.WriteString(c.String())
+	}
+	return sb.String()
+}
+
+// DependencyGraph parses the named expressions and returns the graph of the references between
+// them, for use when scheduling their evaluation.
+//
+// An expression depends on another when it refers to the name of the other expression as an
+// identifier, or as a qualified identifier when the name is qualified, and the reference is not
+// shadowed by a comprehension variable. References to other names, such as variables, are ignored.
+//
+// Parse errors are reported using the name of the expression as the source description. When the
+// references are cyclic, a *DependencyCycleError is returned which lists every cycle along with
+// the location of the reference which closes it.
+func (e *Env) DependencyGraph(exprs ...NamedExpr) (*DependencyGraph, error) {
+	names := make(map[string]bool, len(exprs))
+	for _, ne := range exprs {
+		if names[ne.Name] {
+			return nil, fmt.Errorf("duplicate expression name: %s", ne.Name)
+		}
+		names[ne.Name] = true
+	}
+	asts := make(map[string]*Ast, len(exprs))
+	refs := make(map[string][]exprRef, len(exprs))
+	deps := make(map[string][]string, len(exprs))
+	for _, ne := range exprs {
+		ast, iss := e.ParseSource(common.NewStringSource(ne.Expr, ne.Name))
+		if iss.Err() != nil {
+			return nil, iss.Err()
+		}
+		asts[ne.Name] = ast
+		c := &refCollector{names: names}
+		WalkExpr(ast.Expr(), c.visit, nil)
+		refs[ne.Name] = c.refs
+		seen := map[string]bool{}
+		for _, r := range c.refs {
+			if !seen[r.name] {
+				seen[r.name] = true
+				deps[ne.Name] = append(deps[ne.Name], r.name)
+			}
+		}
+	}
+
+	// Find the cycles with a depth-first search, reporting the reference along each back edge.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(exprs))
+	var path []string
+	var cycles []DependencyCycle
+	var search func(name string)
+	search = func(name string) {
+		state[name] = visiting
+		path = append(path, name)
+		for _, r := range refs[name] {
+			switch state[r.name] {
+			case unvisited:
+				search(r.name)
+			case visiting:
+				start := len(path) - 1
+				for path[start] != r.name {
+					start--
+				}
+				cycle := append(append([]string{}, path[start:]...), r.name)
+				cycles = append(cycles, DependencyCycle{
+					Path:     cycle,
+					Location: exprLocation(asts[name], r.id),
+				})
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+	}
+	for _, ne := range exprs {
+		if state[ne.Name] == unvisited {
+			search(ne.Name)
+		}
+	}
+	if len(cycles) != 0 {
+		return nil, &DependencyCycleError{Cycles: cycles}
+	}
+
+	// Assign each expression to the level following the deepest of its dependencies.
+	levels := make(map[string]int, len(exprs))
+	var level func(name string) int
+	level = func(name string) int {
+		if l, found := levels[name]; found {
+			return l
+		}
+		l := 0
+		for _, dep := range deps[name] {
+			if dl := level(dep) + 1; dl > l {
+				l = dl
+			}
+		}
+		levels[name] = l
+		return l
+	}
+	g := &DependencyGraph{deps: deps}
+	for _, ne := range exprs {
+		l := level(ne.Name)
+		for len(g.Levels) <= l {
+			g.Levels = append(g.Levels, []string{})
+		}
+		g.Levels[l] = append(g.Levels[l], ne.Name)
+	}
+	for _, names := range g.Levels {
+		g.Order = append(g.Order, names...)
+	}
+	return g, nil
+}
+
+// exprRef is a reference to a named expression.
+type exprRef struct {
+	name string
+	id   int64
+}
+
+// refCollector collects the references to named expressions within a parsed expression.
+type refCollector struct {
+	names map[string]bool
+	refs  []exprRef
+}
+
+func (c *refCollector) visit(e *exprpb.Expr, scope *ExprScope) bool {
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		name := e.GetIdentExpr().GetName()
+		if c.names[name] && !scope.IsLocal(name) {
+			c.refs = append(c.refs, exprRef{name: name, id: e.GetId()})
+		}
+	case *exprpb.Expr_SelectExpr:
+		if name, root, found := qualifiedIdent(e); found && c.names[name] && !scope.IsLocal(root) {
+			c.refs = append(c.refs, exprRef{name: name, id: e.GetId()})
+			return false
+		}
+	}
+	return true
+}
+
+// qualifiedIdent returns the qualified identifier spelled by a chain of field selections on an
+// identifier, along with the root identifier of the chain.
+func qualifiedIdent(e *exprpb.Expr) (string, string, bool) {
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		name := e.GetIdentExpr().GetName()
+		return name, name, true
+	case *exprpb.Expr_SelectExpr:
+		sel := e.GetSelectExpr()
+		if sel.GetTestOnly() {
+			return "", "", false
+		}
+		operand, root, found := qualifiedIdent(sel.GetOperand())
+		if !found {
+			return "", "", false
+		}
+		return operand + "." + sel.GetField(), root, true
+	}
+	return "", "", false
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDependencyGraph(t *testing.T) {
+	env, err := NewEnv(Variable("request", MapType(StringType, DynType)))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	g, err := env.DependencyGraph(
+		NamedExpr{Name: "allowed", Expr: `is_admin || (is_owner && !limits.exceeded)`},
+		NamedExpr{Name: "is_admin", Expr: `'admin' in roles`},
+		NamedExpr{Name: "is_owner", Expr: `request.user == request.owner`},
+		NamedExpr{Name: "roles", Expr: `request.roles.filter(r, r != '')`},
+		NamedExpr{Name: "limits.exceeded", Expr: `request.count > 10`},
+		NamedExpr{Name: "shadowed", Expr: `[1].exists(roles, roles > 0)`},
+	)
+	if err != nil {
+		t.Fatalf("env.DependencyGraph() failed: %v", err)
+	}
+	wantLevels := [][]string{
+		{"is_owner", "roles", "limits.exceeded", "shadowed"},
+		{"is_admin"},
+		{"allowed"},
+	}
+	if !reflect.DeepEqual(g.Levels, wantLevels) {
+		t.Errorf("g.Levels got %v, wanted %v", g.Levels, wantLevels)
+	}
+	wantOrder := []string{"is_owner", "roles", "limits.exceeded", "shadowed", "is_admin", "allowed"}
+	if !reflect.DeepEqual(g.Order, wantOrder) {
+		t.Errorf("g.Order got %v, wanted %v", g.Order, wantOrder)
+	}
+	wantDeps := []string{"is_admin", "is_owner", "limits.exceeded"}
+	if !reflect.DeepEqual(g.Dependencies("allowed"), wantDeps) {
+		t.Errorf("g.Dependencies('allowed') got %v, wanted %v", g.Dependencies("allowed"), wantDeps)
+	}
+	if len(g.Dependencies("shadowed")) != 0 {
+		t.Errorf("g.Dependencies('shadowed') got %v, wanted none", g.Dependencies("shadowed"))
+	}
+
+	_, err = env.DependencyGraph(
+		NamedExpr{Name: "a", Expr: `b && c`},
+		NamedExpr{Name: "b", Expr: `request.x == 1 ||
+  a`},
+		NamedExpr{Name: "c", Expr: `c`},
+	)
+	var cycleErr *DependencyCycleError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("env.DependencyGraph() got error %v, wanted a DependencyCycleError", err)
+	}
+	want := `2 dependency cycle(s):
+  b:2:3: dependency cycle: a -> b -> a
+  c:1:1: dependency cycle: c -> c`
+	if err.Error() != want {
+		t.Errorf("env.DependencyGraph() got error %q, wanted %q", err.Error(), want)
+	}
+
+	_, err = env.DependencyGraph(NamedExpr{Name: "a", Expr: `1 +`})
+	if err == nil || !strings.Contains(err.Error(), "ERROR: a:1:4") {
+		t.Errorf("env.DependencyGraph() got error %v, wanted a parse error within 'a'", err)
+	}
+	_, err = env.DependencyGraph(NamedExpr{Name: "a", Expr: `1`}, NamedExpr{Name: "a", Expr: `2`})
+	if err == nil || err.Error() != "duplicate expression name: a" {
+		t.Errorf("env.DependencyGraph() got error %v, wanted duplicate name error", err)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"github.com/google/cel-go/common"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// checkDeterminism reports an error for each call to a function declared as nondeterministic when
+// the environment has been configured with DeterministicEval, other than the functions whose values
+// are supplied by the program or its activation, such as now().
+func (e *Env) checkDeterminism(ast *Ast) *common.Errors {
+	errs := common.NewErrors(ast.Source())
+	if !e.HasFeature(featureDeterministicEval) {
+		return errs
+	}
+	visitExpr(ast.Expr(), func(expr *exprpb.Expr) {
+		call := expr.GetCallExpr()
+		if call == nil {
+			return
+		}
+		fn, found := e.functions[call.GetFunction()]
+		if !found || !fn.nondeterministic || fn.programSourced {
+			return
+		}
+		errs.ReportError(exprLocation(ast, expr.GetId()),
+			"nondeterministic function '%s' is not permitted in deterministic evaluation mode",
+			call.GetFunction())
+	})
+	return errs
+}
+
+// exprLocation returns the source location of the expression id, or common.NoLocation if the
+// location is not known.
+func exprLocation(ast *Ast, id int64) common.Location {
+	offset, found := ast.SourceInfo().GetPositions()[id]
+	if !found || ast.Source() == nil {
+		return common.NoLocation
+	}
+	loc, found := ast.Source().OffsetLocation(offset)
+	if !found {
+		return common.NoLocation
+	}
+	return loc
+}
@@ -0,0 +1,78 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+func TestDeterministicEval(t *testing.T) {
+	clock := func(...ref.Val) ref.Val { return types.Timestamp{Time: time.Now()} }
+	opts := []EnvOption{
+		Variable("start", TimestampType),
+		Function("clock",
+			Overload("clock", []*Type{}, TimestampType, FunctionBinding(clock)),
+			NonDeterministic()),
+	}
+	env, err := NewEnv(opts...)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	if _, iss := env.Compile("clock() > start"); iss.Err() != nil {
+		t.Errorf("env.Compile() without DeterministicEval failed: %v", iss.Err())
+	}
+
+	detEnv, err := env.Extend(DeterministicEval())
+	if err != nil {
+		t.Fatalf("env.Extend() failed: %v", err)
+	}
+	_, iss := detEnv.Compile("start < clock()")
+	if iss.Err() == nil || !strings.Contains(iss.Err().Error(), "nondeterministic function 'clock'") {
+		t.Errorf("detEnv.Compile() got %v, wanted nondeterministic function error", iss.Err())
+	}
+	if _, iss := detEnv.Compile("start < timestamp('2023-01-01T00:00:00Z')"); iss.Err() != nil {
+		t.Errorf("detEnv.Compile() failed: %v", iss.Err())
+	}
+
+	parsed, iss := detEnv.Parse("clock()")
+	if iss.Err() != nil {
+		t.Fatalf("detEnv.Parse() failed: %v", iss.Err())
+	}
+	if _, err := detEnv.Program(parsed); err == nil {
+		t.Error("detEnv.Program() succeeded, wanted nondeterministic function error")
+	}
+
+	// The flag states tested by flags.enabled() are supplied by the program.
+	flagsEnv, err := detEnv.Extend(FeatureFlags())
+	if err != nil {
+		t.Fatalf("detEnv.Extend() failed: %v", err)
+	}
+	ast, iss := flagsEnv.Compile("flags.enabled('beta')")
+	if iss.Err() != nil {
+		t.Fatalf("flagsEnv.Compile() failed: %v", iss.Err())
+	}
+	prg, err := flagsEnv.Program(ast, FlagSource(FlagStates{"beta": true}))
+	if err != nil {
+		t.Fatalf("flagsEnv.Program() failed: %v", err)
+	}
+	if out, _, err := prg.Eval(NoVars()); err != nil || out != types.True {
+		t.Errorf("prg.Eval() got %v, %v, wanted true", out, err)
+	}
+}
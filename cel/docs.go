@@ -0,0 +1,151 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DocKind indicates the kind of declaration a Doc describes.
+type DocKind int
+
+const (
+	// VariableDocKind describes a variable declaration.
+	VariableDocKind DocKind = iota + 1
+
+	// FunctionDocKind describes a function declaration.
+	FunctionDocKind
+
+	// MacroDocKind describes a macro.
+	MacroDocKind
+)
+
+// String implements the fmt.Stringer interface method.
+func (k DocKind) String() string {
+	switch k {
+	case VariableDocKind:
+		return "variable"
+	case FunctionDocKind:
+		return "function"
+	case MacroDocKind:
+		return "macro"
+	}
+	return fmt.Sprintf("DocKind(%d)", int(k))
+}
+
+// Doc holds the documentation of a declaration within an environment.
+type Doc struct {
+	// Kind indicates whether the documented declaration is a variable, function, or macro.
+	Kind DocKind
+
+	// Name is the name of the variable, function, or macro.
+	Name string
+
+	// Type is the declared type of a variable, and nil otherwise.
+	Type *Type
+
+	// Description is a human-readable description of the declaration.
+	Description string
+
+	// Examples are CEL expressions which illustrate the usage of the declaration.
+	Examples []string
+}
+
+// VariableDoc attaches a description and usage examples to a variable declaration, e.g.
+// `Variable("user", StringType, VariableDoc("the authenticated user", "user == 'alice'"))`.
+func VariableDoc(description string, examples ...string) VariableOpt {
+	return func(v *variableDecl) (*variableDecl, error) {
+		v.doc = &Doc{
+			Kind:        VariableDocKind,
+			Name:        v.name,
+			Type:        v.t,
+			Description: description,
+			Examples:    examples,
+		}
+		return v, nil
+	}
+}
+
+// FunctionDoc attaches a description and usage examples to a function declaration.
+//
+// When a function is declared more than once, the most recently declared documentation is
+// retained.
+func FunctionDoc(description string, examples ...string) FunctionOpt {
+	return func(f *functionDecl) (*functionDecl, error) {
+		f.doc = &Doc{
+			Kind:        FunctionDocKind,
+			Name:        f.name,
+			Description: description,
+			Examples:    examples,
+		}
+		return f, nil
+	}
+}
+
+// MacroDoc attaches a description and usage examples to the macros with the given name, e.g.
+// `all`, as macros are otherwise anonymous to the environment.
+func MacroDoc(name, description string, examples ...string) EnvOption {
+	return func(e *Env) (*Env, error) {
+		e.macroDocs[name] = &Doc{
+			Kind:        MacroDocKind,
+			Name:        name,
+			Description: description,
+			Examples:    examples,
+		}
+		return e, nil
+	}
+}
+
+// Doc returns the documentation of the variable, function, or macro of the given kind and name,
+// if any has been attached to its declaration.
+func (e *Env) Doc(kind DocKind, name string) (*Doc, bool) {
+	var doc *Doc
+	switch kind {
+	case VariableDocKind:
+		doc = e.variableDocs[name]
+	case FunctionDocKind:
+		if fn, found := e.functions[name]; found {
+			doc = fn.doc
+		}
+	case MacroDocKind:
+		doc = e.macroDocs[name]
+	}
+	return doc, doc != nil
+}
+
+// Docs returns the documentation attached to the declarations of the environment, ordered by kind
+// and then by name.
+func (e *Env) Docs() []*Doc {
+	var docs []*Doc
+	for _, doc := range e.variableDocs {
+		docs = append(docs, doc)
+	}
+	for _, fn := range e.functions {
+		if fn.doc != nil {
+			docs = append(docs, fn.doc)
+		}
+	}
+	for _, doc := range e.macroDocs {
+		docs = append(docs, doc)
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		if docs[i].Kind != docs[j].Kind {
+			return docs[i].Kind < docs[j].Kind
+		}
+		return docs[i].Name < docs[j].Name
+	})
+	return docs
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+func TestDocs(t *testing.T) {
+	env, err := NewEnv(
+		Variable("user", StringType, VariableDoc("the authenticated user", "user == 'alice'")),
+		Variable("timeout", IntType,
+			VariableDoc("the request timeout"), DefaultValue(30), Unit("seconds")),
+		Variable("undocumented", IntType),
+		Function("greet",
+			FunctionDoc("greets the named user", "greet('alice') == 'hello, alice'"),
+			Overload("greet_string", []*Type{StringType}, StringType,
+				UnaryBinding(func(arg ref.Val) ref.Val {
+					return types.String("hello, ") + arg.(types.String)
+				}))),
+		MacroDoc("all", "tests whether a predicate holds for all elements", "[1, 2].all(x, x > 0)"),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	doc, found := env.Doc(VariableDocKind, "user")
+	if !found || doc.Description != "the authenticated user" || doc.Type != StringType {
+		t.Errorf("env.Doc(variable, user) got %v, %v", doc, found)
+	}
+	// Documented variables may also have defaults and units.
+	doc, found = env.Doc(VariableDocKind, "timeout")
+	if !found || doc.Description != "the request timeout" || doc.Type != IntType {
+		t.Errorf("env.Doc(variable, timeout) got %v, %v", doc, found)
+	}
+	timeoutAst, iss := env.Compile(`timeout`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile(timeout) failed: %v", iss.Err())
+	}
+	timeoutPrg, err := env.Program(timeoutAst)
+	if err != nil {
+		t.Fatalf("env.Program(timeout) failed: %v", err)
+	}
+	if out, _, err := timeoutPrg.Eval(NoVars()); err != nil || out != types.Int(30) {
+		t.Errorf("timeout got %v, %v, wanted the default value 30", out, err)
+	}
+	if _, found := env.Doc(VariableDocKind, "undocumented"); found {
+		t.Error("env.Doc(variable, undocumented) found a doc")
+	}
+	// Documentation is retained when the function is extended.
+	ext, err := env.Extend(Function("greet",
+		Overload("greet_int", []*Type{IntType}, StringType)))
+	if err != nil {
+		t.Fatalf("env.Extend() failed: %v", err)
+	}
+	var got []string
+	for _, d := range ext.Docs() {
+		got = append(got, fmt.Sprintf("%s %s: %s %v", d.Kind, d.Name, d.Description, d.Examples))
+	}
+	want := []string{
+		"variable timeout: the request timeout []",
+		"variable user: the authenticated user [user == 'alice']",
+		"function greet: greets the named user [greet('alice') == 'hello, alice']",
+		"macro all: tests whether a predicate holds for all elements [[1, 2].all(x, x > 0)]",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ext.Docs() got %v, wanted %v", got, want)
+	}
+}
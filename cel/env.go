@@ -42,6 +42,15 @@ type Ast struct {
 	source  Source
 	refMap  map[int64]*exprpb.Reference
 	typeMap map[int64]*exprpb.Type
+
+	// resolutions records the names resolved through the container during type-checking.
+	resolutions map[int64]*ContainerResolution
+
+	// defaults records the default values of the variables referenced by the expression.
+	defaults map[string]ref.Val
+
+	// warnings records the findings of the environment's linters.
+	warnings []common.Error
 }
 
 // Expr returns the proto serializable instance of the parsed/checked expression.
@@ -86,6 +95,13 @@ func (ast *Ast) Source() Source {
 	return ast.source
 }
 
+// Warnings returns the non-fatal warnings reported for the Ast when it was type-checked, such as
+// those of the linters configured with the Linters option, in the order in which they were
+// reported.
+func (ast *Ast) Warnings() []common.Error {
+	return append([]common.Error{}, ast.warnings...)
+}
+
 // FormatType converts a type message into a string representation.
 func FormatType(t *exprpb.Type) string {
 	return checker.FormatCheckedType(t)
@@ -103,6 +119,26 @@ type Env struct {
 	features        map[int]bool
 	appliedFeatures map[int]bool
 	libraries       map[string]bool
+	sensitivePaths  map[string]bool
+	variableDocs    map[string]*Doc
+	macroDocs       map[string]*Doc
+
+	// Values of variables which are not bound by the activation, keyed by variable name.
+	variableDefaults map[string]ref.Val
+
+	// Units of the quantities held by variables, keyed by variable name, and the functions which
+	// convert quantities between units, keyed by function name.
+	variableUnits   map[string]string
+	unitConversions map[string]unitConversion
+
+	// Size estimates keyed by profile name and path, where the default sizes have an empty name.
+	sizeProfiles map[string]map[string]checker.SizeEstimate
+
+	// Result of int and uint arithmetic which overflows.
+	overflowPolicy interpreter.OverflowPolicy
+
+	// Limits on the inputs of the standard string functions.
+	stringLimits interpreter.StringLimits
 
 	// Internal parser representation
 	prsr     *parser.Parser
@@ -114,8 +150,23 @@ type Env struct {
 	chkOnce sync.Once
 	chkOpts []checker.Option
 
+	// Linters run against successfully checked expressions.
+	linters []Linter
+
+	// Layers whose declaration quotas apply to the environment, in the order they were added.
+	layers []*envLayer
+
 	// Program options tied to the environment
 	progOpts []ProgramOption
+
+	// Fingerprint of the environment, computed on first use from the fingerprint of the parent
+	// environment, the declarations added after the first fpDecls, and the generation of the
+	// opaque configuration.
+	fp        envFingerprint
+	fpOnce    sync.Once
+	fpParent  envFingerprint
+	fpDecls   int
+	opaqueGen uint64
 }
 
 // NewEnv creates a program environment configured with the standard library of CEL functions and
@@ -162,7 +213,15 @@ func NewCustomEnv(opts ...EnvOption) (*Env, error) {
 		features:        map[int]bool{},
 		appliedFeatures: map[int]bool{},
 		libraries:       map[string]bool{},
+		sensitivePaths:  map[string]bool{},
+		variableDocs:    map[string]*Doc{},
+		macroDocs:       map[string]*Doc{},
+		sizeProfiles:    map[string]map[string]checker.SizeEstimate{},
 		progOpts:        []ProgramOption{},
+
+		variableDefaults: map[string]ref.Val{},
+		variableUnits:    map[string]string{},
+		unitConversions:  map[string]unitConversion{},
 	}).configure(opts)
 }
 
@@ -185,18 +244,66 @@ func (e *Env) Check(ast *Ast) (*Ast, *Issues) {
 		return nil, NewIssues(errs)
 	}
 
+	written := writtenNames(pe.GetExpr(), map[int64]string{})
 	res, errs := checker.Check(pe, ast.Source(), e.chk)
 	if len(errs.GetErrors()) > 0 {
 		return nil, NewIssues(errs)
 	}
 	// Manually create the Ast to ensure that the Ast source information (which may be more
 	// detailed than the information provided by Check), is returned to the caller.
-	return &Ast{
-		source:  ast.Source(),
-		expr:    res.GetExpr(),
-		info:    res.GetSourceInfo(),
-		refMap:  res.GetReferenceMap(),
-		typeMap: res.GetTypeMap()}, nil
+	checked := &Ast{
+		source:      ast.Source(),
+		expr:        res.GetExpr(),
+		info:        res.GetSourceInfo(),
+		refMap:      res.GetReferenceMap(),
+		typeMap:     res.GetTypeMap(),
+		resolutions: e.containerResolutions(written, res.GetReferenceMap()),
+		defaults:    e.referencedDefaults(res.GetReferenceMap())}
+	if iss := e.checkRestrictions(checked); iss != nil {
+		return nil, iss
+	}
+	checked.warnings = e.lint(checked)
+	return checked, nil
+}
+
+// checkRestrictions reports the uses of nondeterministic functions, conversions, and units which
+// the environment prohibits within a type-checked Ast, or returns nil when there are none.
+func (e *Env) checkRestrictions(checked *Ast) *Issues {
+	for _, check := range []func(*Ast) *common.Errors{e.checkDeterminism, e.checkConversions, e.checkUnits} {
+		if errs := check(checked); len(errs.GetErrors()) > 0 {
+			return NewIssues(errs)
+		}
+	}
+	return nil
+}
+
+// VariableDefaults returns the default values of the variables referenced by a type-checked
+// expression, keyed by variable name, as declared with the DefaultValue option.
+//
+// Defaults are recorded by Env.Check, and are not available for Asts which were constructed from a
+// CheckedExpr proto.
+func (ast *Ast) VariableDefaults() map[string]ref.Val {
+	defaults := make(map[string]ref.Val, len(ast.defaults))
+	for name, val := range ast.defaults {
+		defaults[name] = val
+	}
+	return defaults
+}
+
+// referencedDefaults returns the default values of the variables referenced by the expression.
+func (e *Env) referencedDefaults(refMap map[int64]*exprpb.Reference) map[string]ref.Val {
+	var defaults map[string]ref.Val
+	for _, r := range refMap {
+		val, found := e.variableDefaults[r.GetName()]
+		if !found || len(r.GetOverloadId()) != 0 {
+			continue
+		}
+		if defaults == nil {
+			defaults = map[string]ref.Val{}
+		}
+		defaults[r.GetName()] = val
+	}
+	return defaults
 }
 
 // Compile combines the Parse and Check phases CEL program compilation to produce an Ast and
@@ -314,6 +421,35 @@ func (e *Env) Extend(opts ...EnvOption) (*Env, error) {
 	for k, v := range e.libraries {
 		libsCopy[k] = v
 	}
+	sensitiveCopy := make(map[string]bool, len(e.sensitivePaths))
+	for k, v := range e.sensitivePaths {
+		sensitiveCopy[k] = v
+	}
+	defaultsCopy := make(map[string]ref.Val, len(e.variableDefaults))
+	for k, v := range e.variableDefaults {
+		defaultsCopy[k] = v
+	}
+	unitsCopy := make(map[string]string, len(e.variableUnits))
+	for k, v := range e.variableUnits {
+		unitsCopy[k] = v
+	}
+	unitConvsCopy := make(map[string]unitConversion, len(e.unitConversions))
+	for k, v := range e.unitConversions {
+		unitConvsCopy[k] = v
+	}
+	varDocsCopy := make(map[string]*Doc, len(e.variableDocs))
+	for k, v := range e.variableDocs {
+		varDocsCopy[k] = v
+	}
+	macroDocsCopy := make(map[string]*Doc, len(e.macroDocs))
+	for k, v := range e.macroDocs {
+		macroDocsCopy[k] = v
+	}
+
+	sizeProfilesCopy := make(map[string]map[string]checker.SizeEstimate, len(e.sizeProfiles))
+	for k, v := range e.sizeProfiles {
+		sizeProfilesCopy[k] = v
+	}
 
 	ext := &Env{
 		Container:       e.Container,
@@ -325,9 +461,25 @@ func (e *Env) Extend(opts ...EnvOption) (*Env, error) {
 		features:        featuresCopy,
 		appliedFeatures: appliedFeaturesCopy,
 		libraries:       libsCopy,
+		sensitivePaths:  sensitiveCopy,
+		variableDocs:    varDocsCopy,
+		macroDocs:       macroDocsCopy,
+		sizeProfiles:    sizeProfilesCopy,
+		overflowPolicy:  e.overflowPolicy,
+		stringLimits:    e.stringLimits,
 		provider:        provider,
 		chkOpts:         chkOptsCopy,
 		prsrOpts:        prsrOptsCopy,
+		linters:         append([]Linter{}, e.linters...),
+		layers:          append([]*envLayer{}, e.layers...),
+
+		variableDefaults: defaultsCopy,
+		variableUnits:    unitsCopy,
+		unitConversions:  unitConvsCopy,
+
+		fpParent:  e.fingerprint(),
+		fpDecls:   len(decsCopy),
+		opaqueGen: e.opaqueGen,
 	}
 	return ext.configure(opts)
 }
@@ -376,6 +528,9 @@ func (e *Env) ParseSource(src Source) (*Ast, *Issues) {
 
 // Program generates an evaluable instance of the Ast within the environment (Env).
 func (e *Env) Program(ast *Ast, opts ...ProgramOption) (Program, error) {
+	if errs := e.checkDeterminism(ast); len(errs.GetErrors()) > 0 {
+		return nil, errors.New(errs.ToDisplayString())
+	}
 	optSet := e.progOpts
 	if len(opts) != 0 {
 		mergedOpts := []ProgramOption{}
@@ -383,7 +538,15 @@ func (e *Env) Program(ast *Ast, opts ...ProgramOption) (Program, error) {
 		mergedOpts = append(mergedOpts, opts...)
 		optSet = mergedOpts
 	}
-	return newProgram(e, ast, optSet)
+	prg, err := newProgram(e, ast, optSet)
+	if err != nil {
+		return nil, err
+	}
+	// Evaluate programs in rounds when asynchronous overloads may suspend calls.
+	if async := e.asyncOverloads(); len(async) > 0 {
+		return &asyncProgram{Program: prg, bindings: async}, nil
+	}
+	return prg, nil
 }
 
 // TypeAdapter returns the `ref.TypeAdapter` configured for the environment.
@@ -459,12 +622,15 @@ func (e *Env) ResidualAst(a *Ast, details *EvalDetails) (*Ast, error) {
 }
 
 // EstimateCost estimates the cost of a type checked CEL expression using the length estimates of input data and
-// extension functions provided by estimator.
+// extension functions provided by estimator, and the sizes registered with SizeEstimates.
 func (e *Env) EstimateCost(ast *Ast, estimator checker.CostEstimator) (checker.CostEstimate, error) {
 	checked, err := AstToCheckedExpr(ast)
 	if err != nil {
 		return checker.CostEstimate{}, fmt.Errorf("EsimateCost could not inspect Ast: %v", err)
 	}
+	if len(e.sizeProfiles[""]) != 0 {
+		estimator = e.sizeEstimator("", estimator)
+	}
 	return checker.Cost(checked, estimator), nil
 }
 
@@ -472,6 +638,7 @@ func (e *Env) EstimateCost(ast *Ast, estimator checker.CostEstimator) (checker.C
 func (e *Env) configure(opts []EnvOption) (*Env, error) {
 	// Customized the environment using the provided EnvOption values. If an error is
 	// generated at any step this, will be returned as a nil Env with a non-nil error.
+	opaque := e.opaqueConfig()
 	var err error
 	for _, opt := range opts {
 		e, err = opt(e)
@@ -480,12 +647,25 @@ func (e *Env) configure(opts []EnvOption) (*Env, error) {
 		}
 	}
 
+	// Detect the conflicts between composed type providers once all types have been registered.
+	if composite, isComposite := e.provider.(*compositeTypeProvider); isComposite {
+		if err := composite.validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	// If the default UTC timezone fix has been enabled, make sure the library is configured
 	e, err = e.maybeApplyFeature(featureDefaultUTCTimeZone, Lib(timeUTCLibrary{}))
 	if err != nil {
 		return nil, err
 	}
 
+	// Declare the ordering comparisons of lists and maps when aggregate comparisons are enabled.
+	e, err = e.maybeApplyFeature(featureAggregateComparisons, Lib(aggregateComparisonLibrary{}))
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize all of the functions configured within the environment.
 	for _, fn := range e.functions {
 		err = fn.init()
@@ -494,6 +674,17 @@ func (e *Env) configure(opts []EnvOption) (*Env, error) {
 		}
 	}
 
+	// Limit the declarations added on top of the layers of the environment.
+	if err := e.enforceLayerQuotas(); err != nil {
+		return nil, err
+	}
+
+	// Distinguish the environment from others when options changed configuration which cannot be
+	// compared, such as function bindings.
+	if opaque.changed(e) {
+		e.opaqueGen = nextOpaqueGeneration()
+	}
+
 	// Configure the parser.
 	prsrOpts := []parser.Option{}
 	prsrOpts = append(prsrOpts, e.prsrOpts...)
@@ -527,6 +718,15 @@ func (e *Env) initChecker() error {
 				e.HasFeature(featureDisableDynamicAggregateLiterals)),
 			checker.CrossTypeNumericComparisons(
 				e.HasFeature(featureCrossTypeNumericComparisons)))
+		displayNames := map[string]string{}
+		for name, fn := range e.functions {
+			if fn.displayName != "" {
+				displayNames[name] = fn.displayName
+			}
+		}
+		if len(displayNames) != 0 {
+			chkOpts = append(chkOpts, checker.FunctionDisplayNames(displayNames))
+		}
 
 		ce, err := checker.NewEnv(e.Container, e.provider, chkOpts...)
 		if err != nil {
@@ -581,7 +781,8 @@ func (e *Env) maybeApplyFeature(feature int, option EnvOption) (*Env, error) {
 
 // Issues defines methods for inspecting the error details of parse and check calls.
 //
-// Note: in the future, non-fatal warnings and notices may be inspectable via the Issues struct.
+// Non-fatal warnings, such as those reported by the linters configured with the Linters option,
+// are not issues, and are instead inspectable via the Ast.Warnings method.
 type Issues struct {
 	errs *common.Errors
 }
@@ -15,12 +15,16 @@
 package cel
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
+	"github.com/google/cel-go/checker/decls"
 	"github.com/google/cel-go/common"
 	"github.com/google/cel-go/common/operators"
 	"github.com/google/cel-go/common/types"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 )
 
 func TestIssuesNil(t *testing.T) {
@@ -82,6 +86,60 @@ ERROR: <input>:1:2: Syntax error: mismatched input '<EOF>' expecting {'[', '{',
 	}
 }
 
+func TestMergeDeclarations(t *testing.T) {
+	base, err := NewEnv(
+		Variable("x", IntType),
+		Function("plugin.size",
+			Overload("plugin_size_string", []*Type{StringType}, IntType)),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	env, err := base.Extend(MergeDeclarations(
+		decls.NewVar("x", decls.Int),
+		decls.NewVar("y", decls.String),
+		decls.NewFunction("plugin.size",
+			decls.NewOverload("plugin_size_string", []*exprpb.Type{decls.String}, decls.Int),
+			decls.NewOverload("plugin_size_bytes", []*exprpb.Type{decls.Bytes}, decls.Int)),
+	))
+	if err != nil {
+		t.Fatalf("Extend(MergeDeclarations()) failed: %v", err)
+	}
+	_, iss := env.Compile(`plugin.size(y) + plugin.size(b'abc') + x`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+
+	_, err = base.Extend(MergeDeclarations(
+		decls.NewVar("x", decls.String),
+		decls.NewVar("y", decls.String),
+		decls.NewVar("y", decls.Bool),
+		decls.NewFunction("plugin.size",
+			decls.NewOverload("plugin_size_string", []*exprpb.Type{decls.Bytes}, decls.Int)),
+		decls.NewFunction("plugin.length",
+			decls.NewInstanceOverload("add_int64", []*exprpb.Type{decls.String}, decls.Int)),
+	))
+	var conflictErr *DeclarationConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("Extend(MergeDeclarations()) got error %v, wanted DeclarationConflictError", err)
+	}
+	wantErr := `4 declaration conflict(s):
+  variable x redeclared with type string, previously declared with type int
+  variable y redeclared with type bool, previously declared with type string
+  overload plugin_size_string redeclared as plugin.size(bytes) -> int, previously declared as plugin.size(string) -> int
+  overload add_int64 redeclared as string.plugin.length() -> int, previously declared as _+_(int, int) -> int`
+	if err.Error() != wantErr {
+		t.Errorf("Extend(MergeDeclarations()) got error %v, wanted %v", err, wantErr)
+	}
+	var names []string
+	for _, c := range conflictErr.Conflicts {
+		names = append(names, c.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"x", "y", "plugin.size", "plugin.length"}) {
+		t.Errorf("got conflicts for %v, wanted x, y, plugin.size, and plugin.length", names)
+	}
+}
+
 func BenchmarkNewCustomEnvLazy(b *testing.B) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
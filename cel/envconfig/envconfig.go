@@ -0,0 +1,65 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package envconfig parses the declarative environment configurations described by cel.EnvConfig.
+//
+// Configurations are written in YAML or JSON. Textproto configurations are not supported, since
+// the configuration schema is not defined by a protobuf message.
+package envconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/google/cel-go/cel"
+)
+
+// Parse parses an environment configuration written in YAML or JSON, rejecting unknown fields.
+func Parse(data []byte) (*cel.EnvConfig, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	config := &cel.EnvConfig{}
+	if err := dec.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid environment config: %w", err)
+	}
+	return config, nil
+}
+
+// NewEnv creates an environment from a configuration written in YAML or JSON. See cel.EnvConfig
+// for the configuration schema.
+func NewEnv(data []byte, opts ...cel.ConfigOption) (*cel.Env, error) {
+	config, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return config.NewEnv(opts...)
+}
+
+// NewEnvFromFile creates an environment from the configuration file at the given path.
+func NewEnvFromFile(path string, opts ...cel.ConfigOption) (*cel.Env, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	env, err := NewEnv(data, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return env, nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+
+	proto3pb "github.com/google/cel-go/test/proto3pb"
+)
+
+const testEnvConfig = `
+# Environment for the config tests.
+name: "test"
+container: google.expr.proto3.test
+libraries:
+  - name: cel.lib.optional
+features:
+  cross_type_numeric_comparisons: true
+limits:
+  parser_recursion_limit: 10
+  cost_limit: 100
+variables:
+  - name: request
+    type: map(string, dyn)
+    description: "The request being authorized."
+  - name: msg
+    type: google.expr.proto3.test.TestAllTypes
+  - name: scores
+    type: 'list(double)'
+functions:
+  - name: isAdmin
+    overloads:
+      - id: isAdmin_string
+        args: [string]
+        result: bool
+  - name: first
+    overloads:
+      - id: list_first
+        member: true
+        args: [list(T)]
+        result: optional_type(T)
+        type_params: [T]
+`
+
+func TestNewEnv(t *testing.T) {
+	env, err := NewEnv([]byte(testEnvConfig),
+		cel.ConfigEnvOptions(
+			cel.Types(&proto3pb.TestAllTypes{}),
+			cel.Function("isAdmin",
+				cel.Overload("isAdmin_string", []*cel.Type{cel.StringType}, cel.BoolType,
+					cel.UnaryBinding(func(user ref.Val) ref.Val {
+						return types.Bool(user == types.String("root"))
+					}))),
+			cel.Function("first",
+				cel.MemberOverload("list_first", []*cel.Type{cel.ListType(cel.TypeParamType("T"))}, cel.OptionalType(cel.TypeParamType("T")),
+					cel.UnaryBinding(func(l ref.Val) ref.Val {
+						return l.(traits.Lister).Get(types.IntZero)
+					})))))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	if env.Container.Name() != "google.expr.proto3.test" {
+		t.Errorf("env.Container = %q, wanted google.expr.proto3.test", env.Container.Name())
+	}
+	if !env.HasLibrary("cel.lib.optional") {
+		t.Error("env missing configured library")
+	}
+	if doc, found := env.Doc(cel.VariableDocKind, "request"); !found || doc.Description != "The request being authorized." {
+		t.Errorf("env.Documentation(request) = %v, wanted the configured description", doc)
+	}
+	tests := []struct {
+		expr string
+		out  ref.Val
+	}{
+		{expr: `isAdmin(request.user) && msg.single_int64 < 2.5`, out: types.True},
+		{expr: `scores.first().orValue(0.0) > 1u`, out: types.True},
+		{expr: `has(request.user)`, out: types.True},
+	}
+	for _, tst := range tests {
+		ast, iss := env.Compile(tst.expr)
+		if iss.Err() != nil {
+			t.Fatalf("env.Compile(%q) failed: %v", tst.expr, iss.Err())
+		}
+		prg, err := env.Program(ast)
+		if err != nil {
+			t.Fatalf("env.Program(%q) failed: %v", tst.expr, err)
+		}
+		out, _, err := prg.Eval(map[string]any{
+			"request": map[string]any{"user": "root"},
+			"msg":     &proto3pb.TestAllTypes{SingleInt64: 2},
+			"scores":  []float64{1.5},
+		})
+		if err != nil {
+			t.Fatalf("prg.Eval(%q) failed: %v", tst.expr, err)
+		}
+		if out.Equal(tst.out) != types.True {
+			t.Errorf("prg.Eval(%q) got %v, wanted %v", tst.expr, out, tst.out)
+		}
+	}
+
+	// The configured limits apply to parsing and evaluation.
+	if _, iss := env.Compile(strings.Repeat("[", 12) + strings.Repeat("]", 12)); iss.Err() == nil {
+		t.Error("env.Compile() succeeded beyond the parser recursion limit")
+	}
+	ast, iss := env.Compile(`scores.map(s, s * 2.0).size() > 0`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	_, _, err = prg.Eval(map[string]any{"scores": make([]float64, 100)})
+	if err == nil || !strings.Contains(err.Error(), "cost limit exceeded") {
+		t.Errorf("prg.Eval() got error %v, wanted cost limit exceeded", err)
+	}
+}
+
+func TestNewEnvFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "env.json")
+	config := `{
+		"stdlib": ["comparisons_only"],
+		"variables": [{"name": "x", "type": "int"}]
+	}`
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	env, err := NewEnvFromFile(path)
+	if err != nil {
+		t.Fatalf("NewEnvFromFile() failed: %v", err)
+	}
+	if _, iss := env.Compile(`x < 10`); iss.Err() != nil {
+		t.Errorf("env.Compile(x < 10) failed: %v", iss.Err())
+	}
+	if _, iss := env.Compile(`x + 1 < 10`); iss.Err() == nil {
+		t.Error("env.Compile(x + 1 < 10) succeeded, wanted the stdlib subset to exclude addition")
+	}
+}
+
+func TestNewEnvErrors(t *testing.T) {
+	tests := []struct {
+		config string
+		err    string
+	}{
+		{config: `variable: []`, err: "line 1: field variable not found"},
+		{config: "variables:\n  - name: x\n    type: list(int", err: `invalid type "list(int"`},
+		{config: "variables:\n  - name: x\n    type: map(int)", err: "map requires 2 type parameters"},
+		{config: "libraries:\n  - name: cel.lib.unknown", err: "unknown library: cel.lib.unknown"},
+		{config: "libraries:\n  - name: cel.lib.optional\n    version: 2", err: "unsupported version: 2"},
+		{config: "features:\n  unknown: true", err: "unknown feature: unknown"},
+		{config: "limits:\n  unknown: 1", err: "unknown limit: unknown"},
+		{config: "stdlib: [unknown]", err: "unknown stdlib profile: unknown"},
+		{config: "overflow_policy: clamp", err: "unknown overflow policy: clamp"},
+		{config: "functions:\n  - name: f", err: "function f: no overloads declared"},
+		{config: "name: a\nname: b", err: `line 2: mapping key "name" already defined`},
+		{config: "name: a\n  container: b", err: "line 2: mapping values are not allowed"},
+		{config: "- name", err: "cannot unmarshal !!seq"},
+		{config: `{"variables": [{"name": "x", "kind": "int"}]}`, err: "field kind not found"},
+	}
+	for _, tst := range tests {
+		_, err := NewEnv([]byte(tst.config))
+		if err == nil || !strings.Contains(err.Error(), tst.err) {
+			t.Errorf("NewEnv(%q) got error %v, wanted %q", tst.config, err, tst.err)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/google/cel-go/common/debug"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+
+	"google.golang.org/protobuf/proto"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Sampler generates an input for the differential evaluation of expressions, using the source of
+// randomness provided.
+type Sampler func(r *rand.Rand) map[string]any
+
+// equivalenceSamples is the number of inputs over which expressions are compared when they are
+// not structurally equivalent.
+const equivalenceSamples = 500
+
+// EquivalenceResult reports whether two expressions are likely to be equivalent.
+type EquivalenceResult struct {
+	// Equivalent indicates whether the expressions are structurally equivalent, or else produced
+	// the same result for every input sampled.
+	Equivalent bool
+
+	// Structural indicates whether equivalence was established from the canonical forms of the
+	// expressions, in which case no inputs were sampled.
+	Structural bool
+
+	// Samples is the number of inputs over which the expressions were evaluated.
+	Samples int
+
+	// Counterexample is the first input for which the expressions produced different results,
+	// and ResultA and ResultB are the respective results.
+	Counterexample map[string]any
+	ResultA        ref.Val
+	ResultB        ref.Val
+}
+
+// Equivalent reports whether the expressions are likely to be equivalent within the environment.
+//
+// The expressions are first compared structurally, ignoring expression ids and the order of the
+// operands of the commutative operators `&&`, `||`, `==`, and `!=`. Otherwise, the expressions are
+// evaluated over a fixed number of inputs produced by the sampler from a deterministic source of
+// randomness, and are considered equivalent when their results agree for every input. Results
+// agree when they are equal values of the same type, or when both are errors or both are unknown.
+//
+// Since differential evaluation only samples the input space, a positive result indicates likely
+// rather than proven equivalence. A nil sampler defaults to RandomSampler(env).
+func Equivalent(env *Env, a, b *Ast, sampler Sampler) (*EquivalenceResult, error) {
+	if debug.ToDebugString(canonicalExpr(a.Expr())) == debug.ToDebugString(canonicalExpr(b.Expr())) {
+		return &EquivalenceResult{Equivalent: true, Structural: true}, nil
+	}
+	prgA, err := env.Program(a)
+	if err != nil {
+		return nil, err
+	}
+	prgB, err := env.Program(b)
+	if err != nil {
+		return nil, err
+	}
+	if sampler == nil {
+		sampler = RandomSampler(env)
+	}
+	r := rand.New(rand.NewSource(1))
+	res := &EquivalenceResult{Equivalent: true}
+	for i := 0; i < equivalenceSamples; i++ {
+		input := sampler(r)
+		outA, _, _ := prgA.Eval(input)
+		outB, _, _ := prgB.Eval(input)
+		res.Samples++
+		if !sameResult(outA, outB) {
+			res.Equivalent = false
+			res.Counterexample = input
+			res.ResultA = outA
+			res.ResultB = outB
+			break
+		}
+	}
+	return res, nil
+}
+
+// sameResult returns whether two evaluation results agree.
+func sameResult(a, b ref.Val) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if types.IsError(a) || types.IsError(b) {
+		return types.IsError(a) && types.IsError(b)
+	}
+	if types.IsUnknown(a) || types.IsUnknown(b) {
+		return types.IsUnknown(a) && types.IsUnknown(b)
+	}
+	if a.Type().TypeName() != b.Type().TypeName() {
+		return false
+	}
+	if da, ok := a.(types.Double); ok && math.IsNaN(float64(da)) {
+		db := b.(types.Double)
+		return math.IsNaN(float64(db))
+	}
+	return a.Equal(b) == types.True
+}
+
+// canonicalExpr returns a copy of the expression in which the operands of chains of the same
+// commutative operator are sorted.
+func canonicalExpr(e *exprpb.Expr) *exprpb.Expr {
+	c := proto.Clone(e).(*exprpb.Expr)
+	canonicalize(c)
+	return c
+}
+
+func canonicalize(e *exprpb.Expr) {
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		canonicalize(e.GetSelectExpr().GetOperand())
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		if call.GetTarget() != nil {
+			canonicalize(call.GetTarget())
+		}
+		for _, arg := range call.GetArgs() {
+			canonicalize(arg)
+		}
+		switch call.GetFunction() {
+		case operators.LogicalAnd, operators.LogicalOr:
+			operands := flattenOperands(e, call.GetFunction(), nil)
+			sortExprs(operands)
+			// Rebuild the chain left-associatively from the sorted operands.
+			chain := operands[0]
+			for _, op := range operands[1:] {
+				chain = &exprpb.Expr{ExprKind: &exprpb.Expr_CallExpr{
+					CallExpr: &exprpb.Expr_Call{Function: call.GetFunction(), Args: []*exprpb.Expr{chain, op}}}}
+			}
+			e.ExprKind = chain.GetExprKind()
+		case operators.Equals, operators.NotEquals:
+			sortExprs(call.GetArgs())
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range e.GetListExpr().GetElements() {
+			canonicalize(elem)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			if entry.GetMapKey() != nil {
+				canonicalize(entry.GetMapKey())
+			}
+			canonicalize(entry.GetValue())
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		canonicalize(comp.GetIterRange())
+		canonicalize(comp.GetAccuInit())
+		canonicalize(comp.GetLoopCondition())
+		canonicalize(comp.GetLoopStep())
+		canonicalize(comp.GetResult())
+	}
+}
+
+// flattenOperands collects the operands of a chain of calls to the same binary operator.
+func flattenOperands(e *exprpb.Expr, function string, operands []*exprpb.Expr) []*exprpb.Expr {
+	if call := e.GetCallExpr(); call != nil && call.GetFunction() == function && len(call.GetArgs()) == 2 {
+		operands = flattenOperands(call.GetArgs()[0], function, operands)
+		return flattenOperands(call.GetArgs()[1], function, operands)
+	}
+	return append(operands, e)
+}
+
+func sortExprs(exprs []*exprpb.Expr) {
+	keys := make(map[*exprpb.Expr]string, len(exprs))
+	for _, e := range exprs {
+		keys[e] = debug.ToDebugString(e)
+	}
+	sort.SliceStable(exprs, func(i, j int) bool {
+		return keys[exprs[i]] < keys[exprs[j]]
+	})
+}
+
+// RandomSampler returns a Sampler which binds each variable declared within the environment to a
+// random value of its declared type, favoring small domains and boundary values so that distinct
+// variables frequently hold equal values.
+//
+// Variables whose types are messages or opaque types are left unbound.
+func RandomSampler(env *Env) Sampler {
+	vars := map[string]*Type{}
+	for _, d := range env.declarations {
+		if d.GetIdent() == nil {
+			continue
+		}
+		if t, err := ExprTypeToType(d.GetIdent().GetType()); err == nil {
+			vars[d.GetName()] = t
+		}
+	}
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return func(r *rand.Rand) map[string]any {
+		input := make(map[string]any, len(names))
+		for _, name := range names {
+			if val, ok := randomValue(r, vars[name], 0); ok {
+				input[name] = val
+			}
+		}
+		return input
+	}
+}
+
+var (
+	sampleInts    = []int64{math.MinInt64, -2, -1, 0, 1, 2, 3, 10, math.MaxInt64}
+	sampleUints   = []uint64{0, 1, 2, 3, 10, math.MaxUint64}
+	sampleDoubles = []float64{math.Inf(-1), -1.5, -1, 0, 0.5, 1, 2, math.Inf(1), math.NaN()}
+	sampleStrings = []string{"", "a", "b", "ab", "A", "a b", "é"}
+)
+
+// randomValue returns a random value of the type, bounding the nesting of lists and maps.
+func randomValue(r *rand.Rand, t *Type, depth int) (any, bool) {
+	switch t.kind {
+	case BoolKind:
+		return r.Intn(2) == 0, true
+	case IntKind:
+		return sampleInts[r.Intn(len(sampleInts))], true
+	case UintKind:
+		return sampleUints[r.Intn(len(sampleUints))], true
+	case DoubleKind:
+		return sampleDoubles[r.Intn(len(sampleDoubles))], true
+	case StringKind:
+		return sampleStrings[r.Intn(len(sampleStrings))], true
+	case BytesKind:
+		return []byte(sampleStrings[r.Intn(len(sampleStrings))]), true
+	case NullTypeKind:
+		return types.NullValue, true
+	case DurationKind:
+		return time.Duration(sampleInts[r.Intn(5)+1]) * time.Second, true
+	case TimestampKind:
+		return time.Unix(r.Int63n(4102444800), 0).UTC(), true
+	case ListKind:
+		if depth > 2 {
+			return []any{}, true
+		}
+		n := r.Intn(4)
+		list := make([]any, 0, n)
+		for i := 0; i < n; i++ {
+			if elem, ok := randomValue(r, t.parameters[0], depth+1); ok {
+				list = append(list, elem)
+			}
+		}
+		return list, true
+	case MapKind:
+		m := map[any]any{}
+		if depth > 2 {
+			return m, true
+		}
+		keyType := t.parameters[0]
+		if keyType.isDyn() {
+			keyType = StringType
+		}
+		n := r.Intn(4)
+		for i := 0; i < n; i++ {
+			k, okK := randomValue(r, keyType, depth+1)
+			v, okV := randomValue(r, t.parameters[1], depth+1)
+			if !okK || !okV {
+				continue
+			}
+			if _, isBytes := k.([]byte); isBytes {
+				continue
+			}
+			m[k] = v
+		}
+		return m, true
+	case DynKind, AnyKind, TypeParamKind:
+		kinds := []*Type{BoolType, IntType, UintType, DoubleType, StringType, NullType}
+		return randomValue(r, kinds[r.Intn(len(kinds))], depth)
+	}
+	return nil, false
+}
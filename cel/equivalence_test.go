@@ -0,0 +1,65 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"testing"
+)
+
+func TestEquivalent(t *testing.T) {
+	env, err := NewEnv(
+		Variable("a", IntType),
+		Variable("b", IntType),
+		Variable("s", StringType),
+		Variable("tags", ListType(StringType)),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		a, b       string
+		equivalent bool
+		structural bool
+	}{
+		{a: `a == 1 && s != '' || b > 2`, b: `b > 2 || '' != s && 1 == a`, equivalent: true, structural: true},
+		{a: `a > b`, b: `b < a`, equivalent: true},
+		{a: `!(a > 1 && b > 1)`, b: `a <= 1 || b <= 1`, equivalent: true},
+		{a: `tags.exists(t, t == s)`, b: `s in tags`, equivalent: true},
+		{a: `a > b`, b: `a >= b`},
+		{a: `size(s) > 0`, b: `s.startsWith('a')`},
+	}
+	for _, tc := range tests {
+		astA, iss := env.Compile(tc.a)
+		if iss.Err() != nil {
+			t.Fatalf("env.Compile(%q) failed: %v", tc.a, iss.Err())
+		}
+		astB, iss := env.Compile(tc.b)
+		if iss.Err() != nil {
+			t.Fatalf("env.Compile(%q) failed: %v", tc.b, iss.Err())
+		}
+		res, err := Equivalent(env, astA, astB, nil)
+		if err != nil {
+			t.Fatalf("Equivalent(%q, %q) failed: %v", tc.a, tc.b, err)
+		}
+		if res.Equivalent != tc.equivalent || res.Structural != tc.structural {
+			t.Errorf("Equivalent(%q, %q) got equivalent=%t, structural=%t, wanted %t, %t",
+				tc.a, tc.b, res.Equivalent, res.Structural, tc.equivalent, tc.structural)
+		}
+		if !res.Equivalent && (res.Counterexample == nil || sameResult(res.ResultA, res.ResultB)) {
+			t.Errorf("Equivalent(%q, %q) got counterexample %v with results %v, %v",
+				tc.a, tc.b, res.Counterexample, res.ResultA, res.ResultB)
+		}
+	}
+}
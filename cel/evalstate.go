@@ -0,0 +1,207 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+	"github.com/google/cel-go/parser"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	statuspb "google.golang.org/genproto/googleapis/rpc/status"
+)
+
+// EvalStateToProto converts the evaluation state of a program planned from the Ast into the
+// EvalState proto message.
+//
+// The results are ordered by expression id and refer to a deduplicated list of values. Errors are
+// reported as an ErrorSet with a single status, and unknowns as an UnknownSet. Values which have
+// no proto representation, such as optional values, are omitted.
+func EvalStateToProto(ast *Ast, state interpreter.EvalState) (*exprpb.EvalState, error) {
+	pb := &exprpb.EvalState{}
+	values := &protoValueIndex{}
+	for _, id := range evalStateIDs(ast, state) {
+		val, _ := state.Value(id)
+		ev, found := refValueToExprValue(val)
+		if !found {
+			continue
+		}
+		idx, err := values.index(ev)
+		if err != nil {
+			return nil, err
+		}
+		pb.Results = append(pb.Results, &exprpb.EvalState_Result{Expr: id, Value: int64(idx)})
+	}
+	for _, v := range values.values {
+		pb.Values = append(pb.Values, v.(*exprpb.ExprValue))
+	}
+	return pb, nil
+}
+
+// EvalStateToExplain converts the evaluation state of a program planned from the Ast into the
+// Explain proto message understood by existing analysis tooling.
+//
+// The Explain message can only record values, so the steps which evaluated to errors or unknowns
+// are omitted along with values which have no proto representation.
+func EvalStateToExplain(ast *Ast, state interpreter.EvalState) (*exprpb.Explain, error) {
+	pb := &exprpb.Explain{}
+	values := &protoValueIndex{}
+	for _, id := range evalStateIDs(ast, state) {
+		val, _ := state.Value(id)
+		if types.IsUnknownOrError(val) {
+			continue
+		}
+		v, err := RefValueToValue(val)
+		if err != nil {
+			continue
+		}
+		idx, err := values.index(v)
+		if err != nil {
+			return nil, err
+		}
+		pb.ExprSteps = append(pb.ExprSteps, &exprpb.Explain_ExprStep{Id: id, ValueIndex: int32(idx)})
+	}
+	for _, v := range values.values {
+		pb.Values = append(pb.Values, v.(*exprpb.Value))
+	}
+	return pb, nil
+}
+
+// EvalStateToJSON converts the evaluation state of a program planned from the Ast into JSON.
+//
+// The JSON form extends the EvalState proto message with the source text and location of each
+// evaluated subexpression, so that a trace can be analyzed without the Ast:
+//
+//	{
+//	  "expression": "a < b",
+//	  "steps": [
+//	    {"id": "1", "expr": "a", "location": "<input>:1:1", "value": {"value": {"int64Value": "1"}}},
+//	    ...
+//	  ]
+//	}
+//
+// The values are encoded as ExprValue messages in the proto3 JSON format.
+func EvalStateToJSON(ast *Ast, state interpreter.EvalState) ([]byte, error) {
+	exprs := map[int64]*exprpb.Expr{}
+	visitExpr(ast.Expr(), func(e *exprpb.Expr) {
+		exprs[e.GetId()] = e
+	})
+	trace := &evalTraceJSON{Steps: []*evalStepJSON{}}
+	if ast.Source() != nil {
+		trace.Expression = ast.Source().Content()
+	}
+	for _, id := range evalStateIDs(ast, state) {
+		val, _ := state.Value(id)
+		ev, found := refValueToExprValue(val)
+		if !found {
+			continue
+		}
+		value, err := protojson.Marshal(ev)
+		if err != nil {
+			return nil, err
+		}
+		step := &evalStepJSON{ID: id, Value: value}
+		if src, err := parser.Unparse(exprs[id], ast.SourceInfo()); err == nil {
+			step.Expr = src
+		}
+		if loc := exprLocation(ast, id); loc.Line() > 0 {
+			step.Location = fmt.Sprintf("%s:%d:%d", ast.Source().Description(), loc.Line(), loc.Column()+1)
+		}
+		trace.Steps = append(trace.Steps, step)
+	}
+	return json.Marshal(trace)
+}
+
+type evalTraceJSON struct {
+	Expression string          `json:"expression,omitempty"`
+	Steps      []*evalStepJSON `json:"steps"`
+}
+
+type evalStepJSON struct {
+	ID       int64           `json:"id,string"`
+	Expr     string          `json:"expr,omitempty"`
+	Location string          `json:"location,omitempty"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// evalStateIDs returns the sorted ids of the expressions within the Ast which have a value in the
+// evaluation state.
+func evalStateIDs(ast *Ast, state interpreter.EvalState) []int64 {
+	var ids []int64
+	visitExpr(ast.Expr(), func(e *exprpb.Expr) {
+		if _, found := state.Value(e.GetId()); found {
+			ids = append(ids, e.GetId())
+		}
+	})
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// refValueToExprValue converts a value, error, or unknown into an ExprValue, returning false if
+// the value has no proto representation.
+func refValueToExprValue(val ref.Val) (*exprpb.ExprValue, bool) {
+	switch v := val.(type) {
+	case *types.Err:
+		return &exprpb.ExprValue{
+			Kind: &exprpb.ExprValue_Error{
+				Error: &exprpb.ErrorSet{
+					Errors: []*statuspb.Status{{Message: v.String()}},
+				},
+			},
+		}, true
+	case types.Unknown:
+		return &exprpb.ExprValue{
+			Kind: &exprpb.ExprValue_Unknown{
+				Unknown: &exprpb.UnknownSet{Exprs: v},
+			},
+		}, true
+	}
+	pb, err := RefValueToValue(val)
+	if err != nil {
+		return nil, false
+	}
+	return &exprpb.ExprValue{Kind: &exprpb.ExprValue_Value{Value: pb}}, true
+}
+
+// protoValueIndex deduplicates the values referenced by the results of an evaluation.
+type protoValueIndex struct {
+	values  []proto.Message
+	indices map[string]int
+}
+
+// index returns the position of the value within the deduplicated list, appending it if needed.
+func (idx *protoValueIndex) index(v proto.Message) (int, error) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(v)
+	if err != nil {
+		return 0, err
+	}
+	if i, found := idx.indices[string(b)]; found {
+		return i, nil
+	}
+	if idx.indices == nil {
+		idx.indices = map[string]int{}
+	}
+	idx.indices[string(b)] = len(idx.values)
+	idx.values = append(idx.values, v)
+	return len(idx.values) - 1, nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"encoding/json"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestEvalStateToProto(t *testing.T) {
+	env, err := NewEnv(Variable("a", IntType), Variable("b", IntType))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile("a < b || 1 / (a - a) == 1")
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast, EvalOptions(OptExhaustiveEval))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	_, det, err := prg.Eval(map[string]any{"a": 1, "b": 2})
+	if err != nil {
+		t.Fatalf("prg.Eval() failed: %v", err)
+	}
+
+	state, err := EvalStateToProto(ast, det.State())
+	if err != nil {
+		t.Fatalf("EvalStateToProto() failed: %v", err)
+	}
+	var lastID int64
+	results := map[int64]*exprpb.ExprValue{}
+	for _, r := range state.GetResults() {
+		if r.GetExpr() <= lastID {
+			t.Errorf("EvalStateToProto() results are not ordered by id: %v", state.GetResults())
+		}
+		lastID = r.GetExpr()
+		results[r.GetExpr()] = state.GetValues()[r.GetValue()]
+	}
+	if len(state.GetValues()) >= len(state.GetResults()) {
+		t.Errorf("EvalStateToProto() got %d values for %d results, wanted deduplicated values",
+			len(state.GetValues()), len(state.GetResults()))
+	}
+	root := ast.Expr()
+	if !results[root.GetId()].GetValue().GetBoolValue() {
+		t.Errorf("EvalStateToProto() got root value %v, wanted true", results[root.GetId()])
+	}
+	div := root.GetCallExpr().GetArgs()[1].GetCallExpr().GetArgs()[0]
+	if errs := results[div.GetId()].GetError().GetErrors(); len(errs) != 1 || errs[0].GetMessage() != "division by zero" {
+		t.Errorf("EvalStateToProto() got division value %v, wanted a division by zero error", results[div.GetId()])
+	}
+
+	explain, err := EvalStateToExplain(ast, det.State())
+	if err != nil {
+		t.Fatalf("EvalStateToExplain() failed: %v", err)
+	}
+	if len(explain.GetExprSteps()) != len(state.GetResults())-2 {
+		t.Errorf("EvalStateToExplain() got steps %v, wanted the non-error results of %v",
+			explain.GetExprSteps(), state.GetResults())
+	}
+	for _, step := range explain.GetExprSteps() {
+		if step.GetId() == div.GetId() {
+			t.Errorf("EvalStateToExplain() included the error step: %v", step)
+		}
+	}
+
+	out, err := EvalStateToJSON(ast, det.State())
+	if err != nil {
+		t.Fatalf("EvalStateToJSON() failed: %v", err)
+	}
+	var trace struct {
+		Expression string `json:"expression"`
+		Steps      []struct {
+			ID       int64           `json:"id,string"`
+			Expr     string          `json:"expr"`
+			Location string          `json:"location"`
+			Value    json.RawMessage `json:"value"`
+		} `json:"steps"`
+	}
+	if err := json.Unmarshal(out, &trace); err != nil {
+		t.Fatalf("json.Unmarshal(%s) failed: %v", out, err)
+	}
+	if trace.Expression != "a < b || 1 / (a - a) == 1" || len(trace.Steps) != len(state.GetResults()) {
+		t.Fatalf("EvalStateToJSON() got %s, wanted the expression and a step per result", out)
+	}
+	for _, step := range trace.Steps {
+		if step.ID != root.GetCallExpr().GetArgs()[0].GetId() {
+			continue
+		}
+		var ev exprpb.ExprValue
+		if err := protojson.Unmarshal(step.Value, &ev); err != nil {
+			t.Fatalf("protojson.Unmarshal(%s) failed: %v", step.Value, err)
+		}
+		if step.Expr != "a < b" || step.Location != "<input>:1:3" || !ev.GetValue().GetBoolValue() {
+			t.Errorf("EvalStateToJSON() got step %+v, wanted 'a < b' at <input>:1:3 with value true", step)
+		}
+	}
+}
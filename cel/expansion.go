@@ -0,0 +1,237 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/common/debug"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// MacroExpansion describes a macro invocation within an expression and the expression it expanded
+// into.
+type MacroExpansion struct {
+	// ID is the expression id of the macro invocation, which is shared by its expansion.
+	ID int64
+
+	// Macro is the name of the macro, e.g. `exists_one`.
+	Macro string
+
+	// Start and End are the code point offsets of the first character of the invocation and of
+	// the character following it within the expression source.
+	Start, End int32
+
+	// Call is the source text of the invocation.
+	Call string
+
+	// Expansion is the expression the macro expanded into.
+	Expansion *exprpb.Expr
+}
+
+// String renders the invocation along with a debug rendering of its expansion, in which
+// comprehensions are written as `__comprehension__` calls.
+func (m *MacroExpansion) String() string {
+	return fmt.Sprintf("%s [%d:%d] %s\n%s", m.Macro, m.Start, m.End, m.Call, debug.ToDebugString(m.Expansion))
+}
+
+// MacroExpansions returns the macro invocations within a parsed or checked Ast ordered by their
+// position within the source, with enclosing invocations first. Invocations nested within the
+// arguments of other macros are included.
+//
+// When the Ast was parsed without macro call tracking, its source is parsed again with tracking
+// enabled in order to recover the invocations.
+func (e *Env) MacroExpansions(ast *Ast) ([]*MacroExpansion, error) {
+	info, err := e.macroTrackedSourceInfo(ast)
+	if err != nil {
+		return nil, err
+	}
+	if len(info.GetMacroCalls()) == 0 {
+		return []*MacroExpansion{}, nil
+	}
+	expanded := map[int64]*exprpb.Expr{}
+	collectMacroExpansions(ast.Expr(), info.GetMacroCalls(), expanded)
+	src := []rune(ast.Source().Content())
+	var expansions []*MacroExpansion
+	for id, call := range info.GetMacroCalls() {
+		exp, found := expanded[id]
+		if !found {
+			continue
+		}
+		start := macroCallStart(id, call, info)
+		end := macroCallEnd(src, info.GetPositions()[id])
+		text := ""
+		if start >= 0 && int(end) <= len(src) && start <= end {
+			text = string(src[start:end])
+		}
+		expansions = append(expansions, &MacroExpansion{
+			ID:        id,
+			Macro:     call.GetCallExpr().GetFunction(),
+			Start:     start,
+			End:       end,
+			Call:      text,
+			Expansion: exp,
+		})
+	}
+	sort.Slice(expansions, func(i, j int) bool {
+		if expansions[i].Start != expansions[j].Start {
+			return expansions[i].Start < expansions[j].Start
+		}
+		return expansions[i].End > expansions[j].End
+	})
+	return expansions, nil
+}
+
+// macroTrackedSourceInfo returns the source info of the Ast, parsing its source again with macro
+// call tracking enabled if the Ast contains no record of its macro calls.
+func (e *Env) macroTrackedSourceInfo(ast *Ast) (*exprpb.SourceInfo, error) {
+	info := ast.SourceInfo()
+	if len(info.GetMacroCalls()) != 0 || ast.Source() == nil {
+		return info, nil
+	}
+	tracking, err := e.Extend(EnableMacroCallTracking())
+	if err != nil {
+		return nil, err
+	}
+	parsed, iss := tracking.ParseSource(ast.Source())
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	return parsed.SourceInfo(), nil
+}
+
+// collectMacroExpansions records the subexpressions whose ids identify macro invocations.
+func collectMacroExpansions(e *exprpb.Expr, calls map[int64]*exprpb.Expr, expanded map[int64]*exprpb.Expr) {
+	if e == nil {
+		return
+	}
+	if _, found := calls[e.GetId()]; found {
+		expanded[e.GetId()] = e
+	}
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		collectMacroExpansions(e.GetSelectExpr().GetOperand(), calls, expanded)
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		collectMacroExpansions(call.GetTarget(), calls, expanded)
+		for _, arg := range call.GetArgs() {
+			collectMacroExpansions(arg, calls, expanded)
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range e.GetListExpr().GetElements() {
+			collectMacroExpansions(elem, calls, expanded)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			collectMacroExpansions(entry.GetMapKey(), calls, expanded)
+			collectMacroExpansions(entry.GetValue(), calls, expanded)
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		collectMacroExpansions(comp.GetIterRange(), calls, expanded)
+		collectMacroExpansions(comp.GetAccuInit(), calls, expanded)
+		collectMacroExpansions(comp.GetLoopCondition(), calls, expanded)
+		collectMacroExpansions(comp.GetLoopStep(), calls, expanded)
+		collectMacroExpansions(comp.GetResult(), calls, expanded)
+	}
+}
+
+// macroCallStart returns the smallest source offset among the nodes of the macro invocation,
+// following the invocations of the macros nested within its target and arguments.
+func macroCallStart(id int64, call *exprpb.Expr, info *exprpb.SourceInfo) int32 {
+	start, found := info.GetPositions()[id]
+	if !found {
+		start = -1
+	} else if c := call.GetCallExpr(); c.GetTarget() == nil {
+		// The position of a global call is that of its opening parenthesis.
+		start -= int32(len([]rune(c.GetFunction())))
+	}
+	var visit func(e *exprpb.Expr)
+	visit = func(e *exprpb.Expr) {
+		if e == nil {
+			return
+		}
+		if pos, found := info.GetPositions()[e.GetId()]; found && (start < 0 || pos < start) {
+			start = pos
+		}
+		if nested, found := info.GetMacroCalls()[e.GetId()]; found && e.GetExprKind() == nil {
+			visit(nested)
+			return
+		}
+		switch e.GetExprKind().(type) {
+		case *exprpb.Expr_SelectExpr:
+			visit(e.GetSelectExpr().GetOperand())
+		case *exprpb.Expr_CallExpr:
+			c := e.GetCallExpr()
+			visit(c.GetTarget())
+			for _, arg := range c.GetArgs() {
+				visit(arg)
+			}
+		case *exprpb.Expr_ListExpr:
+			for _, elem := range e.GetListExpr().GetElements() {
+				visit(elem)
+			}
+		case *exprpb.Expr_StructExpr:
+			for _, entry := range e.GetStructExpr().GetEntries() {
+				visit(entry.GetMapKey())
+				visit(entry.GetValue())
+			}
+		}
+	}
+	visit(call)
+	return start
+}
+
+// macroCallEnd returns the offset following the parenthesis which closes the argument list opened
+// at the given offset, skipping over the contents of string and bytes literals.
+func macroCallEnd(src []rune, open int32) int32 {
+	depth := 0
+	for i := int(open); i < len(src); i++ {
+		switch src[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return int32(i + 1)
+			}
+		case '"', '\'':
+			i = skipQuoted(src, i)
+		}
+	}
+	return int32(len(src))
+}
+
+// skipQuoted returns the offset of the closing quote of the literal opened at offset i.
+func skipQuoted(src []rune, i int) int {
+	quote := string(src[i])
+	if i+2 < len(src) && src[i+1] == src[i] && src[i+2] == src[i] {
+		quote = strings.Repeat(quote, 3)
+	}
+	raw := i > 0 && (src[i-1] == 'r' || src[i-1] == 'R')
+	for j := i + len(quote); j < len(src); j++ {
+		if src[j] == '\\' && !raw {
+			j++
+			continue
+		}
+		if strings.HasPrefix(string(src[j:minInt(j+len(quote), len(src))]), quote) {
+			return j + len(quote) - 1
+		}
+	}
+	return len(src)
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMacroExpansions(t *testing.T) {
+	env, err := NewEnv(Variable("items", ListType(StringType)))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`items.filter(i, i != ')').exists_one(i, has({'a': i}.a))`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	expansions, err := env.MacroExpansions(ast)
+	if err != nil {
+		t.Fatalf("env.MacroExpansions() failed: %v", err)
+	}
+	var got []string
+	for _, exp := range expansions {
+		got = append(got, fmt.Sprintf("%s [%d:%d] %s", exp.Macro, exp.Start, exp.End, exp.Call))
+	}
+	want := []string{
+		"exists_one [0:56] items.filter(i, i != ')').exists_one(i, has({'a': i}.a))",
+		"filter [0:25] items.filter(i, i != ')')",
+		"has [40:55] has({'a': i}.a)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("env.MacroExpansions() got %v, wanted %v", got, want)
+	}
+	if !strings.Contains(expansions[0].String(), "__comprehension__(") {
+		t.Errorf("expansion got %s, wanted a comprehension", expansions[0])
+	}
+}
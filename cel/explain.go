@@ -0,0 +1,288 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+	"github.com/google/cel-go/parser"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Explanation describes why an evaluation produced its result as a tree of subexpressions.
+type Explanation struct {
+	// Result is the result of the evaluation, which may be an error or unknown value.
+	Result ref.Val
+
+	// Root is the explanation of the top-level expression.
+	Root *ExplanationNode
+}
+
+// ExplanationNode describes the evaluation of a single subexpression.
+type ExplanationNode struct {
+	// ID is the expression id of the subexpression.
+	ID int64
+
+	// Expr is the source text of the subexpression.
+	Expr string
+
+	// Value is the value the subexpression evaluated to, or nil when it was not evaluated.
+	Value ref.Val
+
+	// Determining indicates whether the value of the subexpression determined the result of the
+	// evaluation. For example, in `a && b` where `a` is false and `b` is true, only `a` determines
+	// the result.
+	Determining bool
+
+	// Children are the explanations of the operands of the subexpression.
+	//
+	// Only the range of a comprehension is explained, since the values of the comprehension's loop
+	// variables differ between iterations.
+	Children []*ExplanationNode
+
+	// function is the name of the logical or conditional operator of the subexpression, if any.
+	function string
+	// logical indicates whether the subexpression is a logical operator.
+	logical bool
+}
+
+// String renders the subexpression and its value, e.g. `request.user.role == 'admin' -> false`.
+func (n *ExplanationNode) String() string {
+	if n.Value == nil {
+		return n.Expr + " -> <not evaluated>"
+	}
+	return fmt.Sprintf("%s -> %s", n.Expr, formatExplainedValue(n.Value))
+}
+
+// Causes returns the determining subexpressions which are not logical operators, in source order.
+//
+// The causes are the conditions which explain the outcome to an end user: for the expression
+// `request.user.role == 'admin' || request.user.id == resource.owner` evaluating to false, the
+// causes are both comparisons, and rendering them yields a message such as "access denied because
+// request.user.role == 'admin' -> false".
+func (ex *Explanation) Causes() []*ExplanationNode {
+	var causes []*ExplanationNode
+	var visit func(n *ExplanationNode)
+	visit = func(n *ExplanationNode) {
+		if !n.Determining {
+			return
+		}
+		if !n.logical {
+			causes = append(causes, n)
+			return
+		}
+		for _, child := range n.Children {
+			visit(child)
+		}
+	}
+	visit(ex.Root)
+	return causes
+}
+
+// String renders the explanation tree with one subexpression per line, where determining
+// subexpressions are marked with an asterisk.
+func (ex *Explanation) String() string {
+	var sb strings.Builder
+	var visit func(n *ExplanationNode, depth int)
+	visit = func(n *ExplanationNode, depth int) {
+		sb.WriteString(strings.Repeat("  ", depth))
+		if n.Determining {
+			sb.WriteString("* ")
+		} else {
+			sb.WriteString("  ")
+		}
+		sb.WriteString(n.String())
+		sb.WriteString("\n")
+		for _, child := range n.Children {
+			visit(child, depth+1)
+		}
+	}
+	visit(ex.Root, 0)
+	return sb.String()
+}
+
+// Explain evaluates the program against the input vars and explains the result.
+//
+// The program must be created with the OptTrackState or OptExhaustiveEval evaluation option so that
+// the values of subexpressions are observed. With OptExhaustiveEval, the operands of logical
+// operators which did not determine the result are also evaluated and reported.
+//
+// An evaluation error is not returned as an error, but is the Result of the explanation. The error
+// is only non-nil when the program cannot be explained.
+func Explain(prg Program, vars any) (*Explanation, error) {
+	ast := programAst(prg)
+	if ast == nil {
+		return nil, errors.New("program does not support explanations")
+	}
+	out, det, err := prg.Eval(vars)
+	if det == nil || det.State() == nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.New("explanations require the OptTrackState or OptExhaustiveEval option")
+	}
+	if out == nil {
+		if err == nil {
+			return nil, errors.New("evaluation produced no result")
+		}
+		out = types.NewErr(err.Error())
+	}
+	ex := &explainer{
+		state: det.State(),
+		info:  ast.SourceInfo(),
+	}
+	root := ex.explain(ast.Expr())
+	root.markDetermining()
+	return &Explanation{Result: out, Root: root}, nil
+}
+
+// programAst returns the Ast from which the program was planned, or nil if it is not known.
+func programAst(prg Program) *Ast {
+	switch p := prg.(type) {
+	case *prog:
+		return p.ast
+	case *progGen:
+		return p.ast
+	case *boundProgram:
+		return programAst(p.base)
+	case *IncrementalProgram:
+		return programAst(p.Program)
+	case *asyncProgram:
+		return programAst(p.Program)
+	case *preparedProgram:
+		return programAst(p.Program)
+	}
+	return nil
+}
+
+type explainer struct {
+	state interpreter.EvalState
+	info  *exprpb.SourceInfo
+}
+
+func (ex *explainer) explain(e *exprpb.Expr) *ExplanationNode {
+	n := &ExplanationNode{ID: e.GetId()}
+	if src, err := parser.Unparse(e, ex.info); err == nil {
+		n.Expr = src
+	} else {
+		n.Expr = fmt.Sprintf("<expr %d>", e.GetId())
+	}
+	if val, found := ex.state.Value(e.GetId()); found {
+		n.Value = val
+	}
+	var children []*exprpb.Expr
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		// Attributes such as `request.user.role` are resolved as a whole, so the operands of the
+		// field selections within them are not explained separately.
+		operand := e.GetSelectExpr().GetOperand()
+		switch operand.GetExprKind().(type) {
+		case *exprpb.Expr_IdentExpr, *exprpb.Expr_SelectExpr:
+		default:
+			children = append(children, operand)
+		}
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		switch call.GetFunction() {
+		case operators.LogicalAnd, operators.LogicalOr, operators.LogicalNot:
+			n.logical = true
+			n.function = call.GetFunction()
+		case operators.Conditional:
+			n.function = call.GetFunction()
+		}
+		if call.GetTarget() != nil {
+			children = append(children, call.GetTarget())
+		}
+		children = append(children, call.GetArgs()...)
+	case *exprpb.Expr_ListExpr:
+		children = append(children, e.GetListExpr().GetElements()...)
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			if entry.GetMapKey() != nil {
+				children = append(children, entry.GetMapKey())
+			}
+			children = append(children, entry.GetValue())
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		children = append(children, e.GetComprehensionExpr().GetIterRange())
+	}
+	for _, child := range children {
+		n.Children = append(n.Children, ex.explain(child))
+	}
+	return n
+}
+
+// markDetermining marks the node as determining along with the operands which determined its
+// value.
+func (n *ExplanationNode) markDetermining() {
+	n.Determining = true
+	switch n.function {
+	case operators.LogicalAnd, operators.LogicalOr:
+		// The operands whose value equals the result of the operator determine it, e.g. any false
+		// operand of a false conjunction, or any error operand of an erroneous conjunction.
+		for _, child := range n.Children {
+			if child.Value != nil && sameOutcome(child.Value, n.Value) {
+				child.markDetermining()
+			}
+		}
+		return
+	case operators.Conditional:
+		cond := n.Children[0]
+		cond.markDetermining()
+		switch cond.Value {
+		case types.True:
+			n.Children[1].markDetermining()
+		case types.False:
+			n.Children[2].markDetermining()
+		}
+		return
+	}
+	for _, child := range n.Children {
+		child.markDetermining()
+	}
+}
+
+// sameOutcome returns whether two values are both errors, both unknowns, or equal booleans.
+func sameOutcome(val, result ref.Val) bool {
+	if result == nil {
+		return false
+	}
+	switch {
+	case types.IsError(result):
+		return types.IsError(val)
+	case types.IsUnknown(result):
+		return types.IsUnknown(val)
+	}
+	return val == result
+}
+
+func formatExplainedValue(val ref.Val) string {
+	switch v := val.(type) {
+	case types.String:
+		return fmt.Sprintf("%q", string(v))
+	case *types.Err:
+		return "error: " + v.Error()
+	case types.Unknown:
+		return "unknown"
+	}
+	return fmt.Sprintf("%v", val.Value())
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+)
+
+func TestExplain(t *testing.T) {
+	env, err := NewEnv(
+		Variable("request", MapType(StringType, DynType)),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`request.active && (request.role == 'admin' || request.user == request.owner)`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	vars := map[string]any{
+		"request": map[string]any{"active": true, "role": "viewer", "user": "bob", "owner": "alice"},
+	}
+
+	prg, err := env.Program(ast, EvalOptions(OptTrackState))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	ex, err := Explain(prg, vars)
+	if err != nil {
+		t.Fatalf("Explain() failed: %v", err)
+	}
+	if ex.Result != types.False {
+		t.Errorf("Explain() got result %v, wanted false", ex.Result)
+	}
+	var causes []string
+	for _, c := range ex.Causes() {
+		causes = append(causes, c.String())
+	}
+	wantCauses := []string{
+		`request.role == "admin" -> false`,
+		`request.user == request.owner -> false`,
+	}
+	if !reflect.DeepEqual(causes, wantCauses) {
+		t.Errorf("Causes() got %v, wanted %v", causes, wantCauses)
+	}
+	if ex.Root.Children[0].Determining {
+		t.Errorf("Explain() marked %v as determining", ex.Root.Children[0])
+	}
+
+	// A short-circuited operand is reported as not evaluated.
+	vars["request"].(map[string]any)["active"] = false
+	ex, err = Explain(prg, vars)
+	if err != nil {
+		t.Fatalf("Explain() failed: %v", err)
+	}
+	want := `* request.active && (request.role == "admin" || request.user == request.owner) -> false
+  * request.active -> false
+    request.role == "admin" || request.user == request.owner -> <not evaluated>
+`
+	if !strings.HasPrefix(ex.String(), want) {
+		t.Errorf("Explain() got:\n%s\nwanted prefix:\n%s", ex.String(), want)
+	}
+
+	// Explanations require state tracking.
+	prg, err = env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	if _, err := Explain(prg, vars); err == nil {
+		t.Error("Explain() succeeded without state tracking")
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// envFingerprint identifies the configuration with which an environment compiles and evaluates
+// expressions, such that environments with the same fingerprint produce equivalent programs.
+type envFingerprint [sha256.Size]byte
+
+// opaqueGenerations numbers the environments whose options configure behavior through functions,
+// such as bindings, macros, and parser options, which cannot be compared between environments.
+var opaqueGenerations uint64
+
+// opaqueConfig records the parts of an environment's configuration which are held as functions,
+// so that the options which change them may be detected.
+type opaqueConfig struct {
+	prsrOpts        int
+	chkOpts         int
+	progOpts        int
+	linters         int
+	macros          int
+	unitConversions int
+	functions       map[string]*functionDecl
+	adapter         ref.TypeAdapter
+	provider        ref.TypeProvider
+}
+
+// opaqueConfig returns the current state of the opaque parts of the configuration.
+func (e *Env) opaqueConfig() *opaqueConfig {
+	funcs := make(map[string]*functionDecl, len(e.functions))
+	for name, fn := range e.functions {
+		funcs[name] = fn
+	}
+	return &opaqueConfig{
+		prsrOpts:        len(e.prsrOpts),
+		chkOpts:         len(e.chkOpts),
+		progOpts:        len(e.progOpts),
+		linters:         len(e.linters),
+		macros:          len(e.macros),
+		unitConversions: len(e.unitConversions),
+		functions:       funcs,
+		adapter:         e.adapter,
+		provider:        e.provider,
+	}
+}
+
+// changed returns whether the opaque parts of the environment's configuration differ from the
+// recorded state. Type registries are copied for every environment, and are compared by the
+// types they contain rather than by identity.
+func (c *opaqueConfig) changed(e *Env) bool {
+	if c.prsrOpts != len(e.prsrOpts) || c.chkOpts != len(e.chkOpts) || c.progOpts != len(e.progOpts) ||
+		c.linters != len(e.linters) || c.macros != len(e.macros) ||
+		c.unitConversions != len(e.unitConversions) || len(c.functions) != len(e.functions) {
+		return true
+	}
+	for name, fn := range e.functions {
+		if c.functions[name] != fn {
+			return true
+		}
+	}
+	if _, isReg := e.adapter.(ref.TypeRegistry); !isReg && e.adapter != c.adapter {
+		return true
+	}
+	if _, isReg := e.provider.(ref.TypeRegistry); !isReg && e.provider != c.provider {
+		return true
+	}
+	return false
+}
+
+// fingerprint returns the fingerprint of the environment, which is computed from the fingerprint
+// of the environment it extends and the declarative configuration of the environment, such as its
+// container, declarations, features, types, and integer overflow policy.
+//
+// Options which configure the environment through functions are not comparable, so environments
+// configured by such options are distinguished by a generation number instead. Environments only
+// share a fingerprint when they are configured by the same declarative options on top of the same
+// opaque configuration.
+func (e *Env) fingerprint() envFingerprint {
+	e.fpOnce.Do(func() {
+		h := sha256.New()
+		h.Write(e.fpParent[:])
+		fmt.Fprintf(h, "gen:%d;", e.opaqueGen)
+		fmt.Fprintf(h, "container:%+v;", e.Container)
+		opts := proto.MarshalOptions{Deterministic: true}
+		for _, d := range e.declarations[e.fpDecls:] {
+			bytes, err := opts.Marshal(d)
+			if err != nil {
+				// Unreachable for well-formed declarations, but never share an environment whose
+				// declarations cannot be identified.
+				fmt.Fprintf(h, "decl:%p;", d)
+				continue
+			}
+			fmt.Fprintf(h, "decl:%d:%x;", len(bytes), bytes)
+		}
+		fmt.Fprintf(h, "features:%v;", e.features)
+		fmt.Fprintf(h, "libraries:%v;", e.libraries)
+		fmt.Fprintf(h, "sensitive:%v;", e.sensitivePaths)
+		names := make([]string, 0, len(e.variableDefaults))
+		for name := range e.variableDefaults {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			val := e.variableDefaults[name]
+			fmt.Fprintf(h, "default:%q:%s:%v;", name, val.Type().TypeName(), val)
+		}
+		fmt.Fprintf(h, "units:%v;", e.variableUnits)
+		fmt.Fprintf(h, "sizes:%v;", e.sizeProfiles)
+		fmt.Fprintf(h, "limits:%+v;", e.stringLimits)
+		fmt.Fprintf(h, "overflow:%d;", e.overflowPolicy)
+		if lister, isLister := e.provider.(interface{ TypeNames() []string }); isLister {
+			fmt.Fprintf(h, "types:%q;", lister.TypeNames())
+		}
+		copy(e.fp[:], h.Sum(nil))
+	})
+	return e.fp
+}
+
+// nextOpaqueGeneration returns a generation number which no other environment has used.
+func nextOpaqueGeneration() uint64 {
+	return atomic.AddUint64(&opaqueGenerations, 1)
+}
@@ -0,0 +1,273 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+
+	"google.golang.org/protobuf/proto"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+const (
+	flagsLibraryName = "cel.lib.flags"
+	flagsNamespace   = "flags"
+	flagsFunction    = "flags.enabled"
+	flagsOverload    = "flags_enabled_string"
+)
+
+// FlagProvider supplies the states of the feature flags tested by `flags.enabled()`.
+//
+// The provider may be invoked concurrently when programs are evaluated concurrently.
+type FlagProvider interface {
+	// FlagEnabled returns whether the named flag is enabled.
+	FlagEnabled(name string) bool
+}
+
+// FlagStates is a FlagProvider backed by a map from flag names to their states. Flags which are
+// absent from the map are disabled.
+type FlagStates map[string]bool
+
+// FlagEnabled implements the FlagProvider interface method.
+func (f FlagStates) FlagEnabled(name string) bool {
+	return f[name]
+}
+
+// FlagSource configures the provider of the flag states tested by `flags.enabled()` for all
+// evaluations of a program. Without a provider, every flag is disabled.
+//
+// The option has no effect unless the environment is configured with FeatureFlags.
+func FlagSource(provider FlagProvider) ProgramOption {
+	return func(p *prog) (*prog, error) {
+		p.flags = provider
+		return p, nil
+	}
+}
+
+type flagsLibrary struct{}
+
+// LibraryName implements the SingletonLibrary interface method.
+func (flagsLibrary) LibraryName() string {
+	return flagsLibraryName
+}
+
+// CompileOptions implements the Library interface method.
+func (flagsLibrary) CompileOptions() []EnvOption {
+	return []EnvOption{
+		Function(flagsFunction,
+			Overload(flagsOverload, []*Type{StringType}, BoolType,
+				UnaryBinding(func(ref.Val) ref.Val {
+					return types.False
+				})),
+			NonDeterministic(),
+			programSourced()),
+	}
+}
+
+// ProgramOptions implements the Library interface method.
+func (flagsLibrary) ProgramOptions() []ProgramOption {
+	return []ProgramOption{}
+}
+
+// flagCalls returns a decorator which evaluates calls to flags.enabled() using the provider.
+func flagCalls(provider FlagProvider) interpreter.InterpretableDecorator {
+	return func(i interpreter.Interpretable) (interpreter.Interpretable, error) {
+		call, ok := i.(interpreter.InterpretableCall)
+		if !ok || call.OverloadID() != flagsOverload || provider == nil {
+			return i, nil
+		}
+		return &evalFlag{InterpretableCall: call, provider: provider}, nil
+	}
+}
+
+// evalFlag evaluates a call to flags.enabled().
+type evalFlag struct {
+	interpreter.InterpretableCall
+	provider FlagProvider
+}
+
+// Eval implements the Interpretable interface method.
+func (f *evalFlag) Eval(vars interpreter.Activation) ref.Val {
+	arg := f.Args()[0].Eval(vars)
+	name, ok := arg.(types.String)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(arg)
+	}
+	return types.Bool(f.provider.FlagEnabled(string(name)))
+}
+
+// PinFlags returns a copy of the Ast in which the tests of the pinned flags by `flags.enabled()`
+// are replaced by the pinned states, and the branches guarded by them are folded away: conditional
+// expressions whose condition becomes constant are replaced by the selected branch, as with
+// EliminateDeadBranches, and logical operators with a constant operand are simplified, e.g.
+// `flags.enabled('beta') && expr` becomes `expr` when the flag is pinned on and `false` when it is
+// pinned off.
+//
+// Flags which are not pinned, or which are tested by name expressions other than string literals,
+// remain to be evaluated against the FlagSource of the program. Pinning every flag tested by an
+// expression for a given deployment thus removes the calls to `flags.enabled()` altogether.
+//
+// The input Ast is not modified.
+func (e *Env) PinFlags(ast *Ast, pinned map[string]bool) *Ast {
+	p := &flagPinner{
+		pinned:  pinned,
+		removed: map[int64]bool{},
+		pins:    map[int64]bool{},
+	}
+	expr := p.visit(proto.Clone(ast.Expr()).(*exprpb.Expr))
+	if len(p.pins) == 0 {
+		return ast
+	}
+	pruned := withoutIDs(ast, expr, p.removed)
+	// The calls replaced by literals no longer refer to the function.
+	for id := range p.pins {
+		delete(pruned.refMap, id)
+	}
+	return e.EliminateDeadBranches(pruned)
+}
+
+type flagPinner struct {
+	pinned  map[string]bool
+	removed map[int64]bool
+	// pins holds the ids of the expressions which were replaced by boolean literals.
+	pins map[int64]bool
+}
+
+// visit pins the flags within the expression graph, returning the expression which replaces the
+// input expression.
+func (p *flagPinner) visit(e *exprpb.Expr) *exprpb.Expr {
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		sel := e.GetSelectExpr()
+		sel.Operand = p.visit(sel.GetOperand())
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		if state, found := p.pinnedState(call); found {
+			p.removeAll(call.GetTarget())
+			return p.replace(e, call.GetArgs()[0], state)
+		}
+		if call.GetTarget() != nil {
+			call.Target = p.visit(call.GetTarget())
+		}
+		for i, arg := range call.GetArgs() {
+			call.Args[i] = p.visit(arg)
+		}
+		switch call.GetFunction() {
+		case operators.LogicalAnd, operators.LogicalOr:
+			return p.simplifyLogical(e)
+		case operators.LogicalNot:
+			if b, isBool := boolLiteral(call.GetArgs()[0]); isBool {
+				return p.replace(e, call.GetArgs()[0], !b)
+			}
+		}
+	case *exprpb.Expr_ListExpr:
+		list := e.GetListExpr()
+		for i, elem := range list.GetElements() {
+			list.Elements[i] = p.visit(elem)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			if entry.GetMapKey() != nil {
+				entry.KeyKind = &exprpb.Expr_CreateStruct_Entry_MapKey{MapKey: p.visit(entry.GetMapKey())}
+			}
+			entry.Value = p.visit(entry.GetValue())
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		comp.IterRange = p.visit(comp.GetIterRange())
+		comp.AccuInit = p.visit(comp.GetAccuInit())
+		comp.LoopCondition = p.visit(comp.GetLoopCondition())
+		comp.LoopStep = p.visit(comp.GetLoopStep())
+		comp.Result = p.visit(comp.GetResult())
+	}
+	return e
+}
+
+// pinnedState returns the pinned state of the flag tested by the call, if any.
+//
+// Checked expressions refer to the function by its qualified name, while parsed expressions call
+// `enabled` on the `flags` namespace.
+func (p *flagPinner) pinnedState(call *exprpb.Expr_Call) (bool, bool) {
+	switch {
+	case call.GetFunction() == flagsFunction && call.GetTarget() == nil:
+	case call.GetFunction() == "enabled" && call.GetTarget().GetIdentExpr().GetName() == flagsNamespace:
+	default:
+		return false, false
+	}
+	if len(call.GetArgs()) != 1 {
+		return false, false
+	}
+	name, isString := call.GetArgs()[0].GetConstExpr().GetConstantKind().(*exprpb.Constant_StringValue)
+	if !isString {
+		return false, false
+	}
+	state, found := p.pinned[name.StringValue]
+	return state, found
+}
+
+// simplifyLogical replaces a logical operator with a literal operand by the value it is known to
+// produce: the absorbing literal (false for &&, true for ||) or the other operand.
+func (p *flagPinner) simplifyLogical(e *exprpb.Expr) *exprpb.Expr {
+	call := e.GetCallExpr()
+	absorbing := call.GetFunction() == operators.LogicalOr
+	args := call.GetArgs()
+	for i, arg := range args {
+		b, isBool := boolLiteral(arg)
+		if !isBool {
+			continue
+		}
+		other := args[1-i]
+		if b == absorbing {
+			p.removeAll(other)
+			return p.replace(e, arg, b)
+		}
+		p.removeAll(arg)
+		p.removed[e.GetId()] = true
+		return other
+	}
+	return e
+}
+
+// replace substitutes a boolean literal for the expression, whose operand is removed.
+func (p *flagPinner) replace(e, operand *exprpb.Expr, value bool) *exprpb.Expr {
+	p.removeAll(operand)
+	p.pins[e.GetId()] = true
+	return &exprpb.Expr{
+		Id: e.GetId(),
+		ExprKind: &exprpb.Expr_ConstExpr{
+			ConstExpr: &exprpb.Constant{ConstantKind: &exprpb.Constant_BoolValue{BoolValue: value}},
+		},
+	}
+}
+
+// removeAll records the ids of the expression graph as removed.
+func (p *flagPinner) removeAll(e *exprpb.Expr) {
+	visitExpr(e, func(expr *exprpb.Expr) {
+		p.removed[expr.GetId()] = true
+	})
+}
+
+// boolLiteral returns the value of the expression when it is a boolean literal.
+func boolLiteral(e *exprpb.Expr) (bool, bool) {
+	b, isBool := e.GetConstExpr().GetConstantKind().(*exprpb.Constant_BoolValue)
+	if !isBool {
+		return false, false
+	}
+	return b.BoolValue, true
+}
@@ -0,0 +1,122 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestFeatureFlags(t *testing.T) {
+	env, err := NewEnv(FeatureFlags(), Variable("x", IntType))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	eval := func(ast *Ast, vars any, opts ...ProgramOption) ref.Val {
+		t.Helper()
+		prg, err := env.Program(ast, opts...)
+		if err != nil {
+			t.Fatalf("env.Program() failed: %v", err)
+		}
+		out, _, _ := prg.Eval(vars)
+		return out
+	}
+	tests := []struct {
+		expr   string
+		pinned map[string]bool
+		out    string
+	}{
+		{
+			expr:   `flags.enabled('beta') ? x + 1 : x`,
+			pinned: map[string]bool{"beta": true},
+			out:    `x + 1`,
+		},
+		{
+			expr:   `flags.enabled('beta') && x > 1 || !flags.enabled('strict') && x > 0`,
+			pinned: map[string]bool{"beta": false, "strict": false},
+			out:    `x > 0`,
+		},
+		{
+			expr:   `flags.enabled('beta') || x > 1`,
+			pinned: map[string]bool{"beta": true},
+			out:    `true`,
+		},
+		{
+			expr:   `flags.enabled('beta') && flags.enabled('gamma') ? x : 0`,
+			pinned: map[string]bool{"beta": true},
+			out:    `flags.enabled("gamma") ? x : 0`,
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile() failed: %v", iss.Err())
+			}
+			before := proto.Clone(ast.Expr())
+			pinned := env.PinFlags(ast, tc.pinned)
+			if !proto.Equal(ast.Expr(), before) {
+				t.Error("env.PinFlags() modified the input ast")
+			}
+			out, err := AstToString(pinned)
+			if err != nil {
+				t.Fatalf("AstToString() failed: %v", err)
+			}
+			if out != tc.out {
+				t.Errorf("env.PinFlags() got %s, wanted %s", out, tc.out)
+			}
+			ids := map[int64]bool{}
+			visitExpr(pinned.Expr(), func(e *exprpb.Expr) { ids[e.GetId()] = true })
+			for id := range pinned.typeMap {
+				if !ids[id] {
+					t.Errorf("env.PinFlags() retained the type of removed expression %d", id)
+				}
+			}
+			// The pinned program agrees with the original program given the pinned flags.
+			for _, x := range []int{0, 1, 2} {
+				vars := map[string]any{"x": x}
+				want := eval(ast, vars, FlagSource(FlagStates(tc.pinned)))
+				got := eval(pinned, vars, FlagSource(FlagStates(tc.pinned)))
+				if want.Equal(got) != types.True {
+					t.Errorf("pinned program got %v, wanted %v for x = %d", got, want, x)
+				}
+			}
+		})
+	}
+
+	// Without a provider, every flag is disabled.
+	ast, iss := env.Compile(`flags.enabled('beta') ? 1 : 2`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	out := eval(ast, NoVars())
+	if out != types.Int(2) {
+		t.Errorf("prg.Eval() got %v, wanted 2 without a flag provider", out)
+	}
+	out = eval(ast, NoVars(), FlagSource(FlagStates{"beta": true}))
+	if out != types.Int(1) {
+		t.Errorf("prg.Eval() got %v, wanted 1 with the flag enabled", out)
+	}
+	if pruned := env.EliminateDeadBranches(ast); pruned != ast {
+		t.Error("env.EliminateDeadBranches() folded an unpinned flag")
+	}
+}
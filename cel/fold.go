@@ -0,0 +1,142 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/parser"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// FoldDef defines a comprehension-based receiver macro of the form `range.name(var, args...)`
+// which folds the elements of the range, or the keys of a map range, into an accumulator.
+//
+// The accumulator is typed by the AccuType rather than inferred from the expressions produced by
+// Init and Step, so the checker rejects initial values and steps which are not assignable to the
+// AccuType, and the result of the macro is not left dyn-typed when the step is computed by
+// functions returning dyn. Likewise, the checker rejects ranges which are not assignable to the
+// RangeType, when one is given.
+//
+// The AccuType and RangeType may contain type parameters, e.g. `list(T)`, though the parameters
+// of one are not bound to those of the other.
+//
+// The args given to Init, Step, and Result are the macro arguments following the iteration
+// variable. Each argument expression, as well as the iteration variable expression, must appear at
+// most once within the expressions produced for a single expansion.
+type FoldDef struct {
+	// Name is the name of the macro.
+	Name string
+
+	// ArgCount is the number of arguments which follow the iteration variable.
+	ArgCount int
+
+	// RangeType is the type of the range, or nil if any list or map may be folded.
+	RangeType *Type
+
+	// AccuType is the type of the accumulator.
+	AccuType *Type
+
+	// Init returns the expression which initializes the accumulator.
+	Init func(eh MacroExprHelper, args []*exprpb.Expr) *exprpb.Expr
+
+	// Step returns the expression which computes the accumulator from the current accumulator and
+	// iteration variable.
+	Step func(eh MacroExprHelper, accu, iterVar *exprpb.Expr, args []*exprpb.Expr) *exprpb.Expr
+
+	// Result returns the expression which computes the result of the macro from the final
+	// accumulator, or nil if the result is the accumulator.
+	Result func(eh MacroExprHelper, accu *exprpb.Expr, args []*exprpb.Expr) *exprpb.Expr
+}
+
+// FoldMacro registers a comprehension-based macro, e.g. a `sum` macro which folds a list into an
+// int:
+//
+//	cel.FoldMacro(cel.FoldDef{
+//	    Name:     "sum",
+//	    ArgCount: 1,
+//	    AccuType: cel.IntType,
+//	    Init: func(eh cel.MacroExprHelper, args []*exprpb.Expr) *exprpb.Expr {
+//	        return eh.LiteralInt(0)
+//	    },
+//	    Step: func(eh cel.MacroExprHelper, accu, iterVar *exprpb.Expr, args []*exprpb.Expr) *exprpb.Expr {
+//	        return eh.GlobalCall(operators.Add, accu, args[0])
+//	    },
+//	})
+//
+// which expands `[1, 2, 3].sum(x, x * x)` into a comprehension evaluating to 14.
+//
+// The accumulator and range are typed by calls to internal identity functions declared with the
+// AccuType and RangeType, named `@fold_<name>_accu` and `@fold_<name>_range` respectively. The
+// identity functions do not guard the runtime types of their arguments, so the accumulator may be
+// represented at runtime by a value private to the functions called by the step and result. Type
+// errors involving the identity functions refer to the name of the macro.
+func FoldMacro(def FoldDef) EnvOption {
+	return func(e *Env) (*Env, error) {
+		if def.Name == "" || def.ArgCount < 0 || def.AccuType == nil || def.Init == nil || def.Step == nil {
+			return nil, fmt.Errorf("fold macro %q requires a name, an accumulator type, and init and step expressions", def.Name)
+		}
+		accuFn := fmt.Sprintf("@fold_%s_accu", def.Name)
+		opts := []EnvOption{
+			Function(accuFn, Overload(accuFn, []*Type{def.AccuType}, def.AccuType),
+				SingletonUnaryBinding(foldIdentity), DisplayName(def.Name)),
+		}
+		rangeFn := ""
+		if def.RangeType != nil {
+			rangeFn = fmt.Sprintf("@fold_%s_range", def.Name)
+			opts = append(opts,
+				Function(rangeFn, Overload(rangeFn, []*Type{def.RangeType}, def.RangeType),
+					SingletonUnaryBinding(foldIdentity), DisplayName(def.Name)))
+		}
+		expander := func(eh MacroExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+			iterVar := args[0]
+			if iterVar.GetIdentExpr() == nil {
+				return nil, &common.Error{
+					Message:  "argument must be a simple name",
+					Location: eh.OffsetLocation(iterVar.GetId()),
+				}
+			}
+			args = args[1:]
+			iterRange := target
+			if rangeFn != "" {
+				iterRange = eh.GlobalCall(rangeFn, target)
+			}
+			init := eh.GlobalCall(accuFn, def.Init(eh, args))
+			step := eh.GlobalCall(accuFn, def.Step(eh, eh.AccuIdent(), iterVar, args))
+			result := eh.AccuIdent()
+			if def.Result != nil {
+				result = def.Result(eh, eh.AccuIdent(), args)
+			}
+			return eh.Fold(iterVar.GetIdentExpr().GetName(), iterRange, parser.AccumulatorName,
+				init, eh.LiteralBool(true), step, result), nil
+		}
+		opts = append(opts, Macros(NewReceiverMacro(def.Name, def.ArgCount+1, expander)))
+		for _, opt := range opts {
+			var err error
+			if e, err = opt(e); err != nil {
+				return nil, err
+			}
+		}
+		return e, nil
+	}
+}
+
+// foldIdentity implements the functions which type the accumulator and range of a fold macro.
+func foldIdentity(val ref.Val) ref.Val {
+	return val
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/common/operators"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestFoldMacro(t *testing.T) {
+	sum := FoldDef{
+		Name:      "sum",
+		ArgCount:  1,
+		RangeType: ListType(IntType),
+		AccuType:  IntType,
+		Init: func(eh MacroExprHelper, args []*exprpb.Expr) *exprpb.Expr {
+			return eh.LiteralInt(0)
+		},
+		Step: func(eh MacroExprHelper, accu, iterVar *exprpb.Expr, args []*exprpb.Expr) *exprpb.Expr {
+			return eh.GlobalCall(operators.Add, accu, args[0])
+		},
+	}
+	// distinct collects the distinct elements of a list, where the element type is preserved.
+	distinct := FoldDef{
+		Name:     "distinct",
+		AccuType: ListType(TypeParamType("T")),
+		Init: func(eh MacroExprHelper, args []*exprpb.Expr) *exprpb.Expr {
+			return eh.NewList()
+		},
+		Step: func(eh MacroExprHelper, accu, iterVar *exprpb.Expr, args []*exprpb.Expr) *exprpb.Expr {
+			name := iterVar.GetIdentExpr().GetName()
+			return eh.GlobalCall(operators.Conditional,
+				eh.GlobalCall(operators.In, eh.Ident(name), accu),
+				eh.AccuIdent(),
+				eh.GlobalCall(operators.Add, eh.AccuIdent(), eh.NewList(iterVar)))
+		},
+		Result: func(eh MacroExprHelper, accu *exprpb.Expr, args []*exprpb.Expr) *exprpb.Expr {
+			return accu
+		},
+	}
+	env, err := NewEnv(FoldMacro(sum), FoldMacro(distinct), Variable("dyns", ListType(DynType)))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	runEvalTests(t, env, []evalTestCase{
+		{expr: `[1, 2, 3].sum(x, x * x)`, outType: IntType, out: 14},
+		{expr: `[].sum(x, x)`, outType: IntType, out: 0},
+		{expr: `dyns.sum(x, x)`, outType: IntType, out: 3},
+		{expr: `[1, 2, 1, 3, 2].distinct(x)`, outType: ListType(IntType), out: []int64{1, 2, 3}},
+		{expr: `['a', 'a'].distinct(x)`, outType: ListType(StringType), out: []string{"a"}},
+		{expr: `['a'].sum(x, 1)`, err: "found no matching overload for 'sum' applied to '(list(string))'"},
+		{expr: `[1].sum(x, 'a')`, err: "found no matching overload for '_+_'"},
+		{expr: `[1].sum(x.y, x)`, err: "argument must be a simple name"},
+	}, map[string]any{"dyns": []any{1, 2}})
+	if _, err := NewEnv(FoldMacro(FoldDef{Name: "bad"})); err == nil {
+		t.Error("NewEnv() with an incomplete fold macro succeeded, wanted error")
+	}
+}
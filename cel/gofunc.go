@@ -0,0 +1,347 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	goContextType      = reflect.TypeOf((*context.Context)(nil)).Elem()
+	goErrorType        = reflect.TypeOf((*error)(nil)).Elem()
+	goRefValType       = reflect.TypeOf((*ref.Val)(nil)).Elem()
+	goProtoMessageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
+	goDurationType     = reflect.TypeOf(time.Duration(0))
+	goTimeType         = reflect.TypeOf(time.Time{})
+	goBytesType        = reflect.TypeOf([]byte{})
+)
+
+// FunctionFromGo declares a global function with a single overload implemented by the Go function,
+// deriving the overload signature from the Go signature, e.g. `func(string, int64) bool` declares
+// the overload `name(string, int) -> bool`.
+//
+// The supported parameter and result types are:
+//
+//   - bool, the signed and unsigned integer types, float32 and float64, string, and []byte
+//   - time.Duration and time.Time
+//   - slices and maps of the supported types
+//   - pointers to generated protobuf message structs, whose types must be registered via Types
+//   - ref.Val and the empty interface, which are declared as `dyn`
+//
+// The Go function may accept a context.Context as its first parameter, in which case it receives
+// the context of the evaluation, and may return an error as its last result, in which case a
+// non-nil error is returned as the result of the call. Variadic functions are not supported.
+//
+// The overload id is derived from the function name and the declared parameter types, e.g.
+// `name_string_int`. Additional options, such as FunctionDoc, may be provided to configure the
+// function declaration.
+func FunctionFromGo(name string, fn any, opts ...FunctionOpt) EnvOption {
+	return func(e *Env) (*Env, error) {
+		overload, err := goFunctionOverload(e, name, fn)
+		if err != nil {
+			return nil, err
+		}
+		return Function(name, append([]FunctionOpt{overload}, opts...)...)(e)
+	}
+}
+
+// goFunctionOverload returns a FunctionOpt declaring the overload implemented by the Go function.
+func goFunctionOverload(e *Env, name string, fn any) (FunctionOpt, error) {
+	fnVal := reflect.ValueOf(fn)
+	if !fnVal.IsValid() || fnVal.Kind() != reflect.Func || fnVal.IsNil() {
+		return nil, fmt.Errorf("function %s must be implemented by a non-nil Go func, got: %T", name, fn)
+	}
+	fnType := fnVal.Type()
+	if fnType.IsVariadic() {
+		return nil, fmt.Errorf("function %s must not be variadic", name)
+	}
+	withContext := fnType.NumIn() > 0 && fnType.In(0) == goContextType
+	var params []reflect.Type
+	var argTypes []*Type
+	for i := 0; i < fnType.NumIn(); i++ {
+		if i == 0 && withContext {
+			continue
+		}
+		t, err := goTypeToType(fnType.In(i))
+		if err != nil {
+			return nil, fmt.Errorf("function %s parameter %d: %w", name, i, err)
+		}
+		params = append(params, fnType.In(i))
+		argTypes = append(argTypes, t)
+	}
+	withError := false
+	switch {
+	case fnType.NumOut() == 2 && fnType.Out(1) == goErrorType:
+		withError = true
+	case fnType.NumOut() != 1:
+		return nil, fmt.Errorf("function %s must return a value and an optional error", name)
+	}
+	resultType, err := goTypeToType(fnType.Out(0))
+	if err != nil {
+		return nil, fmt.Errorf("function %s result: %w", name, err)
+	}
+
+	call := func(ctx context.Context, args ...ref.Val) ref.Val {
+		in := make([]reflect.Value, 0, fnType.NumIn())
+		if withContext {
+			in = append(in, reflect.ValueOf(&ctx).Elem())
+		}
+		for i, arg := range args {
+			v, err := goArgValue(arg, params[i])
+			if err != nil {
+				return types.NewErr("%s: %v", name, err)
+			}
+			in = append(in, v)
+		}
+		out := fnVal.Call(in)
+		if withError && !out[1].IsNil() {
+			return types.NewErr("%s: %v", name, out[1].Interface())
+		}
+		return goResultValue(e.adapter, out[0])
+	}
+
+	var binding OverloadOpt
+	switch {
+	case withContext:
+		binding = ContextFunctionBinding(call)
+	case len(argTypes) == 1:
+		binding = UnaryBinding(func(arg ref.Val) ref.Val {
+			return call(context.Background(), arg)
+		})
+	case len(argTypes) == 2:
+		binding = BinaryBinding(func(lhs, rhs ref.Val) ref.Val {
+			return call(context.Background(), lhs, rhs)
+		})
+	default:
+		binding = FunctionBinding(func(args ...ref.Val) ref.Val {
+			return call(context.Background(), args...)
+		})
+	}
+	return Overload(goOverloadID(name, argTypes), argTypes, resultType, binding), nil
+}
+
+// goArgValue converts a CEL value into a reflect.Value of the Go parameter type.
+//
+// Integers are converted to the sized integer types with a range check, and the elements of lists
+// and maps are converted individually so that they may also be of the sized integer types.
+func goArgValue(arg ref.Val, paramType reflect.Type) (reflect.Value, error) {
+	switch {
+	case paramType == goRefValType:
+		return reflect.ValueOf(&arg).Elem(), nil
+	case paramType.Kind() == reflect.Interface && paramType.NumMethod() == 0:
+		native := arg.Value()
+		if native == nil {
+			return reflect.Zero(paramType), nil
+		}
+		return reflect.ValueOf(native), nil
+	case paramType == goDurationType || paramType == goBytesType:
+		// Converted by the CEL value below.
+	default:
+		switch paramType.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i, isInt := arg.(types.Int)
+			if !isInt {
+				return reflect.Value{}, fmt.Errorf("cannot convert %s to %v", arg.Type().TypeName(), paramType)
+			}
+			v := reflect.New(paramType).Elem()
+			if v.OverflowInt(int64(i)) {
+				return reflect.Value{}, fmt.Errorf("integer %d out of range for %v", i, paramType)
+			}
+			v.SetInt(int64(i))
+			return v, nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			u, isUint := arg.(types.Uint)
+			if !isUint {
+				return reflect.Value{}, fmt.Errorf("cannot convert %s to %v", arg.Type().TypeName(), paramType)
+			}
+			v := reflect.New(paramType).Elem()
+			if v.OverflowUint(uint64(u)) {
+				return reflect.Value{}, fmt.Errorf("integer %d out of range for %v", u, paramType)
+			}
+			v.SetUint(uint64(u))
+			return v, nil
+		case reflect.Slice:
+			list, isList := arg.(traits.Lister)
+			if !isList {
+				return reflect.Value{}, fmt.Errorf("cannot convert %s to %v", arg.Type().TypeName(), paramType)
+			}
+			v := reflect.MakeSlice(paramType, 0, 0)
+			for it := list.Iterator(); it.HasNext() == types.True; {
+				elem, err := goArgValue(it.Next(), paramType.Elem())
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				v = reflect.Append(v, elem)
+			}
+			return v, nil
+		case reflect.Map:
+			m, isMap := arg.(traits.Mapper)
+			if !isMap {
+				return reflect.Value{}, fmt.Errorf("cannot convert %s to %v", arg.Type().TypeName(), paramType)
+			}
+			v := reflect.MakeMap(paramType)
+			for it := m.Iterator(); it.HasNext() == types.True; {
+				key := it.Next()
+				k, err := goArgValue(key, paramType.Key())
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				elem, err := goArgValue(m.Get(key), paramType.Elem())
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				v.SetMapIndex(k, elem)
+			}
+			return v, nil
+		}
+	}
+	native, err := arg.ConvertToNative(paramType)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	v := reflect.ValueOf(native)
+	if !v.IsValid() {
+		return reflect.Zero(paramType), nil
+	}
+	if !v.Type().AssignableTo(paramType) {
+		if !v.Type().ConvertibleTo(paramType) {
+			return reflect.Value{}, fmt.Errorf("cannot convert %v to %v", v.Type(), paramType)
+		}
+		v = v.Convert(paramType)
+	}
+	return v, nil
+}
+
+// goResultValue converts the result of a Go function into a CEL value, widening the integer types
+// which the type adapter does not support, such as int16.
+//
+// The elements of slices and the keys and values of maps are converted individually, mirroring
+// goArgValue, so that they may also be of the sized integer types.
+func goResultValue(adapter ref.TypeAdapter, out reflect.Value) ref.Val {
+	if val, isVal := out.Interface().(ref.Val); isVal {
+		return val
+	}
+	switch out.Type() {
+	case goDurationType, goBytesType:
+		return adapter.NativeToValue(out.Interface())
+	}
+	switch out.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return types.Int(out.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return types.Uint(out.Uint())
+	case reflect.Slice:
+		elems := make([]ref.Val, out.Len())
+		for i := 0; i < out.Len(); i++ {
+			elem := goResultValue(adapter, out.Index(i))
+			if types.IsError(elem) {
+				return elem
+			}
+			elems[i] = elem
+		}
+		return types.NewRefValList(adapter, elems)
+	case reflect.Map:
+		entries := make(map[ref.Val]ref.Val, out.Len())
+		for it := out.MapRange(); it.Next(); {
+			key := goResultValue(adapter, it.Key())
+			if types.IsError(key) {
+				return key
+			}
+			val := goResultValue(adapter, it.Value())
+			if types.IsError(val) {
+				return val
+			}
+			entries[key] = val
+		}
+		return types.NewRefValMap(adapter, entries)
+	}
+	return adapter.NativeToValue(out.Interface())
+}
+
+// goTypeToType returns the CEL type corresponding to the Go type.
+func goTypeToType(t reflect.Type) (*Type, error) {
+	switch t {
+	case goRefValType:
+		return DynType, nil
+	case goDurationType:
+		return DurationType, nil
+	case goTimeType:
+		return TimestampType, nil
+	case goBytesType:
+		return BytesType, nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return BoolType, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return IntType, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return UintType, nil
+	case reflect.Float32, reflect.Float64:
+		return DoubleType, nil
+	case reflect.String:
+		return StringType, nil
+	case reflect.Slice:
+		elem, err := goTypeToType(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return ListType(elem), nil
+	case reflect.Map:
+		key, err := goTypeToType(t.Key())
+		if err != nil {
+			return nil, err
+		}
+		val, err := goTypeToType(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return MapType(key, val), nil
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return DynType, nil
+		}
+	case reflect.Ptr:
+		if t.Implements(goProtoMessageType) && t.Elem().Kind() == reflect.Struct {
+			msg := reflect.New(t.Elem()).Interface().(proto.Message)
+			return ObjectType(string(msg.ProtoReflect().Descriptor().FullName())), nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported Go type: %v", t)
+}
+
+// goOverloadID returns an overload id formed from the function name and the parameter types,
+// e.g. `name_list_string_int` for `name(list(string), int)`.
+func goOverloadID(name string, argTypes []*Type) string {
+	var sb strings.Builder
+	sb.WriteString(name)
+	for _, t := range argTypes {
+		sb.WriteString("_")
+		sb.WriteString(strings.Join(strings.FieldsFunc(t.String(), func(r rune) bool {
+			return r != '_' && !('a' <= r && r <= 'z') && !('A' <= r && r <= 'Z') && !('0' <= r && r <= '9')
+		}), "_"))
+	}
+	return sb.String()
+}
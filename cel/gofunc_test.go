@@ -0,0 +1,127 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+func TestFunctionFromGo(t *testing.T) {
+	type ctxKey struct{}
+	env, err := NewEnv(
+		Variable("xs", ListType(IntType)),
+		FunctionFromGo("greet", func(name string, times int32) string {
+			return strings.Repeat("hi "+name+"!", int(times))
+		}),
+		FunctionFromGo("sum", func(xs []int64) int64 {
+			total := int64(0)
+			for _, x := range xs {
+				total += x
+			}
+			return total
+		}),
+		FunctionFromGo("parse", strconv.ParseBool),
+		FunctionFromGo("shift", func(t time.Time, d time.Duration) time.Time { return t.Add(d) }),
+		FunctionFromGo("describe", func(v any, m map[string]float64) string {
+			return fmt.Sprintf("%v:%d", v, len(m))
+		}),
+		FunctionFromGo("tenant", func(ctx context.Context) string {
+			tenant, _ := ctx.Value(ctxKey{}).(string)
+			return tenant
+		}),
+		FunctionFromGo("small", func(a int8, b uint16, cs []int16) int16 {
+			total := int16(a) + int16(b)
+			for _, c := range cs {
+				total += c
+			}
+			return total
+		}),
+		FunctionFromGo("smalls", func() []int16 { return []int16{1, -2} }),
+		FunctionFromGo("counts", func() map[string]uint8 { return map[string]uint8{"a": 1, "b": 255} }),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		expr string
+		out  ref.Val
+		err  string
+	}{
+		{expr: `greet('cel', 2)`, out: types.String("hi cel!hi cel!")},
+		{expr: `sum(xs) + sum([4])`, out: types.Int(10)},
+		{expr: `parse('true')`, out: types.True},
+		{expr: `parse('maybe')`, err: `parse: strconv.ParseBool: parsing "maybe": invalid syntax`},
+		{expr: `shift(timestamp('2023-01-01T00:00:00Z'), duration('1h')) == timestamp('2023-01-01T01:00:00Z')`, out: types.True},
+		{expr: `describe(1u, {'a': 1.0})`, out: types.String("1:1")},
+		{expr: `tenant()`, out: types.String("acme")},
+		{expr: `small(-1, 2u, [3, 4])`, out: types.Int(8)},
+		{expr: `small(128, 2u, [])`, err: "small: integer 128 out of range for int8"},
+		{expr: `small(1, 2u, [32768])`, err: "small: integer 32768 out of range for int16"},
+		{expr: `smalls()[0] == 1 && smalls() == [1, -2]`, out: types.True},
+		{expr: `counts()['b'] == 255u && counts() == {'a': 1u, 'b': 255u}`, out: types.True},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile() failed: %v", iss.Err())
+			}
+			prg, err := env.Program(ast)
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			ctx := context.WithValue(context.Background(), ctxKey{}, "acme")
+			out, _, err := prg.ContextEval(ctx, map[string]any{"xs": []int64{1, 2, 3}})
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("prg.Eval() got %v, %v, wanted error %q", out, err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("prg.Eval() failed: %v", err)
+			}
+			if out.Equal(tc.out) != types.True {
+				t.Errorf("prg.Eval() got %v, wanted %v", out, tc.out)
+			}
+		})
+	}
+	if _, iss := env.Compile(`greet(1, 2)`); iss.Err() == nil {
+		t.Error("env.Compile() with mismatched argument types succeeded, wanted error")
+	}
+
+	badFuncs := map[string]any{
+		"not a func":  1,
+		"variadic":    func(xs ...int) int { return len(xs) },
+		"unsupported": func(ch chan int) int { return 0 },
+		"no result":   func(s string) {},
+		"nil":         nil,
+		"nil func":    (func(string) string)(nil),
+	}
+	for name, fn := range badFuncs {
+		if _, err := NewEnv(FunctionFromGo("bad", fn)); err == nil {
+			t.Errorf("NewEnv() with %s succeeded, wanted error", name)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"sort"
+
+	"github.com/google/cel-go/interpreter"
+	"github.com/google/cel-go/interpreter/functions"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// IncrementalProgram is a Program which retains the results of subexpressions between
+// evaluations, re-evaluating only the subexpressions which depend on the attributes reported as
+// changed via NotifyChanged.
+//
+// The program is intended for the repeated evaluation of an expression against a single input
+// which changes over time. The caller is responsible for reporting every change to the input
+// before the next evaluation; unreported changes are not observed by the cached subexpressions.
+type IncrementalProgram struct {
+	Program
+	cache *interpreter.IncrementalCache
+}
+
+// NotifyChanged invalidates the cached results which depend on the attribute path, where the path
+// is a variable name optionally followed by dot-delimited field selections, e.g. `request.auth`.
+//
+// A change to a path also invalidates the results which depend on paths nested within it, or on
+// the paths which contain it.
+func (p *IncrementalProgram) NotifyChanged(path string) {
+	p.cache.NotifyChanged(path)
+}
+
+// Reset invalidates all cached results, such as when the evaluation input is replaced wholesale.
+func (p *IncrementalProgram) Reset() {
+	p.cache.Reset()
+}
+
+// withFunctions implements the rebinder interface method. The results of calls to rebindable
+// functions are never cached, so the copy shares the cache of the program, and changes reported to
+// either program invalidate the results cached for both.
+func (p *IncrementalProgram) withFunctions(bindings ...*functions.Overload) (Program, error) {
+	prg, err := WithFunctions(p.Program, bindings...)
+	if err != nil {
+		return nil, err
+	}
+	return &IncrementalProgram{Program: prg, cache: p.cache}, nil
+}
+
+// evalSeries implements the seriesEvaluator interface method. The program is notified of the
+// variables changed by each delta of the series.
+func (p *IncrementalProgram) evalSeries(base any) (*Series, error) {
+	return newSeries(p, base)
+}
+
+// warmup implements the warmer interface method.
+func (p *IncrementalProgram) warmup(sampleVars any) error {
+	return Warmup(p.Program, sampleVars)
+}
+
+// IncrementalProgram generates an evaluable instance of the Ast which caches the results of its
+// subexpressions between evaluations. See IncrementalProgram for more information.
+//
+// Subexpressions which call nondeterministic or rebindable functions, or refer to comprehension
+// variables, are always evaluated. The cost and memory of cached results are only tracked when they are computed.
+func (e *Env) IncrementalProgram(ast *Ast, opts ...ProgramOption) (*IncrementalProgram, error) {
+	a := &dependencyAnalyzer{
+		env:  e,
+		ast:  ast,
+		deps: make(map[int64][]string),
+	}
+	a.visit(ast.Expr(), map[string]bool{})
+	cache := interpreter.NewIncrementalCache(a.deps)
+	prgOpts := make([]ProgramOption, 0, len(opts)+1)
+	prgOpts = append(prgOpts, opts...)
+	prgOpts = append(prgOpts, func(p *prog) (*prog, error) {
+		p.incremental = cache
+		return p, nil
+	})
+	prg, err := e.Program(ast, prgOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &IncrementalProgram{Program: prg, cache: cache}, nil
+}
+
+// dependencyAnalyzer computes the attribute paths on which each subexpression depends.
+type dependencyAnalyzer struct {
+	env  *Env
+	ast  *Ast
+	deps map[int64][]string
+}
+
+// exprDeps describes the inputs of a subexpression.
+type exprDeps struct {
+	// paths are the attribute paths referenced by the subexpression.
+	paths map[string]bool
+	// locals are the comprehension variables referenced, but not declared, by the subexpression.
+	locals map[string]bool
+	// nondeterministic indicates whether the subexpression calls a nondeterministic function, or a
+	// rebindable function whose implementation may differ between evaluations.
+	nondeterministic bool
+}
+
+func (d *exprDeps) merge(other *exprDeps) {
+	for path := range other.paths {
+		d.paths[path] = true
+	}
+	for local := range other.locals {
+		d.locals[local] = true
+	}
+	d.nondeterministic = d.nondeterministic || other.nondeterministic
+}
+
+// visit computes the dependencies of the expression graph, recording the attribute paths of each
+// cacheable subexpression. The locals are the comprehension variables in scope.
+func (a *dependencyAnalyzer) visit(e *exprpb.Expr, locals map[string]bool) *exprDeps {
+	deps := &exprDeps{paths: map[string]bool{}, locals: map[string]bool{}}
+	if path, root, found := a.attributePath(e); found {
+		if locals[root] {
+			deps.locals[root] = true
+		} else if path != "" {
+			deps.paths[path] = true
+		}
+		return deps
+	}
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		deps.merge(a.visit(e.GetSelectExpr().GetOperand(), locals))
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		if fn, found := a.env.functions[call.GetFunction()]; found && (fn.nondeterministic || fn.rebindable) {
+			deps.nondeterministic = true
+		}
+		if call.GetTarget() != nil {
+			deps.merge(a.visit(call.GetTarget(), locals))
+		}
+		for _, arg := range call.GetArgs() {
+			deps.merge(a.visit(arg, locals))
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range e.GetListExpr().GetElements() {
+			deps.merge(a.visit(elem, locals))
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			if entry.GetMapKey() != nil {
+				deps.merge(a.visit(entry.GetMapKey(), locals))
+			}
+			deps.merge(a.visit(entry.GetValue(), locals))
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		deps.merge(a.visit(comp.GetIterRange(), locals))
+		deps.merge(a.visit(comp.GetAccuInit(), locals))
+		scope := make(map[string]bool, len(locals)+2)
+		for local := range locals {
+			scope[local] = true
+		}
+		scope[comp.GetIterVar()] = true
+		scope[comp.GetAccuVar()] = true
+		body := &exprDeps{paths: map[string]bool{}, locals: map[string]bool{}}
+		body.merge(a.visit(comp.GetLoopCondition(), scope))
+		body.merge(a.visit(comp.GetLoopStep(), scope))
+		body.merge(a.visit(comp.GetResult(), scope))
+		delete(body.locals, comp.GetIterVar())
+		delete(body.locals, comp.GetAccuVar())
+		deps.merge(body)
+	}
+	if len(deps.paths) != 0 && len(deps.locals) == 0 && !deps.nondeterministic {
+		paths := make([]string, 0, len(deps.paths))
+		for path := range deps.paths {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		a.deps[e.GetId()] = paths
+	}
+	return deps
+}
+
+// attributePath returns the attribute path of an identifier, or of a chain of field selections
+// rooted at an identifier, along with the name of the root identifier.
+//
+// Identifiers which refer to constants, such as enum values, have an empty path.
+func (a *dependencyAnalyzer) attributePath(e *exprpb.Expr) (string, string, bool) {
+	if ref, found := a.ast.refMap[e.GetId()]; found && ref.GetName() != "" {
+		if ref.GetValue() != nil {
+			return "", ref.GetName(), true
+		}
+		return ref.GetName(), ref.GetName(), true
+	}
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		name := e.GetIdentExpr().GetName()
+		return name, name, true
+	case *exprpb.Expr_SelectExpr:
+		sel := e.GetSelectExpr()
+		if sel.GetTestOnly() {
+			return "", "", false
+		}
+		path, root, found := a.attributePath(sel.GetOperand())
+		if !found || path == "" {
+			return "", "", false
+		}
+		return path + "." + sel.GetField(), root, true
+	}
+	return "", "", false
+}
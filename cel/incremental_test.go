@@ -0,0 +1,119 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+func TestIncrementalProgram(t *testing.T) {
+	calls := map[string]int{}
+	env, err := NewEnv(
+		Variable("user", MapType(StringType, StringType)),
+		Variable("resource", StringType),
+		Function("expensive",
+			Overload("expensive_string", []*Type{StringType}, IntType,
+				UnaryBinding(func(arg ref.Val) ref.Val {
+					calls[string(arg.(types.String))]++
+					return types.Int(len(arg.(types.String)))
+				}))),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(
+		`expensive(user.name) + expensive(resource) + [1, 2].map(i, i + expensive(resource))[1]`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	for _, opts := range []ProgramOption{
+		EvalOptions(OptOptimize),
+		EvalOptions(OptExhaustiveEval),
+	} {
+		for k := range calls {
+			delete(calls, k)
+		}
+		prg, err := env.IncrementalProgram(ast, opts)
+		if err != nil {
+			t.Fatalf("env.IncrementalProgram() failed: %v", err)
+		}
+		vars := map[string]any{
+			"user":     map[string]string{"name": "alice"},
+			"resource": "doc",
+		}
+		eval := func(want types.Int) {
+			t.Helper()
+			out, _, err := prg.Eval(vars)
+			if err != nil || out != want {
+				t.Errorf("prg.Eval() got %v, %v, wanted %v", out, err, want)
+			}
+		}
+		eval(13)
+		eval(13)
+		// The loop-invariant call within the comprehension is also evaluated once.
+		if calls["alice"] != 1 || calls["doc"] != 2 {
+			t.Errorf("repeated evaluation got calls %v, wanted alice: 1, doc: 2", calls)
+		}
+
+		// Only the subexpressions which depend on the user are re-evaluated.
+		vars["user"] = map[string]string{"name": "bob"}
+		prg.NotifyChanged("user.name")
+		eval(11)
+		if calls["bob"] != 1 || calls["doc"] != 2 {
+			t.Errorf("evaluation after user change got calls %v, wanted bob: 1, doc: 2", calls)
+		}
+
+		vars["resource"] = "file"
+		prg.NotifyChanged("resource")
+		eval(13)
+		if calls["bob"] != 1 || calls["file"] != 2 {
+			t.Errorf("evaluation after resource change got calls %v, wanted bob: 1, file: 2", calls)
+		}
+
+		prg.Reset()
+		eval(13)
+		if calls["bob"] != 2 || calls["file"] != 4 {
+			t.Errorf("evaluation after reset got calls %v, wanted bob: 2, file: 4", calls)
+		}
+	}
+
+	// The errors of an interrupted evaluation are not retained for later evaluations.
+	env, err = NewEnv(Variable("items", ListType(IntType)))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss = env.Compile(`items.filter(i, i > 0).size()`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.IncrementalProgram(ast, InterruptCheckFrequency(1))
+	if err != nil {
+		t.Fatalf("env.IncrementalProgram() failed: %v", err)
+	}
+	vars := map[string]any{"items": []int{1, 2, 3}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if out, _, err := prg.ContextEval(ctx, vars); err == nil {
+		t.Fatalf("prg.ContextEval() with a cancelled context got %v, wanted error", out)
+	}
+	out, _, err := prg.Eval(vars)
+	if err != nil || out != types.Int(3) {
+		t.Errorf("prg.Eval() after cancellation got %v, %v, wanted 3", out, err)
+	}
+}
@@ -69,9 +69,35 @@ func CheckedExprToAstWithSource(checkedExpr *exprpb.CheckedExpr, src Source) *As
 	}
 }
 
+// ProgramFromCheckedExpr plans a Program from a checked expression proto, such as one produced by
+// the toolchain of another language, without parsing or type-checking the expression again.
+//
+// Since the expression was not checked within the environment, it is first verified with
+// Env.Validate, and the restrictions which Env.Check applies to the expressions it checks, such as
+// the prohibition of nondeterministic functions, are applied. The default values of the variables
+// referenced by the expression are recorded as for an Ast produced by Env.Check.
+func ProgramFromCheckedExpr(env *Env, checkedExpr *exprpb.CheckedExpr, opts ...ProgramOption) (Program, error) {
+	if checkedExpr.GetExpr() == nil {
+		return nil, errors.New("checked expression must not be empty")
+	}
+	ast := CheckedExprToAst(checkedExpr)
+	if iss := env.Validate(ast); iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	if iss := env.checkRestrictions(ast); iss != nil {
+		return nil, iss.Err()
+	}
+	ast.resolutions = env.containerResolutions(writtenNames(ast.Expr(), map[int64]string{}), ast.refMap)
+	ast.defaults = env.referencedDefaults(ast.refMap)
+	return env.Program(ast, opts...)
+}
+
 // AstToCheckedExpr converts an Ast to an protobuf CheckedExpr value.
 //
 // If the Ast.IsChecked() returns false, this conversion method will return an error.
+//
+// The container resolutions and variable defaults recorded by Env.Check have no representation
+// within the CheckedExpr, and are not retained by the conversion.
 func AstToCheckedExpr(a *Ast) (*exprpb.CheckedExpr, error) {
 	if !a.IsChecked() {
 		return nil, fmt.Errorf("cannot convert unchecked ast")
@@ -245,6 +271,9 @@ func ValueToRefValue(adapter ref.TypeAdapter, v *exprpb.Value) (ref.Val, error)
 	case *exprpb.Value_MapValue:
 		m := v.GetMapValue()
 		entries := make(map[ref.Val]ref.Val)
+		keys := make(map[any]ref.Val)
+		vals := make(map[any]ref.Val)
+		hashable := false
 		for _, entry := range m.Entries {
 			key, err := ValueToRefValue(adapter, entry.Key)
 			if err != nil {
@@ -254,7 +283,17 @@ func ValueToRefValue(adapter ref.TypeAdapter, v *exprpb.Value) (ref.Val, error)
 			if err != nil {
 				return nil, err
 			}
-			entries[key] = pb
+			// Keys such as bytes cannot be used as the keys of Go maps.
+			if _, isHashable := key.(traits.Hashable); isHashable {
+				hashable = true
+			} else {
+				entries[key] = pb
+			}
+			keys[types.HashKey(key)] = key
+			vals[types.HashKey(key)] = pb
+		}
+		if hashable {
+			return types.NewHashKeyMap(adapter, keys, vals), nil
 		}
 		return adapter.NativeToValue(entries), nil
 	case *exprpb.Value_ListValue:
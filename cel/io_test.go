@@ -16,6 +16,7 @@ package cel
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -190,3 +191,93 @@ func TestCheckedExprToAstMissingInfo(t *testing.T) {
 		t.Fatalf("ast2.ResultType() got %v, wanted 'int'", ast.ResultType())
 	}
 }
+
+func TestProgramFromCheckedExpr(t *testing.T) {
+	roll := Function("roll", Overload("roll", []*Type{}, IntType), NonDeterministic())
+	env, err := NewEnv(
+		roll,
+		Variable("x", IntType),
+		Variable("limit", IntType, DefaultValue(10)),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	toCheckedExpr := func(expr string) *exprpb.CheckedExpr {
+		t.Helper()
+		ast, iss := env.Compile(expr)
+		if iss.Err() != nil {
+			t.Fatalf("env.Compile(%q) failed: %v", expr, iss.Err())
+		}
+		checked, err := AstToCheckedExpr(ast)
+		if err != nil {
+			t.Fatalf("AstToCheckedExpr() failed: %v", err)
+		}
+		return checked
+	}
+
+	prg, err := ProgramFromCheckedExpr(env, toCheckedExpr(`x + 1 < limit`))
+	if err != nil {
+		t.Fatalf("ProgramFromCheckedExpr() failed: %v", err)
+	}
+	out, _, err := prg.Eval(map[string]any{"x": 5})
+	if err != nil || out != types.True {
+		t.Errorf("prg.Eval() got %v, %v, wanted true", out, err)
+	}
+
+	// A checked expression without references for its identifiers and calls must be rejected
+	// rather than evaluated with whichever declarations happen to match.
+	unreferenced := toCheckedExpr(`x + 1`)
+	unreferenced.ReferenceMap = map[int64]*exprpb.Reference{}
+	unreferenced.TypeMap = map[int64]*exprpb.Type{unreferenced.GetExpr().GetId(): decls.Int}
+
+	tests := []struct {
+		env     []EnvOption
+		expr    string
+		checked *exprpb.CheckedExpr
+		err     string
+	}{
+		{
+			env:  []EnvOption{Variable("limit", IntType)},
+			expr: `x + 1 < limit`,
+			err:  "undeclared reference to 'x'",
+		},
+		{
+			env:  []EnvOption{Variable("x", StringType), Variable("limit", IntType)},
+			expr: `x + 1 < limit`,
+			err:  "incompatible type for 'x'",
+		},
+		{
+			env:  []EnvOption{roll, DeterministicEval()},
+			expr: `roll() > 3`,
+			err:  "nondeterministic function 'roll'",
+		},
+		{
+			checked: &exprpb.CheckedExpr{},
+			err:     "checked expression must not be empty",
+		},
+		{
+			env:     []EnvOption{Variable("x", IntType)},
+			checked: unreferenced,
+			err:     "missing reference for 'x'",
+		},
+		{
+			env:     []EnvOption{Variable("x", IntType)},
+			checked: unreferenced,
+			err:     "missing overload reference for '_+_'",
+		},
+	}
+	for _, tc := range tests {
+		target, err := NewEnv(tc.env...)
+		if err != nil {
+			t.Fatalf("NewEnv() failed: %v", err)
+		}
+		checked := tc.checked
+		if checked == nil {
+			checked = toCheckedExpr(tc.expr)
+		}
+		_, err = ProgramFromCheckedExpr(target, checked)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("ProgramFromCheckedExpr(%q) got %v, wanted error containing %q", tc.expr, err, tc.err)
+		}
+	}
+}
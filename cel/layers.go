@@ -0,0 +1,137 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DeclarationQuota limits the number of declarations which a layer may add to the environment it
+// extends. A negative limit leaves the corresponding declarations unlimited.
+type DeclarationQuota struct {
+	// MaxVariables is the number of variables the layer may declare.
+	MaxVariables int
+
+	// MaxFunctions is the number of functions the layer may declare overloads for, including new
+	// overloads of functions declared by the environment the layer extends.
+	MaxFunctions int
+}
+
+// LayerUsage describes the declarations added by a layer, as returned by Env.LayerUsage.
+type LayerUsage struct {
+	// Quota is the quota of the layer.
+	Quota DeclarationQuota
+
+	// Variables lists the names of the variables declared by the layer, in sorted order.
+	Variables []string
+
+	// Functions lists the names of the functions for which the layer declares overloads, in
+	// sorted order.
+	Functions []string
+}
+
+// Layer extends the environment with a layer of options whose declarations are limited by a
+// quota, such as the customizations of a tenant on top of an environment shared by all tenants.
+//
+// The quota is enforced against the declarations added by the options of the layer, and continues
+// to be enforced when the layered environment is extended further, so that the declarations added
+// on top of the shared environment never exceed the quota. Redeclaring a variable or overload
+// which the shared environment already declares does not count against the quota.
+//
+// Layers may be nested, in which case every layer's quota is enforced.
+func (e *Env) Layer(quota DeclarationQuota, opts ...EnvOption) (*Env, error) {
+	base, err := newDeclMerger(e)
+	if err != nil {
+		return nil, err
+	}
+	l := &envLayer{quota: quota, base: base}
+	return e.Extend(append([]EnvOption{addLayer(l)}, opts...)...)
+}
+
+// LayerUsage returns the usage of each layer of the environment in the order in which the layers
+// were added, or nil when the environment was not created with Layer.
+func (e *Env) LayerUsage() ([]LayerUsage, error) {
+	if len(e.layers) == 0 {
+		return nil, nil
+	}
+	current, err := newDeclMerger(e)
+	if err != nil {
+		return nil, err
+	}
+	usage := make([]LayerUsage, len(e.layers))
+	for i, l := range e.layers {
+		usage[i] = l.usage(current)
+	}
+	return usage, nil
+}
+
+func addLayer(l *envLayer) EnvOption {
+	return func(e *Env) (*Env, error) {
+		e.layers = append(e.layers, l)
+		return e, nil
+	}
+}
+
+// envLayer records the declarations of the environment a layer extends.
+type envLayer struct {
+	quota DeclarationQuota
+	base  *declMerger
+}
+
+// usage returns the declarations which are present in the current declarations of the layered
+// environment and absent from the base of the layer.
+func (l *envLayer) usage(current *declMerger) LayerUsage {
+	u := LayerUsage{Quota: l.quota}
+	for name := range current.idents {
+		if _, found := l.base.idents[name]; !found {
+			u.Variables = append(u.Variables, name)
+		}
+	}
+	fns := map[string]bool{}
+	for id, d := range current.overloads {
+		if _, found := l.base.overloads[id]; !found && !fns[d.GetName()] {
+			fns[d.GetName()] = true
+			u.Functions = append(u.Functions, d.GetName())
+		}
+	}
+	sort.Strings(u.Variables)
+	sort.Strings(u.Functions)
+	return u
+}
+
+// enforceLayerQuotas returns an error when the declarations of the environment exceed the quota of
+// any of its layers.
+func (e *Env) enforceLayerQuotas() error {
+	if len(e.layers) == 0 {
+		return nil
+	}
+	usage, err := e.LayerUsage()
+	if err != nil {
+		return err
+	}
+	for _, u := range usage {
+		if u.Quota.MaxVariables >= 0 && len(u.Variables) > u.Quota.MaxVariables {
+			return fmt.Errorf("declaration quota exceeded: %d variables declared (%s), limit %d",
+				len(u.Variables), strings.Join(u.Variables, ", "), u.Quota.MaxVariables)
+		}
+		if u.Quota.MaxFunctions >= 0 && len(u.Functions) > u.Quota.MaxFunctions {
+			return fmt.Errorf("declaration quota exceeded: %d functions declared (%s), limit %d",
+				len(u.Functions), strings.Join(u.Functions, ", "), u.Quota.MaxFunctions)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/common/types/ref"
+)
+
+func TestLayer(t *testing.T) {
+	base, err := NewEnv(
+		Variable("shared", StringType),
+		Function("base_fn", Overload("base_fn_string", []*Type{StringType}, BoolType)))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	quota := DeclarationQuota{MaxVariables: 1, MaxFunctions: 1}
+	tenant, err := base.Layer(quota,
+		Variable("shared", StringType),
+		Variable("tenant_var", IntType),
+		Function("size", MemberOverload("tenant_size", []*Type{IntType}, IntType,
+			UnaryBinding(func(v ref.Val) ref.Val { return v }))))
+	if err != nil {
+		t.Fatalf("base.Layer() failed: %v", err)
+	}
+	usage, err := tenant.LayerUsage()
+	if err != nil {
+		t.Fatalf("tenant.LayerUsage() failed: %v", err)
+	}
+	want := []LayerUsage{{Quota: quota, Variables: []string{"tenant_var"}, Functions: []string{"size"}}}
+	if !reflect.DeepEqual(usage, want) {
+		t.Errorf("tenant.LayerUsage() got %v, wanted %v", usage, want)
+	}
+	if _, iss := tenant.Compile(`base_fn(shared) && tenant_var.size() > 0`); iss.Err() != nil {
+		t.Errorf("tenant.Compile() failed: %v", iss.Err())
+	}
+	if usage, _ := base.LayerUsage(); usage != nil {
+		t.Errorf("base.LayerUsage() got %v, wanted nil", usage)
+	}
+
+	_, err = base.Layer(quota, Variable("a", IntType), Variable("b", IntType))
+	if err == nil || err.Error() != "declaration quota exceeded: 2 variables declared (a, b), limit 1" {
+		t.Errorf("base.Layer() got error %v, wanted variable quota error", err)
+	}
+	_, err = tenant.Extend(Function("tenant_fn", Overload("tenant_fn_int", []*Type{IntType}, IntType)))
+	if err == nil || err.Error() != "declaration quota exceeded: 2 functions declared (size, tenant_fn), limit 1" {
+		t.Errorf("tenant.Extend() got error %v, wanted function quota error", err)
+	}
+	unlimited, err := base.Layer(DeclarationQuota{MaxVariables: -1, MaxFunctions: 0},
+		Variable("a", IntType), Variable("b", IntType))
+	if err != nil {
+		t.Fatalf("base.Layer() with unlimited variables failed: %v", err)
+	}
+	nested, err := unlimited.Layer(DeclarationQuota{MaxVariables: 1, MaxFunctions: 1}, Variable("c", IntType))
+	if err != nil {
+		t.Fatalf("unlimited.Layer() failed: %v", err)
+	}
+	usage, err = nested.LayerUsage()
+	if err != nil {
+		t.Fatalf("nested.LayerUsage() failed: %v", err)
+	}
+	if len(usage) != 2 || !reflect.DeepEqual(usage[0].Variables, []string{"a", "b", "c"}) ||
+		!reflect.DeepEqual(usage[1].Variables, []string{"c"}) {
+		t.Errorf("nested.LayerUsage() got %v, wanted usage for both layers", usage)
+	}
+}
@@ -15,11 +15,13 @@
 package cel
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/cel-go/checker"
+	"github.com/google/cel-go/common"
 	"github.com/google/cel-go/common/operators"
 	"github.com/google/cel-go/common/overloads"
 	"github.com/google/cel-go/common/types"
@@ -28,6 +30,8 @@ import (
 	"github.com/google/cel-go/interpreter"
 	"github.com/google/cel-go/interpreter/functions"
 	"github.com/google/cel-go/parser"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 )
 
 // Library provides a collection of EnvOption and ProgramOption values used to configure a CEL
@@ -145,6 +149,17 @@ func (optionalLibrary) CompileOptions() []EnvOption {
 					}
 					return types.OptionalNone
 				}))),
+		Function("optional.ofNullable",
+			Overload("optional_ofNullable", []*Type{paramTypeV}, optionalTypeV,
+				UnaryBinding(func(value ref.Val) ref.Val {
+					if value.Type() == types.NullType {
+						return types.OptionalNone
+					}
+					return types.OptionalOf(value)
+				}))),
+		Function("optional.firstPresent",
+			Overload("optional_firstPresent_list", []*Type{ListType(optionalTypeV)}, optionalTypeV,
+				UnaryBinding(optionalFirstPresent))),
 		Function("optional.none",
 			Overload("optional_none", []*Type{}, optionalTypeV,
 				FunctionBinding(func(values ...ref.Val) ref.Val {
@@ -163,6 +178,14 @@ func (optionalLibrary) CompileOptions() []EnvOption {
 					return types.Bool(opt.HasValue())
 				}))),
 
+		// Combinators which bind the value of an optional to a variable, and which select the first
+		// optional value with a value from a list literal without evaluating the remaining entries.
+		Macros(
+			NewReceiverMacro(optMapMacro, 2, optMap),
+			NewReceiverMacro(optFilterMacro, 2, optFilter),
+			NewReceiverMacro(optFirstPresentMacro, 1, optFirstPresent),
+		),
+
 		// Implementation of 'or' and 'orValue' are special-cased to support short-circuiting in the
 		// evaluation chain.
 		Function("or",
@@ -204,6 +227,109 @@ func enableOptionalSyntax() EnvOption {
 	}
 }
 
+const (
+	optMapMacro          = "optMap"
+	optFilterMacro       = "optFilter"
+	optFirstPresentMacro = "firstPresent"
+	unusedIterVar        = "#unused"
+)
+
+// optMap expands `opt.optMap(x, expr)` into an optional with the result of `expr` when `opt` has a
+// value bound to `x`, and to `optional.none()` otherwise:
+//
+//	opt.hasValue() ? optional.of(<expr with x = opt.value()>) : optional.none()
+func optMap(meh MacroExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+	varName, err := optVarName(meh, optMapMacro, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return meh.GlobalCall(operators.Conditional,
+		meh.ReceiverCall("hasValue", target),
+		meh.GlobalCall("optional.of", optBind(meh, varName, target, args[1])),
+		meh.GlobalCall("optional.none")), nil
+}
+
+// optFilter expands `opt.optFilter(x, pred)` into `opt` when `opt` has a value bound to `x` which
+// satisfies the predicate, and to `optional.none()` otherwise:
+//
+//	opt.hasValue() ? (<pred with x = opt.value()> ? opt : optional.none()) : optional.none()
+func optFilter(meh MacroExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+	varName, err := optVarName(meh, optFilterMacro, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return meh.GlobalCall(operators.Conditional,
+		meh.ReceiverCall("hasValue", target),
+		meh.GlobalCall(operators.Conditional,
+			optBind(meh, varName, target, args[1]),
+			meh.Copy(target),
+			meh.GlobalCall("optional.none")),
+		meh.GlobalCall("optional.none")), nil
+}
+
+// optFirstPresent expands `optional.firstPresent([o1, o2, ...])` into `o1.or(o2).or(...)`, so that
+// the optionals are evaluated in order until one has a value. Arguments other than list literals
+// are left to the `optional.firstPresent` function.
+func optFirstPresent(meh MacroExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+	if target.GetIdentExpr().GetName() != "optional" || args[0].GetListExpr() == nil {
+		return nil, nil
+	}
+	list := args[0].GetListExpr()
+	if len(list.GetOptionalIndices()) != 0 {
+		return nil, nil
+	}
+	elems := list.GetElements()
+	if len(elems) == 0 {
+		return meh.GlobalCall("optional.none"), nil
+	}
+	result := elems[0]
+	for _, elem := range elems[1:] {
+		result = meh.ReceiverCall("or", result, elem)
+	}
+	return result, nil
+}
+
+func optVarName(meh MacroExprHelper, macro string, arg *exprpb.Expr) (string, *common.Error) {
+	if arg.GetIdentExpr() == nil {
+		return "", &common.Error{
+			Message:  fmt.Sprintf("%s() variable name must be a simple identifier", macro),
+			Location: meh.OffsetLocation(arg.GetId()),
+		}
+	}
+	return arg.GetIdentExpr().GetName(), nil
+}
+
+// optBind binds the value of the optional target to the variable within the expression, using a
+// comprehension over an empty range whose accumulator is the variable.
+func optBind(meh MacroExprHelper, varName string, target, expr *exprpb.Expr) *exprpb.Expr {
+	return meh.Fold(unusedIterVar,
+		meh.NewList(),
+		varName,
+		meh.ReceiverCall("value", meh.Copy(target)),
+		meh.LiteralBool(false),
+		meh.Ident(varName),
+		expr)
+}
+
+// optionalFirstPresent returns the first optional within the list which has a value.
+func optionalFirstPresent(list ref.Val) ref.Val {
+	l, isLister := list.(traits.Lister)
+	if !isLister {
+		return types.MaybeNoSuchOverloadErr(list)
+	}
+	for it := l.Iterator(); it.HasNext() == types.True; {
+		elem := it.Next()
+		opt, isOpt := elem.(*types.Optional)
+		if !isOpt {
+			return types.MaybeNoSuchOverloadErr(elem)
+		}
+		if opt.HasValue() {
+			return opt
+		}
+	}
+	return types.OptionalNone
+}
+
 func decorateOptionalOr(i interpreter.Interpretable) (interpreter.Interpretable, error) {
 	call, ok := i.(interpreter.InterpretableCall)
 	if !ok {
@@ -303,6 +429,33 @@ func (timeUTCLibrary) ProgramOptions() []ProgramOption {
 	return []ProgramOption{}
 }
 
+// aggregateComparisonLibrary declares the ordering comparisons of lists and maps, which are
+// evaluated by the interpreter.CompareAggregates decorator of the program.
+type aggregateComparisonLibrary struct{}
+
+func (aggregateComparisonLibrary) CompileOptions() []EnvOption {
+	// The elements are compared at evaluation time, so that lists and maps whose elements are
+	// comparable with one another, such as ints and doubles, may be compared regardless of their
+	// static types.
+	listType := ListType(DynType)
+	mapType := MapType(DynType, DynType)
+	comparison := func(op, id string) EnvOption {
+		return Function(op,
+			Overload(id+"_list", []*Type{listType, listType}, BoolType),
+			Overload(id+"_map", []*Type{mapType, mapType}, BoolType))
+	}
+	return []EnvOption{
+		comparison(operators.Less, "less"),
+		comparison(operators.LessEquals, "less_equals"),
+		comparison(operators.Greater, "greater"),
+		comparison(operators.GreaterEquals, "greater_equals"),
+	}
+}
+
+func (aggregateComparisonLibrary) ProgramOptions() []ProgramOption {
+	return []ProgramOption{}
+}
+
 // Declarations and functions which enable using UTC on time.Time inputs when the timezone is unspecified
 // in the CEL expression.
 var (
@@ -0,0 +1,115 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/checker"
+	"github.com/google/cel-go/common"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Warning describes a non-fatal finding within a type-checked expression, such as a local binding
+// which is never used.
+type Warning struct {
+	// ID is the id of the expression to which the warning applies, used to locate the warning
+	// within the expression source.
+	ID int64
+
+	// Message describes the finding.
+	Message string
+}
+
+// Linter inspects a type-checked Ast and returns the warnings found within it.
+type Linter func(ast *Ast) []Warning
+
+// Linters configures linters which are run against every Ast which type-checks successfully within
+// the environment. The warnings the linters return are reported by Ast.Warnings and do not cause
+// checking to fail.
+func Linters(linters ...Linter) EnvOption {
+	return func(e *Env) (*Env, error) {
+		e.linters = append(e.linters, linters...)
+		return e, nil
+	}
+}
+
+// lint runs the linters of the environment against the checked Ast, returning nil when there are
+// no warnings.
+func (e *Env) lint(ast *Ast) []common.Error {
+	if len(e.linters) == 0 {
+		return nil
+	}
+	warnings := common.NewErrors(ast.Source())
+	for _, l := range e.linters {
+		for _, w := range l(ast) {
+			warnings.ReportError(exprLocation(ast, w.ID), "%s", w.Message)
+		}
+	}
+	if len(warnings.GetErrors()) == 0 {
+		return nil
+	}
+	return warnings.GetErrors()
+}
+
+// HeterogeneousListLiterals returns a Linter which warns about list literals whose elements have
+// incompatible types, such as `[1, 2.0]`, and which are therefore typed as list(dyn) rather than
+// as a list of a more useful element type. The warning reports the types and locations of the
+// first two elements whose types differ.
+func HeterogeneousListLiterals() Linter {
+	return func(ast *Ast) []Warning {
+		var warnings []Warning
+		visitExpr(ast.Expr(), func(e *exprpb.Expr) {
+			list := e.GetListExpr()
+			if list == nil || ast.typeMap[e.GetId()].GetListType().GetElemType().GetDyn() == nil {
+				return
+			}
+			optionals := make(map[int32]bool, len(list.GetOptionalIndices()))
+			for _, idx := range list.GetOptionalIndices() {
+				optionals[idx] = true
+			}
+			var first *exprpb.Expr
+			var firstType *exprpb.Type
+			for i, elem := range list.GetElements() {
+				elemType := ast.typeMap[elem.GetId()]
+				if params := elemType.GetAbstractType().GetParameterTypes(); optionals[int32(i)] && len(params) == 1 {
+					elemType = params[0]
+				}
+				if elemType.GetDyn() != nil {
+					continue
+				}
+				if first == nil {
+					first, firstType = elem, elemType
+					continue
+				}
+				if checker.FormatCheckedType(elemType) == checker.FormatCheckedType(firstType) {
+					continue
+				}
+				firstLoc := exprLocation(ast, first.GetId())
+				elemLoc := exprLocation(ast, elem.GetId())
+				msg := fmt.Sprintf("list elements have incompatible types: '%s' at %d:%d and '%s' at %d:%d, so the list is typed as list(dyn)",
+					checker.FormatCheckedType(firstType), firstLoc.Line(), firstLoc.Column()+1,
+					checker.FormatCheckedType(elemType), elemLoc.Line(), elemLoc.Column()+1)
+				if hint := checker.UnificationHint(firstType, elemType); hint != "" {
+					msg += "; " + hint
+				}
+				warnings = append(warnings, Warning{ID: e.GetId(), Message: msg})
+				return
+			}
+		})
+		return warnings
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/common/operators"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestLinters(t *testing.T) {
+	constCond := func(ast *Ast) []Warning {
+		var warnings []Warning
+		visitExpr(ast.Expr(), func(e *exprpb.Expr) {
+			if call := e.GetCallExpr(); call != nil && call.GetFunction() == operators.Conditional &&
+				call.GetArgs()[0].GetConstExpr() != nil {
+				warnings = append(warnings, Warning{ID: e.GetId(), Message: "constant condition"})
+			}
+		})
+		return warnings
+	}
+	env, err := NewEnv(Variable("x", IntType), Linters(constCond))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile("x > 0\n  && (true ? x : 1) < 10")
+	// Warnings are not issues, so a successful compilation still reports no issues.
+	if iss != nil {
+		t.Fatalf("env.Compile() got issues %v, wanted nil", iss)
+	}
+	warnings := ast.Warnings()
+	if len(warnings) != 1 {
+		t.Fatalf("ast.Warnings() got %v, wanted a single warning", warnings)
+	}
+	if warnings[0].Message != "constant condition" || warnings[0].Location.Line() != 2 {
+		t.Errorf("ast.Warnings() got %v, wanted 'constant condition' on line 2", warnings[0])
+	}
+
+	ast, iss = env.Compile("x > 0")
+	if iss != nil || len(ast.Warnings()) != 0 {
+		t.Errorf("env.Compile('x > 0') got %v, warnings %v, wanted none", iss, ast.Warnings())
+	}
+	_, iss = env.Compile("(true ? x : 'str') > 0")
+	if iss.Err() == nil {
+		t.Errorf("env.Compile() got %v, wanted a check error", iss)
+	}
+}
+
+func TestHeterogeneousListLiterals(t *testing.T) {
+	env, err := NewEnv(
+		OptionalTypes(),
+		Variable("o", OptionalType(IntType)),
+		Variable("d", DynType),
+		Linters(HeterogeneousListLiterals()))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{expr: `[1, 2.0]`, want: []string{"1:1: list elements have incompatible types: 'int' at 1:2 and 'double' at 1:5, " +
+			"so the list is typed as list(dyn); numeric types are not converted implicitly, convert the values with int(), uint(), or double()"}},
+		{expr: `[o, 1]`, want: []string{"1:1: list elements have incompatible types: 'optional(int)' at 1:2 and 'int' at 1:5, " +
+			"so the list is typed as list(dyn); wrap the non-optional value with optional.of()"}},
+		{expr: `[[1, 'a'], [2]]`, want: []string{"1:2: list elements have incompatible types: 'int' at 1:3 and 'string' at 1:6, " +
+			"so the list is typed as list(dyn)"}},
+		{expr: `[1, d, 2]`},
+		{expr: `[1, ?o, 2]`},
+		{expr: `[o, optional.none()]`},
+		{expr: `[]`},
+	}
+	for _, tc := range tests {
+		tst := tc
+		t.Run(tst.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tst.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%q) failed: %v", tst.expr, iss.Err())
+			}
+			var got []string
+			for _, w := range ast.Warnings() {
+				got = append(got, fmt.Sprintf("%d:%d: %s", w.Location.Line(), w.Location.Column()+1, w.Message))
+			}
+			if !reflect.DeepEqual(got, tst.want) {
+				t.Errorf("env.Compile(%q) got warnings %v, wanted %v", tst.expr, got, tst.want)
+			}
+		})
+	}
+}
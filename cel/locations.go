@@ -0,0 +1,85 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+)
+
+// EvalError is an evaluation error annotated with the expression id and source location of the
+// subexpression which produced it, as reported when the OptErrorLocations option is set.
+//
+// The error is wrapped within the types.Err value produced by the evaluation, and may be extracted
+// from either the value or the error returned by Eval with errors.As.
+type EvalError struct {
+	// ExprID is the id of the subexpression which produced the error.
+	ExprID int64
+
+	// Location is the location of the subexpression within the source, or common.NoLocation if
+	// the location is not known.
+	Location common.Location
+
+	description string
+	cause       error
+}
+
+// Error implements the error interface method, prefixing the message of the original error with
+// the location of the subexpression which produced it.
+func (e *EvalError) Error() string {
+	if e.Location == common.NoLocation {
+		return fmt.Sprintf("#%d: %v", e.ExprID, e.cause)
+	}
+	return fmt.Sprintf("%s:%d:%d: %v",
+		e.description, e.Location.Line(), e.Location.Column()+1, e.cause)
+}
+
+// Unwrap returns the original error.
+func (e *EvalError) Unwrap() error {
+	return e.cause
+}
+
+// locateErrors returns a patcher which annotates the errors produced by the evaluation with the
+// location of the subexpression which first produced them.
+//
+// Errors propagate from the subexpression which produced them to the expressions which contain
+// it, and so only errors which have not already been annotated are located.
+func locateErrors(ast *Ast) interpreter.EvalPatcher {
+	description := "<input>"
+	if ast.Source() != nil {
+		description = ast.Source().Description()
+	}
+	return func(id int64, programStep any, val ref.Val) (ref.Val, bool) {
+		err, ok := val.(*types.Err)
+		if !ok {
+			return val, false
+		}
+		var located *EvalError
+		if errors.As(err, &located) {
+			return val, false
+		}
+		return types.WrapErr(&EvalError{
+			ExprID:      id,
+			Location:    exprLocation(ast, id),
+			description: description,
+			cause:       err,
+		}), true
+	}
+}
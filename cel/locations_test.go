@@ -0,0 +1,112 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+)
+
+func TestErrorLocations(t *testing.T) {
+	env, err := NewEnv(
+		Variable("a", IntType),
+		Variable("m", MapType(StringType, IntType)),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		expr    string
+		opts    []EvalOption
+		wantErr string
+		wantID  int64
+	}{
+		{
+			expr:    `a + 10 / (a - 1)`,
+			wantErr: "<input>:1:8: division by zero",
+			wantID:  4,
+		},
+		{
+			expr:    "a > 0 &&\n  m['missing'] == 1",
+			wantErr: "<input>:2:4: no such key: missing",
+			wantID:  5,
+		},
+		{
+			expr:    `[1, 2].all(x, 10 / (x - a) > 0)`,
+			wantErr: "<input>:1:18: division by zero",
+		},
+		{
+			expr:    `a + 10 / (a - 1)`,
+			opts:    []EvalOption{OptExhaustiveEval},
+			wantErr: "<input>:1:8: division by zero",
+			wantID:  4,
+		},
+		{
+			expr:    `a + 10 / 0`,
+			opts:    []EvalOption{OptOptimize},
+			wantErr: "<input>:1:8: division by zero",
+			wantID:  4,
+		},
+		{
+			expr:    `a + 10 / (a - 1)`,
+			opts:    []EvalOption{OptScalarEval},
+			wantErr: "<input>:1:8: division by zero",
+			wantID:  4,
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%v) failed: %v", tc.expr, iss.Err())
+			}
+			prg, err := env.Program(ast, EvalOptions(append(tc.opts, OptErrorLocations)...))
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			out, _, err := prg.Eval(map[string]any{"a": 1, "m": map[string]int{}})
+			if err == nil || err.Error() != tc.wantErr {
+				t.Fatalf("prg.Eval() got %v, %v, wanted error %q", out, err, tc.wantErr)
+			}
+			if !types.IsError(out) || out.(*types.Err).Error() != tc.wantErr {
+				t.Errorf("prg.Eval() got value %v, wanted error %q", out, tc.wantErr)
+			}
+			var evalErr *EvalError
+			if !errors.As(err, &evalErr) {
+				t.Fatalf("errors.As(%v, *EvalError) failed", err)
+			}
+			if tc.wantID != 0 && evalErr.ExprID != tc.wantID {
+				t.Errorf("got error at expression id %d, wanted %d", evalErr.ExprID, tc.wantID)
+			}
+		})
+	}
+
+	// Errors are reported without locations unless the option is set.
+	ast, iss := env.Compile(`a + 10 / (a - 1)`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	_, _, err = prg.Eval(map[string]any{"a": 1})
+	if err == nil || err.Error() != "division by zero" {
+		t.Errorf("prg.Eval() got error %v, wanted division by zero", err)
+	}
+}
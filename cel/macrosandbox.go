@@ -0,0 +1,298 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/debug"
+	"github.com/google/cel-go/parser"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// ExprKind enumerates the kinds of expression node which a macro expansion may produce.
+type ExprKind int
+
+const (
+	// LiteralExpr is a constant literal.
+	LiteralExpr ExprKind = iota + 1
+
+	// IdentExpr is an identifier.
+	IdentExpr
+
+	// SelectExpr is a field selection or presence test.
+	SelectExpr
+
+	// CallExpr is a function call.
+	CallExpr
+
+	// ListExpr is a list literal.
+	ListExpr
+
+	// StructExpr is a map or message literal.
+	StructExpr
+
+	// ComprehensionExpr is a comprehension.
+	ComprehensionExpr
+)
+
+// String returns the name of the kind of expression.
+func (k ExprKind) String() string {
+	switch k {
+	case LiteralExpr:
+		return "literal"
+	case IdentExpr:
+		return "identifier"
+	case SelectExpr:
+		return "select"
+	case CallExpr:
+		return "call"
+	case ListExpr:
+		return "list"
+	case StructExpr:
+		return "struct"
+	case ComprehensionExpr:
+		return "comprehension"
+	}
+	return fmt.Sprintf("ExprKind(%d)", int(k))
+}
+
+// MacroPolicy describes the expansions which SandboxedMacros permits.
+type MacroPolicy struct {
+	// BannedKinds lists the kinds of expression which the expansion may not introduce. The
+	// arguments of the macro call may contain expressions of any kind.
+	BannedKinds []ExprKind
+
+	// AllowedIdents lists the identifiers which the expansion may introduce without binding them
+	// within a comprehension of its own, such as the names of types. Identifiers are otherwise
+	// only permitted within the arguments of the macro call.
+	AllowedIdents []string
+}
+
+// SandboxedMacros adds macros supplied by untrusted sources, such as plugins, to the environment
+// after validating their expansions against the policy.
+//
+// Each expansion is checked for expressions of the banned kinds, for identifiers which are neither
+// bound by a comprehension within the expansion nor allowed by the policy, and for expression ids
+// which collide with the ids of other expressions, e.g. ids assigned by the expander rather than
+// allocated by the MacroExprHelper. The expander is
+// invoked with identifiers as arguments before the macro is admitted, and the option fails when
+// the resulting expansion violates the policy or the expander panics.
+//
+// Since expansions depend on their arguments, each expansion is also checked when an expression
+// is parsed, in which case a violation or a panic is reported as a parse error.
+func SandboxedMacros(policy MacroPolicy, macros ...Macro) EnvOption {
+	return func(e *Env) (*Env, error) {
+		sandboxed := make([]Macro, len(macros))
+		for i, m := range macros {
+			if err := probeMacro(policy, m); err != nil {
+				return nil, err
+			}
+			sandboxed[i] = sandboxMacro(policy, m, nil)
+		}
+		return Macros(sandboxed...)(e)
+	}
+}
+
+// sandboxMacro returns a macro which validates the expansions of the macro against the policy,
+// reporting violations to the given function when it is non-nil.
+func sandboxMacro(policy MacroPolicy, m Macro, report func(error)) Macro {
+	expander := m.Expander()
+	sandboxed := func(eh MacroExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (
+		expr *exprpb.Expr, macroErr *common.Error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err := fmt.Errorf("macro '%s' expansion panicked: %v", m.Function(), r)
+				if report != nil {
+					report(err)
+				}
+				expr, macroErr = nil, &common.Error{Message: err.Error()}
+			}
+		}()
+		// Every id allocated by the helper during the expansion follows the id of this expression.
+		floor := eh.LiteralBool(false).GetId()
+		expr, macroErr = expander(eh, target, args)
+		if macroErr != nil || expr == nil {
+			return expr, macroErr
+		}
+		v := &expansionValidator{
+			policy:  policy,
+			floor:   floor,
+			inputs:  map[*exprpb.Expr]bool{},
+			ids:     map[int64]*exprpb.Expr{},
+			allowed: map[string]bool{},
+		}
+		for _, ident := range policy.AllowedIdents {
+			v.allowed[ident] = true
+		}
+		v.collectInputs(target)
+		for _, arg := range args {
+			v.collectInputs(arg)
+		}
+		WalkExpr(expr, v.validate, nil)
+		if len(v.violations) == 0 {
+			return expr, nil
+		}
+		err := fmt.Errorf("macro '%s' expansion rejected: %s", m.Function(), strings.Join(v.violations, "; "))
+		if report != nil {
+			report(err)
+		}
+		return nil, &common.Error{Message: err.Error()}
+	}
+	switch {
+	case m.ArgCount() == 0 && m.IsReceiverStyle():
+		return parser.NewReceiverVarArgMacro(m.Function(), sandboxed)
+	case m.ArgCount() == 0:
+		return parser.NewGlobalVarArgMacro(m.Function(), sandboxed)
+	case m.IsReceiverStyle():
+		return parser.NewReceiverMacro(m.Function(), m.ArgCount(), sandboxed)
+	default:
+		return parser.NewGlobalMacro(m.Function(), m.ArgCount(), sandboxed)
+	}
+}
+
+// probeMacro expands a call to the macro with identifiers as arguments, returning an error if the
+// expansion violates the policy.
+func probeMacro(policy MacroPolicy, m Macro) error {
+	argCounts := []int{m.ArgCount()}
+	if m.ArgCount() == 0 {
+		// Probe var-arg macros with several argument counts.
+		argCounts = []int{0, 1, 2, 3}
+	}
+	var violation error
+	probe := sandboxMacro(policy, m, func(err error) { violation = err })
+	p, err := parser.NewParser(parser.Macros(probe))
+	if err != nil {
+		return err
+	}
+	for _, n := range argCounts {
+		args := make([]string, n)
+		for i := range args {
+			args[i] = fmt.Sprintf("arg%d", i)
+		}
+		call := fmt.Sprintf("%s(%s)", m.Function(), strings.Join(args, ", "))
+		if m.IsReceiverStyle() {
+			call = "target." + call
+		}
+		// Expanders may reject the arguments as malformed, which is not a violation.
+		p.Parse(common.NewTextSource(call))
+		if violation != nil {
+			return violation
+		}
+	}
+	return nil
+}
+
+// expansionValidator checks an expansion against a macro policy.
+type expansionValidator struct {
+	policy MacroPolicy
+	// floor is the id which precedes the ids allocated during the expansion.
+	floor int64
+	// inputs holds the expressions of the target and arguments of the macro call.
+	inputs map[*exprpb.Expr]bool
+	// copies holds the debug strings of the inputs, computed when first needed.
+	copies map[string]bool
+	// ids holds the expressions of the expansion by id.
+	ids        map[int64]*exprpb.Expr
+	allowed    map[string]bool
+	violations []string
+}
+
+func (v *expansionValidator) collectInputs(e *exprpb.Expr) {
+	if e == nil {
+		return
+	}
+	v.inputs[e] = true
+	for _, c := range exprChildren(e) {
+		v.collectInputs(c)
+	}
+}
+
+// validate checks the expression, which is within an argument of the macro call when it is one
+// of the inputs.
+func (v *expansionValidator) validate(e *exprpb.Expr, scope *ExprScope) bool {
+	id := e.GetId()
+	// The same expression may appear more than once, as with the accumulator of a comprehension,
+	// but distinct expressions must not share an id.
+	if other, found := v.ids[id]; found && other != e {
+		v.violatef("expression id %d is used by more than one expression", id)
+	}
+	v.ids[id] = e
+	if v.inputs[e] {
+		return true
+	}
+	if id <= v.floor {
+		v.violatef("expression id %d was not allocated by the macro helper", id)
+	}
+	kind := exprKind(e)
+	banned := false
+	for _, k := range v.policy.BannedKinds {
+		banned = banned || kind == k
+	}
+	unbound := kind == IdentExpr &&
+		!scope.IsLocal(e.GetIdentExpr().GetName()) && !v.allowed[e.GetIdentExpr().GetName()]
+	// Copies of the arguments made with MacroExprHelper.Copy are subject to the same checks as
+	// the arguments themselves.
+	if (banned || unbound) && v.isCopy(e) {
+		v.collectInputs(e)
+		return true
+	}
+	if banned {
+		v.violatef("%s expressions are not permitted", kind)
+	}
+	if unbound {
+		v.violatef("identifier '%s' is unbound", e.GetIdentExpr().GetName())
+	}
+	return true
+}
+
+// isCopy returns whether the expression is structurally identical to one of the inputs.
+func (v *expansionValidator) isCopy(e *exprpb.Expr) bool {
+	if v.copies == nil {
+		v.copies = make(map[string]bool, len(v.inputs))
+		for in := range v.inputs {
+			v.copies[debug.ToDebugString(in)] = true
+		}
+	}
+	return v.copies[debug.ToDebugString(e)]
+}
+
+func (v *expansionValidator) violatef(format string, args ...any) {
+	v.violations = append(v.violations, fmt.Sprintf(format, args...))
+}
+
+// exprKind returns the kind of the expression.
+func exprKind(e *exprpb.Expr) ExprKind {
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_ConstExpr:
+		return LiteralExpr
+	case *exprpb.Expr_IdentExpr:
+		return IdentExpr
+	case *exprpb.Expr_SelectExpr:
+		return SelectExpr
+	case *exprpb.Expr_CallExpr:
+		return CallExpr
+	case *exprpb.Expr_ListExpr:
+		return ListExpr
+	case *exprpb.Expr_StructExpr:
+		return StructExpr
+	case *exprpb.Expr_ComprehensionExpr:
+		return ComprehensionExpr
+	}
+	return 0
+}
@@ -0,0 +1,146 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestSandboxedMacros(t *testing.T) {
+	twice := NewGlobalMacro("twice", 1,
+		func(eh MacroExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+			return eh.GlobalCall(operators.Add, args[0], eh.Copy(args[0])), nil
+		})
+	env, err := NewEnv(
+		Variable("x", IntType),
+		SandboxedMacros(MacroPolicy{BannedKinds: []ExprKind{StructExpr}},
+			twice, AllMacro, ExistsMacro, MapMacro, FilterMacro, HasMacro),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	out, err := interpret(t, env, `twice(x) == 4 && [1, 2].all(i, i > 0) && [x].map(i, i * 2) == [4]`,
+		map[string]any{"x": 2})
+	if err != nil {
+		t.Fatalf("interpret() failed: %v", err)
+	}
+	if out != types.True {
+		t.Errorf("interpret() got %v, wanted true", out)
+	}
+
+	// Violations which are apparent when the macro is admitted.
+	tests := []struct {
+		name     string
+		policy   MacroPolicy
+		expander MacroExpander
+		err      string
+	}{
+		{
+			name: "unbound identifier",
+			expander: func(eh MacroExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+				return eh.GlobalCall(operators.Add, args[0], eh.Ident("secret")), nil
+			},
+			err: "identifier 'secret' is unbound",
+		},
+		{
+			name:   "allowed identifier",
+			policy: MacroPolicy{AllowedIdents: []string{"int"}},
+			expander: func(eh MacroExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+				return eh.GlobalCall(operators.Equals, eh.GlobalCall("type", args[0]), eh.Ident("int")), nil
+			},
+		},
+		{
+			name: "colliding ids",
+			expander: func(eh MacroExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+				one := eh.LiteralInt(1)
+				two := eh.LiteralInt(2)
+				two.Id = one.GetId()
+				return eh.GlobalCall(operators.Add, args[0], eh.GlobalCall(operators.Add, one, two)), nil
+			},
+			err: "is used by more than one expression",
+		},
+		{
+			name: "fabricated id",
+			expander: func(eh MacroExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+				return &exprpb.Expr{Id: 1, ExprKind: &exprpb.Expr_ConstExpr{
+					ConstExpr: &exprpb.Constant{ConstantKind: &exprpb.Constant_BoolValue{BoolValue: true}}}}, nil
+			},
+			err: "was not allocated by the macro helper",
+		},
+		{
+			name:   "banned kind",
+			policy: MacroPolicy{BannedKinds: []ExprKind{StructExpr}},
+			expander: func(eh MacroExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+				return eh.NewMap(eh.NewMapEntry(eh.LiteralString("k"), args[0], false)), nil
+			},
+			err: "struct expressions are not permitted",
+		},
+		{
+			name: "panic",
+			expander: func(eh MacroExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+				panic("boom")
+			},
+			err: "expansion panicked: boom",
+		},
+		{
+			name: "malformed arguments",
+			expander: func(eh MacroExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+				return nil, &common.Error{Message: "argument must be a literal"}
+			},
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewEnv(SandboxedMacros(tc.policy, NewGlobalMacro("probe", 1, tc.expander)))
+			if tc.err == "" {
+				if err != nil {
+					t.Errorf("NewEnv() failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("NewEnv() got %v, wanted error containing %q", err, tc.err)
+			}
+		})
+	}
+
+	// Violations which depend on the arguments are reported when the expression is parsed.
+	sneaky := NewGlobalMacro("sneaky", 1,
+		func(eh MacroExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+			if args[0].GetConstExpr() != nil {
+				return eh.GlobalCall(operators.Add, args[0], eh.Ident("secret")), nil
+			}
+			return eh.Copy(args[0]), nil
+		})
+	env, err = NewEnv(Variable("x", IntType), SandboxedMacros(MacroPolicy{}, sneaky))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	if _, iss := env.Compile(`sneaky(x)`); iss.Err() != nil {
+		t.Errorf("env.Compile(`sneaky(x)`) failed: %v", iss.Err())
+	}
+	_, iss := env.Compile(`sneaky(1)`)
+	if iss.Err() == nil || !strings.Contains(iss.Err().Error(), "macro 'sneaky' expansion rejected") {
+		t.Errorf("env.Compile(`sneaky(1)`) got %v, wanted a rejected expansion", iss.Err())
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// OverloadIsPure declares that the overload always produces the same result for the same
+// arguments and has no side effects, so that its results may be memoized by programs created with
+// the MemoizePureCalls or SharedCallCache options.
+func OverloadIsPure() OverloadOpt {
+	return func(o *overloadDecl) (*overloadDecl, error) {
+		o.pure = true
+		return o, nil
+	}
+}
+
+// MemoizePureCalls configures the program to memoize the results of calls to pure overloads for
+// the duration of each evaluation, so that repeated calls with identical arguments, such as a
+// geo lookup referenced by several clauses of a policy, are only computed once.
+//
+// Memoized results bypass the guards configured via GuardOverloads.
+func MemoizePureCalls() ProgramOption {
+	return func(p *prog) (*prog, error) {
+		p.memoizeCalls = true
+		return p, nil
+	}
+}
+
+// SharedCallCache configures the program to memoize the results of calls to pure overloads within
+// the cache, which may be shared across evaluations and programs.
+//
+// Memoized results bypass the guards configured via GuardOverloads.
+func SharedCallCache(cache *CallCache) ProgramOption {
+	return func(p *prog) (*prog, error) {
+		p.memoizeCalls = true
+		p.callCache = cache
+		return p, nil
+	}
+}
+
+// pureOverloads returns the ids of the pure overloads of the environment, along with the names of
+// the functions whose overloads are all pure.
+func (e *Env) pureOverloads() []string {
+	var ids []string
+	for name, fn := range e.functions {
+		allPure := len(fn.overloads) > 0
+		for _, o := range fn.overloads {
+			if o.pure {
+				ids = append(ids, o.id)
+			} else {
+				allPure = false
+			}
+		}
+		if allPure {
+			ids = append(ids, name)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// CallCache memoizes the results of calls to pure overloads across evaluations.
+//
+// Results expire after the time-to-live, and the cache holds at most the maximum number of
+// entries, evicting the entries closest to expiry when full. A CallCache is safe for concurrent
+// use.
+type CallCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]callCacheEntry
+	now        func() time.Time
+}
+
+type callCacheEntry struct {
+	val     ref.Val
+	expires time.Time
+}
+
+// NewCallCache creates a CallCache whose entries expire after the ttl, and which holds at most
+// maxEntries results. A non-positive maxEntries leaves the number of entries unbounded.
+func NewCallCache(ttl time.Duration, maxEntries int) *CallCache {
+	return &CallCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    map[string]callCacheEntry{},
+		now:        time.Now,
+	}
+}
+
+// Get implements the interpreter.CallMemo interface method.
+func (c *CallCache) Get(key string) (ref.Val, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, found := c.entries[key]
+	if !found {
+		return nil, false
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.val, true
+}
+
+// Put implements the interpreter.CallMemo interface method.
+func (c *CallCache) Put(key string, val ref.Val) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if _, found := c.entries[key]; !found && c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		c.evict(now)
+	}
+	c.entries[key] = callCacheEntry{val: val, expires: now.Add(c.ttl)}
+}
+
+// Len returns the number of entries within the cache, including expired entries which have not
+// yet been evicted.
+func (c *CallCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// evict removes the expired entries, or the entry closest to expiry when none have expired.
+func (c *CallCache) evict(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.expires.Before(oldest) {
+			oldestKey, oldest = key, entry.expires
+		}
+	}
+	if len(c.entries) >= c.maxEntries {
+		delete(c.entries, oldestKey)
+	}
+}
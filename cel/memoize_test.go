@@ -0,0 +1,136 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+)
+
+func TestMemoizePureCalls(t *testing.T) {
+	calls := map[string]int{}
+	env, err := NewEnv(
+		Variable("ips", ListType(StringType)),
+		Function("geo",
+			Overload("geo_string", []*Type{StringType}, StringType,
+				OverloadIsPure(),
+				UnaryBinding(func(arg ref.Val) ref.Val {
+					calls[string(arg.(types.String))]++
+					return types.String("region-" + string(arg.(types.String)))
+				})),
+		),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`geo('1.1.1.1') != 'blocked' && ips.all(ip, geo(ip) == geo('1.1.1.1') || geo(ip) != 'blocked')`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	vars := map[string]any{"ips": []string{"1.1.1.1", "2.2.2.2", "2.2.2.2"}}
+
+	prg, err := env.Program(ast, MemoizePureCalls(), EvalOptions(OptCacheAttributes))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	for i := 1; i <= 2; i++ {
+		out, _, err := prg.Eval(vars)
+		if err != nil || out != types.True {
+			t.Fatalf("prg.Eval() got %v, %v, wanted true", out, err)
+		}
+		// Results are memoized per evaluation.
+		if want := map[string]int{"1.1.1.1": i, "2.2.2.2": i}; !reflect.DeepEqual(calls, want) {
+			t.Errorf("geo() calls got %v, wanted %v", calls, want)
+		}
+	}
+
+	calls = map[string]int{}
+	cache := NewCallCache(time.Minute, 10)
+	for _, opts := range [][]ProgramOption{
+		{SharedCallCache(cache)},
+		{SharedCallCache(cache), EvalOptions(OptTrackState)},
+	} {
+		prg, err = env.Program(ast, opts...)
+		if err != nil {
+			t.Fatalf("env.Program() failed: %v", err)
+		}
+		out, _, err := prg.Eval(vars)
+		if err != nil || out != types.True {
+			t.Fatalf("prg.Eval() got %v, %v, wanted true", out, err)
+		}
+	}
+	// Results are shared across evaluations and programs.
+	if want := map[string]int{"1.1.1.1": 1, "2.2.2.2": 1}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("geo() calls got %v, wanted %v", calls, want)
+	}
+	if cache.Len() != 2 {
+		t.Errorf("cache.Len() got %d, wanted 2", cache.Len())
+	}
+
+	// Calls whose arguments format identically must not share memoized results.
+	env, err = NewEnv(
+		Function("count",
+			Overload("count_list", []*Type{ListType(DynType)}, IntType,
+				OverloadIsPure(),
+				UnaryBinding(func(arg ref.Val) ref.Val {
+					return arg.(traits.Sizer).Size()
+				})),
+		),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss = env.Compile(`[count(['a b']), count(['a', 'b'])]`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err = env.Program(ast, MemoizePureCalls())
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	out, _, err := prg.Eval(NoVars())
+	if err != nil {
+		t.Fatalf("prg.Eval() failed: %v", err)
+	}
+	want := types.DefaultTypeAdapter.NativeToValue([]int{1, 2})
+	if out.Equal(want) != types.True {
+		t.Errorf("prg.Eval() got %v, wanted %v", out, want)
+	}
+}
+
+func TestCallCacheExpiry(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := NewCallCache(time.Minute, 2)
+	cache.now = func() time.Time { return now }
+	cache.Put("a", types.Int(1))
+	now = now.Add(10 * time.Second)
+	cache.Put("b", types.Int(2))
+	cache.Put("c", types.Int(3))
+	if _, found := cache.Get("a"); found {
+		t.Error("cache.Get(a) found the entry closest to expiry after eviction")
+	}
+	if val, found := cache.Get("b"); !found || val != types.Int(2) {
+		t.Errorf("cache.Get(b) got %v, %v, wanted 2", val, found)
+	}
+	now = now.Add(time.Minute)
+	if _, found := cache.Get("c"); found {
+		t.Error("cache.Get(c) found an expired entry")
+	}
+}
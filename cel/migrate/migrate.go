@@ -0,0 +1,467 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package migrate rewrites stored CEL expressions as the libraries they depend on evolve.
+//
+// Migrations are expressed as versioned rules which rewrite the parsed form of an expression,
+// e.g. renaming a function or reordering its arguments. A Migrator applies the rules newer than
+// the version an expression was last migrated to, formats the result back into CEL source, and
+// type-checks it against the current environment, reporting the rules applied to each expression
+// and any expressions which no longer compile.
+package migrate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/parser"
+
+	"google.golang.org/protobuf/proto"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Rewriter rewrites an expression node in place and reports whether the node was changed.
+//
+// Nodes are visited in post-order, so the nodes introduced by a rewrite are not visited again.
+// New nodes must be assigned ids produced by the nextID function.
+type Rewriter func(e *exprpb.Expr, nextID func() int64) bool
+
+// Rule is a migration which rewrites the expressions last migrated to an earlier version.
+type Rule struct {
+	// Name identifies the rule within migration reports.
+	Name string
+
+	// Version is the version to which the rule migrates expressions.
+	Version int
+
+	rewrite Rewriter
+	applies func(*cel.Ast) bool
+	// bind returns the rewriter for a specific expression when the rewrite depends on the
+	// expression as a whole, such as on the scopes of its local variables.
+	bind func(e *exprpb.Expr) (Rewriter, error)
+}
+
+// NewRule returns a rule which rewrites each node of an expression using the rewriter.
+func NewRule(name string, version int, rewrite Rewriter) *Rule {
+	return &Rule{Name: name, Version: version, rewrite: rewrite}
+}
+
+// When restricts the rule to the parsed expressions for which the predicate holds, as evaluated
+// prior to the application of the rule.
+func (r *Rule) When(pred func(ast *cel.Ast) bool) *Rule {
+	r.applies = pred
+	return r
+}
+
+// RenameFunction returns a rule which renames the calls of a function, whether written in global
+// or receiver style.
+func RenameFunction(version int, from, to string) *Rule {
+	name := fmt.Sprintf("rename function %s to %s", from, to)
+	return NewRule(name, version, func(e *exprpb.Expr, _ func() int64) bool {
+		call := e.GetCallExpr()
+		if call == nil || call.GetFunction() != from {
+			return false
+		}
+		call.Function = to
+		return true
+	})
+}
+
+// RenameVariable returns a rule which renames the references to a variable.
+//
+// Local variables, such as the iteration variables of macros, are not renamed, nor are the
+// references to locals which shadow the variable. Migration fails when a renamed reference would
+// be captured by a local variable with the new name.
+func RenameVariable(version int, from, to string) *Rule {
+	r := NewRule(fmt.Sprintf("rename variable %s to %s", from, to), version, nil)
+	r.bind = func(e *exprpb.Expr) (Rewriter, error) {
+		refs := map[int64]bool{}
+		if err := findVariableRefs(e, from, to, map[string]int{}, refs); err != nil {
+			return nil, err
+		}
+		// The references are identified by id, which the expansions of macros share with the
+		// arguments of the recorded macro calls.
+		return func(e *exprpb.Expr, _ func() int64) bool {
+			ident := e.GetIdentExpr()
+			if ident == nil || ident.GetName() != from || !refs[e.GetId()] {
+				return false
+			}
+			ident.Name = to
+			return true
+		}, nil
+	}
+	return r
+}
+
+// findVariableRefs records the ids of the identifiers which refer to the variable rather than to a
+// local variable in scope, and reports an error when a local variable named `to` is in scope at
+// any of them.
+func findVariableRefs(e *exprpb.Expr, name, to string, locals map[string]int, refs map[int64]bool) error {
+	if e == nil {
+		return nil
+	}
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		if e.GetIdentExpr().GetName() != name || locals[name] > 0 {
+			return nil
+		}
+		if locals[to] > 0 {
+			return fmt.Errorf("renaming variable %s to %s would be captured by a local variable", name, to)
+		}
+		refs[e.GetId()] = true
+	case *exprpb.Expr_SelectExpr:
+		return findVariableRefs(e.GetSelectExpr().GetOperand(), name, to, locals, refs)
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		if err := findVariableRefs(call.GetTarget(), name, to, locals, refs); err != nil {
+			return err
+		}
+		for _, arg := range call.GetArgs() {
+			if err := findVariableRefs(arg, name, to, locals, refs); err != nil {
+				return err
+			}
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range e.GetListExpr().GetElements() {
+			if err := findVariableRefs(elem, name, to, locals, refs); err != nil {
+				return err
+			}
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			if err := findVariableRefs(entry.GetMapKey(), name, to, locals, refs); err != nil {
+				return err
+			}
+			if err := findVariableRefs(entry.GetValue(), name, to, locals, refs); err != nil {
+				return err
+			}
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		if err := findVariableRefs(comp.GetIterRange(), name, to, locals, refs); err != nil {
+			return err
+		}
+		if err := findVariableRefs(comp.GetAccuInit(), name, to, locals, refs); err != nil {
+			return err
+		}
+		locals[comp.GetAccuVar()]++
+		defer func() { locals[comp.GetAccuVar()]-- }()
+		locals[comp.GetIterVar()]++
+		if err := findVariableRefs(comp.GetLoopCondition(), name, to, locals, refs); err != nil {
+			return err
+		}
+		if err := findVariableRefs(comp.GetLoopStep(), name, to, locals, refs); err != nil {
+			return err
+		}
+		locals[comp.GetIterVar()]--
+		return findVariableRefs(comp.GetResult(), name, to, locals, refs)
+	}
+	return nil
+}
+
+// ReorderArguments returns a rule which reorders the arguments of the global calls to a function
+// with as many arguments as the order has entries, where the i-th argument of the migrated call is
+// the argument at index order[i] of the original call.
+func ReorderArguments(version int, function string, order ...int) *Rule {
+	name := fmt.Sprintf("reorder arguments of %s as %v", function, order)
+	return NewRule(name, version, func(e *exprpb.Expr, _ func() int64) bool {
+		call := e.GetCallExpr()
+		if call == nil || call.GetFunction() != function || call.GetTarget() != nil ||
+			len(call.GetArgs()) != len(order) {
+			return false
+		}
+		args := make([]*exprpb.Expr, len(order))
+		for i, idx := range order {
+			args[i] = call.GetArgs()[idx]
+		}
+		call.Args = args
+		return true
+	})
+}
+
+// Expression is a stored expression along with the version it was last migrated to.
+type Expression struct {
+	// ID identifies the expression within the store.
+	ID string
+
+	// Source is the CEL source of the expression.
+	Source string
+
+	// Version is the version the expression was last migrated to, or zero if it has never been
+	// migrated.
+	Version int
+}
+
+// Result describes the migration of a single expression.
+type Result struct {
+	// ID identifies the expression within the store.
+	ID string
+
+	// Source is the CEL source of the migrated expression, or the original source if migration
+	// failed.
+	Source string
+
+	// Version is the version the expression was migrated to.
+	Version int
+
+	// Ast is the type-checked Ast of the migrated expression, or nil if migration failed.
+	Ast *cel.Ast
+
+	// Applied lists the names of the rules which changed the expression, in order of application.
+	Applied []string
+
+	// Err is non-nil when the expression could not be parsed, formatted, or type-checked
+	// following its migration.
+	Err error
+}
+
+// Changed returns whether any rule changed the expression.
+func (r *Result) Changed() bool {
+	return len(r.Applied) != 0
+}
+
+// Report summarizes the migration of a store of expressions.
+type Report struct {
+	// Results holds the result of each expression in the order the expressions were provided.
+	Results []*Result
+}
+
+// Failed returns the results of the expressions which could not be migrated.
+func (r *Report) Failed() []*Result {
+	var failed []*Result
+	for _, res := range r.Results {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// String renders a line per expression with the outcome of its migration.
+func (r *Report) String() string {
+	var sb strings.Builder
+	for _, res := range r.Results {
+		switch {
+		case res.Err != nil:
+			fmt.Fprintf(&sb, "%s: error: %v\n", res.ID, res.Err)
+		case res.Changed():
+			fmt.Fprintf(&sb, "%s: migrated to v%d: %s\n", res.ID, res.Version, strings.Join(res.Applied, ", "))
+		default:
+			fmt.Fprintf(&sb, "%s: unchanged\n", res.ID)
+		}
+	}
+	return sb.String()
+}
+
+// Migrator applies a set of versioned rules to expressions.
+type Migrator struct {
+	env      *cel.Env
+	parseEnv *cel.Env
+	rules    []*Rule
+	version  int
+}
+
+// NewMigrator returns a Migrator which applies the rules in version order, and then in the order
+// provided, type-checking the migrated expressions against the environment.
+//
+// The environment is also used to parse expressions, and so must declare the macros used by the
+// expressions being migrated.
+func NewMigrator(env *cel.Env, rules ...*Rule) (*Migrator, error) {
+	sorted := make([]*Rule, len(rules))
+	copy(sorted, rules)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+	version := 0
+	for _, r := range sorted {
+		if r.Version <= 0 {
+			return nil, fmt.Errorf("migration rule %q has non-positive version %d", r.Name, r.Version)
+		}
+		version = r.Version
+	}
+	// Macro calls must be tracked in order to format migrated expressions with their macros intact.
+	parseEnv, err := env.Extend(cel.EnableMacroCallTracking())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{env: env, parseEnv: parseEnv, rules: sorted, version: version}, nil
+}
+
+// Version returns the version to which expressions are migrated, which is that of the most recent
+// rule.
+func (m *Migrator) Version() int {
+	return m.version
+}
+
+// MigrateAll migrates each of the expressions.
+func (m *Migrator) MigrateAll(exprs ...Expression) *Report {
+	report := &Report{Results: make([]*Result, len(exprs))}
+	for i, expr := range exprs {
+		report.Results[i] = m.Migrate(expr)
+	}
+	return report
+}
+
+// Migrate applies the rules newer than the version of the expression.
+func (m *Migrator) Migrate(expr Expression) *Result {
+	res := &Result{ID: expr.ID, Source: expr.Source, Version: expr.Version}
+	parsed, iss := m.parseEnv.Parse(expr.Source)
+	if iss.Err() != nil {
+		res.Err = iss.Err()
+		return res
+	}
+	e := proto.Clone(parsed.Expr()).(*exprpb.Expr)
+	info := proto.Clone(parsed.SourceInfo()).(*exprpb.SourceInfo)
+	ids := &idGenerator{}
+	ids.observe(e)
+	for _, call := range info.GetMacroCalls() {
+		ids.observe(call)
+	}
+	for _, r := range m.rules {
+		if r.Version <= expr.Version {
+			continue
+		}
+		if r.applies != nil && !r.applies(cel.ParsedExprToAst(&exprpb.ParsedExpr{Expr: e, SourceInfo: info})) {
+			continue
+		}
+		rw := r.rewrite
+		if r.bind != nil {
+			var err error
+			rw, err = r.bind(e)
+			if err != nil {
+				res.Err = err
+				return res
+			}
+		}
+		changed := rewrite(e, rw, ids.next)
+		// Macro calls are recorded separately from their expansions, and are used in place of the
+		// expansions when formatting the migrated expression.
+		for _, call := range info.GetMacroCalls() {
+			changed = rewrite(call, rw, ids.next) || changed
+		}
+		if changed {
+			res.Applied = append(res.Applied, r.Name)
+		}
+	}
+	version := expr.Version
+	if m.version > version {
+		version = m.version
+	}
+	src := expr.Source
+	if res.Changed() {
+		var err error
+		src, err = parser.Unparse(e, info)
+		if err != nil {
+			res.Err = err
+			return res
+		}
+	}
+	ast, iss := m.env.Compile(src)
+	if iss.Err() != nil {
+		res.Err = iss.Err()
+		return res
+	}
+	res.Source = src
+	res.Version = version
+	res.Ast = ast
+	return res
+}
+
+// rewrite applies the rewriter to the nodes of the expression in post-order, and returns whether
+// any node was changed.
+func rewrite(e *exprpb.Expr, rw Rewriter, nextID func() int64) bool {
+	if e == nil {
+		return false
+	}
+	changed := false
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		changed = rewrite(e.GetSelectExpr().GetOperand(), rw, nextID)
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		changed = rewrite(call.GetTarget(), rw, nextID)
+		for _, arg := range call.GetArgs() {
+			changed = rewrite(arg, rw, nextID) || changed
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range e.GetListExpr().GetElements() {
+			changed = rewrite(elem, rw, nextID) || changed
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			changed = rewrite(entry.GetMapKey(), rw, nextID) || changed
+			changed = rewrite(entry.GetValue(), rw, nextID) || changed
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		changed = rewrite(comp.GetIterRange(), rw, nextID)
+		changed = rewrite(comp.GetAccuInit(), rw, nextID) || changed
+		changed = rewrite(comp.GetLoopCondition(), rw, nextID) || changed
+		changed = rewrite(comp.GetLoopStep(), rw, nextID) || changed
+		changed = rewrite(comp.GetResult(), rw, nextID) || changed
+	}
+	return rw(e, nextID) || changed
+}
+
+// idGenerator produces expression ids which are greater than those of the observed expressions.
+type idGenerator struct {
+	maxID int64
+}
+
+func (g *idGenerator) next() int64 {
+	g.maxID++
+	return g.maxID
+}
+
+// observe records the largest id within the expression.
+func (g *idGenerator) observe(e *exprpb.Expr) {
+	if e == nil {
+		return
+	}
+	if e.GetId() > g.maxID {
+		g.maxID = e.GetId()
+	}
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		g.observe(e.GetSelectExpr().GetOperand())
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		g.observe(call.GetTarget())
+		for _, arg := range call.GetArgs() {
+			g.observe(arg)
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range e.GetListExpr().GetElements() {
+			g.observe(elem)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			if entry.GetId() > g.maxID {
+				g.maxID = entry.GetId()
+			}
+			g.observe(entry.GetMapKey())
+			g.observe(entry.GetValue())
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		g.observe(comp.GetIterRange())
+		g.observe(comp.GetAccuInit())
+		g.observe(comp.GetLoopCondition())
+		g.observe(comp.GetLoopStep())
+		g.observe(comp.GetResult())
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestMigrate(t *testing.T) {
+	env, err := cel.NewEnv(
+		cel.Variable("req", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("threshold", cel.IntType),
+		cel.Function("hasRole",
+			cel.Overload("has_role_map_string", []*cel.Type{cel.MapType(cel.StringType, cel.StringType), cel.StringType}, cel.BoolType)),
+		cel.Function("within",
+			cel.Overload("within_int_int", []*cel.Type{cel.IntType, cel.IntType}, cel.BoolType)),
+	)
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	// Wraps the deprecated `limit` variable in a conversion to int.
+	wrapLimit := NewRule("wrap limit", 3, func(e *exprpb.Expr, nextID func() int64) bool {
+		if e.GetIdentExpr().GetName() != "limit" {
+			return false
+		}
+		inner := &exprpb.Expr{Id: nextID(), ExprKind: &exprpb.Expr_IdentExpr{
+			IdentExpr: &exprpb.Expr_Ident{Name: "threshold"}}}
+		e.ExprKind = &exprpb.Expr_CallExpr{CallExpr: &exprpb.Expr_Call{
+			Function: "int", Args: []*exprpb.Expr{inner}}}
+		return true
+	}).When(func(ast *cel.Ast) bool {
+		return !strings.Contains(ast.Source().Content(), "threshold")
+	})
+	m, err := NewMigrator(env,
+		wrapLimit,
+		RenameFunction(1, "checkRole", "hasRole"),
+		ReorderArguments(2, "within", 1, 0),
+		RenameVariable(2, "request", "req"),
+	)
+	if err != nil {
+		t.Fatalf("NewMigrator() failed: %v", err)
+	}
+	report := m.MigrateAll(
+		Expression{ID: "a", Source: `checkRole(request, 'admin') && within(limit, 10)`},
+		Expression{ID: "b", Source: `['x', 'y'].exists(r, checkRole(request, r))`, Version: 1},
+		Expression{ID: "c", Source: `within(10, threshold)`, Version: 3},
+		Expression{ID: "d", Source: `unknownFn(request)`},
+	)
+	var got []string
+	for _, res := range report.Results {
+		got = append(got, res.Source)
+	}
+	want := []string{
+		`hasRole(req, "admin") && within(10, int(threshold))`,
+		`['x', 'y'].exists(r, checkRole(request, r))`,
+		`within(10, threshold)`,
+		`unknownFn(request)`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("migrated sources got %v, wanted %v", got, want)
+	}
+	wantReport := `a: migrated to v3: rename function checkRole to hasRole, reorder arguments of within as [1 0], rename variable request to req, wrap limit
+b: error: ERROR: <input>:1:31: undeclared reference to 'checkRole' (in container '')
+ | ["x", "y"].exists(r, checkRole(req, r))
+ | ..............................^
+c: unchanged
+d: error: ERROR: <input>:1:10: undeclared reference to 'unknownFn' (in container '')
+ | unknownFn(req)
+ | .........^
+`
+	if report.String() != wantReport {
+		t.Errorf("report.String() got %s, wanted %s", report, wantReport)
+	}
+	if len(report.Failed()) != 2 {
+		t.Errorf("report.Failed() got %d results, wanted 2", len(report.Failed()))
+	}
+	if res := report.Results[0]; res.Version != 3 || res.Ast == nil || !res.Ast.IsChecked() {
+		t.Errorf("report.Results[0] got version %d, ast %v, wanted a checked ast at v3", res.Version, res.Ast)
+	}
+}
+
+func TestNewMigratorInvalidVersion(t *testing.T) {
+	env, err := cel.NewEnv()
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	if _, err := NewMigrator(env, RenameFunction(0, "a", "b")); err == nil {
+		t.Error("NewMigrator() with a zero version succeeded, wanted error")
+	}
+}
+
+func TestRenameVariableScopes(t *testing.T) {
+	env, err := cel.NewEnv(
+		cel.Variable("y", cel.IntType),
+		cel.Variable("z", cel.IntType),
+	)
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		to  string
+		in  string
+		out string
+		err string
+	}{
+		{
+			to:  "z",
+			in:  `[1, 2].exists(x, x > 0) || x > 0`,
+			out: `[1, 2].exists(x, x > 0) || z > 0`,
+		},
+		{
+			to:  "z",
+			in:  `[1].exists(a, [2].all(b, b == x))`,
+			out: `[1].exists(a, [2].all(b, b == z))`,
+		},
+		{
+			to:  "y",
+			in:  `[1, 2].exists(y, y == x)`,
+			err: "would be captured by a local variable",
+		},
+		{
+			to:  "y",
+			in:  `[1, 2].exists(y, y == 1) && x == 1`,
+			out: `[1, 2].exists(y, y == 1) && y == 1`,
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.in, func(t *testing.T) {
+			m, err := NewMigrator(env, RenameVariable(1, "x", tc.to))
+			if err != nil {
+				t.Fatalf("NewMigrator() failed: %v", err)
+			}
+			res := m.Migrate(Expression{ID: "a", Source: tc.in})
+			if tc.err != "" {
+				if res.Err == nil || !strings.Contains(res.Err.Error(), tc.err) {
+					t.Errorf("Migrate(%q) got %v, wanted error containing %q", tc.in, res.Err, tc.err)
+				}
+				return
+			}
+			if res.Err != nil || res.Source != tc.out {
+				t.Errorf("Migrate(%q) got %q, %v, wanted %q", tc.in, res.Source, res.Err, tc.out)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"strconv"
+
+	"github.com/google/cel-go/common"
+
+	"google.golang.org/protobuf/proto"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// MinifyMapping relates the expression ids and local variable names of a minified Ast to those of
+// the original Ast, so that errors and evaluation state reported against the minified Ast may be
+// translated back. The mapping is intended to be serialized, e.g. as JSON, and retained alongside
+// the original policy rather than distributed with the minified Ast.
+type MinifyMapping struct {
+	// IDs maps the expression ids of the minified Ast to the ids of the original Ast.
+	IDs map[int64]int64 `json:"ids"`
+
+	// Variables maps the local variable names of the minified Ast to the names of the original
+	// Ast, such as the variables of `cel.bind()` or the iteration variables of macros.
+	Variables map[string]string `json:"variables"`
+}
+
+// OriginalID returns the id of the original expression for the minified expression id, or zero
+// if the id is not known.
+func (m *MinifyMapping) OriginalID(id int64) int64 {
+	return m.IDs[id]
+}
+
+// OriginalVariable returns the original name of a minified local variable, or the input name if it
+// was not renamed.
+func (m *MinifyMapping) OriginalVariable(name string) string {
+	if orig, found := m.Variables[name]; found {
+		return orig
+	}
+	return name
+}
+
+// Minify returns a copy of the Ast suited to distribution to untrusted evaluators, along with the
+// mapping required to relate the copy back to the input Ast.
+//
+// The copy is minified as follows:
+//   - the local variables of comprehensions, including let-bindings, are renamed to short names
+//     which cannot be expressed in CEL source, e.g. `@0`, so they cannot collide with declarations;
+//   - expression ids are renumbered densely from 1 in pre-order;
+//   - the source text, source positions, and macro call records are removed.
+//
+// The names of the variables and functions declared within the environment are retained, since
+// they are required for evaluation. Type and reference information of checked Asts is preserved
+// under the new expression ids, so the minified Ast may be planned without type-checking, as are
+// the container resolutions and variable defaults recorded by Env.Check. The input Ast is not
+// modified.
+func (e *Env) Minify(ast *Ast) (*Ast, *MinifyMapping) {
+	m := &minifier{
+		ast: ast,
+		mapping: &MinifyMapping{
+			IDs:       map[int64]int64{},
+			Variables: map[string]string{},
+		},
+		refMap: map[int64]*exprpb.Reference{},
+	}
+	if ast.typeMap != nil {
+		m.typeMap = map[int64]*exprpb.Type{}
+	}
+	expr := proto.Clone(ast.Expr()).(*exprpb.Expr)
+	m.visit(expr, map[string]string{})
+	info := &exprpb.SourceInfo{
+		SyntaxVersion: ast.SourceInfo().GetSyntaxVersion(),
+		Positions:     map[int64]int32{},
+	}
+	var refMap map[int64]*exprpb.Reference
+	if ast.refMap != nil {
+		refMap = m.refMap
+	}
+	return &Ast{
+		expr:        expr,
+		info:        info,
+		source:      common.NewInfoSource(info),
+		refMap:      refMap,
+		typeMap:     m.typeMap,
+		resolutions: m.resolutions,
+		defaults:    ast.defaults,
+	}, m.mapping
+}
+
+type minifier struct {
+	ast     *Ast
+	mapping *MinifyMapping
+	nextID  int64
+	nextVar int
+	refMap  map[int64]*exprpb.Reference
+	typeMap map[int64]*exprpb.Type
+
+	resolutions map[int64]*ContainerResolution
+}
+
+// visit renumbers the expression and renames its local variables, where the scope maps the
+// original names of the local variables in scope to their new names.
+func (m *minifier) visit(e *exprpb.Expr, scope map[string]string) {
+	origID := e.GetId()
+	m.nextID++
+	e.Id = m.nextID
+	m.mapping.IDs[e.GetId()] = origID
+	if t, found := m.ast.typeMap[origID]; found {
+		m.typeMap[e.GetId()] = t
+	}
+	if ref, found := m.ast.refMap[origID]; found {
+		m.refMap[e.GetId()] = ref
+	}
+	if r, found := m.ast.resolutions[origID]; found {
+		if m.resolutions == nil {
+			m.resolutions = map[int64]*ContainerResolution{}
+		}
+		moved := *r
+		moved.ID = e.GetId()
+		m.resolutions[e.GetId()] = &moved
+	}
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		ident := e.GetIdentExpr()
+		if name, found := scope[ident.GetName()]; found {
+			ident.Name = name
+			if ref, found := m.refMap[e.GetId()]; found {
+				ref = proto.Clone(ref).(*exprpb.Reference)
+				ref.Name = name
+				m.refMap[e.GetId()] = ref
+			}
+		}
+	case *exprpb.Expr_SelectExpr:
+		m.visit(e.GetSelectExpr().GetOperand(), scope)
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		if call.GetTarget() != nil {
+			m.visit(call.GetTarget(), scope)
+		}
+		for _, arg := range call.GetArgs() {
+			m.visit(arg, scope)
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range e.GetListExpr().GetElements() {
+			m.visit(elem, scope)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			origEntryID := entry.GetId()
+			m.nextID++
+			entry.Id = m.nextID
+			m.mapping.IDs[entry.GetId()] = origEntryID
+			if entry.GetMapKey() != nil {
+				m.visit(entry.GetMapKey(), scope)
+			}
+			m.visit(entry.GetValue(), scope)
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		m.visit(comp.GetIterRange(), scope)
+		m.visit(comp.GetAccuInit(), scope)
+		inner := make(map[string]string, len(scope)+2)
+		for orig, name := range scope {
+			inner[orig] = name
+		}
+		comp.IterVar = m.rename(comp.GetIterVar(), inner)
+		comp.AccuVar = m.rename(comp.GetAccuVar(), inner)
+		m.visit(comp.GetLoopCondition(), inner)
+		m.visit(comp.GetLoopStep(), inner)
+		m.visit(comp.GetResult(), inner)
+	}
+}
+
+// rename assigns a new name to the local variable and records it within the scope.
+func (m *minifier) rename(orig string, scope map[string]string) string {
+	name := "@" + strconv.Itoa(m.nextVar)
+	m.nextVar++
+	scope[orig] = name
+	m.mapping.Variables[name] = orig
+	return name
+}
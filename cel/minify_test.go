@@ -0,0 +1,81 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMinify(t *testing.T) {
+	env, err := NewEnv(
+		Variable("secret_roles", ListType(StringType)),
+		Variable("required", ListType(StringType)),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`required.all(internal_role, secret_roles.exists(internal_role, internal_role == 'admin') ||
+		secret_roles.exists(granted_role, granted_role == internal_role))`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	minAst, mapping := env.Minify(ast)
+	checked, err := AstToCheckedExpr(minAst)
+	if err != nil {
+		t.Fatalf("AstToCheckedExpr() failed: %v", err)
+	}
+	out, err := proto.Marshal(checked)
+	if err != nil {
+		t.Fatalf("proto.Marshal() failed: %v", err)
+	}
+	for _, leaked := range []string{"internal_role", "granted_role", "__result__"} {
+		if strings.Contains(string(out), leaked) {
+			t.Errorf("minified Ast contains %q", leaked)
+		}
+	}
+	if len(minAst.SourceInfo().GetPositions()) != 0 || len(minAst.SourceInfo().GetMacroCalls()) != 0 {
+		t.Errorf("minified Ast retains source info: %v", minAst.SourceInfo())
+	}
+	if mapping.OriginalVariable("@0") != "internal_role" {
+		t.Errorf("OriginalVariable(@0) got %q, wanted internal_role", mapping.OriginalVariable("@0"))
+	}
+	if mapping.OriginalID(minAst.Expr().GetId()) != ast.Expr().GetId() {
+		t.Errorf("OriginalID(%d) got %d, wanted %d", minAst.Expr().GetId(),
+			mapping.OriginalID(minAst.Expr().GetId()), ast.Expr().GetId())
+	}
+
+	prg := mustProgram(t, env, ast)
+	minPrg := mustProgram(t, env, minAst)
+	for _, vars := range []map[string]any{
+		{"secret_roles": []string{"admin"}, "required": []string{"reader"}},
+		{"secret_roles": []string{"reader"}, "required": []string{"reader", "writer"}},
+		{"secret_roles": []string{"reader", "writer"}, "required": []string{"reader", "writer"}},
+	} {
+		want, _, err := prg.Eval(vars)
+		if err != nil {
+			t.Fatalf("prg.Eval() failed: %v", err)
+		}
+		got, _, err := minPrg.Eval(vars)
+		if err != nil {
+			t.Fatalf("minified prg.Eval() failed: %v", err)
+		}
+		if got != want {
+			t.Errorf("minified prg.Eval(%v) got %v, wanted %v", vars, got, want)
+		}
+	}
+}
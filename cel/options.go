@@ -15,6 +15,7 @@
 package cel
 
 import (
+	"context"
 	"fmt"
 
 	"google.golang.org/protobuf/proto"
@@ -23,6 +24,7 @@ import (
 	"google.golang.org/protobuf/reflect/protoregistry"
 	"google.golang.org/protobuf/types/dynamicpb"
 
+	"github.com/google/cel-go/checker"
 	"github.com/google/cel-go/checker/decls"
 	"github.com/google/cel-go/common/containers"
 	"github.com/google/cel-go/common/types/pb"
@@ -66,6 +68,15 @@ const (
 	// Enable the use of optional types in the syntax, type-system, type-checking,
 	// and runtime.
 	featureOptionalTypes
+
+	// Reject expressions which call functions declared as nondeterministic.
+	featureDeterministicEval
+
+	// Evaluate ordering comparisons involving null to false rather than to an error.
+	featureNullSafeComparisons
+
+	// Enable the ordering comparisons of lists and maps.
+	featureAggregateComparisons
 )
 
 // EnvOption is a functional interface for configuring the environment.
@@ -94,6 +105,8 @@ func CustomTypeAdapter(adapter ref.TypeAdapter) EnvOption {
 
 // CustomTypeProvider swaps the default ref.TypeProvider implementation with a custom one.
 //
+// To compose several providers rather than replacing the provider, see TypeProviders.
+//
 // Note: This option must be specified before the Types and TypeDescs options when used together.
 func CustomTypeProvider(provider ref.TypeProvider) EnvOption {
 	return func(e *Env) (*Env, error) {
@@ -397,7 +410,8 @@ const (
 	// OptOptimize precomputes functions and operators with constants as arguments at program
 	// creation time. It also pre-compiles regex pattern constants passed to 'matches', reports any compilation errors
 	// at program creation and uses the compiled regex pattern for all 'matches' function invocations.
-	// This flag is useful when the expression will be evaluated repeatedly against
+	// Chains of string and list concatenations are flattened so that each chain is computed with a
+	// single allocation. This flag is useful when the expression will be evaluated repeatedly against
 	// a series of different inputs.
 	OptOptimize EvalOption = 1 << iota
 
@@ -415,6 +429,50 @@ const (
 
 	// OptCheckStringFormat enables compile-time checking of string.format calls for syntax/cardinality.
 	OptCheckStringFormat EvalOption = 1 << iota
+
+	// OptBytecode lowers the planned program into bytecode executed by a stack machine rather than
+	// evaluating the program by walking the planned tree. The results are identical to those of the
+	// tree-walking evaluator.
+	//
+	// Bytecode is only produced for the portions of the program which do not require per-node
+	// state tracking, so the option has little effect when combined with OptTrackState,
+	// OptExhaustiveEval, or OptTrackCost.
+	OptBytecode EvalOption = 1 << iota
+
+	// OptScalarEval evaluates checked expressions whose subexpressions are all of type int, uint,
+	// double, bool, or string using unboxed Go values, avoiding the allocation of intermediate
+	// results. Expressions which do not meet these criteria are evaluated normally.
+	//
+	// Any condition which would produce an error, such as a missing variable or an arithmetic
+	// overflow, causes the expression to be reevaluated with the standard evaluator, so the option
+	// is best suited to expressions which rarely produce errors. The option has no effect when
+	// combined with OptTrackState, OptExhaustiveEval, OptTrackCost, or OptPartialEval.
+	OptScalarEval EvalOption = 1 << iota
+
+	// OptCacheAttributes memoizes the resolution of variables and their constant field and index
+	// selections for the duration of a single evaluation, so that an attribute such as
+	// `request.user.id` which appears several times within an expression is only resolved once.
+	//
+	// Selections with non-constant qualifiers, such as `request.users[index]`, are not cached.
+	OptCacheAttributes EvalOption = 1 << iota
+
+	// OptSortedMapIteration makes comprehensions iterate over the keys of maps in sorted order, with
+	// keys of the same type ordered by value and keys of different types ordered by type name, so
+	// that comprehensions whose results depend upon the iteration order, such as `m.map(k, k)`,
+	// produce the same result on every evaluation. Maps are always formatted in sorted key order.
+	OptSortedMapIteration EvalOption = 1 << iota
+
+	// OptErrorLocations annotates the errors produced by the evaluation with the expression id and
+	// source location of the subexpression which produced them, such as the division within
+	// `a + b / c`. The location prefixes the message of both the error value and the error returned
+	// by Eval, and is available as an *EvalError via errors.As.
+	OptErrorLocations EvalOption = 1 << iota
+
+	// OptTrackProvenance records the provenance of the value produced by each subexpression: the
+	// ids of the subexpressions and the input attributes which contributed to it. The provenance
+	// of the result is available via EvalDetails.Provenance, and that of the intermediate values
+	// via EvalDetails.ProvenanceTracker. The option is incompatible with ParallelExhaustiveEval.
+	OptTrackProvenance EvalOption = 1 << iota
 )
 
 // EvalOptions sets one or more evaluation options which may affect the evaluation or Result.
@@ -457,6 +515,212 @@ func CostLimit(costLimit uint64) ProgramOption {
 	}
 }
 
+// CostBudget enables cost tracking and draws the runtime cost of each evaluation of the program
+// from the budget as the cost accrues. The evaluation which exhausts the budget exits early with
+// an interpreter.EvalCancelledError whose Cause is interpreter.CostBudgetExceeded, as do all later
+// evaluations until the budget is reset.
+//
+// A budget may be shared by many programs to bound the total cost of their evaluations, e.g. the
+// rules of a single tenant, and may be combined with a CostLimit on the cost of each evaluation.
+func CostBudget(budget *interpreter.CostBudget) ProgramOption {
+	return func(p *prog) (*prog, error) {
+		if budget == nil {
+			return nil, fmt.Errorf("cost budget must not be nil")
+		}
+		p.costBudget = budget
+		p.evalOpts |= OptTrackCost
+		return p, nil
+	}
+}
+
+// MemoryLimit configures program evaluation to exit early with a "memory limit exceeded" error
+// once the approximate number of bytes allocated for the strings, bytes, lists, maps, and objects
+// constructed during evaluation exceeds the memory limit.
+//
+// Unlike the CostLimit, which is indicative of CPU usage, the memory limit guards against
+// expressions which construct large values from large inputs, e.g. `string(payload)`. The error
+// returned from evaluation is an interpreter.EvalCancelledError whose Cause is
+// interpreter.MemoryLimitExceeded.
+func MemoryLimit(limit uint64) ProgramOption {
+	return func(p *prog) (*prog, error) {
+		p.memoryLimit = &limit
+		return p, nil
+	}
+}
+
+// ParallelExhaustiveEval evaluates the operands of logical operators, and the elements of list and
+// map literals, concurrently using at most `workers` additional goroutines at a time across all
+// evaluations of the program.
+//
+// The option requires OptExhaustiveEval and benefits evaluations which are bound by the latency of
+// variable resolution or function calls rather than by CPU. Activations and function bindings used
+// with the program must be safe for concurrent use. The option cannot be combined with
+// OptTrackCost, OptCacheAttributes, or OptTrackProvenance.
+func ParallelExhaustiveEval(workers int) ProgramOption {
+	return func(p *prog) (*prog, error) {
+		if workers <= 0 {
+			return nil, fmt.Errorf("parallel evaluation requires a positive number of workers: %d", workers)
+		}
+		p.evalPool = interpreter.NewEvalPool(workers)
+		return p, nil
+	}
+}
+
+// ReorderLogicalOperands reorders the operands of `&&` and `||` chains within checked expressions so
+// that the operands with the lowest static cost estimate are evaluated first. The estimator may be
+// nil, in which case the default size and call cost estimates are used.
+//
+// Since logical operators are commutative the result of evaluation is unaffected; however, when
+// several operands produce an error or unknown value, the error or unknown which is reported may
+// differ. Where the evaluation order matters, the expression may be wrapped in a call to
+// `cel.ordered`, made available by the OrderedLogic option, to retain its written order.
+//
+// Chains which contain calls to functions declared as NonDeterministic are never reordered.
+func ReorderLogicalOperands(estimator checker.CostEstimator) ProgramOption {
+	return func(p *prog) (*prog, error) {
+		p.reorderLogic = true
+		p.reorderEstimator = estimator
+		return p, nil
+	}
+}
+
+// ObserveFolds reports the number of iterations evaluated by each comprehension, and whether its
+// loop condition terminated it early, to the observer as the program is evaluated.
+func ObserveFolds(observer interpreter.FoldObserver) ProgramOption {
+	return CustomDecorator(interpreter.ObserveFolds(observer))
+}
+
+// CollectProfile records the hit counts, boolean outcomes, and value sizes of each expression node
+// within the profile as the program is evaluated.
+//
+// Profiles collected from the evaluation of a checked expression may be merged and provided to
+// ProfileGuidedOptimization when planning later programs for the same checked expression.
+func CollectProfile(profile *interpreter.Profile) ProgramOption {
+	return CustomDecorator(interpreter.Observe(interpreter.ProfileObserver(profile)))
+}
+
+// ProfileGuidedOptimization informs the plan-time optimizations of the program with a profile
+// collected by CollectProfile from the evaluation of the same checked expression.
+//
+// When used together with ReorderLogicalOperands, the operands of logical operators are ordered
+// by their estimated cost relative to the observed likelihood that they short-circuit evaluation,
+// and the observed sizes of lists, maps, and strings inform the cost estimates.
+func ProfileGuidedOptimization(profile *interpreter.Profile) ProgramOption {
+	return func(p *prog) (*prog, error) {
+		p.profile = profile
+		return p, nil
+	}
+}
+
+// FlatAttributes configures the program to resolve variables from flat key-value stores, where the
+// variable name and the constant field selections and indices of an attribute are joined by the
+// separator to form the name of the value within the activation.
+//
+// For example, with a separator of "." the expression `request.user.id` resolves to the value of
+// "request.user.id" within the activation, and `has(request.user.id)` tests whether the key is
+// present. Unknown attribute patterns are honored when evaluating with a PartialActivation.
+func FlatAttributes(separator string) ProgramOption {
+	return func(p *prog) (*prog, error) {
+		if separator == "" {
+			return nil, fmt.Errorf("flat attribute separator must not be empty")
+		}
+		p.flatSeparator = separator
+		return p, nil
+	}
+}
+
+// InterceptQualifiers configures the program to consult the interceptor before each constant field
+// selection or index is applied to an object during evaluation, so that the qualifier may be
+// translated on the fly, e.g. `request.user_id` to the `userId` key of a JSON object.
+func InterceptQualifiers(interceptor interpreter.QualifierInterceptor) ProgramOption {
+	return func(p *prog) (*prog, error) {
+		if interceptor == nil {
+			return nil, fmt.Errorf("qualifier interceptor must not be nil")
+		}
+		p.qualifierInterceptor = interceptor
+		return p, nil
+	}
+}
+
+// MaskAttributes configures the program to replace the values of the attributes matched by the
+// patterns with the values computed by the mask function before they are used by the expression,
+// e.g. to hash email addresses, so that evaluations over sensitive data never observe the raw
+// values. The option may be given several times to mask different attributes differently, in which
+// case the first matching mask applies.
+//
+// The values beneath a masked path are masked as well, and expressions which access an ancestor of
+// a masked path as a whole, e.g. `user` for the pattern `user.email`, produce an error rather than
+// expose the raw values beneath it. Only the attributes rooted at the variables of the activation
+// are masked.
+func MaskAttributes(mask func(ref.Val) ref.Val, patterns ...*interpreter.AttributePattern) ProgramOption {
+	return func(p *prog) (*prog, error) {
+		if mask == nil {
+			return nil, fmt.Errorf("attribute mask must not be nil")
+		}
+		p.attributeMasks = append(p.attributeMasks, &interpreter.AttributeMask{Patterns: patterns, Mask: mask})
+		return p, nil
+	}
+}
+
+// PatchEval configures the program to call the patcher after each evaluation step so that it may
+// substitute the value observed for specific expression ids, such as to replay recorded values or
+// to inject faults without modifying the inputs of the evaluation.
+//
+// Patchers are called in registration order and the evaluation state tracked by OptTrackState
+// records the patched values. Subexpressions folded into constants by OptOptimize are not
+// evaluated, and so are not observed by the patcher.
+func PatchEval(patcher interpreter.EvalPatcher) ProgramOption {
+	return func(p *prog) (*prog, error) {
+		if patcher == nil {
+			return nil, fmt.Errorf("eval patcher must not be nil")
+		}
+		p.patchers = append(p.patchers, patcher)
+		return p, nil
+	}
+}
+
+// OverloadGuard validates the arguments of a function overload before its implementation is
+// invoked, with access to the context of the evaluation.
+//
+// A nil result permits the call. An error or unknown result vetoes the call and is returned as its
+// result. The context is the one provided to Program.ContextEval, or context.Background() when the
+// program is evaluated via Program.Eval.
+type OverloadGuard func(ctx context.Context, overloadID string, args []ref.Val) ref.Val
+
+// GuardOverloads configures the program to invoke the guard before the implementation of each of
+// the given overload ids, such as to validate the size of the inputs of user-registered functions
+// in one place rather than within each binding.
+//
+// A function name may be given in place of an overload id to guard every overload of the function,
+// which is required for expressions which have not been type-checked. Guards registered for the
+// same overload are invoked in registration order until one of them vetoes the call.
+//
+// Guarded calls with constant arguments may be guarded at planning time when combined with
+// OptOptimize.
+func GuardOverloads(guard OverloadGuard, overloadIDs ...string) ProgramOption {
+	return func(p *prog) (*prog, error) {
+		if len(overloadIDs) == 0 {
+			return nil, fmt.Errorf("guarded overload ids must not be empty")
+		}
+		if p.guards == nil {
+			p.guards = map[string][]OverloadGuard{}
+		}
+		for _, id := range overloadIDs {
+			p.guards[id] = append(p.guards[id], guard)
+		}
+		return p, nil
+	}
+}
+
+// OrderedLogic declares the `cel.ordered(bool) -> bool` function which returns its argument and
+// pins the evaluation order of the logical operators within it when the ReorderLogicalOperands
+// program option is used.
+//
+//	cel.ordered(has(msg.field) && msg.field.startsWith('prefix'))
+func OrderedLogic() EnvOption {
+	return Lib(orderedLogicLibrary{})
+}
+
 func fieldToCELType(field protoreflect.FieldDescriptor) (*exprpb.Type, error) {
 	if field.Kind() == protoreflect.MessageKind || field.Kind() == protoreflect.GroupKind {
 		msgName := (string)(field.Message().FullName())
@@ -545,13 +809,130 @@ func DefaultUTCTimeZone(enabled bool) EnvOption {
 	return features(featureDefaultUTCTimeZone, enabled)
 }
 
+// DeterministicEval rejects expressions which call functions declared with the NonDeterministic
+// function option, such as functions which read the wall clock or generate random values.
+//
+// Expressions which require such values should instead reference a variable whose value is
+// provided by the activation, e.g. a `now` variable bound to the evaluation time. This makes it
+// possible to replay evaluations and to guarantee that replicas agree on the result.
+//
+// The functions whose values are supplied by the program or its activation are permitted:
+// `now()` returns the time of the Clock option or of the ClockVar binding, and produces an error
+// when neither is supplied rather than reading the wall clock, while `flags.enabled()` tests the
+// flag states of the FlagSource option.
+//
+// Violations are reported as errors by Env.Check and Env.Program.
+func DeterministicEval() EnvOption {
+	return features(featureDeterministicEval, true)
+}
+
+// NullSafeComparisons makes the ordering comparisons `<`, `<=`, `>`, and `>=` evaluate to false
+// when either operand is null, rather than producing a no such overload error, mirroring the
+// treatment of NULL within SQL predicates.
+//
+// The behavior is contained to the ordering comparisons: equality with null is unaffected, and
+// the result of a comparison with null may still be negated, e.g. `!(x < 1)` is true when `x` is
+// null. Comparisons with null operands are only possible for values which are null at runtime,
+// such as dynamically typed values, JSON values, and unset protobuf wrapper fields.
+func NullSafeComparisons(enabled bool) EnvOption {
+	return features(featureNullSafeComparisons, enabled)
+}
+
+// AggregateComparisons enables the ordering comparisons `<`, `<=`, `>`, and `>=` of lists and of
+// maps, so that structured values may be sorted or ranked, e.g. `[1, 2] < [1, 3]`.
+//
+// Lists are compared lexicographically by their elements, and maps lexicographically by their
+// entries in the order of their keys, where a list or map which is a prefix of the other is the
+// lesser. The elements, keys, and values must themselves be comparable, such as numbers, strings,
+// or nested lists and maps, or else the comparison produces an error. Since the elements are
+// compared during evaluation, the operands are type-checked as `list(dyn)` or `map(dyn, dyn)`:
+// numbers of different types are compared by value, as in `[1] < [1.5]`, while incomparable
+// elements, as in `[1] < ['a']`, produce an error during evaluation.
+//
+// List and map values implement traits.Comparer with the same ordering, so functions which order
+// values through traits.Comparer also order lists and maps. Hosts may compare values consistently
+// with the expressions using types.CompareAggregates. Without this option, the ordering
+// comparisons of lists and maps produce a no such overload error, even for dynamically typed
+// operands.
+func AggregateComparisons(enabled bool) EnvOption {
+	return features(featureAggregateComparisons, enabled)
+}
+
 // OptionalTypes enable support for optional syntax and types in CEL. The optional value type makes
 // it possible to express whether variables have been provided, whether a result has been computed,
 // and in the future whether an object field path, map key value, or list index has a value.
+//
+// Optional values may be combined without nesting calls to `or` and `orValue`:
+//
+//	optional.ofNullable(value)             // optional.none() when the value is null
+//	opt.optMap(x, expr)                    // optional.of(expr) with x bound to the value of opt
+//	opt.optFilter(x, pred)                 // opt when its value satisfies the predicate
+//	optional.firstPresent([o1, o2, ...])   // the first optional with a value
+//
+// The `optMap` and `optFilter` names avoid a conflict with the `map` and `filter` macros, which
+// are expanded before the type of their target is known.
 func OptionalTypes() EnvOption {
 	return Lib(optionalLibrary{})
 }
 
+// NowFunction declares the `now()` function, which returns the current time as a timestamp.
+//
+// The function is declared as nondeterministic. The source of the time may be replaced for all
+// evaluations of a program with the Clock option, or for a single evaluation by binding ClockVar
+// within the activation, so that tests and replayed evaluations observe a deterministic time. For
+// the same reason, the function is permitted by DeterministicEval, under which it requires one of
+// the two sources of time.
+func NowFunction() EnvOption {
+	return Lib(nowLibrary{})
+}
+
+// FeatureFlags declares the `flags.enabled(<string>)` function, which tests whether the named
+// feature flag is enabled, so that subexpressions may be guarded by flags, e.g.
+// `flags.enabled('strict_quota') ? usage < quota : true`.
+//
+// Flag states are supplied for all evaluations of a program with the FlagSource option. Flags
+// whose states are fixed for a deployment may instead be pinned with Env.PinFlags, which folds
+// away the branches the pinned states rule out. The function is declared as nondeterministic, so
+// that its calls are neither folded nor cached before the flags are pinned.
+func FeatureFlags() EnvOption {
+	return Lib(flagsLibrary{})
+}
+
+// SpreadSyntax enables the spreading of lists and maps within list and map literals, e.g.
+// `[...base, 'extra']` and `{...defaults, 'override': 1}`.
+//
+// A list spread inserts the elements of the spread list, while a map spread inserts the entries of
+// the spread map, where entries which follow in the literal take precedence over earlier entries
+// with the same key. The spread operands are type-checked as lists and maps whose element, key,
+// and value types agree with the rest of the literal.
+func SpreadSyntax() EnvOption {
+	return Lib(spreadLibrary{})
+}
+
+// SliceSyntax enables the slicing of values with the index syntax, e.g. `x[a:b]`, `x[a:b:c]`,
+// `x[a:]`, `x[:b]`, and `x[::-1]`.
+//
+// Slices are parsed as calls to the `slice` member function, e.g. `x[a:b:c]` as
+// `x.slice(a, b, c)`, `x[:b]` as `x.slice(0, b)`, and `x[::-1]` as `x.slice(null, null, -1)`,
+// whose overloads for lists and strings are declared by the Lists and Strings extension
+// libraries, which enable the syntax.
+func SliceSyntax() EnvOption {
+	return func(e *Env) (*Env, error) {
+		e.prsrOpts = append(e.prsrOpts, parser.EnableSliceSyntax(true))
+		return e, nil
+	}
+}
+
+// Pairs enables pair values, which hold two values of possibly different types without losing the
+// type of either value as a two-element list of `dyn` would, e.g. the results of zipping two lists.
+//
+// A pair is constructed with `pair(a, b)` and its elements are selected with the `first` and
+// `second` fields, e.g. `pair('count', 2).second == 2`. The type-checker infers the type of a pair
+// as `pair(A, B)` from the types of its elements.
+func Pairs() EnvOption {
+	return Lib(pairLibrary{})
+}
+
 // features sets the given feature flags.  See list of Feature constants above.
 func features(flag int, enabled bool) EnvOption {
 	return func(e *Env) (*Env, error) {
@@ -560,6 +941,56 @@ func features(flag int, enabled bool) EnvOption {
 	}
 }
 
+// InfixOperators configures custom binary operator symbols which the parser maps to calls to the
+// named functions, e.g. the symbol `=~` to the `matches` function. The functions must be declared
+// separately within the environment.
+//
+// Expressions containing custom operators may be rendered with their operators intact using
+// parser.Unparse with the parser.UnparseInfixOperators option.
+func InfixOperators(ops ...parser.InfixOperator) EnvOption {
+	return func(e *Env) (*Env, error) {
+		e.prsrOpts = append(e.prsrOpts, parser.InfixOperators(ops...))
+		return e, nil
+	}
+}
+
+// IntegerOverflowPolicy sets the result of int and uint arithmetic which overflows the range of
+// the type: an error as required by the CEL specification (the default), a wrapped result as in
+// two's complement arithmetic, or a result clamped to the minimum or maximum value of the type.
+//
+// Policies other than interpreter.OverflowError are intended to ease the migration of
+// expressions from systems with such semantics. The policy is a property of the environment, so
+// that programs cached for environments with different policies are never shared.
+func IntegerOverflowPolicy(policy interpreter.OverflowPolicy) EnvOption {
+	return func(e *Env) (*Env, error) {
+		switch policy {
+		case interpreter.OverflowError, interpreter.OverflowWrap, interpreter.OverflowSaturate:
+			e.overflowPolicy = policy
+			return e, nil
+		}
+		return nil, fmt.Errorf("unsupported overflow policy: %v", policy)
+	}
+}
+
+// StringInputLimits bounds the inputs of the standard string functions `matches`, `contains`,
+// `startsWith`, and `endsWith`, so that expressions evaluated against untrusted inputs reject
+// pathologically long strings and regular expressions before doing any work, regardless of
+// whether a cost limit is configured. The lengths of the constant arguments written within an
+// expression are not limited.
+//
+// Arguments which exceed the limits produce an error wrapping an *interpreter.InputLengthError or
+// an *interpreter.RegexSizeError, which may be inspected with errors.As. Constant regular
+// expressions which exceed the limit fail the creation of the program.
+func StringInputLimits(limits interpreter.StringLimits) EnvOption {
+	return func(e *Env) (*Env, error) {
+		if limits.MaxInputLength < 0 || limits.MaxRegexProgramSize < 0 {
+			return nil, fmt.Errorf("string limits must not be negative: %+v", limits)
+		}
+		e.stringLimits = limits
+		return e, nil
+	}
+}
+
 // ParserRecursionLimit adjusts the AST depth the parser will tolerate.
 // Defaults defined in the parser package.
 func ParserRecursionLimit(limit int) EnvOption {
@@ -0,0 +1,46 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+type pairLibrary struct{}
+
+// LibraryName implements the SingletonLibrary interface method.
+func (pairLibrary) LibraryName() string {
+	return "cel.lib.pair"
+}
+
+// CompileOptions implements the Library interface method.
+func (pairLibrary) CompileOptions() []EnvOption {
+	paramTypeA := TypeParamType("A")
+	paramTypeB := TypeParamType("B")
+	return []EnvOption{
+		Types(types.PairType),
+		Function("pair",
+			Overload("pair_A_B", []*Type{paramTypeA, paramTypeB}, PairType(paramTypeA, paramTypeB),
+				BinaryBinding(func(first, second ref.Val) ref.Val {
+					return types.NewPair(first, second)
+				}))),
+	}
+}
+
+// ProgramOptions implements the Library interface method.
+func (pairLibrary) ProgramOptions() []ProgramOption {
+	return []ProgramOption{}
+}
@@ -0,0 +1,38 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+)
+
+func TestPairs(t *testing.T) {
+	env, err := NewEnv(Pairs(), Variable("names", ListType(StringType)))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	runEvalTests(t, env, []evalTestCase{
+		{expr: `pair('count', 2)`, outType: PairType(StringType, IntType),
+			out: types.NewPair(types.String("count"), types.Int(2))},
+		{expr: `pair('count', 2).second + 1`, outType: IntType, out: 3},
+		{expr: `pair(names[0], pair(1u, 2.0)).second.first`, outType: UintType, out: uint64(1)},
+		{expr: `names.map(n, pair(n, size(n))).filter(p, p.second > 1).map(p, p.first)`,
+			outType: ListType(StringType), out: []string{"bob"}},
+		{expr: `has(pair(1, 2).first) && pair(1, 2) == pair(1, 2)`, outType: BoolType, out: true},
+		{expr: `pair(1, 2).third`, err: "undefined field 'third'"},
+	}, map[string]any{"names": []string{"bob", "a"}})
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"errors"
+
+	"github.com/google/cel-go/interpreter"
+)
+
+// DescribePlan describes the evaluation plan of a program after the decorators configured by its
+// ProgramOption values have been applied, making it possible to see which nodes were replaced by
+// optimized forms, e.g. by the OptOptimize evaluation option.
+//
+// The plan may be rendered as a Graphviz digraph or as a Mermaid flowchart:
+//
+//	plan, err := cel.DescribePlan(prg)
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Println(plan.DOT())
+//
+// For programs which track evaluation state or cost, the plan is that of a single evaluation.
+func DescribePlan(prg Program) (*interpreter.PlanNode, error) {
+	i, err := programPlan(prg)
+	if err != nil {
+		return nil, err
+	}
+	return interpreter.DescribePlan(i), nil
+}
+
+// PlanStats reports the number of nodes in the evaluation plan of a program by kind, along with
+// the depth of the plan, after the decorators configured by its ProgramOption values have been
+// applied. Since constant folding and other optimizations are reflected in the statistics, they
+// are a more accurate measure of the complexity of a program than the length of its source, e.g.
+// for the purpose of admission control.
+func PlanStats(prg Program) (interpreter.PlanStats, error) {
+	plan, err := DescribePlan(prg)
+	if err != nil {
+		return interpreter.PlanStats{}, err
+	}
+	return plan.Stats(), nil
+}
+
+// programPlan returns the planned Interpretable of the program.
+func programPlan(prg Program) (interpreter.Interpretable, error) {
+	switch p := prg.(type) {
+	case *prog:
+		return p.interpretable, nil
+	case *progGen:
+		genProg, err := p.factory(interpreter.NewEvalState(), &interpreter.CostTracker{}, &interpreter.MemoryTracker{},
+			interpreter.NewProvenanceTracker())
+		if err != nil {
+			return nil, err
+		}
+		return programPlan(genProg)
+	case *boundProgram:
+		return programPlan(p.base)
+	case *IncrementalProgram:
+		return programPlan(p.Program)
+	case *asyncProgram:
+		return programPlan(p.Program)
+	case *preparedProgram:
+		return programPlan(p.Program)
+	}
+	return nil, errors.New("program does not support plan descriptions")
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/interpreter"
+)
+
+func TestDescribePlan(t *testing.T) {
+	env, err := NewEnv(Variable("x", IntType), Variable("s", StringType))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`x in [1, 2, 3] && s + 'a' + 'b' == 'ab'`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	tests := []struct {
+		name string
+		opts []ProgramOption
+		want []string
+	}{
+		{name: "default", want: []string{"evalAnd", "evalEq", "evalBinary"}},
+		{
+			name: "optimized",
+			opts: []ProgramOption{EvalOptions(OptOptimize)},
+			want: []string{"evalSetMembership", "evalConcat"},
+		},
+		{
+			name: "state tracking",
+			opts: []ProgramOption{EvalOptions(OptOptimize, OptTrackState)},
+			want: []string{"evalWatch", "evalSetMembership"},
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.name, func(t *testing.T) {
+			prg, err := env.Program(ast, tc.opts...)
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			plan, err := DescribePlan(prg)
+			if err != nil {
+				t.Fatalf("DescribePlan() failed: %v", err)
+			}
+			dot := plan.DOT()
+			for _, kind := range tc.want {
+				if !strings.Contains(dot, kind) {
+					t.Errorf("DescribePlan().DOT() got %s, wanted node %s", dot, kind)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanStats(t *testing.T) {
+	env, err := NewEnv(Variable("x", IntType))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`x in [1, 2, 3]`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	tests := []struct {
+		opts []EvalOption
+		want interpreter.PlanStats
+	}{
+		{want: interpreter.PlanStats{Nodes: 6, Depth: 3, Attributes: 1, Calls: 1, Constants: 3, Constructors: 1}},
+		{
+			opts: []EvalOption{OptTrackState},
+			want: interpreter.PlanStats{Nodes: 6, Depth: 3, Attributes: 1, Calls: 1, Constants: 3, Constructors: 1},
+		},
+		{
+			opts: []EvalOption{OptOptimize},
+			want: interpreter.PlanStats{Nodes: 2, Depth: 2, Attributes: 1, Calls: 1},
+		},
+	}
+	for _, tc := range tests {
+		prg, err := env.Program(ast, EvalOptions(tc.opts...))
+		if err != nil {
+			t.Fatalf("env.Program() failed: %v", err)
+		}
+		stats, err := PlanStats(prg)
+		if err != nil {
+			t.Fatalf("PlanStats() failed: %v", err)
+		}
+		if stats != tc.want {
+			t.Errorf("PlanStats() with options %v got %+v, wanted %+v", tc.opts, stats, tc.want)
+		}
+	}
+}
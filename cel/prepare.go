@@ -0,0 +1,486 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/interpreter"
+	"github.com/google/cel-go/interpreter/functions"
+
+	"google.golang.org/protobuf/proto"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+)
+
+// maxPreparedPrograms is the maximum number of programs retained by a PreparedExpr.
+const maxPreparedPrograms = 1000
+
+// ParamDecl declares a named, typed parameter of a prepared expression.
+type ParamDecl struct {
+	Name string
+	Type *Type
+}
+
+// Param creates a parameter declaration with a name and type for use with Env.Prepare.
+func Param(name string, t *Type) ParamDecl {
+	return ParamDecl{Name: name, Type: t}
+}
+
+// PreparedExpr is an expression template whose parameters are bound to values before evaluation.
+//
+// The expression is compiled once, with each parameter declared as a variable. When the parameters
+// are bound, references to parameters whose values are expressible as literals, i.e. the
+// primitive types along with lists and maps of them, are replaced by those literals and the
+// resulting expression is planned with OptOptimize. The bound values are therefore treated as
+// constants: calls over them are folded, and membership tests against bound lists are specialized
+// to set lookups. Other parameter values are supplied to the program as variables.
+//
+// Programs are cached by the set of bound literal values. A PreparedExpr is safe for concurrent
+// use.
+type PreparedExpr struct {
+	env    *Env
+	ast    *Ast
+	params []ParamDecl
+
+	mu       sync.Mutex
+	programs map[string]Program
+}
+
+// Prepare compiles an expression template with a set of typed parameters, which are bound to
+// values at evaluation time with PreparedExpr.Bind.
+//
+// The parameters are declared as variables within an extension of the environment, so their
+// names must not collide with the names of variables declared within the environment.
+func (e *Env) Prepare(expr string, params ...ParamDecl) (*PreparedExpr, error) {
+	opts := make([]EnvOption, 0, len(params))
+	for _, p := range params {
+		if p.Type == nil {
+			return nil, fmt.Errorf("parameter %s must have a type", p.Name)
+		}
+		opts = append(opts, Variable(p.Name, p.Type))
+	}
+	env, err := e.Extend(opts...)
+	if err != nil {
+		return nil, err
+	}
+	ast, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	return &PreparedExpr{
+		env:      env,
+		ast:      ast,
+		params:   params,
+		programs: map[string]Program{},
+	}, nil
+}
+
+// Ast returns the checked Ast of the expression template, in which the parameters are variables.
+func (p *PreparedExpr) Ast() *Ast {
+	return p.ast
+}
+
+// Bind returns a program which evaluates the expression with its parameters bound to the given
+// values.
+//
+// Every parameter must be bound, and the value bound to a parameter must be assignable to its
+// declared type. Values which are not bound to a parameter result in an error.
+func (p *PreparedExpr) Bind(values map[string]any) (Program, error) {
+	for name := range values {
+		if !p.hasParam(name) {
+			return nil, fmt.Errorf("no such parameter: %s", name)
+		}
+	}
+	literals := map[string]*exprpb.Expr{}
+	vars := map[string]any{}
+	var key strings.Builder
+	for _, param := range p.params {
+		native, found := values[param.Name]
+		if !found {
+			return nil, fmt.Errorf("missing value for parameter: %s", param.Name)
+		}
+		val := p.env.adapter.NativeToValue(native)
+		if types.IsUnknownOrError(val) {
+			return nil, fmt.Errorf("invalid value for parameter %s: %v", param.Name, val)
+		}
+		if !isAssignableParamValue(param.Type, val) {
+			return nil, fmt.Errorf("parameter %s expects a value of type %v, got: %v",
+				param.Name, param.Type, val.Type().TypeName())
+		}
+		lit, isLiteral := valueToLiteral(val)
+		if !isLiteral {
+			vars[param.Name] = val
+			continue
+		}
+		literals[param.Name] = lit
+		b, err := proto.MarshalOptions{Deterministic: true}.Marshal(lit)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&key, "%s=%x;", param.Name, b)
+	}
+	prg, err := p.program(key.String(), literals)
+	if err != nil {
+		return nil, err
+	}
+	if len(vars) == 0 {
+		return prg, nil
+	}
+	params, err := interpreter.NewActivation(vars)
+	if err != nil {
+		return nil, err
+	}
+	return &preparedProgram{Program: prg, params: params}, nil
+}
+
+// hasParam returns whether the expression declares a parameter with the given name.
+func (p *PreparedExpr) hasParam(name string) bool {
+	for _, param := range p.params {
+		if param.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// program returns the cached program for the literal parameter values, planning it if necessary.
+func (p *PreparedExpr) program(key string, literals map[string]*exprpb.Expr) (Program, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if prg, found := p.programs[key]; found {
+		return prg, nil
+	}
+	prg, err := p.env.Program(substituteParams(p.ast, literals), EvalOptions(OptOptimize))
+	if err != nil {
+		return nil, err
+	}
+	if len(p.programs) >= maxPreparedPrograms {
+		for k := range p.programs {
+			delete(p.programs, k)
+			break
+		}
+	}
+	p.programs[key] = prg
+	return prg, nil
+}
+
+// isAssignableParamValue returns whether the value is assignable to the parameter type. Unlike
+// Type.IsAssignableRuntimeType, every element of a list or map value is inspected.
+func isAssignableParamValue(t *Type, val ref.Val) bool {
+	if !t.IsAssignableRuntimeType(val) {
+		return false
+	}
+	switch {
+	case t.kind == ListKind && len(t.parameters) == 1:
+		for it := val.(traits.Lister).Iterator(); it.HasNext() == types.True; {
+			if !isAssignableParamValue(t.parameters[0], it.Next()) {
+				return false
+			}
+		}
+	case t.kind == MapKind && len(t.parameters) == 2:
+		m := val.(traits.Mapper)
+		for it := m.Iterator(); it.HasNext() == types.True; {
+			k := it.Next()
+			if !isAssignableParamValue(t.parameters[0], k) ||
+				!isAssignableParamValue(t.parameters[1], m.Get(k)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// preparedProgram supplies the values of the parameters which could not be bound as literals to
+// the evaluation of the underlying program.
+type preparedProgram struct {
+	Program
+	params interpreter.Activation
+}
+
+// Eval implements the Program interface method.
+func (pp *preparedProgram) Eval(input any) (ref.Val, *EvalDetails, error) {
+	vars, err := pp.activation(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pp.Program.Eval(vars)
+}
+
+// ContextEval implements the Program interface method.
+func (pp *preparedProgram) ContextEval(ctx context.Context, input any) (ref.Val, *EvalDetails, error) {
+	vars, err := pp.activation(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pp.Program.ContextEval(ctx, vars)
+}
+
+// withFunctions implements the rebinder interface method.
+func (pp *preparedProgram) withFunctions(bindings ...*functions.Overload) (Program, error) {
+	prg, err := WithFunctions(pp.Program, bindings...)
+	if err != nil {
+		return nil, err
+	}
+	return &preparedProgram{Program: prg, params: pp.params}, nil
+}
+
+// evalSeries implements the seriesEvaluator interface method.
+func (pp *preparedProgram) evalSeries(base any) (*Series, error) {
+	return newSeries(pp, base)
+}
+
+// warmup implements the warmer interface method.
+func (pp *preparedProgram) warmup(sampleVars any) error {
+	vars, err := pp.activation(sampleVars)
+	if err != nil {
+		return err
+	}
+	return Warmup(pp.Program, vars)
+}
+
+// activation layers the parameter values over the input, so that parameters take precedence over
+// variables of the same name.
+func (pp *preparedProgram) activation(input any) (interpreter.Activation, error) {
+	vars, err := interpreter.NewActivation(input)
+	if err != nil {
+		return nil, err
+	}
+	return interpreter.NewHierarchicalActivation(vars, pp.params), nil
+}
+
+// substituteParams returns a copy of the checked Ast in which the references to the parameters
+// are replaced by their literal values.
+func substituteParams(ast *Ast, literals map[string]*exprpb.Expr) *Ast {
+	if len(literals) == 0 {
+		return ast
+	}
+	s := &paramSubstituter{
+		refMap:   ast.refMap,
+		literals: literals,
+		replaced: map[int64]*exprpb.Expr{},
+	}
+	expr := proto.Clone(ast.Expr()).(*exprpb.Expr)
+	visitExpr(expr, func(e *exprpb.Expr) {
+		if e.GetId() > s.nextID {
+			s.nextID = e.GetId()
+		}
+	})
+	expr = s.visit(expr, map[string]bool{})
+	if len(s.replaced) == 0 {
+		return ast
+	}
+	refMap := make(map[int64]*exprpb.Reference, len(ast.refMap))
+	for id, ref := range ast.refMap {
+		if _, found := s.replaced[id]; !found {
+			refMap[id] = ref
+		}
+	}
+	// The macro calls retain the original argument ids, so the same references are replaced within
+	// them in order for the Ast to unparse to the bound expression.
+	info := ast.info
+	if len(info.GetMacroCalls()) != 0 {
+		info = proto.Clone(info).(*exprpb.SourceInfo)
+		for _, call := range info.GetMacroCalls() {
+			visitExpr(call, func(e *exprpb.Expr) {
+				if lit, found := s.replaced[e.GetId()]; found && e.GetIdentExpr() != nil {
+					e.ExprKind = proto.Clone(lit).(*exprpb.Expr).GetExprKind()
+				}
+			})
+		}
+	}
+	return &Ast{
+		expr:        expr,
+		info:        info,
+		source:      ast.source,
+		refMap:      refMap,
+		typeMap:     ast.typeMap,
+		resolutions: ast.resolutions,
+		defaults:    ast.defaults,
+	}
+}
+
+type paramSubstituter struct {
+	refMap   map[int64]*exprpb.Reference
+	literals map[string]*exprpb.Expr
+	replaced map[int64]*exprpb.Expr
+	nextID   int64
+}
+
+// visit replaces the parameter references within the expression which are not shadowed by
+// comprehension variables, returning the expression which replaces the input expression.
+func (s *paramSubstituter) visit(e *exprpb.Expr, shadowed map[string]bool) *exprpb.Expr {
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		name := e.GetIdentExpr().GetName()
+		if ref, found := s.refMap[e.GetId()]; found && ref.GetName() != "" {
+			name = ref.GetName()
+		}
+		lit, found := s.literals[name]
+		if !found || shadowed[e.GetIdentExpr().GetName()] {
+			return e
+		}
+		lit = s.renumber(proto.Clone(lit).(*exprpb.Expr), e.GetId())
+		s.replaced[e.GetId()] = lit
+		return lit
+	case *exprpb.Expr_SelectExpr:
+		sel := e.GetSelectExpr()
+		sel.Operand = s.visit(sel.GetOperand(), shadowed)
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		if call.GetTarget() != nil {
+			call.Target = s.visit(call.GetTarget(), shadowed)
+		}
+		for i, arg := range call.GetArgs() {
+			call.Args[i] = s.visit(arg, shadowed)
+		}
+	case *exprpb.Expr_ListExpr:
+		list := e.GetListExpr()
+		for i, elem := range list.GetElements() {
+			list.Elements[i] = s.visit(elem, shadowed)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			if entry.GetMapKey() != nil {
+				entry.KeyKind = &exprpb.Expr_CreateStruct_Entry_MapKey{
+					MapKey: s.visit(entry.GetMapKey(), shadowed),
+				}
+			}
+			entry.Value = s.visit(entry.GetValue(), shadowed)
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		comp.IterRange = s.visit(comp.GetIterRange(), shadowed)
+		comp.AccuInit = s.visit(comp.GetAccuInit(), shadowed)
+		inner := make(map[string]bool, len(shadowed)+2)
+		for name := range shadowed {
+			inner[name] = true
+		}
+		inner[comp.GetIterVar()] = true
+		inner[comp.GetAccuVar()] = true
+		comp.LoopCondition = s.visit(comp.GetLoopCondition(), inner)
+		comp.LoopStep = s.visit(comp.GetLoopStep(), inner)
+		comp.Result = s.visit(comp.GetResult(), inner)
+	}
+	return e
+}
+
+// renumber assigns the id of the replaced reference to the root of the literal and fresh ids to
+// the nested literals, keeping the ids within the Ast unique.
+func (s *paramSubstituter) renumber(lit *exprpb.Expr, id int64) *exprpb.Expr {
+	lit.Id = id
+	switch lit.GetExprKind().(type) {
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range lit.GetListExpr().GetElements() {
+			s.nextID++
+			s.renumber(elem, s.nextID)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range lit.GetStructExpr().GetEntries() {
+			s.nextID++
+			entry.Id = s.nextID
+			s.nextID++
+			s.renumber(entry.GetMapKey(), s.nextID)
+			s.nextID++
+			s.renumber(entry.GetValue(), s.nextID)
+		}
+	}
+	return lit
+}
+
+// valueToLiteral returns the literal expression for a value of a primitive type, or a list or map
+// of them. The ids of the literal expressions are left unset.
+//
+// Map entries are ordered by their serialized keys, so that equal maps produce identical literals.
+func valueToLiteral(val ref.Val) (*exprpb.Expr, bool) {
+	var c *exprpb.Constant
+	switch v := val.(type) {
+	case types.Bool:
+		c = &exprpb.Constant{ConstantKind: &exprpb.Constant_BoolValue{BoolValue: bool(v)}}
+	case types.Bytes:
+		c = &exprpb.Constant{ConstantKind: &exprpb.Constant_BytesValue{BytesValue: []byte(v)}}
+	case types.Double:
+		c = &exprpb.Constant{ConstantKind: &exprpb.Constant_DoubleValue{DoubleValue: float64(v)}}
+	case types.Int:
+		c = &exprpb.Constant{ConstantKind: &exprpb.Constant_Int64Value{Int64Value: int64(v)}}
+	case types.Null:
+		c = &exprpb.Constant{ConstantKind: &exprpb.Constant_NullValue{NullValue: structpb.NullValue_NULL_VALUE}}
+	case types.String:
+		c = &exprpb.Constant{ConstantKind: &exprpb.Constant_StringValue{StringValue: string(v)}}
+	case types.Uint:
+		c = &exprpb.Constant{ConstantKind: &exprpb.Constant_Uint64Value{Uint64Value: uint64(v)}}
+	}
+	if c != nil {
+		return &exprpb.Expr{ExprKind: &exprpb.Expr_ConstExpr{ConstExpr: c}}, true
+	}
+	switch v := val.(type) {
+	case traits.Mapper:
+		type mapEntry struct {
+			key   []byte
+			entry *exprpb.Expr_CreateStruct_Entry
+		}
+		var entries []mapEntry
+		for it := v.Iterator(); it.HasNext() == types.True; {
+			k := it.Next()
+			keyLit, isLiteral := valueToLiteral(k)
+			if !isLiteral {
+				return nil, false
+			}
+			valLit, isLiteral := valueToLiteral(v.Get(k))
+			if !isLiteral {
+				return nil, false
+			}
+			b, err := proto.MarshalOptions{Deterministic: true}.Marshal(keyLit)
+			if err != nil {
+				return nil, false
+			}
+			entries = append(entries, mapEntry{
+				key: b,
+				entry: &exprpb.Expr_CreateStruct_Entry{
+					KeyKind: &exprpb.Expr_CreateStruct_Entry_MapKey{MapKey: keyLit},
+					Value:   valLit,
+				},
+			})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return bytes.Compare(entries[i].key, entries[j].key) < 0
+		})
+		structExpr := &exprpb.Expr_CreateStruct{}
+		for _, e := range entries {
+			structExpr.Entries = append(structExpr.Entries, e.entry)
+		}
+		return &exprpb.Expr{ExprKind: &exprpb.Expr_StructExpr{StructExpr: structExpr}}, true
+	case traits.Lister:
+		listExpr := &exprpb.Expr_CreateList{}
+		for it := v.Iterator(); it.HasNext() == types.True; {
+			elem, isLiteral := valueToLiteral(it.Next())
+			if !isLiteral {
+				return nil, false
+			}
+			listExpr.Elements = append(listExpr.Elements, elem)
+		}
+		return &exprpb.Expr{ExprKind: &exprpb.Expr_ListExpr{ListExpr: listExpr}}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,105 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+)
+
+func TestPrepare(t *testing.T) {
+	env, err := NewEnv(
+		Variable("x", StringType),
+		Variable("now", TimestampType),
+		EnableMacroCallTracking())
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	prepared, err := env.Prepare(`x in allowed && [1, 2].all(limit, limit < 3) && size(x) < limit`,
+		Param("allowed", ListType(StringType)), Param("limit", IntType))
+	if err != nil {
+		t.Fatalf("env.Prepare() failed: %v", err)
+	}
+	prg, err := prepared.Bind(map[string]any{"allowed": []string{"a", "bc"}, "limit": 2})
+	if err != nil {
+		t.Fatalf("prepared.Bind() failed: %v", err)
+	}
+	src, err := AstToString(programAst(prg))
+	if err != nil {
+		t.Fatalf("AstToString() failed: %v", err)
+	}
+	want := `x in ["a", "bc"] && [1, 2].all(limit, limit < 3) && size(x) < 2`
+	if src != want {
+		t.Errorf("bound expression got %q, wanted %q", src, want)
+	}
+	for x, want := range map[string]bool{"a": true, "bc": false, "d": false} {
+		out, _, err := prg.Eval(map[string]any{"x": x})
+		if err != nil {
+			t.Fatalf("prg.Eval(x=%q) failed: %v", x, err)
+		}
+		if out != types.Bool(want) {
+			t.Errorf("prg.Eval(x=%q) got %v, wanted %v", x, out, want)
+		}
+	}
+	cached, err := prepared.Bind(map[string]any{"allowed": []string{"a", "bc"}, "limit": 2})
+	if err != nil {
+		t.Fatalf("prepared.Bind() failed: %v", err)
+	}
+	if cached != prg {
+		t.Error("prepared.Bind() with the same values did not return the cached program")
+	}
+
+	// Parameters which are not expressible as literals are supplied as variables.
+	prepared, err = env.Prepare(`now < deadline`, Param("deadline", TimestampType))
+	if err != nil {
+		t.Fatalf("env.Prepare() failed: %v", err)
+	}
+	deadline := time.Unix(100, 0).UTC()
+	prg, err = prepared.Bind(map[string]any{"deadline": deadline})
+	if err != nil {
+		t.Fatalf("prepared.Bind() failed: %v", err)
+	}
+	out, _, err := prg.Eval(map[string]any{"now": time.Unix(50, 0).UTC(), "deadline": time.Unix(0, 0)})
+	if err != nil || out != types.True {
+		t.Errorf("prg.Eval() got %v, %v, wanted true", out, err)
+	}
+
+	badBindings := []struct {
+		values map[string]any
+		err    string
+	}{
+		{values: map[string]any{}, err: "missing value for parameter: deadline"},
+		{values: map[string]any{"deadline": deadline, "other": 1}, err: "no such parameter: other"},
+		{values: map[string]any{"deadline": "tomorrow"}, err: "parameter deadline expects a value of type google.protobuf.Timestamp"},
+	}
+	for _, tc := range badBindings {
+		if _, err := prepared.Bind(tc.values); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("prepared.Bind(%v) got error %v, wanted %q", tc.values, err, tc.err)
+		}
+	}
+	prepared, err = env.Prepare(`size(names)`, Param("names", ListType(StringType)))
+	if err != nil {
+		t.Fatalf("env.Prepare() failed: %v", err)
+	}
+	if _, err := prepared.Bind(map[string]any{"names": []any{"a", 1}}); err == nil {
+		t.Error("prepared.Bind() with a mistyped list element succeeded, wanted error")
+	}
+	if _, err := env.Prepare(`x == param`, Param("param", IntType)); err == nil {
+		t.Error("env.Prepare() with a mistyped expression succeeded, wanted error")
+	}
+}
@@ -16,12 +16,19 @@ package cel
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/google/cel-go/checker"
+	"github.com/google/cel-go/common/operators"
 	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
 	"github.com/google/cel-go/interpreter"
+	"github.com/google/cel-go/interpreter/functions"
 
 	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 )
@@ -54,6 +61,76 @@ type Program interface {
 	ContextEval(context.Context, any) (ref.Val, *EvalDetails, error)
 }
 
+// The operations below are package functions rather than methods of Program so that adding them
+// does not break the implementations of Program outside of this package. Each is implemented by
+// the programs created by an Env through an unexported interface, and returns an error naming the
+// type of any other Program.
+
+// EvalSeries returns a Series which repeatedly evaluates the program against the base input as it
+// is changed by a series of deltas.
+//
+// The base value may either be an `interpreter.Activation` or a `map[string]any`. When the program
+// is an IncrementalProgram, or a program derived from one such as by WithFunctions, it is notified
+// of the variables changed by each delta.
+func EvalSeries(prg Program, base any) (*Series, error) {
+	s, ok := prg.(seriesEvaluator)
+	if !ok {
+		return nil, fmt.Errorf("unsupported program type for EvalSeries: %T", prg)
+	}
+	return s.evalSeries(base)
+}
+
+// seriesEvaluator is implemented by the programs which support EvalSeries.
+type seriesEvaluator interface {
+	evalSeries(base any) (*Series, error)
+}
+
+// WithFunctions returns a copy of the program in which the implementations of the given overloads
+// replace those of the program. The plan of the program is shared with the copy, so creating the
+// copy is inexpensive.
+//
+// Only the overloads of functions declared with the Rebindable option may be replaced, and only
+// programs created by an Env support the replacement.
+func WithFunctions(prg Program, bindings ...*functions.Overload) (Program, error) {
+	r, ok := prg.(rebinder)
+	if !ok {
+		return nil, fmt.Errorf("unsupported program type for WithFunctions: %T", prg)
+	}
+	return r.withFunctions(bindings...)
+}
+
+// rebinder is implemented by the programs which support WithFunctions.
+type rebinder interface {
+	withFunctions(bindings ...*functions.Overload) (Program, error)
+}
+
+// Warmup evaluates the program once against sample input in warm-up mode, so that the internals
+// of the planned program which are initialized on first use, such as the reflection of protobuf
+// messages and the compilation of regular expressions, are initialized before the program serves
+// its first request. The evaluation follows the branches taken for the sample input, and so the
+// sample input should exercise the parts of the program to be warmed.
+//
+// The warm-up evaluation layers the sample input over the program's default variables as Eval
+// does, but skips the program's EvalPatchers, OverloadGuards, and call memoization, and neither
+// populates the caches of incremental programs nor records node timings. The result of the
+// evaluation is discarded, as are the errors it produces. An error is only returned when the input
+// is invalid, the program was not created by an Env, or the evaluation panics or is cancelled,
+// such as when it exceeds the cost limit of the program.
+//
+// The sampleVars value may either be an `interpreter.Activation` or a `map[string]any`.
+func Warmup(prg Program, sampleVars any) error {
+	w, ok := prg.(warmer)
+	if !ok {
+		return fmt.Errorf("unsupported program type for Warmup: %T", prg)
+	}
+	return w.warmup(sampleVars)
+}
+
+// warmer is implemented by the programs which support Warmup.
+type warmer interface {
+	warmup(sampleVars any) error
+}
+
 // NoVars returns an empty Activation.
 func NoVars() interpreter.Activation {
 	return interpreter.EmptyActivation()
@@ -89,10 +166,20 @@ func AttributePattern(varName string) *interpreter.AttributePattern {
 	return interpreter.NewAttributePattern(varName)
 }
 
+// ParseAttributePattern returns an AttributePattern parsed from a path expression with optional
+// wildcards, such as `request.headers.*` or `request.items[*].name`.
+//
+// See interpreter.ParseAttributePattern for the supported syntax.
+func ParseAttributePattern(pattern string) (*interpreter.AttributePattern, error) {
+	return interpreter.ParseAttributePattern(pattern)
+}
+
 // EvalDetails holds additional information observed during the Eval() call.
 type EvalDetails struct {
-	state       interpreter.EvalState
-	costTracker *interpreter.CostTracker
+	state         interpreter.EvalState
+	costTracker   *interpreter.CostTracker
+	memoryTracker *interpreter.MemoryTracker
+	provenance    *interpreter.ProvenanceTracker
 }
 
 // State of the evaluation, non-nil if the OptTrackState or OptExhaustiveEval is specified
@@ -111,6 +198,43 @@ func (ed *EvalDetails) ActualCost() *uint64 {
 	return &cost
 }
 
+// ActualCostByOverload returns the tracked cost of function calls aggregated by overload id when
+// `CostTracking` is enabled. Otherwise, returns nil.
+//
+// The cost attributed to each overload excludes the cost of evaluating the call arguments, so the
+// breakdown identifies the functions which dominate the actual cost of an expression.
+func (ed *EvalDetails) ActualCostByOverload() map[string]interpreter.OverloadCost {
+	if ed.costTracker == nil {
+		return nil
+	}
+	return ed.costTracker.OverloadCosts()
+}
+
+// ActualMemory returns the approximate number of bytes allocated for the values constructed
+// through the course of execution when a `MemoryLimit` is set. Otherwise, returns nil.
+func (ed *EvalDetails) ActualMemory() *uint64 {
+	if ed.memoryTracker == nil {
+		return nil
+	}
+	mem := ed.memoryTracker.ActualMemory()
+	return &mem
+}
+
+// Provenance returns the provenance of the result of the evaluation when OptTrackProvenance is
+// specified within EvalOptions. Otherwise, returns nil.
+func (ed *EvalDetails) Provenance() *interpreter.Provenance {
+	if ed.provenance == nil {
+		return nil
+	}
+	return ed.provenance.Result()
+}
+
+// ProvenanceTracker returns the provenance of the values of the subexpressions evaluated when
+// OptTrackProvenance is specified within EvalOptions. Otherwise, returns nil.
+func (ed *EvalDetails) ProvenanceTracker() *interpreter.ProvenanceTracker {
+	return ed.provenance
+}
+
 // prog is the internal implementation of the Program interface.
 type prog struct {
 	*Env
@@ -129,6 +253,63 @@ type prog struct {
 	interpretable     interpreter.Interpretable
 	callCostEstimator interpreter.ActualCostEstimator
 	costLimit         *uint64
+	costBudget        *interpreter.CostBudget
+	memoryLimit       *uint64
+	evalPool          *interpreter.EvalPool
+
+	// Plan-time reordering of logical operator operands by estimated cost.
+	reorderLogic     bool
+	reorderEstimator checker.CostEstimator
+	profile          *interpreter.Profile
+
+	// Overload ids of functions whose implementations may be replaced by WithFunctions.
+	rebindable map[string]bool
+
+	// Separator of the flat keys from which attributes are resolved, if set.
+	flatSeparator string
+
+	// Interceptor of the constant qualifiers of attributes, if set.
+	qualifierInterceptor interpreter.QualifierInterceptor
+	attributeMasks       []*interpreter.AttributeMask
+
+	// Cache of subexpression results retained between evaluations of an IncrementalProgram.
+	incremental *interpreter.IncrementalCache
+
+	// Ast from which the program was planned, used to explain evaluation results.
+	ast *Ast
+
+	// Guards invoked before the implementations of overloads, keyed by overload id or function name.
+	guards map[string][]OverloadGuard
+
+	// Memoization of the results of calls to pure overloads, shared across evaluations when the
+	// call cache is set.
+	memoizeCalls bool
+	callCache    *CallCache
+
+	// Per-node wall time recorded across evaluations, if set.
+	nodeTimings *interpreter.NodeTimings
+
+	// Isolation of the evaluation from the implementations of declared functions, if set.
+	sandbox *functionSandbox
+
+	// Patchers which may substitute the values produced by evaluation steps, in registration order.
+	patchers []interpreter.EvalPatcher
+
+	// Source of the time returned by now(), if set.
+	clock func() time.Time
+
+	// Provider of the flag states tested by flags.enabled(), if set.
+	flags FlagProvider
+
+	// Whether the elements of the list built by the top-level comprehension are streamed.
+	streamResults bool
+
+	// Whether the program may be planned for scalar evaluation, which is only the case when no
+	// decorator or attribute factory alters the results of the planned Interpretable.
+	scalarEligible bool
+
+	// Whether comprehensions record their progress into the checkpoint of EvalSlice.
+	checkpointing bool
 }
 
 func (p *prog) clone() *prog {
@@ -139,6 +320,11 @@ func (p *prog) clone() *prog {
 		dispatcher:              p.dispatcher,
 		interpreter:             p.interpreter,
 		interruptCheckFrequency: p.interruptCheckFrequency,
+		rebindable:              p.rebindable,
+		ast:                     p.ast,
+		memoizeCalls:            p.memoizeCalls,
+		callCache:               p.callCache,
+		scalarEligible:          p.scalarEligible,
 	}
 }
 
@@ -167,12 +353,50 @@ func newProgram(e *Env, ast *Ast, opts []ProgramOption) (Program, error) {
 		}
 	}
 
+	// Bind the default values of variables beneath the other bindings of the evaluation.
+	if len(e.variableDefaults) > 0 {
+		bindings := make(map[string]any, len(e.variableDefaults))
+		for name, val := range e.variableDefaults {
+			bindings[name] = val
+		}
+		defaults, err := interpreter.NewActivation(bindings)
+		if err != nil {
+			return nil, err
+		}
+		if p.defaultVars != nil {
+			defaults = interpreter.NewHierarchicalActivation(defaults, p.defaultVars)
+		}
+		p.defaultVars = defaults
+	}
+
+	// Parallel evaluation applies to exhaustive evaluation, and excludes the features which depend on
+	// the order of evaluation or which are not safe for concurrent use.
+	if p.evalPool != nil {
+		if p.evalOpts&OptExhaustiveEval != OptExhaustiveEval {
+			return nil, errors.New("parallel evaluation requires OptExhaustiveEval")
+		}
+		if p.evalOpts&(OptTrackCost|OptCacheAttributes|OptTrackProvenance) != 0 {
+			return nil, errors.New("parallel evaluation is incompatible with OptTrackCost, OptCacheAttributes and OptTrackProvenance")
+		}
+	}
+
+	// Reorder logical operands by estimated cost before planning.
+	if p.reorderLogic && ast.IsChecked() {
+		ast = e.reorderLogicalOperands(ast, p.reorderEstimator, p.profile)
+	}
+	p.ast = ast
+
 	// Add the function bindings created via Function() options.
 	for _, fn := range e.functions {
 		bindings, err := fn.bindings()
 		if err != nil {
 			return nil, err
 		}
+		if p.sandbox != nil {
+			for i, b := range bindings {
+				bindings[i] = p.sandbox.wrap(b)
+			}
+		}
 		err = disp.Add(bindings...)
 		if err != nil {
 			return nil, err
@@ -181,30 +405,133 @@ func newProgram(e *Env, ast *Ast, opts []ProgramOption) (Program, error) {
 
 	// Set the attribute factory after the options have been set.
 	var attrFactory interpreter.AttributeFactory
-	if p.evalOpts&OptPartialEval == OptPartialEval {
+	if p.flatSeparator != "" {
+		attrFactory = interpreter.NewFlatAttributeFactory(e.Container, e.adapter, e.provider, p.flatSeparator)
+	} else if p.evalOpts&OptPartialEval == OptPartialEval {
 		attrFactory = interpreter.NewPartialAttributeFactory(e.Container, e.adapter, e.provider)
 	} else {
 		attrFactory = interpreter.NewAttributeFactory(e.Container, e.adapter, e.provider)
 	}
+	if p.qualifierInterceptor != nil {
+		attrFactory = interpreter.NewInterceptingAttributeFactory(attrFactory, p.qualifierInterceptor)
+	}
+	if len(p.attributeMasks) != 0 {
+		attrFactory = interpreter.NewMaskingAttributeFactory(attrFactory, e.adapter, p.attributeMasks...)
+	}
+	if p.evalOpts&OptCacheAttributes == OptCacheAttributes {
+		attrFactory = interpreter.NewCachingAttributeFactory(attrFactory)
+	}
 	interp := interpreter.NewInterpreter(disp, e.Container, e.provider, e.adapter, attrFactory)
 	p.interpreter = interp
 
 	// Translate the EvalOption flags into InterpretableDecorator instances.
 	decorators := make([]interpreter.InterpretableDecorator, len(p.decorators))
 	copy(decorators, p.decorators)
+	// Count the decorators which leave the results of scalar subexpressions unchanged, since any
+	// other decorator would be bypassed by scalar evaluation.
+	scalarSafeDecorators := 0
+	if p.flatSeparator != "" {
+		decorators = append(decorators, interpreter.FlatPresenceTests())
+	}
+	// Evaluate now() using the clock of the evaluation or program.
+	if e.HasLibrary(nowLibraryName) {
+		decorators = append(decorators, clockCalls(p.clock, e.HasFeature(featureDeterministicEval)))
+		scalarSafeDecorators++
+	}
+	// Test the flags of flags.enabled() against the provider of the program.
+	if e.HasLibrary(flagsLibraryName) {
+		decorators = append(decorators, flagCalls(p.flags))
+		scalarSafeDecorators++
+	}
+	// Deliver the elements built by the top-level comprehension to the sink of StreamEval.
+	if p.streamResults {
+		if !isStreamableComprehension(ast.Expr()) {
+			return nil, errors.New("streaming results requires a top-level comprehension which builds a list")
+		}
+		decorators = append(decorators, interpreter.StreamFold(ast.Expr().GetId()))
+		scalarSafeDecorators++
+	}
+	// Record the progress of the comprehensions into the checkpoint of EvalSlice.
+	if p.checkpointing {
+		decorators = append(decorators, interpreter.CheckpointFolds(checkpointedComprehensions(ast.Expr())...))
+		scalarSafeDecorators++
+	}
+
+	// Allow the implementations of rebindable functions to be replaced at evaluation time.
+	p.rebindable = e.rebindableOverloads()
+	if len(p.rebindable) > 0 {
+		ids := make([]string, 0, len(p.rebindable))
+		for id := range p.rebindable {
+			ids = append(ids, id)
+		}
+		decorators = append(decorators, interpreter.LateBindCalls(ids...))
+	}
+
+	// Evaluate the ordering comparisons involving null to false when null-safe comparisons are
+	// enabled.
+	if e.HasFeature(featureNullSafeComparisons) {
+		p, err = GuardOverloads(nullSafeComparison,
+			operators.Less, operators.LessEquals, operators.Greater, operators.GreaterEquals)(p)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Evaluate the ordering comparisons of lists and maps when aggregate comparisons are enabled.
+	if e.HasFeature(featureAggregateComparisons) {
+		decorators = append(decorators, interpreter.CompareAggregates(disp))
+	}
+	// Apply the overflow policy of the environment to integer arithmetic before guarding the calls,
+	// so that guards observe the arguments of the arithmetic operators.
+	if e.overflowPolicy != interpreter.OverflowError {
+		decorators = append(decorators, interpreter.ApplyOverflowPolicy(disp, e.overflowPolicy))
+	}
+	// Validate the arguments of guarded calls before their implementations are invoked.
+	if len(p.guards) > 0 {
+		decorators = append(decorators, interpreter.GuardCalls(disp, p.callGuards()))
+	}
+	// Memoize the results of pure calls after guarding them, so that memoized results are returned
+	// without invoking the guards.
+	if p.memoizeCalls {
+		var memo interpreter.CallMemo
+		if p.callCache != nil {
+			memo = p.callCache
+		}
+		decorators = append(decorators, interpreter.MemoizeCalls(disp, memo, e.pureOverloads()...))
+	}
 
 	// Enable interrupt checking if there's a non-zero check frequency
 	if p.interruptCheckFrequency > 0 {
 		decorators = append(decorators, interpreter.InterruptableEval())
+		scalarSafeDecorators++
+	}
+	if p.evalOpts&OptSortedMapIteration == OptSortedMapIteration {
+		decorators = append(decorators, interpreter.SortedMapIteration())
+		scalarSafeDecorators++
 	}
 	// Enable constant folding first.
 	if p.evalOpts&OptOptimize == OptOptimize {
+		convs, err := e.conversionBindings()
+		if err != nil {
+			return nil, err
+		}
+		if len(convs) > 0 {
+			decorators = append(decorators, foldConversions(convs))
+			scalarSafeDecorators++
+		}
 		decorators = append(decorators, interpreter.Optimize())
+		scalarSafeDecorators++
 		p.regexOptimizations = append(p.regexOptimizations, interpreter.MatchesRegexOptimization)
 	}
 	// Enable regex compilation of constants immediately after folding constants.
 	if len(p.regexOptimizations) > 0 {
 		decorators = append(decorators, interpreter.CompileRegexConstants(p.regexOptimizations...))
+		scalarSafeDecorators++
+	}
+	// Check the inputs of the standard string functions after constant folding, so that only the
+	// inputs computed at evaluation time are checked.
+	if e.stringLimits != (interpreter.StringLimits{}) {
+		decorators = append(decorators, interpreter.LimitStrings(e.stringLimits))
 	}
 	// Enable compile-time checking of syntax/cardinality for string.format calls.
 	if p.evalOpts&OptCheckStringFormat == OptCheckStringFormat {
@@ -215,7 +542,7 @@ func newProgram(e *Env, ast *Ast, opts []ProgramOption) (Program, error) {
 				if err != nil {
 					return false, err
 				}
-				if t.kind == DynKind {
+				if t.kind == DynKind || e.isFormatterType(t) {
 					return true, nil
 				}
 				for _, vt := range validTypes {
@@ -236,13 +563,37 @@ func newProgram(e *Env, ast *Ast, opts []ProgramOption) (Program, error) {
 			}
 		}
 		decorators = append(decorators, interpreter.InterpolateFormattedString(isValidType))
+		scalarSafeDecorators++
+	}
+	// Redact errors from calls and attributes which involve sensitive values.
+	var sensitiveIDs map[int64]bool
+	if len(e.sensitivePaths) > 0 {
+		sensitiveIDs = e.sensitiveExprIDs(ast)
+		decorators = append(decorators, redactErrors(sensitiveIDs, sensitiveAttrRoots(ast.Expr(), sensitiveIDs)))
+	}
+	// Patch the evaluation steps after the other static decorators so that the observers observe
+	// the patched values.
+	patchers := p.patchers
+	if p.evalOpts&OptErrorLocations == OptErrorLocations {
+		// Locate the errors before the configured patchers so that they observe the located errors.
+		patchers = append([]interpreter.EvalPatcher{locateErrors(ast)}, patchers...)
 	}
+	if len(patchers) > 0 {
+		decorators = append(decorators, interpreter.Patch(patchers...))
+	}
+
+	p.scalarEligible = len(decorators) == scalarSafeDecorators &&
+		p.flatSeparator == "" && p.qualifierInterceptor == nil && len(p.attributeMasks) == 0 &&
+		p.sandbox == nil && p.incremental == nil && p.nodeTimings == nil && p.memoryLimit == nil
 
 	// Enable exhaustive eval, state tracking and cost tracking last since they require a factory.
-	if p.evalOpts&(OptExhaustiveEval|OptTrackState|OptTrackCost) != 0 {
-		factory := func(state interpreter.EvalState, costTracker *interpreter.CostTracker) (Program, error) {
+	if p.evalOpts&(OptExhaustiveEval|OptTrackState|OptTrackCost|OptTrackProvenance) != 0 || p.memoryLimit != nil {
+		factory := func(state interpreter.EvalState, costTracker *interpreter.CostTracker,
+			memoryTracker *interpreter.MemoryTracker, provenance *interpreter.ProvenanceTracker) (Program, error) {
 			costTracker.Estimator = p.callCostEstimator
 			costTracker.Limit = p.costLimit
+			costTracker.Budget = p.costBudget
+			memoryTracker.Limit = p.memoryLimit
 			// Limit capacity to guarantee a reallocation when calling 'append(decs, ...)' below. This
 			// prevents the underlying memory from being shared between factory function calls causing
 			// undesired mutations.
@@ -251,26 +602,130 @@ func newProgram(e *Env, ast *Ast, opts []ProgramOption) (Program, error) {
 
 			if p.evalOpts&(OptExhaustiveEval|OptTrackState) != 0 {
 				// EvalStateObserver is required for OptExhaustiveEval.
-				observers = append(observers, interpreter.EvalStateObserver(state))
+				observer := interpreter.EvalStateObserver(state)
+				if len(sensitiveIDs) > 0 {
+					observer = redactObserver(sensitiveIDs, observer)
+				}
+				observers = append(observers, observer)
 			}
 			if p.evalOpts&OptTrackCost == OptTrackCost {
 				observers = append(observers, interpreter.CostObserver(costTracker))
 			}
+			if p.memoryLimit != nil {
+				observers = append(observers, interpreter.MemoryObserver(memoryTracker))
+			}
 
 			// Enable exhaustive eval over a basic observer since it offers a superset of features.
 			if p.evalOpts&OptExhaustiveEval == OptExhaustiveEval {
-				decs = append(decs, interpreter.ExhaustiveEval(), interpreter.Observe(observers...))
+				decs = append(decs, interpreter.ExhaustiveEval())
+				// Evaluate independent subexpressions concurrently, serializing the observers.
+				if p.evalPool != nil {
+					decs = append(decs, interpreter.ParallelEval(p.evalPool))
+					observers = synchronizeObservers(observers)
+				}
+				decs = append(decs, interpreter.Observe(observers...))
 			} else if len(observers) > 0 {
 				decs = append(decs, interpreter.Observe(observers...))
 			}
+			if p.evalOpts&OptTrackProvenance == OptTrackProvenance {
+				decs = append(decs, interpreter.TrackProvenance(provenance))
+			}
+			// Cache subexpression results last so that cached results bypass all other decorators.
+			if p.incremental != nil {
+				decs = append(decs, interpreter.IncrementalEval(p.incremental))
+			}
+			// Time nodes last so that the timings include the overhead of the other decorators.
+			if p.nodeTimings != nil {
+				decs = append(decs, interpreter.TimeNodes(p.nodeTimings))
+			}
 
 			return p.clone().initInterpretable(ast, decs)
 		}
-		return newProgGen(factory)
+		return newProgGen(factory, p.rebindable, ast, p.evalOpts&OptTrackProvenance == OptTrackProvenance)
+	}
+	if p.incremental != nil {
+		decorators = append(decorators, interpreter.IncrementalEval(p.incremental))
+	}
+	if p.nodeTimings != nil {
+		decorators = append(decorators, interpreter.TimeNodes(p.nodeTimings))
 	}
 	return p.initInterpretable(ast, decorators)
 }
 
+// isFormatterType returns whether the type is registered with a runtime type which supplies its
+// own representation within formatted strings.
+func (e *Env) isFormatterType(t *Type) bool {
+	if t.kind != OpaqueKind && t.kind != StructKind {
+		return false
+	}
+	val, found := e.provider.FindIdent(t.runtimeType.TypeName())
+	if !found {
+		return false
+	}
+	runtimeType, isType := val.(ref.Type)
+	return isType && runtimeType.HasTrait(traits.FormatterType)
+}
+
+// synchronizeObservers combines the observers into a single observer which may be called
+// concurrently.
+func synchronizeObservers(observers []interpreter.EvalObserver) []interpreter.EvalObserver {
+	var mu sync.Mutex
+	return []interpreter.EvalObserver{
+		func(id int64, programStep any, val ref.Val) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, observer := range observers {
+				observer(id, programStep, val)
+			}
+		},
+	}
+}
+
+// nullSafeComparison is an OverloadGuard which vetoes ordering comparisons with a null operand,
+// evaluating them to false.
+func nullSafeComparison(ctx context.Context, overloadID string, args []ref.Val) ref.Val {
+	for _, arg := range args {
+		if arg.Type() == types.NullType {
+			return types.False
+		}
+	}
+	return nil
+}
+
+// callGuards adapts the overload guards of the program to the interpreter, supplying the context
+// of the evaluation to each guard.
+func (p *prog) callGuards() map[string]interpreter.CallGuard {
+	guards := make(map[string]interpreter.CallGuard, len(p.guards))
+	for id, gs := range p.guards {
+		gs := gs
+		guards[id] = func(vars interpreter.Activation, overloadID string, args []ref.Val) ref.Val {
+			ctx := interpreter.EvalContext(vars)
+			for _, g := range gs {
+				if veto := g(ctx, overloadID, args); veto != nil {
+					return veto
+				}
+			}
+			return nil
+		}
+	}
+	return guards
+}
+
+// rebindableOverloads returns the set of overload ids belonging to functions declared with the
+// Rebindable option.
+func (e *Env) rebindableOverloads() map[string]bool {
+	ids := map[string]bool{}
+	for _, fn := range e.functions {
+		if !fn.rebindable {
+			continue
+		}
+		for _, o := range fn.overloads {
+			ids[o.id] = true
+		}
+	}
+	return ids
+}
+
 func (p *prog) initInterpretable(ast *Ast, decs []interpreter.InterpretableDecorator) (*prog, error) {
 	// Unchecked programs do not contain type and reference information and may be slower to execute.
 	if !ast.IsChecked() {
@@ -279,7 +734,7 @@ func (p *prog) initInterpretable(ast *Ast, decs []interpreter.InterpretableDecor
 		if err != nil {
 			return nil, err
 		}
-		p.interpretable = interpretable
+		p.interpretable = p.maybeLowerToBytecode(interpretable)
 		return p, nil
 	}
 
@@ -293,12 +748,51 @@ func (p *prog) initInterpretable(ast *Ast, decs []interpreter.InterpretableDecor
 	if err != nil {
 		return nil, err
 	}
-	p.interpretable = interpretable
+	p.interpretable = p.maybePlanScalar(checked, p.maybeLowerToBytecode(interpretable))
 	return p, nil
 }
 
+// maybePlanScalar replaces the planned Interpretable with an unboxed scalar evaluation of the
+// expression when OptScalarEval is set and the expression is eligible, retaining the planned
+// Interpretable as the fallback.
+func (p *prog) maybePlanScalar(checked *exprpb.CheckedExpr, i interpreter.Interpretable) interpreter.Interpretable {
+	if p.evalOpts&OptScalarEval != OptScalarEval ||
+		p.evalOpts&(OptTrackState|OptTrackCost|OptTrackProvenance|OptPartialEval) != 0 ||
+		!p.scalarEligible {
+		return i
+	}
+	if scalar, ok := interpreter.NewScalarInterpretable(checked, i); ok {
+		return scalar
+	}
+	return i
+}
+
+// maybeLowerToBytecode converts the planned Interpretable into bytecode when OptBytecode is set.
+func (p *prog) maybeLowerToBytecode(i interpreter.Interpretable) interpreter.Interpretable {
+	if p.evalOpts&OptBytecode == OptBytecode {
+		return interpreter.LowerToBytecode(i)
+	}
+	return i
+}
+
 // Eval implements the Program interface method.
-func (p *prog) Eval(input any) (v ref.Val, det *EvalDetails, err error) {
+func (p *prog) Eval(input any) (ref.Val, *EvalDetails, error) {
+	v, err := p.eval(input, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The output of an internal Eval may have a value (`v`) that is a types.Err. This step
+	// translates the CEL value to a Go error response. This interface does not quite match the
+	// RPC signature which allows for multiple errors to be returned, but should be sufficient.
+	if types.IsError(v) {
+		return v, nil, v.(*types.Err)
+	}
+	return v, nil, nil
+}
+
+// eval evaluates the planned Interpretable against the input, in warm-up mode when `warmup` is
+// set, and returns an error when the input is invalid or the evaluation panics.
+func (p *prog) eval(input any, warmup bool) (v ref.Val, err error) {
 	// Configure error recovery for unexpected panics during evaluation. Note, the use of named
 	// return values makes it possible to modify the error response during the recovery
 	// function.
@@ -321,19 +815,20 @@ func (p *prog) Eval(input any) (v ref.Val, det *EvalDetails, err error) {
 		vars = activationPool.Setup(v)
 		defer activationPool.Put(vars)
 	default:
-		return nil, nil, fmt.Errorf("invalid input, wanted Activation or map[string]any, got: (%T)%v", input, input)
+		return nil, fmt.Errorf("invalid input, wanted Activation or map[string]any, got: (%T)%v", input, input)
 	}
 	if p.defaultVars != nil {
 		vars = interpreter.NewHierarchicalActivation(p.defaultVars, vars)
 	}
-	v = p.interpretable.Eval(vars)
-	// The output of an internal Eval may have a value (`v`) that is a types.Err. This step
-	// translates the CEL value to a Go error response. This interface does not quite match the
-	// RPC signature which allows for multiple errors to be returned, but should be sufficient.
-	if types.IsError(v) {
-		err = v.(*types.Err)
+	if warmup {
+		vars = interpreter.NewWarmupActivation(vars)
+	} else if p.memoizeCalls && p.callCache == nil {
+		vars = interpreter.NewCallMemoActivation(vars)
 	}
-	return
+	if p.evalOpts&OptCacheAttributes == OptCacheAttributes {
+		vars = interpreter.NewAttributeCacheActivation(vars)
+	}
+	return p.interpretable.Eval(vars), nil
 }
 
 // ContextEval implements the Program interface.
@@ -346,12 +841,12 @@ func (p *prog) ContextEval(ctx context.Context, input any) (ref.Val, *EvalDetail
 	var vars interpreter.Activation
 	switch v := input.(type) {
 	case interpreter.Activation:
-		vars = ctxActivationPool.Setup(v, ctx.Done(), p.interruptCheckFrequency)
+		vars = ctxActivationPool.Setup(v, ctx, p.interruptCheckFrequency)
 		defer ctxActivationPool.Put(vars)
 	case map[string]any:
 		rawVars := activationPool.Setup(v)
 		defer activationPool.Put(rawVars)
-		vars = ctxActivationPool.Setup(rawVars, ctx.Done(), p.interruptCheckFrequency)
+		vars = ctxActivationPool.Setup(rawVars, ctx, p.interruptCheckFrequency)
 		defer ctxActivationPool.Put(vars)
 	default:
 		return nil, nil, fmt.Errorf("invalid input, wanted Activation or map[string]any, got: (%T)%v", input, input)
@@ -359,23 +854,44 @@ func (p *prog) ContextEval(ctx context.Context, input any) (ref.Val, *EvalDetail
 	return p.Eval(vars)
 }
 
+// withFunctions implements the rebinder interface method.
+func (p *prog) withFunctions(bindings ...*functions.Overload) (Program, error) {
+	return newBoundProgram(p, p.rebindable, nil, bindings)
+}
+
+// evalSeries implements the seriesEvaluator interface method.
+func (p *prog) evalSeries(base any) (*Series, error) {
+	return newSeries(p, base)
+}
+
+// warmup implements the warmer interface method.
+func (p *prog) warmup(sampleVars any) error {
+	_, err := p.eval(sampleVars, true)
+	return err
+}
+
 // progFactory is a helper alias for marking a program creation factory function.
-type progFactory func(interpreter.EvalState, *interpreter.CostTracker) (Program, error)
+type progFactory func(interpreter.EvalState, *interpreter.CostTracker, *interpreter.MemoryTracker,
+	*interpreter.ProvenanceTracker) (Program, error)
 
 // progGen holds a reference to a progFactory instance and implements the Program interface.
 type progGen struct {
-	factory progFactory
+	factory         progFactory
+	rebindable      map[string]bool
+	ast             *Ast
+	trackProvenance bool
 }
 
 // newProgGen tests the factory object by calling it once and returns a factory-based Program if
 // the test is successful.
-func newProgGen(factory progFactory) (Program, error) {
+func newProgGen(factory progFactory, rebindable map[string]bool, ast *Ast, trackProvenance bool) (Program, error) {
 	// Test the factory to make sure that configuration errors are spotted at config
-	_, err := factory(interpreter.NewEvalState(), &interpreter.CostTracker{})
+	_, err := factory(interpreter.NewEvalState(), &interpreter.CostTracker{}, &interpreter.MemoryTracker{},
+		interpreter.NewProvenanceTracker())
 	if err != nil {
 		return nil, err
 	}
-	return &progGen{factory: factory}, nil
+	return &progGen{factory: factory, rebindable: rebindable, ast: ast, trackProvenance: trackProvenance}, nil
 }
 
 // Eval implements the Program interface method.
@@ -385,12 +901,18 @@ func (gen *progGen) Eval(input any) (ref.Val, *EvalDetails, error) {
 	// results.
 	state := interpreter.NewEvalState()
 	costTracker := &interpreter.CostTracker{}
-	det := &EvalDetails{state: state, costTracker: costTracker}
+	memoryTracker := &interpreter.MemoryTracker{}
+	det := &EvalDetails{state: state, costTracker: costTracker, memoryTracker: memoryTracker}
+	var provenance *interpreter.ProvenanceTracker
+	if gen.trackProvenance {
+		provenance = interpreter.NewProvenanceTracker()
+		det.provenance = provenance
+	}
 
 	// Generate a new instance of the interpretable using the factory configured during the call to
 	// newProgram(). It is incredibly unlikely that the factory call will generate an error given
 	// the factory test performed within the Program() call.
-	p, err := gen.factory(state, costTracker)
+	p, err := gen.factory(state, costTracker, memoryTracker, provenance)
 	if err != nil {
 		return nil, det, err
 	}
@@ -413,12 +935,18 @@ func (gen *progGen) ContextEval(ctx context.Context, input any) (ref.Val, *EvalD
 	// results.
 	state := interpreter.NewEvalState()
 	costTracker := &interpreter.CostTracker{}
-	det := &EvalDetails{state: state, costTracker: costTracker}
+	memoryTracker := &interpreter.MemoryTracker{}
+	det := &EvalDetails{state: state, costTracker: costTracker, memoryTracker: memoryTracker}
+	var provenance *interpreter.ProvenanceTracker
+	if gen.trackProvenance {
+		provenance = interpreter.NewProvenanceTracker()
+		det.provenance = provenance
+	}
 
 	// Generate a new instance of the interpretable using the factory configured during the call to
 	// newProgram(). It is incredibly unlikely that the factory call will generate an error given
 	// the factory test performed within the Program() call.
-	p, err := gen.factory(state, costTracker)
+	p, err := gen.factory(state, costTracker, memoryTracker, provenance)
 	if err != nil {
 		return nil, det, err
 	}
@@ -431,19 +959,111 @@ func (gen *progGen) ContextEval(ctx context.Context, input any) (ref.Val, *EvalD
 	return v, det, nil
 }
 
+// withFunctions implements the rebinder interface method.
+func (gen *progGen) withFunctions(bindings ...*functions.Overload) (Program, error) {
+	return newBoundProgram(gen, gen.rebindable, nil, bindings)
+}
+
+// evalSeries implements the seriesEvaluator interface method.
+func (gen *progGen) evalSeries(base any) (*Series, error) {
+	return newSeries(gen, base)
+}
+
+// warmup implements the warmer interface method.
+func (gen *progGen) warmup(sampleVars any) error {
+	p, err := gen.factory(interpreter.NewEvalState(), &interpreter.CostTracker{}, &interpreter.MemoryTracker{},
+		interpreter.NewProvenanceTracker())
+	if err != nil {
+		return err
+	}
+	return Warmup(p, sampleVars)
+}
+
+// boundProgram evaluates a shared program with a set of function bindings which replace the
+// implementations of the program's rebindable functions.
+type boundProgram struct {
+	base       Program
+	rebindable map[string]bool
+	bindings   []*functions.Overload
+}
+
+// newBoundProgram returns a boundProgram which evaluates the base program with the function
+// bindings, where the bindings provided later take precedence.
+func newBoundProgram(base Program, rebindable map[string]bool,
+	prev, bindings []*functions.Overload) (Program, error) {
+	merged := make([]*functions.Overload, 0, len(prev)+len(bindings))
+	merged = append(merged, prev...)
+	for _, b := range bindings {
+		if !rebindable[b.Operator] {
+			return nil, fmt.Errorf("overload is not rebindable: %s", b.Operator)
+		}
+		merged = append(merged, b)
+	}
+	return &boundProgram{base: base, rebindable: rebindable, bindings: merged}, nil
+}
+
+// Eval implements the Program interface method.
+func (bp *boundProgram) Eval(input any) (ref.Val, *EvalDetails, error) {
+	vars, err := bp.activation(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bp.base.Eval(vars)
+}
+
+// ContextEval implements the Program interface method.
+func (bp *boundProgram) ContextEval(ctx context.Context, input any) (ref.Val, *EvalDetails, error) {
+	vars, err := bp.activation(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bp.base.ContextEval(ctx, vars)
+}
+
+// withFunctions implements the rebinder interface method.
+func (bp *boundProgram) withFunctions(bindings ...*functions.Overload) (Program, error) {
+	return newBoundProgram(bp.base, bp.rebindable, bp.bindings, bindings)
+}
+
+// evalSeries implements the seriesEvaluator interface method.
+func (bp *boundProgram) evalSeries(base any) (*Series, error) {
+	return newSeries(bp, base)
+}
+
+// warmup implements the warmer interface method.
+func (bp *boundProgram) warmup(sampleVars any) error {
+	vars, err := bp.activation(sampleVars)
+	if err != nil {
+		return err
+	}
+	return Warmup(bp.base, vars)
+}
+
+// activation wraps the input in an Activation which supplies the program's function bindings.
+func (bp *boundProgram) activation(input any) (interpreter.Activation, error) {
+	vars, err := interpreter.NewActivation(input)
+	if err != nil {
+		return nil, err
+	}
+	return interpreter.NewFunctionBindingActivation(vars, bp.bindings...), nil
+}
+
 type ctxEvalActivation struct {
 	parent                  interpreter.Activation
+	ctx                     context.Context
 	interrupt               <-chan struct{}
-	interruptCheckCount     uint
+	interruptCheckCount     uint64
 	interruptCheckFrequency uint
 }
 
 // ResolveName implements the Activation interface method, but adds a special #interrupted variable
-// which is capable of testing whether a 'done' signal is provided from a context.Context channel.
+// which is capable of testing whether a 'done' signal is provided from a context.Context channel,
+// and a special #context variable which holds the context.Context itself.
 func (a *ctxEvalActivation) ResolveName(name string) (any, bool) {
 	if name == "#interrupted" {
-		a.interruptCheckCount++
-		if a.interruptCheckCount%a.interruptCheckFrequency == 0 {
+		// The count is updated atomically as subexpressions may be evaluated concurrently.
+		count := atomic.AddUint64(&a.interruptCheckCount, 1)
+		if count%uint64(a.interruptCheckFrequency) == 0 {
 			select {
 			case <-a.interrupt:
 				return true, true
@@ -453,6 +1073,9 @@ func (a *ctxEvalActivation) ResolveName(name string) (any, bool) {
 		}
 		return nil, false
 	}
+	if name == "#context" {
+		return a.ctx, true
+	}
 	return a.parent.ResolveName(name)
 }
 
@@ -475,17 +1098,21 @@ type ctxEvalActivationPool struct {
 }
 
 // Setup initializes a pooled Activation with the ability check for context.Context cancellation
-func (p *ctxEvalActivationPool) Setup(vars interpreter.Activation, done <-chan struct{}, interruptCheckRate uint) *ctxEvalActivation {
+func (p *ctxEvalActivationPool) Setup(vars interpreter.Activation, ctx context.Context, interruptCheckRate uint) *ctxEvalActivation {
 	a := p.Pool.Get().(*ctxEvalActivation)
 	a.parent = vars
-	a.interrupt = done
+	a.ctx = ctx
+	a.interrupt = ctx.Done()
 	a.interruptCheckCount = 0
 	a.interruptCheckFrequency = interruptCheckRate
 	return a
 }
 
 type evalActivation struct {
-	vars     map[string]any
+	vars map[string]any
+
+	// mu guards the lazyVars, which may be resolved concurrently by a parallel evaluation.
+	mu       sync.Mutex
 	lazyVars map[string]any
 }
 
@@ -506,24 +1133,27 @@ func (a *evalActivation) ResolveName(name string) (any, bool) {
 	}
 	switch obj := v.(type) {
 	case func() ref.Val:
-		if resolved, found := a.lazyVars[name]; found {
-			return resolved, true
-		}
-		lazy := obj()
-		a.lazyVars[name] = lazy
-		return lazy, true
+		return a.resolveLazy(name, func() any { return obj() }), true
 	case func() any:
-		if resolved, found := a.lazyVars[name]; found {
-			return resolved, true
-		}
-		lazy := obj()
-		a.lazyVars[name] = lazy
-		return lazy, true
+		return a.resolveLazy(name, obj), true
 	default:
 		return obj, true
 	}
 }
 
+// resolveLazy returns the value of the lazy binding, invoking it only for the first resolution of
+// the name.
+func (a *evalActivation) resolveLazy(name string, binding func() any) any {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if resolved, found := a.lazyVars[name]; found {
+		return resolved
+	}
+	lazy := binding()
+	a.lazyVars[name] = lazy
+	return lazy
+}
+
 // Parent implements the interpreter.Activation interface
 func (a *evalActivation) Parent() interpreter.Activation {
 	return nil
@@ -0,0 +1,122 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrackProvenance(t *testing.T) {
+	env, err := NewEnv(
+		Variable("req", MapType(StringType, DynType)),
+		Variable("limits", ListType(IntType)),
+		Variable("override", BoolType),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	vars := map[string]any{
+		"req":      map[string]any{"age": 20, "x-country": "US"},
+		"limits":   []int{10, 30},
+		"override": true,
+	}
+	tests := []struct {
+		expr   string
+		inputs []string
+	}{
+		{
+			expr:   `req.age >= 18 && req['x-country'] in ['US', 'CA'] || override`,
+			inputs: []string{"req.age", `req["x-country"]`},
+		},
+		{
+			expr:   `override || req.age > 18`,
+			inputs: []string{"override"},
+		},
+		{
+			expr:   `limits.exists(l, l > req.age)`,
+			inputs: []string{"limits", "req.age"},
+		},
+		{
+			expr:   `limits[1] + 1`,
+			inputs: []string{"limits[1]"},
+		},
+		{
+			expr:   `[1, 2].map(x, x * 2)`,
+			inputs: []string{},
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%q) failed: %v", tc.expr, iss.Err())
+			}
+			prg, err := env.Program(ast, EvalOptions(OptTrackProvenance))
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			_, det, err := prg.Eval(vars)
+			if err != nil {
+				t.Fatalf("prg.Eval() failed: %v", err)
+			}
+			prov := det.Provenance()
+			if prov == nil {
+				t.Fatal("det.Provenance() returned nil")
+			}
+			if !reflect.DeepEqual(prov.Inputs, tc.inputs) {
+				t.Errorf("det.Provenance().Inputs got %v, wanted %v", prov.Inputs, tc.inputs)
+			}
+			if !containsID(prov.ExprIDs, ast.Expr().GetId()) {
+				t.Errorf("det.Provenance().ExprIDs got %v, wanted the root id %d", prov.ExprIDs, ast.Expr().GetId())
+			}
+			// Every intermediate value carries the provenance of its subexpressions.
+			tracker := det.ProvenanceTracker()
+			for _, id := range prov.ExprIDs {
+				sub, found := tracker.Value(id)
+				if !found {
+					t.Fatalf("tracker.Value(%d) not found", id)
+				}
+				for _, subID := range sub.ExprIDs {
+					if !containsID(prov.ExprIDs, subID) {
+						t.Errorf("tracker.Value(%d) contains id %d absent from the result provenance", id, subID)
+					}
+				}
+			}
+		})
+	}
+
+	ast, iss := env.Compile(`override`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	if _, det, _ := prg.Eval(vars); det != nil && det.Provenance() != nil {
+		t.Errorf("det.Provenance() got %v, wanted nil without OptTrackProvenance", det.Provenance())
+	}
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
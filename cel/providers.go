@@ -0,0 +1,320 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// DefaultTypeProviderName is the name under which the type provider of the environment is composed
+// with the providers given to TypeProviders.
+const DefaultTypeProviderName = "default"
+
+// TypeProviderSource is a named ref.TypeProvider composed into an environment by TypeProviders.
+type TypeProviderSource struct {
+	// Name identifies the provider within conflict errors and the results of Env.TypeProviderName.
+	Name string
+
+	// Priority orders the providers. A provider shadows the types of providers with a lower
+	// priority, while the provider of the environment has priority zero.
+	Priority int
+
+	// Provider resolves the types. When the provider is also a ref.TypeAdapter, it is consulted
+	// when converting native values, in priority order.
+	Provider ref.TypeProvider
+
+	// Types lists the qualified names of the types served by the provider, which are checked for
+	// conflicts with the other providers. When empty, the names are listed by the provider if it
+	// implements a `TypeNames() []string` method, as the protobuf type registry does.
+	Types []string
+}
+
+// TypeProviders composes the type provider of the environment with additional providers, e.g. a
+// protobuf registry, a provider of native Go structs, and a provider of custom opaque types.
+//
+// Lookups are served by the provider with the highest priority which knows the type, with the
+// earliest given provider winning among providers of equal priority. A provider with a higher
+// priority may deliberately shadow a type of a provider with a lower priority. However, when
+// providers of equal priority define the same type differently, the conflict is reported as an
+// error when the environment is created. The provider which serves a type may be queried with
+// Env.TypeProviderName.
+//
+// Types registered with the Types and TypeDescs options are registered with the provider of the
+// environment, which must be a ref.TypeRegistry.
+func TypeProviders(sources ...TypeProviderSource) EnvOption {
+	return func(e *Env) (*Env, error) {
+		composite, isComposite := e.provider.(*compositeTypeProvider)
+		if isComposite {
+			composite = composite.with(nil)
+		} else {
+			composite = &compositeTypeProvider{
+				sources: []*TypeProviderSource{{Name: DefaultTypeProviderName, Provider: e.provider}},
+			}
+			if adapter, isAdapter := e.provider.(ref.TypeAdapter); !isAdapter || adapter != e.adapter {
+				composite.adapter = e.adapter
+			}
+		}
+		names := map[string]bool{}
+		for _, src := range composite.sources {
+			names[src.Name] = true
+		}
+		for _, src := range sources {
+			if src.Name == "" || src.Provider == nil {
+				return nil, errors.New("type providers require a name and a provider")
+			}
+			if names[src.Name] {
+				return nil, fmt.Errorf("type provider %q already configured", src.Name)
+			}
+			names[src.Name] = true
+			src := src
+			composite.sources = append(composite.sources, &src)
+		}
+		sort.SliceStable(composite.sources, func(i, j int) bool {
+			return composite.sources[i].Priority > composite.sources[j].Priority
+		})
+		e.provider = composite
+		e.adapter = composite
+		return e, nil
+	}
+}
+
+// TypeProviderName returns the name of the type provider which serves the given qualified type
+// name, or false if the type is not known to the environment.
+//
+// Environments which are not configured with TypeProviders report the DefaultTypeProviderName.
+func (e *Env) TypeProviderName(typeName string) (string, bool) {
+	if composite, isComposite := e.provider.(*compositeTypeProvider); isComposite {
+		src, found := composite.sourceOf(typeName)
+		if !found {
+			return "", false
+		}
+		return src.Name, true
+	}
+	if _, found := e.provider.FindType(typeName); !found {
+		return "", false
+	}
+	return DefaultTypeProviderName, true
+}
+
+// compositeTypeProvider implements the ref.TypeRegistry interface over a prioritized list of
+// providers, ordered from the highest to the lowest priority.
+type compositeTypeProvider struct {
+	sources []*TypeProviderSource
+	// adapter is the type adapter of the environment when the providers were composed, if distinct
+	// from its provider, which is consulted after the providers which are also adapters.
+	adapter ref.TypeAdapter
+}
+
+// with returns a copy of the composite provider whose sources are transformed by the given
+// function, or copied as-is when the function is nil.
+func (p *compositeTypeProvider) with(fn func(*TypeProviderSource) *TypeProviderSource) *compositeTypeProvider {
+	cp := &compositeTypeProvider{adapter: p.adapter, sources: make([]*TypeProviderSource, len(p.sources))}
+	for i, src := range p.sources {
+		srcCopy := *src
+		cp.sources[i] = &srcCopy
+		if fn != nil {
+			cp.sources[i] = fn(&srcCopy)
+		}
+	}
+	return cp
+}
+
+// validate reports the types which are defined differently by providers of equal priority.
+func (p *compositeTypeProvider) validate() error {
+	for i, src := range p.sources {
+		for _, typeName := range providedTypeNames(src) {
+			t, _ := src.Provider.FindType(typeName)
+			for _, other := range p.sources[i+1:] {
+				if other.Priority != src.Priority {
+					break
+				}
+				if otherType, found := other.Provider.FindType(typeName); found && !proto.Equal(t, otherType) {
+					return fmt.Errorf("type %s is provided by both %q and %q", typeName, src.Name, other.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func providedTypeNames(src *TypeProviderSource) []string {
+	if len(src.Types) != 0 {
+		return src.Types
+	}
+	if lister, isLister := src.Provider.(interface{ TypeNames() []string }); isLister {
+		return lister.TypeNames()
+	}
+	return nil
+}
+
+// sourceOf returns the highest priority source which knows the type.
+func (p *compositeTypeProvider) sourceOf(typeName string) (*TypeProviderSource, bool) {
+	for _, src := range p.sources {
+		if _, found := src.Provider.FindType(typeName); found {
+			return src, true
+		}
+	}
+	return nil, false
+}
+
+// EnumValue implements the ref.TypeProvider interface method.
+func (p *compositeTypeProvider) EnumValue(enumName string) ref.Val {
+	var val ref.Val
+	for _, src := range p.sources {
+		val = src.Provider.EnumValue(enumName)
+		if !types.IsError(val) {
+			return val
+		}
+	}
+	return val
+}
+
+// FindIdent implements the ref.TypeProvider interface method.
+func (p *compositeTypeProvider) FindIdent(identName string) (ref.Val, bool) {
+	for _, src := range p.sources {
+		if val, found := src.Provider.FindIdent(identName); found {
+			return val, true
+		}
+	}
+	return nil, false
+}
+
+// FindType implements the ref.TypeProvider interface method.
+func (p *compositeTypeProvider) FindType(typeName string) (*exprpb.Type, bool) {
+	src, found := p.sourceOf(typeName)
+	if !found {
+		return nil, false
+	}
+	return src.Provider.FindType(typeName)
+}
+
+// FindFieldType implements the ref.TypeProvider interface method, consulting the provider which
+// serves the message type.
+func (p *compositeTypeProvider) FindFieldType(messageType, fieldName string) (*ref.FieldType, bool) {
+	src, found := p.sourceOf(messageType)
+	if !found {
+		return nil, false
+	}
+	return src.Provider.FindFieldType(messageType, fieldName)
+}
+
+// NewValue implements the ref.TypeProvider interface method, consulting the provider which serves
+// the type.
+func (p *compositeTypeProvider) NewValue(typeName string, fields map[string]ref.Val) ref.Val {
+	src, found := p.sourceOf(typeName)
+	if !found {
+		return types.NewErr("unknown type '%s'", typeName)
+	}
+	return src.Provider.NewValue(typeName, fields)
+}
+
+// NativeToValue implements the ref.TypeAdapter interface method, returning the first successful
+// conversion by the providers which are also adapters, in priority order.
+func (p *compositeTypeProvider) NativeToValue(value any) ref.Val {
+	var errVal ref.Val
+	for _, src := range p.sources {
+		adapter, isAdapter := src.Provider.(ref.TypeAdapter)
+		if !isAdapter {
+			continue
+		}
+		val := adapter.NativeToValue(value)
+		if !types.IsError(val) {
+			return val
+		}
+		if errVal == nil {
+			errVal = val
+		}
+	}
+	if p.adapter != nil {
+		val := p.adapter.NativeToValue(value)
+		if errVal == nil || !types.IsError(val) {
+			return val
+		}
+	}
+	return errVal
+}
+
+// Copy implements the ref.TypeRegistry interface method, copying the providers which are
+// registries.
+func (p *compositeTypeProvider) Copy() ref.TypeRegistry {
+	copies := map[ref.TypeRegistry]ref.TypeRegistry{}
+	cp := p.with(func(src *TypeProviderSource) *TypeProviderSource {
+		if reg, isReg := src.Provider.(ref.TypeRegistry); isReg {
+			copies[reg] = reg.Copy()
+			src.Provider = copies[reg]
+		}
+		return src
+	})
+	// The adapter of the environment is usually the same registry as its provider.
+	if reg, isReg := p.adapter.(ref.TypeRegistry); isReg {
+		if regCopy, found := copies[reg]; found {
+			cp.adapter = regCopy
+		} else {
+			cp.adapter = reg.Copy()
+		}
+	}
+	return cp
+}
+
+// registry returns the provider of the environment as a ref.TypeRegistry.
+func (p *compositeTypeProvider) registry() (ref.TypeRegistry, error) {
+	for _, src := range p.sources {
+		if src.Name != DefaultTypeProviderName {
+			continue
+		}
+		if reg, isReg := src.Provider.(ref.TypeRegistry); isReg {
+			return reg, nil
+		}
+		return nil, fmt.Errorf("custom types not supported by provider: %T", src.Provider)
+	}
+	return nil, errors.New("custom types not supported by composite provider without a default")
+}
+
+// RegisterDescriptor implements the ref.TypeRegistry interface method.
+func (p *compositeTypeProvider) RegisterDescriptor(fileDesc protoreflect.FileDescriptor) error {
+	reg, err := p.registry()
+	if err != nil {
+		return err
+	}
+	return reg.RegisterDescriptor(fileDesc)
+}
+
+// RegisterMessage implements the ref.TypeRegistry interface method.
+func (p *compositeTypeProvider) RegisterMessage(message proto.Message) error {
+	reg, err := p.registry()
+	if err != nil {
+		return err
+	}
+	return reg.RegisterMessage(message)
+}
+
+// RegisterType implements the ref.TypeRegistry interface method.
+func (p *compositeTypeProvider) RegisterType(types ...ref.Type) error {
+	reg, err := p.registry()
+	if err != nil {
+		return err
+	}
+	return reg.RegisterType(types...)
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+
+	proto2pb "github.com/google/cel-go/test/proto2pb"
+	proto3pb "github.com/google/cel-go/test/proto3pb"
+)
+
+func TestTypeProviders(t *testing.T) {
+	protos, err := types.NewRegistry(&proto3pb.TestAllTypes{})
+	if err != nil {
+		t.Fatalf("types.NewRegistry() failed: %v", err)
+	}
+	env, err := NewEnv(
+		TypeProviders(TypeProviderSource{Name: "protos", Priority: 1, Provider: protos}),
+		Container("google.expr.proto3.test"))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	if name, found := env.TypeProviderName("google.expr.proto3.test.TestAllTypes"); !found || name != "protos" {
+		t.Errorf("env.TypeProviderName(TestAllTypes) got %q, %v, wanted protos", name, found)
+	}
+	if _, found := env.TypeProviderName("unknown.Type"); found {
+		t.Error("env.TypeProviderName(unknown.Type) found a provider")
+	}
+	out, err := interpret(t, env, `TestAllTypes{single_int64: 1}.single_int64 == 1`, NoVars())
+	if err != nil || out != types.True {
+		t.Errorf("TestAllTypes construction got %v, %v, wanted true", out, err)
+	}
+
+	// The types registered after composition are served by the default provider.
+	env, err = env.Extend(Types(&proto2pb.TestAllTypes{}))
+	if err != nil {
+		t.Fatalf("env.Extend() failed: %v", err)
+	}
+	if name, found := env.TypeProviderName("google.expr.proto2.test.TestAllTypes"); !found || name != DefaultTypeProviderName {
+		t.Errorf("env.TypeProviderName(proto2 TestAllTypes) got %q, %v, wanted default", name, found)
+	}
+
+	// A provider which defines a registered type differently conflicts with providers of equal
+	// priority, but may shadow those of a lower priority.
+	opaque := &opaqueTypeProvider{TypeProvider: protos, typeName: "google.expr.proto3.test.TestAllTypes"}
+	_, err = NewEnv(Types(&proto3pb.TestAllTypes{}),
+		TypeProviders(TypeProviderSource{Name: "opaque", Provider: opaque, Types: []string{opaque.typeName}}))
+	if err == nil || !strings.Contains(err.Error(), `type google.expr.proto3.test.TestAllTypes is provided by both "default" and "opaque"`) {
+		t.Errorf("NewEnv() with conflicting providers got error %v, wanted a conflict", err)
+	}
+	env, err = NewEnv(Types(&proto3pb.TestAllTypes{}),
+		TypeProviders(TypeProviderSource{Name: "opaque", Priority: 1, Provider: opaque, Types: []string{opaque.typeName}}))
+	if err != nil {
+		t.Fatalf("NewEnv() with shadowing provider failed: %v", err)
+	}
+	if name, _ := env.TypeProviderName(opaque.typeName); name != "opaque" {
+		t.Errorf("env.TypeProviderName() got %q, wanted opaque", name)
+	}
+	_, err = NewEnv(TypeProviders(
+		TypeProviderSource{Name: "protos", Provider: protos},
+		TypeProviderSource{Name: "protos", Provider: protos}))
+	if err == nil || !strings.Contains(err.Error(), `type provider "protos" already configured`) {
+		t.Errorf("NewEnv() with duplicate provider names got error %v, wanted already configured", err)
+	}
+}
+
+// opaqueTypeProvider defines a single type as an opaque type.
+type opaqueTypeProvider struct {
+	ref.TypeProvider
+	typeName string
+}
@@ -0,0 +1,380 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/overloads"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// IntBounds declares the inclusive range of values which an integer variable may take, for use by
+// RangeAnalysis.
+type IntBounds struct {
+	Min int64
+	Max int64
+}
+
+// RangeAnalysis returns a Linter which infers the range of values of the integer expressions
+// within an Ast and warns about arithmetic which always overflows and comparisons which are always
+// false.
+//
+// Ranges are inferred from literals, from the bounds declared for variables, keyed by the
+// qualified variable name, and from the comparisons which guard an expression, such as the left
+// operand of a logical AND or the condition of a conditional. For example, the right operand of
+// `x > 0 && x < 0` is reported as always false.
+//
+// The analysis is conservative: expressions whose range cannot be inferred are assumed to take
+// any integer value, and so no warning is reported for them.
+//
+// The linter is configured with the Linters option:
+//
+//	env, err := cel.NewEnv(
+//	  cel.Variable("x", cel.IntType),
+//	  cel.Linters(cel.RangeAnalysis(map[string]cel.IntBounds{"x": {Min: 0, Max: 100}})))
+func RangeAnalysis(bounds map[string]IntBounds) Linter {
+	facts := rangeFacts{}
+	for name, b := range bounds {
+		facts[name] = intRange{lo: b.Min, hi: b.Max}
+	}
+	return func(ast *Ast) []Warning {
+		a := &rangeAnalyzer{ast: ast}
+		a.check(ast.Expr(), facts)
+		return a.warnings
+	}
+}
+
+// intRange is an inclusive range of integer values, which is empty when lo is greater than hi.
+type intRange struct {
+	lo int64
+	hi int64
+}
+
+var anyInt = intRange{lo: math.MinInt64, hi: math.MaxInt64}
+
+func (r intRange) empty() bool {
+	return r.lo > r.hi
+}
+
+func (r intRange) intersect(other intRange) intRange {
+	if other.lo > r.lo {
+		r.lo = other.lo
+	}
+	if other.hi < r.hi {
+		r.hi = other.hi
+	}
+	return r
+}
+
+func (r intRange) union(other intRange) intRange {
+	if r.empty() {
+		return other
+	}
+	if other.empty() {
+		return r
+	}
+	if other.lo < r.lo {
+		r.lo = other.lo
+	}
+	if other.hi > r.hi {
+		r.hi = other.hi
+	}
+	return r
+}
+
+// rangeFacts maps variable names to the ranges known for them. Facts are copied rather than
+// modified when refined, since refinements only apply to the guarded expressions.
+type rangeFacts map[string]intRange
+
+func (f rangeFacts) with(name string, r intRange) rangeFacts {
+	refined := make(rangeFacts, len(f)+1)
+	for n, v := range f {
+		refined[n] = v
+	}
+	refined[name] = r
+	return refined
+}
+
+func (f rangeFacts) without(names ...string) rangeFacts {
+	refined := make(rangeFacts, len(f))
+	for n, v := range f {
+		refined[n] = v
+	}
+	for _, n := range names {
+		delete(refined, n)
+	}
+	return refined
+}
+
+// rangeAnalyzer collects the range warnings for a checked Ast.
+type rangeAnalyzer struct {
+	ast      *Ast
+	warnings []Warning
+}
+
+// check visits the expression, reporting overflows and always false comparisons given the facts
+// known about the variables in scope.
+func (a *rangeAnalyzer) check(e *exprpb.Expr, facts rangeFacts) {
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		if _, found := a.varName(e); !found {
+			a.check(e.GetSelectExpr().GetOperand(), facts)
+		}
+	case *exprpb.Expr_CallExpr:
+		a.checkCall(e, facts)
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range e.GetListExpr().GetElements() {
+			a.check(elem, facts)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			a.check(entry.GetMapKey(), facts)
+			a.check(entry.GetValue(), facts)
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		a.check(comp.GetIterRange(), facts)
+		a.check(comp.GetAccuInit(), facts)
+		inner := facts.without(comp.GetIterVar(), comp.GetAccuVar())
+		a.check(comp.GetLoopCondition(), inner)
+		a.check(comp.GetLoopStep(), inner)
+		a.check(comp.GetResult(), inner)
+	}
+}
+
+func (a *rangeAnalyzer) checkCall(e *exprpb.Expr, facts rangeFacts) {
+	call := e.GetCallExpr()
+	args := call.GetArgs()
+	switch call.GetFunction() {
+	case operators.LogicalAnd:
+		a.check(args[0], facts)
+		a.check(args[1], a.refine(args[0], facts))
+		return
+	case operators.Conditional:
+		a.check(args[0], facts)
+		a.check(args[1], a.refine(args[0], facts))
+		a.check(args[2], facts)
+		return
+	case operators.Less, operators.LessEquals, operators.Greater, operators.GreaterEquals,
+		operators.Equals, operators.NotEquals:
+		if a.isInt(args[0]) && a.isInt(args[1]) &&
+			alwaysFalse(call.GetFunction(), a.rangeOf(args[0], facts), a.rangeOf(args[1], facts)) {
+			a.warnings = append(a.warnings, Warning{ID: e.GetId(), Message: "comparison is always false"})
+		}
+	case operators.Add, operators.Subtract, operators.Multiply, operators.Negate:
+		if a.isInt(e) {
+			if _, overflows := a.arith(e, facts); overflows {
+				a.warnings = append(a.warnings, Warning{
+					ID:      e.GetId(),
+					Message: "integer arithmetic always overflows",
+				})
+			}
+		}
+	}
+	a.check(call.GetTarget(), facts)
+	for _, arg := range args {
+		a.check(arg, facts)
+	}
+}
+
+// rangeOf returns the range of values of an integer expression given the facts known about the
+// variables in scope.
+func (a *rangeAnalyzer) rangeOf(e *exprpb.Expr, facts rangeFacts) intRange {
+	if c := e.GetConstExpr(); c != nil {
+		if v, ok := c.GetConstantKind().(*exprpb.Constant_Int64Value); ok {
+			return intRange{lo: v.Int64Value, hi: v.Int64Value}
+		}
+		return anyInt
+	}
+	if name, found := a.varName(e); found {
+		if r, found := facts[name]; found {
+			return r
+		}
+		return anyInt
+	}
+	call := e.GetCallExpr()
+	if call == nil {
+		return anyInt
+	}
+	switch call.GetFunction() {
+	case operators.Add, operators.Subtract, operators.Multiply, operators.Negate:
+		r, overflows := a.arith(e, facts)
+		if overflows {
+			return anyInt
+		}
+		return r
+	case operators.Conditional:
+		args := call.GetArgs()
+		return a.rangeOf(args[1], a.refine(args[0], facts)).union(a.rangeOf(args[2], facts))
+	case overloads.Size:
+		return intRange{lo: 0, hi: math.MaxInt64}
+	}
+	return anyInt
+}
+
+// arith returns the range of values of an arithmetic expression, and whether every combination of
+// operand values overflows. Results which overflow are errors rather than values, and so the
+// range is limited to the results which do not overflow.
+func (a *rangeAnalyzer) arith(e *exprpb.Expr, facts rangeFacts) (intRange, bool) {
+	call := e.GetCallExpr()
+	args := call.GetArgs()
+	l := a.rangeOf(args[0], facts)
+	r := intRange{lo: 0, hi: 0}
+	if len(args) > 1 {
+		r = a.rangeOf(args[1], facts)
+	}
+	if l.empty() || r.empty() {
+		return intRange{lo: 1, hi: 0}, false
+	}
+	llo, lhi := big.NewInt(l.lo), big.NewInt(l.hi)
+	rlo, rhi := big.NewInt(r.lo), big.NewInt(r.hi)
+	var lo, hi *big.Int
+	switch call.GetFunction() {
+	case operators.Add:
+		lo, hi = new(big.Int).Add(llo, rlo), new(big.Int).Add(lhi, rhi)
+	case operators.Subtract:
+		lo, hi = new(big.Int).Sub(llo, rhi), new(big.Int).Sub(lhi, rlo)
+	case operators.Negate:
+		lo, hi = new(big.Int).Neg(lhi), new(big.Int).Neg(llo)
+	case operators.Multiply:
+		for _, p := range []*big.Int{
+			new(big.Int).Mul(llo, rlo), new(big.Int).Mul(llo, rhi),
+			new(big.Int).Mul(lhi, rlo), new(big.Int).Mul(lhi, rhi),
+		} {
+			if lo == nil || p.Cmp(lo) < 0 {
+				lo = p
+			}
+			if hi == nil || p.Cmp(hi) > 0 {
+				hi = p
+			}
+		}
+	}
+	minInt, maxInt := big.NewInt(math.MinInt64), big.NewInt(math.MaxInt64)
+	if lo.Cmp(maxInt) > 0 || hi.Cmp(minInt) < 0 {
+		return anyInt, true
+	}
+	res := anyInt
+	if lo.Cmp(minInt) > 0 {
+		res.lo = lo.Int64()
+	}
+	if hi.Cmp(maxInt) < 0 {
+		res.hi = hi.Int64()
+	}
+	return res, false
+}
+
+// refine returns the facts known about the variables in scope when the guard evaluates to true.
+func (a *rangeAnalyzer) refine(guard *exprpb.Expr, facts rangeFacts) rangeFacts {
+	call := guard.GetCallExpr()
+	if call == nil {
+		return facts
+	}
+	args := call.GetArgs()
+	fn := call.GetFunction()
+	switch fn {
+	case operators.LogicalAnd:
+		return a.refine(args[1], a.refine(args[0], facts))
+	case operators.Less, operators.LessEquals, operators.Greater, operators.GreaterEquals,
+		operators.Equals:
+		if !a.isInt(args[0]) || !a.isInt(args[1]) {
+			return facts
+		}
+		if name, found := a.varName(args[0]); found {
+			bound := comparisonBound(fn, a.rangeOf(args[1], facts))
+			facts = facts.with(name, a.rangeOf(args[0], facts).intersect(bound))
+		}
+		if name, found := a.varName(args[1]); found {
+			bound := comparisonBound(mirrorComparison[fn], a.rangeOf(args[0], facts))
+			facts = facts.with(name, a.rangeOf(args[1], facts).intersect(bound))
+		}
+	}
+	return facts
+}
+
+// varName returns the name by which the facts about a variable reference are known.
+func (a *rangeAnalyzer) varName(e *exprpb.Expr) (string, bool) {
+	if ref, found := a.ast.refMap[e.GetId()]; found && ref.GetName() != "" {
+		return ref.GetName(), true
+	}
+	if ident := e.GetIdentExpr(); ident != nil {
+		return ident.GetName(), true
+	}
+	return "", false
+}
+
+func (a *rangeAnalyzer) isInt(e *exprpb.Expr) bool {
+	return a.ast.typeMap[e.GetId()].GetPrimitive() == exprpb.Type_INT64
+}
+
+// mirrorComparison maps each comparison to the comparison with its operands swapped.
+var mirrorComparison = map[string]string{
+	operators.Less:          operators.Greater,
+	operators.LessEquals:    operators.GreaterEquals,
+	operators.Greater:       operators.Less,
+	operators.GreaterEquals: operators.LessEquals,
+	operators.Equals:        operators.Equals,
+}
+
+// comparisonBound returns the range of values v for which `v <fn> other` may be true.
+func comparisonBound(fn string, other intRange) intRange {
+	bound := anyInt
+	switch fn {
+	case operators.Less:
+		if other.hi == math.MinInt64 {
+			return intRange{lo: 1, hi: 0}
+		}
+		bound.hi = other.hi - 1
+	case operators.LessEquals:
+		bound.hi = other.hi
+	case operators.Greater:
+		if other.lo == math.MaxInt64 {
+			return intRange{lo: 1, hi: 0}
+		}
+		bound.lo = other.lo + 1
+	case operators.GreaterEquals:
+		bound.lo = other.lo
+	case operators.Equals:
+		bound = other
+	}
+	return bound
+}
+
+// alwaysFalse returns whether the comparison is false for every pair of values in the operand
+// ranges. Comparisons with empty ranges are not reported, as they are only reachable when an
+// earlier comparison has already been reported.
+func alwaysFalse(fn string, l, r intRange) bool {
+	if l.empty() || r.empty() {
+		return false
+	}
+	switch fn {
+	case operators.Less:
+		return l.lo >= r.hi
+	case operators.LessEquals:
+		return l.lo > r.hi
+	case operators.Greater:
+		return l.hi <= r.lo
+	case operators.GreaterEquals:
+		return l.hi < r.lo
+	case operators.Equals:
+		return l.intersect(r).empty()
+	case operators.NotEquals:
+		return l.lo == l.hi && r.lo == r.hi && l.lo == r.lo
+	}
+	return false
+}
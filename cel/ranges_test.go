@@ -0,0 +1,68 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestRangeAnalysis(t *testing.T) {
+	env, err := NewEnv(
+		Variable("x", IntType),
+		Variable("y", IntType),
+		Variable("s", StringType),
+		Linters(RangeAnalysis(map[string]IntBounds{"y": {Min: 0, Max: 100}})))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{expr: `x > 0 && x < 0`, want: []string{"1:12: comparison is always false"}},
+		{expr: `x >= 0 && x <= 10 && x < 10`},
+		{expr: `0 < x && x == 0`, want: []string{"1:12: comparison is always false"}},
+		{expr: `x == 1 && x != 1`, want: []string{"1:13: comparison is always false"}},
+		{expr: `x > 0 ? x < 1 : x < 1`, want: []string{"1:11: comparison is always false"}},
+		{expr: `x > 0 || x < 0`},
+		{expr: `y > 100`, want: []string{"1:3: comparison is always false"}},
+		{expr: `size(s) < 0`, want: []string{"1:9: comparison is always false"}},
+		{expr: `y * 2 + 1 > 201`, want: []string{"1:11: comparison is always false"}},
+		{expr: `9223372036854775807 + 1 > 0`, want: []string{"1:21: integer arithmetic always overflows"}},
+		{expr: `-(-9223372036854775807 - 1) > 0`, want: []string{"1:1: integer arithmetic always overflows"}},
+		{expr: `x + 1 > 0`},
+		{expr: `x > 9223372036854775806 && x + 1 > 0`, want: []string{"1:30: integer arithmetic always overflows"}},
+		{expr: `[1, 2].exists(y, y > 100)`},
+		{expr: `double(x) > 0.5 && x < 0`},
+	}
+	for _, tc := range tests {
+		tst := tc
+		t.Run(tst.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tst.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%q) failed: %v", tst.expr, iss.Err())
+			}
+			var got []string
+			for _, w := range ast.Warnings() {
+				got = append(got, fmt.Sprintf("%d:%d: %s", w.Location.Line(), w.Location.Column()+1, w.Message))
+			}
+			if !reflect.DeepEqual(got, tst.want) {
+				t.Errorf("env.Compile(%q) got warnings %v, wanted %v", tst.expr, got, tst.want)
+			}
+		})
+	}
+}
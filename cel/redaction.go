@@ -0,0 +1,368 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// sensitiveExprIDs returns the set of expression ids whose values derive from a sensitive path.
+func (e *Env) sensitiveExprIDs(ast *Ast) map[int64]bool {
+	s := &sensitivityAnalyzer{
+		paths:   e.sensitivePaths,
+		refMap:  ast.refMap,
+		typeMap: ast.typeMap,
+		ids:     map[int64]bool{},
+		scopes:  map[string][]bool{},
+	}
+	s.visit(ast.Expr())
+	return s.ids
+}
+
+type sensitivityAnalyzer struct {
+	paths   map[string]bool
+	refMap  map[int64]*exprpb.Reference
+	typeMap map[int64]*exprpb.Type
+	ids     map[int64]bool
+	// scopes records whether the comprehension variables in scope hold sensitive values.
+	scopes map[string][]bool
+}
+
+// visit determines whether the expression is sensitive, recording the ids of all sensitive
+// expressions within the expression graph.
+func (s *sensitivityAnalyzer) visit(e *exprpb.Expr) bool {
+	sensitive := false
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		name := e.GetIdentExpr().GetName()
+		if scope := s.scopes[name]; len(scope) > 0 {
+			sensitive = scope[len(scope)-1]
+		} else {
+			sensitive = s.matches(s.resolvedName(e, name))
+		}
+	case *exprpb.Expr_SelectExpr:
+		sel := e.GetSelectExpr()
+		operandSensitive := s.visit(sel.GetOperand())
+		if ref, found := s.refMap[e.GetId()]; found && ref.GetName() != "" {
+			// The type-checker resolved the selection to a qualified variable name.
+			sensitive = s.matches(ref.GetName())
+		} else if path, found := s.path(e); found {
+			sensitive = s.matches(path)
+		} else {
+			sensitive = operandSensitive
+		}
+		if sel.GetTestOnly() {
+			sensitive = false
+		}
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		if call.GetTarget() != nil {
+			sensitive = s.visit(call.GetTarget())
+		}
+		for _, arg := range call.GetArgs() {
+			sensitive = s.visit(arg) || sensitive
+		}
+		if call.GetFunction() == operators.Index {
+			if path, found := s.path(e); found {
+				sensitive = s.matches(path)
+			}
+		}
+		if s.isBool(e) {
+			sensitive = false
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range e.GetListExpr().GetElements() {
+			sensitive = s.visit(elem) || sensitive
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			if entry.GetMapKey() != nil {
+				sensitive = s.visit(entry.GetMapKey()) || sensitive
+			}
+			sensitive = s.visit(entry.GetValue()) || sensitive
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		rangeSensitive := s.visit(comp.GetIterRange())
+		accuSensitive := s.visit(comp.GetAccuInit())
+		s.pushScope(comp.GetIterVar(), rangeSensitive)
+		// The accumulator becomes sensitive if any step folds a sensitive value into it, so the
+		// loop is analyzed a second time once the accumulator is known to be sensitive.
+		for i := 0; i < 2; i++ {
+			s.pushScope(comp.GetAccuVar(), accuSensitive)
+			condSensitive := s.visit(comp.GetLoopCondition())
+			stepSensitive := s.visit(comp.GetLoopStep())
+			s.popScope(comp.GetAccuVar())
+			if accuSensitive || !(stepSensitive || condSensitive) {
+				break
+			}
+			accuSensitive = true
+		}
+		s.popScope(comp.GetIterVar())
+		s.pushScope(comp.GetAccuVar(), accuSensitive)
+		sensitive = s.visit(comp.GetResult())
+		s.popScope(comp.GetAccuVar())
+	}
+	if sensitive {
+		s.ids[e.GetId()] = true
+	}
+	return sensitive
+}
+
+// resolvedName returns the name of the variable referenced by an identifier, preferring the name
+// resolved by the type-checker when available.
+func (s *sensitivityAnalyzer) resolvedName(e *exprpb.Expr, name string) string {
+	if ref, found := s.refMap[e.GetId()]; found && ref.GetName() != "" {
+		return ref.GetName()
+	}
+	return name
+}
+
+// path returns the dotted field path for a chain of field selections and constant string
+// indexes rooted at a variable.
+func (s *sensitivityAnalyzer) path(e *exprpb.Expr) (string, bool) {
+	var fields []string
+	for {
+		switch e.GetExprKind().(type) {
+		case *exprpb.Expr_IdentExpr:
+			name := e.GetIdentExpr().GetName()
+			if len(s.scopes[name]) > 0 {
+				return "", false
+			}
+			return joinReversed(append(fields, s.resolvedName(e, name))), true
+		case *exprpb.Expr_SelectExpr:
+			if ref, found := s.refMap[e.GetId()]; found && ref.GetName() != "" {
+				return joinReversed(append(fields, ref.GetName())), true
+			}
+			sel := e.GetSelectExpr()
+			fields = append(fields, sel.GetField())
+			e = sel.GetOperand()
+		case *exprpb.Expr_CallExpr:
+			call := e.GetCallExpr()
+			if call.GetFunction() != operators.Index || len(call.GetArgs()) != 2 {
+				return "", false
+			}
+			key := call.GetArgs()[1].GetConstExpr()
+			if key == nil || key.GetStringValue() == "" {
+				return "", false
+			}
+			fields = append(fields, key.GetStringValue())
+			e = call.GetArgs()[0]
+		default:
+			return "", false
+		}
+	}
+}
+
+func joinReversed(fields []string) string {
+	for i, j := 0, len(fields)-1; i < j; i, j = i+1, j-1 {
+		fields[i], fields[j] = fields[j], fields[i]
+	}
+	return strings.Join(fields, ".")
+}
+
+// matches returns whether the path is sensitive, contains a sensitive path, or is nested within a
+// sensitive path.
+func (s *sensitivityAnalyzer) matches(path string) bool {
+	for p := range s.paths {
+		if path == p || strings.HasPrefix(path, p+".") || strings.HasPrefix(p, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// isBool returns whether the call expression is known to produce a boolean result.
+func (s *sensitivityAnalyzer) isBool(e *exprpb.Expr) bool {
+	if t, found := s.typeMap[e.GetId()]; found {
+		return t.GetPrimitive() == exprpb.Type_BOOL
+	}
+	_, found := boolOperators[e.GetCallExpr().GetFunction()]
+	return found
+}
+
+func (s *sensitivityAnalyzer) pushScope(name string, sensitive bool) {
+	s.scopes[name] = append(s.scopes[name], sensitive)
+}
+
+func (s *sensitivityAnalyzer) popScope(name string) {
+	scope := s.scopes[name]
+	s.scopes[name] = scope[:len(scope)-1]
+}
+
+var boolOperators = map[string]struct{}{
+	operators.LogicalAnd:       {},
+	operators.LogicalOr:        {},
+	operators.LogicalNot:       {},
+	operators.Equals:           {},
+	operators.NotEquals:        {},
+	operators.Less:             {},
+	operators.LessEquals:       {},
+	operators.Greater:          {},
+	operators.GreaterEquals:    {},
+	operators.In:               {},
+	operators.OldIn:            {},
+	operators.NotStrictlyFalse: {},
+}
+
+// redactedValue is substituted for sensitive values within evaluation state and observers.
+var redactedValue = types.NewErr("<redacted>")
+
+// redactObserver wraps an observer such that the values of sensitive expressions are redacted.
+func redactObserver(ids map[int64]bool, observer interpreter.EvalObserver) interpreter.EvalObserver {
+	return func(id int64, programStep any, val ref.Val) {
+		if ids[id] {
+			val = redactedValue
+		}
+		observer(id, programStep, val)
+	}
+}
+
+// sensitiveAttrRoots returns the ids of the expressions at the root of a chain of field selections
+// and indexes where one of the qualifiers selects from, or indexes by, a sensitive value. The ids
+// correspond to the ids of the attributes produced by the planner for the chain.
+func sensitiveAttrRoots(e *exprpb.Expr, ids map[int64]bool) map[int64]bool {
+	roots := map[int64]bool{}
+	visitExpr(e, func(expr *exprpb.Expr) {
+		var operand, key *exprpb.Expr
+		switch expr.GetExprKind().(type) {
+		case *exprpb.Expr_SelectExpr:
+			operand = expr.GetSelectExpr().GetOperand()
+		case *exprpb.Expr_CallExpr:
+			operand, key = indexOperands(expr)
+		}
+		if operand == nil || !(ids[operand.GetId()] || ids[key.GetId()]) {
+			return
+		}
+		roots[attrRoot(operand).GetId()] = true
+	})
+	return roots
+}
+
+// attrRoot returns the expression at the root of a chain of field selections and indexes.
+func attrRoot(e *exprpb.Expr) *exprpb.Expr {
+	for {
+		if sel := e.GetSelectExpr(); sel != nil && !sel.GetTestOnly() {
+			e = sel.GetOperand()
+			continue
+		}
+		operand, _ := indexOperands(e)
+		if operand == nil {
+			return e
+		}
+		e = operand
+	}
+}
+
+// indexOperands returns the operand and key of an index expression, or nil if the expression is
+// not an index.
+func indexOperands(e *exprpb.Expr) (*exprpb.Expr, *exprpb.Expr) {
+	call := e.GetCallExpr()
+	switch call.GetFunction() {
+	case operators.Index, operators.OptIndex, operators.OptSelect:
+		if len(call.GetArgs()) == 2 {
+			return call.GetArgs()[0], call.GetArgs()[1]
+		}
+	}
+	return nil, nil
+}
+
+// redactErrors returns a decorator which replaces the errors produced by function calls with
+// sensitive arguments, and by attributes which are sensitive or which are qualified by sensitive
+// values, with an error that omits the original message.
+func redactErrors(ids, attrRoots map[int64]bool) interpreter.InterpretableDecorator {
+	return func(i interpreter.Interpretable) (interpreter.Interpretable, error) {
+		switch i := i.(type) {
+		case *redactedAttr:
+			return i, nil
+		case interpreter.InterpretableAttribute:
+			if ids[i.ID()] || attrRoots[i.ID()] {
+				return &redactedAttr{InterpretableAttribute: i}, nil
+			}
+		case interpreter.InterpretableCall:
+			for _, arg := range i.Args() {
+				if ids[arg.ID()] {
+					return &redactedCall{InterpretableCall: i}, nil
+				}
+			}
+		}
+		return i, nil
+	}
+}
+
+type redactedCall struct {
+	interpreter.InterpretableCall
+}
+
+// Eval implements the Interpretable interface method.
+func (r *redactedCall) Eval(vars interpreter.Activation) ref.Val {
+	val := r.InterpretableCall.Eval(vars)
+	if types.IsError(val) {
+		return types.NewErr("%s: error redacted as the call involves a sensitive value", r.Function())
+	}
+	return val
+}
+
+// redactedAttr redacts the errors produced while resolving an attribute.
+//
+// Since the attribute may be qualified at a later stage in program planning, the redactedAttr
+// must implement the InterpretableAttribute interface by proxy.
+type redactedAttr struct {
+	interpreter.InterpretableAttribute
+}
+
+// AddQualifier proxies the InterpretableAttribute.AddQualifier method, returning the redacted
+// attribute.
+func (r *redactedAttr) AddQualifier(q interpreter.Qualifier) (interpreter.Attribute, error) {
+	_, err := r.InterpretableAttribute.AddQualifier(q)
+	return r, err
+}
+
+// Eval implements the Interpretable interface method.
+func (r *redactedAttr) Eval(vars interpreter.Activation) ref.Val {
+	val := r.InterpretableAttribute.Eval(vars)
+	if types.IsError(val) {
+		return types.NewErr(redactedAttrMessage)
+	}
+	return val
+}
+
+// Qualify implements the InterpretableAttribute interface method.
+func (r *redactedAttr) Qualify(vars interpreter.Activation, obj any) (any, error) {
+	out, err := r.InterpretableAttribute.Qualify(vars, obj)
+	if err != nil {
+		return nil, errors.New(redactedAttrMessage)
+	}
+	return out, nil
+}
+
+// QualifyIfPresent implements the InterpretableAttribute interface method.
+func (r *redactedAttr) QualifyIfPresent(vars interpreter.Activation, obj any, presenceOnly bool) (any, bool, error) {
+	out, found, err := r.InterpretableAttribute.QualifyIfPresent(vars, obj, presenceOnly)
+	if err != nil {
+		return nil, false, errors.New(redactedAttrMessage)
+	}
+	return out, found, nil
+}
+
+const redactedAttrMessage = "error redacted as the attribute involves a sensitive value"
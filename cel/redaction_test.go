@@ -0,0 +1,168 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestSensitiveValues(t *testing.T) {
+	env, err := NewEnv(
+		SensitiveVariable("token", StringType),
+		Variable("req", MapType(StringType, DynType)),
+		SensitiveFields("req.auth.secret"),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	vars := map[string]any{
+		"token": "s3cr3t",
+		"req": map[string]any{
+			"user": "alice",
+			"auth": map[string]any{"secret": "hunter2"},
+		},
+	}
+	tests := []struct {
+		expr string
+		// redacted lists the expression text of nodes which must be redacted from the eval state.
+		redacted []string
+		// visible lists the expression text of nodes which must not be redacted.
+		visible []string
+	}{
+		{
+			expr:     `token + '!' != 'x'`,
+			redacted: []string{"token", `token + "!"`},
+			visible:  []string{`token + "!" != "x"`},
+		},
+		{
+			expr:     `req.user == 'alice' && req.auth.secret.size() > 3`,
+			redacted: []string{"req", "req.auth", "req.auth.secret", "req.auth.secret.size()"},
+			visible:  []string{"req.user", "req.auth.secret.size() > 3"},
+		},
+		{
+			expr:     `[token][0] != '' && {'k': token}.k != ''`,
+			redacted: []string{"[token]", "[token][0]", `{"k": token}`, `{"k": token}.k`},
+			visible:  []string{`[token][0] != ""`},
+		},
+		{
+			expr: `[token].map(t, t + 'x').exists(t, t != '')`,
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%q) failed: %v", tc.expr, iss.Err())
+			}
+			prg, err := env.Program(ast, EvalOptions(OptTrackState))
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			out, det, err := prg.Eval(vars)
+			if err != nil {
+				t.Fatalf("prg.Eval() failed: %v", err)
+			}
+			if out != types.True {
+				t.Errorf("prg.Eval() got %v, wanted true", out)
+			}
+			state := det.State()
+			for _, id := range state.IDs() {
+				val, _ := state.Value(id)
+				if strings.Contains(fmt.Sprintf("%v", val.Value()), "s3cr3t") ||
+					strings.Contains(fmt.Sprintf("%v", val.Value()), "hunter2") {
+					t.Errorf("state value for id %d leaks a sensitive value: %v", id, val)
+				}
+			}
+			sensitive := env.sensitiveExprIDs(ast)
+			for _, text := range tc.redacted {
+				if !sensitive[findExprID(t, ast, text)] {
+					t.Errorf("expression %q was not marked sensitive", text)
+				}
+			}
+			for _, text := range tc.visible {
+				if sensitive[findExprID(t, ast, text)] {
+					t.Errorf("expression %q was marked sensitive", text)
+				}
+			}
+		})
+	}
+
+	// Errors raised by functions with sensitive arguments must not include the argument values.
+	ast, iss := env.Compile(`int(token) > 0 || timestamp(req.auth.secret) > timestamp(0)`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast, EvalOptions(OptExhaustiveEval))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	_, _, err = prg.Eval(vars)
+	if err == nil {
+		t.Fatal("prg.Eval() succeeded, wanted error")
+	}
+	if strings.Contains(err.Error(), "s3cr3t") || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("prg.Eval() error leaks a sensitive value: %v", err)
+	}
+
+	// Errors raised while resolving attributes qualified by sensitive values must not include the
+	// qualifier values.
+	for _, expr := range []string{
+		`req[token] == 1`,
+		`{'a': 1}[token] == 1`,
+		`req.auth[req.auth.secret] == 1`,
+	} {
+		ast, iss := env.Compile(expr)
+		if iss.Err() != nil {
+			t.Fatalf("env.Compile(%q) failed: %v", expr, iss.Err())
+		}
+		prg, err := env.Program(ast)
+		if err != nil {
+			t.Fatalf("env.Program() failed: %v", err)
+		}
+		_, _, err = prg.Eval(vars)
+		if err == nil {
+			t.Fatalf("prg.Eval(%q) succeeded, wanted error", expr)
+		}
+		if strings.Contains(err.Error(), "s3cr3t") || strings.Contains(err.Error(), "hunter2") {
+			t.Errorf("prg.Eval(%q) error leaks a sensitive value: %v", expr, err)
+		}
+	}
+}
+
+// findExprID returns the id of the subexpression of the ast whose unparsed text matches the input.
+func findExprID(t *testing.T, ast *Ast, text string) int64 {
+	t.Helper()
+	var id int64
+	visitExpr(ast.Expr(), func(e *exprpb.Expr) {
+		if id != 0 {
+			return
+		}
+		sub, err := AstToString(ParsedExprToAst(&exprpb.ParsedExpr{Expr: e, SourceInfo: ast.SourceInfo()}))
+		if err == nil && sub == text {
+			id = e.GetId()
+		}
+	})
+	if id == 0 {
+		t.Fatalf("no subexpression found matching %q", text)
+	}
+	return id
+}
@@ -0,0 +1,262 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"sort"
+
+	"github.com/google/cel-go/checker"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+
+	"google.golang.org/protobuf/proto"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+const orderedFunction = "cel.ordered"
+
+type orderedLogicLibrary struct{}
+
+// LibraryName implements the SingletonLibrary interface method.
+func (orderedLogicLibrary) LibraryName() string {
+	return "cel.lib.ordered"
+}
+
+// CompileOptions implements the Library interface method.
+func (orderedLogicLibrary) CompileOptions() []EnvOption {
+	return []EnvOption{
+		Function(orderedFunction,
+			Overload("cel_ordered_bool", []*Type{BoolType}, BoolType,
+				UnaryBinding(func(value ref.Val) ref.Val {
+					return value
+				}))),
+	}
+}
+
+// ProgramOptions implements the Library interface method.
+func (orderedLogicLibrary) ProgramOptions() []ProgramOption {
+	return []ProgramOption{}
+}
+
+// reorderLogicalOperands returns a copy of the checked AST in which the operands of each `&&` and
+// `||` chain are sorted by ascending estimated cost.
+//
+// When a profile is provided, the estimated cost of each operand is weighted by the observed
+// likelihood of the operand short-circuiting the chain, and the observed sizes of values are used
+// in place of the default size estimates.
+//
+// Chains which contain a call to a nondeterministic function, and chains nested within a call to
+// `cel.ordered`, retain their original order.
+func (e *Env) reorderLogicalOperands(ast *Ast, estimator checker.CostEstimator, profile *interpreter.Profile) *Ast {
+	estimator = e.sizeEstimator("", estimator)
+	if profile != nil {
+		estimator = &profileCostEstimator{CostEstimator: estimator, profile: profile}
+	}
+	r := &logicReorderer{
+		env:       e,
+		estimator: estimator,
+		profile:   profile,
+		checked: &exprpb.CheckedExpr{
+			ReferenceMap: ast.refMap,
+			TypeMap:      ast.typeMap,
+			SourceInfo:   ast.info,
+		},
+	}
+	expr := proto.Clone(ast.Expr()).(*exprpb.Expr)
+	r.visit(expr)
+	return &Ast{
+		expr:        expr,
+		info:        ast.info,
+		source:      ast.source,
+		refMap:      ast.refMap,
+		typeMap:     ast.typeMap,
+		resolutions: ast.resolutions,
+		defaults:    ast.defaults,
+	}
+}
+
+type logicReorderer struct {
+	env       *Env
+	estimator checker.CostEstimator
+	profile   *interpreter.Profile
+	checked   *exprpb.CheckedExpr
+}
+
+// visit reorders the logical operator chains within the expression graph.
+func (r *logicReorderer) visit(e *exprpb.Expr) {
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		r.visit(e.GetSelectExpr().GetOperand())
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		fn := call.GetFunction()
+		if fn == orderedFunction {
+			return
+		}
+		if fn == operators.LogicalAnd || fn == operators.LogicalOr {
+			r.reorder(e)
+			return
+		}
+		if call.GetTarget() != nil {
+			r.visit(call.GetTarget())
+		}
+		for _, arg := range call.GetArgs() {
+			r.visit(arg)
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range e.GetListExpr().GetElements() {
+			r.visit(elem)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			r.visit(entry.GetMapKey())
+			r.visit(entry.GetValue())
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		r.visit(comp.GetIterRange())
+		r.visit(comp.GetAccuInit())
+		r.visit(comp.GetLoopCondition())
+		r.visit(comp.GetLoopStep())
+		r.visit(comp.GetResult())
+	}
+}
+
+// reorder sorts the operands of the logical operator chain rooted at the input expression.
+//
+// The chain is rebuilt as a left-associative sequence of the chain's existing call nodes so that
+// the expression ids, and the type and reference information associated with them, are preserved.
+func (r *logicReorderer) reorder(root *exprpb.Expr) {
+	fn := root.GetCallExpr().GetFunction()
+	var calls []*exprpb.Expr
+	var operands []*exprpb.Expr
+	var flatten func(e *exprpb.Expr)
+	flatten = func(e *exprpb.Expr) {
+		call := e.GetCallExpr()
+		if call.GetFunction() != fn || len(call.GetArgs()) != 2 {
+			operands = append(operands, e)
+			return
+		}
+		if e != root {
+			calls = append(calls, e)
+		}
+		flatten(call.GetArgs()[0])
+		flatten(call.GetArgs()[1])
+	}
+	flatten(root)
+	calls = append(calls, root)
+
+	pinned := false
+	for _, op := range operands {
+		r.visit(op)
+		pinned = pinned || r.isNondeterministic(op)
+	}
+	if pinned {
+		return
+	}
+	ranks := make(map[*exprpb.Expr]float64, len(operands))
+	for _, op := range operands {
+		ranks[op] = r.rank(fn, op)
+	}
+	sort.SliceStable(operands, func(i, j int) bool {
+		return ranks[operands[i]] < ranks[operands[j]]
+	})
+	lhs := operands[0]
+	for i, call := range calls {
+		call.GetCallExpr().Args = []*exprpb.Expr{lhs, operands[i+1]}
+		lhs = call
+	}
+}
+
+// rank orders the operands of a logical operator chain, where operands with a lower rank are
+// evaluated first.
+//
+// Without a profile, the rank is the maximum estimated cost of the operand. With a profile, the
+// estimated cost is divided by the observed likelihood that the operand short-circuits the chain,
+// which minimizes the expected cost of evaluating the chain when the operands are independent.
+func (r *logicReorderer) rank(fn string, op *exprpb.Expr) float64 {
+	cost := r.cost(op)
+	rank := float64(cost.Max)
+	if r.profile == nil {
+		return rank
+	}
+	n, found := r.profile.Node(op.GetId())
+	if !found {
+		return rank * 2
+	}
+	if fn == operators.LogicalAnd {
+		return rank / n.FalseRatio()
+	}
+	return rank / n.TrueRatio()
+}
+
+// cost estimates the cost of evaluating the subexpression.
+func (r *logicReorderer) cost(e *exprpb.Expr) checker.CostEstimate {
+	sub := &exprpb.CheckedExpr{
+		ReferenceMap: r.checked.GetReferenceMap(),
+		TypeMap:      r.checked.GetTypeMap(),
+		SourceInfo:   r.checked.GetSourceInfo(),
+		Expr:         e,
+	}
+	return checker.Cost(sub, r.estimator)
+}
+
+// isNondeterministic returns whether the subexpression calls a nondeterministic function.
+func (r *logicReorderer) isNondeterministic(e *exprpb.Expr) bool {
+	found := false
+	visitExpr(e, func(expr *exprpb.Expr) {
+		call := expr.GetCallExpr()
+		if call == nil {
+			return
+		}
+		if fn, ok := r.env.functions[call.GetFunction()]; ok && fn.nondeterministic {
+			found = true
+		}
+	})
+	return found
+}
+
+// profileCostEstimator estimates the sizes of values from the largest size observed within a
+// profile when the wrapped estimator has no estimate.
+type profileCostEstimator struct {
+	checker.CostEstimator
+	profile *interpreter.Profile
+}
+
+// EstimateSize implements the checker.CostEstimator interface method.
+func (e *profileCostEstimator) EstimateSize(element checker.AstNode) *checker.SizeEstimate {
+	if size := e.CostEstimator.EstimateSize(element); size != nil {
+		return size
+	}
+	if n, found := e.profile.Node(element.Expr().GetId()); found && n.SizeCount > 0 {
+		return &checker.SizeEstimate{Min: 0, Max: n.SizeMax}
+	}
+	return nil
+}
+
+// defaultCostEstimator defers to the default size and call cost estimates of the checker.
+type defaultCostEstimator struct{}
+
+// EstimateSize implements the checker.CostEstimator interface method.
+func (defaultCostEstimator) EstimateSize(element checker.AstNode) *checker.SizeEstimate {
+	return nil
+}
+
+// EstimateCallCost implements the checker.CostEstimator interface method.
+func (defaultCostEstimator) EstimateCallCost(function, overloadID string, target *checker.AstNode, args []checker.AstNode) *checker.CallEstimate {
+	return nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+)
+
+func TestReorderLogicalOperands(t *testing.T) {
+	env, err := NewEnv(
+		OrderedLogic(),
+		Variable("names", ListType(StringType)),
+		Variable("flag", BoolType),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	vars := map[string]any{
+		"names": []string{"a", "b", "c", "d"},
+		"flag":  false,
+	}
+	tests := []struct {
+		expr      string
+		reordered bool
+	}{
+		{expr: `names.exists(n, n.startsWith('z')) && flag`, reordered: true},
+		{expr: `names.exists(n, n.startsWith('z')) || !flag`, reordered: true},
+		{expr: `flag && names.exists(n, n.startsWith('z'))`, reordered: false},
+		{expr: `cel.ordered(names.exists(n, n.startsWith('z')) && flag)`, reordered: false},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile() failed: %v", iss.Err())
+			}
+			before := proto.Clone(ast.Expr())
+			prg, err := env.Program(ast, CostTracking(nil))
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			want, det, err := prg.Eval(vars)
+			if err != nil {
+				t.Fatalf("prg.Eval() failed: %v", err)
+			}
+			wantCost := *det.ActualCost()
+
+			prg, err = env.Program(ast, CostTracking(nil), ReorderLogicalOperands(nil))
+			if err != nil {
+				t.Fatalf("env.Program(ReorderLogicalOperands()) failed: %v", err)
+			}
+			out, det, err := prg.Eval(vars)
+			if err != nil {
+				t.Fatalf("prg.Eval() failed: %v", err)
+			}
+			if out.Equal(want) != types.True {
+				t.Errorf("prg.Eval() got %v, wanted %v", out, want)
+			}
+			gotCost := *det.ActualCost()
+			if tc.reordered && gotCost >= wantCost {
+				t.Errorf("prg.Eval() cost %d, wanted less than %d", gotCost, wantCost)
+			}
+			if !tc.reordered && gotCost != wantCost {
+				t.Errorf("prg.Eval() cost %d, wanted %d", gotCost, wantCost)
+			}
+			if !proto.Equal(ast.Expr(), before) {
+				t.Errorf("env.Program() modified the input ast, got %v, wanted %v", ast.Expr(), before)
+			}
+		})
+	}
+}
+
+func TestReorderLogicalOperandsNondeterministic(t *testing.T) {
+	env, err := NewEnv(
+		Variable("flag", BoolType),
+		Function("roll",
+			Overload("roll", []*Type{}, IntType,
+				FunctionBinding(func(args ...ref.Val) ref.Val { return types.Int(4) })),
+			NonDeterministic()),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`[1, 2, 3].all(x, x < roll()) || flag`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	reordered := env.reorderLogicalOperands(ast, nil, nil)
+	if !proto.Equal(reordered.Expr(), ast.Expr()) {
+		t.Errorf("reorderLogicalOperands() reordered a nondeterministic chain: %v", reordered.Expr())
+	}
+}
+
+func TestProfileGuidedOptimization(t *testing.T) {
+	env, err := NewEnv(
+		Variable("a", BoolType),
+		Variable("b", BoolType),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`a && b`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	args := ast.Expr().GetCallExpr().GetArgs()
+	aID, bID := args[0].GetId(), args[1].GetId()
+	vars := map[string]any{"a": true, "b": false}
+
+	profile := interpreter.NewProfile()
+	prg, err := env.Program(ast, CollectProfile(profile))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, _, err := prg.Eval(vars); err != nil {
+			t.Fatalf("prg.Eval() failed: %v", err)
+		}
+	}
+	if n, found := profile.Node(bID); !found || n.Hits != 10 || n.False != 10 {
+		t.Fatalf("profile.Node(b) got %+v, wanted 10 false outcomes", n)
+	}
+
+	// Without a profile, operands of equal cost retain their order.
+	for _, tc := range []struct {
+		opts      []ProgramOption
+		evaluated int64
+		skipped   int64
+	}{
+		{
+			opts:      []ProgramOption{ReorderLogicalOperands(nil)},
+			evaluated: aID,
+			skipped:   -1,
+		},
+		{
+			opts:      []ProgramOption{ReorderLogicalOperands(nil), ProfileGuidedOptimization(profile)},
+			evaluated: bID,
+			skipped:   aID,
+		},
+	} {
+		opts := append([]ProgramOption{EvalOptions(OptTrackState)}, tc.opts...)
+		prg, err := env.Program(ast, opts...)
+		if err != nil {
+			t.Fatalf("env.Program() failed: %v", err)
+		}
+		out, det, err := prg.Eval(vars)
+		if err != nil || out != types.False {
+			t.Fatalf("prg.Eval() got %v, %v, wanted false", out, err)
+		}
+		if _, found := det.State().Value(tc.evaluated); !found {
+			t.Errorf("prg.Eval() did not evaluate expression %d", tc.evaluated)
+		}
+		if _, found := det.State().Value(tc.skipped); found {
+			t.Errorf("prg.Eval() evaluated expression %d, wanted it to be skipped", tc.skipped)
+		}
+	}
+}
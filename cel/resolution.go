@@ -0,0 +1,152 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/common/containers"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// ContainerResolution describes a name within an expression which was resolved to a declaration
+// with a different name through the container namespace, an abbreviation, or an alias.
+type ContainerResolution struct {
+	// ID is the id of the expression which refers to the declaration.
+	ID int64
+
+	// Name is the name as written in the expression, e.g. `a.b.c`.
+	Name string
+
+	// Resolved is the name of the declaration the name resolved to, e.g. `com.example.a.b.c`.
+	Resolved string
+
+	// Alias is the abbreviation or alias applied to the leading component of the name, or empty
+	// if the name resolved through the container namespace.
+	Alias string
+
+	// Shadowed lists the names of other declarations the name would have resolved to, in
+	// resolution order, were the resolved declaration absent.
+	Shadowed []string
+}
+
+// ContainerResolutions returns the names within a type-checked expression which resolved to
+// declarations through the container, ordered by expression id.
+//
+// Resolutions are recorded by Env.Check and are carried by the Asts derived from the checked Ast,
+// such as those produced by Env.Minify. They are not recorded within the reference map, since the
+// exprpb.Reference message has no field for the name as written, and so they are not serialized by
+// AstToCheckedExpr and are not available for Asts which were constructed from a CheckedExpr proto.
+func (ast *Ast) ContainerResolutions() []*ContainerResolution {
+	resolutions := make([]*ContainerResolution, 0, len(ast.resolutions))
+	for _, r := range ast.resolutions {
+		resolutions = append(resolutions, r)
+	}
+	sort.Slice(resolutions, func(i, j int) bool {
+		return resolutions[i].ID < resolutions[j].ID
+	})
+	return resolutions
+}
+
+// writtenNames returns the possibly qualified names of the identifiers, selections, and message
+// constructions within the parsed expression, keyed by expression id.
+//
+// The names must be collected prior to type-checking, as the checker rewrites them to the names of
+// the declarations they resolve to.
+func writtenNames(e *exprpb.Expr, names map[int64]string) map[int64]string {
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		names[e.GetId()] = e.GetIdentExpr().GetName()
+	case *exprpb.Expr_SelectExpr:
+		if qname, found := containers.ToQualifiedName(e); found {
+			names[e.GetId()] = qname
+		}
+		writtenNames(e.GetSelectExpr().GetOperand(), names)
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		if call.GetTarget() != nil {
+			writtenNames(call.GetTarget(), names)
+		}
+		for _, arg := range call.GetArgs() {
+			writtenNames(arg, names)
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range e.GetListExpr().GetElements() {
+			writtenNames(elem, names)
+		}
+	case *exprpb.Expr_StructExpr:
+		st := e.GetStructExpr()
+		if st.GetMessageName() != "" {
+			names[e.GetId()] = st.GetMessageName()
+		}
+		for _, entry := range st.GetEntries() {
+			if entry.GetMapKey() != nil {
+				writtenNames(entry.GetMapKey(), names)
+			}
+			writtenNames(entry.GetValue(), names)
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		writtenNames(comp.GetIterRange(), names)
+		writtenNames(comp.GetAccuInit(), names)
+		writtenNames(comp.GetLoopCondition(), names)
+		writtenNames(comp.GetLoopStep(), names)
+		writtenNames(comp.GetResult(), names)
+	}
+	return names
+}
+
+// containerResolutions relates the names written within an expression to the declarations they
+// were resolved to by the checker.
+func (e *Env) containerResolutions(written map[int64]string, refMap map[int64]*exprpb.Reference) map[int64]*ContainerResolution {
+	var resolutions map[int64]*ContainerResolution
+	for id, name := range written {
+		ref, found := refMap[id]
+		if !found || ref.GetName() == "" || len(ref.GetOverloadId()) != 0 {
+			continue
+		}
+		absName := strings.TrimPrefix(name, ".")
+		candidates := e.Container.ResolveCandidateNames(name)
+		alias := ""
+		if len(candidates) == 1 && candidates[0] != absName {
+			alias = strings.SplitN(absName, ".", 2)[0]
+		} else if ref.GetName() == absName {
+			continue
+		}
+		r := &ContainerResolution{
+			ID:       id,
+			Name:     name,
+			Resolved: ref.GetName(),
+			Alias:    alias,
+		}
+		shadowing := false
+		for _, candidate := range candidates {
+			if candidate == ref.GetName() {
+				shadowing = true
+				continue
+			}
+			if shadowing && e.chk.LookupIdent("."+candidate) != nil {
+				r.Shadowed = append(r.Shadowed, candidate)
+			}
+		}
+		if resolutions == nil {
+			resolutions = map[int64]*ContainerResolution{}
+		}
+		resolutions[id] = r
+	}
+	return resolutions
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestContainerResolutions(t *testing.T) {
+	env, err := NewEnv(
+		Container("com.example"),
+		Abbrevs("google.rpc.context.AttributeContext"),
+		Variable("com.example.a.b.c", IntType),
+		Variable("a.b.c", IntType),
+		Variable("x", IntType),
+		Variable("google.rpc.context.AttributeContext.request", StringType),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`a.b.c + x > 0 && AttributeContext.request == ''`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	var got []string
+	for _, r := range ast.ContainerResolutions() {
+		got = append(got, fmt.Sprintf("%s -> %s alias=%q shadowed=%v", r.Name, r.Resolved, r.Alias, r.Shadowed))
+	}
+	want := []string{
+		`a.b.c -> com.example.a.b.c alias="" shadowed=[a.b.c]`,
+		`AttributeContext.request -> google.rpc.context.AttributeContext.request alias="AttributeContext" shadowed=[]`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ast.ContainerResolutions() got %v, wanted %v", got, want)
+	}
+
+	// Minification carries the resolutions over to the renumbered expression ids.
+	origID := ast.ContainerResolutions()[0].ID
+	minified, mapping := env.Minify(ast)
+	got = nil
+	for _, r := range minified.ContainerResolutions() {
+		if mapping.OriginalID(r.ID) == 0 {
+			t.Errorf("minified resolution %v has an unknown id", r)
+		}
+		got = append(got, fmt.Sprintf("%s -> %s alias=%q shadowed=%v", r.Name, r.Resolved, r.Alias, r.Shadowed))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("minified.ContainerResolutions() got %v, wanted %v", got, want)
+	}
+	if ast.ContainerResolutions()[0].ID != origID {
+		t.Error("env.Minify() modified the resolutions of the input ast")
+	}
+}
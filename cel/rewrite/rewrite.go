@@ -0,0 +1,520 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rewrite applies declarative peephole optimizations to CEL expressions.
+//
+// A rule is written as a pair of CEL expressions: a pattern containing placeholders, and the
+// replacement for the expressions which match it, e.g. `_x + 0` rewrites to `_x`. Rules may carry a
+// side condition which inspects the expressions bound to the placeholders, such as their types,
+// before the rewrite is applied. An Engine applies its rules until none of them match, and only
+// accepts rules which shrink the expression so that the rewriting is guaranteed to terminate.
+package rewrite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/debug"
+
+	"google.golang.org/protobuf/proto"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Rule rewrites the expressions which match its pattern into its replacement.
+//
+// Placeholders are identifiers which begin with a single underscore, e.g. `_x` or `_list`, and
+// match any expression. A placeholder which occurs more than once in the pattern only matches
+// when each occurrence matches the same expression. Every other identifier matches only itself.
+type Rule struct {
+	// Name identifies the rule in the list of rules applied by Engine.Rewrite.
+	Name string
+
+	// Pattern is the CEL source of the expressions the rule matches.
+	Pattern string
+
+	// Replacement is the CEL source of the expression which replaces a match, and may only refer
+	// to the placeholders of the pattern.
+	Replacement string
+
+	condition func(*Match) bool
+}
+
+// NewRule returns a rule which rewrites expressions matching the pattern into the replacement.
+func NewRule(name, pattern, replacement string) *Rule {
+	return &Rule{Name: name, Pattern: pattern, Replacement: replacement}
+}
+
+// Where restricts the rule to the matches for which the condition holds.
+func (r *Rule) Where(condition func(*Match) bool) *Rule {
+	r.condition = condition
+	return r
+}
+
+// Match describes an expression which matches the pattern of a rule.
+type Match struct {
+	// Bindings holds the expressions bound to the placeholders of the pattern by name, without the
+	// leading underscore.
+	Bindings map[string]*exprpb.Expr
+
+	types map[int64]*exprpb.Type
+}
+
+// Type returns the type of the expression bound to the placeholder when the expression being
+// rewritten has been type-checked.
+func (m *Match) Type(placeholder string) (*cel.Type, bool) {
+	e, found := m.Bindings[placeholder]
+	if !found {
+		return nil, false
+	}
+	t, found := m.types[e.GetId()]
+	if !found {
+		return nil, false
+	}
+	celType, err := cel.ExprTypeToType(t)
+	if err != nil {
+		return nil, false
+	}
+	return celType, true
+}
+
+// Engine applies a set of rewrite rules to expressions.
+type Engine struct {
+	env   *cel.Env
+	rules []*compiledRule
+}
+
+// compiledRule holds the parsed pattern and replacement of a rule.
+type compiledRule struct {
+	*Rule
+	pattern     *exprpb.Expr
+	replacement *exprpb.Expr
+}
+
+// NewEngine parses the patterns and replacements of the rules using the environment, which must
+// also be used to parse and check the expressions given to Engine.Rewrite.
+//
+// To guarantee that rewriting terminates, every rule must shrink the expressions it matches: the
+// replacement must contain fewer nodes than the pattern, not counting placeholders, and must
+// refer to each placeholder no more often than the pattern does. NewEngine rejects the rules which
+// do not.
+func NewEngine(env *cel.Env, rules ...*Rule) (*Engine, error) {
+	eng := &Engine{env: env, rules: make([]*compiledRule, len(rules))}
+	for i, r := range rules {
+		pattern, iss := env.Parse(r.Pattern)
+		if iss.Err() != nil {
+			return nil, fmt.Errorf("rewrite rule %q has invalid pattern: %v", r.Name, iss.Err())
+		}
+		replacement, iss := env.Parse(r.Replacement)
+		if iss.Err() != nil {
+			return nil, fmt.Errorf("rewrite rule %q has invalid replacement: %v", r.Name, iss.Err())
+		}
+		cr := &compiledRule{Rule: r, pattern: pattern.Expr(), replacement: replacement.Expr()}
+		if err := cr.checkShrinks(); err != nil {
+			return nil, fmt.Errorf("rewrite rule %q may not terminate: %v", r.Name, err)
+		}
+		eng.rules[i] = cr
+	}
+	return eng, nil
+}
+
+// Rewrite applies the rules to the expression until none of them match, returning the rewritten
+// expression along with the names of the rules in the order they were applied.
+//
+// The rules are tried in order against each node of the expression, starting from the leaves.
+// When the expression has been type-checked, the rewritten expression is checked again after each
+// round of rewrites, so that the conditions of the rules may inspect the types of the expressions
+// produced by earlier rewrites, and the result is a checked Ast. Otherwise the result is a parsed
+// Ast.
+func (eng *Engine) Rewrite(ast *cel.Ast) (*cel.Ast, []string, error) {
+	parsed, err := cel.AstToParsedExpr(ast)
+	if err != nil {
+		return nil, nil, err
+	}
+	e := proto.Clone(parsed.GetExpr()).(*exprpb.Expr)
+	info := proto.Clone(parsed.GetSourceInfo()).(*exprpb.SourceInfo)
+	checked := ast.IsChecked()
+	var types map[int64]*exprpb.Type
+	if checked {
+		ce, err := cel.AstToCheckedExpr(ast)
+		if err != nil {
+			return nil, nil, err
+		}
+		types = ce.GetTypeMap()
+	}
+	r := &rewriter{rules: eng.rules, info: info, types: types, dirty: map[int64]bool{}}
+	r.maxID = maxID(e)
+	for _, call := range info.GetMacroCalls() {
+		if id := maxID(call); id > r.maxID {
+			r.maxID = id
+		}
+	}
+	result := ast
+	for {
+		// Each round either shrinks the expression or leaves it unchanged, which ends the rewriting.
+		applied := len(r.applied)
+		r.visit(e)
+		if len(r.applied) == applied {
+			break
+		}
+		r.pruneSourceInfo(e)
+		result = cel.ParsedExprToAst(&exprpb.ParsedExpr{Expr: e, SourceInfo: info})
+		if !checked {
+			continue
+		}
+		var iss *cel.Issues
+		result, iss = eng.env.Check(result)
+		if iss.Err() != nil {
+			return nil, r.applied, fmt.Errorf("rewritten expression failed to check after applying %s: %v",
+				strings.Join(r.applied[applied:], ", "), iss.Err())
+		}
+		ce, err := cel.AstToCheckedExpr(result)
+		if err != nil {
+			return nil, r.applied, err
+		}
+		r.types = ce.GetTypeMap()
+		// The checker may have annotated the expression, so continue from its copy.
+		e = ce.GetExpr()
+		info = ce.GetSourceInfo()
+		r.info = info
+	}
+	return result, r.applied, nil
+}
+
+// rewriter applies the rules to the nodes of an expression.
+type rewriter struct {
+	rules []*compiledRule
+	info  *exprpb.SourceInfo
+	types map[int64]*exprpb.Type
+	maxID int64
+	// dirty holds the ids of the expressions whose subexpressions were rewritten, which invalidates
+	// the macro calls recorded for them.
+	dirty   map[int64]bool
+	applied []string
+}
+
+// visit rewrites the subexpressions of the expression before the expression itself, applying at
+// most one rule to each node, and returns whether the expression changed.
+func (r *rewriter) visit(e *exprpb.Expr) bool {
+	changed := false
+	for _, c := range children(e) {
+		changed = r.visit(c) || changed
+	}
+	if changed {
+		r.dirty[e.GetId()] = true
+	}
+	for _, rule := range r.rules {
+		m := &Match{Bindings: map[string]*exprpb.Expr{}, types: r.types}
+		if !match(rule.pattern, e, m.Bindings) {
+			continue
+		}
+		if rule.condition != nil && !rule.condition(m) {
+			continue
+		}
+		r.replace(e, rule.replacement, m.Bindings)
+		r.applied = append(r.applied, rule.Name)
+		return true
+	}
+	return changed
+}
+
+// replace rewrites the expression in place as an instance of the replacement.
+func (r *rewriter) replace(e, replacement *exprpb.Expr, bindings map[string]*exprpb.Expr) {
+	used := map[string]bool{}
+	inst := r.instantiate(replacement, bindings, used)
+	// Expressions introduced by the replacement take the position of the expression they replace.
+	positions := r.info.GetPositions()
+	if _, found := positions[inst.GetId()]; !found {
+		if offset, found := positions[e.GetId()]; found {
+			positions[inst.GetId()] = offset
+		}
+	}
+	r.dirty[e.GetId()] = true
+	e.Id = inst.GetId()
+	e.ExprKind = inst.GetExprKind()
+}
+
+// instantiate returns a copy of the replacement with fresh ids in which the placeholders are
+// substituted with the expressions bound to them. The first use of each binding reuses the bound
+// expression, while later uses copy it.
+func (r *rewriter) instantiate(replacement *exprpb.Expr, bindings map[string]*exprpb.Expr,
+	used map[string]bool) *exprpb.Expr {
+	if name, isPlaceholder := placeholder(replacement); isPlaceholder {
+		bound := bindings[name]
+		if !used[name] {
+			used[name] = true
+			return bound
+		}
+		dup := proto.Clone(bound).(*exprpb.Expr)
+		r.renumber(dup)
+		return dup
+	}
+	inst := proto.Clone(replacement).(*exprpb.Expr)
+	inst.Id = r.nextID()
+	switch inst.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		sel := inst.GetSelectExpr()
+		sel.Operand = r.instantiate(sel.GetOperand(), bindings, used)
+	case *exprpb.Expr_CallExpr:
+		call := inst.GetCallExpr()
+		if call.GetTarget() != nil {
+			call.Target = r.instantiate(call.GetTarget(), bindings, used)
+		}
+		for i, arg := range call.GetArgs() {
+			call.Args[i] = r.instantiate(arg, bindings, used)
+		}
+	case *exprpb.Expr_ListExpr:
+		list := inst.GetListExpr()
+		for i, elem := range list.GetElements() {
+			list.Elements[i] = r.instantiate(elem, bindings, used)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range inst.GetStructExpr().GetEntries() {
+			entry.Id = r.nextID()
+			if entry.GetMapKey() != nil {
+				entry.KeyKind = &exprpb.Expr_CreateStruct_Entry_MapKey{
+					MapKey: r.instantiate(entry.GetMapKey(), bindings, used)}
+			}
+			entry.Value = r.instantiate(entry.GetValue(), bindings, used)
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := inst.GetComprehensionExpr()
+		comp.IterRange = r.instantiate(comp.GetIterRange(), bindings, used)
+		comp.AccuInit = r.instantiate(comp.GetAccuInit(), bindings, used)
+		comp.LoopCondition = r.instantiate(comp.GetLoopCondition(), bindings, used)
+		comp.LoopStep = r.instantiate(comp.GetLoopStep(), bindings, used)
+		comp.Result = r.instantiate(comp.GetResult(), bindings, used)
+	}
+	return inst
+}
+
+// renumber assigns fresh ids to the nodes of the expression.
+func (r *rewriter) renumber(e *exprpb.Expr) {
+	e.Id = r.nextID()
+	for _, entry := range e.GetStructExpr().GetEntries() {
+		entry.Id = r.nextID()
+	}
+	for _, c := range children(e) {
+		r.renumber(c)
+	}
+}
+
+func (r *rewriter) nextID() int64 {
+	r.maxID++
+	return r.maxID
+}
+
+// pruneSourceInfo removes the positions of the expressions which were rewritten away, along with
+// the macro calls whose expansions were rewritten, as they no longer describe the expression.
+func (r *rewriter) pruneSourceInfo(e *exprpb.Expr) {
+	live := map[int64]bool{}
+	var collect func(*exprpb.Expr)
+	collect = func(e *exprpb.Expr) {
+		live[e.GetId()] = true
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			live[entry.GetId()] = true
+		}
+		for _, c := range children(e) {
+			collect(c)
+		}
+	}
+	collect(e)
+	for id := range r.info.GetPositions() {
+		if !live[id] {
+			delete(r.info.Positions, id)
+		}
+	}
+	for id := range r.info.GetMacroCalls() {
+		if !live[id] || r.dirty[id] {
+			delete(r.info.MacroCalls, id)
+		}
+	}
+	r.dirty = map[int64]bool{}
+}
+
+// checkShrinks returns an error if an application of the rule may not shrink the expression.
+func (cr *compiledRule) checkShrinks() error {
+	patternNodes, patternUses := countNodes(cr.pattern)
+	replacementNodes, replacementUses := countNodes(cr.replacement)
+	for name, uses := range replacementUses {
+		if patternUses[name] == 0 {
+			return fmt.Errorf("replacement refers to placeholder _%s which the pattern does not bind", name)
+		}
+		if uses > patternUses[name] {
+			return fmt.Errorf("replacement refers to placeholder _%s %d times, more than the pattern's %d",
+				name, uses, patternUses[name])
+		}
+	}
+	if replacementNodes >= patternNodes {
+		return fmt.Errorf("replacement has %d nodes, which is not fewer than the pattern's %d",
+			replacementNodes, patternNodes)
+	}
+	return nil
+}
+
+// countNodes returns the number of nodes in the expression other than placeholders, along with
+// the number of uses of each placeholder.
+func countNodes(e *exprpb.Expr) (int, map[string]int) {
+	nodes := 0
+	uses := map[string]int{}
+	var count func(*exprpb.Expr)
+	count = func(e *exprpb.Expr) {
+		if name, isPlaceholder := placeholder(e); isPlaceholder {
+			uses[name]++
+			return
+		}
+		nodes++
+		for _, c := range children(e) {
+			count(c)
+		}
+	}
+	count(e)
+	return nodes, uses
+}
+
+// match returns whether the expression matches the pattern, binding the placeholders of the
+// pattern to the matching subexpressions.
+func match(pattern, e *exprpb.Expr, bindings map[string]*exprpb.Expr) bool {
+	if name, isPlaceholder := placeholder(pattern); isPlaceholder {
+		if bound, found := bindings[name]; found {
+			return debug.ToDebugString(bound) == debug.ToDebugString(e)
+		}
+		bindings[name] = e
+		return true
+	}
+	switch pattern.GetExprKind().(type) {
+	case *exprpb.Expr_ConstExpr:
+		return e.GetConstExpr() != nil && proto.Equal(pattern.GetConstExpr(), e.GetConstExpr())
+	case *exprpb.Expr_IdentExpr:
+		return e.GetIdentExpr() != nil && pattern.GetIdentExpr().GetName() == e.GetIdentExpr().GetName()
+	case *exprpb.Expr_SelectExpr:
+		ps, es := pattern.GetSelectExpr(), e.GetSelectExpr()
+		return es != nil && ps.GetField() == es.GetField() && ps.GetTestOnly() == es.GetTestOnly() &&
+			match(ps.GetOperand(), es.GetOperand(), bindings)
+	case *exprpb.Expr_CallExpr:
+		pc, ec := pattern.GetCallExpr(), e.GetCallExpr()
+		if ec == nil || pc.GetFunction() != ec.GetFunction() ||
+			(pc.GetTarget() == nil) != (ec.GetTarget() == nil) || len(pc.GetArgs()) != len(ec.GetArgs()) {
+			return false
+		}
+		if pc.GetTarget() != nil && !match(pc.GetTarget(), ec.GetTarget(), bindings) {
+			return false
+		}
+		for i, arg := range pc.GetArgs() {
+			if !match(arg, ec.GetArgs()[i], bindings) {
+				return false
+			}
+		}
+		return true
+	case *exprpb.Expr_ListExpr:
+		pl, el := pattern.GetListExpr(), e.GetListExpr()
+		if el == nil || len(pl.GetElements()) != len(el.GetElements()) ||
+			fmt.Sprint(pl.GetOptionalIndices()) != fmt.Sprint(el.GetOptionalIndices()) {
+			return false
+		}
+		for i, elem := range pl.GetElements() {
+			if !match(elem, el.GetElements()[i], bindings) {
+				return false
+			}
+		}
+		return true
+	case *exprpb.Expr_StructExpr:
+		ps, es := pattern.GetStructExpr(), e.GetStructExpr()
+		if es == nil || ps.GetMessageName() != es.GetMessageName() || len(ps.GetEntries()) != len(es.GetEntries()) {
+			return false
+		}
+		for i, pe := range ps.GetEntries() {
+			ee := es.GetEntries()[i]
+			if pe.GetFieldKey() != ee.GetFieldKey() || pe.GetOptionalEntry() != ee.GetOptionalEntry() ||
+				(pe.GetMapKey() == nil) != (ee.GetMapKey() == nil) {
+				return false
+			}
+			if pe.GetMapKey() != nil && !match(pe.GetMapKey(), ee.GetMapKey(), bindings) {
+				return false
+			}
+			if !match(pe.GetValue(), ee.GetValue(), bindings) {
+				return false
+			}
+		}
+		return true
+	case *exprpb.Expr_ComprehensionExpr:
+		pc, ec := pattern.GetComprehensionExpr(), e.GetComprehensionExpr()
+		return ec != nil && pc.GetIterVar() == ec.GetIterVar() && pc.GetAccuVar() == ec.GetAccuVar() &&
+			match(pc.GetIterRange(), ec.GetIterRange(), bindings) &&
+			match(pc.GetAccuInit(), ec.GetAccuInit(), bindings) &&
+			match(pc.GetLoopCondition(), ec.GetLoopCondition(), bindings) &&
+			match(pc.GetLoopStep(), ec.GetLoopStep(), bindings) &&
+			match(pc.GetResult(), ec.GetResult(), bindings)
+	}
+	return false
+}
+
+// placeholder returns the name of the placeholder without its leading underscore when the
+// expression is a placeholder.
+func placeholder(e *exprpb.Expr) (string, bool) {
+	name := e.GetIdentExpr().GetName()
+	// The accumulators of the standard macros, e.g. `__result__`, are not placeholders.
+	if len(name) < 2 || name[0] != '_' || name[1] == '_' {
+		return "", false
+	}
+	return name[1:], true
+}
+
+// maxID returns the largest id within the expression.
+func maxID(e *exprpb.Expr) int64 {
+	id := e.GetId()
+	for _, entry := range e.GetStructExpr().GetEntries() {
+		if entry.GetId() > id {
+			id = entry.GetId()
+		}
+	}
+	for _, c := range children(e) {
+		if cid := maxID(c); cid > id {
+			id = cid
+		}
+	}
+	return id
+}
+
+// children returns the direct subexpressions of the expression.
+func children(e *exprpb.Expr) []*exprpb.Expr {
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		return []*exprpb.Expr{e.GetSelectExpr().GetOperand()}
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		c := make([]*exprpb.Expr, 0, len(call.GetArgs())+1)
+		if call.GetTarget() != nil {
+			c = append(c, call.GetTarget())
+		}
+		return append(c, call.GetArgs()...)
+	case *exprpb.Expr_ListExpr:
+		return e.GetListExpr().GetElements()
+	case *exprpb.Expr_StructExpr:
+		var c []*exprpb.Expr
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			if entry.GetMapKey() != nil {
+				c = append(c, entry.GetMapKey())
+			}
+			c = append(c, entry.GetValue())
+		}
+		return c
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		return []*exprpb.Expr{comp.GetIterRange(), comp.GetAccuInit(),
+			comp.GetLoopCondition(), comp.GetLoopStep(), comp.GetResult()}
+	}
+	return nil
+}
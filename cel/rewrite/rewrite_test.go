@@ -0,0 +1,160 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rewrite
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestRewrite(t *testing.T) {
+	env, err := cel.NewEnv(
+		cel.Variable("x", cel.IntType),
+		cel.Variable("b", cel.BoolType),
+		cel.Variable("s", cel.StringType),
+		cel.Variable("l", cel.ListType(cel.IntType)),
+	)
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	eng, err := NewEngine(env,
+		NewRule("double negation", "!(!_x)", "_x"),
+		NewRule("add zero", "_x + 0", "_x").Where(func(m *Match) bool {
+			t, found := m.Type("x")
+			return found && t == cel.IntType
+		}),
+		NewRule("and self", "_x && _x", "_x"),
+		NewRule("empty list", "size(_l) == 0", "_l == []").Where(func(m *Match) bool {
+			t, found := m.Type("l")
+			return found && cel.ListType(cel.DynType).IsAssignableType(t)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+	tests := []struct {
+		expr    string
+		out     string
+		applied []string
+	}{
+		{
+			expr:    `!(!(x + 0 + 0 == 1))`,
+			out:     `x == 1`,
+			applied: []string{"add zero", "add zero", "double negation"},
+		},
+		{
+			// The rewrites of the operands produce a match for the enclosing expression.
+			expr:    `!(!b) && !(!(!(!b)))`,
+			out:     `b`,
+			applied: []string{"double negation", "double negation", "double negation", "and self"},
+		},
+		{
+			expr:    `size(l) == 0 || size(s) == 0`,
+			out:     `l == [] || size(s) == 0`,
+			applied: []string{"empty list"},
+		},
+		{
+			expr: `x + 1 == 2`,
+			out:  `x + 1 == 2`,
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%q) failed: %v", tc.expr, iss.Err())
+			}
+			out, applied, err := eng.Rewrite(ast)
+			if err != nil {
+				t.Fatalf("Rewrite() failed: %v", err)
+			}
+			if !out.IsChecked() {
+				t.Error("Rewrite() returned an unchecked Ast")
+			}
+			src, err := cel.AstToString(out)
+			if err != nil {
+				t.Fatalf("cel.AstToString() failed: %v", err)
+			}
+			if src != tc.out {
+				t.Errorf("Rewrite() got %q, wanted %q", src, tc.out)
+			}
+			if !reflect.DeepEqual(applied, tc.applied) {
+				t.Errorf("Rewrite() applied %v, wanted %v", applied, tc.applied)
+			}
+			prg, err := env.Program(out)
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			vars := map[string]any{"x": 1, "b": true, "s": "", "l": []int{}}
+			if _, _, err := prg.Eval(vars); err != nil {
+				t.Errorf("prg.Eval() failed: %v", err)
+			}
+		})
+	}
+
+	// Parsed expressions are rewritten without type information.
+	parsed, iss := env.Parse(`!(!b) && !(!b)`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Parse() failed: %v", iss.Err())
+	}
+	out, _, err := eng.Rewrite(parsed)
+	if err != nil {
+		t.Fatalf("Rewrite() failed: %v", err)
+	}
+	if out.IsChecked() {
+		t.Error("Rewrite() of a parsed Ast returned a checked Ast")
+	}
+	if src, _ := cel.AstToString(out); src != "b" {
+		t.Errorf("Rewrite() got %q, wanted %q", src, "b")
+	}
+}
+
+func TestNewEngineErrors(t *testing.T) {
+	env, err := cel.NewEnv()
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		rule *Rule
+		err  string
+	}{
+		{
+			rule: NewRule("commute", "_x + _y", "_y + _x"),
+			err:  "not fewer than the pattern's",
+		},
+		{
+			rule: NewRule("duplicate", "_x * 2", "_x + _x"),
+			err:  "more than the pattern's 1",
+		},
+		{
+			rule: NewRule("unbound", "_x + 0", "_y"),
+			err:  "does not bind",
+		},
+		{
+			rule: NewRule("invalid", "_x +", "_x"),
+			err:  "invalid pattern",
+		},
+	}
+	for _, tc := range tests {
+		_, err := NewEngine(env, tc.rule)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("NewEngine(%q) got error %v, wanted error containing %q", tc.rule.Name, err, tc.err)
+		}
+	}
+}
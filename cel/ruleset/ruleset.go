@@ -0,0 +1,309 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ruleset combines compiled boolean CEL programs into rule sets using the logical
+// combinators AllOf, AnyOf, and FirstMatch, and explains which rules fired during evaluation.
+//
+// All of the rules within a set are evaluated against a single shared activation. Programs
+// created with the cel.OptCacheAttributes evaluation option also share their attribute resolution
+// results, so an attribute such as `request.auth.claims` referenced by many rules is only
+// resolved once per evaluation of the set. Rules compiled together with CompileRules additionally
+// share the results of the function calls and comprehensions which appear in several rules.
+package ruleset
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+)
+
+// Node is a rule or a combination of rules which evaluates to a boolean outcome.
+type Node interface {
+	// eval returns whether the node matched the input, recording the outcome of each rule
+	// evaluated within the evaluation.
+	eval(ev *evaluation) (bool, error)
+}
+
+// Rule is a named boolean program.
+type Rule struct {
+	// Name identifies the rule within explanations.
+	Name string
+
+	// Priority orders the rules of a FirstMatch combinator, where rules with a higher priority are
+	// evaluated first. Rules of equal priority are evaluated in declaration order.
+	Priority int
+
+	// Program is the compiled rule expression, which must evaluate to a bool.
+	Program cel.Program
+}
+
+// NewRule creates a Rule from the expression source after verifying that the expression has a
+// boolean or dynamic output type.
+//
+// The rule program is created with the cel.OptCacheAttributes evaluation option in addition to
+// the given program options so that attribute resolution is shared with the other rules of the
+// set.
+func NewRule(env *cel.Env, name, expr string, priority int, opts ...cel.ProgramOption) (*Rule, error) {
+	ast, err := compileRule(env, name, expr)
+	if err != nil {
+		return nil, err
+	}
+	prg, err := newRuleProgram(env, name, ast, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Rule{Name: name, Priority: priority, Program: prg}, nil
+}
+
+// compileRule compiles the rule expression, verifying that it has a boolean or dynamic output
+// type.
+func compileRule(env *cel.Env, name, expr string) (*cel.Ast, error) {
+	ast, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("rule %s: %w", name, iss.Err())
+	}
+	if !ast.OutputType().IsAssignableType(cel.BoolType) {
+		return nil, fmt.Errorf("rule %s: got output type %v, wanted bool", name, ast.OutputType())
+	}
+	return ast, nil
+}
+
+// newRuleProgram creates the rule program with the cel.OptCacheAttributes evaluation option in
+// addition to the given program options.
+func newRuleProgram(env *cel.Env, name string, ast *cel.Ast, opts ...cel.ProgramOption) (cel.Program, error) {
+	prgOpts := make([]cel.ProgramOption, 0, len(opts)+1)
+	prgOpts = append(prgOpts, cel.EvalOptions(cel.OptCacheAttributes))
+	prgOpts = append(prgOpts, opts...)
+	prg, err := env.Program(ast, prgOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: %w", name, err)
+	}
+	return prg, nil
+}
+
+func (r *Rule) eval(ev *evaluation) (bool, error) {
+	if err := ev.ctx.Err(); err != nil {
+		return false, err
+	}
+	out, _, err := r.Program.Eval(ev.vars)
+	outcome := Outcome{Rule: r.Name, Value: out, Err: err}
+	if err == nil {
+		if b, isBool := out.(types.Bool); isBool {
+			outcome.Matched = bool(b)
+		} else {
+			outcome.Err = fmt.Errorf("rule %s: got %v, wanted bool result", r.Name, out)
+		}
+	}
+	ev.outcomes = append(ev.outcomes, outcome)
+	return outcome.Matched, outcome.Err
+}
+
+// AllOf returns a Node which matches when all of its nodes match.
+//
+// Evaluation stops at the first node which does not match. When no node fails to match but a
+// node produces an error, the first error is returned, mirroring the semantics of CEL `&&`.
+func AllOf(nodes ...Node) Node {
+	return &allOf{nodes: nodes}
+}
+
+type allOf struct {
+	nodes []Node
+}
+
+func (n *allOf) eval(ev *evaluation) (bool, error) {
+	var firstErr error
+	for _, node := range n.nodes {
+		matched, err := node.eval(ev)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	return firstErr == nil, firstErr
+}
+
+// AnyOf returns a Node which matches when any of its nodes match.
+//
+// Evaluation stops at the first node which matches. When no node matches but a node produces an
+// error, the first error is returned, mirroring the semantics of CEL `||`.
+func AnyOf(nodes ...Node) Node {
+	return &anyOf{nodes: nodes}
+}
+
+type anyOf struct {
+	nodes []Node
+}
+
+func (n *anyOf) eval(ev *evaluation) (bool, error) {
+	var firstErr error
+	for _, node := range n.nodes {
+		matched, err := node.eval(ev)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, firstErr
+}
+
+// FirstMatch returns a Node which evaluates its rules in priority order and matches when one of
+// them matches, selecting the first matching rule.
+//
+// Since a lower priority rule must not be selected when a higher priority rule cannot be decided,
+// an error produced by a rule ends evaluation and is returned.
+func FirstMatch(rules ...*Rule) Node {
+	sorted := make([]*Rule, len(rules))
+	copy(sorted, rules)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+	return &firstMatch{rules: sorted}
+}
+
+type firstMatch struct {
+	rules []*Rule
+}
+
+func (n *firstMatch) eval(ev *evaluation) (bool, error) {
+	for _, r := range n.rules {
+		matched, err := r.eval(ev)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			if ev.selected == "" {
+				ev.selected = r.Name
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Outcome records the evaluation of a single rule.
+type Outcome struct {
+	// Rule is the name of the rule.
+	Rule string
+
+	// Matched indicates whether the rule evaluated to true.
+	Matched bool
+
+	// Value is the result of the rule program, which may be nil when evaluation fails.
+	Value ref.Val
+
+	// Err is the error produced by the rule, if any.
+	Err error
+}
+
+// Result is the outcome of evaluating a rule set.
+type Result struct {
+	// Matched indicates whether the root node of the rule set matched.
+	Matched bool
+
+	// Selected is the name of the first rule selected by a FirstMatch combinator, or empty when no
+	// FirstMatch combinator selected a rule.
+	Selected string
+
+	// Outcomes holds the outcome of each evaluated rule in evaluation order. Rules skipped due to
+	// short-circuiting are absent.
+	Outcomes []Outcome
+}
+
+// Fired returns the names of the rules which matched, in evaluation order.
+func (r *Result) Fired() []string {
+	var fired []string
+	for _, o := range r.Outcomes {
+		if o.Matched {
+			fired = append(fired, o.Rule)
+		}
+	}
+	return fired
+}
+
+// Explain returns a human-readable summary of the outcome of each evaluated rule, one per line.
+func (r *Result) Explain() string {
+	var sb strings.Builder
+	for _, o := range r.Outcomes {
+		switch {
+		case o.Err != nil:
+			fmt.Fprintf(&sb, "%s: error: %v\n", o.Rule, o.Err)
+		case o.Matched:
+			fmt.Fprintf(&sb, "%s: fired\n", o.Rule)
+		default:
+			fmt.Fprintf(&sb, "%s: not fired\n", o.Rule)
+		}
+	}
+	return sb.String()
+}
+
+// Eval evaluates the rule set rooted at the node against the input, which may either be an
+// `interpreter.Activation` or a `map[string]any`.
+//
+// The context is consulted before each rule is evaluated; programs which should also observe
+// cancellation during evaluation must be created with the cel.InterruptCheckFrequency option and
+// evaluated via an activation which reports interrupts.
+//
+// The Result is returned even when evaluation produces an error, so that the rules which were
+// evaluated before the error may be explained.
+func Eval(ctx context.Context, root Node, input any) (*Result, error) {
+	var vars interpreter.Activation
+	switch v := input.(type) {
+	case interpreter.Activation:
+		vars = v
+	case map[string]any:
+		act, err := interpreter.NewActivation(v)
+		if err != nil {
+			return nil, err
+		}
+		vars = act
+	default:
+		return nil, fmt.Errorf("invalid input, wanted Activation or map[string]any, got: (%T)%v", input, input)
+	}
+	// The memo of shared subexpressions is placed beneath the attribute cache so that the rule
+	// programs reuse the attribute cache rather than creating their own.
+	ev := &evaluation{
+		ctx:  ctx,
+		vars: interpreter.NewAttributeCacheActivation(newSharedMemo(vars)),
+	}
+	matched, err := root.eval(ev)
+	return &Result{
+		Matched:  matched && err == nil,
+		Selected: ev.selected,
+		Outcomes: ev.outcomes,
+	}, err
+}
+
+// evaluation holds the state shared by the rules evaluated during a single call to Eval.
+type evaluation struct {
+	ctx      context.Context
+	vars     interpreter.Activation
+	selected string
+	outcomes []Outcome
+}
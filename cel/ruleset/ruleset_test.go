@@ -0,0 +1,270 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ruleset
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+)
+
+func TestEval(t *testing.T) {
+	env, err := cel.NewEnv(
+		cel.Variable("request", cel.MapType(cel.StringType, cel.DynType)),
+	)
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	rule := func(name, expr string, priority int) *Rule {
+		t.Helper()
+		r, err := NewRule(env, name, expr, priority)
+		if err != nil {
+			t.Fatalf("NewRule(%q) failed: %v", expr, err)
+		}
+		return r
+	}
+	isAdmin := rule("is_admin", `'admin' in request.roles`, 10)
+	isOwner := rule("is_owner", `request.user == request.owner`, 5)
+	isPublic := rule("is_public", `request.public`, 1)
+	isActive := rule("is_active", `request.active`, 0)
+	divByZero := rule("div_by_zero", `1 / request.zero == 1`, 20)
+
+	tests := []struct {
+		name     string
+		root     Node
+		vars     map[string]any
+		matched  bool
+		selected string
+		fired    []string
+		explain  string
+		err      string
+	}{
+		{
+			name:    "all_of",
+			root:    AllOf(isActive, AnyOf(isAdmin, isOwner)),
+			vars:    map[string]any{"active": true, "roles": []string{}, "user": "a", "owner": "a"},
+			matched: true,
+			fired:   []string{"is_active", "is_owner"},
+			explain: "is_active: fired\nis_admin: not fired\nis_owner: fired\n",
+		},
+		{
+			name:    "all_of_short_circuit",
+			root:    AllOf(isActive, isAdmin),
+			vars:    map[string]any{"active": false},
+			explain: "is_active: not fired\n",
+		},
+		{
+			name:    "all_of_false_absorbs_error",
+			root:    AllOf(divByZero, isActive),
+			vars:    map[string]any{"active": false, "zero": 0},
+			explain: "div_by_zero: error: division by zero\nis_active: not fired\n",
+		},
+		{
+			name:    "any_of_error",
+			root:    AnyOf(divByZero, isActive),
+			vars:    map[string]any{"active": false, "zero": 0},
+			explain: "div_by_zero: error: division by zero\nis_active: not fired\n",
+			err:     "division by zero",
+		},
+		{
+			name:     "first_match_priority",
+			root:     FirstMatch(isPublic, isOwner, isAdmin),
+			vars:     map[string]any{"public": true, "roles": []string{}, "user": "a", "owner": "a"},
+			matched:  true,
+			selected: "is_owner",
+			fired:    []string{"is_owner"},
+			explain:  "is_admin: not fired\nis_owner: fired\n",
+		},
+		{
+			name:    "first_match_error",
+			root:    FirstMatch(isPublic, divByZero),
+			vars:    map[string]any{"public": true, "zero": 0},
+			explain: "div_by_zero: error: division by zero\n",
+			err:     "division by zero",
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := Eval(context.Background(), tc.root, map[string]any{"request": tc.vars})
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("Eval() got error %v, wanted %q", err, tc.err)
+				}
+			} else if err != nil {
+				t.Fatalf("Eval() failed: %v", err)
+			}
+			if res.Matched != tc.matched {
+				t.Errorf("Eval() got matched %v, wanted %v", res.Matched, tc.matched)
+			}
+			if res.Selected != tc.selected {
+				t.Errorf("Eval() got selected %q, wanted %q", res.Selected, tc.selected)
+			}
+			if !reflect.DeepEqual(res.Fired(), tc.fired) {
+				t.Errorf("Fired() got %v, wanted %v", res.Fired(), tc.fired)
+			}
+			if res.Explain() != tc.explain {
+				t.Errorf("Explain() got %q, wanted %q", res.Explain(), tc.explain)
+			}
+		})
+	}
+}
+
+func TestEvalSharedAttributeCache(t *testing.T) {
+	env, err := cel.NewEnv(
+		cel.Variable("request", cel.MapType(cel.StringType, cel.DynType)),
+	)
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	var rules []Node
+	for _, expr := range []string{
+		`request.user == 'alice'`,
+		`request.user.startsWith('a')`,
+		`request.user.size() == 5`,
+	} {
+		r, err := NewRule(env, expr, expr, 0)
+		if err != nil {
+			t.Fatalf("NewRule(%q) failed: %v", expr, err)
+		}
+		rules = append(rules, r)
+	}
+	vars := &countingActivation{
+		vars:   map[string]any{"request": map[string]any{"user": "alice"}},
+		counts: map[string]int{},
+	}
+	res, err := Eval(context.Background(), AllOf(rules...), vars)
+	if err != nil {
+		t.Fatalf("Eval() failed: %v", err)
+	}
+	if !res.Matched || len(res.Fired()) != 3 {
+		t.Errorf("Eval() got %v, wanted all rules to fire", res.Explain())
+	}
+	if vars.counts["request"] != 1 {
+		t.Errorf("request resolved %d times, wanted 1", vars.counts["request"])
+	}
+}
+
+func TestCompileRulesSharedExprs(t *testing.T) {
+	calls := map[string]int{}
+	env, err := cel.NewEnv(
+		cel.Variable("request", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Function("lookup",
+			cel.Overload("lookup_string", []*cel.Type{cel.StringType}, cel.ListType(cel.StringType),
+				cel.UnaryBinding(func(arg ref.Val) ref.Val {
+					calls[string(arg.(types.String))]++
+					return types.NewStringList(types.DefaultTypeAdapter, []string{"admin", "dev"})
+				}))),
+	)
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	rules, err := CompileRules(env, []RuleSource{
+		{Name: "is_admin", Expr: `'admin' in lookup(request.user)`},
+		{Name: "is_dev", Expr: `'dev' in lookup(request.user)`},
+		{Name: "is_owner_or_dev", Expr: `request.owner == request.user || 'dev' in lookup(request.user)`},
+		// Calls which refer to comprehension variables are evaluated for each element.
+		{Name: "all_groups", Expr: `request.groups.all(g, lookup(g).size() == 2)`},
+		{Name: "any_group", Expr: `request.groups.exists(g, lookup(g).size() == 2)`},
+		{Name: "unshared", Expr: `lookup(request.owner).size() > 0`},
+	})
+	if err != nil {
+		t.Fatalf("CompileRules() failed: %v", err)
+	}
+	vars := map[string]any{"request": map[string]any{
+		"user":   "alice",
+		"owner":  "bob",
+		"groups": []string{"eng", "ops"},
+	}}
+	for i := 0; i < 2; i++ {
+		nodes := make([]Node, len(rules))
+		for j, r := range rules {
+			nodes[j] = r
+		}
+		res, err := Eval(context.Background(), AllOf(nodes...), vars)
+		if err != nil {
+			t.Fatalf("Eval() failed: %v", err)
+		}
+		if !res.Matched {
+			t.Errorf("Eval() got %v, wanted all rules to fire", res.Explain())
+		}
+	}
+	// The shared subexpressions are evaluated once per call to Eval, while the lookups of the
+	// groups are performed by both comprehensions, except where exists() stops early.
+	want := map[string]int{"alice": 2, "eng": 4, "ops": 2, "bob": 2}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("got lookup() calls %v, wanted %v", calls, want)
+	}
+
+	// Programs evaluated outside of a rule set evaluate the shared subexpressions as usual.
+	out, _, err := rules[0].Program.Eval(vars)
+	if err != nil || out != types.True {
+		t.Errorf("rules[0].Program.Eval() got %v, %v, wanted true", out, err)
+	}
+}
+
+func TestEvalCancelled(t *testing.T) {
+	env, err := cel.NewEnv()
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	r, err := NewRule(env, "always", `true`, 0)
+	if err != nil {
+		t.Fatalf("NewRule() failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err := Eval(ctx, AnyOf(r), map[string]any{})
+	if err != context.Canceled {
+		t.Errorf("Eval() got error %v, wanted %v", err, context.Canceled)
+	}
+	if res.Matched {
+		t.Error("Eval() matched after cancellation")
+	}
+}
+
+func TestNewRuleErrors(t *testing.T) {
+	env, err := cel.NewEnv(cel.Variable("x", cel.IntType))
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	if _, err := NewRule(env, "not_bool", `x + 1`, 0); err == nil || !strings.Contains(err.Error(), "wanted bool") {
+		t.Errorf("NewRule() got error %v, wanted output type error", err)
+	}
+	if _, err := NewRule(env, "bad_syntax", `x +`, 0); err == nil || !strings.Contains(err.Error(), "rule bad_syntax") {
+		t.Errorf("NewRule() got error %v, wanted syntax error", err)
+	}
+}
+
+type countingActivation struct {
+	vars   map[string]any
+	counts map[string]int
+}
+
+func (a *countingActivation) ResolveName(name string) (any, bool) {
+	a.counts[name]++
+	val, found := a.vars[name]
+	return val, found
+}
+
+func (a *countingActivation) Parent() interpreter.Activation {
+	return nil
+}
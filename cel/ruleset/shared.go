@@ -0,0 +1,357 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ruleset
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// RuleSource describes a rule to be compiled by CompileRules.
+type RuleSource struct {
+	// Name identifies the rule within explanations.
+	Name string
+
+	// Expr is the source of the rule expression, which must evaluate to a bool.
+	Expr string
+
+	// Priority orders the rules of a FirstMatch combinator, see Rule.Priority.
+	Priority int
+}
+
+// CompileRules compiles the rules of a module together, returning the rules in the order of their
+// sources.
+//
+// In addition to the checks and options applied by NewRule, the function calls and comprehensions
+// which appear in more than one rule are planned as shared subexpressions: the first rule to
+// evaluate a shared subexpression during a call to Eval records its result, and the other rules
+// reuse the result rather than evaluating the subexpression again. Subexpressions which refer to
+// the variables of an enclosing comprehension are not shared.
+//
+// Since shared results are reused for the remainder of the evaluation, rules which call
+// nondeterministic functions should be compiled with NewRule instead.
+func CompileRules(env *cel.Env, sources []RuleSource, opts ...cel.ProgramOption) ([]*Rule, error) {
+	asts := make([]*cel.Ast, len(sources))
+	for i, src := range sources {
+		ast, err := compileRule(env, src.Name, src.Expr)
+		if err != nil {
+			return nil, err
+		}
+		asts[i] = ast
+	}
+	slots, err := planSharedExprs(asts)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]*Rule, len(sources))
+	for i, src := range sources {
+		ruleOpts := opts
+		if len(slots[i]) > 0 {
+			ruleOpts = append([]cel.ProgramOption{cel.CustomDecorator(shareExprs(slots[i]))}, opts...)
+		}
+		prg, err := newRuleProgram(env, src.Name, asts[i], ruleOpts...)
+		if err != nil {
+			return nil, err
+		}
+		rules[i] = &Rule{Name: src.Name, Priority: src.Priority, Program: prg}
+	}
+	return rules, nil
+}
+
+// planSharedExprs assigns a memoization slot to each function call and comprehension which occurs
+// within more than one of the expressions, returning the slots of each expression by expression
+// id.
+func planSharedExprs(asts []*cel.Ast) ([]map[int64]int, error) {
+	occurrences := map[string]map[int][]int64{}
+	for i, ast := range asts {
+		checked, err := cel.AstToCheckedExpr(ast)
+		if err != nil {
+			return nil, err
+		}
+		k := &exprKeyer{refMap: checked.GetReferenceMap(), report: func(id int64, key string) {
+			if occurrences[key] == nil {
+				occurrences[key] = map[int][]int64{}
+			}
+			occurrences[key][i] = append(occurrences[key][i], id)
+		}}
+		cel.WalkExpr(checked.GetExpr(), k.visit, k.key)
+	}
+	var keys []string
+	for key, rules := range occurrences {
+		if len(rules) > 1 {
+			keys = append(keys, key)
+		}
+	}
+	// Assign the slots in a stable order.
+	sort.Strings(keys)
+	slots := make([]map[int64]int, len(asts))
+	for slot, key := range keys {
+		for i, ids := range occurrences[key] {
+			if slots[i] == nil {
+				slots[i] = map[int64]int{}
+			}
+			for _, id := range ids {
+				slots[i][id] = slot
+			}
+		}
+	}
+	return slots, nil
+}
+
+// exprKeyer computes structural keys for subexpressions which are independent of expression ids,
+// reporting the keys of the calls and comprehensions which do not refer to comprehension variables
+// bound outside of them.
+type exprKeyer struct {
+	refMap map[int64]*exprpb.Reference
+	report func(id int64, key string)
+	// keys holds the keys of the visited subexpressions whose parents have yet to be keyed.
+	keys []exprKey
+}
+
+// exprKey is the key of an expression along with the set of comprehension variables it refers to.
+type exprKey struct {
+	key  string
+	free map[string]bool
+}
+
+// visit skips the operands of qualified identifiers.
+func (k *exprKeyer) visit(e *exprpb.Expr, _ *cel.ExprScope) bool {
+	return e.GetSelectExpr() == nil || k.refMap[e.GetId()].GetName() == ""
+}
+
+// key computes the key of the expression from the keys of its subexpressions.
+func (k *exprKeyer) key(e *exprpb.Expr, scope *cel.ExprScope) {
+	children := k.keys[len(k.keys)-k.childCount(e):]
+	k.keys = k.keys[:len(k.keys)-len(children)]
+	var sb strings.Builder
+	free := map[string]bool{}
+	next := 0
+	child := func() *exprKey {
+		c := &children[next]
+		next++
+		sb.WriteString(c.key)
+		for name := range c.free {
+			free[name] = true
+		}
+		return c
+	}
+	shareable := false
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_ConstExpr:
+		fmt.Fprintf(&sb, "%v", e.GetConstExpr())
+	case *exprpb.Expr_IdentExpr:
+		name := e.GetIdentExpr().GetName()
+		if scope.IsLocal(name) {
+			free[name] = true
+		} else if ref, found := k.refMap[e.GetId()]; found && ref.GetName() != "" {
+			name = ref.GetName()
+		}
+		sb.WriteString(name)
+	case *exprpb.Expr_SelectExpr:
+		sel := e.GetSelectExpr()
+		if ref, found := k.refMap[e.GetId()]; found && ref.GetName() != "" {
+			// A qualified identifier.
+			sb.WriteString(ref.GetName())
+			break
+		}
+		if sel.GetTestOnly() {
+			sb.WriteString("has(")
+		}
+		child()
+		fmt.Fprintf(&sb, ".%s", sel.GetField())
+		if sel.GetTestOnly() {
+			sb.WriteString(")")
+		}
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		sb.WriteString(call.GetFunction())
+		if ref, found := k.refMap[e.GetId()]; found {
+			fmt.Fprintf(&sb, "%v", ref.GetOverloadId())
+		}
+		sb.WriteString("(")
+		if call.GetTarget() != nil {
+			child()
+			sb.WriteString(".")
+		}
+		for range call.GetArgs() {
+			child()
+			sb.WriteString(",")
+		}
+		sb.WriteString(")")
+		shareable = true
+	case *exprpb.Expr_ListExpr:
+		sb.WriteString("[")
+		for range e.GetListExpr().GetElements() {
+			child()
+			sb.WriteString(",")
+		}
+		sb.WriteString("]")
+	case *exprpb.Expr_StructExpr:
+		st := e.GetStructExpr()
+		fmt.Fprintf(&sb, "%s{", st.GetMessageName())
+		for _, entry := range st.GetEntries() {
+			if entry.GetMapKey() != nil {
+				child()
+			} else {
+				sb.WriteString(entry.GetFieldKey())
+			}
+			sb.WriteString(":")
+			child()
+			sb.WriteString(",")
+		}
+		sb.WriteString("}")
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		fmt.Fprintf(&sb, "comprehension(%s,%s,", comp.GetIterVar(), comp.GetAccuVar())
+		child()
+		sb.WriteString(",")
+		child()
+		sb.WriteString(",")
+		inner := map[string]bool{}
+		for _, c := range children[next:] {
+			sb.WriteString(c.key)
+			sb.WriteString(",")
+			for name := range c.free {
+				inner[name] = true
+			}
+		}
+		sb.WriteString(")")
+		delete(inner, comp.GetIterVar())
+		delete(inner, comp.GetAccuVar())
+		for name := range inner {
+			free[name] = true
+		}
+		shareable = true
+	}
+	key := sb.String()
+	if shareable && len(free) == 0 {
+		k.report(e.GetId(), key)
+	}
+	k.keys = append(k.keys, exprKey{key: key, free: free})
+}
+
+// childCount returns the number of subexpressions of the expression which are keyed.
+func (k *exprKeyer) childCount(e *exprpb.Expr) int {
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		if !k.visit(e, nil) {
+			return 0
+		}
+		return 1
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		if call.GetTarget() != nil {
+			return len(call.GetArgs()) + 1
+		}
+		return len(call.GetArgs())
+	case *exprpb.Expr_ListExpr:
+		return len(e.GetListExpr().GetElements())
+	case *exprpb.Expr_StructExpr:
+		n := 0
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			if entry.GetMapKey() != nil {
+				n++
+			}
+			n++
+		}
+		return n
+	case *exprpb.Expr_ComprehensionExpr:
+		return 5
+	}
+	return 0
+}
+
+// shareExprs returns a decorator which memoizes the results of the shared subexpressions within
+// the memo of the evaluation.
+func shareExprs(slots map[int64]int) interpreter.InterpretableDecorator {
+	return func(i interpreter.Interpretable) (interpreter.Interpretable, error) {
+		slot, found := slots[i.ID()]
+		if !found {
+			return i, nil
+		}
+		return &sharedExpr{Interpretable: i, slot: slot}, nil
+	}
+}
+
+// sharedExpr evaluates a shared subexpression at most once per evaluation of a rule set.
+type sharedExpr struct {
+	interpreter.Interpretable
+	slot int
+}
+
+// Eval implements the Interpretable interface method.
+func (s *sharedExpr) Eval(vars interpreter.Activation) ref.Val {
+	m, found := vars.ResolveName(sharedMemoName)
+	if !found {
+		return s.Interpretable.Eval(vars)
+	}
+	memo := m.(*sharedMemo)
+	if val, found := memo.get(s.slot); found {
+		return val
+	}
+	val := s.Interpretable.Eval(vars)
+	memo.put(s.slot, val)
+	return val
+}
+
+// sharedMemoName is the name by which the memo of an evaluation is resolved from the activation.
+// The name is not a valid CEL identifier, and so cannot be referenced by a rule.
+const sharedMemoName = "@ruleset.memo"
+
+// sharedMemo is an activation which holds the results of the shared subexpressions evaluated
+// during a single evaluation of a rule set.
+type sharedMemo struct {
+	interpreter.Activation
+	mu     sync.Mutex
+	values map[int]ref.Val
+}
+
+func newSharedMemo(vars interpreter.Activation) *sharedMemo {
+	return &sharedMemo{Activation: vars, values: map[int]ref.Val{}}
+}
+
+// ResolveName implements the Activation interface method.
+func (m *sharedMemo) ResolveName(name string) (any, bool) {
+	if name == sharedMemoName {
+		return m, true
+	}
+	return m.Activation.ResolveName(name)
+}
+
+// Parent implements the Activation interface method.
+func (m *sharedMemo) Parent() interpreter.Activation {
+	return m.Activation
+}
+
+func (m *sharedMemo) get(slot int) (ref.Val, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	val, found := m.values[slot]
+	return val, found
+}
+
+func (m *sharedMemo) put(slot int, val ref.Val) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[slot] = val
+}
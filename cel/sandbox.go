@@ -0,0 +1,174 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter/functions"
+)
+
+// SandboxFunctions isolates the evaluation from the implementations of the functions declared with
+// the Function option, such as those of custom functions and extension libraries.
+//
+// A panic within an implementation is recovered and reported as the result of the call, which is
+// an error wrapping a *FunctionPanicError. When the timeout is positive, each call is abandoned
+// once the timeout elapses and reported as an error wrapping a *FunctionTimeoutError. Context
+// functions receive a context which is cancelled on timeout, though other implementations cannot be
+// interrupted and run to completion in the background, so the timeout bounds the latency of the
+// evaluation rather than the resources consumed by the implementation.
+//
+// The errors may be inspected with errors.As, e.g.
+//
+//	var panicErr *cel.FunctionPanicError
+//	if errors.As(err, &panicErr) { ... }
+//
+// The standard library functions and the overloads provided by the Functions option are not
+// sandboxed.
+func SandboxFunctions(timeout time.Duration) ProgramOption {
+	return func(p *prog) (*prog, error) {
+		p.sandbox = &functionSandbox{timeout: timeout}
+		return p, nil
+	}
+}
+
+// FunctionPanicError reports a panic raised by the implementation of a sandboxed function.
+type FunctionPanicError struct {
+	// OverloadID is the overload id, or the function name when dispatched dynamically.
+	OverloadID string
+
+	// Value is the value with which the implementation panicked.
+	Value any
+
+	// StackDigest is a digest of the functions and lines on the stack of the panic, which is stable
+	// across panics raised at the same site and so may be used to group occurrences.
+	StackDigest string
+}
+
+// Error implements the error interface method.
+func (e *FunctionPanicError) Error() string {
+	return fmt.Sprintf("panic in function '%s': %v (stack digest %s)", e.OverloadID, e.Value, e.StackDigest)
+}
+
+// FunctionTimeoutError reports a call to a sandboxed function which exceeded the timeout.
+type FunctionTimeoutError struct {
+	// OverloadID is the overload id, or the function name when dispatched dynamically.
+	OverloadID string
+
+	// Timeout is the timeout which was exceeded.
+	Timeout time.Duration
+}
+
+// Error implements the error interface method.
+func (e *FunctionTimeoutError) Error() string {
+	return fmt.Sprintf("function '%s' timed out after %v", e.OverloadID, e.Timeout)
+}
+
+type functionSandbox struct {
+	timeout time.Duration
+}
+
+// wrap returns a copy of the overload whose implementations are sandboxed.
+func (s *functionSandbox) wrap(o *functions.Overload) *functions.Overload {
+	id := o.Operator
+	sandboxed := *o
+	if o.Unary != nil {
+		sandboxed.Unary = func(arg ref.Val) ref.Val {
+			return s.call(context.Background(), id, func(context.Context) ref.Val {
+				return o.Unary(arg)
+			})
+		}
+	}
+	if o.Binary != nil {
+		sandboxed.Binary = func(lhs, rhs ref.Val) ref.Val {
+			return s.call(context.Background(), id, func(context.Context) ref.Val {
+				return o.Binary(lhs, rhs)
+			})
+		}
+	}
+	if o.Function != nil {
+		sandboxed.Function = func(args ...ref.Val) ref.Val {
+			return s.call(context.Background(), id, func(context.Context) ref.Val {
+				return o.Function(args...)
+			})
+		}
+	}
+	if o.ContextFunction != nil {
+		sandboxed.ContextFunction = func(ctx context.Context, args ...ref.Val) ref.Val {
+			return s.call(ctx, id, func(ctx context.Context) ref.Val {
+				return o.ContextFunction(ctx, args...)
+			})
+		}
+	}
+	return &sandboxed
+}
+
+// call invokes the implementation, recovering panics and enforcing the timeout.
+func (s *functionSandbox) call(ctx context.Context, id string, fn func(context.Context) ref.Val) ref.Val {
+	if s.timeout <= 0 {
+		return recoverCall(id, func() ref.Val { return fn(ctx) })
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	// The channel is buffered so that an abandoned call does not block once it completes.
+	result := make(chan ref.Val, 1)
+	go func() {
+		result <- recoverCall(id, func() ref.Val { return fn(ctx) })
+	}()
+	select {
+	case val := <-result:
+		return val
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return types.WrapErr(&FunctionTimeoutError{OverloadID: id, Timeout: s.timeout})
+		}
+		return types.WrapErr(ctx.Err())
+	}
+}
+
+// recoverCall converts a panic within the call into an error value.
+func recoverCall(id string, fn func() ref.Val) (val ref.Val) {
+	defer func() {
+		if r := recover(); r != nil {
+			val = types.WrapErr(&FunctionPanicError{OverloadID: id, Value: r, StackDigest: stackDigest()})
+		}
+	}()
+	return fn()
+}
+
+// stackDigest digests the functions and lines of the calling stack, excluding the runtime.
+func stackDigest() string {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	h := sha256.New()
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			fmt.Fprintf(h, "%s:%d\n", frame.Function, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+func TestSandboxFunctions(t *testing.T) {
+	env, err := NewEnv(
+		Function("boom",
+			Overload("boom_string", []*Type{StringType}, BoolType,
+				UnaryBinding(func(arg ref.Val) ref.Val {
+					panic(fmt.Sprintf("boom: %v", arg))
+				}))),
+		Function("slow",
+			Overload("slow_int", []*Type{IntType}, IntType,
+				UnaryBinding(func(arg ref.Val) ref.Val {
+					time.Sleep(time.Duration(arg.(types.Int)) * time.Millisecond)
+					return arg
+				}))))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	eval := func(expr string, opts ...ProgramOption) (ref.Val, error) {
+		t.Helper()
+		ast, iss := env.Compile(expr)
+		if iss.Err() != nil {
+			t.Fatalf("env.Compile(%q) failed: %v", expr, iss.Err())
+		}
+		prg, err := env.Program(ast, opts...)
+		if err != nil {
+			t.Fatalf("env.Program(%q) failed: %v", expr, err)
+		}
+		out, _, err := prg.Eval(NoVars())
+		return out, err
+	}
+
+	// Without the sandbox, the panic aborts the evaluation.
+	if _, err := eval(`boom('a') || true`); err == nil || !strings.Contains(err.Error(), "internal error: boom: a") {
+		t.Errorf("eval() without sandbox got %v, wanted internal error", err)
+	}
+	// With the sandbox, the panic is the result of the call.
+	if out, err := eval(`boom('a') || true`, SandboxFunctions(0)); err != nil || out != types.True {
+		t.Errorf("eval() with sandbox got %v, %v, wanted true", out, err)
+	}
+	var digests []string
+	for _, arg := range []string{"a", "b"} {
+		_, err := eval(fmt.Sprintf(`boom('%s')`, arg), SandboxFunctions(0))
+		var panicErr *FunctionPanicError
+		if !errors.As(err, &panicErr) {
+			t.Fatalf("eval() got %v, wanted a FunctionPanicError", err)
+		}
+		if panicErr.OverloadID != "boom_string" || panicErr.Value != "boom: "+arg || panicErr.StackDigest == "" {
+			t.Errorf("eval() got %+v, wanted a panic in boom_string", panicErr)
+		}
+		digests = append(digests, panicErr.StackDigest)
+	}
+	if digests[0] != digests[1] {
+		t.Errorf("stack digests of panics at the same site differ: %v", digests)
+	}
+
+	if out, err := eval(`slow(1)`, SandboxFunctions(time.Second)); err != nil || out != types.Int(1) {
+		t.Errorf("eval(slow(1)) got %v, %v, wanted 1", out, err)
+	}
+	_, err = eval(`slow(500)`, SandboxFunctions(10*time.Millisecond))
+	var timeoutErr *FunctionTimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.OverloadID != "slow_int" {
+		t.Errorf("eval(slow(500)) got %v, wanted a FunctionTimeoutError", err)
+	}
+}
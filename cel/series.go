@@ -0,0 +1,153 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+)
+
+// Series evaluates a program repeatedly against an input which changes by small deltas between
+// evaluations, such as the state of a stream of events. A Series is created by EvalSeries.
+//
+// The series layers the variables set by each delta over the base input within a single reusable
+// activation, so no activation is allocated per evaluation. A Series is not safe for concurrent
+// use.
+type Series struct {
+	prg     Program
+	vars    *seriesActivation
+	changed []string
+}
+
+// newSeries returns a Series which evaluates the program against the base input, which
+// may either be an `interpreter.Activation` or a `map[string]any`.
+func newSeries(prg Program, base any) (*Series, error) {
+	vars, err := interpreter.NewActivation(base)
+	if err != nil {
+		return nil, err
+	}
+	return &Series{
+		prg: prg,
+		vars: &seriesActivation{
+			base:     vars,
+			bindings: map[string]any{},
+		},
+	}, nil
+}
+
+// Next applies the delta to the input of the series and evaluates the program against it.
+//
+// Each variable within the delta replaces the value of the variable from the base input or from
+// an earlier delta, and remains set for subsequent evaluations. Variables whose values are
+// unchanged are ignored, so that an IncrementalProgram only invalidates the cached results of the
+// subexpressions which depend on the variables that actually changed. Lazily computed values,
+// i.e. `func() ref.Val` and `func() any`, are always considered changed.
+//
+// Values are compared with the values they replace, so a value must not be mutated in place once
+// it has been supplied to the series, either within the base input or a delta: a map or slice
+// which is modified in place and supplied again compares equal to itself and is ignored. Supply a
+// new value instead.
+//
+// The output contract of Next is otherwise identical to the Program Eval method.
+func (s *Series) Next(delta map[string]any) (ref.Val, *EvalDetails, error) {
+	s.changed = s.changed[:0]
+	for name, val := range delta {
+		prev, found := s.vars.ResolveName(name)
+		if found && sameValue(prev, val) {
+			continue
+		}
+		s.vars.bindings[name] = val
+		s.changed = append(s.changed, name)
+	}
+	sort.Strings(s.changed)
+	if inc := incrementalProgram(s.prg); inc != nil {
+		for _, name := range s.changed {
+			inc.NotifyChanged(name)
+		}
+	}
+	return s.prg.Eval(s.vars)
+}
+
+// Changed returns the sorted names of the variables whose values were changed by the most recent
+// call to Next.
+func (s *Series) Changed() []string {
+	changed := make([]string, len(s.changed))
+	copy(changed, s.changed)
+	return changed
+}
+
+// Vars returns the current input of the series, which reflects the base input and every delta
+// applied so far.
+func (s *Series) Vars() interpreter.Activation {
+	return s.vars
+}
+
+// incrementalProgram returns the IncrementalProgram which evaluates the program, unwrapping the
+// programs derived from it, or nil if the program is not incremental.
+func incrementalProgram(prg Program) *IncrementalProgram {
+	switch p := prg.(type) {
+	case *IncrementalProgram:
+		return p
+	case *boundProgram:
+		return incrementalProgram(p.base)
+	case *asyncProgram:
+		return incrementalProgram(p.Program)
+	case *preparedProgram:
+		return incrementalProgram(p.Program)
+	}
+	return nil
+}
+
+// sameValue returns whether a variable value is unchanged by a delta.
+func sameValue(prev, next any) bool {
+	prevVal, prevIsVal := prev.(ref.Val)
+	nextVal, nextIsVal := next.(ref.Val)
+	if prevIsVal && nextIsVal {
+		return prevVal.Type() == nextVal.Type() && prevVal.Equal(nextVal) == types.True
+	}
+	return reflect.DeepEqual(prev, next)
+}
+
+// seriesActivation resolves the variables set by the deltas of a series ahead of the base input.
+type seriesActivation struct {
+	base     interpreter.Activation
+	bindings map[string]any
+}
+
+// ResolveName implements the Activation interface method.
+func (a *seriesActivation) ResolveName(name string) (any, bool) {
+	val, found := a.bindings[name]
+	if !found {
+		return a.base.ResolveName(name)
+	}
+	switch fn := val.(type) {
+	case func() ref.Val:
+		val = fn()
+		a.bindings[name] = val
+	case func() any:
+		val = fn()
+		a.bindings[name] = val
+	}
+	return val, true
+}
+
+// Parent implements the Activation interface method.
+func (a *seriesActivation) Parent() interpreter.Activation {
+	return a.base
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter/functions"
+)
+
+func TestEvalSeries(t *testing.T) {
+	env, err := NewEnv(
+		Variable("threshold", IntType),
+		Variable("reading", IntType),
+		Variable("tags", ListType(StringType)),
+		Function("scale",
+			Overload("scale_int", []*Type{IntType}, IntType,
+				UnaryBinding(func(arg ref.Val) ref.Val {
+					return arg
+				})),
+			Rebindable()),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`reading > threshold && 'alert' in tags`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	inc, err := env.IncrementalProgram(ast)
+	if err != nil {
+		t.Fatalf("env.IncrementalProgram() failed: %v", err)
+	}
+	// Programs derived from an incremental program are notified of the changes by the series.
+	bound, err := WithFunctions(inc)
+	if err != nil {
+		t.Fatalf("WithFunctions() failed: %v", err)
+	}
+	base := map[string]any{"threshold": 10, "reading": 0, "tags": []string{"alert"}}
+	steps := []struct {
+		delta   map[string]any
+		out     ref.Val
+		changed []string
+	}{
+		{delta: map[string]any{"reading": 5}, out: types.False, changed: []string{"reading"}},
+		{delta: map[string]any{"reading": 11}, out: types.True, changed: []string{"reading"}},
+		{delta: map[string]any{"reading": 11, "tags": []string{"alert"}}, out: types.True, changed: []string{}},
+		{delta: map[string]any{"tags": []string{}}, out: types.False, changed: []string{"tags"}},
+		{delta: map[string]any{"threshold": 1, "tags": []string{"alert"}}, out: types.True, changed: []string{"tags", "threshold"}},
+	}
+	for _, p := range []Program{prg, inc, bound} {
+		series, err := EvalSeries(p, base)
+		if err != nil {
+			t.Fatalf("EvalSeries() failed: %v", err)
+		}
+		for i, step := range steps {
+			out, _, err := series.Next(step.delta)
+			if err != nil {
+				t.Fatalf("series.Next() step %d failed: %v", i, err)
+			}
+			if out != step.out {
+				t.Errorf("series.Next() step %d got %v, wanted %v", i, out, step.out)
+			}
+			if !reflect.DeepEqual(series.Changed(), step.changed) {
+				t.Errorf("series.Changed() step %d got %v, wanted %v", i, series.Changed(), step.changed)
+			}
+		}
+		if reading, _ := series.Vars().ResolveName("reading"); reading != 11 {
+			t.Errorf("series.Vars() got reading %v, wanted 11", reading)
+		}
+	}
+	// The base input is not modified by the deltas.
+	if base["reading"] != 0 {
+		t.Errorf("base input modified: %v", base)
+	}
+	if _, err := EvalSeries(prg, "invalid"); err == nil {
+		t.Error("EvalSeries() with an invalid base succeeded, wanted error")
+	}
+	_, err = EvalSeries(struct{ Program }{prg}, base)
+	if err == nil || !strings.Contains(err.Error(), "struct { cel.Program }") {
+		t.Errorf("EvalSeries() of an external program got %v, wanted error naming its type", err)
+	}
+
+	// The results of rebindable functions are not cached, so programs which share a cache but bind
+	// different implementations produce their own results.
+	ast, iss = env.Compile(`scale(reading) > threshold`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	inc, err = env.IncrementalProgram(ast)
+	if err != nil {
+		t.Fatalf("env.IncrementalProgram() failed: %v", err)
+	}
+	tripled, err := WithFunctions(inc, &functions.Overload{
+		Operator: "scale_int",
+		Unary: func(arg ref.Val) ref.Val {
+			return arg.(types.Int) * 3
+		},
+	})
+	if err != nil {
+		t.Fatalf("WithFunctions() failed: %v", err)
+	}
+	vars := map[string]any{"threshold": 10, "reading": 5}
+	for i, tc := range []struct {
+		prg Program
+		out ref.Val
+	}{{prg: inc, out: types.False}, {prg: tripled, out: types.True}, {prg: inc, out: types.False}} {
+		out, _, err := tc.prg.Eval(vars)
+		if err != nil || out != tc.out {
+			t.Errorf("prg.Eval() program %d got %v, %v, wanted %v", i, out, err, tc.out)
+		}
+	}
+}
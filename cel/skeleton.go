@@ -0,0 +1,230 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Skeleton is the structure of an expression with its literals and identifiers abstracted, such
+// that expressions which differ only in their constants or in the names of their variables share
+// the same skeleton. Skeletons are intended for grouping near-duplicate expressions, e.g. the
+// policies of many tenants which were written from the same template.
+//
+// Literals are abstracted to their kind, e.g. `<string>`, and identifiers, including the variables
+// of comprehensions, to `<ident>`. Function names, field names, and message type names are
+// retained, since they determine the behavior of the expression.
+type Skeleton struct {
+	root *skeletonNode
+	// features holds the multiset of the labels of each node combined with those of its children.
+	features map[string]int
+}
+
+// Skeleton returns the skeleton of the expression.
+func (ast *Ast) Skeleton() *Skeleton {
+	s := &Skeleton{root: newSkeletonNode(ast.Expr()), features: map[string]int{}}
+	s.root.collectFeatures(s.features)
+	return s
+}
+
+// String returns the canonical form of the skeleton, e.g. `_>_(<ident>.size(), <int>)`. Two
+// skeletons are identical if and only if their canonical forms are equal.
+func (s *Skeleton) String() string {
+	var sb strings.Builder
+	s.root.write(&sb)
+	return sb.String()
+}
+
+// Fingerprint returns a short hash of the canonical form of the skeleton, suitable as the key of
+// a cluster of expressions with identical skeletons.
+func (s *Skeleton) Fingerprint() string {
+	h := fnv.New64a()
+	h.Write([]byte(s.String()))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// Size returns the number of nodes in the skeleton.
+func (s *Skeleton) Size() int {
+	return s.root.size()
+}
+
+// Similarity returns a measure of the similarity of the skeletons between 0, for skeletons which
+// share no structure, and 1, for identical skeletons.
+//
+// The measure is the Jaccard index of the multisets of the nodes of either skeleton, where each
+// node is identified by its label along with the labels of its children. It is symmetric and
+// inexpensive to compute, so it may be used to compare every pair of a large number of
+// expressions, though skeletons which share every node may still differ in their arrangement.
+func (s *Skeleton) Similarity(other *Skeleton) float64 {
+	intersection, union := 0, 0
+	for f, n := range s.features {
+		m := other.features[f]
+		if n < m {
+			intersection += n
+			union += m
+		} else {
+			intersection += m
+			union += n
+		}
+	}
+	for f, m := range other.features {
+		if _, found := s.features[f]; !found {
+			union += m
+		}
+	}
+	if union == 0 {
+		return 1
+	}
+	return float64(intersection) / float64(union)
+}
+
+// skeletonNode is a node of a skeleton, written as its operand, if any, followed by its label and
+// its arguments enclosed by the open and close delimiters, e.g. `<ident>.size()`.
+type skeletonNode struct {
+	label   string
+	operand *skeletonNode
+	args    []*skeletonNode
+	open    string
+	close   string
+}
+
+func newSkeletonNode(e *exprpb.Expr) *skeletonNode {
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_ConstExpr:
+		return &skeletonNode{label: literalKind(e.GetConstExpr())}
+	case *exprpb.Expr_IdentExpr:
+		return &skeletonNode{label: "<ident>"}
+	case *exprpb.Expr_SelectExpr:
+		sel := e.GetSelectExpr()
+		n := &skeletonNode{label: "." + sel.GetField(), operand: newSkeletonNode(sel.GetOperand())}
+		if sel.GetTestOnly() {
+			return &skeletonNode{label: "has", args: []*skeletonNode{n}, open: "(", close: ")"}
+		}
+		return n
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		n := &skeletonNode{label: call.GetFunction(), open: "(", close: ")"}
+		if call.GetTarget() != nil {
+			n.label = "." + call.GetFunction()
+			n.operand = newSkeletonNode(call.GetTarget())
+		}
+		for _, arg := range call.GetArgs() {
+			n.args = append(n.args, newSkeletonNode(arg))
+		}
+		return n
+	case *exprpb.Expr_ListExpr:
+		n := &skeletonNode{open: "[", close: "]"}
+		for _, elem := range e.GetListExpr().GetElements() {
+			n.args = append(n.args, newSkeletonNode(elem))
+		}
+		return n
+	case *exprpb.Expr_StructExpr:
+		st := e.GetStructExpr()
+		n := &skeletonNode{label: st.GetMessageName(), open: "{", close: "}"}
+		for _, entry := range st.GetEntries() {
+			value := []*skeletonNode{newSkeletonNode(entry.GetValue())}
+			if entry.GetMapKey() != nil {
+				n.args = append(n.args,
+					&skeletonNode{label: ": ", operand: newSkeletonNode(entry.GetMapKey()), args: value})
+				continue
+			}
+			n.args = append(n.args, &skeletonNode{label: entry.GetFieldKey() + ": ", args: value})
+		}
+		return n
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		return &skeletonNode{label: "fold", open: "(", close: ")", args: []*skeletonNode{
+			newSkeletonNode(comp.GetIterRange()),
+			newSkeletonNode(comp.GetAccuInit()),
+			newSkeletonNode(comp.GetLoopCondition()),
+			newSkeletonNode(comp.GetLoopStep()),
+			newSkeletonNode(comp.GetResult()),
+		}}
+	}
+	return &skeletonNode{label: "<unspecified>"}
+}
+
+// literalKind returns the label of a literal of the kind of the constant.
+func literalKind(c *exprpb.Constant) string {
+	switch c.GetConstantKind().(type) {
+	case *exprpb.Constant_BoolValue:
+		return "<bool>"
+	case *exprpb.Constant_BytesValue:
+		return "<bytes>"
+	case *exprpb.Constant_DoubleValue:
+		return "<double>"
+	case *exprpb.Constant_Int64Value:
+		return "<int>"
+	case *exprpb.Constant_NullValue:
+		return "<null>"
+	case *exprpb.Constant_StringValue:
+		return "<string>"
+	case *exprpb.Constant_Uint64Value:
+		return "<uint>"
+	}
+	return "<literal>"
+}
+
+// name returns the label of the node, or its delimiters for list and map literals.
+func (n *skeletonNode) name() string {
+	if n.label != "" {
+		return n.label
+	}
+	return n.open + n.close
+}
+
+func (n *skeletonNode) children() []*skeletonNode {
+	if n.operand == nil {
+		return n.args
+	}
+	return append([]*skeletonNode{n.operand}, n.args...)
+}
+
+func (n *skeletonNode) write(sb *strings.Builder) {
+	if n.operand != nil {
+		n.operand.write(sb)
+	}
+	sb.WriteString(n.label)
+	sb.WriteString(n.open)
+	for i, arg := range n.args {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		arg.write(sb)
+	}
+	sb.WriteString(n.close)
+}
+
+func (n *skeletonNode) size() int {
+	size := 1
+	for _, c := range n.children() {
+		size += c.size()
+	}
+	return size
+}
+
+func (n *skeletonNode) collectFeatures(features map[string]int) {
+	children := n.children()
+	names := make([]string, len(children))
+	for i, c := range children {
+		names[i] = c.name()
+		c.collectFeatures(features)
+	}
+	features[n.name()+"("+strings.Join(names, ", ")+")"]++
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"testing"
+
+	proto3pb "github.com/google/cel-go/test/proto3pb"
+)
+
+func TestSkeleton(t *testing.T) {
+	env, err := NewEnv(
+		Variable("a", IntType),
+		Variable("b", IntType),
+		Variable("name", StringType),
+		Variable("tags", ListType(StringType)),
+		Types(&proto3pb.TestAllTypes{}),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	skeleton := func(expr string) *Skeleton {
+		t.Helper()
+		ast, iss := env.Compile(expr)
+		if iss.Err() != nil {
+			t.Fatalf("env.Compile(%q) failed: %v", expr, iss.Err())
+		}
+		return ast.Skeleton()
+	}
+	tests := []struct {
+		expr string
+		out  string
+	}{
+		{expr: `a > 10 && name.startsWith("dev")`,
+			out: `_&&_(_>_(<ident>, <int>), <ident>.startsWith(<string>))`},
+		{expr: `b > 42 && name.startsWith("prod")`,
+			out: `_&&_(_>_(<ident>, <int>), <ident>.startsWith(<string>))`},
+		{expr: `{"k": [a, 1]}`, out: `{<string>: [<ident>, <int>]}`},
+		{expr: `google.expr.proto3.test.TestAllTypes{single_int64: a}.single_int64`,
+			out: `google.expr.proto3.test.TestAllTypes{single_int64: <ident>}.single_int64`},
+	}
+	for _, tc := range tests {
+		if got := skeleton(tc.expr).String(); got != tc.out {
+			t.Errorf("Skeleton(%q) got %q, wanted %q", tc.expr, got, tc.out)
+		}
+	}
+
+	dev := skeleton(`a > 10 && name.startsWith("dev")`)
+	prod := skeleton(`b > 42 && name.startsWith("prod")`)
+	if dev.Fingerprint() != prod.Fingerprint() {
+		t.Errorf("Fingerprint() differs for identical skeletons: %s, %s", dev.Fingerprint(), prod.Fingerprint())
+	}
+	if sim := dev.Similarity(prod); sim != 1 {
+		t.Errorf("Similarity() of identical skeletons got %v, wanted 1", sim)
+	}
+	if dev.Size() != 7 {
+		t.Errorf("Size() got %d, wanted 7", dev.Size())
+	}
+	near := skeleton(`a > 10 && name.startsWith("dev") && "admin" in tags`)
+	far := skeleton(`tags.exists(t, t == "x")`)
+	nearSim, farSim := dev.Similarity(near), dev.Similarity(far)
+	if nearSim <= farSim || nearSim >= 1 {
+		t.Errorf("Similarity() got near %v and far %v, wanted far < near < 1", nearSim, farSim)
+	}
+	if near.Similarity(dev) != nearSim {
+		t.Error("Similarity() is not symmetric")
+	}
+}
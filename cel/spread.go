@@ -0,0 +1,83 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/parser"
+)
+
+type spreadLibrary struct{}
+
+// LibraryName implements the SingletonLibrary interface method.
+func (spreadLibrary) LibraryName() string {
+	return "cel.lib.spread"
+}
+
+// CompileOptions implements the Library interface method.
+func (spreadLibrary) CompileOptions() []EnvOption {
+	mapTypeKV := MapType(TypeParamType("K"), TypeParamType("V"))
+	return []EnvOption{
+		enableSpreadSyntax(),
+		Function(operators.MergeMaps,
+			Overload("merge_maps_map_K_V", []*Type{mapTypeKV, mapTypeKV}, mapTypeKV,
+				BinaryBinding(mergeMaps))),
+	}
+}
+
+// ProgramOptions implements the Library interface method.
+func (spreadLibrary) ProgramOptions() []ProgramOption {
+	return []ProgramOption{}
+}
+
+func enableSpreadSyntax() EnvOption {
+	return func(e *Env) (*Env, error) {
+		e.prsrOpts = append(e.prsrOpts, parser.EnableSpreadSyntax(true))
+		return e, nil
+	}
+}
+
+// mergeMaps returns a new map with the entries of both maps, where the entries of the second map
+// replace the entries of the first map with the same key.
+func mergeMaps(lhs, rhs ref.Val) ref.Val {
+	entries := map[ref.Val]ref.Val{}
+	keys := map[any]ref.Val{}
+	vals := map[any]ref.Val{}
+	hashable := false
+	for _, arg := range []ref.Val{lhs, rhs} {
+		m, isMap := arg.(traits.Mapper)
+		if !isMap {
+			return types.MaybeNoSuchOverloadErr(arg)
+		}
+		for it := m.Iterator(); it.HasNext() == types.True; {
+			key := it.Next()
+			// Keys such as bytes cannot be used as the keys of Go maps.
+			if _, isHashable := key.(traits.Hashable); isHashable {
+				hashable = true
+			} else {
+				entries[key] = m.Get(key)
+			}
+			keys[types.HashKey(key)] = key
+			vals[types.HashKey(key)] = m.Get(key)
+		}
+	}
+	if hashable {
+		return types.NewHashKeyMap(types.DefaultTypeAdapter, keys, vals)
+	}
+	return types.NewRefValMap(types.DefaultTypeAdapter, entries)
+}
@@ -0,0 +1,53 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"testing"
+)
+
+func TestSpreadSyntax(t *testing.T) {
+	env, err := NewEnv(
+		SpreadSyntax(),
+		Variable("defaults", MapType(StringType, IntType)),
+		Variable("items", ListType(IntType)),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	runEvalTests(t, env, []evalTestCase{
+		{expr: `[...items, 4, ...[5]] == [1, 2, 3, 4, 5]`, outType: BoolType, out: true},
+		{expr: `[...[], ...items].size() == 3`, outType: BoolType, out: true},
+		{expr: `{...defaults, 'b': 20, 'c': 3} == {'a': 1, 'b': 20, 'c': 3}`, outType: BoolType, out: true},
+		{expr: `{'a': 10, ...defaults} == {'a': 1, 'b': 2}`, outType: BoolType, out: true},
+		{expr: `{...defaults, ...{'a': 0}}['a'] == 0`, outType: BoolType, out: true},
+		{expr: `[...items]`, outType: ListType(IntType), out: []int64{1, 2, 3}},
+		{expr: `{...defaults}`, outType: MapType(StringType, IntType), out: map[string]int64{"a": 1, "b": 2}},
+		{expr: `[...defaults]`, err: "found no matching overload"},
+		{expr: `{...items}`, err: "found no matching overload"},
+		{expr: `{...defaults, 'k': 'v'}`, err: "found no matching overload"},
+	}, map[string]any{
+		"defaults": map[string]int64{"a": 1, "b": 2},
+		"items":    []int64{1, 2, 3},
+	})
+
+	stdEnv, err := NewEnv(Variable("items", ListType(IntType)))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	if _, iss := stdEnv.Compile(`[...items]`); iss.Err() == nil {
+		t.Error("env.Compile() succeeded without SpreadSyntax(), wanted error")
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/interpreter"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// CheckStandardEval returns an error if the evaluation of the checked Ast within the environment
+// depends on configuration of the environment which alters the semantics of the standard library,
+// such as an integer overflow policy other than interpreter.OverflowError, string limits, null-safe
+// or aggregate comparisons, error redaction, variable defaults, function bindings of the
+// environment, or names which the environment's container does not resolve as the Ast records.
+//
+// Tools which evaluate expressions outside of a cel.Program, such as code generators, use the check
+// to reject the configurations they cannot reproduce.
+func (e *Env) CheckStandardEval(ast *Ast) error {
+	if !ast.IsChecked() {
+		return errors.New("standard evaluation requires a checked ast")
+	}
+	if e.overflowPolicy != interpreter.OverflowError {
+		return errors.New("unsupported integer overflow policy")
+	}
+	if e.stringLimits != (interpreter.StringLimits{}) {
+		return errors.New("unsupported string limits")
+	}
+	if e.HasFeature(featureNullSafeComparisons) {
+		return errors.New("unsupported null-safe comparisons")
+	}
+	if e.HasFeature(featureAggregateComparisons) {
+		return errors.New("unsupported aggregate comparisons")
+	}
+	if len(e.sensitivePaths) != 0 {
+		return errors.New("unsupported sensitive paths")
+	}
+	for _, r := range ast.resolutions {
+		if !containsName(e.Container.ResolveCandidateNames(r.Name), r.Resolved) {
+			return fmt.Errorf("name '%s' resolved to '%s' outside of container '%s'",
+				r.Name, r.Resolved, e.Container.Name())
+		}
+	}
+	var err error
+	visitExpr(ast.Expr(), func(expr *exprpb.Expr) {
+		if err != nil {
+			return
+		}
+		switch expr.GetExprKind().(type) {
+		case *exprpb.Expr_IdentExpr, *exprpb.Expr_SelectExpr:
+			name := ast.refMap[expr.GetId()].GetName()
+			if _, found := e.variableDefaults[name]; found {
+				err = fmt.Errorf("unsupported default value of variable '%s'", name)
+			}
+		case *exprpb.Expr_CallExpr:
+			fn, found := e.functions[expr.GetCallExpr().GetFunction()]
+			if !found {
+				return
+			}
+			if fn.rebindable || fn.singleton != nil {
+				err = fmt.Errorf("unsupported binding of function '%s'", fn.name)
+				return
+			}
+			for _, id := range ast.refMap[expr.GetId()].GetOverloadId() {
+				for _, o := range fn.overloads {
+					if o.id == id && (o.hasBinding() || o.asyncOp != nil) {
+						err = fmt.Errorf("unsupported binding of overload '%s'", id)
+						return
+					}
+				}
+			}
+		}
+	})
+	return err
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
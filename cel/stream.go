@@ -0,0 +1,154 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/interpreter"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// StreamResults configures the program to deliver the elements of the list built by its top-level
+// comprehension, such as `items.filter(i, i.size > 10)` or `items.map(i, i.name)`, to the sink
+// given to StreamEval as each element is computed, rather than materializing the whole list. The
+// memory retained by a streaming evaluation is then independent of the size of the range.
+//
+// Creating the program fails when the expression is not a comprehension which builds a list by
+// appending to its accumulator. When the program is evaluated other than by StreamEval, the list
+// is built as usual.
+func StreamResults() ProgramOption {
+	return func(p *prog) (*prog, error) {
+		p.streamResults = true
+		return p, nil
+	}
+}
+
+// StreamEval evaluates the program with the input, which may be an interpreter.Activation or a
+// map[string]any, and delivers the elements of the resulting list to yield until yield returns
+// false or the list is exhausted.
+//
+// Programs configured with StreamResults deliver the elements as they are computed. Programs
+// which are not stream the elements of the list once it has been computed. Evaluations which do
+// not produce a list, or which are cancelled by the context, produce an error.
+func StreamEval(ctx context.Context, prg Program, input any, yield func(elem ref.Val) bool) (*EvalDetails, error) {
+	vars, err := interpreter.NewActivation(input)
+	if err != nil {
+		return nil, err
+	}
+	out, det, err := prg.ContextEval(ctx, interpreter.NewStreamActivation(vars, yield))
+	if err != nil {
+		return det, err
+	}
+	switch v := out.(type) {
+	case types.Int:
+		// The elements were delivered by the streaming comprehension.
+		return det, nil
+	case traits.Lister:
+		for it := v.Iterator(); it.HasNext() == types.True; {
+			if !yield(it.Next()) {
+				break
+			}
+		}
+		return det, nil
+	}
+	return det, fmt.Errorf("got '%s', expected list result", out.Type().TypeName())
+}
+
+// isStreamableComprehension returns whether the expression is a comprehension which builds a list
+// by appending elements to an initially empty accumulator, such that the elements appended by
+// each iteration may be delivered independently of the elements appended before them.
+func isStreamableComprehension(e *exprpb.Expr) bool {
+	comp := e.GetComprehensionExpr()
+	if comp == nil {
+		return false
+	}
+	accuVar := comp.GetAccuVar()
+	init := comp.GetAccuInit().GetListExpr()
+	if init == nil || len(init.GetElements()) != 0 ||
+		comp.GetResult().GetIdentExpr().GetName() != accuVar {
+		return false
+	}
+	return !referencesIdent(comp.GetLoopCondition(), accuVar) &&
+		isAppendStep(comp.GetLoopStep(), accuVar)
+}
+
+// isAppendStep returns whether the step of a comprehension evaluates to its accumulator, or to the
+// accumulator with elements appended, without otherwise referring to the accumulator.
+func isAppendStep(step *exprpb.Expr, accuVar string) bool {
+	if step.GetIdentExpr().GetName() == accuVar {
+		return true
+	}
+	call := step.GetCallExpr()
+	if call == nil || call.GetTarget() != nil {
+		return false
+	}
+	args := call.GetArgs()
+	switch call.GetFunction() {
+	case operators.Add:
+		return len(args) == 2 && args[0].GetIdentExpr().GetName() == accuVar &&
+			!referencesIdent(args[1], accuVar)
+	case operators.Conditional:
+		return len(args) == 3 && !referencesIdent(args[0], accuVar) &&
+			isAppendStep(args[1], accuVar) && isAppendStep(args[2], accuVar)
+	}
+	return false
+}
+
+// referencesIdent returns whether the expression refers to the identifier with the given name.
+func referencesIdent(e *exprpb.Expr, name string) bool {
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		return e.GetIdentExpr().GetName() == name
+	case *exprpb.Expr_SelectExpr:
+		return referencesIdent(e.GetSelectExpr().GetOperand(), name)
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		if call.GetTarget() != nil && referencesIdent(call.GetTarget(), name) {
+			return true
+		}
+		for _, arg := range call.GetArgs() {
+			if referencesIdent(arg, name) {
+				return true
+			}
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range e.GetListExpr().GetElements() {
+			if referencesIdent(elem, name) {
+				return true
+			}
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			if referencesIdent(entry.GetMapKey(), name) || referencesIdent(entry.GetValue(), name) {
+				return true
+			}
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		return referencesIdent(comp.GetIterRange(), name) ||
+			referencesIdent(comp.GetAccuInit(), name) ||
+			referencesIdent(comp.GetLoopCondition(), name) ||
+			referencesIdent(comp.GetLoopStep(), name) ||
+			referencesIdent(comp.GetResult(), name)
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+)
+
+func TestStreamResults(t *testing.T) {
+	env, err := NewEnv(Variable("items", ListType(IntType)))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	items := make([]int, 100)
+	for i := range items {
+		items[i] = i
+	}
+	tests := []struct {
+		expr  string
+		limit int
+		out   []int64
+	}{
+		{expr: `items.filter(i, i % 25 == 0)`, out: []int64{0, 25, 50, 75}},
+		{expr: `items.filter(i, i > 90).map(i, i * 2)`, out: []int64{182, 184, 186, 188, 190, 192, 194, 196, 198}},
+		{expr: `items.map(i, i < 3, i + 1)`, out: []int64{1, 2, 3}},
+		// The division by zero for the third element is never evaluated.
+		{expr: `items.map(i, 10 / (2 - i))`, limit: 2, out: []int64{5, 10}},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%v) failed: %v", tc.expr, iss.Err())
+			}
+			prg, err := env.Program(ast, StreamResults())
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			var out []int64
+			_, err = StreamEval(context.Background(), prg, map[string]any{"items": items}, func(elem ref.Val) bool {
+				out = append(out, int64(elem.(types.Int)))
+				return tc.limit == 0 || len(out) < tc.limit
+			})
+			if err != nil {
+				t.Fatalf("StreamEval() failed: %v", err)
+			}
+			if !reflect.DeepEqual(out, tc.out) {
+				t.Errorf("StreamEval() got %v, wanted %v", out, tc.out)
+			}
+			if tc.limit != 0 {
+				return
+			}
+			// Evaluations other than StreamEval build the list as usual.
+			val, _, err := prg.Eval(map[string]any{"items": items})
+			if err != nil {
+				t.Fatalf("prg.Eval() failed: %v", err)
+			}
+			if val.(traits.Lister).Size() != types.Int(len(tc.out)) {
+				t.Errorf("prg.Eval() got %v, wanted %v", val, tc.out)
+			}
+		})
+	}
+	ast, iss := env.Compile(`items.all(i, i >= 0)`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	if _, err := env.Program(ast, StreamResults()); err == nil {
+		t.Error("env.Program(StreamResults()) succeeded for a comprehension which does not build a list")
+	}
+}
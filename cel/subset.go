@@ -0,0 +1,172 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"errors"
+
+	"github.com/google/cel-go/checker"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/overloads"
+	"github.com/google/cel-go/interpreter/functions"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// StdLibProfile selects the standard library overloads made available by StdLibSubset, given the
+// name of the function and the id of the overload.
+type StdLibProfile func(function, overloadID string) bool
+
+var (
+	// ComparisonsOnly permits the logical operators, equality and ordering comparisons, membership
+	// tests with `in`, and indexing, so that expressions may only compare the values they are
+	// provided.
+	ComparisonsOnly StdLibProfile = func(function, overloadID string) bool {
+		switch function {
+		case operators.LogicalAnd, operators.LogicalOr, operators.LogicalNot, operators.Conditional,
+			operators.NotStrictlyFalse, operators.OldNotStrictlyFalse,
+			operators.Equals, operators.NotEquals,
+			operators.Less, operators.LessEquals, operators.Greater, operators.GreaterEquals,
+			operators.In, operators.OldIn, operators.Index:
+			return true
+		}
+		return false
+	}
+
+	// NoStringConstruction excludes the concatenation of strings and bytes, and the conversion of
+	// values to strings and bytes, so that expressions cannot construct new string or bytes values.
+	NoStringConstruction StdLibProfile = func(function, overloadID string) bool {
+		switch {
+		case overloadID == overloads.AddString, overloadID == overloads.AddBytes:
+			return false
+		case function == overloads.TypeConvertString, function == overloads.TypeConvertBytes:
+			return false
+		}
+		return true
+	}
+
+	// NoConversions excludes the type conversion functions, e.g. `int()` and `timestamp()`.
+	NoConversions StdLibProfile = func(function, overloadID string) bool {
+		switch function {
+		case overloads.TypeConvertInt, overloads.TypeConvertUint, overloads.TypeConvertDouble,
+			overloads.TypeConvertBool, overloads.TypeConvertBytes, overloads.TypeConvertString,
+			overloads.TypeConvertTimestamp, overloads.TypeConvertDuration, overloads.TypeConvertType,
+			overloads.TypeConvertDyn:
+			return false
+		}
+		return true
+	}
+)
+
+// StdLibSubset returns an EnvOption which configures the subset of the standard library permitted
+// by every one of the profiles, e.g. `StdLibSubset(ComparisonsOnly)`, along with the standard
+// macros and type identifiers.
+//
+// The subset must be configured within an environment created by NewCustomEnv, since NewEnv
+// configures the complete standard library. The subset takes the place of the standard library,
+// so a later StdLib option has no effect. Macros whose expansions call excluded functions fail to
+// type-check, e.g. `map()` expands into list concatenation, which ComparisonsOnly excludes.
+//
+// Overloads are excluded from type-checking individually. Since the runtime implementations of the
+// standard library are shared between the overloads of a function, a function is only excluded
+// from the runtime when all of its overloads are excluded, so expressions which are evaluated
+// without being type-checked may still call the excluded overloads of the included functions.
+func StdLibSubset(profiles ...StdLibProfile) EnvOption {
+	return func(e *Env) (*Env, error) {
+		if e.HasLibrary(stdLibrary{}.LibraryName()) {
+			return nil, errors.New("standard library subsets require an environment created by NewCustomEnv")
+		}
+		return Lib(stdLibrarySubset{profiles: profiles})(e)
+	}
+}
+
+// stdLibrarySubset implements the SingletonLibrary interface in place of the standard library.
+type stdLibrarySubset struct {
+	profiles []StdLibProfile
+}
+
+// LibraryName implements the SingletonLibrary interface method.
+func (stdLibrarySubset) LibraryName() string {
+	return stdLibrary{}.LibraryName()
+}
+
+// CompileOptions implements the Library interface method.
+func (lib stdLibrarySubset) CompileOptions() []EnvOption {
+	return []EnvOption{
+		Declarations(lib.declarations()...),
+		Macros(StandardMacros...),
+	}
+}
+
+// ProgramOptions implements the Library interface method.
+func (lib stdLibrarySubset) ProgramOptions() []ProgramOption {
+	permitted := map[string]bool{}
+	for _, d := range lib.declarations() {
+		if d.GetFunction() != nil {
+			permitted[d.GetName()] = true
+		}
+	}
+	var impls []*functions.Overload
+	for _, o := range functions.StandardOverloads() {
+		switch o.Operator {
+		case overloads.Iterator, overloads.HasNext, overloads.Next:
+			// Required for the evaluation of comprehensions.
+			impls = append(impls, o)
+		default:
+			if permitted[o.Operator] {
+				impls = append(impls, o)
+			}
+		}
+	}
+	return []ProgramOption{Functions(impls...)}
+}
+
+// declarations returns the standard declarations with the overloads which are not permitted by
+// the profiles removed.
+func (lib stdLibrarySubset) declarations() []*exprpb.Decl {
+	var decls []*exprpb.Decl
+	for _, d := range checker.StandardDeclarations() {
+		fn := d.GetFunction()
+		if fn == nil {
+			decls = append(decls, d)
+			continue
+		}
+		var permitted []*exprpb.Decl_FunctionDecl_Overload
+		for _, o := range fn.GetOverloads() {
+			if lib.permits(d.GetName(), o.GetOverloadId()) {
+				permitted = append(permitted, o)
+			}
+		}
+		if len(permitted) == 0 {
+			continue
+		}
+		decls = append(decls, &exprpb.Decl{
+			Name: d.GetName(),
+			DeclKind: &exprpb.Decl_Function{
+				Function: &exprpb.Decl_FunctionDecl{Overloads: permitted},
+			},
+		})
+	}
+	return decls
+}
+
+func (lib stdLibrarySubset) permits(function, overloadID string) bool {
+	for _, p := range lib.profiles {
+		if !p(function, overloadID) {
+			return false
+		}
+	}
+	return true
+}
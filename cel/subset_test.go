@@ -0,0 +1,51 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"testing"
+)
+
+func TestStdLibSubset(t *testing.T) {
+	tests := []struct {
+		profiles []StdLibProfile
+		expr     string
+		err      string
+	}{
+		{profiles: []StdLibProfile{ComparisonsOnly}, expr: `x > 1 && name in ['a', 'b'] && tags.all(t, t != '')`},
+		{profiles: []StdLibProfile{ComparisonsOnly}, expr: `x + 1 > 1`, err: "undeclared reference to '_+_'"},
+		{profiles: []StdLibProfile{ComparisonsOnly}, expr: `size(name) > 1`, err: "undeclared reference to 'size'"},
+		{profiles: []StdLibProfile{NoStringConstruction}, expr: `x + 1 > 1 && size(name + 'a') > 1`, err: "found no matching overload for '_+_' applied to '(string, string)'"},
+		{profiles: []StdLibProfile{NoStringConstruction}, expr: `string(x) == name`, err: "undeclared reference to 'string'"},
+		{profiles: []StdLibProfile{NoStringConstruction, NoConversions}, expr: `int(name) == x`, err: "undeclared reference to 'int'"},
+		{profiles: []StdLibProfile{NoConversions}, expr: `x + 1 > 1 && name + 'a' != ''`},
+	}
+	for _, tc := range tests {
+		env, err := NewCustomEnv(
+			StdLibSubset(tc.profiles...),
+			Variable("x", IntType),
+			Variable("name", StringType),
+			Variable("tags", ListType(StringType)),
+		)
+		if err != nil {
+			t.Fatalf("NewCustomEnv() failed: %v", err)
+		}
+		runEvalTests(t, env, []evalTestCase{{expr: tc.expr, out: true, err: tc.err}},
+			map[string]any{"x": 2, "name": "a", "tags": []string{"t"}})
+	}
+	if _, err := NewEnv(StdLibSubset(ComparisonsOnly)); err == nil {
+		t.Error("NewEnv(StdLibSubset()) succeeded, wanted error")
+	}
+}
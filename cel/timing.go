@@ -0,0 +1,145 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/cel-go/interpreter"
+	"github.com/google/cel-go/parser"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// CollectNodeTimings records the wall time spent evaluating each expression node within the
+// timings as the program is evaluated.
+//
+// Timing every node adds overhead to each evaluation, and so is intended for diagnosing the slow
+// subexpressions of an expression rather than for use in production.
+func CollectNodeTimings(timings *interpreter.NodeTimings) ProgramOption {
+	return func(p *prog) (*prog, error) {
+		p.nodeTimings = timings
+		return p, nil
+	}
+}
+
+// FormatNodeTimings renders the timings collected from the evaluation of the Ast as a table in the
+// manner of `pprof -top`, with one row per expression node ordered by cumulative time. The Ast
+// must have been parsed within the environment.
+//
+// Each row reports the number of evaluations of the node, the cumulative time spent evaluating
+// the node and its subexpressions along with its percentage of the time spent evaluating the
+// whole expression, the mean and sampled percentile durations, and the source location and text
+// of the node. At most limit rows are rendered when the limit is positive.
+func (e *Env) FormatNodeTimings(ast *Ast, timings *interpreter.NodeTimings, limit int) string {
+	// Macro calls are required to render the text of nodes which contain comprehensions.
+	info, err := e.macroTrackedSourceInfo(ast)
+	if err != nil {
+		info = ast.SourceInfo()
+	}
+	exprs := map[int64]*exprpb.Expr{}
+	indexExprs(ast.Expr(), exprs)
+	type row struct {
+		id     int64
+		timing interpreter.NodeTiming
+	}
+	var rows []row
+	for _, id := range timings.IDs() {
+		if t, found := timings.Node(id); found {
+			rows = append(rows, row{id: id, timing: t})
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].timing.Total > rows[j].timing.Total
+	})
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+	var root float64
+	if t, found := timings.Node(ast.Expr().GetId()); found {
+		root = float64(t.Total)
+	}
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "count\tcum\tcum%\tmean\tp50\tp90\tp99\tmax\t  location  expression")
+	for _, r := range rows {
+		t := r.timing
+		pct := 0.0
+		if root > 0 {
+			pct = 100 * float64(t.Total) / root
+		}
+		text := ""
+		if expr, found := exprs[r.id]; found {
+			if src, err := parser.Unparse(expr, info); err == nil {
+				text = src
+			}
+		}
+		fmt.Fprintf(w, "%d\t%v\t%.1f%%\t%v\t%v\t%v\t%v\t%v\t  %s  %s\n",
+			t.Count, t.Total, pct, t.Mean(), t.Quantile(0.5), t.Quantile(0.9), t.Quantile(0.99), t.Max,
+			nodeLocation(ast, r.id), text)
+	}
+	w.Flush()
+	return sb.String()
+}
+
+// nodeLocation returns the `line:column` location of the expression id within the Ast source.
+func nodeLocation(ast *Ast, id int64) string {
+	offset, found := ast.SourceInfo().GetPositions()[id]
+	if !found || ast.Source() == nil {
+		return fmt.Sprintf("#%d", id)
+	}
+	loc, found := ast.Source().OffsetLocation(offset)
+	if !found {
+		return fmt.Sprintf("#%d", id)
+	}
+	return fmt.Sprintf("%d:%d", loc.Line(), loc.Column()+1)
+}
+
+// indexExprs records the subexpressions of the expression by id.
+func indexExprs(e *exprpb.Expr, exprs map[int64]*exprpb.Expr) {
+	if e == nil {
+		return
+	}
+	exprs[e.GetId()] = e
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		indexExprs(e.GetSelectExpr().GetOperand(), exprs)
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		indexExprs(call.GetTarget(), exprs)
+		for _, arg := range call.GetArgs() {
+			indexExprs(arg, exprs)
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range e.GetListExpr().GetElements() {
+			indexExprs(elem, exprs)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			indexExprs(entry.GetMapKey(), exprs)
+			indexExprs(entry.GetValue(), exprs)
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		indexExprs(comp.GetIterRange(), exprs)
+		indexExprs(comp.GetAccuInit(), exprs)
+		indexExprs(comp.GetLoopCondition(), exprs)
+		indexExprs(comp.GetLoopStep(), exprs)
+		indexExprs(comp.GetResult(), exprs)
+	}
+}
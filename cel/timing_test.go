@@ -0,0 +1,57 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/interpreter"
+)
+
+func TestCollectNodeTimings(t *testing.T) {
+	env, err := NewEnv(Variable("names", ListType(StringType)))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`size(names) > 1 &&
+names.all(n, n.startsWith('a'))`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	timings := interpreter.NewNodeTimings(16)
+	prg, err := env.Program(ast, CollectNodeTimings(timings), EvalOptions(OptTrackState))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if out, _, err := prg.Eval(map[string]any{"names": []string{"ann", "adam"}}); err != nil || out != types.True {
+			t.Fatalf("prg.Eval() got %v, %v, wanted true", out, err)
+		}
+	}
+	report := env.FormatNodeTimings(ast, timings, 3)
+	lines := strings.Split(strings.TrimSpace(report), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("FormatNodeTimings() got %d lines, wanted a header and 3 rows:\n%s", len(lines), report)
+	}
+	// The root expression accounts for all of the evaluation time and sorts first.
+	if !strings.Contains(lines[1], "100.0%") || !strings.Contains(lines[1], "1:17  size(names) > 1 &&") {
+		t.Errorf("FormatNodeTimings() got root row %q", lines[1])
+	}
+	if !strings.HasPrefix(strings.TrimSpace(lines[1]), "3 ") {
+		t.Errorf("FormatNodeTimings() got root row %q, wanted 3 evaluations", lines[1])
+	}
+}
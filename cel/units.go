@@ -0,0 +1,202 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/overloads"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Unit annotates an int, uint, or double variable with the unit of the quantity it holds, e.g.
+// `Variable("timeout", IntType, Unit("seconds"))`.
+//
+// Expressions which combine quantities of different units are rejected by Env.Check, e.g. adding
+// or comparing a quantity of seconds with one of milliseconds. Quantities must instead be
+// converted explicitly with the functions declared by UnitConversion.
+//
+// The unit of an expression follows from the units of its operands:
+//
+//   - Addition, subtraction, modulo, negation, and the int, uint, and double conversions preserve
+//     the unit of their operands, which must agree.
+//   - Multiplication and division preserve the unit of their operand when the other operand has no
+//     unit, e.g. `timeout * 1000` remains a quantity of seconds. The product or quotient of two
+//     quantities has no unit.
+//   - The branches of a conditional must agree, and the conditional has their unit.
+//   - Comparisons require the units of their operands to agree.
+//
+// Literals and the results of other functions have no unit, and are compatible with any unit.
+func Unit(unit string) VariableOpt {
+	return func(v *variableDecl) (*variableDecl, error) {
+		if unit == "" {
+			return nil, fmt.Errorf("unit must not be empty")
+		}
+		switch v.t.kind {
+		case IntKind, UintKind, DoubleKind:
+		default:
+			return nil, fmt.Errorf("unit %s requires a variable of type int, uint, or double, got %s", unit, v.t)
+		}
+		v.unit = unit
+		return v, nil
+	}
+}
+
+// UnitConversion declares a function which converts int and double quantities of one unit into
+// another by multiplying them by the ratio numerator / denominator, e.g.
+// `UnitConversion("toMillis", "seconds", "milliseconds", 1000, 1)`.
+//
+// The conversion of an int truncates the result of the division toward zero, and produces an error
+// when the multiplication overflows. The argument of the function must be a quantity of the from
+// unit, or have no unit, and the result is a quantity of the to unit.
+func UnitConversion(function, from, to string, numerator, denominator int64) EnvOption {
+	return func(e *Env) (*Env, error) {
+		if from == "" || to == "" {
+			return nil, fmt.Errorf("unit conversion %s: units must not be empty", function)
+		}
+		if numerator <= 0 || denominator <= 0 {
+			return nil, fmt.Errorf("unit conversion %s: ratio %d/%d must be positive",
+				function, numerator, denominator)
+		}
+		e, err := Function(function,
+			Overload(fmt.Sprintf("%s_int", function), []*Type{IntType}, IntType,
+				UnaryBinding(func(arg ref.Val) ref.Val {
+					n, ok := arg.(types.Int)
+					if !ok {
+						return types.MaybeNoSuchOverloadErr(arg)
+					}
+					scaled := n.Multiply(types.Int(numerator))
+					if types.IsError(scaled) {
+						return scaled
+					}
+					return scaled.(types.Int) / types.Int(denominator)
+				}), OverloadIsPure()),
+			Overload(fmt.Sprintf("%s_double", function), []*Type{DoubleType}, DoubleType,
+				UnaryBinding(func(arg ref.Val) ref.Val {
+					d, ok := arg.(types.Double)
+					if !ok {
+						return types.MaybeNoSuchOverloadErr(arg)
+					}
+					return d * types.Double(numerator) / types.Double(denominator)
+				}), OverloadIsPure()),
+		)(e)
+		if err != nil {
+			return nil, err
+		}
+		e.unitConversions[function] = unitConversion{from: from, to: to}
+		return e, nil
+	}
+}
+
+// unitConversion records the units of a function declared with UnitConversion.
+type unitConversion struct {
+	from string
+	to   string
+}
+
+// checkUnits reports an error for each expression which combines quantities of different units.
+func (e *Env) checkUnits(ast *Ast) *common.Errors {
+	errs := common.NewErrors(ast.Source())
+	if len(e.variableUnits) == 0 {
+		return errs
+	}
+	c := &unitChecker{env: e, ast: ast, errs: errs}
+	c.unitOf(ast.Expr())
+	return errs
+}
+
+type unitChecker struct {
+	env  *Env
+	ast  *Ast
+	errs *common.Errors
+}
+
+// unitOf returns the unit of the expression, or the empty string if the expression has no unit,
+// reporting the expressions within it which combine incompatible units.
+func (c *unitChecker) unitOf(e *exprpb.Expr) string {
+	if ref, found := c.ast.refMap[e.GetId()]; found && len(ref.GetOverloadId()) == 0 {
+		// A reference to a variable, possibly by a qualified name.
+		return c.env.variableUnits[ref.GetName()]
+	}
+	call := e.GetCallExpr()
+	if call == nil {
+		for _, child := range exprChildren(e) {
+			c.unitOf(child)
+		}
+		return ""
+	}
+	args := call.GetArgs()
+	units := make([]string, len(args))
+	c.unitOf(call.GetTarget())
+	for i, arg := range args {
+		units[i] = c.unitOf(arg)
+	}
+	fn := call.GetFunction()
+	switch fn {
+	case operators.Add, operators.Subtract, operators.Modulo,
+		operators.Equals, operators.NotEquals,
+		operators.Less, operators.LessEquals, operators.Greater, operators.GreaterEquals:
+		unit := c.agree(e, fn, units[0], units[1])
+		switch fn {
+		case operators.Add, operators.Subtract, operators.Modulo:
+			return unit
+		}
+		return ""
+	case operators.Multiply, operators.Divide:
+		if units[0] != "" && units[1] != "" {
+			return ""
+		}
+		return units[0] + units[1]
+	case operators.Negate:
+		return units[0]
+	case operators.Conditional:
+		return c.agree(e, fn, units[1], units[2])
+	case overloads.TypeConvertInt, overloads.TypeConvertUint, overloads.TypeConvertDouble:
+		if len(units) == 1 {
+			return units[0]
+		}
+		return ""
+	}
+	if conv, found := c.env.unitConversions[fn]; found && len(units) == 1 && call.GetTarget() == nil {
+		if units[0] != "" && units[0] != conv.from {
+			c.errs.ReportError(exprLocation(c.ast, e.GetId()),
+				"unit conversion '%s' expects a quantity of %s, got %s", fn, conv.from, units[0])
+		}
+		return conv.to
+	}
+	return ""
+}
+
+// agree reports an error if the units differ, and returns the unit they share.
+func (c *unitChecker) agree(e *exprpb.Expr, fn, left, right string) string {
+	if left == "" {
+		return right
+	}
+	if right == "" || left == right {
+		return left
+	}
+	op, _ := operators.FindReverse(fn)
+	if fn == operators.Conditional {
+		op = "?:"
+	}
+	c.errs.ReportError(exprLocation(c.ast, e.GetId()),
+		"incompatible units in '%s': %s and %s", op, left, right)
+	return ""
+}
@@ -0,0 +1,51 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"testing"
+)
+
+func TestUnits(t *testing.T) {
+	env, err := NewEnv(
+		Variable("timeout", IntType, Unit("seconds")),
+		Variable("elapsed", IntType, Unit("milliseconds")),
+		Variable("limit", DoubleType, Unit("bytes")),
+		Variable("count", IntType),
+		UnitConversion("toMillis", "seconds", "milliseconds", 1000, 1),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	runEvalTests(t, env, []evalTestCase{
+		{expr: `toMillis(timeout) > elapsed`, out: true},
+		{expr: `toMillis(timeout + 1) - elapsed`, out: 1500},
+		{expr: `elapsed < 1000 && timeout * 2 >= 4`, out: false},
+		{expr: `(count > 0 ? timeout : 1) == 2`, out: true},
+		{expr: `elapsed / count > 100`, out: true},
+		{expr: `double(timeout) * limit > 0.0`, out: true},
+		{expr: `timeout > elapsed`, err: "incompatible units in '>': seconds and milliseconds"},
+		{expr: `elapsed + timeout * 1000 < 10`, err: "incompatible units in '+': milliseconds and seconds"},
+		{expr: `(count > 0 ? timeout : elapsed) == 1`, err: "incompatible units in '?:': seconds and milliseconds"},
+		{expr: `toMillis(elapsed) > 0`, err: "unit conversion 'toMillis' expects a quantity of seconds, got milliseconds"},
+		{expr: `-int(timeout) != elapsed`, err: "incompatible units in '!=': seconds and milliseconds"},
+	}, map[string]any{"timeout": 2, "elapsed": 1500, "limit": 1.5, "count": 3})
+	if _, err := NewEnv(Variable("name", StringType, Unit("bytes"))); err == nil {
+		t.Error("NewEnv() with a unit on a string variable succeeded, wanted error")
+	}
+	if _, err := NewEnv(UnitConversion("f", "a", "b", 0, 1)); err == nil {
+		t.Error("NewEnv() with a zero conversion ratio succeeded, wanted error")
+	}
+}
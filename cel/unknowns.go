@@ -0,0 +1,299 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// AttributeTrail identifies an attribute by its fully qualified variable name and a sequence of
+// constant qualifiers, each of which is a string, int64, uint64, or bool.
+type AttributeTrail struct {
+	Variable   string
+	Qualifiers []any
+}
+
+// String renders the attribute trail in the syntax accepted by ParseAttributePattern, e.g.
+// `request.headers['x-token'][0]`.
+func (t AttributeTrail) String() string {
+	var sb strings.Builder
+	sb.WriteString(t.Variable)
+	for _, q := range t.Qualifiers {
+		switch v := q.(type) {
+		case string:
+			if isIdentifier(v) {
+				sb.WriteString("." + v)
+			} else {
+				sb.WriteString("[" + strconv.Quote(v) + "]")
+			}
+		case uint64:
+			fmt.Fprintf(&sb, "[%du]", v)
+		default:
+			fmt.Fprintf(&sb, "[%v]", v)
+		}
+	}
+	return sb.String()
+}
+
+// overlaps returns whether one trail is equal to, or a prefix of, the other.
+func (t AttributeTrail) overlaps(other AttributeTrail) bool {
+	if t.Variable != other.Variable {
+		return false
+	}
+	for i := 0; i < len(t.Qualifiers) && i < len(other.Qualifiers); i++ {
+		if t.Qualifiers[i] != other.Qualifiers[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// pattern returns the AttributePattern which matches the trail.
+func (t AttributeTrail) pattern() (*interpreter.AttributePattern, error) {
+	pat := AttributePattern(t.Variable)
+	for _, q := range t.Qualifiers {
+		switch v := q.(type) {
+		case string:
+			pat.QualString(v)
+		case int64:
+			pat.QualInt(v)
+		case uint64:
+			pat.QualUint(v)
+		case bool:
+			pat.QualBool(v)
+		default:
+			return nil, fmt.Errorf("unsupported qualifier type %T in attribute %s", q, t.Variable)
+		}
+	}
+	return pat, nil
+}
+
+// AttributeFetcher returns the values of the attributes whose values were unknown during
+// evaluation, in the same order as the input trails.
+type AttributeFetcher func(ctx context.Context, trails []AttributeTrail) ([]any, error)
+
+// ResolveUnknowns evaluates a program created with OptPartialEval until it produces a value which
+// does not depend on unknown attributes.
+//
+// The `unknowns` are the attributes which are initially unknown within `vars`. After each
+// evaluation which produces an unknown value, the unknown attributes which the result depends on
+// are provided to the fetcher, the fetched values are merged into the variables, and the program
+// is evaluated again. Values fetched for qualified attributes are merged into nested
+// `map[string]any` values of the variables, so such attributes must be qualified by strings.
+//
+// The input `vars` are not modified. When unknowns remain after `maxIterations` evaluations, the
+// unknown result is returned along with an error.
+func ResolveUnknowns(ctx context.Context, prg Program, ast *Ast, vars map[string]any,
+	unknowns []AttributeTrail, fetch AttributeFetcher, maxIterations int) (ref.Val, *EvalDetails, error) {
+	r := &unknownResolver{
+		exprs:    map[int64]*exprpb.Expr{},
+		refMap:   ast.refMap,
+		vars:     make(map[string]any, len(vars)),
+		unknowns: unknowns,
+	}
+	visitExpr(ast.Expr(), func(e *exprpb.Expr) {
+		r.exprs[e.GetId()] = e
+	})
+	for name, val := range vars {
+		r.vars[name] = val
+	}
+	for i := 0; ; i++ {
+		patterns := make([]*interpreter.AttributePattern, len(r.unknowns))
+		for j, unk := range r.unknowns {
+			pat, err := unk.pattern()
+			if err != nil {
+				return nil, nil, err
+			}
+			patterns[j] = pat
+		}
+		input, err := PartialVars(r.vars, patterns...)
+		if err != nil {
+			return nil, nil, err
+		}
+		out, det, err := prg.ContextEval(ctx, input)
+		unk, isUnk := out.(types.Unknown)
+		if err != nil || !isUnk {
+			return out, det, err
+		}
+		if i+1 >= maxIterations {
+			return out, det, fmt.Errorf("unknown attributes remain after %d evaluations", maxIterations)
+		}
+		fetched := r.dependencies(unk)
+		if len(fetched) == 0 {
+			return out, det, errors.New("unknown result does not depend on any unknown attribute")
+		}
+		vals, err := fetch(ctx, fetched)
+		if err != nil {
+			return out, det, err
+		}
+		if len(vals) != len(fetched) {
+			return out, det, fmt.Errorf("fetcher returned %d values for %d attributes", len(vals), len(fetched))
+		}
+		for j, trail := range fetched {
+			if err := r.merge(trail, vals[j]); err != nil {
+				return out, det, err
+			}
+		}
+	}
+}
+
+type unknownResolver struct {
+	exprs    map[int64]*exprpb.Expr
+	refMap   map[int64]*exprpb.Reference
+	vars     map[string]any
+	unknowns []AttributeTrail
+}
+
+// dependencies removes and returns the unknown attributes on which the unknown value depends.
+//
+// When the attribute of an unknown expression id cannot be determined, all unknown attributes
+// are considered dependencies.
+func (r *unknownResolver) dependencies(unk types.Unknown) []AttributeTrail {
+	deps := map[int]bool{}
+	for _, id := range unk {
+		trail, found := r.trail(r.exprs[id])
+		for i, u := range r.unknowns {
+			if !found || u.overlaps(trail) {
+				deps[i] = true
+			}
+		}
+	}
+	var fetched, remaining []AttributeTrail
+	for i, u := range r.unknowns {
+		if deps[i] {
+			fetched = append(fetched, u)
+		} else {
+			remaining = append(remaining, u)
+		}
+	}
+	r.unknowns = remaining
+	return fetched
+}
+
+// trail returns the attribute trail of an identifier, or of a chain of field selections and
+// constant indices rooted at an identifier.
+func (r *unknownResolver) trail(e *exprpb.Expr) (AttributeTrail, bool) {
+	if e == nil {
+		return AttributeTrail{}, false
+	}
+	if ref, found := r.refMap[e.GetId()]; found && ref.GetName() != "" && ref.GetValue() == nil {
+		return AttributeTrail{Variable: ref.GetName()}, true
+	}
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		return AttributeTrail{Variable: e.GetIdentExpr().GetName()}, true
+	case *exprpb.Expr_SelectExpr:
+		sel := e.GetSelectExpr()
+		return r.qualify(sel.GetOperand(), sel.GetField())
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		if call.GetFunction() != operators.Index || len(call.GetArgs()) != 2 {
+			return AttributeTrail{}, false
+		}
+		var qual any
+		switch c := call.GetArgs()[1].GetConstExpr().GetConstantKind().(type) {
+		case *exprpb.Constant_StringValue:
+			qual = c.StringValue
+		case *exprpb.Constant_Int64Value:
+			qual = c.Int64Value
+		case *exprpb.Constant_Uint64Value:
+			qual = c.Uint64Value
+		case *exprpb.Constant_BoolValue:
+			qual = c.BoolValue
+		default:
+			return AttributeTrail{}, false
+		}
+		return r.qualify(call.GetArgs()[0], qual)
+	}
+	return AttributeTrail{}, false
+}
+
+func (r *unknownResolver) qualify(operand *exprpb.Expr, qual any) (AttributeTrail, bool) {
+	trail, found := r.trail(operand)
+	if !found {
+		return AttributeTrail{}, false
+	}
+	quals := make([]any, len(trail.Qualifiers), len(trail.Qualifiers)+1)
+	copy(quals, trail.Qualifiers)
+	return AttributeTrail{Variable: trail.Variable, Qualifiers: append(quals, qual)}, true
+}
+
+// merge sets the value of the attribute within the variables, copying the nested maps along the
+// attribute path rather than modifying them.
+func (r *unknownResolver) merge(trail AttributeTrail, val any) error {
+	if len(trail.Qualifiers) == 0 {
+		r.vars[trail.Variable] = val
+		return nil
+	}
+	root, err := r.copyMap(r.vars[trail.Variable], trail)
+	if err != nil {
+		return err
+	}
+	r.vars[trail.Variable] = root
+	m := root
+	for i, q := range trail.Qualifiers {
+		key, isStr := q.(string)
+		if !isStr {
+			return fmt.Errorf("cannot merge attribute %s: qualifier %v is not a string", trail, q)
+		}
+		if i == len(trail.Qualifiers)-1 {
+			m[key] = val
+			break
+		}
+		next, err := r.copyMap(m[key], trail)
+		if err != nil {
+			return err
+		}
+		m[key] = next
+		m = next
+	}
+	return nil
+}
+
+func (r *unknownResolver) copyMap(val any, trail AttributeTrail) (map[string]any, error) {
+	if val == nil {
+		return map[string]any{}, nil
+	}
+	m, isMap := val.(map[string]any)
+	if !isMap {
+		return nil, fmt.Errorf("cannot merge attribute %s into value of type %T", trail, val)
+	}
+	cp := make(map[string]any, len(m)+1)
+	for k, v := range m {
+		cp[k] = v
+	}
+	return cp, nil
+}
+
+// isIdentifier returns whether the string may be used as a field selection.
+func isIdentifier(s string) bool {
+	for i, c := range s {
+		if c != '_' && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && !(i > 0 && '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return s != ""
+}
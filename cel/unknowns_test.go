@@ -0,0 +1,86 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+)
+
+func TestResolveUnknowns(t *testing.T) {
+	env, err := NewEnv(
+		Variable("request", MapType(StringType, DynType)),
+		Variable("quota", IntType),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`request.auth.claims.admin || (request.size < quota && request['x-id'] != '')`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast, EvalOptions(OptPartialEval))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	vars := map[string]any{
+		"request": map[string]any{"size": 10, "x-id": "abc"},
+	}
+	unknowns := []AttributeTrail{
+		{Variable: "request", Qualifiers: []any{"auth", "claims"}},
+		{Variable: "quota"},
+		{Variable: "request", Qualifiers: []any{"unused"}},
+	}
+	var fetches [][]string
+	values := map[string]any{
+		"request.auth.claims": map[string]any{"admin": false},
+		"quota":               100,
+	}
+	fetch := func(ctx context.Context, trails []AttributeTrail) ([]any, error) {
+		var names []string
+		var vals []any
+		for _, trail := range trails {
+			names = append(names, trail.String())
+			vals = append(vals, values[trail.String()])
+		}
+		fetches = append(fetches, names)
+		return vals, nil
+	}
+	out, _, err := ResolveUnknowns(context.Background(), prg, ast, vars, unknowns, fetch, 3)
+	if err != nil || out != types.True {
+		t.Fatalf("ResolveUnknowns() got %v, %v, wanted true", out, err)
+	}
+	if want := [][]string{{"request.auth.claims"}, {"quota"}}; !reflect.DeepEqual(fetches, want) {
+		t.Errorf("ResolveUnknowns() fetched %v, wanted %v", fetches, want)
+	}
+	if _, found := vars["request"].(map[string]any)["auth"]; found {
+		t.Error("ResolveUnknowns() modified the input variables")
+	}
+
+	// Evaluation stops at the iteration cap.
+	_, _, err = ResolveUnknowns(context.Background(), prg, ast, vars, unknowns, fetch, 1)
+	if err == nil || !strings.Contains(err.Error(), "unknown attributes remain") {
+		t.Errorf("ResolveUnknowns() with one iteration got %v, wanted unknown attributes error", err)
+	}
+
+	trail := AttributeTrail{Variable: "a.b", Qualifiers: []any{"c", "x-y", int64(-1), uint64(2), true}}
+	if got, want := trail.String(), `a.b.c["x-y"][-1][2u][true]`; got != want {
+		t.Errorf("trail.String() got %s, wanted %s", got, want)
+	}
+}
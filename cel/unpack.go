@@ -0,0 +1,91 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"github.com/google/cel-go/checker"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// Unpacking enables the inspection of dynamic values, such as the contents of a google.protobuf.Any
+// field, according to their type at runtime without unpacking them on the host.
+//
+// The `unpack` function returns an optional value of the given type, which has a value when the
+// dynamic value is of that type, e.g.
+//
+//	event.payload.unpack(google.rpc.context.AttributeContext.Request)
+//	  .optMap(r, r.method == 'GET').orValue(false)
+//
+// The option also enables the type-checker to narrow the type of a dynamic variable or field which
+// is tested against a type, within the branch of a conditional or the right-hand side of a logical
+// and which is only evaluated when the test succeeds, e.g.
+//
+//	type(event.payload) == google.rpc.context.AttributeContext.Request &&
+//	  event.payload.method == 'GET'
+//
+// where the field selection `method` is checked against the declaration of the message.
+//
+// Values of the protobuf wrapper types are unpacked to their primitive types, and so are unpacked
+// with types such as `int` and `string` rather than the names of the wrapper types.
+//
+// The option implies OptionalTypes.
+func Unpacking() EnvOption {
+	return Lib(unpackLibrary{})
+}
+
+type unpackLibrary struct{}
+
+// LibraryName implements the SingletonLibrary interface method.
+func (unpackLibrary) LibraryName() string {
+	return "cel.lib.unpack"
+}
+
+// CompileOptions implements the Library interface method.
+func (unpackLibrary) CompileOptions() []EnvOption {
+	paramTypeT := TypeParamType("T")
+	return []EnvOption{
+		OptionalTypes(),
+		enableTypeNarrowing(),
+		Function("unpack",
+			MemberOverload("dyn_unpack_type", []*Type{DynType, TypeTypeWithParam(paramTypeT)},
+				OptionalType(paramTypeT),
+				BinaryBinding(unpack))),
+	}
+}
+
+// ProgramOptions implements the Library interface method.
+func (unpackLibrary) ProgramOptions() []ProgramOption {
+	return []ProgramOption{}
+}
+
+func enableTypeNarrowing() EnvOption {
+	return func(e *Env) (*Env, error) {
+		e.chkOpts = append(e.chkOpts, checker.TypeNarrowing(true))
+		return e, nil
+	}
+}
+
+// unpack returns an optional with the value when its runtime type is the given type.
+func unpack(value, typ ref.Val) ref.Val {
+	t, isType := typ.(ref.Type)
+	if !isType {
+		return types.MaybeNoSuchOverloadErr(typ)
+	}
+	if value.Type().TypeName() == t.TypeName() {
+		return types.OptionalOf(value)
+	}
+	return types.OptionalNone
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+
+	anypb "google.golang.org/protobuf/types/known/anypb"
+
+	proto3pb "github.com/google/cel-go/test/proto3pb"
+)
+
+func TestUnpacking(t *testing.T) {
+	env, err := NewEnv(
+		Container("google.expr.proto3.test"),
+		Types(&proto3pb.TestAllTypes{}),
+		Variable("msg", ObjectType("google.expr.proto3.test.TestAllTypes")),
+		Variable("x", DynType),
+		Unpacking(),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	nested, err := anypb.New(&proto3pb.NestedTestAllTypes{Payload: &proto3pb.TestAllTypes{SingleInt64: 7}})
+	if err != nil {
+		t.Fatalf("anypb.New() failed: %v", err)
+	}
+	vars := map[string]any{
+		"msg": &proto3pb.TestAllTypes{SingleAny: nested},
+		"x":   int64(3),
+	}
+	runEvalTests(t, env, []evalTestCase{
+		{
+			expr:    `msg.single_any.unpack(NestedTestAllTypes).optMap(n, n.payload.single_int64)`,
+			outType: OptionalType(IntType),
+			out:     types.OptionalOf(types.Int(7)),
+		},
+		{
+			expr:    `msg.single_any.unpack(TestAllTypes).hasValue()`,
+			outType: BoolType,
+			out:     false,
+		},
+		{
+			expr:    `x.unpack(int).orValue(0) + 1`,
+			outType: IntType,
+			out:     4,
+		},
+		{
+			// The type test narrows the type of the field within the right-hand side.
+			expr:    `type(msg.single_any) == NestedTestAllTypes && msg.single_any.payload.single_int64 == 7`,
+			outType: BoolType,
+			out:     true,
+		},
+		{
+			expr:    `type(msg.single_any) == TestAllTypes ? msg.single_any.single_int64 : -1`,
+			outType: IntType,
+			out:     -1,
+		},
+		{
+			expr:    `type(x) == int ? x + 1 : 0`,
+			outType: IntType,
+			out:     4,
+		},
+		{
+			// Narrowed fields are checked against the declaration of the message.
+			expr: `type(x) == TestAllTypes && x.no_such_field == 1`,
+			err:  "undefined field 'no_such_field'",
+		},
+	}, vars)
+
+	// Comprehension variables shadow the narrowed variables.
+	_, iss := env.Compile(`type(x) == TestAllTypes && [{'a': 1}].exists(x, x.a == 1)`)
+	if iss.Err() != nil {
+		t.Errorf("env.Compile() failed: %v", iss.Err())
+	}
+}
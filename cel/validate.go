@@ -0,0 +1,243 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"github.com/google/cel-go/common"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Validate verifies that a checked Ast, possibly checked within another environment, remains valid
+// within this environment without checking it again. This makes it possible to detect whether
+// expressions compiled by an earlier release are safe to evaluate after a change to the
+// environment, such as during a rolling upgrade.
+//
+// The Ast remains valid when the declarations and protobuf fields it references still exist, and
+// the types recorded for the Ast are compatible with the declarations:
+//
+//   - Variables must be declared with a type assignable to the recorded type.
+//   - Function overloads must be declared under the same id with the same number of arguments,
+//     accepting the recorded argument types, and returning a type assignable to the recorded type.
+//   - Fields must exist with a type assignable to the recorded type.
+//
+// Every identifier and function call must have a reference, since the references determine how the
+// Ast is evaluated.
+//
+// Dynamically typed declarations are compatible with any recorded type. Validation has failed if
+// the returned Issues value and its Issues.Err() value are non-nil.
+func (e *Env) Validate(ast *Ast) *Issues {
+	errs := common.NewErrors(ast.Source())
+	if !ast.IsChecked() {
+		errs.ReportError(common.NoLocation, "ast is not type-checked")
+		return NewIssues(errs)
+	}
+	if err := e.initChecker(); err != nil {
+		errs.ReportError(common.NoLocation, err.Error())
+		return NewIssues(errs)
+	}
+	v := &astValidator{env: e, ast: ast, errs: errs}
+	WalkExpr(ast.Expr(), v.visit, v.validate)
+	if len(errs.GetErrors()) == 0 {
+		return nil
+	}
+	return NewIssues(errs)
+}
+
+type astValidator struct {
+	env  *Env
+	ast  *Ast
+	errs *common.Errors
+}
+
+// visit skips the operands of qualified identifiers.
+func (v *astValidator) visit(e *exprpb.Expr, _ *ExprScope) bool {
+	_, found := v.ast.refMap[e.GetId()]
+	return e.GetSelectExpr() == nil || !found
+}
+
+// validate checks the expression after its subexpressions have been checked.
+func (v *astValidator) validate(e *exprpb.Expr, scope *ExprScope) {
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		if !scope.IsLocal(e.GetIdentExpr().GetName()) {
+			v.validateIdent(e)
+		}
+	case *exprpb.Expr_SelectExpr:
+		if _, found := v.ast.refMap[e.GetId()]; found {
+			// A qualified identifier.
+			v.validateIdent(e)
+			return
+		}
+		v.validateSelect(e)
+	case *exprpb.Expr_CallExpr:
+		v.validateCall(e)
+	case *exprpb.Expr_StructExpr:
+		v.validateStruct(e)
+	}
+}
+
+// validateIdent verifies that a referenced variable, enum constant, or type name still exists.
+func (v *astValidator) validateIdent(e *exprpb.Expr) {
+	ref, found := v.ast.refMap[e.GetId()]
+	if !found {
+		v.report(e, "missing reference for '%s'", e.GetIdentExpr().GetName())
+		return
+	}
+	name := ref.GetName()
+	if ref.GetValue() != nil {
+		if _, found := v.env.provider.FindIdent(name); !found {
+			v.report(e, "undeclared reference to '%s'", name)
+		}
+		return
+	}
+	if decl := v.env.chk.LookupIdent(name); decl != nil {
+		if !v.compatible(v.ast.typeMap[e.GetId()], decl.GetIdent().GetType()) {
+			v.report(e, "incompatible type for '%s': declared as '%s', checked as '%s'",
+				name, formatCheckedType(decl.GetIdent().GetType()), formatCheckedType(v.ast.typeMap[e.GetId()]))
+		}
+		return
+	}
+	if _, found := v.env.provider.FindType(name); !found {
+		v.report(e, "undeclared reference to '%s'", name)
+	}
+}
+
+// validateCall verifies that the overloads selected for the call are still declared.
+func (v *astValidator) validateCall(e *exprpb.Expr) {
+	call := e.GetCallExpr()
+	ref, found := v.ast.refMap[e.GetId()]
+	if !found || len(ref.GetOverloadId()) == 0 {
+		v.report(e, "missing overload reference for '%s'", call.GetFunction())
+		return
+	}
+	fn := v.env.chk.LookupFunction(call.GetFunction())
+	if fn == nil {
+		v.report(e, "undeclared reference to '%s'", call.GetFunction())
+		return
+	}
+	overloads := map[string]*exprpb.Decl_FunctionDecl_Overload{}
+	for _, o := range fn.GetFunction().GetOverloads() {
+		overloads[o.GetOverloadId()] = o
+	}
+	args := call.GetArgs()
+	if call.GetTarget() != nil {
+		args = append([]*exprpb.Expr{call.GetTarget()}, args...)
+	}
+	for _, id := range ref.GetOverloadId() {
+		o, found := overloads[id]
+		if !found {
+			v.report(e, "overload '%s' of function '%s' is no longer declared", id, call.GetFunction())
+			continue
+		}
+		compatible := len(o.GetParams()) == len(args) &&
+			v.compatible(v.ast.typeMap[e.GetId()], o.GetResultType())
+		for i, p := range o.GetParams() {
+			if !compatible {
+				break
+			}
+			compatible = v.compatible(p, v.ast.typeMap[args[i].GetId()])
+		}
+		if !compatible {
+			v.report(e, "overload '%s' of function '%s' has an incompatible signature", id, call.GetFunction())
+		}
+	}
+}
+
+// validateSelect verifies that a selected message field still exists.
+func (v *astValidator) validateSelect(e *exprpb.Expr) {
+	sel := e.GetSelectExpr()
+	msgType := v.ast.typeMap[sel.GetOperand().GetId()].GetMessageType()
+	if msgType == "" {
+		return
+	}
+	ft, found := v.env.provider.FindFieldType(msgType, sel.GetField())
+	if !found {
+		v.report(e, "undefined field '%s' of type '%s'", sel.GetField(), msgType)
+		return
+	}
+	if !sel.GetTestOnly() && !v.compatible(v.ast.typeMap[e.GetId()], ft.Type) {
+		v.report(e, "incompatible type for field '%s' of type '%s'", sel.GetField(), msgType)
+	}
+}
+
+// validateStruct verifies that the fields set by a message construction still exist.
+func (v *astValidator) validateStruct(e *exprpb.Expr) {
+	msgType := v.ast.typeMap[e.GetId()].GetMessageType()
+	if msgType == "" {
+		return
+	}
+	if _, found := v.env.provider.FindType(msgType); !found {
+		v.report(e, "undeclared reference to '%s'", msgType)
+		return
+	}
+	for _, entry := range e.GetStructExpr().GetEntries() {
+		ft, found := v.env.provider.FindFieldType(msgType, entry.GetFieldKey())
+		if !found {
+			v.report(entry.GetValue(), "undefined field '%s' of type '%s'", entry.GetFieldKey(), msgType)
+			continue
+		}
+		if !v.compatible(ft.Type, v.ast.typeMap[entry.GetValue().GetId()]) {
+			v.report(entry.GetValue(), "incompatible type for field '%s' of type '%s'", entry.GetFieldKey(), msgType)
+		}
+	}
+}
+
+// compatible indicates whether a value of the from type may be used where the to type is expected,
+// treating unknown types, dynamic types, and type parameters as compatible with any type.
+func (v *astValidator) compatible(to, from *exprpb.Type) bool {
+	if to == nil || from == nil {
+		return true
+	}
+	toType, err := ExprTypeToType(to)
+	if err != nil {
+		return true
+	}
+	fromType, err := ExprTypeToType(from)
+	if err != nil {
+		return true
+	}
+	return isCompatibleType(toType, fromType)
+}
+
+func isCompatibleType(to, from *Type) bool {
+	if from.isDyn() || to.IsAssignableType(from) {
+		return true
+	}
+	if to.kind != from.kind ||
+		to.runtimeType.TypeName() != from.runtimeType.TypeName() ||
+		len(to.parameters) != len(from.parameters) {
+		return false
+	}
+	for i, p := range to.parameters {
+		if !isCompatibleType(p, from.parameters[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (v *astValidator) report(e *exprpb.Expr, format string, args ...any) {
+	v.errs.ReportError(exprLocation(v.ast, e.GetId()), format, args...)
+}
+
+// formatCheckedType formats a checked type for error messages.
+func formatCheckedType(t *exprpb.Type) string {
+	ct, err := ExprTypeToType(t)
+	if err != nil {
+		return t.String()
+	}
+	return ct.String()
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"strings"
+	"testing"
+
+	proto3pb "github.com/google/cel-go/test/proto3pb"
+)
+
+func TestValidate(t *testing.T) {
+	msgType := ObjectType("google.expr.proto3.test.TestAllTypes")
+	fn := func(resultType *Type, overloadIDs ...string) EnvOption {
+		var opts []FunctionOpt
+		for _, id := range overloadIDs {
+			opts = append(opts, Overload(id, []*Type{IntType}, resultType))
+		}
+		return Function("f", opts...)
+	}
+	env, err := NewEnv(Types(&proto3pb.TestAllTypes{}),
+		Variable("x", IntType), Variable("msg", msgType), fn(IntType, "f_int"))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`f(x) + msg.single_int64 > 0 && [1].all(y, y > x) && has(msg.single_int32)`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	tests := []struct {
+		name string
+		opts []EnvOption
+		err  string
+	}{
+		{
+			name: "unchanged",
+			opts: []EnvOption{Types(&proto3pb.TestAllTypes{}), Variable("x", IntType), Variable("msg", msgType), fn(IntType, "f_int")},
+		},
+		{
+			name: "additional declarations",
+			opts: []EnvOption{Types(&proto3pb.TestAllTypes{}), Variable("x", IntType), Variable("y", StringType),
+				Variable("msg", msgType), fn(IntType, "f_int"),
+				Function("f", Overload("f_string", []*Type{StringType}, IntType))},
+		},
+		{
+			name: "dynamic variable",
+			opts: []EnvOption{Types(&proto3pb.TestAllTypes{}), Variable("x", DynType), Variable("msg", msgType), fn(IntType, "f_int")},
+		},
+		{
+			name: "incompatible variable",
+			opts: []EnvOption{Types(&proto3pb.TestAllTypes{}), Variable("x", StringType), Variable("msg", msgType), fn(IntType, "f_int")},
+			err:  "ERROR: <input>:1:3: incompatible type for 'x': declared as 'string', checked as 'int'",
+		},
+		{
+			name: "undeclared variable",
+			opts: []EnvOption{Types(&proto3pb.TestAllTypes{}), Variable("msg", msgType), fn(IntType, "f_int")},
+			err:  "ERROR: <input>:1:3: undeclared reference to 'x'",
+		},
+		{
+			name: "removed overload",
+			opts: []EnvOption{Types(&proto3pb.TestAllTypes{}), Variable("x", IntType), Variable("msg", msgType), fn(IntType, "f_int64")},
+			err:  "ERROR: <input>:1:2: overload 'f_int' of function 'f' is no longer declared",
+		},
+		{
+			name: "incompatible overload",
+			opts: []EnvOption{Types(&proto3pb.TestAllTypes{}), Variable("x", IntType), Variable("msg", msgType), fn(StringType, "f_int")},
+			err:  "ERROR: <input>:1:2: overload 'f_int' of function 'f' has an incompatible signature",
+		},
+		{
+			name: "unregistered message",
+			opts: []EnvOption{Variable("x", IntType), Variable("msg", msgType), fn(IntType, "f_int")},
+			err:  "undefined field 'single_int64' of type 'google.expr.proto3.test.TestAllTypes'",
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.name, func(t *testing.T) {
+			newEnv, err := NewEnv(tc.opts...)
+			if err != nil {
+				t.Fatalf("NewEnv() failed: %v", err)
+			}
+			iss := newEnv.Validate(ast)
+			if tc.err == "" {
+				if iss.Err() != nil {
+					t.Errorf("Validate() failed: %v", iss.Err())
+				}
+				return
+			}
+			if iss.Err() == nil || !strings.Contains(iss.Err().Error(), tc.err) {
+				t.Errorf("Validate() got %v, wanted error %q", iss.Err(), tc.err)
+			}
+		})
+	}
+
+	parsed, iss := env.Parse(`x`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Parse() failed: %v", iss.Err())
+	}
+	if iss := env.Validate(parsed); iss.Err() == nil || !strings.Contains(iss.Err().Error(), "ast is not type-checked") {
+		t.Errorf("Validate() of a parsed ast got %v, wanted not type-checked", iss.Err())
+	}
+}
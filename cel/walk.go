@@ -0,0 +1,125 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// ExprScope tracks the comprehension variables in scope at an expression visited by WalkExpr.
+type ExprScope struct {
+	// locals counts the comprehension variables in scope by name.
+	locals map[string]int
+}
+
+// IsLocal returns whether the name refers to a comprehension variable in scope.
+func (s *ExprScope) IsLocal(name string) bool {
+	return s.locals[name] > 0
+}
+
+func (s *ExprScope) enter(names ...string) {
+	if s.locals == nil {
+		s.locals = map[string]int{}
+	}
+	for _, name := range names {
+		s.locals[name]++
+	}
+}
+
+func (s *ExprScope) exit(names ...string) {
+	for _, name := range names {
+		s.locals[name]--
+	}
+}
+
+// WalkExpr traverses the expression graph, calling pre before visiting the subexpressions of each
+// expression and post afterwards. Either function may be nil.
+//
+// When pre returns false, the subexpressions are skipped, though post is still called. The scope
+// holds the comprehension variables in scope at the expression: the accumulator variable is in
+// scope within the loop condition, loop step, and result of a comprehension, and the iteration
+// variable within the loop condition and loop step.
+func WalkExpr(e *exprpb.Expr,
+	pre func(e *exprpb.Expr, scope *ExprScope) bool,
+	post func(e *exprpb.Expr, scope *ExprScope)) {
+	(&ExprScope{}).walk(e, pre, post)
+}
+
+func (s *ExprScope) walk(e *exprpb.Expr,
+	pre func(e *exprpb.Expr, scope *ExprScope) bool,
+	post func(e *exprpb.Expr, scope *ExprScope)) {
+	if e == nil {
+		return
+	}
+	if pre == nil || pre(e, s) {
+		comp := e.GetComprehensionExpr()
+		if comp == nil {
+			for _, c := range exprChildren(e) {
+				s.walk(c, pre, post)
+			}
+		} else {
+			s.walk(comp.GetIterRange(), pre, post)
+			s.walk(comp.GetAccuInit(), pre, post)
+			s.enter(comp.GetAccuVar(), comp.GetIterVar())
+			s.walk(comp.GetLoopCondition(), pre, post)
+			s.walk(comp.GetLoopStep(), pre, post)
+			s.exit(comp.GetIterVar())
+			s.walk(comp.GetResult(), pre, post)
+			s.exit(comp.GetAccuVar())
+		}
+	}
+	if post != nil {
+		post(e, s)
+	}
+}
+
+// visitExpr performs a pre-order traversal of the expression graph.
+func visitExpr(e *exprpb.Expr, visitor func(*exprpb.Expr)) {
+	WalkExpr(e, func(e *exprpb.Expr, _ *ExprScope) bool {
+		visitor(e)
+		return true
+	}, nil)
+}
+
+// exprChildren returns the direct subexpressions of the expression.
+func exprChildren(e *exprpb.Expr) []*exprpb.Expr {
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		return []*exprpb.Expr{e.GetSelectExpr().GetOperand()}
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		children := make([]*exprpb.Expr, 0, len(call.GetArgs())+1)
+		if call.GetTarget() != nil {
+			children = append(children, call.GetTarget())
+		}
+		return append(children, call.GetArgs()...)
+	case *exprpb.Expr_ListExpr:
+		return e.GetListExpr().GetElements()
+	case *exprpb.Expr_StructExpr:
+		var children []*exprpb.Expr
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			if entry.GetMapKey() != nil {
+				children = append(children, entry.GetMapKey())
+			}
+			children = append(children, entry.GetValue())
+		}
+		return children
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		return []*exprpb.Expr{comp.GetIterRange(), comp.GetAccuInit(),
+			comp.GetLoopCondition(), comp.GetLoopStep(), comp.GetResult()}
+	}
+	return nil
+}
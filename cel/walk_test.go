@@ -0,0 +1,58 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestWalkExpr(t *testing.T) {
+	env, err := NewEnv(
+		Variable("x", IntType),
+		Variable("a.b", IntType),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Parse(`[x].all(x, x > a.b) && x > 0`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Parse() failed: %v", iss.Err())
+	}
+	var pre, post []string
+	WalkExpr(ast.Expr(), func(e *exprpb.Expr, scope *ExprScope) bool {
+		if name := e.GetIdentExpr().GetName(); name != "" {
+			pre = append(pre, fmt.Sprintf("%s:%t", name, scope.IsLocal(name)))
+		}
+		// Skip the operands of selections.
+		return e.GetSelectExpr() == nil
+	}, func(e *exprpb.Expr, _ *ExprScope) {
+		if sel := e.GetSelectExpr(); sel != nil {
+			post = append(post, sel.GetField())
+		}
+	})
+	// The comprehension variables are local within the comprehension, unlike the references to x
+	// outside of it.
+	wantPre := []string{"x:false", "__result__:true", "__result__:true", "x:true", "__result__:true", "x:false"}
+	if !reflect.DeepEqual(pre, wantPre) {
+		t.Errorf("WalkExpr() visited identifiers %v, wanted %v", pre, wantPre)
+	}
+	if !reflect.DeepEqual(post, []string{"b"}) {
+		t.Errorf("WalkExpr() visited selections %v, wanted [b]", post)
+	}
+}
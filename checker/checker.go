@@ -39,6 +39,10 @@ type checker struct {
 	sourceInfo         *exprpb.SourceInfo
 	types              map[int64]*exprpb.Type
 	references         map[int64]*exprpb.Reference
+	typeNarrowing      bool
+	// narrowed holds the types established by type tests of dynamic variables and fields, keyed by
+	// their qualified names.
+	narrowed map[string]*exprpb.Type
 }
 
 // Check performs type checking, giving a typed AST.
@@ -58,6 +62,7 @@ func Check(parsedExpr *exprpb.ParsedExpr,
 		sourceInfo:         parsedExpr.GetSourceInfo(),
 		types:              make(map[int64]*exprpb.Type),
 		references:         make(map[int64]*exprpb.Reference),
+		typeNarrowing:      env.typeNarrowing,
 	}
 	c.check(parsedExpr.GetExpr())
 
@@ -102,8 +107,10 @@ func (c *checker) check(e *exprpb.Expr) {
 		}
 	case *exprpb.Expr_IdentExpr:
 		c.checkIdent(e)
+		c.narrow(e)
 	case *exprpb.Expr_SelectExpr:
 		c.checkSelect(e)
+		c.narrow(e)
 	case *exprpb.Expr_CallExpr:
 		c.checkCall(e)
 	case *exprpb.Expr_ListExpr:
@@ -231,6 +238,18 @@ func (c *checker) checkSelectField(e, operand *exprpb.Expr, field string, option
 		if fieldType, found := c.lookupFieldType(c.location(e), messageType.GetMessageType(), field); found {
 			resultType = fieldType.Type
 		}
+	case kindAbstract:
+		// Pairs yield the type of the selected element as the selection result type.
+		if !isPair(targetType) {
+			c.errors.typeDoesNotSupportFieldSelection(c.location(e), targetType)
+			resultType = decls.Dyn
+			break
+		}
+		if elemType, found := pairElementType(targetType, field); found {
+			resultType = elemType
+		} else {
+			c.errors.undefinedField(c.location(e), field)
+		}
 	case kindTypeParam:
 		// Set the operand type to DYN to prevent assignment to a potentially incorrect type
 		// at a later point in type-checking. The isAssignable call will update the type
@@ -267,8 +286,10 @@ func (c *checker) checkCall(e *exprpb.Expr) {
 
 	args := call.GetArgs()
 	// Traverse arguments.
-	for _, arg := range args {
-		c.check(arg)
+	if !c.typeNarrowing || !c.checkNarrowedArgs(fnName, args) {
+		for _, arg := range args {
+			c.check(arg)
+		}
 	}
 
 	target := call.GetTarget()
@@ -396,7 +417,19 @@ func (c *checker) resolveOverload(
 		for i, arg := range argTypes {
 			argTypes[i] = substitute(c.mappings, arg, true)
 		}
-		c.errors.noMatchingOverload(loc, fn.GetName(), argTypes, target != nil)
+		// Conditionals with a valid condition only fail to resolve when the types of the branches
+		// differ, so report the types and locations of the branches instead of the signature.
+		if fn.GetName() == operators.Conditional && len(args) == 3 &&
+			isAssignable(c.mappings, decls.Bool, argTypes[0]) != nil {
+			c.errors.incompatibleBranches(loc,
+				argTypes[1], c.location(args[1]), argTypes[2], c.location(args[2]))
+			return nil
+		}
+		name := fn.GetName()
+		if displayName, found := c.env.displayNames[name]; found {
+			name = displayName
+		}
+		c.errors.noMatchingOverload(loc, name, argTypes, target != nil)
 		resultType = decls.Error
 		return nil
 	}
@@ -557,6 +590,7 @@ func (c *checker) checkComprehension(e *exprpb.Expr) {
 	// This scope will contain the accumulation variable used to compute the result.
 	c.env = c.env.enterScope()
 	c.env.Add(decls.NewVar(comp.GetAccuVar(), accuType))
+	narrowed := c.shadowNarrowed(comp)
 	// Create a block scope for the loop.
 	c.env = c.env.enterScope()
 	c.env.Add(decls.NewVar(comp.GetIterVar(), varType))
@@ -570,6 +604,7 @@ func (c *checker) checkComprehension(e *exprpb.Expr) {
 	c.check(comp.GetResult())
 	// Exit the comprehension scope.
 	c.env = c.env.exitScope()
+	c.narrowed = narrowed
 	c.setType(e, substitute(c.mappings, c.getType(comp.GetResult()), false))
 }
 
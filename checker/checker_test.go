@@ -265,6 +265,27 @@ _+_(int(1u~uint)~int^uint64_to_int64,
   ~int^add_int64`,
 		outType: decls.Int,
 	},
+	{
+		in: `true ? 1 : 2.0`,
+		err: `
+	ERROR: <input>:1:6: conditional branches have incompatible types: 'int' at 1:8 and 'double' at 1:12; numeric types are not converted implicitly, convert the values with int(), uint(), or double()
+	  | true ? 1 : 2.0
+	  | .....^`,
+	},
+	{
+		in: `true ? 1 : 'one'`,
+		err: `
+	ERROR: <input>:1:6: conditional branches have incompatible types: 'int' at 1:8 and 'string' at 1:12
+	  | true ? 1 : 'one'
+	  | .....^`,
+	},
+	{
+		in: `1 ? 1 : 'one'`,
+		err: `
+	ERROR: <input>:1:3: found no matching overload for '_?_:_' applied to '(int, int, string)'
+	  | 1 ? 1 : 'one'
+	  | ..^`,
+	},
 	{
 		in: `false && !true || false ? 2 : 3`,
 		out: `
@@ -2059,6 +2080,27 @@ _&&_(_==_(list~type(list(dyn))^list,
 		outType: decls.NewOptionalType(decls.String),
 		out:     `a~optional(map(string, string))^a.b~optional(string)`,
 	},
+	{
+		in: `a.second`,
+		env: testEnv{
+			idents: []*exprpb.Decl{
+				decls.NewVar("a", decls.NewPairType(decls.String, decls.NewListType(decls.Int))),
+			},
+		},
+		outType: decls.NewListType(decls.Int),
+		out:     `a~pair(string, list(int))^a.second~list(int)`,
+	},
+	{
+		in: `a.third`,
+		env: testEnv{
+			idents: []*exprpb.Decl{
+				decls.NewVar("a", decls.NewPairType(decls.String, decls.Int)),
+			},
+		},
+		err: `ERROR: <input>:1:2: undefined field 'third'
+		| a.third
+		| .^`,
+	},
 	{
 		in: `a.dynamic`,
 		env: testEnv{
@@ -2175,6 +2217,21 @@ _&&_(_==_(list~type(list(dyn))^list,
 		| TestAllTypes{?single_int32: 1}
 		| ............................^`,
 	},
+	{
+		in: `[1.5].all(x, key(x) > 0)`,
+		env: testEnv{
+			functions: []*exprpb.Decl{
+				decls.NewFunction("key",
+					decls.NewOverload("key_int", []*exprpb.Type{decls.Int}, decls.Int)),
+			},
+		},
+		opts: []Option{FunctionDisplayNames(map[string]string{"key": "keyed"})},
+		err: `
+		ERROR: <input>:1:17: found no matching overload for 'keyed' applied to '(double)'
+		 | [1.5].all(x, key(x) > 0)
+		 | ................^
+		`,
+	},
 }
 
 var testEnvs = map[string]testEnv{
@@ -70,6 +70,12 @@ func NewOptionalType(paramType *exprpb.Type) *exprpb.Type {
 	return NewAbstractType("optional", paramType)
 }
 
+// NewPairType constructs an abstract type indicating a pair of values whose elements have the
+// first and second types.
+func NewPairType(first, second *exprpb.Type) *exprpb.Type {
+	return NewAbstractType("pair", first, second)
+}
+
 // NewFunctionType creates a function invocation contract, typically only used
 // by type-checking steps after overload resolution.
 func NewFunctionType(resultType *exprpb.Type,
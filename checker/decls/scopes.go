@@ -112,6 +112,35 @@ func (s *Scopes) FindFunction(name string) *exprpb.Decl {
 	return nil
 }
 
+// Declarations returns the ident and function Decls visible from the current scope, excluding
+// those which are shadowed by inner scopes.
+//
+// The order of the Decls is unspecified.
+func (s *Scopes) Declarations() []*exprpb.Decl {
+	idents := map[string]*exprpb.Decl{}
+	functions := map[string]*exprpb.Decl{}
+	for sc := s; sc != nil; sc = sc.parent {
+		for n, id := range sc.scopes.idents {
+			if _, found := idents[n]; !found {
+				idents[n] = id
+			}
+		}
+		for n, fn := range sc.scopes.functions {
+			if _, found := functions[n]; !found {
+				functions[n] = fn
+			}
+		}
+	}
+	decls := make([]*exprpb.Decl, 0, len(idents)+len(functions))
+	for _, id := range idents {
+		decls = append(decls, id)
+	}
+	for _, fn := range functions {
+		decls = append(decls, fn)
+	}
+	return decls
+}
+
 // Group is a set of Decls that is pushed on or popped off a Scopes as a unit.
 // Contains separate namespaces for identifier and function Decls.
 // (Should be named "Scope" perhaps?)
@@ -80,6 +80,8 @@ type Env struct {
 	declarations        *decls.Scopes
 	aggLitElemType      aggregateLiteralElementType
 	filteredOverloadIDs map[string]struct{}
+	typeNarrowing       bool
+	displayNames        map[string]string
 }
 
 // NewEnv returns a new *Env with the given parameters.
@@ -110,6 +112,8 @@ func NewEnv(container *containers.Container, provider ref.TypeProvider, opts ...
 		declarations:        declarations,
 		aggLitElemType:      aggLitElemType,
 		filteredOverloadIDs: filteredOverloadIDs,
+		typeNarrowing:       envOptions.typeNarrowing,
+		displayNames:        envOptions.functionDisplayNames,
 	}, nil
 }
 
@@ -128,6 +132,13 @@ func (e *Env) Add(decls ...*exprpb.Decl) error {
 	return formatError(errMsgs)
 }
 
+// Declarations returns the variable and function Decl protos which have been added to the Env.
+//
+// The order of the declarations is unspecified.
+func (e *Env) Declarations() []*exprpb.Decl {
+	return e.declarations.Declarations()
+}
+
 // LookupIdent returns a Decl proto for typeName as an identifier in the Env.
 // Returns nil if no such identifier is found in the Env.
 func (e *Env) LookupIdent(name string) *exprpb.Decl {
@@ -355,6 +366,7 @@ func (e *Env) enterScope() *Env {
 		container:      e.container,
 		provider:       e.provider,
 		aggLitElemType: e.aggLitElemType,
+		displayNames:   e.displayNames,
 	}
 }
 
@@ -366,6 +378,7 @@ func (e *Env) exitScope() *Env {
 		container:      e.container,
 		provider:       e.provider,
 		aggLitElemType: e.aggLitElemType,
+		displayNames:   e.displayNames,
 	}
 }
 
@@ -15,6 +15,8 @@
 package checker
 
 import (
+	"fmt"
+
 	"github.com/google/cel-go/common"
 
 	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
@@ -42,6 +44,18 @@ func (e *typeErrors) noMatchingOverload(l common.Location, name string, args []*
 	e.ReportError(l, "found no matching overload for '%s' applied to '%s'", name, signature)
 }
 
+func (e *typeErrors) incompatibleBranches(l common.Location,
+	truthy *exprpb.Type, truthyLoc common.Location,
+	falsy *exprpb.Type, falsyLoc common.Location) {
+	msg := fmt.Sprintf("conditional branches have incompatible types: '%s' at %d:%d and '%s' at %d:%d",
+		FormatCheckedType(truthy), truthyLoc.Line(), truthyLoc.Column()+1,
+		FormatCheckedType(falsy), falsyLoc.Line(), falsyLoc.Column()+1)
+	if hint := UnificationHint(truthy, falsy); hint != "" {
+		msg += "; " + hint
+	}
+	e.ReportError(l, "%s", msg)
+}
+
 func (e *typeErrors) notAType(l common.Location, t *exprpb.Type) {
 	e.ReportError(l, "'%s(%v)' is not a type", FormatCheckedType(t), t)
 }
@@ -0,0 +1,115 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"strings"
+
+	"github.com/google/cel-go/common/containers"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/overloads"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// checkNarrowedArgs checks the arguments of a conditional or a logical and, narrowing the types of
+// dynamic variables and fields which the first argument tests against a type, e.g. `type(x) == T`,
+// within the argument which is only evaluated when the test succeeds. It returns false if the call
+// is of any other function.
+func (c *checker) checkNarrowedArgs(fnName string, args []*exprpb.Expr) bool {
+	if fnName != operators.Conditional && fnName != operators.LogicalAnd {
+		return false
+	}
+	c.check(args[0])
+	outer := c.narrowed
+	narrowed := make(map[string]*exprpb.Type, len(outer))
+	for path, t := range outer {
+		narrowed[path] = t
+	}
+	c.collectTypeTests(args[0], narrowed)
+	c.narrowed = narrowed
+	c.check(args[1])
+	c.narrowed = outer
+	for _, arg := range args[2:] {
+		c.check(arg)
+	}
+	return true
+}
+
+// collectTypeTests records the types which the condition tests the dynamic variables and fields
+// against, including the tests within a conjunction.
+func (c *checker) collectTypeTests(cond *exprpb.Expr, narrowed map[string]*exprpb.Type) {
+	call := cond.GetCallExpr()
+	switch call.GetFunction() {
+	case operators.LogicalAnd:
+		for _, arg := range call.GetArgs() {
+			c.collectTypeTests(arg, narrowed)
+		}
+	case operators.Equals:
+		args := call.GetArgs()
+		for i, arg := range args {
+			path, found := typeTestPath(arg)
+			if !found || !isDyn(c.getType(arg.GetCallExpr().GetArgs()[0])) {
+				continue
+			}
+			t := substitute(c.mappings, c.getType(args[1-i]), false)
+			if kindOf(t) == kindType && !isDyn(t.GetType()) && kindOf(t.GetType()) != kindTypeParam {
+				narrowed[path] = t.GetType()
+			}
+		}
+	}
+}
+
+// typeTestPath returns the qualified name of the variable or field within the call `type(<path>)`.
+func typeTestPath(e *exprpb.Expr) (string, bool) {
+	call := e.GetCallExpr()
+	if call.GetFunction() != overloads.TypeConvertType || call.GetTarget() != nil || len(call.GetArgs()) != 1 {
+		return "", false
+	}
+	return containers.ToQualifiedName(call.GetArgs()[0])
+}
+
+// narrow replaces the type of the variable or field with the type established by a type test.
+func (c *checker) narrow(e *exprpb.Expr) {
+	if len(c.narrowed) == 0 {
+		return
+	}
+	path, found := containers.ToQualifiedName(e)
+	if !found {
+		return
+	}
+	if t, found := c.narrowed[path]; found {
+		c.types[e.GetId()] = t
+	}
+}
+
+// shadowNarrowed removes the narrowed types of the paths rooted at the comprehension variables, as
+// the variables hide the declarations which were tested, returning the narrowed types to restore
+// on leaving the comprehension.
+func (c *checker) shadowNarrowed(comp *exprpb.Expr_Comprehension) map[string]*exprpb.Type {
+	outer := c.narrowed
+	if len(outer) == 0 {
+		return outer
+	}
+	inner := make(map[string]*exprpb.Type, len(outer))
+	for path, t := range outer {
+		root := strings.SplitN(path, ".", 2)[0]
+		if root != comp.GetIterVar() && root != comp.GetAccuVar() {
+			inner[path] = t
+		}
+	}
+	c.narrowed = inner
+	return outer
+}
@@ -19,7 +19,9 @@ import "github.com/google/cel-go/checker/decls"
 type options struct {
 	crossTypeNumericComparisons  bool
 	homogeneousAggregateLiterals bool
+	typeNarrowing                bool
 	validatedDeclarations        *decls.Scopes
+	functionDisplayNames         map[string]string
 }
 
 // Option is a functional option for configuring the type-checker
@@ -43,6 +45,26 @@ func HomogeneousAggregateLiterals(enabled bool) Option {
 	}
 }
 
+// TypeNarrowing toggles the narrowing of the types of dynamic variables and fields which are tested
+// against a type, e.g. `type(x) == T`, within the branch of a conditional or the right-hand side of
+// a logical and which is only evaluated when the test succeeds.
+func TypeNarrowing(enabled bool) Option {
+	return func(opts *options) error {
+		opts.typeNarrowing = enabled
+		return nil
+	}
+}
+
+// FunctionDisplayNames sets the names by which type errors refer to functions, keyed by function
+// name, so that errors involving the internal functions to which macros expand refer to the
+// macros instead.
+func FunctionDisplayNames(names map[string]string) Option {
+	return func(opts *options) error {
+		opts.functionDisplayNames = names
+		return nil
+	}
+}
+
 // ValidatedDeclarations provides a references to validated declarations which will be copied
 // into new checker instances.
 func ValidatedDeclarations(env *Env) Option {
@@ -133,6 +133,26 @@ func isOptional(t *exprpb.Type) bool {
 	return false
 }
 
+func isPair(t *exprpb.Type) bool {
+	if kindOf(t) == kindAbstract {
+		at := t.GetAbstractType()
+		return at.GetName() == "pair" && len(at.GetParameterTypes()) == 2
+	}
+	return false
+}
+
+// pairElementType returns the type of the pair element selected by the `first` or `second` field.
+func pairElementType(t *exprpb.Type, field string) (*exprpb.Type, bool) {
+	params := t.GetAbstractType().GetParameterTypes()
+	switch field {
+	case "first":
+		return params[0], true
+	case "second":
+		return params[1], true
+	}
+	return nil, false
+}
+
 func maybeUnwrapOptional(t *exprpb.Type) (*exprpb.Type, bool) {
 	if isOptional(t) {
 		at := t.GetAbstractType()
@@ -141,6 +161,30 @@ func maybeUnwrapOptional(t *exprpb.Type) (*exprpb.Type, bool) {
 	return t, false
 }
 
+// UnificationHint suggests how values of two types which cannot be unified into a single type may
+// be reconciled, such as the branches of a conditional or the elements of a list literal. The
+// empty string is returned when there is no suggestion.
+func UnificationHint(t1, t2 *exprpb.Type) string {
+	for _, pair := range [][2]*exprpb.Type{{t1, t2}, {t2, t1}} {
+		inner, isOpt := maybeUnwrapOptional(pair[0])
+		if isOpt && !isOptional(pair[1]) && isAssignable(newMapping(), inner, pair[1]) != nil {
+			return "wrap the non-optional value with optional.of()"
+		}
+	}
+	if isNumeric(t1) && isNumeric(t2) {
+		return "numeric types are not converted implicitly, convert the values with int(), uint(), or double()"
+	}
+	return ""
+}
+
+func isNumeric(t *exprpb.Type) bool {
+	switch t.GetPrimitive() {
+	case exprpb.Type_INT64, exprpb.Type_UINT64, exprpb.Type_DOUBLE:
+		return true
+	}
+	return false
+}
+
 func maybeUnwrapString(e *exprpb.Expr) (string, bool) {
 	switch e.GetExprKind().(type) {
 	case *exprpb.Expr_ConstExpr:
@@ -258,7 +302,12 @@ func internalIsAssignable(m *mapping, t1 *exprpb.Type, t2 *exprpb.Type) bool {
 		return t1.GetMessageType() == t2.GetMessageType()
 	case kindType:
 		// A type is a type is a type, any additional parameterization of the
-		// type cannot affect method resolution or assignability.
+		// type cannot affect method resolution or assignability. The exception is a type
+		// parameter, which is bound to the type described by the other type.
+		p1, p2 := t1.GetType(), t2.GetType()
+		if p1 != nil && p2 != nil && (kindOf(p1) == kindTypeParam || kindOf(p2) == kindTypeParam) {
+			return internalIsAssignable(m, p1, p2)
+		}
 		return true
 	case kindWellKnown:
 		return t1.GetWellKnown() == t2.GetWellKnown()
@@ -460,6 +509,11 @@ func notReferencedIn(m *mapping, t *exprpb.Type, withinType *exprpb.Type) bool {
 	case kindMap:
 		mt := withinType.GetMapType()
 		return notReferencedIn(m, t, mt.GetKeyType()) && notReferencedIn(m, t, mt.GetValueType())
+	case kindType:
+		if withinType.GetType() == nil {
+			return true
+		}
+		return notReferencedIn(m, t, withinType.GetType())
 	case kindWrapper:
 		return notReferencedIn(m, t, decls.NewPrimitiveType(withinType.GetWrapper()))
 	default:
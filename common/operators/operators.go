@@ -51,6 +51,7 @@ const (
 	// Named operators, must not have be valid identifiers.
 	NotStrictlyFalse = "@not_strictly_false"
 	In               = "@in"
+	MergeMaps        = "@merge_maps"
 
 	// Deprecated: named operators with valid identifiers.
 	OldNotStrictlyFalse = "__not_strictly_false__"
@@ -38,6 +38,7 @@ var (
 	BytesType = NewTypeValue("bytes",
 		traits.AdderType,
 		traits.ComparerType,
+		traits.HashableType,
 		traits.SizerType)
 
 	// byteWrapperType golang reflected type for protobuf bytes wrapper type.
@@ -116,6 +117,14 @@ func (b Bytes) Equal(other ref.Val) ref.Val {
 	return Bool(ok && bytes.Equal(b, otherBytes))
 }
 
+// HashKey implements the traits.Hashable interface method.
+func (b Bytes) HashKey() any {
+	return bytesHashKey(b)
+}
+
+// bytesHashKey identifies a bytes value among map keys.
+type bytesHashKey string
+
 // IsZeroValue returns true if the byte array is empty.
 func (b Bytes) IsZeroValue() bool {
 	return len(b) == 0
@@ -16,8 +16,10 @@ package types
 
 import (
 	"math"
+	"sort"
 
 	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
 )
 
 func compareDoubleInt(d Double, i Int) Int {
@@ -95,3 +97,89 @@ func compareUint(a, b Uint) ref.Val {
 	}
 	return IntZero
 }
+
+// CompareAggregates compares lists lexicographically by their elements, and maps lexicographically
+// by their entries in the order of their keys, returning -1, 0, or 1 as the left-hand side is less
+// than, equal to, or greater than the right-hand side. A list or map which is a prefix of the other
+// is less than the other.
+//
+// The elements, keys, and values must themselves be comparable, i.e. values with the Comparer
+// trait or lists and maps of such values, or else an error is returned.
+func CompareAggregates(lhs, rhs ref.Val) ref.Val {
+	switch l := lhs.(type) {
+	case traits.Lister:
+		r, ok := rhs.(traits.Lister)
+		if !ok {
+			return MaybeNoSuchOverloadErr(rhs)
+		}
+		return compareLists(l, r)
+	case traits.Mapper:
+		r, ok := rhs.(traits.Mapper)
+		if !ok {
+			return MaybeNoSuchOverloadErr(rhs)
+		}
+		return compareMaps(l, r)
+	case traits.Comparer:
+		if !lhs.Type().HasTrait(traits.ComparerType) {
+			return MaybeNoSuchOverloadErr(lhs)
+		}
+		return l.Compare(rhs)
+	}
+	return MaybeNoSuchOverloadErr(lhs)
+}
+
+func compareLists(lhs, rhs traits.Lister) ref.Val {
+	lit, rit := lhs.Iterator(), rhs.Iterator()
+	for {
+		lNext, rNext := lit.HasNext() == True, rit.HasNext() == True
+		switch {
+		case !lNext && !rNext:
+			return IntZero
+		case !rNext:
+			return IntOne
+		case !lNext:
+			return IntNegOne
+		}
+		if cmp := CompareAggregates(lit.Next(), rit.Next()); cmp != IntZero {
+			return cmp
+		}
+	}
+}
+
+func compareMaps(lhs, rhs traits.Mapper) ref.Val {
+	lKeys, err := sortedKeys(lhs)
+	if err != nil {
+		return err
+	}
+	rKeys, err := sortedKeys(rhs)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(lKeys) && i < len(rKeys); i++ {
+		if cmp := CompareAggregates(lKeys[i], rKeys[i]); cmp != IntZero {
+			return cmp
+		}
+		if cmp := CompareAggregates(lhs.Get(lKeys[i]), rhs.Get(rKeys[i])); cmp != IntZero {
+			return cmp
+		}
+	}
+	return compareInt(Int(len(lKeys)), Int(len(rKeys)))
+}
+
+// sortedKeys returns the keys of the map in ascending order, or an error if the keys are not
+// comparable with each other.
+func sortedKeys(m traits.Mapper) ([]ref.Val, ref.Val) {
+	var keys []ref.Val
+	for it := m.Iterator(); it.HasNext() == True; {
+		keys = append(keys, it.Next())
+	}
+	var err ref.Val
+	sort.SliceStable(keys, func(i, j int) bool {
+		cmp := CompareAggregates(keys[i], keys[j])
+		if IsError(cmp) && err == nil {
+			err = cmp
+		}
+		return cmp == IntNegOne
+	})
+	return keys, err
+}
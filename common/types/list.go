@@ -148,6 +148,19 @@ func (l *baseList) Add(other ref.Val) ref.Val {
 		nextList:    otherList}
 }
 
+// Compare implements the traits.Comparer interface method, ordering lists lexicographically by
+// their elements as described by CompareAggregates.
+//
+// The list type does not declare the traits.ComparerType trait, as the ordering comparison
+// operators only apply to lists when enabled by the environment.
+func (l *baseList) Compare(other ref.Val) ref.Val {
+	otherList, ok := other.(traits.Lister)
+	if !ok {
+		return MaybeNoSuchOverloadErr(other)
+	}
+	return compareLists(l, otherList)
+}
+
 // Contains implements the traits.Container interface method.
 func (l *baseList) Contains(elem ref.Val) ref.Val {
 	for i := 0; i < l.size; i++ {
@@ -352,6 +365,16 @@ func (l *concatList) Add(other ref.Val) ref.Val {
 		nextList:    otherList}
 }
 
+// Compare implements the traits.Comparer interface method, ordering lists lexicographically by
+// their elements as described by CompareAggregates.
+func (l *concatList) Compare(other ref.Val) ref.Val {
+	otherList, ok := other.(traits.Lister)
+	if !ok {
+		return MaybeNoSuchOverloadErr(other)
+	}
+	return compareLists(l, otherList)
+}
+
 // Contains implements the traits.Container interface method.
 func (l *concatList) Contains(elem ref.Val) ref.Val {
 	// The concat list relies on the IsErrorOrUnknown checks against the input element to be
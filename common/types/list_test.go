@@ -177,6 +177,34 @@ func TestBaseListEqual(t *testing.T) {
 	}
 }
 
+func TestBaseListCompare(t *testing.T) {
+	reg := newTestRegistry(t)
+	listA := NewDynamicList(reg, []int{1, 2})
+	listB := NewDynamicList(reg, []int{1, 3})
+	// The ordering comparison operators apply to lists only when enabled by the environment, so
+	// the list type does not advertise the comparer trait.
+	if listA.Type().HasTrait(traits.ComparerType) {
+		t.Error("listA.Type() has the comparer trait")
+	}
+	cmp := listA.(traits.Comparer)
+	if out := cmp.Compare(listB); out != IntNegOne {
+		t.Errorf("listA.Compare(listB) got %v, wanted -1", out)
+	}
+	if out := cmp.Compare(listA); out != IntZero {
+		t.Errorf("listA.Compare(listA) got %v, wanted 0", out)
+	}
+	concat := listA.Add(NewDynamicList(reg, []int{0}))
+	if out := concat.(traits.Comparer).Compare(listA); out != IntOne {
+		t.Errorf("concat.Compare(listA) got %v, wanted 1", out)
+	}
+	if out := cmp.Compare(Int(1)); !IsError(out) {
+		t.Errorf("listA.Compare(1) got %v, wanted error", out)
+	}
+	if out := cmp.Compare(NewDynamicList(reg, []string{"a"})); !IsError(out) {
+		t.Errorf("listA.Compare(['a']) got %v, wanted error", out)
+	}
+}
+
 func TestBaseListGet(t *testing.T) {
 	validateList123(t, NewDynamicList(newTestRegistry(t), []int32{1, 2, 3}))
 }
@@ -17,6 +17,7 @@ package types
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/stoewer/go-strcase"
@@ -31,6 +32,58 @@ import (
 	structpb "google.golang.org/protobuf/types/known/structpb"
 )
 
+// SortedMapKeys returns the keys of the map in a deterministic order: keys of the same type are
+// ordered by value, and keys of different types are ordered by type name.
+func SortedMapKeys(m traits.Mapper) []ref.Val {
+	var keys []ref.Val
+	for it := m.Iterator(); it.HasNext() == True; {
+		keys = append(keys, it.Next())
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return compareMapKeys(keys[i], keys[j]) < 0
+	})
+	return keys
+}
+
+// NewSortedMapIterator returns an iterator over the keys of the map in the order of SortedMapKeys.
+func NewSortedMapIterator(m traits.Mapper) traits.Iterator {
+	return &sortedKeyIterator{keys: SortedMapKeys(m)}
+}
+
+func compareMapKeys(k1, k2 ref.Val) int {
+	t1, t2 := k1.Type().TypeName(), k2.Type().TypeName()
+	if t1 != t2 {
+		return strings.Compare(t1, t2)
+	}
+	if cmp, isComparer := k1.(traits.Comparer); isComparer {
+		if order, isInt := cmp.Compare(k2).(Int); isInt {
+			return int(order)
+		}
+	}
+	return strings.Compare(fmt.Sprint(k1), fmt.Sprint(k2))
+}
+
+type sortedKeyIterator struct {
+	*baseIterator
+	keys   []ref.Val
+	cursor int
+}
+
+// HasNext implements the traits.Iterator interface method.
+func (it *sortedKeyIterator) HasNext() ref.Val {
+	return Bool(it.cursor < len(it.keys))
+}
+
+// Next implements the traits.Iterator interface method.
+func (it *sortedKeyIterator) Next() ref.Val {
+	if it.cursor >= len(it.keys) {
+		return nil
+	}
+	key := it.keys[it.cursor]
+	it.cursor++
+	return key
+}
+
 // NewDynamicMap returns a traits.Mapper value with dynamic key, value pairs.
 func NewDynamicMap(adapter ref.TypeAdapter, value any) traits.Mapper {
 	refValue := reflect.ValueOf(value)
@@ -66,6 +119,31 @@ func NewRefValMap(adapter ref.TypeAdapter, value map[ref.Val]ref.Val) traits.Map
 	}
 }
 
+// NewHashKeyMap returns a traits.Mapper whose keys may implement traits.Hashable, such as bytes,
+// which cannot be used as the keys of Go maps.
+//
+// The keys and values of the entries are supplied in separate maps keyed by the HashKey of the
+// entry key, and must contain the same hash keys.
+func NewHashKeyMap(adapter ref.TypeAdapter, keys, values map[any]ref.Val) traits.Mapper {
+	m := &baseMap{
+		TypeAdapter: adapter,
+		mapAccessor: &hashKeyMapAccessor{keys: keys, values: values},
+		size:        len(values),
+	}
+	// The map has no native representation other than itself.
+	m.value = m
+	return m
+}
+
+// HashKey returns the value which identifies the key among the keys of a map created with
+// NewHashKeyMap: the hash key of values which implement traits.Hashable, or the key itself.
+func HashKey(key ref.Val) any {
+	if h, ok := key.(traits.Hashable); ok {
+		return h.HashKey()
+	}
+	return key
+}
+
 // NewStringInterfaceMap returns a specialized traits.Mapper with string keys and interface values.
 func NewStringInterfaceMap(adapter ref.TypeAdapter, value map[string]any) traits.Mapper {
 	return &baseMap{
@@ -133,6 +211,19 @@ type baseMap struct {
 	size int
 }
 
+// Compare implements the traits.Comparer interface method, ordering maps lexicographically by
+// their entries in the order of their keys as described by CompareAggregates.
+//
+// The map type does not declare the traits.ComparerType trait, as the ordering comparison
+// operators only apply to maps when enabled by the environment.
+func (m *baseMap) Compare(other ref.Val) ref.Val {
+	otherMap, ok := other.(traits.Mapper)
+	if !ok {
+		return MaybeNoSuchOverloadErr(other)
+	}
+	return compareMaps(m, otherMap)
+}
+
 // Contains implements the traits.Container interface method.
 func (m *baseMap) Contains(index ref.Val) ref.Val {
 	_, found := m.Find(index)
@@ -287,20 +378,16 @@ func (m *baseMap) Size() ref.Val {
 	return Int(m.size)
 }
 
-// String converts the map into a human-readable string.
+// String converts the map into a human-readable string, with the entries in sorted key order.
 func (m *baseMap) String() string {
 	var sb strings.Builder
 	sb.WriteString("{")
-	it := m.Iterator()
-	i := 0
-	for it.HasNext() == True {
-		k := it.Next()
+	for i, k := range SortedMapKeys(m) {
 		v, _ := m.Find(k)
 		sb.WriteString(fmt.Sprintf("%v: %v", k, v))
 		if i != m.size-1 {
 			sb.WriteString(", ")
 		}
-		i++
 	}
 	sb.WriteString("}")
 	return sb.String()
@@ -486,6 +573,59 @@ func (a *refValMapAccessor) Iterator() traits.Iterator {
 	}
 }
 
+type hashKeyMapAccessor struct {
+	keys   map[any]ref.Val
+	values map[any]ref.Val
+}
+
+// Find looks up the key by its hash key, returning (value, true) if present.
+//
+// If the key is not found the function returns (nil, false).
+func (a *hashKeyMapAccessor) Find(key ref.Val) (ref.Val, bool) {
+	if len(a.values) == 0 {
+		return nil, false
+	}
+	if keyVal, found := a.values[HashKey(key)]; found {
+		return keyVal, true
+	}
+	switch k := key.(type) {
+	case Double:
+		if ik, ok := doubleToInt64Lossless(float64(k)); ok {
+			if keyVal, found := a.values[Int(ik)]; found {
+				return keyVal, found
+			}
+		}
+		if uk, ok := doubleToUint64Lossless(float64(k)); ok {
+			keyVal, found := a.values[Uint(uk)]
+			return keyVal, found
+		}
+	// map keys of type double are not supported.
+	case Int:
+		if uk, ok := int64ToUint64Lossless(int64(k)); ok {
+			keyVal, found := a.values[Uint(uk)]
+			return keyVal, found
+		}
+	case Uint:
+		if ik, ok := uint64ToInt64Lossless(uint64(k)); ok {
+			keyVal, found := a.values[Int(ik)]
+			return keyVal, found
+		}
+	}
+	return nil, false
+}
+
+// Iterator creates a new traits.Iterator over the keys of the map.
+func (a *hashKeyMapAccessor) Iterator() traits.Iterator {
+	mapKeys := make([]ref.Val, 0, len(a.keys))
+	for _, k := range a.keys {
+		mapKeys = append(mapKeys, k)
+	}
+	return &refValKeyIterator{
+		mapKeys: mapKeys,
+		len:     len(mapKeys),
+	}
+}
+
 func newStringMapAccessor(strMap map[string]string) mapAccessor {
 	return &stringMapAccessor{mapVal: strMap}
 }
@@ -573,6 +713,16 @@ type protoMap struct {
 	value *pb.Map
 }
 
+// Compare implements the traits.Comparer interface method, ordering maps lexicographically by
+// their entries in the order of their keys as described by CompareAggregates.
+func (m *protoMap) Compare(other ref.Val) ref.Val {
+	otherMap, ok := other.(traits.Mapper)
+	if !ok {
+		return MaybeNoSuchOverloadErr(other)
+	}
+	return compareMaps(m, otherMap)
+}
+
 // Contains returns whether the map contains the given key.
 func (m *protoMap) Contains(key ref.Val) ref.Val {
 	_, found := m.Find(key)
@@ -840,6 +990,28 @@ func (it *protoMapIterator) Next() ref.Val {
 	return nil
 }
 
+type refValKeyIterator struct {
+	*baseIterator
+	mapKeys []ref.Val
+	cursor  int
+	len     int
+}
+
+// HasNext implements the traits.Iterator interface method.
+func (it *refValKeyIterator) HasNext() ref.Val {
+	return Bool(it.cursor < it.len)
+}
+
+// Next implements the traits.Iterator interface method.
+func (it *refValKeyIterator) Next() ref.Val {
+	if it.HasNext() == True {
+		index := it.cursor
+		it.cursor++
+		return it.mapKeys[index]
+	}
+	return nil
+}
+
 type stringKeyIterator struct {
 	*baseIterator
 	mapKeys []string
@@ -319,6 +319,30 @@ func TestStringMapConvertToType(t *testing.T) {
 	}
 }
 
+func TestMapCompare(t *testing.T) {
+	reg := newTestRegistry(t)
+	mapA := NewDynamicMap(reg, map[string]int{"a": 1, "b": 2})
+	mapB := NewDynamicMap(reg, map[string]int{"a": 2, "b": 1})
+	// The ordering comparison operators apply to maps only when enabled by the environment, so
+	// the map type does not advertise the comparer trait.
+	if mapA.Type().HasTrait(traits.ComparerType) {
+		t.Error("mapA.Type() has the comparer trait")
+	}
+	cmp := mapA.(traits.Comparer)
+	if out := cmp.Compare(mapB); out != IntNegOne {
+		t.Errorf("mapA.Compare(mapB) got %v, wanted -1", out)
+	}
+	if out := cmp.Compare(mapA); out != IntZero {
+		t.Errorf("mapA.Compare(mapA) got %v, wanted 0", out)
+	}
+	if out := cmp.Compare(NewDynamicMap(reg, map[string]int{"a": 1})); out != IntOne {
+		t.Errorf("mapA.Compare({'a': 1}) got %v, wanted 1", out)
+	}
+	if out := cmp.Compare(String("a")); !IsError(out) {
+		t.Errorf("mapA.Compare('a') got %v, wanted error", out)
+	}
+}
+
 func TestDynamicMapEqual_True(t *testing.T) {
 	reg := newTestRegistry(t)
 	mapVal := NewDynamicMap(reg, map[string]map[int32]float32{
@@ -541,6 +565,56 @@ func TestRefValMapGet(t *testing.T) {
 	}
 }
 
+func TestHashKeyMap(t *testing.T) {
+	reg := newTestRegistry(t)
+	keys := map[any]ref.Val{}
+	vals := map[any]ref.Val{}
+	entries := [][]ref.Val{
+		{Bytes("abc"), String("digest")},
+		{Int(1), String("one")},
+		{String("abc"), String("string")},
+	}
+	for _, e := range entries {
+		keys[HashKey(e[0])] = e[0]
+		vals[HashKey(e[0])] = e[1]
+	}
+	mapVal := NewHashKeyMap(reg, keys, vals)
+	tests := []struct {
+		key ref.Val
+		out ref.Val
+	}{
+		{key: Bytes("abc"), out: String("digest")},
+		{key: String("abc"), out: String("string")},
+		{key: Uint(1), out: String("one")},
+		{key: Double(1.0), out: String("one")},
+	}
+	for _, tc := range tests {
+		if out := mapVal.Get(tc.key); out.Equal(tc.out) != True {
+			t.Errorf("mapVal.Get(%v) got %v, wanted %v", tc.key, out, tc.out)
+		}
+	}
+	if mapVal.Contains(Bytes("abd")) != False {
+		t.Error("mapVal.Contains(b'abd') got true, wanted false")
+	}
+	if mapVal.Size() != Int(3) {
+		t.Errorf("mapVal.Size() got %v, wanted 3", mapVal.Size())
+	}
+	found := 0
+	for it := mapVal.Iterator(); it.HasNext() == True; {
+		if mapVal.Contains(it.Next()) == True {
+			found++
+		}
+	}
+	if found != 3 {
+		t.Errorf("mapVal.Iterator() visited %d keys, wanted 3", found)
+	}
+	other := NewHashKeyMap(reg, map[any]ref.Val{HashKey(Bytes("abc")): Bytes("abc")},
+		map[any]ref.Val{HashKey(Bytes("abc")): String("digest")})
+	if mapVal.Equal(other) != False || other.Equal(other) != True {
+		t.Errorf("mapVal.Equal() got %v, wanted false", mapVal.Equal(other))
+	}
+}
+
 func TestMapIsZeroValue(t *testing.T) {
 	msg := &proto3pb.TestAllTypes{
 		MapStringString: map[string]string{
@@ -848,6 +922,40 @@ func TestProtoMapString(t *testing.T) {
 	}
 }
 
+func TestMapStringSortedKeys(t *testing.T) {
+	reg := newTestRegistry(t)
+	m := reg.NativeToValue(map[string]int{"c": 3, "a": 1, "b": 2, "d": 4})
+	want := `{a: 1, b: 2, c: 3, d: 4}`
+	for i := 0; i < 10; i++ {
+		if fmt.Sprintf("%v", m) != want {
+			t.Fatalf("map.String() got %v, wanted %v", m, want)
+		}
+	}
+}
+
+func TestSortedMapKeys(t *testing.T) {
+	m := NewRefValMap(DefaultTypeAdapter, map[ref.Val]ref.Val{
+		String("b"): True,
+		Int(10):     True,
+		String("a"): True,
+		Int(-1):     True,
+		Uint(3):     True,
+		False:       True,
+	})
+	keys := SortedMapKeys(m)
+	want := []ref.Val{False, Int(-1), Int(10), String("a"), String("b"), Uint(3)}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("SortedMapKeys() got %v, wanted %v", keys, want)
+	}
+	var iterated []ref.Val
+	for it := NewSortedMapIterator(m); it.HasNext() == True; {
+		iterated = append(iterated, it.Next())
+	}
+	if !reflect.DeepEqual(iterated, want) {
+		t.Errorf("NewSortedMapIterator() got %v, wanted %v", iterated, want)
+	}
+}
+
 func TestProtoMapConvertToNative(t *testing.T) {
 	strMap := map[string]string{
 		"hello":   "world",
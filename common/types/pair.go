@@ -0,0 +1,139 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+)
+
+var (
+	// PairType indicates the runtime type of a pair value.
+	PairType = NewTypeValue("pair",
+		traits.FieldTesterType,
+		traits.IndexerType)
+)
+
+// NewPair returns a pair of values whose elements are selected by the `first` and `second`
+// fields.
+func NewPair(first, second ref.Val) *Pair {
+	return &Pair{first: first, second: second}
+}
+
+// Pair is an ordered pair of values of possibly different types.
+type Pair struct {
+	first  ref.Val
+	second ref.Val
+}
+
+// First returns the first element of the pair.
+func (p *Pair) First() ref.Val {
+	return p.first
+}
+
+// Second returns the second element of the pair.
+func (p *Pair) Second() ref.Val {
+	return p.second
+}
+
+// ConvertToNative implements the ref.Val interface method.
+//
+// A pair converts to a native slice or array of two elements.
+func (p *Pair) ConvertToNative(typeDesc reflect.Type) (any, error) {
+	switch typeDesc.Kind() {
+	case reflect.Array, reflect.Slice:
+		if typeDesc.Kind() == reflect.Array && typeDesc.Len() != 2 {
+			return nil, fmt.Errorf("type conversion error from pair to '%v'", typeDesc)
+		}
+		return NewDynamicList(DefaultTypeAdapter, []ref.Val{p.first, p.second}).ConvertToNative(typeDesc)
+	case reflect.Interface:
+		if reflect.TypeOf(p).Implements(typeDesc) {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("type conversion error from pair to '%v'", typeDesc)
+}
+
+// ConvertToType implements the ref.Val interface method.
+func (p *Pair) ConvertToType(typeVal ref.Type) ref.Val {
+	switch typeVal {
+	case PairType:
+		return p
+	case TypeType:
+		return PairType
+	}
+	return NewErr("type conversion error from '%s' to '%s'", PairType, typeVal)
+}
+
+// Equal implements the ref.Val interface method.
+//
+// Pairs are equal when their corresponding elements are equal.
+func (p *Pair) Equal(other ref.Val) ref.Val {
+	otherPair, ok := other.(*Pair)
+	if !ok {
+		return False
+	}
+	if p.first.Equal(otherPair.first) != True {
+		return False
+	}
+	return Bool(p.second.Equal(otherPair.second) == True)
+}
+
+// Get implements the traits.Indexer interface method.
+func (p *Pair) Get(index ref.Val) ref.Val {
+	switch index {
+	case String("first"):
+		return p.first
+	case String("second"):
+		return p.second
+	}
+	return pairNoSuchField(index)
+}
+
+// IsSet implements the traits.FieldTester interface method.
+//
+// Both elements of a pair are always set.
+func (p *Pair) IsSet(field ref.Val) ref.Val {
+	switch field {
+	case String("first"), String("second"):
+		return True
+	}
+	return pairNoSuchField(field)
+}
+
+// String returns the pair in the form of the call to `pair` which constructs it.
+func (p *Pair) String() string {
+	return fmt.Sprintf("pair(%v, %v)", p.first, p.second)
+}
+
+// Type implements the ref.Val interface method.
+func (p *Pair) Type() ref.Type {
+	return PairType
+}
+
+// Value implements the ref.Val interface method.
+func (p *Pair) Value() any {
+	return []any{p.first.Value(), p.second.Value()}
+}
+
+func pairNoSuchField(field ref.Val) ref.Val {
+	if s, ok := field.(String); ok {
+		return NewErr("no such field '%s'", s)
+	}
+	return MaybeNoSuchOverloadErr(field)
+}
@@ -0,0 +1,75 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPairGet(t *testing.T) {
+	p := NewPair(String("count"), IntOne)
+	if p.Get(String("first")) != String("count") || p.First() != String("count") {
+		t.Errorf("p.Get('first') got %v, wanted 'count'", p.Get(String("first")))
+	}
+	if p.Get(String("second")) != IntOne || p.Second() != IntOne {
+		t.Errorf("p.Get('second') got %v, wanted 1", p.Get(String("second")))
+	}
+	if !IsError(p.Get(String("third"))) {
+		t.Errorf("p.Get('third') got %v, wanted error", p.Get(String("third")))
+	}
+	if p.IsSet(String("first")) != True {
+		t.Errorf("p.IsSet('first') got %v, wanted true", p.IsSet(String("first")))
+	}
+	if !IsError(p.IsSet(IntOne)) {
+		t.Errorf("p.IsSet(1) got %v, wanted error", p.IsSet(IntOne))
+	}
+}
+
+func TestPairEqual(t *testing.T) {
+	p := NewPair(String("count"), IntOne)
+	if p.Equal(NewPair(String("count"), Double(1))) != True {
+		t.Error("pair('count', 1) != pair('count', 1.0), wanted equal")
+	}
+	if p.Equal(NewPair(String("count"), IntZero)) != False {
+		t.Error("pair('count', 1) == pair('count', 0), wanted not equal")
+	}
+	if p.Equal(NewStringList(DefaultTypeAdapter, []string{"count", "1"})) != False {
+		t.Error("pair('count', 1) == ['count', '1'], wanted not equal")
+	}
+}
+
+func TestPairConvert(t *testing.T) {
+	p := NewPair(String("count"), IntOne)
+	out, err := p.ConvertToNative(reflect.TypeOf([]any{}))
+	if err != nil {
+		t.Fatalf("p.ConvertToNative([]any) failed: %v", err)
+	}
+	if !reflect.DeepEqual(out, []any{"count", int64(1)}) {
+		t.Errorf("p.ConvertToNative([]any) got %v, wanted ['count', 1]", out)
+	}
+	if _, err := p.ConvertToNative(reflect.TypeOf([3]any{})); err == nil {
+		t.Error("p.ConvertToNative([3]any) succeeded, wanted error")
+	}
+	if p.ConvertToType(TypeType) != PairType {
+		t.Errorf("p.ConvertToType(TypeType) got %v, wanted pair", p.ConvertToType(TypeType))
+	}
+	if !IsError(p.ConvertToType(ListType)) {
+		t.Errorf("p.ConvertToType(ListType) got %v, wanted error", p.ConvertToType(ListType))
+	}
+	if p.String() != "pair(count, 1)" {
+		t.Errorf("p.String() got %q, wanted 'pair(count, 1)'", p.String())
+	}
+}
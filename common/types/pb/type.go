@@ -17,9 +17,13 @@ package pb
 import (
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
 
 	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 	dynamicpb "google.golang.org/protobuf/types/dynamicpb"
@@ -171,13 +175,96 @@ func newFieldDescription(fieldDesc protoreflect.FieldDescriptor) *FieldDescripti
 		keyType = newFieldDescription(fieldDesc.MapKey())
 		valType = newFieldDescription(fieldDesc.MapValue())
 	}
-	return &FieldDescription{
+	fd := &FieldDescription{
 		desc:        fieldDesc,
 		KeyType:     keyType,
 		ValueType:   valType,
 		reflectType: reflectType,
 		zeroMsg:     zeroValueOf(zeroMsg),
 	}
+	fd.getter = newFieldGetter(fd)
+	return fd
+}
+
+// fieldGetter returns the value of a field from a message using a field descriptor for the field
+// which is declared on the message's descriptor.
+type fieldGetter func(msg protoreflect.Message, field protoreflect.FieldDescriptor) (any, error)
+
+// newFieldGetter returns a fieldGetter specialized to the kind of the field, which avoids the
+// boxing of the field value as a protoreflect.Value interface and the subsequent inspection of its
+// type on every access.
+func newFieldGetter(fd *FieldDescription) fieldGetter {
+	desc := fd.desc
+	switch {
+	case desc.IsMap():
+		return func(msg protoreflect.Message, field protoreflect.FieldDescriptor) (any, error) {
+			return &Map{Map: msg.Get(field).Map(), KeyType: fd.KeyType, ValueType: fd.ValueType}, nil
+		}
+	case desc.IsList():
+		return func(msg protoreflect.Message, field protoreflect.FieldDescriptor) (any, error) {
+			return msg.Get(field).List(), nil
+		}
+	}
+	switch desc.Kind() {
+	case protoreflect.BoolKind:
+		return func(msg protoreflect.Message, field protoreflect.FieldDescriptor) (any, error) {
+			return msg.Get(field).Bool(), nil
+		}
+	case protoreflect.BytesKind:
+		return func(msg protoreflect.Message, field protoreflect.FieldDescriptor) (any, error) {
+			return msg.Get(field).Bytes(), nil
+		}
+	case protoreflect.DoubleKind:
+		return func(msg protoreflect.Message, field protoreflect.FieldDescriptor) (any, error) {
+			return msg.Get(field).Float(), nil
+		}
+	case protoreflect.FloatKind:
+		return func(msg protoreflect.Message, field protoreflect.FieldDescriptor) (any, error) {
+			return float32(msg.Get(field).Float()), nil
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return func(msg protoreflect.Message, field protoreflect.FieldDescriptor) (any, error) {
+			return int32(msg.Get(field).Int()), nil
+		}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return func(msg protoreflect.Message, field protoreflect.FieldDescriptor) (any, error) {
+			return msg.Get(field).Int(), nil
+		}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return func(msg protoreflect.Message, field protoreflect.FieldDescriptor) (any, error) {
+			return uint32(msg.Get(field).Uint()), nil
+		}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return func(msg protoreflect.Message, field protoreflect.FieldDescriptor) (any, error) {
+			return msg.Get(field).Uint(), nil
+		}
+	case protoreflect.StringKind:
+		return func(msg protoreflect.Message, field protoreflect.FieldDescriptor) (any, error) {
+			return msg.Get(field).String(), nil
+		}
+	case protoreflect.EnumKind:
+		return func(msg protoreflect.Message, field protoreflect.FieldDescriptor) (any, error) {
+			return int64(msg.Get(field).Enum()), nil
+		}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if _, isWellKnown := CheckedWellKnowns[string(desc.Message().FullName())]; isWellKnown {
+			return func(msg protoreflect.Message, field protoreflect.FieldDescriptor) (any, error) {
+				// Make sure to unwrap well-known protobuf types before returning.
+				unwrapped, _, err := fd.MaybeUnwrapDynamic(msg.Get(field).Message())
+				return unwrapped, err
+			}
+		}
+		return func(msg protoreflect.Message, field protoreflect.FieldDescriptor) (any, error) {
+			fieldMsg := msg.Get(field).Message()
+			if !fieldMsg.IsValid() {
+				return fd.Zero(), nil
+			}
+			return fieldMsg.Interface(), nil
+		}
+	}
+	return func(msg protoreflect.Message, field protoreflect.FieldDescriptor) (any, error) {
+		return msg.Get(field).Interface(), nil
+	}
 }
 
 // FieldDescription holds metadata related to fields declared within a type.
@@ -190,6 +277,10 @@ type FieldDescription struct {
 	desc        protoreflect.FieldDescriptor
 	reflectType reflect.Type
 	zeroMsg     proto.Message
+	getter      fieldGetter
+
+	structGetterOnce sync.Once
+	structGetter     func(target any) (any, error)
 }
 
 // CheckedType returns the type-definition used at type-check time.
@@ -235,7 +326,8 @@ func (fd *FieldDescription) IsSet(target any) bool {
 		}
 		// Otherwise, fallback to a dynamic lookup of the field descriptor from the target
 		// instance as an attempt to use the cached field descriptor will result in a panic.
-		return pbRef.Has(pbDesc.Fields().ByName(protoreflect.Name(fd.Name())))
+		field := pbDesc.Fields().ByName(protoreflect.Name(fd.Name()))
+		return field != nil && pbRef.Has(field)
 	default:
 		return false
 	}
@@ -254,33 +346,103 @@ func (fd *FieldDescription) GetFrom(target any) (any, error) {
 	}
 	pbRef := v.ProtoReflect()
 	pbDesc := pbRef.Descriptor()
-	var fieldVal any
 	if pbDesc == fd.desc.ContainingMessage() {
 		// When the target protobuf shares the same message descriptor instance as the field
 		// descriptor, use the cached field descriptor value.
-		fieldVal = pbRef.Get(fd.desc).Interface()
-	} else {
-		// Otherwise, fallback to a dynamic lookup of the field descriptor from the target
-		// instance as an attempt to use the cached field descriptor will result in a panic.
-		fieldVal = pbRef.Get(pbDesc.Fields().ByName(protoreflect.Name(fd.Name()))).Interface()
-	}
-	switch fv := fieldVal.(type) {
-	// Fast-path return for primitive types.
-	case bool, []byte, float32, float64, int32, int64, string, uint32, uint64, protoreflect.List:
-		return fv, nil
-	case protoreflect.EnumNumber:
-		return int64(fv), nil
-	case protoreflect.Map:
-		// Return a wrapper around the protobuf-reflected Map types which carries additional
-		// information about the key and value definitions of the map.
-		return &Map{Map: fv, KeyType: fd.KeyType, ValueType: fd.ValueType}, nil
-	case protoreflect.Message:
-		// Make sure to unwrap well-known protobuf types before returning.
-		unwrapped, _, err := fd.MaybeUnwrapDynamic(fv)
-		return unwrapped, err
+		return fd.getter(pbRef, fd.desc)
+	}
+	// Otherwise, fallback to a dynamic lookup of the field descriptor from the target
+	// instance as an attempt to use the cached field descriptor will result in a panic.
+	field := pbDesc.Fields().ByName(protoreflect.Name(fd.Name()))
+	if field == nil {
+		return nil, fmt.Errorf("no such field '%s'", fd.Name())
+	}
+	return fd.getter(pbRef, field)
+}
+
+// PlannedGetter returns a getter for the field which is intended to be resolved once, when a
+// program which selects the field from a statically typed message is planned.
+//
+// When the containing message has a generated Go type, the getter reads scalar fields without
+// explicit presence directly from the Go struct, avoiding protoreflect.Message.Get. The getter
+// falls back to GetFrom for other fields and for other implementations of the message, such as
+// dynamicpb messages.
+func (fd *FieldDescription) PlannedGetter() func(target any) (any, error) {
+	fd.structGetterOnce.Do(func() {
+		fd.structGetter = fd.newStructGetter()
+	})
+	return fd.structGetter
+}
+
+// newStructGetter returns a getter which reads the field from the Go struct of the generated
+// message type, or GetFrom if the field cannot be read this way.
+func (fd *FieldDescription) newStructGetter() func(target any) (any, error) {
+	desc := fd.desc
+	if desc.IsList() || desc.IsMap() || desc.IsExtension() || desc.HasPresence() ||
+		desc.ContainingOneof() != nil {
+		return fd.GetFrom
+	}
+	var read func(f reflect.Value) any
+	switch desc.Kind() {
+	case protoreflect.BoolKind:
+		read = func(f reflect.Value) any { return f.Bool() }
+	case protoreflect.BytesKind:
+		read = func(f reflect.Value) any { return f.Bytes() }
+	case protoreflect.DoubleKind:
+		read = func(f reflect.Value) any { return f.Float() }
+	case protoreflect.FloatKind:
+		read = func(f reflect.Value) any { return float32(f.Float()) }
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		read = func(f reflect.Value) any { return int32(f.Int()) }
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.EnumKind:
+		read = func(f reflect.Value) any { return f.Int() }
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		read = func(f reflect.Value) any { return uint32(f.Uint()) }
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		read = func(f reflect.Value) any { return f.Uint() }
+	case protoreflect.StringKind:
+		read = func(f reflect.Value) any { return f.String() }
 	default:
-		return fv, nil
+		return fd.GetFrom
+	}
+	msgType, err := protoregistry.GlobalTypes.FindMessageByName(desc.ContainingMessage().FullName())
+	if err != nil || msgType.Descriptor() != desc.ContainingMessage() {
+		return fd.GetFrom
+	}
+	goType := reflect.TypeOf(msgType.Zero().Interface())
+	if goType.Kind() != reflect.Ptr || goType.Elem().Kind() != reflect.Struct {
+		return fd.GetFrom
+	}
+	index, found := structFieldIndex(goType.Elem(), desc.Number())
+	if !found {
+		return fd.GetFrom
+	}
+	return func(target any) (any, error) {
+		if reflect.TypeOf(target) != goType {
+			return fd.GetFrom(target)
+		}
+		v := reflect.ValueOf(target)
+		if v.IsNil() {
+			return fd.GetFrom(target)
+		}
+		return read(v.Elem().Field(index)), nil
+	}
+}
+
+// structFieldIndex returns the index of the field of a generated message struct whose protobuf
+// struct tag declares the given field number, e.g. `protobuf:"varint,1,opt,name=single_int32"`.
+func structFieldIndex(t reflect.Type, num protoreflect.FieldNumber) (int, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		parts := strings.Split(t.Field(i).Tag.Get("protobuf"), ",")
+		if len(parts) < 2 {
+			continue
+		}
+		if n, err := strconv.Atoi(parts[1]); err == nil && protoreflect.FieldNumber(n) == num {
+			return i, true
+		}
 	}
+	return 0, false
 }
 
 // IsEnum returns true if the field type refers to an enum value.
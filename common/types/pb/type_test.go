@@ -21,6 +21,7 @@ import (
 
 	"github.com/google/cel-go/checker/decls"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 
 	proto2pb "github.com/google/cel-go/test/proto2pb"
 	proto3pb "github.com/google/cel-go/test/proto3pb"
@@ -210,6 +211,98 @@ func TestFieldDescriptionGetFrom(t *testing.T) {
 	}
 }
 
+func TestFieldDescriptionGetFromAllFields(t *testing.T) {
+	pbdb := NewDb()
+	msg := &proto3pb.TestAllTypes{
+		SingleInt32:         -1,
+		SingleInt64:         -2,
+		SingleUint32:        3,
+		SingleUint64:        4,
+		SingleSint32:        -5,
+		SingleSint64:        -6,
+		SingleFixed32:       7,
+		SingleFixed64:       8,
+		SingleSfixed32:      -9,
+		SingleSfixed64:      -10,
+		SingleFloat:         1.5,
+		SingleDouble:        -2.5,
+		SingleBool:          true,
+		SingleString:        "hello",
+		SingleBytes:         []byte("world"),
+		SingleAny:           mustAny(t, wrapperspb.String("packed")),
+		SingleUint32Wrapper: wrapperspb.UInt32(11),
+		RepeatedInt32:       []int32{1, 2},
+		RepeatedString:      []string{"a", "b"},
+		MapStringString:     map[string]string{"key": "value"},
+		StandaloneEnum:      proto3pb.TestAllTypes_BAZ,
+		NestedType: &proto3pb.TestAllTypes_SingleNestedMessage{
+			SingleNestedMessage: &proto3pb.TestAllTypes_NestedMessage{Bb: 7},
+		},
+	}
+	msgName := string(msg.ProtoReflect().Descriptor().FullName())
+	if _, err := pbdb.RegisterMessage(msg); err != nil {
+		t.Fatalf("pbdb.RegisterMessage(%q) failed: %v", msgName, err)
+	}
+	td, found := pbdb.DescribeType(msgName)
+	if !found {
+		t.Fatalf("pbdb.DescribeType(%q) not found", msgName)
+	}
+	dynMsg := dynamicpb.NewMessage(msg.ProtoReflect().Descriptor())
+	proto.Merge(dynMsg, msg)
+	for _, target := range []proto.Message{msg, dynMsg} {
+		for name, f := range td.FieldMap() {
+			got, err := f.GetFrom(target)
+			if err != nil {
+				t.Fatalf("field %s GetFrom() failed: %v", name, err)
+			}
+			want, err := reflectedFieldValue(f, target.ProtoReflect())
+			if err != nil {
+				t.Fatalf("field %s reflectedFieldValue() failed: %v", name, err)
+			}
+			planned, err := f.PlannedGetter()(target)
+			if err != nil {
+				t.Fatalf("field %s PlannedGetter() failed: %v", name, err)
+			}
+			for _, g := range []any{got, planned} {
+				switch g := g.(type) {
+				case proto.Message:
+					if !proto.Equal(g, want.(proto.Message)) {
+						t.Errorf("got field %s value %v, wanted %v", name, g, want)
+					}
+				default:
+					if !reflect.DeepEqual(g, want) {
+						t.Errorf("got field %s value (%T)%v, wanted (%T)%v", name, g, g, want, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+// reflectedFieldValue returns the value of the field using generic protobuf reflection.
+func reflectedFieldValue(fd *FieldDescription, msg protoreflect.Message) (any, error) {
+	switch fv := msg.Get(fd.Descriptor()).Interface().(type) {
+	case protoreflect.EnumNumber:
+		return int64(fv), nil
+	case protoreflect.Map:
+		return &Map{Map: fv, KeyType: fd.KeyType, ValueType: fd.ValueType}, nil
+	case protoreflect.Message:
+		unwrapped, _, err := fd.MaybeUnwrapDynamic(fv)
+		return unwrapped, err
+	default:
+		return fv, nil
+	}
+}
+
+func mustAny(t *testing.T, msg proto.Message) *anypb.Any {
+	t.Helper()
+	a, err := anypb.New(msg)
+	if err != nil {
+		t.Fatalf("anypb.New() failed: %v", err)
+	}
+	return a
+}
+
 func TestFieldDescriptionIsSet(t *testing.T) {
 	pbdb := NewDb()
 	msg := &proto3pb.TestAllTypes{}
@@ -17,6 +17,7 @@ package types
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"time"
 
 	"google.golang.org/protobuf/proto"
@@ -117,10 +118,12 @@ func (p *protoTypeRegistry) FindFieldType(messageType string,
 	if !found {
 		return nil, false
 	}
+	// The field type is resolved when planning the selection of a field from a statically typed
+	// message, so the getter is specialized to the generated Go type of the message.
 	return &ref.FieldType{
 			Type:    field.CheckedType(),
 			IsSet:   field.IsSet,
-			GetFrom: field.GetFrom},
+			GetFrom: field.PlannedGetter()},
 		true
 }
 
@@ -148,6 +151,16 @@ func (p *protoTypeRegistry) FindType(typeName string) (*exprpb.Type, bool) {
 					MessageType: typeName}}}}, true
 }
 
+// TypeNames returns the sorted, qualified names of the message types known to the registry.
+func (p *protoTypeRegistry) TypeNames() []string {
+	var names []string
+	for _, fd := range p.pbdb.FileDescriptions() {
+		names = append(names, fd.GetTypeNames()...)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (p *protoTypeRegistry) NewValue(typeName string, fields map[string]ref.Val) ref.Val {
 	td, found := p.pbdb.DescribeType(typeName)
 	if !found {
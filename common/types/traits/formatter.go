@@ -0,0 +1,25 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package traits
+
+// Formatter interface for types which supply their own representation within formatted strings,
+// such as those produced by the `string.format` function.
+type Formatter interface {
+	// Format returns the representation of the value for the formatting clause, written without
+	// the leading '%', e.g. `s`, `d`, `.2f`, or `x`, within the given locale.
+	//
+	// Types which do not support the clause return an error.
+	Format(clause string, locale string) (string, error)
+}
@@ -0,0 +1,26 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package traits
+
+// Hashable interface for values which may be used as the keys of maps although they cannot be used
+// as the keys of Go maps, such as bytes.
+type Hashable interface {
+	// HashKey returns a comparable value which identifies the value among map keys, such that two
+	// values are equal if and only if their hash keys are equal.
+	//
+	// The hash keys of values of different types must not be equal, which may be ensured by
+	// returning a type private to the implementation.
+	HashKey() any
+}
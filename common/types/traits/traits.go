@@ -61,4 +61,10 @@ const (
 
 	// SubtractorType type support '-' operations.
 	SubtractorType
+
+	// FormatterType types supply their own representation within formatted strings.
+	FormatterType
+
+	// HashableType types may be used as map keys via their hash keys.
+	HashableType
 )
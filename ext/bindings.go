@@ -15,6 +15,8 @@
 package ext
 
 import (
+	"fmt"
+
 	"github.com/google/cel-go/cel"
 	"github.com/google/cel-go/common"
 
@@ -42,6 +44,10 @@ import (
 //	[d, e, f].exists(elem, elem in valid_values))
 //
 // Local bindings are not guaranteed to be evaluated before use.
+//
+// Bindings which are never referenced by their result expression are reported as warnings by
+// cel.Ast.Warnings when the expression is type-checked, since unused bindings still inflate the
+// estimated cost of the expression.
 func Bindings() cel.EnvOption {
 	return cel.Lib(celBindings{})
 }
@@ -64,6 +70,7 @@ func (celBindings) CompileOptions() []cel.EnvOption {
 			// cel.bind(var, <init>, <expr>)
 			cel.NewReceiverMacro(bindMacro, 3, celBind),
 		),
+		cel.Linters(unusedBindings),
 	}
 }
 
@@ -98,3 +105,94 @@ func celBind(meh cel.MacroExprHelper, target *exprpb.Expr, args []*exprpb.Expr)
 		resultExpr,
 	), nil
 }
+
+// unusedBindings reports the cel.bind() variables which are not referenced by the result
+// expression of their binding, at the location of the variable name when the macro calls of the
+// expression are available and at the location of the binding otherwise.
+func unusedBindings(ast *cel.Ast) []cel.Warning {
+	var warnings []cel.Warning
+	var visit func(e *exprpb.Expr)
+	visit = func(e *exprpb.Expr) {
+		defer forEachChild(e, visit)
+		comp := e.GetComprehensionExpr()
+		if comp == nil || comp.GetIterVar() != unusedIterVar {
+			return
+		}
+		name := comp.GetAccuVar()
+		if referencesVar(comp.GetResult(), name) {
+			return
+		}
+		id := e.GetId()
+		if call, found := ast.SourceInfo().GetMacroCalls()[id]; found && len(call.GetCallExpr().GetArgs()) == 3 {
+			id = call.GetCallExpr().GetArgs()[0].GetId()
+		}
+		warnings = append(warnings, cel.Warning{
+			ID:      id,
+			Message: fmt.Sprintf("cel.bind() variable '%s' is never used", name),
+		})
+	}
+	visit(ast.Expr())
+	return warnings
+}
+
+// referencesVar returns whether the expression refers to the local variable, accounting for the
+// comprehension variables which shadow it.
+func referencesVar(e *exprpb.Expr, name string) bool {
+	if e == nil {
+		return false
+	}
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		return e.GetIdentExpr().GetName() == name
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		if referencesVar(comp.GetIterRange(), name) || referencesVar(comp.GetAccuInit(), name) {
+			return true
+		}
+		if comp.GetIterVar() == name || comp.GetAccuVar() == name {
+			return false
+		}
+		return referencesVar(comp.GetLoopCondition(), name) ||
+			referencesVar(comp.GetLoopStep(), name) ||
+			referencesVar(comp.GetResult(), name)
+	}
+	found := false
+	forEachChild(e, func(child *exprpb.Expr) {
+		found = found || referencesVar(child, name)
+	})
+	return found
+}
+
+// forEachChild invokes the visitor on the direct subexpressions of the expression.
+func forEachChild(e *exprpb.Expr, visitor func(*exprpb.Expr)) {
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		visitor(e.GetSelectExpr().GetOperand())
+	case *exprpb.Expr_CallExpr:
+		call := e.GetCallExpr()
+		if call.GetTarget() != nil {
+			visitor(call.GetTarget())
+		}
+		for _, arg := range call.GetArgs() {
+			visitor(arg)
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range e.GetListExpr().GetElements() {
+			visitor(elem)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			if entry.GetMapKey() != nil {
+				visitor(entry.GetMapKey())
+			}
+			visitor(entry.GetValue())
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comp := e.GetComprehensionExpr()
+		visitor(comp.GetIterRange())
+		visitor(comp.GetAccuInit())
+		visitor(comp.GetLoopCondition())
+		visitor(comp.GetLoopStep())
+		visitor(comp.GetResult())
+	}
+}
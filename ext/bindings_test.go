@@ -16,6 +16,7 @@ package ext
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -100,6 +101,58 @@ func TestBindingsInvalidIdent(t *testing.T) {
 	}
 }
 
+func TestBindingsUnusedWarnings(t *testing.T) {
+	env, err := cel.NewEnv(Bindings(), Strings())
+	if err != nil {
+		t.Fatalf("cel.NewEnv(Bindings(), Strings()) failed: %v", err)
+	}
+	tests := []struct {
+		expr     string
+		warnings []string
+	}{
+		{expr: `cel.bind(a, 'hello', a + '!')`},
+		{
+			expr:     `cel.bind(a, 'hello', 'world')`,
+			warnings: []string{"1:9: cel.bind() variable 'a' is never used"},
+		},
+		{
+			// The inner binding shadows the outer one, leaving the outer binding unused.
+			expr: `cel.bind(a, 'hello',
+			       cel.bind(a, 'world', a + '!'))`,
+			warnings: []string{"1:9: cel.bind() variable 'a' is never used"},
+		},
+		{
+			// The outer binding is used to initialize the inner one.
+			expr: `cel.bind(a, 'hello',
+			       cel.bind(b, a, 'world'))`,
+			warnings: []string{"2:19: cel.bind() variable 'b' is never used"},
+		},
+		{
+			// Comprehension variables shadow bindings of the same name.
+			expr:     `cel.bind(x, 1, [2, 3].all(x, x > 1))`,
+			warnings: []string{"1:9: cel.bind() variable 'x' is never used"},
+		},
+		{expr: `cel.bind(x, 1, [2, 3].all(y, y > x))`},
+	}
+	for i, tst := range tests {
+		tc := tst
+		t.Run(fmt.Sprintf("[%d]", i), func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%v) failed: %v", tc.expr, iss.Err())
+			}
+			var got []string
+			for _, w := range ast.Warnings() {
+				got = append(got, fmt.Sprintf("%d:%d: %s",
+					w.Location.Line(), w.Location.Column()+1, w.Message))
+			}
+			if !reflect.DeepEqual(got, tc.warnings) {
+				t.Errorf("env.Compile(%v) got warnings %v, wanted %v", tc.expr, got, tc.warnings)
+			}
+		})
+	}
+}
+
 func BenchmarkBindings(b *testing.B) {
 	env, err := cel.NewEnv(Bindings(), Strings())
 	if err != nil {
@@ -0,0 +1,59 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ext
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+)
+
+// ConfigLibraries returns the cel.ConfigOption values which make the extension libraries available
+// to environment configurations under their library names, e.g. `cel.lib.ext.strings`:
+//
+//	env, err := envconfig.NewEnv(config, ext.ConfigLibraries()...)
+//
+// The strings library accepts a version, while the other libraries are unversioned.
+func ConfigLibraries() []cel.ConfigOption {
+	unversioned := func(opt cel.EnvOption) func(int) (cel.EnvOption, error) {
+		return func(version int) (cel.EnvOption, error) {
+			if version != 0 {
+				return nil, fmt.Errorf("unsupported version: %d", version)
+			}
+			return opt, nil
+		}
+	}
+	libs := map[string]func(int) (cel.EnvOption, error){
+		celBindings{}.LibraryName(): unversioned(Bindings()),
+		encoderLib{}.LibraryName():  unversioned(Encoders()),
+		errorsLib{}.LibraryName():   unversioned(Errors()),
+		listsLib{}.LibraryName():    unversioned(Lists()),
+		mapsLib{}.LibraryName():     unversioned(Maps()),
+		mathLib{}.LibraryName():     unversioned(Math()),
+		protoLib{}.LibraryName():    unversioned(Protos()),
+		randomLib{}.LibraryName():   unversioned(Random()),
+		(&stringLib{}).LibraryName(): func(version int) (cel.EnvOption, error) {
+			if version < 0 {
+				return nil, fmt.Errorf("unsupported version: %d", version)
+			}
+			return Strings(StringsVersion(uint32(version))), nil
+		},
+	}
+	opts := make([]cel.ConfigOption, 0, len(libs))
+	for name, lib := range libs {
+		opts = append(opts, cel.ConfigLibrary(name, lib))
+	}
+	return opts
+}
@@ -0,0 +1,65 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ext
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/cel/envconfig"
+	"github.com/google/cel-go/common/types"
+)
+
+func TestConfigLibraries(t *testing.T) {
+	config := `
+libraries:
+  - name: cel.lib.ext.strings
+    version: 1
+  - name: cel.lib.ext.math
+  - name: cel.lib.ext.lists
+`
+	env, err := envconfig.NewEnv([]byte(config), ConfigLibraries()...)
+	if err != nil {
+		t.Fatalf("envconfig.NewEnv() failed: %v", err)
+	}
+	expr := `'%d'.format([math.least(3, 1)]) == '1' && ['a', 'bb'].indexBy(s, s.size())[2] == 'bb'`
+	ast, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile(%q) failed: %v", expr, iss.Err())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	out, _, err := prg.Eval(cel.NoVars())
+	if err != nil || out != types.True {
+		t.Errorf("prg.Eval() got %v, %v, wanted true", out, err)
+	}
+
+	// Version 0 of the strings library predates format().
+	env, err = envconfig.NewEnv([]byte("libraries:\n  - name: cel.lib.ext.strings"), ConfigLibraries()...)
+	if err != nil {
+		t.Fatalf("envconfig.NewEnv() failed: %v", err)
+	}
+	if _, iss := env.Compile(`'%d'.format([1])`); iss.Err() == nil {
+		t.Error("env.Compile() succeeded, wanted format() to be undeclared in version 0")
+	}
+
+	_, err = envconfig.NewEnv([]byte("libraries:\n  - name: cel.lib.ext.math\n    version: 1"), ConfigLibraries()...)
+	if err == nil || !strings.Contains(err.Error(), "library cel.lib.ext.math: unsupported version: 1") {
+		t.Errorf("envconfig.NewEnv() got error %v, wanted unsupported version", err)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ext
+
+import (
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Errors returns a cel.EnvOption to configure functions which observe evaluation errors rather
+// than propagating them, so that expressions may degrade gracefully when one of several data
+// sources fails.
+//
+// # Cel.FirstNonError
+//
+// Returns the value of the first expression in the list literal which does not produce an error.
+// When every expression produces an error, the error of the last expression is returned. When an
+// expression which precedes the first successful expression is unknown, the result is unknown.
+//
+// The argument must be a non-empty list literal whose elements share a common type.
+//
+//	cel.firstNonError([<expr>, ...]) -> <T>
+//
+// Examples:
+//
+//	cel.firstNonError([1 / 0, 2])              // 2
+//	cel.firstNonError([primary.id, backup.id]) // backup.id when primary.id is an error
+//	cel.firstNonError([1 / 0])                 // division by zero error
+//	cel.firstNonError(values)                  // parse error
+//
+// # IsError
+//
+// Returns whether the value is an error. Unknown values remain unknown.
+//
+//	<T>.isError() -> <bool>
+//
+// Examples:
+//
+//	(1 / 0).isError()  // true
+//	(1 / 1).isError()  // false
+//	request.id.isError() ? 'anonymous' : request.id
+func Errors() cel.EnvOption {
+	return cel.Lib(errorsLib{})
+}
+
+const (
+	firstNonErrorMacro = "firstNonError"
+	firstNonErrorFunc  = "cel.@firstNonError"
+	isErrorFunc        = "isError"
+)
+
+type errorsLib struct{}
+
+// LibraryName implements the SingletonLibrary interface method.
+func (errorsLib) LibraryName() string {
+	return "cel.lib.ext.errors"
+}
+
+// CompileOptions implements the Library interface method.
+func (errorsLib) CompileOptions() []cel.EnvOption {
+	paramT := cel.TypeParamType("T")
+	return []cel.EnvOption{
+		cel.Macros(
+			// cel.firstNonError([<expr>, ...])
+			cel.NewReceiverMacro(firstNonErrorMacro, 1, celFirstNonError),
+		),
+		cel.Function(firstNonErrorFunc,
+			cel.Overload("cel_@firstNonError", []*cel.Type{paramT, paramT}, paramT,
+				cel.OverloadIsNonStrict(),
+				cel.BinaryBinding(firstNonError))),
+		cel.Function(isErrorFunc,
+			cel.MemberOverload("is_error", []*cel.Type{paramT}, cel.BoolType,
+				cel.OverloadIsNonStrict(),
+				cel.UnaryBinding(isError))),
+	}
+}
+
+// ProgramOptions implements the Library interface method.
+func (errorsLib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func celFirstNonError(meh cel.MacroExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+	if !macroTargetMatchesNamespace(celNamespace, target) {
+		return nil, nil
+	}
+	elems := args[0].GetListExpr().GetElements()
+	if len(elems) == 0 {
+		return nil, &common.Error{
+			Message:  "cel.firstNonError() requires a non-empty list literal",
+			Location: meh.OffsetLocation(args[0].GetId()),
+		}
+	}
+	// Fold the elements from the right: [a, b, c] -> @firstNonError(a, @firstNonError(b, c))
+	out := elems[len(elems)-1]
+	for i := len(elems) - 2; i >= 0; i-- {
+		out = meh.GlobalCall(firstNonErrorFunc, elems[i], out)
+	}
+	return out, nil
+}
+
+func firstNonError(first, second ref.Val) ref.Val {
+	if types.IsError(first) {
+		return second
+	}
+	return first
+}
+
+func isError(val ref.Val) ref.Val {
+	if types.IsUnknown(val) {
+		return val
+	}
+	return types.Bool(types.IsError(val))
+}
@@ -0,0 +1,125 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ext
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+)
+
+var errorsTests = []struct {
+	expr string
+	in   map[string]any
+}{
+	{expr: `cel.firstNonError([1 / 0, 2]) == 2`},
+	{expr: `cel.firstNonError([1, 1 / 0]) == 1`},
+	{expr: `cel.firstNonError([1 / 0, 2 % 0, 3]) == 3`},
+	{expr: `cel.firstNonError([m.primary, m.backup, 'default']) == 'backup'`,
+		in: map[string]any{"m": map[string]string{"backup": "backup"}}},
+	{expr: `cel.firstNonError([1 / 0]).isError()`},
+	{expr: `cel.firstNonError([1 / 0, 2 / 0]).isError()`},
+	{expr: `(1 / 0).isError() && !(1 / 1).isError()`},
+	{expr: `(m.missing.isError() ? 'anonymous' : m.missing) == 'anonymous'`,
+		in: map[string]any{"m": map[string]string{}}},
+}
+
+func TestErrors(t *testing.T) {
+	env, err := cel.NewEnv(Errors(),
+		cel.Variable("m", cel.MapType(cel.StringType, cel.StringType)))
+	if err != nil {
+		t.Fatalf("cel.NewEnv(Errors()) failed: %v", err)
+	}
+	for i, tst := range errorsTests {
+		tc := tst
+		t.Run(fmt.Sprintf("[%d]", i), func(t *testing.T) {
+			var asts []*cel.Ast
+			pAst, iss := env.Parse(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Parse(%v) failed: %v", tc.expr, iss.Err())
+			}
+			asts = append(asts, pAst)
+			cAst, iss := env.Check(pAst)
+			if iss.Err() != nil {
+				t.Fatalf("env.Check(%v) failed: %v", tc.expr, iss.Err())
+			}
+			asts = append(asts, cAst)
+			for _, ast := range asts {
+				for _, opt := range []cel.EvalOption{cel.OptOptimize, cel.OptExhaustiveEval} {
+					prg, err := env.Program(ast, cel.EvalOptions(opt))
+					if err != nil {
+						t.Fatal(err)
+					}
+					in := tc.in
+					if in == nil {
+						in = map[string]any{}
+					}
+					out, _, err := prg.Eval(in)
+					if err != nil {
+						t.Fatal(err)
+					} else if out.Value() != true {
+						t.Errorf("got %v, wanted true for expr: %s", out.Value(), tc.expr)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestErrorsUnknowns(t *testing.T) {
+	env, err := cel.NewEnv(Errors(), cel.Variable("x", cel.IntType))
+	if err != nil {
+		t.Fatalf("cel.NewEnv(Errors()) failed: %v", err)
+	}
+	for _, expr := range []string{`cel.firstNonError([x, 1])`, `x.isError()`} {
+		ast, iss := env.Compile(expr)
+		if iss.Err() != nil {
+			t.Fatalf("env.Compile(%v) failed: %v", expr, iss.Err())
+		}
+		prg, err := env.Program(ast, cel.EvalOptions(cel.OptPartialEval))
+		if err != nil {
+			t.Fatal(err)
+		}
+		vars, _ := cel.PartialVars(map[string]any{}, cel.AttributePattern("x"))
+		out, _, err := prg.Eval(vars)
+		if err != nil || !types.IsUnknown(out) {
+			t.Errorf("prg.Eval(%v) got %v, %v, wanted unknown", expr, out, err)
+		}
+	}
+}
+
+func TestErrorsStaticErrors(t *testing.T) {
+	env, err := cel.NewEnv(Errors(), cel.Variable("values", cel.ListType(cel.IntType)))
+	if err != nil {
+		t.Fatalf("cel.NewEnv(Errors()) failed: %v", err)
+	}
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{expr: `cel.firstNonError([])`, err: "cel.firstNonError() requires a non-empty list literal"},
+		{expr: `cel.firstNonError(values)`, err: "cel.firstNonError() requires a non-empty list literal"},
+		{expr: `cel.firstNonError([1, 'a'])`, err: "found no matching overload for 'cel.@firstNonError'"},
+	}
+	for _, tc := range tests {
+		_, iss := env.Compile(tc.expr)
+		if iss.Err() == nil || !strings.Contains(iss.Err().Error(), tc.err) {
+			t.Errorf("env.Compile(%v) got %v, wanted error containing %q", tc.expr, iss.Err(), tc.err)
+		}
+	}
+}
@@ -0,0 +1,394 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ext
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Lists returns a cel.EnvOption to configure macros which build maps from lists, and functions
+// which slice lists.
+//
+// # GroupBy
+//
+// Groups the elements of a list by the key computed for each element, producing a map from each
+// key to the list of elements with that key, in list order.
+//
+//	<list(V)>.groupBy(<varName>, <keyExpr>) -> <map(K, list(V))>
+//
+// Examples:
+//
+//	[1, 2, 3, 4].groupBy(x, x % 2 == 0) // {false: [1, 3], true: [2, 4]}
+//	users.groupBy(u, u.team)            // map(string, list(User)) when u.team is a string
+//
+// # IndexBy
+//
+// Indexes the elements of a list by the key computed for each element, producing a map from each
+// key to the element with that key. When several elements share a key, the last one is retained.
+//
+//	<list(V)>.indexBy(<varName>, <keyExpr>) -> <map(K, V)>
+//
+// Examples:
+//
+//	['apple', 'banana'].indexBy(s, s.size()) // {5: 'apple', 6: 'banana'}
+//	users.indexBy(u, u.id)                   // map(string, User) when u.id is a string
+//
+// The key and value types of the result are inferred by the type-checker from the key expression
+// and the list element type. Keys must be bool, int, uint, string, or bytes values, and keys of
+// other types are rejected by the type-checker. Keys of type dyn may also be values which
+// implement traits.Hashable.
+//
+// # Slice
+//
+// Returns the elements of a list from the start index up to, but excluding, the end index, taking
+// every step-th element when a step is given. The end index defaults to the size of the list, and
+// the step defaults to 1. As in Python, negative indices count back from the end of the list,
+// indices beyond the bounds of the list are clamped to them, and a negative step takes the
+// elements in reverse order from the start index down to the end index. A step of zero is an
+// error. Null indices take their defaults in the direction of the step.
+//
+//	<list(T)>.slice(<int>) -> <list(T)>
+//	<list(T)>.slice(<int>, <int>) -> <list(T)>
+//	<list(T)>.slice(<int>, <int>, <int>) -> <list(T)>
+//	<list(T)>.slice(null, <int>, <int>) -> <list(T)>
+//	<list(T)>.slice(<int>, null, <int>) -> <list(T)>
+//	<list(T)>.slice(null, null, <int>) -> <list(T)>
+//
+// Examples:
+//
+//	[1, 2, 3, 4, 5].slice(1, 3)       // [2, 3]
+//	[1, 2, 3, 4, 5].slice(-2)         // [4, 5]
+//	[1, 2, 3, 4, 5].slice(0, 5, 2)    // [1, 3, 5]
+//	[1, 2, 3, 4, 5].slice(-1, -6, -1) // [5, 4, 3, 2, 1]
+//
+// Lists may also be sliced with the index syntax, `x[a:b]` and `x[a:b:c]`, where any bound may be
+// omitted:
+//
+//	[1, 2, 3, 4, 5][1:3]      // [2, 3]
+//	[1, 2, 3, 4, 5][-2:]      // [4, 5]
+//	[1, 2, 3, 4, 5][:2]       // [1, 2]
+//	[1, 2, 3, 4, 5][-1:-6:-1] // [5, 4, 3, 2, 1]
+//	[1, 2, 3, 4, 5][::-1]     // [5, 4, 3, 2, 1]
+//
+// Both macros accumulate their results into a native map which is updated in place, so building
+// the map is linear in the size of the list rather than quadratic as when the map is rebuilt by a
+// comprehension for each element.
+func Lists() cel.EnvOption {
+	return cel.Lib(listsLib{})
+}
+
+const (
+	groupByMacro = "groupBy"
+	indexByMacro = "indexBy"
+
+	groupByStepFunc   = "@groupBy_step"
+	groupByResultFunc = "@groupBy_result"
+	indexByStepFunc   = "@indexBy_step"
+	indexByResultFunc = "@indexBy_result"
+	groupByKeyFunc    = "@groupBy_key"
+	indexByKeyFunc    = "@indexBy_key"
+
+	sliceFunc = "slice"
+)
+
+type listsLib struct{}
+
+// LibraryName implements the SingletonLibrary interface method.
+func (listsLib) LibraryName() string {
+	return "cel.lib.ext.lists"
+}
+
+// CompileOptions implements the Library interface method.
+func (listsLib) CompileOptions() []cel.EnvOption {
+	keyType := cel.TypeParamType("K")
+	valType := cel.TypeParamType("V")
+	groupsType := cel.MapType(keyType, cel.ListType(valType))
+	indexType := cel.MapType(keyType, valType)
+	return []cel.EnvOption{
+		cel.FoldMacro(cel.FoldDef{
+			Name:      groupByMacro,
+			ArgCount:  1,
+			RangeType: cel.ListType(valType),
+			AccuType:  groupsType,
+			Init:      newMapAccumulator,
+			Step: func(meh cel.MacroExprHelper, accu, iterVar *exprpb.Expr, args []*exprpb.Expr) *exprpb.Expr {
+				return meh.GlobalCall(groupByStepFunc, accu, meh.GlobalCall(groupByKeyFunc, args[0]), iterVar)
+			},
+			Result: func(meh cel.MacroExprHelper, accu *exprpb.Expr, args []*exprpb.Expr) *exprpb.Expr {
+				return meh.GlobalCall(groupByResultFunc, accu)
+			},
+		}),
+		cel.FoldMacro(cel.FoldDef{
+			Name:      indexByMacro,
+			ArgCount:  1,
+			RangeType: cel.ListType(valType),
+			AccuType:  indexType,
+			Init:      newMapAccumulator,
+			Step: func(meh cel.MacroExprHelper, accu, iterVar *exprpb.Expr, args []*exprpb.Expr) *exprpb.Expr {
+				return meh.GlobalCall(indexByStepFunc, accu, meh.GlobalCall(indexByKeyFunc, args[0]), iterVar)
+			},
+			Result: func(meh cel.MacroExprHelper, accu *exprpb.Expr, args []*exprpb.Expr) *exprpb.Expr {
+				return meh.GlobalCall(indexByResultFunc, accu)
+			},
+		}),
+		cel.SliceSyntax(),
+		cel.Function(sliceFunc,
+			cel.MemberOverload("list_slice_int", []*cel.Type{cel.ListType(valType), cel.IntType}, cel.ListType(valType),
+				cel.BinaryBinding(func(list, start ref.Val) ref.Val {
+					return sliceList(list, start, nil, types.IntOne)
+				})),
+			cel.MemberOverload("list_slice_int_int", []*cel.Type{cel.ListType(valType), cel.IntType, cel.IntType}, cel.ListType(valType),
+				cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+					return sliceList(args[0], args[1], args[2], types.IntOne)
+				})),
+			cel.MemberOverload("list_slice_int_int_int", []*cel.Type{cel.ListType(valType), cel.IntType, cel.IntType, cel.IntType}, cel.ListType(valType),
+				cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+					return sliceList(args[0], args[1], args[2], args[3])
+				})),
+			// Slices with a step, `x[a:b:c]`, pass null for their omitted bounds.
+			cel.MemberOverload("list_slice_null_int_int", []*cel.Type{cel.ListType(valType), cel.NullType, cel.IntType, cel.IntType}, cel.ListType(valType),
+				cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+					return sliceList(args[0], args[1], args[2], args[3])
+				})),
+			cel.MemberOverload("list_slice_int_null_int", []*cel.Type{cel.ListType(valType), cel.IntType, cel.NullType, cel.IntType}, cel.ListType(valType),
+				cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+					return sliceList(args[0], args[1], args[2], args[3])
+				})),
+			cel.MemberOverload("list_slice_null_null_int", []*cel.Type{cel.ListType(valType), cel.NullType, cel.NullType, cel.IntType}, cel.ListType(valType),
+				cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+					return sliceList(args[0], args[1], args[2], args[3])
+				}))),
+		// The keys are passed through identity functions which are only declared for the types
+		// which may be used as map keys, so that keys of other types are rejected by the
+		// type-checker with an error which refers to the macro. Keys of type dyn match every
+		// overload, and are checked by the step.
+		mapKeyFunction(groupByKeyFunc, groupByMacro),
+		mapKeyFunction(indexByKeyFunc, indexByMacro),
+		// The accumulator is not a map at runtime, so the internal functions use singleton
+		// bindings which do not guard the runtime types of their arguments.
+		cel.Function(groupByStepFunc,
+			cel.Overload("groupBy_step_map_K_list_V_K_V", []*cel.Type{groupsType, keyType, valType}, groupsType),
+			cel.SingletonFunctionBinding(func(args ...ref.Val) ref.Val {
+				return accumulate(args[0], args[1], args[2], true)
+			})),
+		cel.Function(groupByResultFunc,
+			cel.Overload("groupBy_result_map_K_list_V", []*cel.Type{groupsType}, groupsType),
+			cel.SingletonUnaryBinding(accumulatedMap)),
+		cel.Function(indexByStepFunc,
+			cel.Overload("indexBy_step_map_K_V_K_V", []*cel.Type{indexType, keyType, valType}, indexType),
+			cel.SingletonFunctionBinding(func(args ...ref.Val) ref.Val {
+				return accumulate(args[0], args[1], args[2], false)
+			})),
+		cel.Function(indexByResultFunc,
+			cel.Overload("indexBy_result_map_K_V", []*cel.Type{indexType}, indexType),
+			cel.SingletonUnaryBinding(accumulatedMap)),
+	}
+}
+
+// mapKeyFunction declares the identity function which types the keys computed by a macro.
+func mapKeyFunction(name, macro string) cel.EnvOption {
+	return cel.Function(name,
+		cel.Overload(name+"_bool", []*cel.Type{cel.BoolType}, cel.BoolType),
+		cel.Overload(name+"_int", []*cel.Type{cel.IntType}, cel.IntType),
+		cel.Overload(name+"_uint", []*cel.Type{cel.UintType}, cel.UintType),
+		cel.Overload(name+"_string", []*cel.Type{cel.StringType}, cel.StringType),
+		cel.Overload(name+"_bytes", []*cel.Type{cel.BytesType}, cel.BytesType),
+		cel.SingletonUnaryBinding(func(key ref.Val) ref.Val {
+			return key
+		}),
+		cel.DisplayName(macro))
+}
+
+// ProgramOptions implements the Library interface method.
+func (listsLib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+// sliceList returns the elements of the list selected by the slice bounds. Omitted bounds are nil
+// or null.
+func sliceList(list, start, end, step ref.Val) ref.Val {
+	l := list.(traits.Lister)
+	indices, err := sliceIndices(int64(l.Size().(types.Int)), start, end, step)
+	if err != nil {
+		return types.NewErr(err.Error())
+	}
+	elems := make([]ref.Val, len(indices))
+	for i, idx := range indices {
+		elems[i] = l.Get(types.Int(idx))
+	}
+	return types.NewRefValList(types.DefaultTypeAdapter, elems)
+}
+
+// sliceIndices returns the indices of a sequence of the given length which are selected by the
+// slice bounds following the semantics of Python slices: negative bounds count back from the end
+// of the sequence, bounds beyond the sequence are clamped to it, and a negative step selects the
+// indices in descending order. Omitted bounds are nil or null, and default to the start and the end
+// of the sequence in the direction of the step.
+func sliceIndices(length int64, start, end, step ref.Val) ([]int64, error) {
+	st := int64(step.(types.Int))
+	if st == 0 {
+		return nil, errors.New("slice step cannot be zero")
+	}
+	// The bounds are clamped to [lower, upper], where the lower bound of a descending slice is -1
+	// so that the slice may include the first element.
+	lower, upper := int64(0), length
+	if st < 0 {
+		lower, upper = -1, length-1
+	}
+	clamp := func(idx int64) int64 {
+		if idx < 0 {
+			idx += length
+			if idx < lower {
+				return lower
+			}
+		} else if idx > upper {
+			return upper
+		}
+		return idx
+	}
+	first, last := lower, upper
+	if st < 0 {
+		first, last = upper, lower
+	}
+	if start, ok := start.(types.Int); ok {
+		first = clamp(int64(start))
+	}
+	if end, ok := end.(types.Int); ok {
+		last = clamp(int64(end))
+	}
+	// The loops stop before advancing past the last index, since advancing by a large step may
+	// overflow.
+	var indices []int64
+	if st > 0 {
+		for i := first; i < last; i += st {
+			indices = append(indices, i)
+			if last-i <= st {
+				break
+			}
+		}
+	} else {
+		for i := first; i > last; i += st {
+			indices = append(indices, i)
+			if last-i >= st {
+				break
+			}
+		}
+	}
+	return indices, nil
+}
+
+func newMapAccumulator(meh cel.MacroExprHelper, args []*exprpb.Expr) *exprpb.Expr {
+	return meh.NewMap()
+}
+
+// accumulate adds the value under the key of the accumulator, replacing the empty map with which
+// the accumulator is initialized by a native accumulator on the first step.
+func accumulate(accu, key, val ref.Val, grouping bool) ref.Val {
+	switch key.(type) {
+	case types.Bool, types.Int, types.Uint, types.String, traits.Hashable:
+	default:
+		return types.NewErr("unsupported map key type: %s", key.Type().TypeName())
+	}
+	acc, isAccumulator := accu.(*mapAccumulator)
+	if !isAccumulator {
+		acc = &mapAccumulator{
+			keys:    map[any]ref.Val{},
+			entries: map[any]ref.Val{},
+			groups:  map[any][]ref.Val{},
+		}
+	}
+	// Keys are recorded by their hash keys, as in map literals, since keys such as bytes cannot
+	// be used as the keys of Go maps.
+	hashKey := types.HashKey(key)
+	if _, isHashable := key.(traits.Hashable); isHashable {
+		acc.hashable = true
+	}
+	acc.keys[hashKey] = key
+	if grouping {
+		acc.groups[hashKey] = append(acc.groups[hashKey], val)
+	} else {
+		acc.entries[hashKey] = val
+	}
+	return acc
+}
+
+// accumulatedMap converts the accumulator into an immutable CEL map.
+func accumulatedMap(accu ref.Val) ref.Val {
+	acc, isAccumulator := accu.(*mapAccumulator)
+	if !isAccumulator {
+		// The list was empty, so the accumulator remains the empty map literal.
+		return accu
+	}
+	vals := acc.entries
+	for hashKey, group := range acc.groups {
+		vals[hashKey] = types.NewRefValList(types.DefaultTypeAdapter, group)
+	}
+	if acc.hashable {
+		return types.NewHashKeyMap(types.DefaultTypeAdapter, acc.keys, vals)
+	}
+	// Without hashable keys, each hash key is the key itself.
+	entries := make(map[ref.Val]ref.Val, len(vals))
+	for hashKey, val := range vals {
+		entries[acc.keys[hashKey]] = val
+	}
+	return types.NewRefValMap(types.DefaultTypeAdapter, entries)
+}
+
+// mapAccumulator is the mutable accumulator of the groupBy and indexBy macros, which is only
+// visible to the internal functions which update it and convert it into the macro result.
+//
+// The entries are recorded by the hash keys of their keys, see types.HashKey.
+type mapAccumulator struct {
+	keys     map[any]ref.Val
+	entries  map[any]ref.Val
+	groups   map[any][]ref.Val
+	hashable bool
+}
+
+var mapAccumulatorType = types.NewTypeValue("ext.lists.accumulator")
+
+// ConvertToNative implements the ref.Val interface method.
+func (acc *mapAccumulator) ConvertToNative(typeDesc reflect.Type) (any, error) {
+	return nil, fmt.Errorf("type conversion error from '%s' to '%v'", mapAccumulatorType, typeDesc)
+}
+
+// ConvertToType implements the ref.Val interface method.
+func (acc *mapAccumulator) ConvertToType(typeVal ref.Type) ref.Val {
+	return types.NewErr("type conversion error from '%s' to '%s'", mapAccumulatorType, typeVal)
+}
+
+// Equal implements the ref.Val interface method.
+func (acc *mapAccumulator) Equal(other ref.Val) ref.Val {
+	return types.Bool(acc == other)
+}
+
+// Type implements the ref.Val interface method.
+func (acc *mapAccumulator) Type() ref.Type {
+	return mapAccumulatorType
+}
+
+// Value implements the ref.Val interface method.
+func (acc *mapAccumulator) Value() any {
+	return acc
+}
@@ -0,0 +1,209 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ext
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestLists(t *testing.T) {
+	listsTests := []struct {
+		expr    string
+		outType *cel.Type
+	}{
+		{
+			expr:    `[1, 2, 3, 4].groupBy(x, x % 2 == 0) == {false: [1, 3], true: [2, 4]}`,
+			outType: cel.MapType(cel.BoolType, cel.ListType(cel.IntType)),
+		},
+		{
+			expr:    `[].groupBy(x, x) == {}`,
+			outType: cel.MapType(cel.BoolType, cel.ListType(cel.BoolType)),
+		},
+		{
+			expr:    `[1, 2, 3].groupBy(x, dyn(x % 2)) == {0: [2], 1: [1, 3]}`,
+			outType: cel.MapType(cel.DynType, cel.ListType(cel.IntType)),
+		},
+		{
+			expr:    `names.groupBy(n, n.size()) == {3: ['bob', 'eve'], 5: ['alice']}`,
+			outType: cel.MapType(cel.IntType, cel.ListType(cel.StringType)),
+		},
+		{
+			expr:    `['apple', 'banana'].indexBy(s, s.size()) == {5: 'apple', 6: 'banana'}`,
+			outType: cel.MapType(cel.IntType, cel.StringType),
+		},
+		{
+			expr:    `names.indexBy(n, n.size()) == {3: 'eve', 5: 'alice'}`,
+			outType: cel.MapType(cel.IntType, cel.StringType),
+		},
+		{
+			expr:    `names.indexBy(n, bytes(n)) == {b'bob': 'bob', b'alice': 'alice', b'eve': 'eve'}`,
+			outType: cel.MapType(cel.BytesType, cel.StringType),
+		},
+		{
+			expr:    `['a', 'b', 'a'].groupBy(s, bytes(s))[b'a'] == ['a', 'a']`,
+			outType: cel.MapType(cel.BytesType, cel.ListType(cel.StringType)),
+		},
+		{
+			expr:    `[1, 2, 3].map(i, [i, i * 2]).indexBy(p, string(p[0]))['2'] == [2, 4]`,
+			outType: cel.MapType(cel.StringType, cel.ListType(cel.IntType)),
+		},
+	}
+	env, err := cel.NewEnv(Lists(), cel.Variable("names", cel.ListType(cel.StringType)))
+	if err != nil {
+		t.Fatalf("cel.NewEnv(Lists()) failed: %v", err)
+	}
+	vars := map[string]any{"names": []string{"bob", "alice", "eve"}}
+	for i, tst := range listsTests {
+		tc := tst
+		t.Run(fmt.Sprintf("[%d]", i), func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%v) failed: %v", tc.expr, iss.Err())
+			}
+			// The type of the map operand of the comparison.
+			mapExpr := ast.Expr().GetCallExpr().GetArgs()[0]
+			checked, err := cel.AstToCheckedExpr(ast)
+			if err != nil {
+				t.Fatalf("cel.AstToCheckedExpr() failed: %v", err)
+			}
+			if mapExpr.GetCallExpr().GetFunction() == "_[_]" {
+				mapExpr = mapExpr.GetCallExpr().GetArgs()[0]
+			}
+			gotType, err := cel.ExprTypeToType(checked.GetTypeMap()[mapExpr.GetId()])
+			if err != nil {
+				t.Fatalf("cel.ExprTypeToType() failed: %v", err)
+			}
+			if !gotType.IsAssignableType(tc.outType) || !tc.outType.IsAssignableType(gotType) {
+				t.Errorf("env.Compile(%v) got map type %v, wanted %v", tc.expr, gotType, tc.outType)
+			}
+			for _, opt := range []cel.EvalOption{cel.OptOptimize, cel.OptExhaustiveEval} {
+				prg, err := env.Program(ast, cel.EvalOptions(opt))
+				if err != nil {
+					t.Fatalf("env.Program() failed: %v", err)
+				}
+				out, _, err := prg.Eval(vars)
+				if err != nil {
+					t.Fatalf("prg.Eval() failed: %v", err)
+				}
+				if out.Value() != true {
+					t.Errorf("prg.Eval() got %v, wanted true for expr: %s", out, tc.expr)
+				}
+			}
+		})
+	}
+}
+
+func TestListsSlice(t *testing.T) {
+	sliceTests := []struct {
+		expr string
+		err  string
+	}{
+		{expr: `[1, 2, 3, 4, 5].slice(1, 3) == [2, 3]`},
+		{expr: `[1, 2, 3, 4, 5].slice(-2) == [4, 5]`},
+		{expr: `[1, 2, 3, 4, 5].slice(0, 5, 2) == [1, 3, 5]`},
+		{expr: `[1, 2, 3, 4, 5].slice(-1, -6, -1) == [5, 4, 3, 2, 1]`},
+		{expr: `[1, 2, 3, 4, 5].slice(3, 0, -2) == [4, 2]`},
+		{expr: `[1, 2, 3, 4, 5].slice(-100, 100) == [1, 2, 3, 4, 5]`},
+		{expr: `[1, 2, 3, 4, 5].slice(3, 1) == []`},
+		{expr: `names.slice(1).map(n, n.slice(0, 1)) == ['a', 'e']`},
+		{expr: `[].slice(0, 1) == []`},
+		{expr: `[1, 2, 3].slice(1, 3, 9223372036854775807) == [2]`},
+		{expr: `[1, 2, 3].slice(-1, -9223372036854775808, -9223372036854775808) == [3]`},
+		{expr: `[1, 2].slice(0, 2, 0) == []`, err: "slice step cannot be zero"},
+		{expr: `[1, 2, 3, 4, 5][1:3] == [2, 3]`},
+		{expr: `[1, 2, 3, 4, 5][-2:] == [4, 5]`},
+		{expr: `[1, 2, 3, 4, 5][:2] == [1, 2]`},
+		{expr: `[1, 2, 3, 4, 5][-1:-6:-1] == [5, 4, 3, 2, 1]`},
+		{expr: `[1, 2, 3, 4, 5][::-1] == [5, 4, 3, 2, 1]`},
+		{expr: `[1, 2, 3, 4, 5][::2] == [1, 3, 5]`},
+		{expr: `[1, 2, 3, 4, 5][3::-2] == [4, 2]`},
+		{expr: `[1, 2, 3, 4, 5][:1:-2] == [5, 3]`},
+		{expr: `[1, 2, 3, 4, 5][true ? 1 : 2:] == [2, 3, 4, 5]`},
+		{expr: `[1, 2, 3, 4, 5][[1, 2][1:][0]:] == [3, 4, 5]`},
+		{expr: `names[1:].map(n, n[:1]) == ['a', 'e']`},
+		{expr: `[1, 2][0:2:0] == []`, err: "slice step cannot be zero"},
+	}
+	env, err := cel.NewEnv(Lists(), Strings(), cel.Variable("names", cel.ListType(cel.StringType)))
+	if err != nil {
+		t.Fatalf("cel.NewEnv(Lists(), Strings()) failed: %v", err)
+	}
+	vars := map[string]any{"names": []string{"bob", "alice", "eve"}}
+	for i, tst := range sliceTests {
+		tc := tst
+		t.Run(fmt.Sprintf("[%d]", i), func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%v) failed: %v", tc.expr, iss.Err())
+			}
+			prg, err := env.Program(ast)
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			out, _, err := prg.Eval(vars)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Errorf("prg.Eval(%v) got %v, %v, wanted error containing %q", tc.expr, out, err, tc.err)
+				}
+				return
+			}
+			if err != nil || out.Value() != true {
+				t.Errorf("prg.Eval(%v) got %v, %v, wanted true", tc.expr, out, err)
+			}
+		})
+	}
+}
+
+func TestListsErrors(t *testing.T) {
+	env, err := cel.NewEnv(Lists(), cel.Variable("m", cel.MapType(cel.StringType, cel.IntType)))
+	if err != nil {
+		t.Fatalf("cel.NewEnv(Lists()) failed: %v", err)
+	}
+	checkErrs := []struct {
+		expr string
+		err  string
+	}{
+		{expr: `m.groupBy(k, k)`, err: "found no matching overload for 'groupBy' applied to '(map(string, int))'"},
+		{expr: `[1].indexBy(x.y, x)`, err: "argument must be a simple name"},
+		{expr: `[1, 2].indexBy(x, 1.5)`, err: "found no matching overload for 'indexBy' applied to '(double)'"},
+		{expr: `[1.5, 2.5].groupBy(x, x)`, err: "found no matching overload for 'groupBy' applied to '(double)'"},
+		{expr: `[1, 2].groupBy(x, [x])`, err: "found no matching overload for 'groupBy' applied to '(list(int))'"},
+		{expr: `m.map(k, k).indexBy(k, m)`, err: "found no matching overload for 'indexBy'"},
+	}
+	for _, tc := range checkErrs {
+		_, iss := env.Compile(tc.expr)
+		if iss.Err() == nil || !strings.Contains(iss.Err().Error(), tc.err) {
+			t.Errorf("env.Compile(%v) got %v, wanted error containing %q", tc.expr, iss.Err(), tc.err)
+		}
+		if iss.Err() != nil && strings.Contains(iss.Err().Error(), "@") {
+			t.Errorf("env.Compile(%v) got %v, wanted error without internal function names", tc.expr, iss.Err())
+		}
+	}
+	ast, iss := env.Compile(`[1.5, 2.5].groupBy(x, dyn(x))`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	_, _, err = prg.Eval(cel.NoVars())
+	if err == nil || !strings.Contains(err.Error(), "unsupported map key type: double") {
+		t.Errorf("prg.Eval() got %v, wanted unsupported map key type error", err)
+	}
+}
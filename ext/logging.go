@@ -0,0 +1,217 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ext
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+)
+
+const (
+	logInfoFunction = "log.info"
+	// logMaxArgs is the largest number of arguments which may follow the message.
+	logMaxArgs = 4
+)
+
+// Logging returns a cel.EnvOption to configure a function for emitting diagnostics from within an
+// expression, e.g. while debugging a complex policy.
+//
+// Log records are delivered to the sink configured for the program with LogOutput. Programs
+// without a sink evaluate calls to the function to true without evaluating their arguments.
+//
+// # Log.Info
+//
+// Emits a log record with a message and up to four arguments, and returns true so that the call
+// may be conjoined with the expression under investigation. The arguments are recorded as they
+// were evaluated, including errors and unknowns.
+//
+//	log.info(<string>, <dyn>...) -> <bool>
+//
+// Examples:
+//
+//	log.info('checking request', request.path) && request.path.startsWith('/admin')
+func Logging() cel.EnvOption {
+	return cel.Lib(loggingLib{})
+}
+
+// LogRecord describes a call to log.info.
+type LogRecord struct {
+	// ExprID is the id of the call expression, which identifies the call within the expression.
+	ExprID int64
+
+	// Message is the message given to the call.
+	Message string
+
+	// Args holds the values of the arguments which follow the message.
+	Args []ref.Val
+
+	// Dropped counts the records which were dropped by the rate limit since the last record was
+	// delivered to the sink.
+	Dropped int
+}
+
+// String returns the message of the record followed by its arguments.
+func (r LogRecord) String() string {
+	var sb strings.Builder
+	sb.WriteString(r.Message)
+	for _, arg := range r.Args {
+		sb.WriteString(" ")
+		if types.IsError(arg) {
+			sb.WriteString(fmt.Sprint(arg))
+			continue
+		}
+		sb.WriteString(fmt.Sprint(arg.Value()))
+	}
+	return sb.String()
+}
+
+// LogSink receives the records emitted by the calls to log.info within a program.
+//
+// The sink may be invoked concurrently when the program is evaluated concurrently.
+type LogSink func(record LogRecord)
+
+// LogOutput configures a program to deliver the records emitted by calls to log.info to the sink,
+// at most maxPerSecond records per second. Records which exceed the rate are dropped, and counted
+// by the next record delivered. A limit of zero or less delivers every record.
+//
+// The option has no effect unless the environment is configured with Logging.
+func LogOutput(sink LogSink, maxPerSecond int) cel.ProgramOption {
+	l := &logLimiter{sink: sink, rate: float64(maxPerSecond), now: time.Now}
+	l.tokens = l.rate
+	return cel.CustomDecorator(func(i interpreter.Interpretable) (interpreter.Interpretable, error) {
+		if log, ok := i.(*evalLog); ok {
+			return &evalLog{id: log.id, args: log.args, limiter: l}, nil
+		}
+		return i, nil
+	})
+}
+
+type loggingLib struct{}
+
+// LibraryName implements the SingletonLibrary interface method.
+func (loggingLib) LibraryName() string {
+	return "cel.lib.ext.logging"
+}
+
+// CompileOptions implements the Library interface method.
+func (loggingLib) CompileOptions() []cel.EnvOption {
+	overloads := make([]cel.FunctionOpt, 0, logMaxArgs+1)
+	argTypes := []*cel.Type{cel.StringType}
+	overloadID := "log_info_string"
+	for i := 0; i <= logMaxArgs; i++ {
+		overloads = append(overloads, cel.Overload(overloadID, argTypes, cel.BoolType,
+			cel.FunctionBinding(func(...ref.Val) ref.Val {
+				return types.True
+			})))
+		argTypes = append(argTypes[:len(argTypes):len(argTypes)], cel.DynType)
+		overloadID += "_dyn"
+	}
+	return []cel.EnvOption{cel.Function(logInfoFunction, overloads...)}
+}
+
+// ProgramOptions implements the Library interface method.
+func (loggingLib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{cel.CustomDecorator(decorateLogCalls)}
+}
+
+// decorateLogCalls replaces the calls to log.info with steps which emit log records to the sink
+// configured by LogOutput, if any.
+func decorateLogCalls(i interpreter.Interpretable) (interpreter.Interpretable, error) {
+	call, ok := i.(interpreter.InterpretableCall)
+	if !ok || call.Function() != logInfoFunction {
+		return i, nil
+	}
+	return &evalLog{id: call.ID(), args: call.Args()}, nil
+}
+
+// evalLog evaluates a call to log.info, delivering a record to the sink of the program when the
+// limiter is non-nil.
+//
+// The call is not an interpreter.InterpretableCall, so that calls with constant arguments are not
+// folded into a single record emitted when the program is planned.
+type evalLog struct {
+	id      int64
+	args    []interpreter.Interpretable
+	limiter *logLimiter
+}
+
+// ID implements the Interpretable interface method.
+func (l *evalLog) ID() int64 {
+	return l.id
+}
+
+// Eval implements the Interpretable interface method.
+func (l *evalLog) Eval(vars interpreter.Activation) ref.Val {
+	if l.limiter == nil {
+		return types.True
+	}
+	msg := l.args[0].Eval(vars)
+	str, ok := msg.(types.String)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(msg)
+	}
+	args := make([]ref.Val, len(l.args)-1)
+	for i, arg := range l.args[1:] {
+		args[i] = arg.Eval(vars)
+	}
+	l.limiter.log(LogRecord{ExprID: l.id, Message: string(str), Args: args})
+	return types.True
+}
+
+// logLimiter delivers records to a sink at a limited rate using a token bucket which holds at most
+// one second's worth of records.
+type logLimiter struct {
+	sink LogSink
+	rate float64
+	now  func() time.Time
+
+	mu      sync.Mutex
+	tokens  float64
+	last    time.Time
+	dropped int
+}
+
+func (l *logLimiter) log(record LogRecord) {
+	if l.rate <= 0 {
+		l.sink(record)
+		return
+	}
+	l.mu.Lock()
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.rate {
+			l.tokens = l.rate
+		}
+	}
+	l.last = now
+	if l.tokens < 1 {
+		l.dropped++
+		l.mu.Unlock()
+		return
+	}
+	l.tokens--
+	record.Dropped = l.dropped
+	l.dropped = 0
+	l.mu.Unlock()
+	l.sink(record)
+}
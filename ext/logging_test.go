@@ -0,0 +1,90 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ext
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+)
+
+func TestLogging(t *testing.T) {
+	env, err := cel.NewEnv(Logging(), cel.Variable("x", cel.IntType))
+	if err != nil {
+		t.Fatalf("cel.NewEnv(Logging()) failed: %v", err)
+	}
+	ast, iss := env.Compile(`log.info('constant') && log.info('checking x', x, x / 0) && x > 1`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+
+	// Without a sink, calls evaluate to true.
+	prg, err := env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	out, _, err := prg.Eval(map[string]any{"x": 2})
+	if err != nil || out != types.True {
+		t.Fatalf("prg.Eval() got %v, %v, wanted true", out, err)
+	}
+
+	var records []LogRecord
+	prg, err = env.Program(ast, LogOutput(func(r LogRecord) { records = append(records, r) }, 0))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if out, _, err := prg.Eval(map[string]any{"x": 2}); err != nil || out != types.True {
+			t.Fatalf("prg.Eval() got %v, %v, wanted true", out, err)
+		}
+	}
+	if len(records) != 4 {
+		t.Fatalf("got %d records, wanted 4: %v", len(records), records)
+	}
+	if records[0].String() != "constant" || records[2].String() != "constant" {
+		t.Errorf("got records %v, wanted the constant message to be logged on each evaluation", records)
+	}
+	rec := records[1]
+	if rec.Message != "checking x" || len(rec.Args) != 2 || rec.Args[0] != types.Int(2) || !types.IsError(rec.Args[1]) {
+		t.Errorf("got record %v, wanted the message, the value of x, and an error", rec)
+	}
+	if rec.ExprID == records[0].ExprID || rec.ExprID == 0 {
+		t.Errorf("got record ids %d and %d, wanted distinct ids of the calls", records[0].ExprID, rec.ExprID)
+	}
+}
+
+func TestLoggingRateLimit(t *testing.T) {
+	now := time.Unix(0, 0)
+	var records []LogRecord
+	l := &logLimiter{
+		sink:   func(r LogRecord) { records = append(records, r) },
+		rate:   2,
+		tokens: 2,
+		now:    func() time.Time { return now },
+	}
+	for i := 0; i < 5; i++ {
+		l.log(LogRecord{Message: "burst"})
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records within the burst, wanted 2", len(records))
+	}
+	now = now.Add(500 * time.Millisecond)
+	l.log(LogRecord{Message: "later"})
+	if len(records) != 3 || records[2].Dropped != 3 {
+		t.Errorf("got records %v, wanted a third record counting 3 dropped records", records)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ext
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/parser"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Maps returns a cel.EnvOption to configure macros which quantify over the entries of maps.
+//
+// # AllEntries
+//
+// Tests whether the predicate holds for every entry of a map, with the key and value of each entry
+// bound to the given variable names.
+//
+//	<map(K, V)>.allEntries(<keyVar>, <valueVar>, <predicate>) -> <bool>
+//
+// Examples:
+//
+//	{'a': 1, 'b': 2}.allEntries(k, v, v > 0)        // true
+//	labels.allEntries(k, v, k.startsWith('app/') || v != '')
+//
+// # ExistsEntry
+//
+// Tests whether the predicate holds for some entry of a map, with the key and value of each entry
+// bound to the given variable names.
+//
+//	<map(K, V)>.existsEntry(<keyVar>, <valueVar>, <predicate>) -> <bool>
+//
+// Examples:
+//
+//	{'a': 1, 'b': 2}.existsEntry(k, v, k == 'b' && v == 2) // true
+//
+// Both macros iterate over the keys of the map and look up the value of each key as it is visited,
+// so no list of entries is built, and both stop at the first entry which decides the result with
+// the same error and unknown semantics as the all() and exists() macros. The number of entries
+// visited by each evaluation may be observed with cel.ObserveFolds, which also reports the
+// comprehensions that bind the map and the value of each entry without iterating.
+func Maps() cel.EnvOption {
+	return cel.Lib(mapsLib{})
+}
+
+const (
+	allEntriesMacro  = "allEntries"
+	existsEntryMacro = "existsEntry"
+
+	entryValueFunc = "@entry_value"
+	entriesVar     = "@entries"
+)
+
+type mapsLib struct{}
+
+// LibraryName implements the SingletonLibrary interface method.
+func (mapsLib) LibraryName() string {
+	return "cel.lib.ext.maps"
+}
+
+// CompileOptions implements the Library interface method.
+func (mapsLib) CompileOptions() []cel.EnvOption {
+	keyType := cel.TypeParamType("K")
+	valType := cel.TypeParamType("V")
+	return []cel.EnvOption{
+		cel.Macros(
+			cel.NewReceiverMacro(allEntriesMacro, 3, allEntries),
+			cel.NewReceiverMacro(existsEntryMacro, 3, existsEntry),
+		),
+		cel.Function(entryValueFunc,
+			cel.Overload("entry_value_map_K_V_K", []*cel.Type{cel.MapType(keyType, valType), keyType}, valType,
+				cel.BinaryBinding(entryValue))),
+	}
+}
+
+// ProgramOptions implements the Library interface method.
+func (mapsLib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+// allEntries expands `m.allEntries(k, v, pred)` into a comprehension over the keys of the map
+// which binds the value of each key:
+//
+//	cel.bind(@entries, m, @entries.all(k, cel.bind(v, @entries[k], pred)))
+func allEntries(meh cel.MacroExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+	return makeEntryQuantifier(meh, allEntriesMacro, target, args)
+}
+
+// existsEntry expands `m.existsEntry(k, v, pred)` into a comprehension over the keys of the map
+// which binds the value of each key:
+//
+//	cel.bind(@entries, m, @entries.exists(k, cel.bind(v, @entries[k], pred)))
+func existsEntry(meh cel.MacroExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+	return makeEntryQuantifier(meh, existsEntryMacro, target, args)
+}
+
+func makeEntryQuantifier(meh cel.MacroExprHelper, macro string, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+	keyVar, err := entryVarName(meh, macro, args[0])
+	if err != nil {
+		return nil, err
+	}
+	valueVar, err := entryVarName(meh, macro, args[1])
+	if err != nil {
+		return nil, err
+	}
+	if keyVar == valueVar {
+		return nil, &common.Error{
+			Message:  fmt.Sprintf("%s() key and value variables must have different names", macro),
+			Location: meh.OffsetLocation(args[1].GetId()),
+		}
+	}
+	pred := meh.Fold(unusedIterVar,
+		meh.NewList(),
+		valueVar,
+		meh.GlobalCall(entryValueFunc, meh.Ident(entriesVar), meh.Ident(keyVar)),
+		meh.LiteralBool(false),
+		meh.Ident(valueVar),
+		args[2])
+	var init, cond, step *exprpb.Expr
+	if macro == allEntriesMacro {
+		init = meh.LiteralBool(true)
+		cond = meh.GlobalCall(operators.NotStrictlyFalse, meh.AccuIdent())
+		step = meh.GlobalCall(operators.LogicalAnd, meh.AccuIdent(), pred)
+	} else {
+		init = meh.LiteralBool(false)
+		cond = meh.GlobalCall(operators.NotStrictlyFalse,
+			meh.GlobalCall(operators.LogicalNot, meh.AccuIdent()))
+		step = meh.GlobalCall(operators.LogicalOr, meh.AccuIdent(), pred)
+	}
+	quantifier := meh.Fold(keyVar,
+		meh.Ident(entriesVar),
+		parser.AccumulatorName,
+		init,
+		cond,
+		step,
+		meh.AccuIdent())
+	return meh.Fold(unusedIterVar,
+		meh.NewList(),
+		entriesVar,
+		target,
+		meh.LiteralBool(false),
+		meh.Ident(entriesVar),
+		quantifier), nil
+}
+
+func entryVarName(meh cel.MacroExprHelper, macro string, arg *exprpb.Expr) (string, *common.Error) {
+	if arg.GetIdentExpr() == nil {
+		return "", &common.Error{
+			Message:  fmt.Sprintf("%s() variable names must be simple identifiers", macro),
+			Location: meh.OffsetLocation(arg.GetId()),
+		}
+	}
+	return arg.GetIdentExpr().GetName(), nil
+}
+
+// entryValue returns the value of the key within the map.
+func entryValue(m, key ref.Val) ref.Val {
+	mapper, ok := m.(traits.Mapper)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(m)
+	}
+	return mapper.Get(key)
+}
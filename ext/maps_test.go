@@ -0,0 +1,147 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ext
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+)
+
+func TestMaps(t *testing.T) {
+	mapsTests := []struct {
+		expr string
+		out  any
+	}{
+		{expr: `{'a': 1, 'b': 2}.allEntries(k, v, v > 0)`, out: true},
+		{expr: `{'a': 1, 'b': 2}.allEntries(k, v, k == 'a' && v == 1)`, out: false},
+		{expr: `{}.allEntries(k, v, false)`, out: true},
+		{expr: `{'a': 1, 'b': 2}.existsEntry(k, v, k == 'b' && v == 2)`, out: true},
+		{expr: `{'a': 1, 'b': 2}.existsEntry(k, v, v > 2)`, out: false},
+		{expr: `{}.existsEntry(k, v, true)`, out: false},
+		{expr: `labels.allEntries(k, v, k.startsWith('app/') || v != '')`, out: true},
+		{expr: `labels.existsEntry(k, v, v == '')`, out: true},
+		{expr: `{'a': {'x': 1}}.allEntries(k, v, v.allEntries(k2, v2, k2 == 'x' && v2 == 1))`, out: true},
+		{expr: `{'a': 0, 'b': 1}.allEntries(k, v, 1 / v < 0)`, out: false},
+		{expr: `{'a': 0, 'b': 1}.existsEntry(k, v, 1 / v > 0)`, out: true},
+		{expr: `cel.bind(v, 10, {'a': 1}.allEntries(k, v, v < 10) && v == 10)`, out: true},
+	}
+	env, err := cel.NewEnv(Maps(), Bindings(),
+		cel.Variable("labels", cel.MapType(cel.StringType, cel.StringType)))
+	if err != nil {
+		t.Fatalf("cel.NewEnv(Maps()) failed: %v", err)
+	}
+	vars := map[string]any{"labels": map[string]string{"app/name": "", "team": "core"}}
+	for i, tst := range mapsTests {
+		tc := tst
+		t.Run(fmt.Sprintf("[%d]", i), func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%v) failed: %v", tc.expr, iss.Err())
+			}
+			if ast.OutputType() != cel.BoolType {
+				t.Errorf("env.Compile(%v) got type %v, wanted bool", tc.expr, ast.OutputType())
+			}
+			for _, opt := range []cel.EvalOption{cel.OptOptimize, cel.OptExhaustiveEval} {
+				prg, err := env.Program(ast, cel.EvalOptions(opt))
+				if err != nil {
+					t.Fatalf("env.Program() failed: %v", err)
+				}
+				out, _, err := prg.Eval(vars)
+				if err != nil {
+					t.Fatalf("prg.Eval() failed: %v", err)
+				}
+				if out.Value() != tc.out {
+					t.Errorf("prg.Eval() got %v, wanted %v for expr: %s", out, tc.out, tc.expr)
+				}
+			}
+		})
+	}
+}
+
+func TestMapsErrors(t *testing.T) {
+	env, err := cel.NewEnv(Maps(), cel.Variable("l", cel.ListType(cel.IntType)))
+	if err != nil {
+		t.Fatalf("cel.NewEnv(Maps()) failed: %v", err)
+	}
+	checkErrs := []struct {
+		expr string
+		err  string
+	}{
+		{expr: `l.allEntries(k, v, v > 0)`, err: "found no matching overload for '@entry_value'"},
+		{expr: `{'a': 1}.existsEntry(k.x, v, v > 0)`, err: "existsEntry() variable names must be simple identifiers"},
+		{expr: `{'a': 1}.allEntries(k, k, k > 0)`, err: "allEntries() key and value variables must have different names"},
+	}
+	for _, tc := range checkErrs {
+		_, iss := env.Compile(tc.expr)
+		if iss.Err() == nil || !strings.Contains(iss.Err().Error(), tc.err) {
+			t.Errorf("env.Compile(%v) got %v, wanted error containing %q", tc.expr, iss.Err(), tc.err)
+		}
+	}
+}
+
+func TestMapsEarlyTermination(t *testing.T) {
+	env, err := cel.NewEnv(Maps(), cel.Variable("m", cel.MapType(cel.IntType, cel.IntType)))
+	if err != nil {
+		t.Fatalf("cel.NewEnv(Maps()) failed: %v", err)
+	}
+	m := map[int64]int64{}
+	for i := int64(0); i < 100; i++ {
+		m[i] = i
+	}
+	tests := []struct {
+		expr       string
+		out        bool
+		iterations int
+		early      bool
+	}{
+		{expr: `m.allEntries(k, v, k == v)`, out: true, iterations: 100},
+		{expr: `m.existsEntry(k, v, k == v)`, out: true, iterations: 1, early: true},
+		{expr: `m.allEntries(k, v, k != v)`, out: false, iterations: 1, early: true},
+	}
+	for _, tc := range tests {
+		ast, iss := env.Compile(tc.expr)
+		if iss.Err() != nil {
+			t.Fatalf("env.Compile(%v) failed: %v", tc.expr, iss.Err())
+		}
+		// The quantifier is the result of the comprehension which binds the map, and is observed
+		// separately from the comprehensions which bind the value of each entry.
+		quantifierID := ast.Expr().GetComprehensionExpr().GetResult().GetId()
+		folds := 0
+		var iterations int
+		var early bool
+		prg, err := env.Program(ast, cel.ObserveFolds(func(id int64, n int, terminatedEarly bool) {
+			if id != quantifierID {
+				return
+			}
+			folds++
+			iterations, early = n, terminatedEarly
+		}))
+		if err != nil {
+			t.Fatalf("env.Program() failed: %v", err)
+		}
+		out, _, err := prg.Eval(map[string]any{"m": m})
+		if err != nil || out != types.Bool(tc.out) {
+			t.Fatalf("prg.Eval(%v) got %v, %v, wanted %v", tc.expr, out, err, tc.out)
+		}
+		if folds != 1 || iterations != tc.iterations || early != tc.early {
+			t.Errorf("prg.Eval(%v) observed %d folds with %d iterations, early: %t, wanted 1 fold with %d iterations, early: %t",
+				tc.expr, folds, iterations, early, tc.iterations, tc.early)
+		}
+	}
+}
@@ -87,6 +87,37 @@ import (
 //	math.least('string') // parse error
 //	math.least(a, b)     // check-time error if a or b is non-numeric
 //	math.least(dyn('string')) // runtime error
+//
+// # Math.SafeDiv
+//
+// Returns the quotient of two int, uint, or double values as an optional value, which is empty when
+// the divisor is zero or the division overflows. Unlike the `/` operator, the division of a double
+// by zero is empty rather than an infinity or NaN.
+//
+//	math.safeDiv(<int>, <int>) -> <optional(int)>
+//	math.safeDiv(<uint>, <uint>) -> <optional(uint)>
+//	math.safeDiv(<double>, <double>) -> <optional(double)>
+//
+// Examples:
+//
+//	math.safeDiv(7, 2)               // optional.of(3)
+//	math.safeDiv(7, 0)               // optional.none()
+//	math.safeDiv(1.0, 0.0)           // optional.none()
+//	math.safeDiv(x, y).orValue(0)    // requires cel.OptionalTypes()
+//
+// # Math.SafeMod
+//
+// Returns the remainder of two int or uint values as an optional value, which is empty when the
+// divisor is zero or the operation overflows. Since CEL does not define the remainder of double
+// values, safeMod is integer-only.
+//
+//	math.safeMod(<int>, <int>) -> <optional(int)>
+//	math.safeMod(<uint>, <uint>) -> <optional(uint)>
+//
+// Examples:
+//
+//	math.safeMod(7, 2)   // optional.of(1)
+//	math.safeMod(7u, 0u) // optional.none()
 func Math() cel.EnvOption {
 	return cel.Lib(mathLib{})
 }
@@ -97,6 +128,8 @@ const (
 	greatestMacro = "greatest"
 	minFunc       = "math.@min"
 	maxFunc       = "math.@max"
+	safeDivFunc   = "math.safeDiv"
+	safeModFunc   = "math.safeMod"
 )
 
 type mathLib struct{}
@@ -179,6 +212,20 @@ func (mathLib) CompileOptions() []cel.EnvOption {
 			cel.Overload("math_@max_list_uint", []*cel.Type{cel.ListType(cel.UintType)}, cel.UintType,
 				cel.UnaryBinding(maxList)),
 		),
+		cel.Function(safeDivFunc,
+			cel.Overload("math_safeDiv_int_int", []*cel.Type{cel.IntType, cel.IntType}, cel.OptionalType(cel.IntType),
+				cel.BinaryBinding(safeDiv)),
+			cel.Overload("math_safeDiv_uint_uint", []*cel.Type{cel.UintType, cel.UintType}, cel.OptionalType(cel.UintType),
+				cel.BinaryBinding(safeDiv)),
+			cel.Overload("math_safeDiv_double_double", []*cel.Type{cel.DoubleType, cel.DoubleType}, cel.OptionalType(cel.DoubleType),
+				cel.BinaryBinding(safeDiv)),
+		),
+		cel.Function(safeModFunc,
+			cel.Overload("math_safeMod_int_int", []*cel.Type{cel.IntType, cel.IntType}, cel.OptionalType(cel.IntType),
+				cel.BinaryBinding(safeMod)),
+			cel.Overload("math_safeMod_uint_uint", []*cel.Type{cel.UintType, cel.UintType}, cel.OptionalType(cel.UintType),
+				cel.BinaryBinding(safeMod)),
+		),
 	}
 }
 
@@ -323,6 +370,33 @@ func maxList(numList ref.Val) ref.Val {
 	}
 }
 
+func safeDiv(dividend, divisor ref.Val) ref.Val {
+	if divisor == types.Double(0) {
+		return types.OptionalNone
+	}
+	div, ok := dividend.(traits.Divider)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(dividend)
+	}
+	return optionalResult(div.Divide(divisor))
+}
+
+func safeMod(dividend, divisor ref.Val) ref.Val {
+	mod, ok := dividend.(traits.Modder)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(dividend)
+	}
+	return optionalResult(mod.Modulo(divisor))
+}
+
+// optionalResult returns an empty optional value in place of an error.
+func optionalResult(val ref.Val) ref.Val {
+	if types.IsError(val) {
+		return types.OptionalNone
+	}
+	return types.OptionalOf(val)
+}
+
 func checkInvalidArgs(meh cel.MacroExprHelper, funcName string, args []*exprpb.Expr) *common.Error {
 	for _, arg := range args {
 		err := checkInvalidArgLiteral(funcName, arg)
@@ -112,9 +112,24 @@ func TestMath(t *testing.T) {
 				"numbers": []float64{-21.0, -10.5, 1.0},
 			},
 		},
+		// Tests for math.safeDiv and math.safeMod
+		{expr: "math.safeDiv(7, 2) == optional.of(3)"},
+		{expr: "math.safeDiv(7u, 2u) == optional.of(3u)"},
+		{expr: "math.safeDiv(a, 0) == optional.none()", in: map[string]any{"a": 7}},
+		{expr: "math.safeDiv(7u, 0u).orValue(42u) == 42u"},
+		{expr: "!math.safeDiv(-9223372036854775808, -1).hasValue()"},
+		{expr: "math.safeDiv(7.0, 2.0) == optional.of(3.5)"},
+		{expr: "math.safeDiv(-1.0, 0.0) == optional.none()"},
+		{expr: "math.safeDiv(1.5, -0.0).orValue(0.0) == 0.0"},
+		{expr: "math.safeMod(7, -2) == optional.of(1)"},
+		{expr: "math.safeMod(7u, 2u) == optional.of(1u)"},
+		{expr: "math.safeMod(a, 0).orValue(-1) == -1", in: map[string]any{"a": 7}},
+		{expr: "math.safeMod(7u, 0u) == optional.none()"},
+		{expr: "!math.safeMod(-9223372036854775808, -1).hasValue()"},
 	}
 
 	env := testMathEnv(t,
+		cel.OptionalTypes(),
 		cel.Variable("a", cel.IntType),
 		cel.Variable("b", cel.IntType),
 		cel.Variable("numbers", cel.ListType(cel.DoubleType)),
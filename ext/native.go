@@ -17,6 +17,7 @@ package ext
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -150,6 +151,17 @@ func (tp *nativeTypeProvider) FindType(typeName string) (*exprpb.Type, bool) {
 	return tp.baseProvider.FindType(typeName)
 }
 
+// TypeNames returns the sorted, qualified names of the native types, which makes it possible to
+// detect conflicts when the provider is composed with others using cel.TypeProviders.
+func (tp *nativeTypeProvider) TypeNames() []string {
+	names := make([]string, 0, len(tp.nativeTypes))
+	for name := range tp.nativeTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // FindFieldType looks up a native type's field definition, and if the type name is not a native
 // type then proxies to the composed ref.TypeProvider
 func (tp *nativeTypeProvider) FindFieldType(typeName, fieldName string) (*ref.FieldType, bool) {
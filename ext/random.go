@@ -0,0 +1,111 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ext
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// Random returns a cel.EnvOption to configure pseudo-random functions whose values derive entirely
+// from a seed given by the expression, typically a value of the activation such as the id of a
+// request, so that expressions which sample or roll out by percentage are reproducible when they
+// are replayed or tested. No function of the library consults the wall clock or a global random
+// number generator, and so the functions are deterministic.
+//
+// # Rand.Uniform
+//
+// Returns a double in the range [0, 1) derived from the seed, and from the salt when one is given.
+// The seed may be a string, bytes, int, or uint value. Seeds of different types yield unrelated
+// values, e.g. `1` and `'1'`. The salt distinguishes the samples taken from the same seed, so that
+// the populations selected by rollouts with different salts are independent. Omitting the salt is
+// equivalent to the empty salt.
+//
+//	rand.uniform(<string|bytes|int|uint>) -> <double>
+//	rand.uniform(<string|bytes|int|uint>, <string>) -> <double>
+//
+// Examples:
+//
+//	rand.uniform(request.id) < 0.1                // true for ~10% of request ids
+//	rand.uniform(user.id, 'new-checkout') < 0.25 // true for ~25% of users
+func Random() cel.EnvOption {
+	return cel.Lib(randomLib{})
+}
+
+const randUniformFunc = "rand.uniform"
+
+type randomLib struct{}
+
+// LibraryName implements the SingletonLibrary interface method.
+func (randomLib) LibraryName() string {
+	return "cel.lib.ext.rand"
+}
+
+// CompileOptions implements the Library interface method.
+func (randomLib) CompileOptions() []cel.EnvOption {
+	seedTypes := []*cel.Type{cel.StringType, cel.BytesType, cel.IntType, cel.UintType}
+	overloads := make([]cel.FunctionOpt, 0, 2*len(seedTypes))
+	for _, t := range seedTypes {
+		overloads = append(overloads,
+			cel.Overload("rand_uniform_"+t.String(), []*cel.Type{t}, cel.DoubleType,
+				cel.UnaryBinding(func(seed ref.Val) ref.Val {
+					return randUniform(seed, "")
+				})),
+			cel.Overload("rand_uniform_"+t.String()+"_string", []*cel.Type{t, cel.StringType}, cel.DoubleType,
+				cel.BinaryBinding(func(seed, salt ref.Val) ref.Val {
+					s, ok := salt.(types.String)
+					if !ok {
+						return types.MaybeNoSuchOverloadErr(salt)
+					}
+					return randUniform(seed, string(s))
+				})))
+	}
+	return []cel.EnvOption{cel.Function(randUniformFunc, overloads...)}
+}
+
+// ProgramOptions implements the Library interface method.
+func (randomLib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+// randUniform derives a double in the range [0, 1) from the SHA-256 digest of the salt and of the
+// seed tagged by its type, using the 53 leading bits of the digest as the fraction.
+func randUniform(seed ref.Val, salt string) ref.Val {
+	h := sha256.New()
+	h.Write([]byte(salt))
+	h.Write([]byte{0})
+	switch s := seed.(type) {
+	case types.String:
+		h.Write([]byte{'s'})
+		h.Write([]byte(s))
+	case types.Bytes:
+		h.Write([]byte{'b'})
+		h.Write(s)
+	case types.Int:
+		h.Write([]byte{'i'})
+		binary.Write(h, binary.BigEndian, int64(s))
+	case types.Uint:
+		h.Write([]byte{'u'})
+		binary.Write(h, binary.BigEndian, uint64(s))
+	default:
+		return types.MaybeNoSuchOverloadErr(seed)
+	}
+	bits := binary.BigEndian.Uint64(h.Sum(nil))
+	return types.Double(float64(bits>>11) / (1 << 53))
+}
@@ -0,0 +1,92 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ext
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+)
+
+func TestRandom(t *testing.T) {
+	randTests := []string{
+		`rand.uniform(id) == rand.uniform(id)`,
+		`rand.uniform(id) >= 0.0 && rand.uniform(id) < 1.0`,
+		`rand.uniform(id) != rand.uniform(id + '.')`,
+		`rand.uniform(id, 'a') != rand.uniform(id, 'b')`,
+		`rand.uniform(id) == rand.uniform(id, '')`,
+		`rand.uniform(1) != rand.uniform('1')`,
+		`rand.uniform(1) != rand.uniform(1u)`,
+		`rand.uniform(b'1') != rand.uniform('1')`,
+		`rand.uniform(dyn(1.5)) < 1.0`,
+	}
+	env, err := cel.NewEnv(Random(), cel.Variable("id", cel.StringType))
+	if err != nil {
+		t.Fatalf("cel.NewEnv(Random()) failed: %v", err)
+	}
+	for i, tst := range randTests {
+		expr := tst
+		t.Run(fmt.Sprintf("[%d]", i), func(t *testing.T) {
+			ast, iss := env.Compile(expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%v) failed: %v", expr, iss.Err())
+			}
+			prg, err := env.Program(ast)
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			out, _, err := prg.Eval(map[string]any{"id": "request-1"})
+			if expr == `rand.uniform(dyn(1.5)) < 1.0` {
+				if err == nil {
+					t.Errorf("prg.Eval(%v) got %v, wanted no such overload error", expr, out)
+				}
+				return
+			}
+			if err != nil || out != types.True {
+				t.Errorf("prg.Eval(%v) got %v, %v, wanted true", expr, out, err)
+			}
+		})
+	}
+}
+
+func TestRandomDistribution(t *testing.T) {
+	env, err := cel.NewEnv(Random(), cel.Variable("id", cel.IntType))
+	if err != nil {
+		t.Fatalf("cel.NewEnv(Random()) failed: %v", err)
+	}
+	ast, iss := env.Compile(`rand.uniform(id, 'rollout') < 0.25`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	selected := 0
+	for id := 0; id < 10000; id++ {
+		out, _, err := prg.Eval(map[string]any{"id": id})
+		if err != nil {
+			t.Fatalf("prg.Eval() failed: %v", err)
+		}
+		if out == types.True {
+			selected++
+		}
+	}
+	if selected < 2300 || selected > 2700 {
+		t.Errorf("rollout selected %d of 10000 ids, wanted about 2500", selected)
+	}
+}
@@ -81,6 +81,11 @@ const (
 // `%X` - same as above, but with A-F capitalized.
 // `%o` - substitutes an integer with its equivalent in octal.
 //
+// Values of custom types which implement the traits.Formatter interface supply their own
+// representation for any clause, e.g. an IP address type which prints as `10.0.0.1` with `%s`.
+// Such types declare the traits.FormatterType trait so that their use in formatting clauses is
+// accepted by the compile-time checks of `cel.OptCheckStringFormat`.
+//
 // <string>.format(<list>)` -> <string>
 //
 // Examples:
@@ -206,6 +211,40 @@ const (
 //	'hello hello'.replace('he', 'we', 1)  // returns 'wello hello'
 //	'hello hello'.replace('he', 'we', 0)  // returns 'hello hello'
 //
+// # Slice
+//
+// Introduced in version: 3
+//
+// Returns the characters of a string from the start position up to, but excluding, the end
+// position, taking every step-th character when a step is given. Positions are code point offsets
+// as for substring, but with the semantics of Python slices: the end position defaults to the
+// length of the string and the step defaults to 1, negative positions count back from the end of
+// the string, positions beyond the string are clamped to it, and a negative step takes the
+// characters in reverse order. A step of zero is an error. Null positions take their defaults in
+// the direction of the step.
+//
+//	<string>.slice(<int>) -> <string>
+//	<string>.slice(<int>, <int>) -> <string>
+//	<string>.slice(<int>, <int>, <int>) -> <string>
+//	<string>.slice(null, <int>, <int>) -> <string>
+//	<string>.slice(<int>, null, <int>) -> <string>
+//	<string>.slice(null, null, <int>) -> <string>
+//
+// Examples:
+//
+//	'tacocat'.slice(-3)       // returns 'cat'
+//	'tacocat'.slice(0, -3)    // returns 'taco'
+//	'tacocat'.slice(0, 7, 2)  // returns 'tcct'
+//	'héllo'.slice(-1, -6, -1) // returns 'olléh'
+//
+// Strings may also be sliced with the index syntax, `x[a:b]` and `x[a:b:c]`, where any bound may
+// be omitted:
+//
+//	'tacocat'[-3:]    // returns 'cat'
+//	'tacocat'[:4]     // returns 'taco'
+//	'héllo'[-1:-6:-1] // returns 'olléh'
+//	'héllo'[::-1]     // returns 'olléh'
+//
 // # Split
 //
 // Returns a list of strings split from the input by the given separator. The function accepts
@@ -226,6 +265,21 @@ const (
 //	'hello hello hello'.split(' ', 2)  // returns ['hello', 'hello hello']
 //	'hello hello hello'.split(' ', -1) // returns ['hello', 'hello', 'hello']
 //
+// # Strings.Join
+//
+// Introduced in version: 2
+//
+// Returns a new string where the elements of a string list are concatenated with the separator
+// placed between them. The result is built in a single buffer, which avoids the repeated copying
+// of accumulating the string with concatenation.
+//
+// strings.join(<list<string>>, <string>) -> <string>
+//
+// Examples:
+//
+//	strings.join(['hello', 'mellow'], ' ') // returns 'hello mellow'
+//	strings.join([], '/')                  // returns ''
+//
 // # Substring
 //
 // Returns the substring given a numeric range corresponding to character positions. Optionally
@@ -465,6 +519,43 @@ func (sl *stringLib) CompileOptions() []cel.EnvOption {
 				}))))
 
 	}
+	if sl.version >= 2 {
+		opts = append(opts, cel.Function("strings.join",
+			cel.Overload("strings_join_list_string", []*cel.Type{cel.ListType(cel.StringType), cel.StringType}, cel.StringType,
+				cel.BinaryBinding(func(list, sep ref.Val) ref.Val {
+					l := list.(traits.Lister)
+					s := sep.(types.String)
+					return joinList(l, string(s))
+				}))))
+	}
+	if sl.version >= 3 {
+		opts = append(opts, cel.SliceSyntax(), cel.Function("slice",
+			cel.MemberOverload("string_slice_int", []*cel.Type{cel.StringType, cel.IntType}, cel.StringType,
+				cel.BinaryBinding(func(str, start ref.Val) ref.Val {
+					return sliceString(str, start, nil, types.IntOne)
+				})),
+			cel.MemberOverload("string_slice_int_int", []*cel.Type{cel.StringType, cel.IntType, cel.IntType}, cel.StringType,
+				cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+					return sliceString(args[0], args[1], args[2], types.IntOne)
+				})),
+			cel.MemberOverload("string_slice_int_int_int", []*cel.Type{cel.StringType, cel.IntType, cel.IntType, cel.IntType}, cel.StringType,
+				cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+					return sliceString(args[0], args[1], args[2], args[3])
+				})),
+			// Slices with a step, `x[a:b:c]`, pass null for their omitted bounds.
+			cel.MemberOverload("string_slice_null_int_int", []*cel.Type{cel.StringType, cel.NullType, cel.IntType, cel.IntType}, cel.StringType,
+				cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+					return sliceString(args[0], args[1], args[2], args[3])
+				})),
+			cel.MemberOverload("string_slice_int_null_int", []*cel.Type{cel.StringType, cel.IntType, cel.NullType, cel.IntType}, cel.StringType,
+				cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+					return sliceString(args[0], args[1], args[2], args[3])
+				})),
+			cel.MemberOverload("string_slice_null_null_int", []*cel.Type{cel.StringType, cel.NullType, cel.NullType, cel.IntType}, cel.StringType,
+				cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+					return sliceString(args[0], args[1], args[2], args[3])
+				}))))
+	}
 	return opts
 }
 
@@ -473,6 +564,21 @@ func (*stringLib) ProgramOptions() []cel.ProgramOption {
 	return []cel.ProgramOption{}
 }
 
+// sliceString returns the code points of the string selected by the slice bounds. Omitted bounds
+// are nil or null.
+func sliceString(str, start, end, step ref.Val) ref.Val {
+	runes := []rune(string(str.(types.String)))
+	indices, err := sliceIndices(int64(len(runes)), start, end, step)
+	if err != nil {
+		return types.NewErr(err.Error())
+	}
+	sliced := make([]rune, len(indices))
+	for i, idx := range indices {
+		sliced[i] = runes[idx]
+	}
+	return types.String(sliced)
+}
+
 func charAt(str string, ind int64) (string, error) {
 	i := int(ind)
 	runes := []rune(str)
@@ -619,6 +725,25 @@ func joinSeparator(strs []string, separator string) (string, error) {
 	return strings.Join(strs, separator), nil
 }
 
+// joinList concatenates the elements of a list of strings with a separator, without first
+// converting the list to a native slice.
+func joinList(list traits.Lister, sep string) ref.Val {
+	var sb strings.Builder
+	it := list.Iterator()
+	for i := 0; it.HasNext() == types.True; i++ {
+		elem := it.Next()
+		str, ok := elem.(types.String)
+		if !ok {
+			return types.MaybeNoSuchOverloadErr(elem)
+		}
+		if i > 0 {
+			sb.WriteString(sep)
+		}
+		sb.WriteString(string(str))
+	}
+	return types.String(sb.String())
+}
+
 func join(strs []string) (string, error) {
 	return strings.Join(strs, ""), nil
 }
@@ -664,7 +789,12 @@ func clauseForType(argType ref.Type) (clauseImpl, error) {
 			return fmt.Sprintf("type(%s)", arg.Value().(string)), nil
 		}, nil
 	default:
-		return nil, fmt.Errorf("no formatting function for %s", argType.TypeName())
+		return func(arg ref.Val, locale string) (string, error) {
+			if str, ok, err := formatCustom(arg, "s", locale); ok {
+				return str, err
+			}
+			return "", fmt.Errorf("no formatting function for %s", argType.TypeName())
+		}, nil
 	}
 }
 
@@ -813,6 +943,9 @@ func FormatString(arg ref.Val, locale string) (string, error) {
 	case types.NullType:
 		return "null", nil
 	default:
+		if str, ok, err := formatCustom(arg, "s", locale); ok {
+			return str, err
+		}
 		return "", fmt.Errorf("string clause can only be used on strings, bools, bytes, ints, doubles, maps, lists, types, durations, and timestamps, was given %s", arg.Type().TypeName())
 	}
 }
@@ -832,6 +965,9 @@ func formatDecimal(arg ref.Val, locale string) (string, error) {
 		}
 		return fmt.Sprintf("%d", argInt), nil
 	default:
+		if str, ok, err := formatCustom(arg, "d", locale); ok {
+			return str, err
+		}
 		return "", fmt.Errorf("decimal clause can only be used on integers, was given %s", arg.Type().TypeName())
 	}
 }
@@ -902,6 +1038,25 @@ func sanitize(s string) string {
 	return sanitizedStringBuilder.String()
 }
 
+// formatCustom formats a value whose type supplies its own representation for the clause via the
+// traits.Formatter interface, reporting whether the value is of such a type.
+func formatCustom(arg ref.Val, clause, locale string) (string, bool, error) {
+	formatter, ok := arg.(traits.Formatter)
+	if !ok {
+		return "", false, nil
+	}
+	str, err := formatter.Format(clause, locale)
+	return str, true, err
+}
+
+// precisionClause returns the text of a clause with an optional precision, e.g. `.2f`.
+func precisionClause(precision *int, verb rune) string {
+	if precision == nil {
+		return string(verb)
+	}
+	return fmt.Sprintf(".%d%c", *precision, verb)
+}
+
 type stringFormatter struct{}
 
 func (c *stringFormatter) String(arg ref.Val, locale string) (string, error) {
@@ -913,6 +1068,7 @@ func (c *stringFormatter) Decimal(arg ref.Val, locale string) (string, error) {
 }
 
 func (c *stringFormatter) Fixed(precision *int) func(ref.Val, string) (string, error) {
+	clause := precisionClause(precision, 'f')
 	if precision == nil {
 		precision = new(int)
 		*precision = defaultPrecision
@@ -926,6 +1082,9 @@ func (c *stringFormatter) Fixed(precision *int) func(ref.Val, string) (string, e
 			}
 		}
 		if arg.Type() != types.DoubleType && !strException {
+			if str, ok, err := formatCustom(arg, clause, locale); ok {
+				return str, err
+			}
 			return "", fmt.Errorf("fixed-point clause can only be used on doubles, was given %s", arg.Type().TypeName())
 		}
 		argFloatVal := arg.ConvertToType(types.DoubleType)
@@ -944,6 +1103,7 @@ func (c *stringFormatter) Fixed(precision *int) func(ref.Val, string) (string, e
 }
 
 func (c *stringFormatter) Scientific(precision *int) func(ref.Val, string) (string, error) {
+	clause := precisionClause(precision, 'e')
 	if precision == nil {
 		precision = new(int)
 		*precision = defaultPrecision
@@ -957,6 +1117,9 @@ func (c *stringFormatter) Scientific(precision *int) func(ref.Val, string) (stri
 			}
 		}
 		if arg.Type() != types.DoubleType && !strException {
+			if str, ok, err := formatCustom(arg, clause, locale); ok {
+				return str, err
+			}
 			return "", fmt.Errorf("scientific clause can only be used on doubles, was given %s", arg.Type().TypeName())
 		}
 		argFloatVal := arg.ConvertToType(types.DoubleType)
@@ -990,6 +1153,9 @@ func (c *stringFormatter) Binary(arg ref.Val, locale string) (string, error) {
 		}
 		return "0", nil
 	default:
+		if str, ok, err := formatCustom(arg, "b", locale); ok {
+			return str, err
+		}
 		return "", fmt.Errorf("only integers and bools can be formatted as binary, was given %s", arg.Type().TypeName())
 	}
 }
@@ -1019,6 +1185,9 @@ func (c *stringFormatter) Hex(useUpper bool) func(ref.Val, string) (string, erro
 			}
 			return fmt.Sprintf(fmtStr, argInt), nil
 		default:
+			if str, ok, err := formatCustom(arg, fmtStr[1:], locale); ok {
+				return str, err
+			}
 			return "", fmt.Errorf("only integers, byte buffers, and strings can be formatted as hex, was given %s", arg.Type().TypeName())
 		}
 	}
@@ -1033,6 +1202,9 @@ func (c *stringFormatter) Octal(arg ref.Val, locale string) (string, error) {
 		argInt := arg.Value().(uint64)
 		return fmt.Sprintf("%o", argInt), nil
 	default:
+		if str, ok, err := formatCustom(arg, "o", locale); ok {
+			return str, err
+		}
 		return "", fmt.Errorf("octal clause can only be used on integers, was given %s", arg.Type().TypeName())
 	}
 }
@@ -27,6 +27,7 @@ import (
 	"github.com/google/cel-go/checker"
 	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
 	proto3pb "github.com/google/cel-go/test/proto3pb"
 )
 
@@ -91,6 +92,24 @@ var stringTests = []struct {
 	{expr: `"tacocat".substring(4, 4) == ""`},
 	{expr: `'ta©o©αT'.substring(2, 6) == "©o©α"`},
 	{expr: `'ta©o©αT'.substring(7, 7) == ""`},
+	// Slice tests.
+	{expr: `'tacocat'.slice(-3) == 'cat'`},
+	{expr: `'tacocat'.slice(0, -3) == 'taco'`},
+	{expr: `'tacocat'.slice(0, 7, 2) == 'tcct'`},
+	{expr: `'tacocat'.slice(2, 100) == 'cocat'`},
+	{expr: `'tacocat'.slice(5, 2) == ''`},
+	{expr: `'ta©o©αT'.slice(-3, -1) == '©α'`},
+	{expr: `'héllo'.slice(-1, -6, -1) == 'olléh'`},
+	{expr: `'héllo'.slice(-1, -100, -2) == 'olh'`},
+	{expr: `'tacocat'.slice(1, 3, 9223372036854775807) == 'a'`},
+	{expr: `'tacocat'.slice(5, 0, -9223372036854775808) == 'a'`},
+	{expr: `'tacocat'[-3:] == 'cat'`},
+	{expr: `'tacocat'[:4] == 'taco'`},
+	{expr: `'tacocat'[0:7:2] == 'tcct'`},
+	{expr: `'ta©o©αT'[-3:-1] == '©α'`},
+	{expr: `'héllo'[-1:-6:-1] == 'olléh'`},
+	{expr: `'héllo'[::-1] == 'olléh'`},
+	{expr: `'tacocat'[1::2] == 'aoa'`},
 	// Trim tests using the unicode standard for whitespace.
 	{expr: `" \f\n\r\t\vtext  ".trim() == "text"`},
 	{expr: `"\u0085\u00a0\u1680text".trim() == "text"`},
@@ -107,6 +126,10 @@ var stringTests = []struct {
 	{expr: `['x', 'y'].join('-') == 'x-y'`},
 	{expr: `[].join() == ''`},
 	{expr: `[].join('-') == ''`},
+	{expr: `strings.join(['x', 'y', 'z'], ', ') == 'x, y, z'`},
+	{expr: `strings.join(['x'], '-') == 'x'`},
+	{expr: `strings.join([], '-') == ''`},
+	{expr: `strings.join(dyn(['x', 1]), '-') == ''`, err: "no such overload"},
 	// Escaping tests.
 	{expr: `strings.quote("first\nsecond") == "\"first\\nsecond\""`},
 	{expr: `strings.quote("bell\a") == "\"bell\\a\""`},
@@ -130,6 +153,10 @@ var stringTests = []struct {
 	{expr: `strings.quote("ta©o©αT") == "\"ta©o©αT\""`},
 	{expr: `strings.quote("") == "\"\""`},
 	// Error test cases based on checked expression usage.
+	{
+		expr: `'tacocat'.slice(0, 7, 0) == ''`,
+		err:  "slice step cannot be zero",
+	},
 	{
 		expr: `'tacocat'.charAt(30) == ''`,
 		err:  "index out of range: 30",
@@ -376,6 +403,18 @@ func TestVersions(t *testing.T) {
 				"quote":  `strings.quote('\a \b "double quotes"')`,
 			},
 		},
+		{
+			version: 2,
+			supportedFunctions: map[string]string{
+				"strings.join": "strings.join(['a', 'b'], '-')",
+			},
+		},
+		{
+			version: 3,
+			supportedFunctions: map[string]string{
+				"slice": "'tacocat'.slice(-3)",
+			},
+		},
 	}
 	for _, lib := range versionCases {
 		env, err := cel.NewEnv(Strings(StringsVersion(lib.version)))
@@ -1263,6 +1302,89 @@ func TestStringFormat(t *testing.T) {
 	}
 }
 
+func TestStringFormatCustomType(t *testing.T) {
+	env, err := cel.NewEnv(
+		Strings(),
+		cel.Types(testIPType),
+		cel.Variable("addr", cel.OpaqueType(testIPType.TypeName())),
+	)
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		expr string
+		out  string
+		err  string
+	}{
+		{expr: `"%s".format([addr])`, out: "10.0.0.1"},
+		{expr: `"%X".format([addr])`, out: "0A000001"},
+		{expr: `"%s".format([[addr, addr]])`, out: "[10.0.0.1, 10.0.0.1]"},
+		{expr: `"%.2f".format([addr])`, err: "unsupported clause for ip: .2f"},
+	}
+	for _, tst := range tests {
+		ast, iss := env.Compile(tst.expr)
+		if iss.Err() != nil {
+			t.Fatalf("env.Compile(%q) failed: %v", tst.expr, iss.Err())
+		}
+		prg, err := env.Program(ast, cel.EvalOptions(cel.OptCheckStringFormat))
+		if err != nil {
+			t.Fatalf("env.Program(%q) failed: %v", tst.expr, err)
+		}
+		out, _, err := prg.Eval(map[string]any{"addr": testIP{10, 0, 0, 1}})
+		if tst.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tst.err) {
+				t.Errorf("prg.Eval(%q) got %v, %v, wanted error %q", tst.expr, out, err, tst.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("prg.Eval(%q) failed: %v", tst.expr, err)
+		}
+		if out.Value() != tst.out {
+			t.Errorf("prg.Eval(%q) got %v, wanted %q", tst.expr, out, tst.out)
+		}
+	}
+}
+
+var testIPType = types.NewTypeValue("ip", traits.FormatterType)
+
+// testIP is an IPv4 address which supplies its own representation within formatted strings.
+type testIP [4]byte
+
+func (ip testIP) ConvertToNative(typeDesc reflect.Type) (any, error) {
+	return nil, fmt.Errorf("type conversion error from ip to '%v'", typeDesc)
+}
+
+func (ip testIP) ConvertToType(typeVal ref.Type) ref.Val {
+	if typeVal == types.TypeType {
+		return testIPType
+	}
+	return types.NewErr("type conversion error from ip to '%s'", typeVal)
+}
+
+func (ip testIP) Equal(other ref.Val) ref.Val {
+	o, ok := other.(testIP)
+	return types.Bool(ok && ip == o)
+}
+
+func (ip testIP) Type() ref.Type {
+	return testIPType
+}
+
+func (ip testIP) Value() any {
+	return [4]byte(ip)
+}
+
+func (ip testIP) Format(clause, locale string) (string, error) {
+	switch clause {
+	case "s":
+		return fmt.Sprintf("%d.%d.%d.%d", ip[0], ip[1], ip[2], ip[3]), nil
+	case "x", "X":
+		return fmt.Sprintf("%"+clause, ip[:]), nil
+	}
+	return "", fmt.Errorf("unsupported clause for ip: %s", clause)
+}
+
 func TestBadLocale(t *testing.T) {
 	_, err := cel.NewEnv(Strings(StringsLocale("bad-locale")))
 	if err != nil {
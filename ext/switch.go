@@ -0,0 +1,128 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ext
+
+import (
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/operators"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Switch returns a cel.EnvOption to configure the cel.switch() macro, which selects a result by
+// matching a single subject against a series of cases.
+//
+// # Cel.Switch
+//
+// Compares the subject to each case in order and returns the result of the first case which is
+// equal to the subject, or the default when no case matches.
+//
+//	cel.switch(<subject>, <case>, <result>, [<case>, <result>, ...] <default>)
+//
+// When the first argument is a simple identifier followed by the subject, the subject is bound to
+// the identifier, and each case is a boolean predicate which may refer to the identifier rather
+// than a value to compare against.
+//
+//	cel.switch(<varName>, <subject>, <predicate>, <result>, [<predicate>, <result>, ...] <default>)
+//
+// The two forms are distinguished by the number of arguments, which is even for the first form
+// and odd for the second. Both forms evaluate the subject once, and are expanded into a chain of
+// conditional expressions.
+//
+// Examples:
+//
+//	cel.switch(request.method, 'GET', 'read', 'PUT', 'write', 'DELETE', 'write', 'unknown')
+//
+//	cel.switch(code, response.code,
+//	  code >= 500, 'server-error',
+//	  code >= 400, 'client-error',
+//	  'ok')
+func Switch() cel.EnvOption {
+	return cel.Lib(celSwitch{})
+}
+
+const (
+	switchMacro      = "switch"
+	switchSubjectVar = "@switch"
+	// switchIterVar is distinct from the iteration variable of cel.bind() so that unreferenced
+	// switch variables are not reported as unused bindings.
+	switchIterVar = "#switch"
+)
+
+type celSwitch struct{}
+
+func (celSwitch) LibraryName() string {
+	return "cel.lib.ext.cel.switch"
+}
+
+func (celSwitch) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Macros(
+			// cel.switch(<subject>, <case>, <result>, ..., <default>)
+			// cel.switch(var, <subject>, <predicate>, <result>, ..., <default>)
+			cel.NewReceiverVarArgMacro(switchMacro, celSwitchExpander),
+		),
+	}
+}
+
+func (celSwitch) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func celSwitchExpander(meh cel.MacroExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+	if !macroTargetMatchesNamespace(celNamespace, target) {
+		return nil, nil
+	}
+	if len(args) < 3 {
+		return nil, &common.Error{
+			Message:  "cel.switch() requires a subject, at least one case and result, and a default",
+			Location: meh.OffsetLocation(target.GetId()),
+		}
+	}
+	varName := switchSubjectVar
+	subject := args[0]
+	cases := args[1 : len(args)-1]
+	matchCase := func(c *exprpb.Expr) *exprpb.Expr {
+		return meh.GlobalCall(operators.Equals, meh.Ident(varName), c)
+	}
+	if len(args)%2 != 0 {
+		if args[0].GetIdentExpr() == nil {
+			return nil, &common.Error{
+				Message:  "cel.switch() variable names must be simple identifiers",
+				Location: meh.OffsetLocation(args[0].GetId()),
+			}
+		}
+		varName = args[0].GetIdentExpr().GetName()
+		subject = args[1]
+		cases = args[2 : len(args)-1]
+		matchCase = func(c *exprpb.Expr) *exprpb.Expr {
+			return c
+		}
+	}
+	result := args[len(args)-1]
+	for i := len(cases) - 2; i >= 0; i -= 2 {
+		result = meh.GlobalCall(operators.Conditional, matchCase(cases[i]), cases[i+1], result)
+	}
+	return meh.Fold(
+		switchIterVar,
+		meh.NewList(),
+		varName,
+		subject,
+		meh.LiteralBool(false),
+		meh.Ident(varName),
+		result,
+	), nil
+}
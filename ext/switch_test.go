@@ -0,0 +1,117 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ext
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestSwitch(t *testing.T) {
+	tests := []string{
+		`cel.switch('PUT', 'GET', 'read', 'PUT', 'write', 'unknown') == 'write'`,
+		`cel.switch('HEAD', 'GET', 'read', 'PUT', 'write', 'unknown') == 'unknown'`,
+		`cel.switch(1 + 1, 1, 'one', 2, 'two', 'many') == 'two'`,
+		`cel.switch(code, 404,
+		   code >= 500, 'server-error',
+		   code >= 400, 'client-error',
+		   'ok') == 'client-error'`,
+		`cel.switch(code, 200, code >= 400, 'error', 'ok') == 'ok'`,
+		// The result may refer to the bound variable.
+		`cel.switch(s, 'hello', s.size() > 3, s + '!', s) == 'hello!'`,
+		// The bound variable shadows variables of the same name only within the switch.
+		`cel.bind(s, 'outer', cel.switch(s, 'inner', true, s, s) + s) == 'innerouter'`,
+		// Cases are matched in order.
+		`cel.switch(x, 5, x > 1, 'first', x > 2, 'second', 'none') == 'first'`,
+		`cel.switch(cel.switch(1, 1, 'a', 'b'), 'a', true, false)`,
+		// Cases which are not matched are not evaluated.
+		`cel.switch(1, 1, true, 2, 1 / 0 == 0, false)`,
+	}
+	env, err := cel.NewEnv(Switch(), Bindings(), Strings())
+	if err != nil {
+		t.Fatalf("cel.NewEnv(Switch()) failed: %v", err)
+	}
+	for i, tst := range tests {
+		expr := tst
+		t.Run(fmt.Sprintf("[%d]", i), func(t *testing.T) {
+			ast, iss := env.Compile(expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%v) failed: %v", expr, iss.Err())
+			}
+			if len(ast.Warnings()) != 0 {
+				t.Errorf("env.Compile(%v) produced warnings: %v", expr, ast.Warnings())
+			}
+			prg, err := env.Program(ast)
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			out, _, err := prg.Eval(cel.NoVars())
+			if err != nil {
+				t.Fatalf("prg.Eval() failed: %v", err)
+			}
+			if out.Value() != true {
+				t.Errorf("got %v, wanted true for expr: %s", out.Value(), expr)
+			}
+		})
+	}
+}
+
+func TestSwitchNonMatch(t *testing.T) {
+	env, err := cel.NewEnv(Switch())
+	if err != nil {
+		t.Fatalf("cel.NewEnv(Switch()) failed: %v", err)
+	}
+	nonMatchExpr := `x.switch(1, 1, 'one', 'other')`
+	ast, iss := env.Parse(nonMatchExpr)
+	if iss.Err() != nil {
+		t.Fatalf("env.Parse(%v) failed: %v", nonMatchExpr, iss.Err())
+	}
+	if len(ast.SourceInfo().GetMacroCalls()) != 0 {
+		t.Fatalf("env.Parse(%v) performed a macro replacement when none was expected: %v",
+			nonMatchExpr, ast.SourceInfo().GetMacroCalls())
+	}
+}
+
+func TestSwitchParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{
+			expr: `cel.switch(1, 'one')`,
+			err:  "ERROR: <input>:1:1: cel.switch() requires a subject, at least one case and result, and a default",
+		},
+		{
+			expr: `cel.switch(a.b, 1, a.b > 0, 'positive', 'other')`,
+			err:  "ERROR: <input>:1:13: cel.switch() variable names must be simple identifiers",
+		},
+	}
+	env, err := cel.NewEnv(Switch())
+	if err != nil {
+		t.Fatalf("cel.NewEnv(Switch()) failed: %v", err)
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			_, iss := env.Parse(tc.expr)
+			if iss.Err() == nil || !strings.Contains(iss.Err().Error(), tc.err) {
+				t.Errorf("env.Parse(%v) got %v, wanted error containing %q", tc.expr, iss.Err(), tc.err)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ require (
 	golang.org/x/text v0.6.0
 	google.golang.org/genproto v0.0.0-20221207170731-23e4bf6bdc37
 	google.golang.org/protobuf v1.28.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require golang.org/x/exp v0.0.0-20220722155223-a9213eeb770e // indirect
@@ -15,6 +15,7 @@
 package interpreter
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
@@ -108,6 +109,54 @@ func (a *mapActivation) ResolveName(name string) (any, bool) {
 	return obj, found
 }
 
+// NewLazyActivation returns an activation which resolves variables by calling the resolver, such
+// as one which reads the variables from a database or another service, and which memoizes the
+// result of each call, including whether the variable was found.
+//
+// The resolver is called at most once per name over the lifetime of the activation, even when the
+// variable is referenced many times or by concurrent evaluation steps, so a new activation should
+// be created for each evaluation in which the values may differ.
+func NewLazyActivation(resolver func(name string) (any, bool)) Activation {
+	return &lazyActivation{resolver: resolver, entries: map[string]*lazyEntry{}}
+}
+
+// lazyActivation memoizes the values resolved by a user callback.
+type lazyActivation struct {
+	resolver func(name string) (any, bool)
+
+	mu      sync.Mutex
+	entries map[string]*lazyEntry
+}
+
+// lazyEntry records the result of resolving a single name, which is computed at most once.
+type lazyEntry struct {
+	once  sync.Once
+	val   any
+	found bool
+}
+
+// Parent implements the Activation interface method.
+func (a *lazyActivation) Parent() Activation {
+	return nil
+}
+
+// ResolveName implements the Activation interface method.
+func (a *lazyActivation) ResolveName(name string) (any, bool) {
+	a.mu.Lock()
+	entry, found := a.entries[name]
+	if !found {
+		entry = &lazyEntry{}
+		a.entries[name] = entry
+	}
+	a.mu.Unlock()
+	// The resolver is called outside of the lock so that distinct names may be resolved
+	// concurrently.
+	entry.once.Do(func() {
+		entry.val, entry.found = a.resolver(name)
+	})
+	return entry.val, entry.found
+}
+
 // hierarchicalActivation which implements Activation and contains a parent and
 // child activation.
 type hierarchicalActivation struct {
@@ -128,6 +177,71 @@ func (a *hierarchicalActivation) ResolveName(name string) (any, bool) {
 	return a.parent.ResolveName(name)
 }
 
+// EvalContext returns the context.Context of an evaluation against the activation, or
+// context.Background() when the activation does not supply one.
+//
+// Activations supply the context as the value of the special `#context` variable, which cannot be
+// referenced from expressions.
+func EvalContext(vars Activation) context.Context {
+	if vars == nil {
+		return context.Background()
+	}
+	if val, found := vars.ResolveName("#context"); found {
+		if ctx, isCtx := val.(context.Context); isCtx && ctx != nil {
+			return ctx
+		}
+	}
+	return context.Background()
+}
+
+// cancellableActivation exposes the state of a context.Context to the comprehensions marked as
+// interruptable via the `#interrupted` variable, and the context itself via the `#context`
+// variable.
+type cancellableActivation struct {
+	parent Activation
+	ctx    context.Context
+}
+
+// Parent implements the Activation interface method.
+func (a *cancellableActivation) Parent() Activation {
+	return a.parent
+}
+
+// ResolveName implements the Activation interface method.
+func (a *cancellableActivation) ResolveName(name string) (any, bool) {
+	switch name {
+	case "#interrupted":
+		select {
+		case <-a.ctx.Done():
+			return true, true
+		default:
+			return false, true
+		}
+	case "#context":
+		return a.ctx, true
+	}
+	return a.parent.ResolveName(name)
+}
+
+// streamActivation supplies the sink of the streaming comprehensions via the `#stream` variable.
+type streamActivation struct {
+	parent Activation
+	sink   ElementSink
+}
+
+// Parent implements the Activation interface method.
+func (a *streamActivation) Parent() Activation {
+	return a.parent
+}
+
+// ResolveName implements the Activation interface method.
+func (a *streamActivation) ResolveName(name string) (any, bool) {
+	if name == "#stream" {
+		return a.sink, true
+	}
+	return a.parent.ResolveName(name)
+}
+
 // NewHierarchicalActivation takes two activations and produces a new one which prioritizes
 // resolution in the child first and parent(s) second.
 func NewHierarchicalActivation(parent Activation, child Activation) Activation {
@@ -199,3 +313,81 @@ var (
 		},
 	}
 )
+
+// ActivationPool recycles map-backed and hierarchical activations between evaluations so that
+// request handlers can avoid allocating new activations for each request.
+//
+// Activations must not be returned to the pool while an evaluation which uses them is in progress,
+// and must not be used once they have been returned. An ActivationPool is safe for concurrent use.
+type ActivationPool struct {
+	maps         sync.Pool
+	hierarchical sync.Pool
+}
+
+// NewActivationPool creates an empty ActivationPool.
+func NewActivationPool() *ActivationPool {
+	return &ActivationPool{
+		maps: sync.Pool{
+			New: func() any {
+				return &PooledActivation{mapActivation{bindings: map[string]any{}}}
+			},
+		},
+		hierarchical: sync.Pool{
+			New: func() any {
+				return &hierarchicalActivation{}
+			},
+		},
+	}
+}
+
+// Get returns an empty map-backed activation from the pool.
+func (p *ActivationPool) Get() *PooledActivation {
+	return p.maps.Get().(*PooledActivation)
+}
+
+// Put resets the map-backed activation and returns it to the pool.
+func (p *ActivationPool) Put(a *PooledActivation) {
+	a.Reset()
+	p.maps.Put(a)
+}
+
+// GetHierarchical returns an activation from the pool which prioritizes resolution in the child
+// activation before the parent, as with NewHierarchicalActivation.
+func (p *ActivationPool) GetHierarchical(parent, child Activation) Activation {
+	a := p.hierarchical.Get().(*hierarchicalActivation)
+	a.parent = parent
+	a.child = child
+	return a
+}
+
+// PutHierarchical returns an activation created by GetHierarchical to the pool. Other activation
+// types are ignored.
+func (p *ActivationPool) PutHierarchical(a Activation) {
+	h, ok := a.(*hierarchicalActivation)
+	if !ok {
+		return
+	}
+	h.parent = nil
+	h.child = nil
+	p.hierarchical.Put(h)
+}
+
+// PooledActivation is a map-backed Activation whose bindings may be reset and reused.
+//
+// As with NewActivation, lazy bindings may be supplied as either `func() any` or `func() ref.Val`
+// values.
+type PooledActivation struct {
+	mapActivation
+}
+
+// Bind sets the value of the named variable.
+func (a *PooledActivation) Bind(name string, value any) {
+	a.bindings[name] = value
+}
+
+// Reset removes all bindings from the activation while retaining its allocated storage.
+func (a *PooledActivation) Reset() {
+	for name := range a.bindings {
+		delete(a.bindings, name)
+	}
+}
@@ -15,6 +15,7 @@
 package interpreter
 
 import (
+	"sync"
 	"testing"
 	"time"
 
@@ -63,6 +64,42 @@ func TestActivation_ResolveLazy(t *testing.T) {
 	}
 }
 
+func TestLazyActivation(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	a := NewLazyActivation(func(name string) (any, bool) {
+		mu.Lock()
+		calls[name]++
+		mu.Unlock()
+		if name == "x" {
+			return types.Int(1), true
+		}
+		return nil, false
+	})
+	prg, _, err := program(t, &testCase{expr: `x + x + x == 3 && [1, 2, 3].all(i, i <= x * 3)`, unchecked: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if out := prg.Eval(a); out != types.True {
+				t.Errorf("prg.Eval() got %v, wanted true", out)
+			}
+		}()
+	}
+	wg.Wait()
+	if _, found := a.ResolveName("y"); found {
+		t.Error("a.ResolveName('y') found an unresolvable variable")
+	}
+	a.ResolveName("y")
+	if calls["x"] != 1 || calls["y"] != 1 {
+		t.Errorf("resolver called %v times, wanted once per name", calls)
+	}
+}
+
 func TestHierarchicalActivation(t *testing.T) {
 	// compose a parent with more properties than the child
 	parent, _ := NewActivation(map[string]any{
@@ -89,3 +126,36 @@ func TestHierarchicalActivation(t *testing.T) {
 		t.Error("Activation failed to resolve child value of 'c'")
 	}
 }
+
+func TestActivationPool(t *testing.T) {
+	pool := NewActivationPool()
+	defaults := pool.Get()
+	defaults.Bind("region", "us")
+	for _, user := range []string{"alice", "bob"} {
+		vars := pool.Get()
+		if _, found := vars.ResolveName("user"); found {
+			t.Error("pool.Get() returned an activation with stale bindings")
+		}
+		vars.Bind("user", user)
+		vars.Bind("lazy", func() any { return user + "@example.com" })
+		a := pool.GetHierarchical(defaults, vars)
+		if got, found := a.ResolveName("user"); !found || got != user {
+			t.Errorf("a.ResolveName('user') got %v, %v, wanted %s", got, found, user)
+		}
+		if got, found := a.ResolveName("lazy"); !found || got != user+"@example.com" {
+			t.Errorf("a.ResolveName('lazy') got %v, %v, wanted %s@example.com", got, found, user)
+		}
+		if got, found := a.ResolveName("region"); !found || got != "us" {
+			t.Errorf("a.ResolveName('region') got %v, %v, wanted us", got, found)
+		}
+		if a.Parent() != defaults {
+			t.Errorf("a.Parent() got %v, wanted the default activation", a.Parent())
+		}
+		pool.PutHierarchical(a)
+		pool.Put(vars)
+	}
+	defaults.Reset()
+	if _, found := defaults.ResolveName("region"); found {
+		t.Error("defaults.Reset() did not remove the bindings")
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/interpreter/functions"
+)
+
+// CompareAggregates returns an InterpretableDecorator which evaluates the ordering comparison
+// operators `<`, `<=`, `>`, and `>=` of two lists or of two maps as described by
+// types.CompareAggregates.
+//
+// Lists and maps do not declare the traits.ComparerType trait, so the standard implementations
+// of the operators reject them. The implementations are resolved from the dispatcher and invoked
+// as usual when either operand is not a list or a map.
+func CompareAggregates(disp Dispatcher) InterpretableDecorator {
+	return func(i Interpretable) (Interpretable, error) {
+		call, ok := i.(InterpretableCall)
+		if !ok || len(call.Args()) != 2 {
+			return i, nil
+		}
+		matches, found := aggregateComparisonResults[call.Function()]
+		if !found {
+			return i, nil
+		}
+		impl, found := disp.FindOverload(call.OverloadID())
+		if !found {
+			impl, found = disp.FindOverload(call.Function())
+		}
+		if !found {
+			return i, nil
+		}
+		return &evalAggregateComparison{InterpretableCall: call, impl: impl, matches: matches}, nil
+	}
+}
+
+// aggregateComparisonResults holds the results of the comparison of two values for which each
+// ordering comparison operator holds.
+var aggregateComparisonResults = map[string][]types.Int{
+	operators.Less:          {types.IntNegOne},
+	operators.LessEquals:    {types.IntNegOne, types.IntZero},
+	operators.Greater:       {types.IntOne},
+	operators.GreaterEquals: {types.IntOne, types.IntZero},
+}
+
+// evalAggregateComparison evaluates an ordering comparison operator which supports lists and
+// maps.
+type evalAggregateComparison struct {
+	InterpretableCall
+	impl    *functions.Overload
+	matches []types.Int
+}
+
+// Eval implements the Interpretable interface method.
+func (call *evalAggregateComparison) Eval(ctx Activation) ref.Val {
+	argVals, errVal := evalCallArgs(ctx, call, resolveCallImpl(ctx, call, call.impl))
+	if errVal != nil {
+		return errVal
+	}
+	return call.applyArgs(ctx, argVals)
+}
+
+// applyArgs implements the callApplier interface method.
+func (call *evalAggregateComparison) applyArgs(ctx Activation, argVals []ref.Val) ref.Val {
+	if !isAggregate(argVals[0]) || !isAggregate(argVals[1]) {
+		return applyCall(ctx, call.InterpretableCall, call.impl, argVals)
+	}
+	cmp := types.CompareAggregates(argVals[0], argVals[1])
+	if types.IsUnknownOrError(cmp) {
+		return cmp
+	}
+	for _, m := range call.matches {
+		if cmp == m {
+			return types.True
+		}
+	}
+	return types.False
+}
+
+func isAggregate(val ref.Val) bool {
+	switch val.(type) {
+	case traits.Lister, traits.Mapper:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewCachingAttributeFactory returns an AttributeFactory whose absolute attributes memoize their
+// resolution results within an Activation created by NewAttributeCacheActivation.
+//
+// Attributes are cached by variable name and qualifier path, so the repeated use of an attribute
+// such as `request.user.id` within an expression is only resolved once per evaluation. Attributes
+// with non-constant qualifiers, such as `request.users[index]`, are not cached.
+//
+// When evaluation does not occur within an attribute cache activation, attributes are resolved
+// by the wrapped AttributeFactory as usual.
+func NewCachingAttributeFactory(fac AttributeFactory) AttributeFactory {
+	return &cachingAttributeFactory{AttributeFactory: fac}
+}
+
+// NewAttributeCacheActivation returns an Activation which holds the attribute resolution cache
+// for a single evaluation against the input `vars`.
+//
+// The activation is not safe for concurrent use and should be discarded once evaluation is
+// complete, as the cached values are not invalidated when the underlying variables change.
+//
+// When `vars` is already an attribute cache activation it is returned as-is, so that a single
+// cache may be shared by the evaluation of several programs against the same input.
+func NewAttributeCacheActivation(vars Activation) Activation {
+	if cache, isCache := vars.(*attributeCacheActivation); isCache {
+		return cache
+	}
+	return &attributeCacheActivation{
+		Activation: vars,
+		entries:    map[string]cachedResolution{},
+	}
+}
+
+type cachingAttributeFactory struct {
+	AttributeFactory
+}
+
+// AbsoluteAttribute implementation of the AttributeFactory interface which wraps the
+// NamespacedAttribute resolution in an internal cachedAttribute object.
+func (fac *cachingAttributeFactory) AbsoluteAttribute(id int64, names ...string) NamespacedAttribute {
+	attr := fac.AttributeFactory.AbsoluteAttribute(id, names...)
+	return &cachedAttribute{
+		NamespacedAttribute: attr,
+		fac:                 fac,
+		key:                 strings.Join(names, ","),
+		cacheable:           true,
+	}
+}
+
+// cachedAttribute embeds the NamespacedAttribute interface and tracks the cache key of the
+// attribute's variable names and qualifier path.
+type cachedAttribute struct {
+	NamespacedAttribute
+	fac       AttributeFactory
+	key       string
+	cacheable bool
+}
+
+// AddQualifier implements the Attribute interface method.
+func (a *cachedAttribute) AddQualifier(qual Qualifier) (Attribute, error) {
+	_, err := a.NamespacedAttribute.AddQualifier(qual)
+	if err != nil {
+		return nil, err
+	}
+	constQual, isConst := qual.(ConstantQualifier)
+	if !isConst {
+		a.cacheable = false
+		return a, nil
+	}
+	val := constQual.Value()
+	opt := ""
+	if qual.IsOptional() {
+		opt = "?"
+	}
+	a.key = fmt.Sprintf("%s%s[%s(%v)]", a.key, opt, val.Type().TypeName(), val.Value())
+	return a, nil
+}
+
+// Resolve is an implementation of the NamespacedAttribute interface method which returns the
+// cached result of resolving the attribute when available.
+func (a *cachedAttribute) Resolve(vars Activation) (any, error) {
+	if !a.cacheable {
+		return a.NamespacedAttribute.Resolve(vars)
+	}
+	cache, found := a.findCache(vars)
+	if !found {
+		return a.NamespacedAttribute.Resolve(vars)
+	}
+	if entry, found := cache.entries[a.key]; found {
+		return entry.val, entry.err
+	}
+	val, err := a.NamespacedAttribute.Resolve(vars)
+	cache.entries[a.key] = cachedResolution{val: val, err: err}
+	return val, err
+}
+
+// findCache returns the attribute cache activation which encloses the input activation, provided
+// that none of the activations between the two bind one of the attribute's variable names, as is
+// the case for comprehension variables.
+func (a *cachedAttribute) findCache(vars Activation) (*attributeCacheActivation, bool) {
+	for vars != nil {
+		switch v := vars.(type) {
+		case *attributeCacheActivation:
+			return v, true
+		case *varActivation:
+			for _, name := range a.CandidateVariableNames() {
+				if v.name == name {
+					return nil, false
+				}
+			}
+		default:
+			// The bindings of other activations are not known, so caching is not safe.
+			return nil, false
+		}
+		vars = vars.Parent()
+	}
+	return nil, false
+}
+
+// Qualify is an implementation of the Qualifier interface method.
+func (a *cachedAttribute) Qualify(vars Activation, obj any) (any, error) {
+	return attrQualify(a.fac, vars, obj, a)
+}
+
+// QualifyIfPresent is an implementation of the Qualifier interface method.
+func (a *cachedAttribute) QualifyIfPresent(vars Activation, obj any, presenceOnly bool) (any, bool, error) {
+	return attrQualifyIfPresent(a.fac, vars, obj, a, presenceOnly)
+}
+
+type cachedResolution struct {
+	val any
+	err error
+}
+
+// attributeCacheActivation holds the results of attribute resolution for a single evaluation.
+type attributeCacheActivation struct {
+	Activation
+	entries map[string]cachedResolution
+}
+
+// Parent implements the Activation interface method.
+//
+// The wrapped activation is returned as the parent so that the search for a PartialActivation
+// among an activation's parents considers the wrapped activation.
+func (a *attributeCacheActivation) Parent() Activation {
+	return a.Activation
+}
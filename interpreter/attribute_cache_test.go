@@ -0,0 +1,124 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/containers"
+	"github.com/google/cel-go/common/types"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestCachingAttributeFactory(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		// resolutions is the number of times the variable 'a' is resolved with caching.
+		resolutions int
+	}{
+		{
+			name:        "repeated_select",
+			expr:        `a.b.c + a.b.c + a.b.c == 3`,
+			resolutions: 1,
+		},
+		{
+			name:        "distinct_paths",
+			expr:        `a.b.c == 1 && a.b.d == 'x' && a.b.c != 2 && a.b.d != 'y'`,
+			resolutions: 2,
+		},
+		{
+			name:        "shadowed_by_comprehension",
+			expr:        `a.b.c == 1 && [{'b': {'c': 2}}].all(a, a.b.c == 2) && a.b.c == 1`,
+			resolutions: 1,
+		},
+		{
+			name:        "outer_variable_within_comprehension",
+			expr:        `[1, 2, 3].all(x, a.b.c == 1)`,
+			resolutions: 1,
+		},
+		{
+			name:        "dynamic_qualifier",
+			expr:        `a.b[k] == 1 && a.b[k] == 1`,
+			resolutions: 2,
+		},
+		{
+			name:        "missing_key",
+			expr:        `a.b.missing == 1 || a.b.missing == 2`,
+			resolutions: 1,
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.name, func(t *testing.T) {
+			reg := newTestRegistry(t)
+			cont := containers.DefaultContainer
+			test := &testCase{
+				expr: tc.expr,
+				env: []*exprpb.Decl{
+					decls.NewVar("a", decls.NewMapType(decls.String, decls.NewMapType(decls.String, decls.Dyn))),
+					decls.NewVar("k", decls.String),
+				},
+				attrs: NewCachingAttributeFactory(NewAttributeFactory(cont, reg, reg)),
+			}
+			prg, _, err := program(t, test)
+			if err != nil {
+				t.Fatal(err)
+			}
+			vars := &countingActivation{
+				Activation: mustActivation(t, map[string]any{
+					"a": map[string]map[string]any{"b": {"c": 1, "d": "x"}},
+					"k": "c",
+				}),
+				counts: map[string]int{},
+			}
+			uncached := prg.Eval(vars)
+			uncachedCount := vars.counts["a"]
+
+			vars.counts = map[string]int{}
+			cached := prg.Eval(NewAttributeCacheActivation(vars))
+			if cached.Equal(uncached) != types.True && !(types.IsError(cached) && types.IsError(uncached)) {
+				t.Errorf("prg.Eval() with attribute cache got %v, wanted %v", cached, uncached)
+			}
+			if vars.counts["a"] != tc.resolutions {
+				t.Errorf("prg.Eval() resolved 'a' %d times with caching, wanted %d (%d without)",
+					vars.counts["a"], tc.resolutions, uncachedCount)
+			}
+		})
+	}
+}
+
+// countingActivation records the number of times each variable is resolved.
+type countingActivation struct {
+	Activation
+	counts map[string]int
+}
+
+// ResolveName implements the Activation interface method.
+func (a *countingActivation) ResolveName(name string) (any, bool) {
+	a.counts[name]++
+	return a.Activation.ResolveName(name)
+}
+
+func mustActivation(t *testing.T, in map[string]any) Activation {
+	t.Helper()
+	vars, err := NewActivation(in)
+	if err != nil {
+		t.Fatalf("NewActivation() failed: %v", err)
+	}
+	return vars
+}
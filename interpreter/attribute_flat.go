@@ -0,0 +1,221 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/common/containers"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// NewFlatAttributeFactory returns an AttributeFactory which resolves attributes from flat
+// key-value stores, where the variable name and the constant qualifiers of an attribute are
+// joined by the separator to form the key of the value within the Activation.
+//
+// For example, with a separator of "." the attribute `request.user.id` resolves to the value of
+// the key "request.user.id", and `request.user['id']` resolves to the same key. When the full path
+// is not a key, the longest path prefix which is a key is resolved and the remaining qualifiers
+// are applied to its value, so flat and nested values may be mixed within the same store.
+// Qualifiers which are not constant strings, ints, uints, or bools end the flattened path.
+//
+// Attribute resolution honors the unknown attribute patterns of a PartialActivation in the same
+// manner as the factory returned by NewPartialAttributeFactory. Presence tests on flat paths,
+// e.g. `has(request.user.id)`, require the FlatPresenceTests decorator.
+func NewFlatAttributeFactory(container *containers.Container,
+	adapter ref.TypeAdapter,
+	provider ref.TypeProvider,
+	separator string) AttributeFactory {
+	fac := NewPartialAttributeFactory(container, adapter, provider).(*partialAttributeFactory)
+	return &flatAttributeFactory{
+		partialAttributeFactory: fac,
+		separator:               separator,
+	}
+}
+
+type flatAttributeFactory struct {
+	*partialAttributeFactory
+	separator string
+}
+
+// AbsoluteAttribute implementation of the AttributeFactory interface which resolves the attribute
+// from flat keys derived from the attribute's variable name and qualifiers.
+func (fac *flatAttributeFactory) AbsoluteAttribute(id int64, names ...string) NamespacedAttribute {
+	paths := make([][]string, len(names))
+	for i, name := range names {
+		paths[i] = []string{strings.Join(strings.Split(name, "."), fac.separator)}
+	}
+	return &flatAttribute{
+		NamespacedAttribute: fac.partialAttributeFactory.AttributeFactory.AbsoluteAttribute(id, names...),
+		fac:                 fac,
+		paths:               paths,
+		flat:                true,
+	}
+}
+
+// MaybeAttribute implementation of the AttributeFactory interface which ensures that the set of
+// 'maybe' NamespacedAttribute values are produced using the flatAttributeFactory.
+func (fac *flatAttributeFactory) MaybeAttribute(id int64, name string) Attribute {
+	return &maybeAttribute{
+		id: id,
+		attrs: []NamespacedAttribute{
+			fac.AbsoluteAttribute(id, fac.container.ResolveCandidateNames(name)...),
+		},
+		adapter:  fac.adapter,
+		provider: fac.provider,
+		fac:      fac,
+	}
+}
+
+// flatAttribute embeds the NamespacedAttribute interface and tracks the flattened path of each
+// candidate variable name.
+type flatAttribute struct {
+	NamespacedAttribute
+	fac        *flatAttributeFactory
+	qualifiers []Qualifier
+	// paths hold the flattened paths of each candidate variable name, where the path at index n
+	// includes the first n qualifiers. The paths end at the first qualifier which cannot be
+	// flattened.
+	paths [][]string
+	flat  bool
+}
+
+// AddQualifier implements the Attribute interface method.
+func (a *flatAttribute) AddQualifier(qual Qualifier) (Attribute, error) {
+	_, err := a.NamespacedAttribute.AddQualifier(qual)
+	if err != nil {
+		return nil, err
+	}
+	a.qualifiers = append(a.qualifiers, qual)
+	if !a.flat {
+		return a, nil
+	}
+	segment, found := flatSegment(qual)
+	if !found {
+		a.flat = false
+		return a, nil
+	}
+	for i, paths := range a.paths {
+		a.paths[i] = append(paths, paths[len(paths)-1]+a.fac.separator+segment)
+	}
+	return a, nil
+}
+
+// Resolve is an implementation of the NamespacedAttribute interface method which resolves the
+// attribute from the longest flattened path present within the Activation.
+func (a *flatAttribute) Resolve(vars Activation) (any, error) {
+	if partial, isPartial := toPartialActivation(vars); isPartial {
+		unk, err := a.fac.matchesUnknownPatterns(partial, a.ID(), a.CandidateVariableNames(), a.qualifiers)
+		if err != nil {
+			return nil, err
+		}
+		if unk != nil {
+			return unk, nil
+		}
+	}
+	for _, paths := range a.paths {
+		// Consider the path prefixes from longest to shortest.
+		for n := len(paths) - 1; n >= 0; n-- {
+			if obj, found := vars.ResolveName(paths[n]); found {
+				obj, isOpt, err := applyQualifiers(vars, obj, a.qualifiers[n:])
+				if err != nil {
+					return nil, err
+				}
+				if isOpt {
+					return types.OptionalOf(a.fac.adapter.NativeToValue(obj)), nil
+				}
+				return obj, nil
+			}
+		}
+	}
+	return a.NamespacedAttribute.Resolve(vars)
+}
+
+// Qualify is an implementation of the Qualifier interface method.
+func (a *flatAttribute) Qualify(vars Activation, obj any) (any, error) {
+	return attrQualify(a.fac, vars, obj, a)
+}
+
+// QualifyIfPresent is an implementation of the Qualifier interface method.
+func (a *flatAttribute) QualifyIfPresent(vars Activation, obj any, presenceOnly bool) (any, bool, error) {
+	return attrQualifyIfPresent(a.fac, vars, obj, a, presenceOnly)
+}
+
+// isPresent returns whether the flattened path of the attribute extended by the field is a key
+// within the Activation.
+func (a *flatAttribute) isPresent(vars Activation, field string) (bool, bool) {
+	if !a.flat {
+		return false, false
+	}
+	for _, paths := range a.paths {
+		if _, found := vars.ResolveName(paths[len(paths)-1] + a.fac.separator + field); found {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// flatSegment returns the path segment of a constant qualifier.
+func flatSegment(qual Qualifier) (string, bool) {
+	constQual, isConst := qual.(ConstantQualifier)
+	if !isConst || qual.IsOptional() {
+		return "", false
+	}
+	switch v := constQual.Value().(type) {
+	case types.String, types.Int, types.Uint, types.Bool:
+		return fmt.Sprintf("%v", v.Value()), true
+	}
+	return "", false
+}
+
+// FlatPresenceTests returns an InterpretableDecorator which evaluates presence tests on the
+// attributes of a NewFlatAttributeFactory by testing whether the flattened path of the field is a
+// key within the Activation when the standard presence test does not find the field.
+func FlatPresenceTests() InterpretableDecorator {
+	return func(i Interpretable) (Interpretable, error) {
+		test, isTest := i.(*evalTestOnly)
+		if !isTest {
+			return i, nil
+		}
+		attr := test.attr.Attr()
+		if cached, isCached := attr.(*cachedAttribute); isCached {
+			attr = cached.NamespacedAttribute
+		}
+		if flat, isFlat := attr.(*flatAttribute); isFlat {
+			return &evalFlatTestOnly{evalTestOnly: test, flat: flat}, nil
+		}
+		return i, nil
+	}
+}
+
+// evalFlatTestOnly tests for the presence of a flattened attribute path.
+type evalFlatTestOnly struct {
+	*evalTestOnly
+	flat *flatAttribute
+}
+
+// Eval implements the Interpretable interface method.
+func (test *evalFlatTestOnly) Eval(ctx Activation) ref.Val {
+	out := test.evalTestOnly.Eval(ctx)
+	if out == types.True || types.IsUnknown(out) {
+		return out
+	}
+	if present, flat := test.flat.isPresent(ctx, string(test.field)); flat {
+		return types.Bool(present)
+	}
+	return out
+}
@@ -0,0 +1,135 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// QualifierInterceptor rewrites the constant qualifiers of attributes, e.g. field names and map
+// keys, as they are applied to objects during evaluation.
+type QualifierInterceptor interface {
+	// InterceptQualifier returns the value with which to qualify the object in place of the
+	// constant qualifier value, or the qualifier value itself to leave the qualification unchanged.
+	//
+	// The object is the value being qualified, such as a map or a protobuf message, and may be
+	// either a native Go value or a ref.Val.
+	InterceptQualifier(obj any, qual ref.Val) ref.Val
+}
+
+// QualifierInterceptorFunc adapts a function to the QualifierInterceptor interface.
+type QualifierInterceptorFunc func(obj any, qual ref.Val) ref.Val
+
+// InterceptQualifier implements the QualifierInterceptor interface method.
+func (f QualifierInterceptorFunc) InterceptQualifier(obj any, qual ref.Val) ref.Val {
+	return f(obj, qual)
+}
+
+// NewInterceptingAttributeFactory returns an AttributeFactory which consults the interceptor
+// before each constant qualifier created by the wrapped AttributeFactory is applied, so that
+// field names may be translated on the fly, e.g. from `snake_case` names within an expression to
+// the `camelCase` keys of a JSON object, without copying the input data.
+//
+// Qualifiers computed from other attributes, as in `a[b]`, are not intercepted. The variable
+// names of attributes are unaffected, so the qualifiers of unchecked expressions which are
+// resolved as part of a qualified variable name, e.g. `a.b` where `a.b` is a variable, are not
+// intercepted either.
+func NewInterceptingAttributeFactory(fac AttributeFactory, interceptor QualifierInterceptor) AttributeFactory {
+	return &interceptingAttributeFactory{AttributeFactory: fac, interceptor: interceptor}
+}
+
+type interceptingAttributeFactory struct {
+	AttributeFactory
+	interceptor QualifierInterceptor
+}
+
+// NewQualifier implements the AttributeFactory interface method.
+func (fac *interceptingAttributeFactory) NewQualifier(objType *exprpb.Type, qualID int64, val any, opt bool) (Qualifier, error) {
+	qual, err := fac.AttributeFactory.NewQualifier(objType, qualID, val, opt)
+	if err != nil {
+		return nil, err
+	}
+	cq, isConst := qual.(ConstantQualifier)
+	if !isConst {
+		return qual, nil
+	}
+	return &interceptedQualifier{ConstantQualifier: cq, fac: fac}, nil
+}
+
+// interceptedQualifier applies the qualifier value chosen by the interceptor in place of the
+// constant qualifier it wraps.
+type interceptedQualifier struct {
+	ConstantQualifier
+	fac *interceptingAttributeFactory
+
+	// rewritten holds the qualifiers created for the values returned by the interceptor, keyed by
+	// value, so that a translated qualifier is only created once.
+	rewritten sync.Map
+}
+
+// Qualify implements the Qualifier interface method.
+func (q *interceptedQualifier) Qualify(vars Activation, obj any) (any, error) {
+	qual, err := q.intercept(obj)
+	if err != nil {
+		return nil, err
+	}
+	return qual.Qualify(vars, obj)
+}
+
+// QualifyIfPresent implements the Qualifier interface method.
+func (q *interceptedQualifier) QualifyIfPresent(vars Activation, obj any, presenceOnly bool) (any, bool, error) {
+	qual, err := q.intercept(obj)
+	if err != nil {
+		return nil, false, err
+	}
+	return qual.QualifyIfPresent(vars, obj, presenceOnly)
+}
+
+// QualifierValueEquals implements the qualifierValueEquator interface method.
+func (q *interceptedQualifier) QualifierValueEquals(value any) bool {
+	qve, ok := q.ConstantQualifier.(qualifierValueEquator)
+	return ok && qve.QualifierValueEquals(value)
+}
+
+// intercept returns the qualifier to apply to the object.
+func (q *interceptedQualifier) intercept(obj any) (Qualifier, error) {
+	orig := q.ConstantQualifier.Value()
+	val := q.fac.interceptor.InterceptQualifier(obj, orig)
+	if val == nil || val.Equal(orig) == types.True {
+		return q.ConstantQualifier, nil
+	}
+	key := val.Value()
+	switch key.(type) {
+	case bool, int64, uint64, string:
+		if qual, found := q.rewritten.Load(key); found {
+			return qual.(Qualifier), nil
+		}
+	default:
+		return nil, fmt.Errorf("invalid qualifier type: %T", key)
+	}
+	// The object type is omitted since the rewritten qualifier may not refer to a declared field.
+	qual, err := q.fac.AttributeFactory.NewQualifier(nil, q.ID(), key, q.IsOptional())
+	if err != nil {
+		return nil, err
+	}
+	q.rewritten.Store(key, qual)
+	return qual, nil
+}
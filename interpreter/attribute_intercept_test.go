@@ -0,0 +1,86 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/containers"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestInterceptingAttributeFactory(t *testing.T) {
+	// camelCase translates snake_case string qualifiers into the camelCase keys of JSON objects.
+	camelCase := QualifierInterceptorFunc(func(obj any, qual ref.Val) ref.Val {
+		if _, isJSON := obj.(map[string]any); !isJSON {
+			return qual
+		}
+		name, isStr := qual.Value().(string)
+		if !isStr {
+			return qual
+		}
+		parts := strings.Split(name, "_")
+		for i := 1; i < len(parts); i++ {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+		return types.String(strings.Join(parts, ""))
+	})
+	tests := []struct {
+		expr string
+		out  ref.Val
+	}{
+		{expr: `request.user_id`, out: types.String("alice")},
+		{expr: `request.user_profile.display_name`, out: types.String("Alice")},
+		{expr: `request['user_id'] == request.user_id`, out: types.True},
+		{expr: `has(request.user_id) && !has(request.user_name)`, out: types.True},
+		{expr: `labels.first_key`, out: types.String("untranslated")},
+		{expr: `request.tags[1]`, out: types.String("b")},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			reg := newTestRegistry(t)
+			cont := containers.DefaultContainer
+			test := &testCase{
+				expr: tc.expr,
+				env: []*exprpb.Decl{
+					decls.NewVar("request", decls.NewMapType(decls.String, decls.Dyn)),
+					decls.NewVar("labels", decls.NewMapType(decls.String, decls.String)),
+				},
+				attrs: NewInterceptingAttributeFactory(NewAttributeFactory(cont, reg, reg), camelCase),
+			}
+			prg, _, err := program(t, test)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := prg.Eval(mustActivation(t, map[string]any{
+				"request": map[string]any{
+					"userId":      "alice",
+					"userProfile": map[string]any{"displayName": "Alice"},
+					"tags":        []string{"a", "b"},
+				},
+				"labels": map[string]string{"first_key": "untranslated"},
+			}))
+			if out.Equal(tc.out) != types.True {
+				t.Errorf("prg.Eval() got %v, wanted %v", out, tc.out)
+			}
+		})
+	}
+}
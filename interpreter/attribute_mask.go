@@ -0,0 +1,186 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// AttributeMask replaces the values of the attributes matched by its patterns with the values
+// computed by its Mask function, e.g. a hash of an email address.
+type AttributeMask struct {
+	// Patterns select the attributes whose values are masked.
+	Patterns []*AttributePattern
+
+	// Mask computes the value which replaces the value of a matched attribute.
+	Mask func(ref.Val) ref.Val
+}
+
+// NewMaskingAttributeFactory returns an AttributeFactory whose attributes replace their resolved
+// values with the masked values when their path is matched by a pattern of one of the masks, so
+// that expressions never observe the raw values.
+//
+// An attribute is matched when a pattern matches its variable and a prefix of its qualifiers, such
+// that the values of the attributes beneath a masked path are masked as well, e.g. the pattern
+// `user.emails` masks both `user.emails` and `user.emails[0]`. Attributes which are ancestors of a
+// masked path, such as `user` for the pattern `user.email`, would expose the raw values beneath
+// them, and so their resolution produces an error. When several masks match an attribute, the first
+// of them applies.
+//
+// Only the attributes rooted at the variables of the activation are masked. Values which are
+// computed by functions and then qualified, as in `f(user).email`, are not masked.
+func NewMaskingAttributeFactory(fac AttributeFactory, adapter ref.TypeAdapter, masks ...*AttributeMask) AttributeFactory {
+	return &maskingAttributeFactory{AttributeFactory: fac, adapter: adapter, masks: masks}
+}
+
+type maskingAttributeFactory struct {
+	AttributeFactory
+	adapter ref.TypeAdapter
+	masks   []*AttributeMask
+}
+
+// AbsoluteAttribute implements the AttributeFactory interface method.
+func (fac *maskingAttributeFactory) AbsoluteAttribute(id int64, names ...string) NamespacedAttribute {
+	return &maskedAttribute{NamespacedAttribute: fac.AttributeFactory.AbsoluteAttribute(id, names...), fac: fac}
+}
+
+// MaybeAttribute implements the AttributeFactory interface method, ensuring that each of the
+// candidate attributes of an unchecked expression is masked.
+func (fac *maskingAttributeFactory) MaybeAttribute(id int64, name string) Attribute {
+	attr := fac.AttributeFactory.MaybeAttribute(id, name)
+	if maybe, isMaybe := attr.(*maybeAttribute); isMaybe {
+		for i, a := range maybe.attrs {
+			maybe.attrs[i] = &maskedAttribute{NamespacedAttribute: a, fac: fac}
+		}
+		maybe.fac = fac
+	}
+	return attr
+}
+
+// match returns the mask which applies to the attribute with the given candidate variable names
+// and qualifiers, if any, and whether the attribute is an ancestor of a masked path.
+func (fac *maskingAttributeFactory) match(vars Activation,
+	variableNames []string,
+	qualifiers []Qualifier) (*AttributeMask, bool, error) {
+	var quals []Qualifier
+	ancestor := false
+	for _, mask := range fac.masks {
+		for _, pat := range mask.Patterns {
+			for _, variable := range variableNames {
+				qualPats, matches := pat.variableQualifierPatterns(variable)
+				if !matches {
+					continue
+				}
+				if quals == nil {
+					var err error
+					quals, err = fac.resolveQualifiers(vars, qualifiers)
+					if err != nil {
+						return nil, false, err
+					}
+				}
+				matched := true
+				for i, qualPat := range qualPats {
+					if i >= len(quals) {
+						ancestor = true
+						matched = false
+						break
+					}
+					if !qualPat.Matches(quals[i]) {
+						matched = false
+						break
+					}
+				}
+				if matched {
+					return mask, false, nil
+				}
+			}
+		}
+	}
+	return nil, ancestor, nil
+}
+
+// resolveQualifiers resolves the qualifiers which are computed from other attributes into constant
+// qualifiers, since patterns can only be matched against constant qualifiers.
+func (fac *maskingAttributeFactory) resolveQualifiers(vars Activation, qualifiers []Qualifier) ([]Qualifier, error) {
+	quals := make([]Qualifier, len(qualifiers))
+	for i, qual := range qualifiers {
+		if attr, isAttr := qual.(Attribute); isAttr {
+			val, err := attr.Resolve(vars)
+			if err != nil {
+				return nil, err
+			}
+			qual, err = fac.NewQualifier(nil, qual.ID(), val, attr.IsOptional())
+			if err != nil {
+				return nil, err
+			}
+		}
+		quals[i] = qual
+	}
+	return quals, nil
+}
+
+// maskedAttribute masks the value of the NamespacedAttribute it embeds when the attribute is
+// matched by a mask.
+type maskedAttribute struct {
+	NamespacedAttribute
+	qualifiers []Qualifier
+	fac        *maskingAttributeFactory
+}
+
+// AddQualifier implements the Attribute interface method.
+func (m *maskedAttribute) AddQualifier(qual Qualifier) (Attribute, error) {
+	_, err := m.NamespacedAttribute.AddQualifier(qual)
+	if err != nil {
+		return nil, err
+	}
+	// The qualifiers are not inspectable on the NamespacedAttribute interface, and so they are
+	// tracked for matching against the patterns of the masks.
+	m.qualifiers = append(m.qualifiers, qual)
+	return m, nil
+}
+
+// Resolve implements the Attribute interface method, masking the resolved value when the attribute
+// is matched by a mask.
+func (m *maskedAttribute) Resolve(vars Activation) (any, error) {
+	mask, ancestor, err := m.fac.match(vars, m.CandidateVariableNames(), m.qualifiers)
+	if err != nil {
+		return nil, err
+	}
+	if ancestor {
+		return nil, fmt.Errorf("access to '%s' would expose masked values", m.CandidateVariableNames()[0])
+	}
+	val, err := m.NamespacedAttribute.Resolve(vars)
+	if err != nil || mask == nil {
+		return val, err
+	}
+	v := m.fac.adapter.NativeToValue(val)
+	if types.IsUnknownOrError(v) {
+		return v, nil
+	}
+	return mask.Mask(v), nil
+}
+
+// Qualify implements the Qualifier interface method.
+func (m *maskedAttribute) Qualify(vars Activation, obj any) (any, error) {
+	return attrQualify(m.fac, vars, obj, m)
+}
+
+// QualifyIfPresent implements the Qualifier interface method.
+func (m *maskedAttribute) QualifyIfPresent(vars Activation, obj any, presenceOnly bool) (any, bool, error) {
+	return attrQualifyIfPresent(m.fac, vars, obj, m, presenceOnly)
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/containers"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestMaskingAttributeFactory(t *testing.T) {
+	// redact replaces strings with a fixed value, and lists of strings with lists of that value.
+	redact := func(val ref.Val) ref.Val {
+		if l, isList := val.Value().([]string); isList {
+			masked := make([]string, len(l))
+			for i := range masked {
+				masked[i] = "***"
+			}
+			return types.DefaultTypeAdapter.NativeToValue(masked)
+		}
+		if _, isStr := val.(types.String); isStr {
+			return types.String("***")
+		}
+		return val
+	}
+	tests := []struct {
+		expr      string
+		unchecked bool
+		out       ref.Val
+		err       string
+	}{
+		{expr: `user.email`, out: types.String("***")},
+		{expr: `user['email'] == user.email`, out: types.True},
+		{expr: `user.name`, out: types.String("alice")},
+		{expr: `user.aliases[0]`, out: types.String("***")},
+		{expr: `user.aliases.size()`, out: types.Int(2)},
+		{expr: `user[field]`, out: types.String("***")},
+		{expr: `user.email`, unchecked: true, out: types.String("***")},
+		{expr: `user`, err: "access to 'user' would expose masked values"},
+		{expr: `user.all(k, k != '')`, err: "access to 'user' would expose masked values"},
+		{expr: `field`, out: types.String("email")},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			reg := newTestRegistry(t)
+			cont := containers.DefaultContainer
+			masks := []*AttributeMask{
+				{
+					Patterns: []*AttributePattern{
+						NewAttributePattern("user").QualString("email"),
+						NewAttributePattern("user").QualString("aliases"),
+					},
+					Mask: redact,
+				},
+			}
+			test := &testCase{
+				expr:      tc.expr,
+				unchecked: tc.unchecked,
+				env: []*exprpb.Decl{
+					decls.NewVar("user", decls.NewMapType(decls.String, decls.Dyn)),
+					decls.NewVar("field", decls.String),
+				},
+				attrs: NewMaskingAttributeFactory(NewAttributeFactory(cont, reg, reg), reg, masks...),
+			}
+			prg, _, err := program(t, test)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := prg.Eval(mustActivation(t, map[string]any{
+				"user": map[string]any{
+					"name":    "alice",
+					"email":   "alice@example.com",
+					"aliases": []string{"al", "ally"},
+				},
+				"field": "email",
+			}))
+			if tc.err != "" {
+				if !types.IsError(out) || !strings.Contains(out.(*types.Err).String(), tc.err) {
+					t.Errorf("prg.Eval() got %v, wanted error containing %q", out, tc.err)
+				}
+				return
+			}
+			if out.Equal(tc.out) != types.True {
+				t.Errorf("prg.Eval() got %v, wanted %v", out, tc.out)
+			}
+		})
+	}
+}
@@ -15,6 +15,10 @@
 package interpreter
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/google/cel-go/common/containers"
 	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
@@ -30,7 +34,12 @@ import (
 // The qualifier patterns for attribute matching must be one of the following:
 //
 //   - valid map key type: string, int, uint, bool
-//   - wildcard (*)
+//   - wildcard (*), optionally restricted to list indices
+//   - string glob, where `*` matches any sequence of characters and `?` matches any character
+//
+// The variable name of a pattern may extend beyond the name of a variable with field selections,
+// e.g. the pattern variable `request.headers` applies to variable `request` with the qualifier
+// `headers`, so that patterns may be written without regard to where the variable name ends.
 //
 // Examples:
 //
@@ -55,6 +64,153 @@ func NewAttributePattern(variable string) *AttributePattern {
 	}
 }
 
+// ParseAttributePattern parses an AttributePattern from its textual form, a variable name followed
+// by field selections and indices, where:
+//
+//   - `.*` matches any field selection or string map key;
+//   - `[*]` matches any index or map key;
+//   - a field name or quoted string key containing `*` or `?` is a glob, e.g. `['x-*']`;
+//   - indices may be quoted strings, ints, uints with a `u` suffix, or bools.
+//
+// Examples:
+//
+//	request.headers.*
+//	request.headers['x-forwarded-*']
+//	request.items[*].name
+//	ns.myvar[0][1u][true]
+func ParseAttributePattern(pattern string) (*AttributePattern, error) {
+	p := &attributePatternParser{input: pattern}
+	name, glob := p.ident()
+	if name == "" || glob {
+		return nil, p.errorf("expected variable name")
+	}
+	apat := NewAttributePattern(name)
+	for p.pos < len(p.input) {
+		switch p.next() {
+		case '.':
+			field, glob := p.ident()
+			switch {
+			case field == "":
+				return nil, p.errorf("expected field name")
+			case glob:
+				apat.QualStringGlob(field)
+			case len(apat.qualifierPatterns) == 0:
+				// Field selections which precede all other qualifiers extend the variable name.
+				apat.variable += "." + field
+			default:
+				apat.QualString(field)
+			}
+		case '[':
+			if err := p.index(apat); err != nil {
+				return nil, err
+			}
+		default:
+			p.pos--
+			return nil, p.errorf("expected '.' or '['")
+		}
+	}
+	return apat, nil
+}
+
+type attributePatternParser struct {
+	input string
+	pos   int
+}
+
+func (p *attributePatternParser) next() byte {
+	c := p.input[p.pos]
+	p.pos++
+	return c
+}
+
+// ident consumes an identifier, which may contain the glob characters `*` and `?`, and returns
+// the identifier along with whether it contains glob characters.
+func (p *attributePatternParser) ident() (string, bool) {
+	start := p.pos
+	glob := false
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if c == '*' || c == '?' {
+			glob = true
+		} else if c != '_' && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') &&
+			!(p.pos > start && '0' <= c && c <= '9') {
+			break
+		}
+		p.pos++
+	}
+	return p.input[start:p.pos], glob
+}
+
+// index consumes an index qualifier pattern and its closing bracket.
+func (p *attributePatternParser) index(apat *AttributePattern) error {
+	end := strings.IndexByte(p.input[p.pos:], ']')
+	if p.pos < len(p.input) && (p.input[p.pos] == '\'' || p.input[p.pos] == '"') {
+		str, err := p.quoted()
+		if err != nil {
+			return err
+		}
+		if strings.ContainsAny(str, "*?") {
+			apat.QualStringGlob(str)
+		} else {
+			apat.QualString(str)
+		}
+		end = strings.IndexByte(p.input[p.pos:], ']')
+		if end != 0 {
+			return p.errorf("expected ']'")
+		}
+		p.pos++
+		return nil
+	}
+	if end < 0 {
+		return p.errorf("expected ']'")
+	}
+	idx := p.input[p.pos : p.pos+end]
+	switch {
+	case idx == "*":
+		apat.Wildcard()
+	case idx == "true" || idx == "false":
+		apat.QualBool(idx == "true")
+	case strings.HasSuffix(idx, "u"):
+		u, err := strconv.ParseUint(idx[:len(idx)-1], 10, 64)
+		if err != nil {
+			return p.errorf("invalid uint index: %s", idx)
+		}
+		apat.QualUint(u)
+	default:
+		i, err := strconv.ParseInt(idx, 10, 64)
+		if err != nil {
+			return p.errorf("invalid index: %s", idx)
+		}
+		apat.QualInt(i)
+	}
+	p.pos += end + 1
+	return nil
+}
+
+// quoted consumes a single or double quoted string in which the quote and backslash characters
+// may be escaped with a backslash.
+func (p *attributePatternParser) quoted() (string, error) {
+	quote := p.next()
+	var sb strings.Builder
+	for p.pos < len(p.input) {
+		c := p.next()
+		switch {
+		case c == quote:
+			return sb.String(), nil
+		case c == '\\' && p.pos < len(p.input):
+			sb.WriteByte(p.next())
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *attributePatternParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid attribute pattern %q at offset %d: %s",
+		p.input, p.pos, fmt.Sprintf(format, args...))
+}
+
 // QualString adds a string qualifier pattern to the AttributePattern. The string may be a valid
 // identifier, or string map key including empty string.
 func (apat *AttributePattern) QualString(pattern string) *AttributePattern {
@@ -85,6 +241,15 @@ func (apat *AttributePattern) QualBool(pattern bool) *AttributePattern {
 	return apat
 }
 
+// QualStringGlob adds a string qualifier pattern which matches the string qualifiers, such as
+// field selections and map keys, whose value matches the glob. Within the glob, `*` matches any
+// sequence of characters and `?` matches any single character.
+func (apat *AttributePattern) QualStringGlob(glob string) *AttributePattern {
+	apat.qualifierPatterns = append(apat.qualifierPatterns,
+		&AttributeQualifierPattern{glob: glob, isGlob: true})
+	return apat
+}
+
 // Wildcard adds a special sentinel qualifier pattern that will match any single qualifier.
 func (apat *AttributePattern) Wildcard() *AttributePattern {
 	apat.qualifierPatterns = append(apat.qualifierPatterns,
@@ -92,33 +257,107 @@ func (apat *AttributePattern) Wildcard() *AttributePattern {
 	return apat
 }
 
+// WildcardIndex adds a qualifier pattern that will match any single int or uint qualifier, such
+// as a list index.
+func (apat *AttributePattern) WildcardIndex() *AttributePattern {
+	apat.qualifierPatterns = append(apat.qualifierPatterns,
+		&AttributeQualifierPattern{wildcard: true, indexOnly: true})
+	return apat
+}
+
 // VariableMatches returns true if the fully qualified variable matches the AttributePattern
 // fully qualified variable name.
 func (apat *AttributePattern) VariableMatches(variable string) bool {
 	return apat.variable == variable
 }
 
+// variableQualifierPatterns returns the qualifier patterns which apply to the qualifiers of the
+// fully qualified variable, and whether the pattern applies to the variable.
+//
+// When the pattern variable extends the variable name with field selections, the additional
+// selections precede the qualifier patterns of the AttributePattern.
+func (apat *AttributePattern) variableQualifierPatterns(variable string) ([]*AttributeQualifierPattern, bool) {
+	if apat.variable == variable {
+		return apat.qualifierPatterns, true
+	}
+	if !strings.HasPrefix(apat.variable, variable+".") {
+		return nil, false
+	}
+	fields := strings.Split(apat.variable[len(variable)+1:], ".")
+	qualPats := make([]*AttributeQualifierPattern, 0, len(fields)+len(apat.qualifierPatterns))
+	for _, field := range fields {
+		qualPats = append(qualPats, &AttributeQualifierPattern{value: field})
+	}
+	return append(qualPats, apat.qualifierPatterns...), true
+}
+
 // QualifierPatterns returns the set of AttributeQualifierPattern values on the AttributePattern.
 func (apat *AttributePattern) QualifierPatterns() []*AttributeQualifierPattern {
 	return apat.qualifierPatterns
 }
 
-// AttributeQualifierPattern holds a wildcard or valued qualifier pattern.
+// AttributeQualifierPattern holds a wildcard, glob, or valued qualifier pattern.
 type AttributeQualifierPattern struct {
-	wildcard bool
-	value    any
+	wildcard  bool
+	indexOnly bool
+	isGlob    bool
+	glob      string
+	value     any
 }
 
-// Matches returns true if the qualifier pattern is a wildcard, or the Qualifier implements the
+// Matches returns true if the qualifier pattern is a wildcard, the qualifier pattern is a glob
+// which matches the string value of the Qualifier, or the Qualifier implements the
 // qualifierValueEquator interface and its IsValueEqualTo returns true for the qualifier pattern.
 func (qpat *AttributeQualifierPattern) Matches(q Qualifier) bool {
-	if qpat.wildcard {
+	if qpat.wildcard && !qpat.indexOnly {
 		return true
 	}
+	if qpat.wildcard || qpat.isGlob {
+		constQual, ok := q.(ConstantQualifier)
+		if !ok {
+			return false
+		}
+		switch v := constQual.Value().(type) {
+		case types.Int, types.Uint:
+			return qpat.indexOnly
+		case types.String:
+			return qpat.isGlob && globMatches(qpat.glob, string(v))
+		}
+		return false
+	}
 	qve, ok := q.(qualifierValueEquator)
 	return ok && qve.QualifierValueEquals(qpat.value)
 }
 
+// globMatches returns whether the string matches the glob, where `*` matches any sequence of
+// characters and `?` matches any single character.
+func globMatches(glob, str string) bool {
+	g, s := []rune(glob), []rune(str)
+	// Track the position of the last `*` and the input position it was matched against so that
+	// the match may be retried with the `*` consuming one more character.
+	star, retry := -1, 0
+	i, j := 0, 0
+	for j < len(s) {
+		switch {
+		case i < len(g) && (g[i] == '?' || g[i] == s[j]):
+			i++
+			j++
+		case i < len(g) && g[i] == '*':
+			star, retry = i, j
+			i++
+		case star >= 0:
+			retry++
+			i, j = star+1, retry
+		default:
+			return false
+		}
+	}
+	for i < len(g) && g[i] == '*' {
+		i++
+	}
+	return i == len(g)
+}
+
 // qualifierValueEquator defines an interface for determining if an input value, of valid map key
 // type, is equal to the value held in the Qualifier. This interface is used by the
 // AttributeQualifierPattern to determine pattern matches for non-wildcard qualifier patterns.
@@ -243,11 +482,14 @@ func (fac *partialAttributeFactory) matchesUnknownPatterns(
 	variableNames []string,
 	qualifiers []Qualifier) (types.Unknown, error) {
 	patterns := vars.UnknownAttributePatterns()
-	candidateIndices := map[int]struct{}{}
+	candidateIndices := map[int][]*AttributeQualifierPattern{}
 	for _, variable := range variableNames {
 		for i, pat := range patterns {
-			if pat.VariableMatches(variable) {
-				candidateIndices[i] = struct{}{}
+			if _, found := candidateIndices[i]; found {
+				continue
+			}
+			if qualPats, matches := pat.variableQualifierPatterns(variable); matches {
+				candidateIndices[i] = qualPats
 			}
 		}
 	}
@@ -280,11 +522,9 @@ func (fac *partialAttributeFactory) matchesUnknownPatterns(
 		newQuals[i] = qual
 	}
 	// Determine whether any of the unknown patterns match.
-	for patIdx := range candidateIndices {
-		pat := patterns[patIdx]
+	for _, qualPats := range candidateIndices {
 		isUnk := true
 		matchExprID := attrID
-		qualPats := pat.QualifierPatterns()
 		for i, qual := range newQuals {
 			if i >= len(qualPats) {
 				break
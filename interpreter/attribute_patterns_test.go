@@ -17,6 +17,7 @@ package interpreter
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/google/cel-go/common/containers"
@@ -181,6 +182,54 @@ var patternTests = map[string]patternTest{
 			{name: "none"},
 		},
 	},
+	"var_string_glob": {
+		pattern: NewAttributePattern("var").QualStringGlob("x-*-id"),
+		matches: []attr{
+			{name: "var"},
+			{name: "var", quals: []any{"x-request-id"}},
+			{name: "var", quals: []any{"x--id", "field"}},
+		},
+		misses: []attr{
+			{name: "var", quals: []any{"x-request"}},
+			{name: "var", quals: []any{int64(0)}},
+		},
+	},
+	"var_wildcard_index": {
+		pattern: NewAttributePattern("var").WildcardIndex().QualString("name"),
+		matches: []attr{
+			{name: "var", quals: []any{int64(2), "name"}},
+			{name: "var", quals: []any{uint64(2)}},
+		},
+		misses: []attr{
+			{name: "var", quals: []any{"key", "name"}},
+			{name: "var", quals: []any{int64(2), "other"}},
+		},
+	},
+	"var_field_extension": {
+		pattern: NewAttributePattern("var.headers").Wildcard(),
+		matches: []attr{
+			{name: "var"},
+			{name: "var", quals: []any{"headers"}},
+			{name: "var", quals: []any{"headers", "x-token"}},
+			{name: "var.headers", quals: []any{"x-token"}},
+		},
+		misses: []attr{
+			{name: "var", quals: []any{"body"}},
+			{name: "var.body"},
+		},
+	},
+	"parsed": {
+		pattern: mustParseAttributePattern("var.items[*]['x-*'].name"),
+		matches: []attr{
+			{name: "var", quals: []any{"items", int64(0), "x-id", "name"}},
+			{name: "var", quals: []any{"items", "key", "x-"}},
+		},
+		misses: []attr{
+			{name: "var", quals: []any{"other"}},
+			{name: "var", quals: []any{"items", int64(0), "y-id"}},
+			{name: "var", quals: []any{"items", int64(0), "x-id", "id"}},
+		},
+	},
 }
 
 func TestAttributePattern_UnknownResolution(t *testing.T) {
@@ -315,6 +364,94 @@ func TestAttributePattern_CrossReference(t *testing.T) {
 	}
 }
 
+func TestParseAttributePattern(t *testing.T) {
+	tests := []struct {
+		in   string
+		want *AttributePattern
+		err  string
+	}{
+		{
+			in:   "request",
+			want: NewAttributePattern("request"),
+		},
+		{
+			in:   "ns.request.headers.*",
+			want: NewAttributePattern("ns.request.headers").QualStringGlob("*"),
+		},
+		{
+			in:   `request.headers['x-forwarded-*']`,
+			want: NewAttributePattern("request.headers").QualStringGlob("x-forwarded-*"),
+		},
+		{
+			in:   `request.items[*].name`,
+			want: NewAttributePattern("request.items").Wildcard().QualString("name"),
+		},
+		{
+			in: `var[0][-1][2u][true]["a.b"]['it\'s']`,
+			want: NewAttributePattern("var").QualInt(0).QualInt(-1).QualUint(2).QualBool(true).
+				QualString("a.b").QualString("it's"),
+		},
+		{in: "", err: "expected variable name"},
+		{in: "*.name", err: "expected variable name"},
+		{in: "request.", err: "expected field name"},
+		{in: "request[1", err: "expected ']'"},
+		{in: "request['a'", err: "expected ']'"},
+		{in: "request['a", err: "unterminated string"},
+		{in: "request[1.5]", err: "invalid index"},
+		{in: "request[xu]", err: "invalid uint index"},
+		{in: "request-name", err: "expected '.' or '['"},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := ParseAttributePattern(tc.in)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("ParseAttributePattern(%q) got %v, %v, wanted error %q", tc.in, got, err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAttributePattern(%q) failed: %v", tc.in, err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ParseAttributePattern(%q) got %+v, wanted %+v", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGlobMatches(t *testing.T) {
+	tests := []struct {
+		glob  string
+		str   string
+		match bool
+	}{
+		{glob: "*", str: "", match: true},
+		{glob: "*", str: "anything", match: true},
+		{glob: "a*c", str: "abbc", match: true},
+		{glob: "a*c", str: "abbd", match: false},
+		{glob: "a?c", str: "abc", match: true},
+		{glob: "a?c", str: "ac", match: false},
+		{glob: "*b*b*", str: "abxbc", match: true},
+		{glob: "*.json", str: "a.json.bak", match: false},
+		{glob: "ü*", str: "über", match: true},
+	}
+	for _, tc := range tests {
+		if got := globMatches(tc.glob, tc.str); got != tc.match {
+			t.Errorf("globMatches(%q, %q) got %v, wanted %v", tc.glob, tc.str, got, tc.match)
+		}
+	}
+}
+
+func mustParseAttributePattern(pattern string) *AttributePattern {
+	apat, err := ParseAttributePattern(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return apat
+}
+
 func genAttr(fac AttributeFactory, a attr) Attribute {
 	id := int64(1)
 	var attr Attribute
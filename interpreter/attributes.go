@@ -19,8 +19,10 @@ import (
 
 	"github.com/google/cel-go/common/containers"
 	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/pb"
 	"github.com/google/cel-go/common/types/ref"
 	"github.com/google/cel-go/common/types/traits"
+	"google.golang.org/protobuf/reflect/protoreflect"
 
 	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 )
@@ -626,12 +628,20 @@ func newQualifier(adapter ref.TypeAdapter, id int64, v any, opt bool) (Qualifier
 		qual = &doubleQualifier{
 			id: id, value: float64(val), celValue: val, adapter: adapter, optional: opt,
 		}
+	case []byte:
+		qual = &hashableQualifier{id: id, celValue: types.Bytes(val), adapter: adapter, optional: opt}
 	case types.Unknown:
 		qual = &unknownQualifier{id: id, value: val}
 	default:
 		if q, ok := v.(Qualifier); ok {
 			return q, nil
 		}
+		// Values which implement traits.Hashable, such as bytes, may be the keys of maps.
+		if _, hashable := v.(traits.Hashable); hashable {
+			if celVal, isVal := v.(ref.Val); isVal {
+				return &hashableQualifier{id: id, celValue: celVal, adapter: adapter, optional: opt}, nil
+			}
+		}
 		return nil, fmt.Errorf("invalid qualifier type: %T", v)
 	}
 	return qual, nil
@@ -1054,6 +1064,127 @@ func (q *fieldQualifier) Value() ref.Val {
 	return types.String(q.Name)
 }
 
+// precomputeIndex returns a direct accessor for a constant index or key into a repeated or map
+// protobuf field, where the field is the last qualifier of the attribute, or the qualifier as is
+// when the container is not known to be such a field.
+//
+// The index of a list access is converted and validated when the program is planned rather than
+// on every evaluation. Negative indices are never within range, and so are reported as missing
+// without inspecting the list.
+func precomputeIndex(attr Attribute, qual Qualifier) Qualifier {
+	nsAttr, isNamespaced := attr.(NamespacedAttribute)
+	constQual, isConst := qual.(ConstantQualifier)
+	if !isNamespaced || !isConst {
+		return qual
+	}
+	quals := nsAttr.Qualifiers()
+	if len(quals) == 0 {
+		return qual
+	}
+	field, isField := quals[len(quals)-1].(*fieldQualifier)
+	if !isField || field.FieldType.Type == nil {
+		return qual
+	}
+	fieldType := field.FieldType.Type
+	switch {
+	case fieldType.GetListType() != nil:
+		index, err := types.IndexOrError(constQual.Value())
+		if err != nil {
+			return qual
+		}
+		return &protoListIndexQualifier{ConstantQualifier: constQual, index: index}
+	case fieldType.GetMapType().GetKeyType().GetPrimitive() == exprpb.Type_STRING:
+		key, isStr := constQual.Value().(types.String)
+		if !isStr {
+			return qual
+		}
+		return &protoMapKeyQualifier{
+			ConstantQualifier: constQual,
+			key:               protoreflect.ValueOfString(string(key)).MapKey(),
+		}
+	}
+	return qual
+}
+
+// protoListIndexQualifier is a constant index into a repeated protobuf field which accesses the
+// protoreflect.List directly, falling back to the original qualifier for any other container.
+type protoListIndexQualifier struct {
+	ConstantQualifier
+	index int
+}
+
+// Qualify implements the Qualifier interface method.
+func (q *protoListIndexQualifier) Qualify(vars Activation, obj any) (any, error) {
+	list, isList := obj.(protoreflect.List)
+	if !isList {
+		return q.ConstantQualifier.Qualify(vars, obj)
+	}
+	if q.index < 0 || q.index >= list.Len() {
+		return nil, missingIndex(q.Value())
+	}
+	return protoValue(list.Get(q.index)), nil
+}
+
+// QualifyIfPresent is an implementation of the Qualifier interface method.
+func (q *protoListIndexQualifier) QualifyIfPresent(vars Activation, obj any, presenceOnly bool) (any, bool, error) {
+	list, isList := obj.(protoreflect.List)
+	if !isList {
+		return q.ConstantQualifier.QualifyIfPresent(vars, obj, presenceOnly)
+	}
+	if q.index < 0 || q.index >= list.Len() {
+		return nil, false, nil
+	}
+	if presenceOnly {
+		return nil, true, nil
+	}
+	return protoValue(list.Get(q.index)), true, nil
+}
+
+// protoMapKeyQualifier is a constant string key into a protobuf map field which accesses the
+// protoreflect.Map directly using a map key computed when the program is planned, falling back to
+// the original qualifier for any other container.
+type protoMapKeyQualifier struct {
+	ConstantQualifier
+	key protoreflect.MapKey
+}
+
+// Qualify implements the Qualifier interface method.
+func (q *protoMapKeyQualifier) Qualify(vars Activation, obj any) (any, error) {
+	m, isMap := obj.(*pb.Map)
+	if !isMap {
+		return q.ConstantQualifier.Qualify(vars, obj)
+	}
+	val := m.Get(q.key)
+	if !val.IsValid() {
+		return nil, missingKey(q.Value())
+	}
+	return protoValue(val), nil
+}
+
+// QualifyIfPresent is an implementation of the Qualifier interface method.
+func (q *protoMapKeyQualifier) QualifyIfPresent(vars Activation, obj any, presenceOnly bool) (any, bool, error) {
+	m, isMap := obj.(*pb.Map)
+	if !isMap {
+		return q.ConstantQualifier.QualifyIfPresent(vars, obj, presenceOnly)
+	}
+	if !m.Has(q.key) {
+		return nil, false, nil
+	}
+	if presenceOnly {
+		return nil, true, nil
+	}
+	return protoValue(m.Get(q.key)), true, nil
+}
+
+// protoValue returns the native value of a protobuf list element or map value, unwrapping
+// messages so that they may be qualified by field.
+func protoValue(val protoreflect.Value) any {
+	if msg, isMsg := val.Interface().(protoreflect.Message); isMsg {
+		return msg.Interface()
+	}
+	return val.Interface()
+}
+
 // doubleQualifier qualifies a CEL object, map, or list using a double value.
 //
 // This qualifier is used for working with dynamic data like JSON or protobuf.Any where the value
@@ -1096,6 +1227,40 @@ func (q *doubleQualifier) Value() ref.Val {
 	return q.celValue
 }
 
+// hashableQualifier qualifies maps by a key which implements traits.Hashable, such as bytes.
+type hashableQualifier struct {
+	id       int64
+	celValue ref.Val
+	adapter  ref.TypeAdapter
+	optional bool
+}
+
+// ID is an implementation of the Qualifier interface method.
+func (q *hashableQualifier) ID() int64 {
+	return q.id
+}
+
+// IsOptional implements the Qualifier interface method.
+func (q *hashableQualifier) IsOptional() bool {
+	return q.optional
+}
+
+// Qualify implements the Qualifier interface method.
+func (q *hashableQualifier) Qualify(vars Activation, obj any) (any, error) {
+	val, _, err := refQualify(q.adapter, obj, q.celValue, false, false)
+	return val, err
+}
+
+// QualifyIfPresent is an implementation of the Qualifier interface method.
+func (q *hashableQualifier) QualifyIfPresent(vars Activation, obj any, presenceOnly bool) (any, bool, error) {
+	return refQualify(q.adapter, obj, q.celValue, true, presenceOnly)
+}
+
+// Value implements the ConstantQualifier interface
+func (q *hashableQualifier) Value() ref.Val {
+	return q.celValue
+}
+
 // unknownQualifier is a simple qualifier which always returns a preconfigured set of unknown values
 // for any value subject to qualification. This is consistent with CEL's unknown handling elsewhere.
 type unknownQualifier struct {
@@ -1022,6 +1022,82 @@ func TestAttributeStateTracking(t *testing.T) {
 	}
 }
 
+func TestAttributesPrecomputedIndex(t *testing.T) {
+	msg := &proto3pb.TestAllTypes{
+		RepeatedInt64: []int64{1, 2, 3},
+		RepeatedNestedMessage: []*proto3pb.TestAllTypes_NestedMessage{
+			{Bb: 7},
+		},
+		MapStringString: map[string]string{"k": "v"},
+	}
+	var tests = []struct {
+		expr   string
+		out    any
+		err    string
+		direct bool
+	}{
+		{expr: `msg.repeated_int64[1]`, out: int64(2), direct: true},
+		{expr: `msg.repeated_int64[3]`, err: "index out of bounds: 3", direct: true},
+		{expr: `msg.repeated_int64[-1]`, err: "index out of bounds: -1", direct: true},
+		{expr: `msg.repeated_nested_message[0].bb`, out: int64(7)},
+		{expr: `msg.map_string_string['k']`, out: "v", direct: true},
+		{expr: `msg.map_string_string['missing']`, err: "no such key: missing", direct: true},
+		{expr: `[1, 2][1]`, out: int64(2)},
+	}
+	for _, test := range tests {
+		tc := test
+		t.Run(tc.expr, func(t *testing.T) {
+			src := common.NewTextSource(tc.expr)
+			parsed, errors := parser.Parse(src)
+			if len(errors.GetErrors()) != 0 {
+				t.Fatalf(errors.ToDisplayString())
+			}
+			cont := containers.DefaultContainer
+			reg := newTestRegistry(t, msg)
+			env, err := checker.NewEnv(cont, reg)
+			if err != nil {
+				t.Fatalf("checker.NewEnv() failed: %v", err)
+			}
+			env.Add(checker.StandardDeclarations()...)
+			env.Add(decls.NewVar("msg", decls.NewObjectType("google.expr.proto3.test.TestAllTypes")))
+			checked, errors := checker.Check(parsed, src, env)
+			if len(errors.GetErrors()) != 0 {
+				t.Fatalf(errors.ToDisplayString())
+			}
+			attrs := NewAttributeFactory(cont, reg, reg)
+			interp := NewStandardInterpreter(cont, reg, reg, attrs)
+			i, err := interp.NewInterpretable(checked)
+			if err != nil {
+				t.Fatalf("NewInterpretable() failed: %v", err)
+			}
+			direct := false
+			if attr, ok := i.(InterpretableAttribute); ok {
+				if nsAttr, ok := attr.Attr().(NamespacedAttribute); ok {
+					quals := nsAttr.Qualifiers()
+					switch quals[len(quals)-1].(type) {
+					case *protoListIndexQualifier, *protoMapKeyQualifier:
+						direct = true
+					}
+				}
+			}
+			if direct != tc.direct {
+				t.Errorf("got direct accessor %t, wanted %t", direct, tc.direct)
+			}
+			in, _ := NewActivation(map[string]any{"msg": msg})
+			out := i.Eval(in)
+			if tc.err != "" {
+				if !types.IsError(out) || out.(*types.Err).Error() != tc.err {
+					t.Errorf("got %v, wanted error %q", out, tc.err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(out.Value(), tc.out) {
+				t.Errorf("got %v, wanted %v", out.Value(), tc.out)
+			}
+		})
+	}
+}
+
 func BenchmarkResolverCustomQualifier(b *testing.B) {
 	reg := newBenchRegistry(b)
 	attrs := &custAttrFactory{
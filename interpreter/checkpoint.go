@@ -0,0 +1,176 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+)
+
+// Checkpoint records the progress of an evaluation which was suspended, so that a later
+// evaluation against the same activation may resume from where the suspended one left off.
+//
+// The progress is recorded per comprehension planned with the CheckpointFolds decorator, keyed by
+// expression id. A checkpoint must only be used to resume evaluations of the program which
+// produced it against the same inputs.
+type Checkpoint struct {
+	Folds map[int64]*FoldCheckpoint
+}
+
+// FoldCheckpoint records the progress of a single comprehension.
+//
+// The accumulator values of the standard macros are ordinary CEL values, and so a checkpoint may be
+// serialized with cel.CheckpointToValue.
+type FoldCheckpoint struct {
+	// Iterations is the number of elements of the range which have been folded into Accu.
+	Iterations int
+
+	// Accu is the value of the accumulator after the iterations, or the result of the
+	// comprehension when Done is true.
+	Accu ref.Val
+
+	// Done indicates that the comprehension completed, in which case its result is reused rather
+	// than recomputed when the evaluation is resumed.
+	Done bool
+}
+
+// NewCheckpoint returns an empty Checkpoint, from which an evaluation starts at the beginning.
+func NewCheckpoint() *Checkpoint {
+	return &Checkpoint{Folds: map[int64]*FoldCheckpoint{}}
+}
+
+// NewCheckpointActivation returns an Activation which records the progress of the comprehensions
+// planned with the CheckpointFolds decorator into the checkpoint, and resumes them from the
+// progress it already records.
+//
+// The suspend function is called after each iteration of these comprehensions, and when it
+// returns true the evaluation is suspended by panicking with an EvalCancelledError whose Cause is
+// EvalSuspended. The function typically returns true once the evaluation has used up its slice
+// of time or of iterations, so that cooperative schedulers may interleave long-running
+// evaluations.
+func NewCheckpointActivation(parent Activation, checkpoint *Checkpoint, suspend func() bool) Activation {
+	return &checkpointActivation{parent: parent, state: &checkpointState{checkpoint: checkpoint, suspend: suspend}}
+}
+
+// checkpointActivation supplies the checkpoint state of the evaluation via the `#checkpoint`
+// variable.
+type checkpointActivation struct {
+	parent Activation
+	state  *checkpointState
+}
+
+// Parent implements the Activation interface method.
+func (a *checkpointActivation) Parent() Activation {
+	return a.parent
+}
+
+// ResolveName implements the Activation interface method.
+func (a *checkpointActivation) ResolveName(name string) (any, bool) {
+	if name == "#checkpoint" {
+		return a.state, true
+	}
+	return a.parent.ResolveName(name)
+}
+
+type checkpointState struct {
+	checkpoint *Checkpoint
+	suspend    func() bool
+}
+
+// evalCheckpointed evaluates a comprehension from the progress recorded for it in the checkpoint,
+// recording its progress when the evaluation is suspended and its result when it completes.
+//
+// Resuming skips the iterations which were already folded into the recorded accumulator, and so
+// relies upon the range being iterated in the same order, which the CheckpointFolds decorator
+// guarantees for maps by iterating them in sorted order.
+func (fold *evalFold) evalCheckpointed(ctx Activation, foldRange ref.Val, state *checkpointState) ref.Val {
+	saved := state.checkpoint.Folds[fold.id]
+	if saved != nil && saved.Done {
+		return saved.Accu
+	}
+	accuCtx := varActivationPool.Get().(*varActivation)
+	accuCtx.parent = ctx
+	accuCtx.name = fold.accuVar
+	accuCtx.val = fold.accu.Eval(ctx)
+	iterCtx := varActivationPool.Get().(*varActivation)
+	iterCtx.parent = accuCtx
+	iterCtx.name = fold.iterVar
+	defer varActivationPool.Put(accuCtx)
+	defer varActivationPool.Put(iterCtx)
+
+	l, ok := accuCtx.val.(traits.Lister)
+	buildingList := !fold.exhaustive && ok && l.Size() == types.IntZero
+	skip := 0
+	if saved != nil {
+		accuCtx.val = saved.Accu
+		skip = saved.Iterations
+	}
+	if buildingList {
+		accuCtx.val = types.NewMutableList(fold.adapter).Add(accuCtx.val)
+	}
+
+	terminated := false
+	iterations := 0
+	it := foldRange.(traits.Iterable).Iterator()
+	if m, isMap := foldRange.(traits.Mapper); isMap && fold.sortedMaps {
+		it = types.NewSortedMapIterator(m)
+	}
+	for it.HasNext() == types.True {
+		elem := it.Next()
+		if iterations < skip {
+			iterations++
+			continue
+		}
+		iterCtx.val = elem
+		cond := fold.cond.Eval(iterCtx)
+		condBool, ok := cond.(types.Bool)
+		if !fold.exhaustive && ok && condBool != types.True {
+			terminated = true
+			break
+		}
+		iterations++
+		accuCtx.val = fold.step.Eval(iterCtx)
+		if fold.interruptable {
+			if stop, found := ctx.ResolveName("#interrupted"); found && stop == true {
+				return types.NewErr("operation interrupted")
+			}
+		}
+		if state.suspend() {
+			state.checkpoint.Folds[fold.id] = &FoldCheckpoint{
+				Iterations: iterations,
+				Accu:       immutableAccu(accuCtx.val),
+			}
+			panic(EvalCancelledError{Cause: EvalSuspended, Message: "operation suspended"})
+		}
+	}
+	// The observer is notified once the comprehension completes, with the iterations of all of the
+	// evaluations over which it was suspended.
+	if fold.observer != nil {
+		fold.observer(fold.id, iterations, terminated)
+	}
+	res := immutableAccu(fold.result.Eval(accuCtx))
+	state.checkpoint.Folds[fold.id] = &FoldCheckpoint{Iterations: iterations, Accu: res, Done: true}
+	return res
+}
+
+// immutableAccu converts a mutable list accumulator into an immutable list, which remains valid
+// once the evaluation which mutated it has ended.
+func immutableAccu(accu ref.Val) ref.Val {
+	if l, isMutable := accu.(traits.MutableLister); isMutable {
+		return l.ToImmutableList()
+	}
+	return accu
+}
@@ -26,46 +26,171 @@ import (
 type InterpretableDecorator func(Interpretable) (Interpretable, error)
 
 // decObserveEval records evaluation state into an EvalState object.
-func decObserveEval(observer EvalObserver) InterpretableDecorator {
+//
+// Interpretables which are already watched call the observer after their existing observers.
+func decObserveEval(observer watchFunc) InterpretableDecorator {
+	return decWatchEval(&watcher{fn: observer})
+}
+
+// decPatchEval substitutes the values of evaluation steps with those produced by the patch, which
+// is not called by warm-up evaluations.
+func decPatchEval(patch watchFunc) InterpretableDecorator {
+	return decWatchEval(&watcher{fn: patch, patch: true})
+}
+
+func decWatchEval(w *watcher) InterpretableDecorator {
 	return func(i Interpretable) (Interpretable, error) {
 		switch inst := i.(type) {
-		case *evalWatch, *evalWatchAttr, *evalWatchConst, *evalWatchConstructor:
-			// these instruction are already watching, return straight-away.
+		case *evalWatch:
+			inst.observers = inst.observers.with(w)
+			return i, nil
+		case *evalWatchAttr:
+			inst.observers = inst.observers.with(w)
+			return i, nil
+		case *evalWatchConst:
+			inst.observers = inst.observers.with(w)
+			return i, nil
+		case *evalWatchConstructor:
+			inst.observers = inst.observers.with(w)
 			return i, nil
 		case InterpretableAttribute:
 			return &evalWatchAttr{
 				InterpretableAttribute: inst,
-				observer:               observer,
+				observers:              watchers{w},
 			}, nil
 		case InterpretableConst:
 			return &evalWatchConst{
 				InterpretableConst: inst,
-				observer:           observer,
+				observers:          watchers{w},
 			}, nil
 		case InterpretableConstructor:
 			return &evalWatchConstructor{
 				constructor: inst,
-				observer:    observer,
+				observers:   watchers{w},
 			}, nil
 		default:
 			return &evalWatch{
 				Interpretable: i,
-				observer:      observer,
+				observers:     watchers{w},
 			}, nil
 		}
 	}
 }
 
+// watchFunc is the form of the observers and patchers called by the watchers of Interpretables,
+// which returns the value of the evaluation step and whether it was replaced.
+type watchFunc func(id int64, programStep any, value ref.Val) (ref.Val, bool)
+
+// watcher is a watchFunc applied by a decorator, where the watchFuncs of patches are not called by
+// warm-up evaluations.
+type watcher struct {
+	fn    watchFunc
+	patch bool
+}
+
+// watchers are the watchers of the decorators which have watched an Interpretable, in the order
+// in which the decorators were applied.
+type watchers []*watcher
+
+// with returns the watchers with the watcher appended, unless it is already present because the
+// Interpretable was decorated more than once by the same decorator.
+func (ws watchers) with(w *watcher) watchers {
+	for _, existing := range ws {
+		if existing == w {
+			return ws
+		}
+	}
+	return append(ws[:len(ws):len(ws)], w)
+}
+
+// observe calls each watchFunc with the value produced by the preceding one, skipping the patches
+// when the activation evaluates the program in warm-up mode.
+func (ws watchers) observe(vars Activation, id int64, programStep any, value ref.Val) (ref.Val, bool) {
+	patched := false
+	warmup, checked := false, false
+	for _, w := range ws {
+		if w.patch {
+			if !checked {
+				warmup, checked = isWarmup(vars), true
+			}
+			if warmup {
+				continue
+			}
+		}
+		var isPatched bool
+		value, isPatched = w.fn(id, programStep, value)
+		patched = patched || isPatched
+	}
+	return value, patched
+}
+
 // decInterruptFolds creates an intepretable decorator which marks comprehensions as interruptable
 // where the interrupt state is communicated via a hidden variable on the Activation.
 func decInterruptFolds() InterpretableDecorator {
 	return func(i Interpretable) (Interpretable, error) {
-		fold, ok := i.(*evalFold)
-		if !ok {
-			return i, nil
+		switch fold := i.(type) {
+		case *evalFold:
+			fold.interruptable = true
+		case *evalJoinFold:
+			fold.interruptable = true
 		}
-		fold.interruptable = true
-		return fold, nil
+		return i, nil
+	}
+}
+
+// decSortMapFolds creates an interpretable decorator which makes comprehensions iterate over the
+// keys of maps in sorted order.
+func decSortMapFolds() InterpretableDecorator {
+	return func(i Interpretable) (Interpretable, error) {
+		switch fold := i.(type) {
+		case *evalFold:
+			fold.sortedMaps = true
+		case *evalJoinFold:
+			fold.sortedMaps = true
+		}
+		return i, nil
+	}
+}
+
+// decObserveFolds creates an interpretable decorator which reports the iterations of each
+// comprehension to the observer.
+func decObserveFolds(observer FoldObserver) InterpretableDecorator {
+	return func(i Interpretable) (Interpretable, error) {
+		switch fold := i.(type) {
+		case *evalFold:
+			fold.observer = observer
+		case *evalJoinFold:
+			fold.observer = observer
+		}
+		return i, nil
+	}
+}
+
+// decStreamFold marks the comprehension with the given id as delivering the elements of the list
+// it builds to the sink of a stream activation.
+func decStreamFold(id int64) InterpretableDecorator {
+	return func(i Interpretable) (Interpretable, error) {
+		if fold, isFold := i.(*evalFold); isFold && fold.id == id {
+			fold.stream = true
+		}
+		return i, nil
+	}
+}
+
+// decCheckpointFolds marks the comprehensions with the given ids as recording their progress into
+// the checkpoint of a checkpoint activation.
+func decCheckpointFolds(ids []int64) InterpretableDecorator {
+	idSet := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		idSet[id] = true
+	}
+	return func(i Interpretable) (Interpretable, error) {
+		if fold, isFold := i.(*evalFold); isFold && idSet[fold.id] {
+			// Resuming relies upon the range being iterated in the same order by each evaluation.
+			fold.checkpointed = true
+			fold.sortedMaps = true
+		}
+		return i, nil
 	}
 }
 
@@ -88,6 +213,11 @@ func decDisableShortcircuits() InterpretableDecorator {
 		case *evalFold:
 			expr.exhaustive = true
 			return expr, nil
+		case *evalJoinFold:
+			// Every iteration of an exhaustive fold is evaluated, including those which follow an
+			// error, and so the fold is evaluated as written.
+			expr.exhaustive = true
+			return expr.evalFold, nil
 		case InterpretableAttribute:
 			cond, isCond := expr.Attr().(*conditionalAttribute)
 			if isCond {
@@ -106,6 +236,7 @@ func decDisableShortcircuits() InterpretableDecorator {
 // conditionally precomputing the result.
 // - build list and map values with constant elements.
 // - convert 'in' operations to set membership tests if possible.
+// - flatten chains of string and list concatenations.
 func decOptimize() InterpretableDecorator {
 	return func(i Interpretable) (Interpretable, error) {
 		switch inst := i.(type) {
@@ -113,6 +244,8 @@ func decOptimize() InterpretableDecorator {
 			return maybeBuildListLiteral(i, inst)
 		case *evalMap:
 			return maybeBuildMapLiteral(i, inst)
+		case *evalFold:
+			return maybeJoinFold(i, inst)
 		case InterpretableCall:
 			if inst.OverloadID() == overloads.InList {
 				return maybeOptimizeSetMembership(i, inst)
@@ -120,6 +253,9 @@ func decOptimize() InterpretableDecorator {
 			if overloads.IsTypeConversionFunction(inst.Function()) {
 				return maybeOptimizeConstUnary(i, inst)
 			}
+			if inst.OverloadID() == overloads.AddString || inst.OverloadID() == overloads.AddList {
+				return maybeFlattenConcat(i, inst)
+			}
 		}
 		return i, nil
 	}
@@ -183,6 +319,93 @@ func maybeOptimizeConstUnary(i Interpretable, call InterpretableCall) (Interpret
 	return NewConstValue(call.ID(), val), nil
 }
 
+// maybeFlattenConcat merges a concatenation whose operands are concatenations of the same type
+// into a single n-ary concatenation.
+func maybeFlattenConcat(i Interpretable, call InterpretableCall) (Interpretable, error) {
+	bin, ok := call.(*evalBinary)
+	if !ok {
+		return i, nil
+	}
+	var args []Interpretable
+	flattened := false
+	for _, arg := range []Interpretable{bin.lhs, bin.rhs} {
+		switch a := arg.(type) {
+		case *evalBinary:
+			if a.overload == bin.overload {
+				args = append(args, a.lhs, a.rhs)
+				flattened = true
+				continue
+			}
+		case *evalConcat:
+			if a.bin.overload == bin.overload {
+				args = append(args, a.args...)
+				flattened = true
+				continue
+			}
+		}
+		args = append(args, arg)
+	}
+	if !flattened {
+		return i, nil
+	}
+	return &evalConcat{bin: *bin, args: args}, nil
+}
+
+// maybeJoinFold converts a fold which accumulates a string into a join of the strings appended to
+// the accumulator by each iteration, provided that:
+// - the accumulator is initialized to the empty string and is the result of the fold.
+// - the loop condition is the constant true.
+// - the loop step is a string concatenation whose first operand is the accumulator.
+func maybeJoinFold(i Interpretable, fold *evalFold) (Interpretable, error) {
+	if fold.exhaustive {
+		return i, nil
+	}
+	init, isConst := fold.accu.(InterpretableConst)
+	if !isConst || init.Value() != types.String("") {
+		return i, nil
+	}
+	cond, isConst := fold.cond.(InterpretableConst)
+	if !isConst || cond.Value() != types.True || !isVarRef(fold.result, fold.accuVar) {
+		return i, nil
+	}
+	var bin *evalBinary
+	var args []Interpretable
+	switch step := fold.step.(type) {
+	case *evalBinary:
+		bin, args = step, []Interpretable{step.lhs, step.rhs}
+	case *evalConcat:
+		bin, args = &step.bin, step.args
+	default:
+		return i, nil
+	}
+	if bin.overload != overloads.AddString || !isVarRef(args[0], fold.accuVar) {
+		return i, nil
+	}
+	return &evalJoinFold{evalFold: fold, bin: *bin, parts: args[1:]}, nil
+}
+
+// isVarRef returns whether the Interpretable is an unqualified reference to the named variable.
+func isVarRef(i Interpretable, name string) bool {
+	attr, isAttr := i.(InterpretableAttribute)
+	if !isAttr {
+		return false
+	}
+	var nsAttr NamespacedAttribute
+	switch a := attr.Attr().(type) {
+	case NamespacedAttribute:
+		nsAttr = a
+	case *maybeAttribute:
+		if len(a.attrs) != 1 {
+			return false
+		}
+		nsAttr = a.attrs[0]
+	default:
+		return false
+	}
+	names := nsAttr.CandidateVariableNames()
+	return len(nsAttr.Qualifiers()) == 0 && len(names) == 1 && names[0] == name
+}
+
 func maybeBuildListLiteral(i Interpretable, l *evalList) (Interpretable, error) {
 	for _, elem := range l.elems {
 		_, isConst := elem.(InterpretableConst)
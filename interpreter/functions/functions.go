@@ -16,7 +16,11 @@
 // interpreter and as declared within the checker#StandardDeclarations.
 package functions
 
-import "github.com/google/cel-go/common/types/ref"
+import (
+	"context"
+
+	"github.com/google/cel-go/common/types/ref"
+)
 
 // Overload defines a named overload of a function, indicating an operand trait
 // which must be present on the first argument to the overload as well as one
@@ -46,6 +50,11 @@ type Overload struct {
 	// nil.
 	Function FunctionOp
 
+	// ContextFunction defines the overload with a ContextFunctionOp
+	// implementation which receives the context of the evaluation. When set,
+	// it takes precedence over the other implementations. May be nil.
+	ContextFunction ContextFunctionOp
+
 	// NonStrict specifies whether the Overload will tolerate arguments that
 	// are types.Err or types.Unknown.
 	NonStrict bool
@@ -60,3 +69,10 @@ type BinaryOp func(lhs ref.Val, rhs ref.Val) ref.Val
 // FunctionOp is a function with accepts zero or more arguments and produces
 // a value or error as a result.
 type FunctionOp func(values ...ref.Val) ref.Val
+
+// ContextFunctionOp is a function which accepts the context of the evaluation
+// along with zero or more arguments and produces a value or error as a result.
+//
+// The context is the one provided to the evaluation, e.g. via ContextEval, or
+// context.Background() when none was provided.
+type ContextFunctionOp func(ctx context.Context, values ...ref.Val) ref.Val
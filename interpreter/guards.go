@@ -0,0 +1,116 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter/functions"
+)
+
+// CallGuard validates the arguments of a call before the implementation of the call is invoked.
+//
+// A nil result permits the call. Any other result, typically an error or unknown value, vetoes the
+// call and is returned as its result.
+type CallGuard func(vars Activation, overloadID string, args []ref.Val) ref.Val
+
+// GuardCalls returns an InterpretableDecorator which invokes the guards registered for a call's
+// overload id, and for its function name, before the implementation of the call.
+//
+// The implementation of a guarded call is resolved from the dispatcher in the same manner as the
+// planner, unless an implementation is supplied at evaluation time for a late bound call. Guards
+// are not invoked for the arguments of a strict call which are errors or unknowns, since the call
+// is never invoked with them. Guards are not invoked by evaluations in warm-up mode, see
+// NewWarmupActivation.
+func GuardCalls(disp Dispatcher, guards map[string]CallGuard) InterpretableDecorator {
+	return func(i Interpretable) (Interpretable, error) {
+		call, ok := i.(InterpretableCall)
+		if !ok {
+			return i, nil
+		}
+		var matched []CallGuard
+		if g, found := guards[call.OverloadID()]; found && call.OverloadID() != "" {
+			matched = append(matched, g)
+		}
+		if g, found := guards[call.Function()]; found && call.Function() != call.OverloadID() {
+			matched = append(matched, g)
+		}
+		if len(matched) == 0 {
+			return i, nil
+		}
+		impl, found := disp.FindOverload(call.OverloadID())
+		if !found {
+			impl, found = disp.FindOverload(call.Function())
+		}
+		if !found {
+			return i, nil
+		}
+		return &evalGuardedCall{InterpretableCall: call, impl: impl, guards: matched}, nil
+	}
+}
+
+// evalGuardedCall evaluates a call after its arguments are permitted by the guards.
+type evalGuardedCall struct {
+	InterpretableCall
+	impl   *functions.Overload
+	guards []CallGuard
+}
+
+// Eval implements the Interpretable interface method.
+func (call *evalGuardedCall) Eval(ctx Activation) ref.Val {
+	argVals, errVal := evalCallArgs(ctx, call, resolveCallImpl(ctx, call, call.impl))
+	if errVal != nil {
+		return errVal
+	}
+	return call.applyArgs(ctx, argVals)
+}
+
+// applyArgs implements the callApplier interface method.
+func (call *evalGuardedCall) applyArgs(ctx Activation, argVals []ref.Val) ref.Val {
+	if isWarmup(ctx) {
+		return applyCall(ctx, call.InterpretableCall, call.impl, argVals)
+	}
+	for _, guard := range call.guards {
+		if veto := guard(ctx, call.OverloadID(), argVals); veto != nil {
+			return veto
+		}
+	}
+	return applyCall(ctx, call.InterpretableCall, call.impl, argVals)
+}
+
+// callApplier is implemented by the call decorators which evaluate the arguments of a call before
+// invoking its implementation, so that such decorators may be composed without evaluating the
+// arguments more than once.
+type callApplier interface {
+	// applyArgs invokes the call with the evaluated argument values.
+	applyArgs(ctx Activation, argVals []ref.Val) ref.Val
+}
+
+// resolveCallImpl returns the implementation of the call supplied by the activation for late bound
+// calls, or else the implementation resolved at planning time.
+func resolveCallImpl(ctx Activation, call InterpretableCall, impl *functions.Overload) *functions.Overload {
+	if bound, found := findFunctionBinding(ctx, call.OverloadID()); found {
+		return bound
+	}
+	return impl
+}
+
+// applyCall invokes the wrapped call with the evaluated argument values, delegating to the wrapped
+// call when it is itself a call decorator.
+func applyCall(ctx Activation, call InterpretableCall, impl *functions.Overload, argVals []ref.Val) ref.Val {
+	if applier, ok := call.(callApplier); ok {
+		return applier.applyArgs(ctx, argVals)
+	}
+	return applyOverload(ctx, call, resolveCallImpl(ctx, call, impl), argVals)
+}
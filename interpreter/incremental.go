@@ -0,0 +1,145 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// IncrementalCache retains the results of subexpressions between evaluations, keyed by expression
+// id, until one of the attribute paths the subexpression depends on is reported as changed.
+//
+// Attribute paths are dot-delimited variable names followed by field selections, e.g. `a.b.c`.
+// A change to a path invalidates the subexpressions which depend on the path, on a path nested
+// within it, or on a path which contains it: a change to `a.b` invalidates dependencies on `a`,
+// `a.b`, and `a.b.c`, but not on `a.d`.
+//
+// An IncrementalCache is safe for concurrent use, though the cached results are only meaningful
+// when every evaluation observes the same input except for the changes reported to the cache.
+type IncrementalCache struct {
+	mu         sync.Mutex
+	deps       map[int64][]string
+	dependents map[string][]int64
+	results    map[int64]ref.Val
+}
+
+// NewIncrementalCache creates an IncrementalCache from the attribute paths on which each cacheable
+// expression id depends.
+//
+// Expression ids which are absent from the map are never cached. This includes subexpressions
+// which refer to comprehension variables, call nondeterministic functions, or have no
+// dependencies at all.
+func NewIncrementalCache(deps map[int64][]string) *IncrementalCache {
+	dependents := make(map[string][]int64)
+	for id, paths := range deps {
+		for _, path := range paths {
+			dependents[path] = append(dependents[path], id)
+		}
+	}
+	return &IncrementalCache{
+		deps:       deps,
+		dependents: dependents,
+		results:    make(map[int64]ref.Val),
+	}
+}
+
+// NotifyChanged invalidates the cached results of the subexpressions which depend on the
+// attribute path.
+func (c *IncrementalCache) NotifyChanged(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for dep, ids := range c.dependents {
+		if !pathsOverlap(dep, path) {
+			continue
+		}
+		for _, id := range ids {
+			delete(c.results, id)
+		}
+	}
+}
+
+// Reset invalidates all cached results.
+func (c *IncrementalCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results = make(map[int64]ref.Val)
+}
+
+func (c *IncrementalCache) result(id int64) (ref.Val, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	val, found := c.results[id]
+	return val, found
+}
+
+func (c *IncrementalCache) store(id int64, val ref.Val) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results[id] = val
+}
+
+// pathsOverlap returns whether one attribute path is equal to, or nested within, the other.
+func pathsOverlap(a, b string) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	return a == b || strings.HasPrefix(b, a+".")
+}
+
+// IncrementalEval returns an InterpretableDecorator which caches the results of the cacheable
+// subexpressions within the IncrementalCache.
+//
+// Attribute lookups are not cached, since they are no more expensive than the cache lookup and
+// the planner relies upon their type when qualifying them further. The results of evaluations in
+// warm-up mode are not cached.
+func IncrementalEval(cache *IncrementalCache) InterpretableDecorator {
+	return func(i Interpretable) (Interpretable, error) {
+		if _, isAttr := i.(InterpretableAttribute); isAttr {
+			return i, nil
+		}
+		if _, isConst := i.(InterpretableConst); isConst {
+			return i, nil
+		}
+		if _, found := cache.deps[i.ID()]; !found {
+			return i, nil
+		}
+		return &evalIncremental{Interpretable: i, cache: cache}, nil
+	}
+}
+
+// evalIncremental returns the cached result of the wrapped Interpretable when one is present.
+type evalIncremental struct {
+	Interpretable
+	cache *IncrementalCache
+}
+
+// Eval implements the Interpretable interface method.
+func (e *evalIncremental) Eval(ctx Activation) ref.Val {
+	if val, found := e.cache.result(e.ID()); found {
+		return val
+	}
+	val := e.Interpretable.Eval(ctx)
+	// Errors and unknowns may be specific to the evaluation, such as when it was interrupted, so
+	// they are computed again by subsequent evaluations. The results of warm-up evaluations are
+	// computed from sample inputs, and so are not cached either.
+	if !types.IsUnknownOrError(val) && !isWarmup(ctx) {
+		e.cache.store(e.ID(), val)
+	}
+	return val
+}
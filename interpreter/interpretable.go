@@ -15,6 +15,9 @@
 package interpreter
 
 import (
+	"context"
+	"strings"
+
 	"github.com/google/cel-go/common/operators"
 	"github.com/google/cel-go/common/overloads"
 	"github.com/google/cel-go/common/types"
@@ -105,6 +108,17 @@ type InterpretableConstructor interface {
 	Type() ref.Type
 }
 
+// CancellableInterpretable interface for evaluating an Interpretable under a context.Context, so
+// that long-running evaluations may be cancelled or time out.
+type CancellableInterpretable interface {
+	Interpretable
+
+	// EvalContext evaluates the Interpretable with the activation, interrupting the evaluation of
+	// comprehensions once the context is done. The result of an interrupted evaluation is an error
+	// which includes the reason the context is done.
+	EvalContext(ctx context.Context, vars Activation) ref.Val
+}
+
 // Core Interpretable implementations used during the program planning phase.
 
 type evalTestOnly struct {
@@ -188,17 +202,22 @@ func (or *evalOr) ID() int64 {
 func (or *evalOr) Eval(ctx Activation) ref.Val {
 	// short-circuit lhs.
 	lVal := or.lhs.Eval(ctx)
-	lBool, lok := lVal.(types.Bool)
-	if lok && lBool == types.True {
+	if lVal == types.True {
 		return types.True
 	}
+	return logicalOr(lVal, or.rhs.Eval(ctx))
+}
+
+// logicalOr computes the result of a logical or once the left-hand side has been determined
+// not to be `true`.
+func logicalOr(lVal, rVal ref.Val) ref.Val {
 	// short-circuit on rhs.
-	rVal := or.rhs.Eval(ctx)
 	rBool, rok := rVal.(types.Bool)
 	if rok && rBool == types.True {
 		return types.True
 	}
 	// return if both sides are bool false.
+	_, lok := lVal.(types.Bool)
 	if lok && rok {
 		return types.False
 	}
@@ -232,17 +251,22 @@ func (and *evalAnd) ID() int64 {
 func (and *evalAnd) Eval(ctx Activation) ref.Val {
 	// short-circuit lhs.
 	lVal := and.lhs.Eval(ctx)
-	lBool, lok := lVal.(types.Bool)
-	if lok && lBool == types.False {
+	if lVal == types.False {
 		return types.False
 	}
+	return logicalAnd(lVal, and.rhs.Eval(ctx))
+}
+
+// logicalAnd computes the result of a logical and once the left-hand side has been determined
+// not to be `false`.
+func logicalAnd(lVal, rVal ref.Val) ref.Val {
 	// short-circuit on rhs.
-	rVal := and.rhs.Eval(ctx)
 	rBool, rok := rVal.(types.Bool)
 	if rok && rBool == types.False {
 		return types.False
 	}
 	// return if both sides are bool true.
+	_, lok := lVal.(types.Bool)
 	if lok && rok {
 		return types.True
 	}
@@ -274,8 +298,10 @@ func (eq *evalEq) ID() int64 {
 
 // Eval implements the Interpretable interface method.
 func (eq *evalEq) Eval(ctx Activation) ref.Val {
-	lVal := eq.lhs.Eval(ctx)
-	rVal := eq.rhs.Eval(ctx)
+	return equals(eq.lhs.Eval(ctx), eq.rhs.Eval(ctx))
+}
+
+func equals(lVal, rVal ref.Val) ref.Val {
 	if types.IsUnknownOrError(lVal) {
 		return lVal
 	}
@@ -313,8 +339,10 @@ func (ne *evalNe) ID() int64 {
 
 // Eval implements the Interpretable interface method.
 func (ne *evalNe) Eval(ctx Activation) ref.Val {
-	lVal := ne.lhs.Eval(ctx)
-	rVal := ne.rhs.Eval(ctx)
+	return notEquals(ne.lhs.Eval(ctx), ne.rhs.Eval(ctx))
+}
+
+func notEquals(lVal, rVal ref.Val) ref.Val {
 	if types.IsUnknownOrError(lVal) {
 		return lVal
 	}
@@ -388,7 +416,11 @@ func (un *evalUnary) ID() int64 {
 
 // Eval implements the Interpretable interface method.
 func (un *evalUnary) Eval(ctx Activation) ref.Val {
-	argVal := un.arg.Eval(ctx)
+	return un.apply(un.arg.Eval(ctx))
+}
+
+// apply invokes the unary function with the evaluated argument.
+func (un *evalUnary) apply(argVal ref.Val) ref.Val {
 	// Early return if the argument to the function is unknown or error.
 	strict := !un.nonStrict
 	if strict && types.IsUnknownOrError(argVal) {
@@ -440,8 +472,11 @@ func (bin *evalBinary) ID() int64 {
 
 // Eval implements the Interpretable interface method.
 func (bin *evalBinary) Eval(ctx Activation) ref.Val {
-	lVal := bin.lhs.Eval(ctx)
-	rVal := bin.rhs.Eval(ctx)
+	return bin.apply(bin.lhs.Eval(ctx), bin.rhs.Eval(ctx))
+}
+
+// apply invokes the binary function with the evaluated arguments.
+func (bin *evalBinary) apply(lVal, rVal ref.Val) ref.Val {
 	// Early return if any argument to the function is unknown or error.
 	strict := !bin.nonStrict
 	if strict {
@@ -480,6 +515,104 @@ func (bin *evalBinary) Args() []Interpretable {
 	return []Interpretable{bin.lhs, bin.rhs}
 }
 
+// evalConcat is a flattened chain of string or list concatenations which computes the result with
+// a single allocation rather than one allocation per pairwise concatenation.
+type evalConcat struct {
+	// bin holds the function, overload, and implementation of the pairwise concatenation.
+	bin  evalBinary
+	args []Interpretable
+}
+
+// ID implements the Interpretable interface method.
+func (c *evalConcat) ID() int64 {
+	return c.bin.id
+}
+
+// Eval implements the Interpretable interface method.
+func (c *evalConcat) Eval(ctx Activation) ref.Val {
+	vals := make([]ref.Val, len(c.args))
+	for i, arg := range c.args {
+		vals[i] = arg.Eval(ctx)
+	}
+	// As with pairwise concatenation, the first unknown or error argument is the result.
+	for _, val := range vals {
+		if types.IsUnknownOrError(val) {
+			return val
+		}
+	}
+	switch c.bin.overload {
+	case overloads.AddString:
+		if out, ok := concatStringValues(vals); ok {
+			return out
+		}
+	case overloads.AddList:
+		if out, ok := concatListValues(vals); ok {
+			return out
+		}
+	}
+	// Values of an unexpected type are concatenated pairwise in order to produce the same result as
+	// the unflattened expression.
+	out := vals[0]
+	for _, val := range vals[1:] {
+		out = c.bin.apply(out, val)
+	}
+	return out
+}
+
+// Function implements the InterpretableCall interface method.
+func (c *evalConcat) Function() string {
+	return c.bin.function
+}
+
+// OverloadID implements the InterpretableCall interface method.
+func (c *evalConcat) OverloadID() string {
+	return c.bin.overload
+}
+
+// Args returns the operands of the concatenation.
+func (c *evalConcat) Args() []Interpretable {
+	return c.args
+}
+
+func concatStringValues(vals []ref.Val) (ref.Val, bool) {
+	size := 0
+	for _, val := range vals {
+		str, ok := val.(types.String)
+		if !ok {
+			return nil, false
+		}
+		size += len(str)
+	}
+	var sb strings.Builder
+	sb.Grow(size)
+	for _, val := range vals {
+		sb.WriteString(string(val.(types.String)))
+	}
+	return types.String(sb.String()), true
+}
+
+func concatListValues(vals []ref.Val) (ref.Val, bool) {
+	size := 0
+	for _, val := range vals {
+		list, ok := val.(traits.Lister)
+		if !ok {
+			return nil, false
+		}
+		listSize, ok := list.Size().(types.Int)
+		if !ok {
+			return nil, false
+		}
+		size += int(listSize)
+	}
+	elems := make([]ref.Val, 0, size)
+	for _, val := range vals {
+		for it := val.(traits.Lister).Iterator(); it.HasNext() == types.True; {
+			elems = append(elems, it.Next())
+		}
+	}
+	return types.NewRefValList(types.DefaultTypeAdapter, elems), true
+}
+
 type evalVarArgs struct {
 	id        int64
 	function  string
@@ -517,6 +650,13 @@ func (fn *evalVarArgs) Eval(ctx Activation) ref.Val {
 			return argVals[i]
 		}
 	}
+	return fn.apply(argVals)
+}
+
+// apply invokes the function with the evaluated arguments, where the arguments are known to be
+// neither unknown nor error when the function is strict.
+func (fn *evalVarArgs) apply(argVals []ref.Val) ref.Val {
+	strict := !fn.nonStrict
 	// If the implementation is bound and the argument value has the right traits required to
 	// invoke it, then call the implementation.
 	arg0 := argVals[0]
@@ -546,6 +686,56 @@ func (fn *evalVarArgs) Args() []Interpretable {
 	return fn.args
 }
 
+// evalContextCall invokes a context-aware function implementation with the context of the
+// evaluation.
+type evalContextCall struct {
+	id        int64
+	function  string
+	overload  string
+	args      []Interpretable
+	trait     int
+	impl      functions.ContextFunctionOp
+	nonStrict bool
+}
+
+// ID implements the Interpretable interface method.
+func (fn *evalContextCall) ID() int64 {
+	return fn.id
+}
+
+// Eval implements the Interpretable interface method.
+func (fn *evalContextCall) Eval(ctx Activation) ref.Val {
+	argVals := make([]ref.Val, len(fn.args))
+	// Early return if any argument to the function is unknown or error.
+	strict := !fn.nonStrict
+	for i, arg := range fn.args {
+		argVals[i] = arg.Eval(ctx)
+		if strict && types.IsUnknownOrError(argVals[i]) {
+			return argVals[i]
+		}
+	}
+	if fn.trait != 0 && len(argVals) > 0 && !(!strict && types.IsUnknownOrError(argVals[0])) &&
+		!argVals[0].Type().HasTrait(fn.trait) {
+		return types.NewErr("no such overload: %s", fn.function)
+	}
+	return fn.impl(EvalContext(ctx), argVals...)
+}
+
+// Function implements the InterpretableCall interface method.
+func (fn *evalContextCall) Function() string {
+	return fn.function
+}
+
+// OverloadID implements the InterpretableCall interface method.
+func (fn *evalContextCall) OverloadID() string {
+	return fn.overload
+}
+
+// Args returns the arguments to the function.
+func (fn *evalContextCall) Args() []Interpretable {
+	return fn.args
+}
+
 type evalList struct {
 	id           int64
 	elems        []Interpretable
@@ -561,10 +751,15 @@ func (l *evalList) ID() int64 {
 
 // Eval implements the Interpretable interface method.
 func (l *evalList) Eval(ctx Activation) ref.Val {
+	return l.build(func(i int) ref.Val { return l.elems[i].Eval(ctx) })
+}
+
+// build constructs the list from the element values produced by the elemValAt function.
+func (l *evalList) build(elemValAt func(i int) ref.Val) ref.Val {
 	elemVals := make([]ref.Val, 0, len(l.elems))
 	// If any argument is unknown or error early terminate.
-	for i, elem := range l.elems {
-		elemVal := elem.Eval(ctx)
+	for i := range l.elems {
+		elemVal := elemValAt(i)
 		if types.IsUnknownOrError(elemVal) {
 			return elemVal
 		}
@@ -607,29 +802,61 @@ func (m *evalMap) ID() int64 {
 
 // Eval implements the Interpretable interface method.
 func (m *evalMap) Eval(ctx Activation) ref.Val {
+	return m.build(
+		func(i int) ref.Val { return m.keys[i].Eval(ctx) },
+		func(i int) ref.Val { return m.vals[i].Eval(ctx) })
+}
+
+// build constructs the map from the entry keys and values produced by the keyValAt and valValAt
+// functions.
+func (m *evalMap) build(keyValAt, valValAt func(i int) ref.Val) ref.Val {
 	entries := make(map[ref.Val]ref.Val)
+	// Keys which cannot be used as the keys of Go maps, such as bytes, are identified by their hash
+	// keys, in which case all of the entries are recorded by hash key.
+	var hashKeys, hashVals map[any]ref.Val
 	// If any argument is unknown or error early terminate.
-	for i, key := range m.keys {
-		keyVal := key.Eval(ctx)
+	for i := range m.keys {
+		keyVal := keyValAt(i)
 		if types.IsUnknownOrError(keyVal) {
 			return keyVal
 		}
-		valVal := m.vals[i].Eval(ctx)
+		valVal := valValAt(i)
 		if types.IsUnknownOrError(valVal) {
 			return valVal
 		}
+		if _, hashable := keyVal.(traits.Hashable); hashable && hashKeys == nil {
+			hashKeys = make(map[any]ref.Val, len(m.keys))
+			hashVals = make(map[any]ref.Val, len(m.keys))
+			for k, v := range entries {
+				hashKeys[k] = k
+				hashVals[k] = v
+			}
+		}
 		if m.hasOptionals && m.optionals[i] {
 			optVal, ok := valVal.(*types.Optional)
 			if !ok {
 				return invalidOptionalEntryInit(keyVal, valVal)
 			}
 			if !optVal.HasValue() {
-				delete(entries, keyVal)
+				if hashKeys != nil {
+					delete(hashKeys, types.HashKey(keyVal))
+					delete(hashVals, types.HashKey(keyVal))
+				} else {
+					delete(entries, keyVal)
+				}
 				continue
 			}
 			valVal = optVal.GetValue()
 		}
-		entries[keyVal] = valVal
+		if hashKeys != nil {
+			hashKeys[types.HashKey(keyVal)] = keyVal
+			hashVals[types.HashKey(keyVal)] = valVal
+		} else {
+			entries[keyVal] = valVal
+		}
+	}
+	if hashKeys != nil {
+		return types.NewHashKeyMap(m.adapter, hashKeys, hashVals)
 	}
 	return m.adapter.NativeToValue(entries)
 }
@@ -714,6 +941,10 @@ type evalFold struct {
 	adapter       ref.TypeAdapter
 	exhaustive    bool
 	interruptable bool
+	sortedMaps    bool
+	observer      FoldObserver
+	stream        bool
+	checkpointed  bool
 }
 
 // ID implements the Interpretable interface method.
@@ -727,6 +958,16 @@ func (fold *evalFold) Eval(ctx Activation) ref.Val {
 	if !foldRange.Type().HasTrait(traits.IterableType) {
 		return types.ValOrErr(foldRange, "got '%T', expected iterable type", foldRange)
 	}
+	if fold.stream {
+		if sink, found := ctx.ResolveName("#stream"); found {
+			return fold.evalStream(ctx, foldRange, sink.(ElementSink))
+		}
+	}
+	if fold.checkpointed {
+		if state, found := ctx.ResolveName("#checkpoint"); found {
+			return fold.evalCheckpointed(ctx, foldRange, state.(*checkpointState))
+		}
+	}
 	// Configure the fold activation with the accumulator initial value.
 	accuCtx := varActivationPool.Get().(*varActivation)
 	accuCtx.parent = ctx
@@ -745,7 +986,12 @@ func (fold *evalFold) Eval(ctx Activation) ref.Val {
 	iterCtx.name = fold.iterVar
 
 	interrupted := false
+	terminated := false
+	iterations := 0
 	it := foldRange.(traits.Iterable).Iterator()
+	if m, isMap := foldRange.(traits.Mapper); isMap && fold.sortedMaps {
+		it = types.NewSortedMapIterator(m)
+	}
 	for it.HasNext() == types.True {
 		// Modify the iter var in the fold activation.
 		iterCtx.val = it.Next()
@@ -754,8 +1000,10 @@ func (fold *evalFold) Eval(ctx Activation) ref.Val {
 		cond := fold.cond.Eval(iterCtx)
 		condBool, ok := cond.(types.Bool)
 		if !fold.exhaustive && ok && condBool != types.True {
+			terminated = true
 			break
 		}
+		iterations++
 		// Evaluate the evaluation step into accu var.
 		accuCtx.val = fold.step.Eval(iterCtx)
 		if fold.interruptable {
@@ -770,6 +1018,9 @@ func (fold *evalFold) Eval(ctx Activation) ref.Val {
 		varActivationPool.Put(accuCtx)
 		return types.NewErr("operation interrupted")
 	}
+	if fold.observer != nil {
+		fold.observer(fold.id, iterations, terminated)
+	}
 
 	// Compute the result.
 	res := fold.result.Eval(accuCtx)
@@ -783,9 +1034,80 @@ func (fold *evalFold) Eval(ctx Activation) ref.Val {
 	return res
 }
 
+// evalStream evaluates a comprehension which builds a list by delivering the elements appended by
+// each step to the sink, rather than accumulating them. The accumulator is bound to a new empty
+// list on each iteration, so the memory retained by the evaluation does not grow with the range.
+//
+// The result is the number of elements delivered to the sink.
+func (fold *evalFold) evalStream(ctx Activation, foldRange ref.Val, sink ElementSink) ref.Val {
+	accuCtx := varActivationPool.Get().(*varActivation)
+	accuCtx.parent = ctx
+	accuCtx.name = fold.accuVar
+	iterCtx := varActivationPool.Get().(*varActivation)
+	iterCtx.parent = accuCtx
+	iterCtx.name = fold.iterVar
+	defer varActivationPool.Put(accuCtx)
+	defer varActivationPool.Put(iterCtx)
+
+	delivered := 0
+	it := foldRange.(traits.Iterable).Iterator()
+	if m, isMap := foldRange.(traits.Mapper); isMap && fold.sortedMaps {
+		it = types.NewSortedMapIterator(m)
+	}
+	for it.HasNext() == types.True {
+		iterCtx.val = it.Next()
+		accuCtx.val = types.NewMutableList(fold.adapter)
+		cond := fold.cond.Eval(iterCtx)
+		if condBool, ok := cond.(types.Bool); ok && condBool != types.True {
+			break
+		}
+		step := fold.step.Eval(iterCtx)
+		if types.IsUnknownOrError(step) {
+			return step
+		}
+		elems, isList := step.(traits.Lister)
+		if !isList {
+			return types.ValOrErr(step, "got '%T', expected list", step)
+		}
+		for elemIt := elems.Iterator(); elemIt.HasNext() == types.True; {
+			delivered++
+			if !sink(elemIt.Next()) {
+				return types.Int(delivered)
+			}
+		}
+		if fold.interruptable {
+			if stop, found := ctx.ResolveName("#interrupted"); found && stop == true {
+				return types.NewErr("operation interrupted")
+			}
+		}
+	}
+	return types.Int(delivered)
+}
+
 // Optional Interpretable implementations that specialize, subsume, or extend the core evaluation
 // plan via decorators.
 
+// evalCancellable evaluates an Interpretable with an activation which exposes the state of the
+// context of the evaluation to the comprehensions marked as interruptable.
+type evalCancellable struct {
+	Interpretable
+}
+
+// EvalContext implements the CancellableInterpretable interface method.
+func (c *evalCancellable) EvalContext(ctx context.Context, vars Activation) ref.Val {
+	if err := ctx.Err(); err != nil {
+		return types.NewErr("operation interrupted: %v", err)
+	}
+	if vars == nil {
+		vars = EmptyActivation()
+	}
+	out := c.Eval(&cancellableActivation{parent: vars, ctx: ctx})
+	if err, isErr := out.(*types.Err); isErr && err.String() == "operation interrupted" && ctx.Err() != nil {
+		return types.NewErr("operation interrupted: %v", ctx.Err())
+	}
+	return out
+}
+
 // evalSetMembership is an Interpretable implementation which tests whether an input value
 // exists within the set of map keys used to model a set.
 type evalSetMembership struct {
@@ -811,17 +1133,106 @@ func (e *evalSetMembership) Eval(ctx Activation) ref.Val {
 	return types.False
 }
 
+// evalJoinFold is an Interpretable implementation of a fold which accumulates a string by
+// appending the values of the step operands to the accumulator on each iteration. The values are
+// appended to a single buffer rather than being concatenated into a new string on each iteration,
+// which would copy the accumulated string every time.
+type evalJoinFold struct {
+	*evalFold
+	// bin holds the implementation of the string concatenation of the step.
+	bin evalBinary
+	// parts are the operands of the step which follow the accumulator.
+	parts []Interpretable
+}
+
+// Eval implements the Interpretable interface method.
+func (fold *evalJoinFold) Eval(ctx Activation) ref.Val {
+	foldRange := fold.iterRange.Eval(ctx)
+	if !foldRange.Type().HasTrait(traits.IterableType) {
+		return types.ValOrErr(foldRange, "got '%T', expected iterable type", foldRange)
+	}
+	// The accumulator is only materialized as a string when the step refers to it.
+	accuCtx := &joinActivation{parent: ctx, name: fold.accuVar}
+	iterCtx := varActivationPool.Get().(*varActivation)
+	defer varActivationPool.Put(iterCtx)
+	iterCtx.parent = accuCtx
+	iterCtx.name = fold.iterVar
+
+	it := foldRange.(traits.Iterable).Iterator()
+	if m, isMap := foldRange.(traits.Mapper); isMap && fold.sortedMaps {
+		it = types.NewSortedMapIterator(m)
+	}
+	iterations := 0
+	for it.HasNext() == types.True {
+		iterCtx.val = it.Next()
+		iterations++
+		for _, part := range fold.parts {
+			val := part.Eval(iterCtx)
+			str, isStr := val.(types.String)
+			if isStr {
+				accuCtx.sb.WriteString(string(str))
+				continue
+			}
+			// The first unknown or error is the result of the concatenation, and remains the value
+			// of the accumulator for the rest of the fold.
+			if types.IsUnknownOrError(val) {
+				return val
+			}
+			val = fold.bin.apply(accuCtx.value(), val)
+			str, isStr = val.(types.String)
+			if !isStr {
+				return val
+			}
+			accuCtx.sb.Reset()
+			accuCtx.sb.WriteString(string(str))
+		}
+		if fold.interruptable {
+			if stop, found := ctx.ResolveName("#interrupted"); found && stop == true {
+				return types.NewErr("operation interrupted")
+			}
+		}
+	}
+	if fold.observer != nil {
+		fold.observer(fold.id, iterations, false)
+	}
+	return accuCtx.value()
+}
+
+// joinActivation binds the accumulator of a join fold to the string accumulated so far.
+type joinActivation struct {
+	parent Activation
+	name   string
+	sb     strings.Builder
+}
+
+// Parent implements the Activation interface method.
+func (a *joinActivation) Parent() Activation {
+	return a.parent
+}
+
+// ResolveName implements the Activation interface method.
+func (a *joinActivation) ResolveName(name string) (any, bool) {
+	if name == a.name {
+		return a.value(), true
+	}
+	return a.parent.ResolveName(name)
+}
+
+func (a *joinActivation) value() ref.Val {
+	return types.String(a.sb.String())
+}
+
 // evalWatch is an Interpretable implementation that wraps the execution of a given
 // expression so that it may observe the computed value and send it to an observer.
 type evalWatch struct {
 	Interpretable
-	observer EvalObserver
+	observers watchers
 }
 
 // Eval implements the Interpretable interface method.
 func (e *evalWatch) Eval(ctx Activation) ref.Val {
 	val := e.Interpretable.Eval(ctx)
-	e.observer(e.ID(), e.Interpretable, val)
+	val, _ = e.observers.observe(ctx, e.ID(), e.Interpretable, val)
 	return val
 }
 
@@ -831,7 +1242,7 @@ func (e *evalWatch) Eval(ctx Activation) ref.Val {
 // must implement the InterpretableAttribute interface by proxy.
 type evalWatchAttr struct {
 	InterpretableAttribute
-	observer EvalObserver
+	observers watchers
 }
 
 // AddQualifier creates a wrapper over the incoming qualifier which observes the qualification
@@ -841,13 +1252,13 @@ func (e *evalWatchAttr) AddQualifier(q Qualifier) (Attribute, error) {
 	if isConst {
 		q = &evalWatchConstQual{
 			ConstantQualifier: cq,
-			observer:          e.observer,
+			observers:         e.observers,
 			adapter:           e.InterpretableAttribute.Adapter(),
 		}
 	} else {
 		q = &evalWatchQual{
 			Qualifier: q,
-			observer:  e.observer,
+			observers: e.observers,
 			adapter:   e.InterpretableAttribute.Adapter(),
 		}
 	}
@@ -858,7 +1269,7 @@ func (e *evalWatchAttr) AddQualifier(q Qualifier) (Attribute, error) {
 // Eval implements the Interpretable interface method.
 func (e *evalWatchAttr) Eval(vars Activation) ref.Val {
 	val := e.InterpretableAttribute.Eval(vars)
-	e.observer(e.ID(), e.InterpretableAttribute, val)
+	val, _ = e.observers.observe(vars, e.ID(), e.InterpretableAttribute, val)
 	return val
 }
 
@@ -866,8 +1277,8 @@ func (e *evalWatchAttr) Eval(vars Activation) ref.Val {
 // string, or uint.
 type evalWatchConstQual struct {
 	ConstantQualifier
-	observer EvalObserver
-	adapter  ref.TypeAdapter
+	observers watchers
+	adapter   ref.TypeAdapter
 }
 
 // Qualify observes the qualification of a object via a constant boolean, int, string, or uint.
@@ -879,7 +1290,9 @@ func (e *evalWatchConstQual) Qualify(vars Activation, obj any) (any, error) {
 	} else {
 		val = e.adapter.NativeToValue(out)
 	}
-	e.observer(e.ID(), e.ConstantQualifier, val)
+	if patched, isPatched := e.observers.observe(vars, e.ID(), e.ConstantQualifier, val); isPatched {
+		return patchedQualification(patched)
+	}
 	return out, err
 }
 
@@ -892,8 +1305,8 @@ func (e *evalWatchConstQual) QualifierValueEquals(value any) bool {
 // evalWatchQual observes the qualification of an object by a value computed at runtime.
 type evalWatchQual struct {
 	Qualifier
-	observer EvalObserver
-	adapter  ref.TypeAdapter
+	observers watchers
+	adapter   ref.TypeAdapter
 }
 
 // Qualify observes the qualification of a object via a value computed at runtime.
@@ -905,20 +1318,31 @@ func (e *evalWatchQual) Qualify(vars Activation, obj any) (any, error) {
 	} else {
 		val = e.adapter.NativeToValue(out)
 	}
-	e.observer(e.ID(), e.Qualifier, val)
+	if patched, isPatched := e.observers.observe(vars, e.ID(), e.Qualifier, val); isPatched {
+		return patchedQualification(patched)
+	}
 	return out, err
 }
 
+// patchedQualification returns the result of a qualification whose value was replaced by an
+// EvalPatcher, where an error value fails the qualification.
+func patchedQualification(val ref.Val) (any, error) {
+	if err, isErr := val.(*types.Err); isErr {
+		return nil, err
+	}
+	return val, nil
+}
+
 // evalWatchConst describes a watcher of an instConst Interpretable.
 type evalWatchConst struct {
 	InterpretableConst
-	observer EvalObserver
+	observers watchers
 }
 
 // Eval implements the Interpretable interface method.
 func (e *evalWatchConst) Eval(vars Activation) ref.Val {
 	val := e.Value()
-	e.observer(e.ID(), e.InterpretableConst, val)
+	val, _ = e.observers.observe(vars, e.ID(), e.InterpretableConst, val)
 	return val
 }
 
@@ -936,8 +1360,11 @@ func (or *evalExhaustiveOr) ID() int64 {
 
 // Eval implements the Interpretable interface method.
 func (or *evalExhaustiveOr) Eval(ctx Activation) ref.Val {
-	lVal := or.lhs.Eval(ctx)
-	rVal := or.rhs.Eval(ctx)
+	return or.result(or.lhs.Eval(ctx), or.rhs.Eval(ctx))
+}
+
+// result combines the operand values of the logical or.
+func (or *evalExhaustiveOr) result(lVal, rVal ref.Val) ref.Val {
 	lBool, lok := lVal.(types.Bool)
 	if lok && lBool == types.True {
 		return types.True
@@ -977,8 +1404,11 @@ func (and *evalExhaustiveAnd) ID() int64 {
 
 // Eval implements the Interpretable interface method.
 func (and *evalExhaustiveAnd) Eval(ctx Activation) ref.Val {
-	lVal := and.lhs.Eval(ctx)
-	rVal := and.rhs.Eval(ctx)
+	return and.result(and.lhs.Eval(ctx), and.rhs.Eval(ctx))
+}
+
+// result combines the operand values of the logical and.
+func (and *evalExhaustiveAnd) result(lVal, rVal ref.Val) ref.Val {
 	lBool, lok := lVal.(types.Bool)
 	if lok && lBool == types.False {
 		return types.False
@@ -1071,6 +1501,14 @@ func (a *evalAttr) Adapter() ref.TypeAdapter {
 func (a *evalAttr) Eval(ctx Activation) ref.Val {
 	v, err := a.attr.Resolve(ctx)
 	if err != nil {
+		// Attributes which cannot be resolved may be replayed from a previous evaluation.
+		if val, found := findReplayedValue(ctx, a.ID()); found {
+			return val
+		}
+		// Errors produced by patched qualifiers are returned as is to preserve their annotations.
+		if errVal, isErr := err.(*types.Err); isErr {
+			return errVal
+		}
 		return types.NewErr(err.Error())
 	}
 	return a.adapter.NativeToValue(v)
@@ -1097,7 +1535,7 @@ func (a *evalAttr) Resolve(ctx Activation) (any, error) {
 
 type evalWatchConstructor struct {
 	constructor InterpretableConstructor
-	observer    EvalObserver
+	observers   watchers
 }
 
 // InitVals implements the InterpretableConstructor InitVals function.
@@ -1118,7 +1556,7 @@ func (c *evalWatchConstructor) ID() int64 {
 // Eval implements the Interpretable Eval function.
 func (c *evalWatchConstructor) Eval(ctx Activation) ref.Val {
 	val := c.constructor.Eval(ctx)
-	c.observer(c.ID(), c.constructor, val)
+	val, _ = c.observers.observe(ctx, c.ID(), c.constructor, val)
 	return val
 }
 
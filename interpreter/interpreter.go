@@ -44,15 +44,41 @@ type EvalObserver func(id int64, programStep any, value ref.Val)
 // Observe constructs a decorator that calls all the provided observers in order after evaluating each Interpretable
 // or Qualifier during program evaluation.
 func Observe(observers ...EvalObserver) InterpretableDecorator {
-	if len(observers) == 1 {
-		return decObserveEval(observers[0])
-	}
-	observeFn := func(id int64, programStep any, val ref.Val) {
+	return decObserveEval(func(id int64, programStep any, val ref.Val) (ref.Val, bool) {
 		for _, observer := range observers {
 			observer(id, programStep, val)
 		}
-	}
-	return decObserveEval(observeFn)
+		return val, false
+	})
+}
+
+// EvalPatcher is an observer which may substitute the value produced by an evaluation step,
+// returning the replacement value and true, or false to retain the observed value.
+//
+// The id identifies the expression that was evaluated, the programStep is the Interpretable or
+// Qualifier that was evaluated and value is the result of the evaluation. Substituting an error
+// value for the result of a Qualifier fails the qualification with the error.
+type EvalPatcher func(id int64, programStep any, value ref.Val) (ref.Val, bool)
+
+// Patch constructs a decorator that calls the provided patchers in order after evaluating each
+// Interpretable or Qualifier during program evaluation, making it possible to replay recorded
+// values or to inject faults without modifying the inputs of an evaluation.
+//
+// Each patcher observes the value produced by the preceding patchers. Observers added by a
+// subsequent Observe decorator observe the patched values. The decorator should be applied after
+// decorators such as Optimize which inspect the type of the Interpretable they decorate.
+//
+// The patchers are not called by evaluations in warm-up mode, see NewWarmupActivation.
+func Patch(patchers ...EvalPatcher) InterpretableDecorator {
+	return decPatchEval(func(id int64, programStep any, val ref.Val) (ref.Val, bool) {
+		patched := false
+		for _, patcher := range patchers {
+			if replacement, isPatched := patcher(id, programStep, val); isPatched {
+				val, patched = replacement, true
+			}
+		}
+		return val, patched
+	})
 }
 
 // EvalCancelledError represents a cancelled program evaluation operation.
@@ -76,6 +102,18 @@ const (
 	// CostLimitExceeded indicates that the operation was cancelled in response to the actual cost limit being
 	// exceeded.
 	CostLimitExceeded
+
+	// MemoryLimitExceeded indicates that the operation was cancelled in response to the approximate
+	// memory allocated for values constructed during evaluation exceeding the memory limit.
+	MemoryLimitExceeded
+
+	// CostBudgetExceeded indicates that the operation was cancelled in response to the exhaustion
+	// of a CostBudget shared with other evaluations.
+	CostBudgetExceeded
+
+	// EvalSuspended indicates that the operation was suspended at the request of the suspend
+	// function of a checkpoint activation, and may be resumed from its Checkpoint.
+	EvalSuspended
 )
 
 // TODO: Replace all usages of TrackState with EvalStateObserver
@@ -120,6 +158,72 @@ func InterruptableEval() InterpretableDecorator {
 	return decInterruptFolds()
 }
 
+// NewCancellable returns a CancellableInterpretable which evaluates the Interpretable under a
+// context.Context.
+//
+// Comprehensions observe the cancellation of the context after each iteration when the
+// Interpretable is planned with the InterruptableEval decorator. Otherwise, the context is only
+// checked before the evaluation begins.
+func NewCancellable(i Interpretable) CancellableInterpretable {
+	if c, isCancellable := i.(CancellableInterpretable); isCancellable {
+		return c
+	}
+	return &evalCancellable{Interpretable: i}
+}
+
+// SortedMapIteration makes comprehensions over maps visit the keys in the order of
+// types.SortedMapKeys rather than in the unspecified order of the map, so that the results of
+// order-dependent comprehensions such as `m.map(k, k)` are reproducible.
+func SortedMapIteration() InterpretableDecorator {
+	return decSortMapFolds()
+}
+
+// CheckpointFolds makes the comprehensions with the given expression ids record their progress
+// into the Checkpoint of the activation, and resume from it, when evaluated against an activation
+// created by NewCheckpointActivation. Against any other activation the comprehensions are
+// evaluated as usual.
+//
+// The comprehensions must not be nested within other comprehensions, since the progress of a
+// comprehension is recorded once per evaluation. Maps are iterated in sorted order so that a
+// resumed comprehension visits the remaining entries of the map.
+func CheckpointFolds(ids ...int64) InterpretableDecorator {
+	return decCheckpointFolds(ids)
+}
+
+// ElementSink receives the elements of the list built by a streaming comprehension as they are
+// computed, and returns false to stop the comprehension from computing further elements.
+type ElementSink func(elem ref.Val) bool
+
+// StreamFold makes the comprehension with the given expression id, which must build a list such as
+// those generated by the map() and filter() macros, deliver the elements it appends to the
+// ElementSink of the activation when evaluated against an activation created by
+// NewStreamActivation. The elements are delivered as each iteration completes rather than being
+// accumulated into a list, and the comprehension evaluates to the number of elements delivered.
+//
+// Against any other activation the comprehension builds its list as usual.
+func StreamFold(id int64) InterpretableDecorator {
+	return decStreamFold(id)
+}
+
+// NewStreamActivation returns an Activation which supplies the sink for the elements of the
+// comprehensions planned with the StreamFold decorator.
+func NewStreamActivation(parent Activation, sink ElementSink) Activation {
+	return &streamActivation{parent: parent, sink: sink}
+}
+
+// FoldObserver is notified of the number of iterations evaluated by a comprehension each time it
+// completes, and of whether its loop condition terminated it before the end of its range, e.g. when
+// the predicate of an all() macro is false for an element.
+//
+// The observer may be invoked concurrently when the program is evaluated concurrently.
+type FoldObserver func(id int64, iterations int, terminatedEarly bool)
+
+// ObserveFolds reports the iterations of each comprehension to the observer, so that the benefit
+// of short-circuiting comprehensions over large ranges may be measured.
+func ObserveFolds(observer FoldObserver) InterpretableDecorator {
+	return decObserveFolds(observer)
+}
+
 // Optimize will pre-compute operations such as list and map construction and optimize
 // call arguments to set membership tests. The set of optimizations will increase over time.
 func Optimize() InterpretableDecorator {
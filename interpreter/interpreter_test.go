@@ -31,6 +31,7 @@ import (
 	"github.com/google/cel-go/checker/decls"
 	"github.com/google/cel-go/common"
 	"github.com/google/cel-go/common/containers"
+	"github.com/google/cel-go/common/operators"
 	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
 	"github.com/google/cel-go/common/types/traits"
@@ -533,6 +534,16 @@ var (
 			name: "index_cross_type_uint",
 			expr: `{1: 'hello', 2: 'world'}[dyn(2u)] == 'world'`,
 		},
+		{
+			name: "index_bytes_key",
+			expr: `{b'\x01': 'hello', b'\x02': 'world'}[x] == 'world' && b'\x01' in {x: 1, b'\x01': 2}`,
+			env: []*exprpb.Decl{
+				decls.NewVar("x", decls.Bytes),
+			},
+			in: map[string]any{
+				"x": []byte{2},
+			},
+		},
 		{
 			name: "index_cross_type_bad_qualifier",
 			expr: `{1: 'hello', 2: 'world'}[x] == 'world'`,
@@ -1400,6 +1411,36 @@ var (
 			expr: `1/0 in [1, 2, 3]`,
 			err:  `division by zero`,
 		},
+		{
+			name: "concat_string_chain",
+			expr: `a + '-' + b + '-' + (a + b) == 'x-yz-xyz'`,
+			env: []*exprpb.Decl{
+				decls.NewVar("a", decls.String),
+				decls.NewVar("b", decls.String),
+			},
+			in: map[string]any{"a": "x", "b": "yz"},
+		},
+		{
+			name: "concat_list_chain",
+			expr: `l + [3] + l + [] + [4, 5]`,
+			env:  []*exprpb.Decl{decls.NewVar("l", decls.NewListType(decls.Int))},
+			in:   map[string]any{"l": []int{1, 2}},
+			out:  []int64{1, 2, 3, 1, 2, 4, 5},
+		},
+		{
+			name: "concat_chain_error",
+			expr: `'a' + string(1/x) + 'b' + string(x/0)`,
+			env:  []*exprpb.Decl{decls.NewVar("x", decls.Int)},
+			in:   map[string]any{"x": 0},
+			err:  "division by zero",
+		},
+		{
+			name: "concat_chain_dyn_mismatch",
+			expr: `'a' + dyn(s) + 'b'`,
+			env:  []*exprpb.Decl{decls.NewVar("s", decls.Dyn)},
+			in:   map[string]any{"s": 1},
+			err:  "no such overload",
+		},
 	}
 )
 
@@ -1610,6 +1651,65 @@ func TestInterpreter_ExhaustiveConditionalExpr(t *testing.T) {
 	}
 }
 
+func TestInterpreter_PatchEval(t *testing.T) {
+	src := common.NewTextSource(`a.b == 'hello' && c`)
+	parsed, errors := parser.Parse(src)
+	if len(errors.GetErrors()) != 0 {
+		t.Fatalf(errors.ToDisplayString())
+	}
+	cont := containers.DefaultContainer
+	reg := newTestRegistry(t)
+	attrs := NewAttributeFactory(cont, reg, reg)
+	intr := NewStandardInterpreter(cont, reg, reg, attrs)
+	vars, _ := NewActivation(map[string]any{
+		"a": map[string]string{"b": "world"},
+		"c": true,
+	})
+
+	// Substitute the value of the select expression at id 2.
+	state := NewEvalState()
+	patcher := func(id int64, programStep any, val ref.Val) (ref.Val, bool) {
+		if id == 2 {
+			return types.String("hello"), true
+		}
+		return val, false
+	}
+	i, err := intr.NewUncheckedInterpretable(parsed.GetExpr(),
+		Patch(patcher), Observe(EvalStateObserver(state)))
+	if err != nil {
+		t.Fatalf("NewUncheckedInterpretable() failed: %v", err)
+	}
+	if out := i.Eval(vars); out != types.True {
+		t.Errorf("Eval() got %v, wanted true", out)
+	}
+	if val, found := state.Value(3); !found || val != types.True {
+		t.Errorf("state.Value(3) got %v, wanted true", val)
+	}
+
+	// Inject an error in place of the value of the variable at id 5.
+	injected := types.NewErr("injected fault")
+	i, err = intr.NewUncheckedInterpretable(parsed.GetExpr(),
+		Patch(func(id int64, programStep any, val ref.Val) (ref.Val, bool) {
+			if id == 5 {
+				return injected, true
+			}
+			return val, false
+		}))
+	if err != nil {
+		t.Fatalf("NewUncheckedInterpretable() failed: %v", err)
+	}
+	if out := i.Eval(vars); out != types.False {
+		t.Errorf("Eval() got %v, wanted false", out)
+	}
+	vars, _ = NewActivation(map[string]any{
+		"a": map[string]string{"b": "hello"},
+		"c": true,
+	})
+	if out := i.Eval(vars); out != injected {
+		t.Errorf("Eval() got %v, wanted %v", out, injected)
+	}
+}
+
 func TestInterpreter_InterruptableEval(t *testing.T) {
 	items := make([]int64, 5000)
 	for i := int64(0); i < 5000; i++ {
@@ -1665,6 +1765,69 @@ func (ca *contextActivation) ResolveName(name string) (any, bool) {
 	return ca.Activation.ResolveName(name)
 }
 
+func TestInterpreter_EvalContext(t *testing.T) {
+	items := make([]int64, 5000)
+	for i := int64(0); i < 5000; i++ {
+		items[i] = i
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	tc := testCase{
+		expr: `items.map(i, cancelAt(i)).size() != 0`,
+		env: []*exprpb.Decl{
+			decls.NewVar("items", decls.NewListType(decls.Int)),
+			decls.NewFunction("cancelAt",
+				decls.NewOverload("cancelAt_int", []*exprpb.Type{decls.Int}, decls.Int)),
+		},
+		funcs: []*functions.Overload{
+			{
+				Operator: "cancelAt_int",
+				Unary: func(arg ref.Val) ref.Val {
+					calls++
+					if arg == types.Int(10) {
+						cancel()
+					}
+					return arg
+				},
+			},
+		},
+		in: map[string]any{
+			"items": items,
+		},
+	}
+	prg, vars, err := program(t, &tc, InterruptableEval())
+	if err != nil {
+		t.Fatalf("program(%s) failed: %v", tc.expr, err)
+	}
+	out := NewCancellable(prg).EvalContext(ctx, vars)
+	if !types.IsError(out) || out.(*types.Err).String() != "operation interrupted: context canceled" {
+		t.Errorf("EvalContext() got %v, wanted operation interrupted error", out)
+	}
+	if calls != 11 {
+		t.Errorf("EvalContext() evaluated %d iterations, wanted the comprehension to stop after 11", calls)
+	}
+
+	// A context which is done interrupts the evaluation before it begins.
+	calls = 0
+	out = NewCancellable(prg).EvalContext(ctx, vars)
+	if !types.IsError(out) || calls != 0 {
+		t.Errorf("EvalContext() got %v after %d calls, wanted an error before the evaluation", out, calls)
+	}
+
+	// The context of the evaluation is exposed to functions via the activation.
+	ctxVars := &cancellableActivation{parent: vars, ctx: ctx}
+	if EvalContext(ctxVars) != ctx {
+		t.Error("EvalContext() did not return the context of the evaluation")
+	}
+
+	// Evaluations which complete before the context is done are unaffected.
+	out = NewCancellable(prg).EvalContext(context.Background(), vars)
+	if out != types.True {
+		t.Errorf("EvalContext() got %v, wanted true", out)
+	}
+}
+
 func TestInterpreter_ExhaustiveLogicalOrEquals(t *testing.T) {
 	// a || b == "b"
 	// Operator "==" is at Expr 4, should be evaluated though "a" is true
@@ -1870,6 +2033,111 @@ func TestInterpreter_TypeConversionOpt(t *testing.T) {
 	}
 }
 
+func TestInterpreter_ConcatFlattening(t *testing.T) {
+	tests := []struct {
+		expr string
+		args int
+	}{
+		{expr: `s + s + s + s`, args: 4},
+		{expr: `s + (s + s) + 'x'`, args: 4},
+		{expr: `[s] + [s] + [s]`, args: 3},
+		{expr: `size(s + s) + size(s + s)`, args: 0},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			prg, _, err := program(t, &testCase{
+				expr: tc.expr,
+				env:  []*exprpb.Decl{decls.NewVar("s", decls.String)},
+			}, Optimize())
+			if err != nil {
+				t.Fatal(err)
+			}
+			concat, isConcat := prg.(*evalConcat)
+			if tc.args == 0 {
+				if isConcat {
+					t.Errorf("program(%q) produced a flattened concatenation", tc.expr)
+				}
+				return
+			}
+			if !isConcat || len(concat.Args()) != tc.args {
+				t.Errorf("program(%q) got %#v, wanted a concatenation of %d arguments", tc.expr, prg, tc.args)
+			}
+		})
+	}
+}
+
+func TestInterpreter_JoinFold(t *testing.T) {
+	// concatAll accumulates a string from the elements of a list, e.g. list.concatAll(x, x + ',').
+	concatAll := parser.NewReceiverMacro("concatAll", 2,
+		func(eh parser.ExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+			v := args[0].GetIdentExpr().GetName()
+			step := eh.GlobalCall(operators.Add, eh.Ident(parser.AccumulatorName), args[1])
+			return eh.Fold(v, target, parser.AccumulatorName, eh.LiteralString(""), eh.LiteralBool(true),
+				step, eh.Ident(parser.AccumulatorName)), nil
+		})
+	tests := []struct {
+		expr   string
+		out    ref.Val
+		joined bool
+	}{
+		{expr: `['a', 'b', 'c'].concatAll(x, x)`, out: types.String("abc"), joined: true},
+		{expr: `['a', 'b', 'c'].concatAll(x, x + ',')`, out: types.String("a,b,c,"), joined: true},
+		{
+			expr:   `['a', 'b', 'c'].concatAll(x, (size(__result__) > 0 ? ',' : '') + x)`,
+			out:    types.String("a,b,c"),
+			joined: true,
+		},
+		{expr: `{'b': 1, 'a': 2}.concatAll(k, k).size()`, out: types.Int(2), joined: true},
+		{expr: `[1, 0].concatAll(x, string(1 / x))`, out: types.NewErr("division by zero"), joined: true},
+		{expr: `['a', 'b'].exists(x, x == 'b')`, out: types.True},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			src := common.NewTextSource(tc.expr)
+			p, err := parser.NewParser(parser.Macros(append(parser.AllMacros, concatAll)...))
+			if err != nil {
+				t.Fatalf("parser.NewParser() failed: %v", err)
+			}
+			parsed, errs := p.Parse(src)
+			if len(errs.GetErrors()) != 0 {
+				t.Fatalf(errs.ToDisplayString())
+			}
+			cont := containers.DefaultContainer
+			reg := newTestRegistry(t)
+			checked, errs := checker.Check(parsed, src, newTestEnv(t, cont, reg))
+			if len(errs.GetErrors()) != 0 {
+				t.Fatalf(errs.ToDisplayString())
+			}
+			disp := NewDispatcher()
+			disp.Add(functions.StandardOverloads()...)
+			interp := NewInterpreter(disp, cont, reg, reg, NewAttributeFactory(cont, reg, reg))
+			for _, opts := range [][]InterpretableDecorator{{}, {Optimize()}, {Optimize(), ExhaustiveEval()}} {
+				i, err := interp.NewInterpretable(checked, opts...)
+				if err != nil {
+					t.Fatalf("NewInterpretable() failed: %v", err)
+				}
+				out := i.Eval(EmptyActivation())
+				if out.Equal(tc.out) != types.True && !(types.IsError(out) && types.IsError(tc.out) &&
+					out.(*types.Err).Error() == tc.out.(*types.Err).Error()) {
+					t.Errorf("Eval() with %d decorators got %v, wanted %v", len(opts), out, tc.out)
+				}
+			}
+			i, err := interp.NewInterpretable(checked, Optimize())
+			if err != nil {
+				t.Fatalf("NewInterpretable() failed: %v", err)
+			}
+			if call, isCall := i.(InterpretableCall); isCall {
+				i = call.Args()[0]
+			}
+			if _, joined := i.(*evalJoinFold); joined != tc.joined {
+				t.Errorf("NewInterpretable() got %T, wanted join fold %t", i, tc.joined)
+			}
+		})
+	}
+}
+
 func TestInterpreter_PlanOptionalElements(t *testing.T) {
 	// [?a] manipulated so the optional index is negative.
 	badOptionalA := &exprpb.Expr{
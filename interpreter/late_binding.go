@@ -0,0 +1,148 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter/functions"
+)
+
+// LateBindCalls returns an InterpretableDecorator which allows the implementation of calls to the
+// given overload ids to be replaced at evaluation time by an Activation created with
+// NewFunctionBindingActivation.
+//
+// When no replacement is provided by the activation, the call is evaluated using the
+// implementation resolved at planning time.
+func LateBindCalls(overloadIDs ...string) InterpretableDecorator {
+	ids := make(map[string]struct{}, len(overloadIDs))
+	for _, id := range overloadIDs {
+		ids[id] = struct{}{}
+	}
+	return func(i Interpretable) (Interpretable, error) {
+		call, ok := i.(InterpretableCall)
+		if !ok {
+			return i, nil
+		}
+		if _, found := ids[call.OverloadID()]; !found {
+			return i, nil
+		}
+		return &evalLateBoundCall{InterpretableCall: call}, nil
+	}
+}
+
+// NewFunctionBindingActivation returns an Activation which supplies the implementations of late
+// bound calls, indexed by their overload id, in addition to the variables of the input `vars`.
+//
+// Function bindings within an activation take precedence over the bindings of its parents.
+func NewFunctionBindingActivation(vars Activation, bindings ...*functions.Overload) Activation {
+	overloads := make(map[string]*functions.Overload, len(bindings))
+	for _, b := range bindings {
+		overloads[b.Operator] = b
+	}
+	return &functionBindingActivation{
+		Activation: vars,
+		overloads:  overloads,
+	}
+}
+
+type functionBindingActivation struct {
+	Activation
+	overloads map[string]*functions.Overload
+}
+
+// Parent implements the Activation interface method.
+//
+// The wrapped activation is returned as the parent so that the wrapped activation is considered
+// when searching for bindings among an activation's parents.
+func (a *functionBindingActivation) Parent() Activation {
+	return a.Activation
+}
+
+// findFunctionBinding returns the nearest function binding for the overload id within the chain of
+// activations.
+func findFunctionBinding(vars Activation, overload string) (*functions.Overload, bool) {
+	for vars != nil {
+		switch v := vars.(type) {
+		case *functionBindingActivation:
+			if impl, found := v.overloads[overload]; found {
+				return impl, true
+			}
+		case *hierarchicalActivation:
+			// The child of a hierarchical activation is not among its parents.
+			if impl, found := findFunctionBinding(v.child, overload); found {
+				return impl, true
+			}
+		}
+		vars = vars.Parent()
+	}
+	return nil, false
+}
+
+// evalLateBoundCall evaluates a call using the implementation supplied by the activation, if any.
+type evalLateBoundCall struct {
+	InterpretableCall
+}
+
+// Eval implements the Interpretable interface method.
+func (call *evalLateBoundCall) Eval(ctx Activation) ref.Val {
+	impl, found := findFunctionBinding(ctx, call.OverloadID())
+	if !found {
+		return call.InterpretableCall.Eval(ctx)
+	}
+	return invokeOverload(ctx, call, impl)
+}
+
+// invokeOverload evaluates the arguments of the call and applies the overload implementation to
+// them, honoring the strictness and operand trait of the implementation.
+func invokeOverload(ctx Activation, call InterpretableCall, impl *functions.Overload) ref.Val {
+	argVals, errVal := evalCallArgs(ctx, call, impl)
+	if errVal != nil {
+		return errVal
+	}
+	return applyOverload(ctx, call, impl, argVals)
+}
+
+// evalCallArgs evaluates the arguments of the call, returning the first error or unknown argument
+// value when the implementation is strict.
+func evalCallArgs(ctx Activation, call InterpretableCall, impl *functions.Overload) ([]ref.Val, ref.Val) {
+	args := call.Args()
+	argVals := make([]ref.Val, len(args))
+	for i, arg := range args {
+		argVals[i] = arg.Eval(ctx)
+		if !impl.NonStrict && types.IsUnknownOrError(argVals[i]) {
+			return nil, argVals[i]
+		}
+	}
+	return argVals, nil
+}
+
+// applyOverload applies the overload implementation to the argument values.
+func applyOverload(ctx Activation, call InterpretableCall, impl *functions.Overload, argVals []ref.Val) ref.Val {
+	if impl.OperandTrait != 0 && len(argVals) > 0 && !argVals[0].Type().HasTrait(impl.OperandTrait) {
+		return types.NewErr("no such overload: %s", call.Function())
+	}
+	switch {
+	case impl.ContextFunction != nil:
+		return impl.ContextFunction(EvalContext(ctx), argVals...)
+	case len(argVals) == 1 && impl.Unary != nil:
+		return impl.Unary(argVals[0])
+	case len(argVals) == 2 && impl.Binary != nil:
+		return impl.Binary(argVals[0], argVals[1])
+	case impl.Function != nil:
+		return impl.Function(argVals...)
+	}
+	return types.NewErr("no such overload: %s", call.Function())
+}
@@ -0,0 +1,296 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/interpreter/functions"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// CallMemo stores the results of calls keyed by the overload id and argument values of the call.
+//
+// A CallMemo shared between evaluations must be safe for concurrent use.
+type CallMemo interface {
+	// Get returns the memoized result of the call key, if present.
+	Get(key string) (ref.Val, bool)
+
+	// Put memoizes the result of the call key.
+	Put(key string, val ref.Val)
+}
+
+// MemoizeCalls returns an InterpretableDecorator which memoizes the results of calls to the given
+// overload ids or function names, which must identify pure functions.
+//
+// When the memo is nil, results are memoized per evaluation within an Activation created by
+// NewCallMemoActivation, and calls evaluated outside of such an activation are not memoized.
+// Calls with error or unknown arguments, calls which produce unknown results, and calls evaluated
+// in warm-up mode are never memoized.
+func MemoizeCalls(disp Dispatcher, memo CallMemo, overloadIDs ...string) InterpretableDecorator {
+	ids := make(map[string]bool, len(overloadIDs))
+	for _, id := range overloadIDs {
+		ids[id] = true
+	}
+	return func(i Interpretable) (Interpretable, error) {
+		call, ok := i.(InterpretableCall)
+		if !ok {
+			return i, nil
+		}
+		key := call.OverloadID()
+		if !ids[key] {
+			key = call.Function()
+		}
+		if key == "" || !ids[key] {
+			return i, nil
+		}
+		impl, found := disp.FindOverload(call.OverloadID())
+		if !found {
+			impl, found = disp.FindOverload(call.Function())
+		}
+		if !found {
+			return i, nil
+		}
+		return &evalMemoizedCall{InterpretableCall: call, impl: impl, key: key, memo: memo}, nil
+	}
+}
+
+// NewCallMemoActivation returns an Activation which holds the results of the calls memoized by
+// the MemoizeCalls decorator during a single evaluation against the input `vars`.
+//
+// When `vars` is already a call memo activation it is returned as-is.
+func NewCallMemoActivation(vars Activation) Activation {
+	if memo, isMemo := vars.(*callMemoActivation); isMemo {
+		return memo
+	}
+	return &callMemoActivation{
+		Activation: vars,
+		results:    map[string]ref.Val{},
+	}
+}
+
+// callMemoActivation exposes itself as the special `#memo` variable so that it may be found from
+// within nested activations such as those of comprehensions.
+type callMemoActivation struct {
+	Activation
+	mu      sync.Mutex
+	results map[string]ref.Val
+}
+
+// ResolveName implements the Activation interface method.
+func (a *callMemoActivation) ResolveName(name string) (any, bool) {
+	if name == "#memo" {
+		return a, true
+	}
+	return a.Activation.ResolveName(name)
+}
+
+// Parent implements the Activation interface method.
+func (a *callMemoActivation) Parent() Activation {
+	return a.Activation
+}
+
+// Get implements the CallMemo interface method.
+func (a *callMemoActivation) Get(key string) (ref.Val, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	val, found := a.results[key]
+	return val, found
+}
+
+// Put implements the CallMemo interface method.
+func (a *callMemoActivation) Put(key string, val ref.Val) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.results[key] = val
+}
+
+// evalMemoizedCall returns the memoized result of a call when one is present.
+type evalMemoizedCall struct {
+	InterpretableCall
+	impl *functions.Overload
+	key  string
+	memo CallMemo
+}
+
+// Eval implements the Interpretable interface method.
+func (call *evalMemoizedCall) Eval(ctx Activation) ref.Val {
+	argVals, errVal := evalCallArgs(ctx, call, resolveCallImpl(ctx, call, call.impl))
+	if errVal != nil {
+		return errVal
+	}
+	return call.applyArgs(ctx, argVals)
+}
+
+// applyArgs implements the callApplier interface method.
+func (call *evalMemoizedCall) applyArgs(ctx Activation, argVals []ref.Val) ref.Val {
+	memo := call.findMemo(ctx)
+	if memo == nil {
+		return applyCall(ctx, call.InterpretableCall, call.impl, argVals)
+	}
+	for _, arg := range argVals {
+		if types.IsUnknownOrError(arg) {
+			return applyCall(ctx, call.InterpretableCall, call.impl, argVals)
+		}
+	}
+	key, encoded := CallKey(call.key, argVals)
+	if !encoded {
+		return applyCall(ctx, call.InterpretableCall, call.impl, argVals)
+	}
+	if val, found := memo.Get(key); found {
+		return val
+	}
+	val := applyCall(ctx, call.InterpretableCall, call.impl, argVals)
+	if !types.IsUnknown(val) {
+		memo.Put(key, val)
+	}
+	return val
+}
+
+func (call *evalMemoizedCall) findMemo(ctx Activation) CallMemo {
+	if ctx != nil && isWarmup(ctx) {
+		return nil
+	}
+	if call.memo != nil {
+		return call.memo
+	}
+	if ctx == nil {
+		return nil
+	}
+	if val, found := ctx.ResolveName("#memo"); found {
+		if memo, isMemo := val.(CallMemo); isMemo {
+			return memo
+		}
+	}
+	return nil
+}
+
+// CallKey identifies a call by its overload id or function name and its argument values, such
+// that calls with distinct arguments have distinct keys. The boolean result is false when an
+// argument has a type which cannot be encoded, in which case the call cannot be identified by key.
+func CallKey(id string, argVals []ref.Val) (string, bool) {
+	var sb strings.Builder
+	writeKeyString(&sb, id)
+	for _, arg := range argVals {
+		if !writeKeyVal(&sb, arg) {
+			return "", false
+		}
+	}
+	return sb.String(), true
+}
+
+// writeKeyString writes a length-prefixed string so that adjacent strings cannot collide.
+func writeKeyString(sb *strings.Builder, s string) {
+	sb.WriteString(strconv.Itoa(len(s)))
+	sb.WriteByte(':')
+	sb.WriteString(s)
+}
+
+// writeKeyVal writes a value tagged with its type, encoding the elements of aggregate values
+// recursively.
+func writeKeyVal(sb *strings.Builder, val ref.Val) bool {
+	switch v := val.(type) {
+	case types.Bool:
+		sb.WriteByte('b')
+		writeKeyString(sb, strconv.FormatBool(bool(v)))
+	case types.Bytes:
+		sb.WriteByte('y')
+		writeKeyString(sb, string(v))
+	case types.Double:
+		sb.WriteByte('d')
+		writeKeyString(sb, strconv.FormatUint(math.Float64bits(float64(v)), 16))
+	case types.Duration:
+		sb.WriteByte('D')
+		writeKeyString(sb, strconv.FormatInt(int64(v.Duration), 10))
+	case types.Int:
+		sb.WriteByte('i')
+		writeKeyString(sb, strconv.FormatInt(int64(v), 10))
+	case types.Null:
+		sb.WriteByte('n')
+	case types.String:
+		sb.WriteByte('s')
+		writeKeyString(sb, string(v))
+	case types.Timestamp:
+		sb.WriteByte('t')
+		writeKeyString(sb, v.Time.Format(time.RFC3339Nano))
+	case types.Uint:
+		sb.WriteByte('u')
+		writeKeyString(sb, strconv.FormatUint(uint64(v), 10))
+	case *types.TypeValue:
+		sb.WriteByte('T')
+		writeKeyString(sb, v.TypeName())
+	case *types.Optional:
+		if !v.HasValue() {
+			sb.WriteString("o0")
+			return true
+		}
+		sb.WriteString("o1")
+		return writeKeyVal(sb, v.GetValue())
+	case traits.Mapper:
+		// Encode the entries independently so that they may be sorted, as map iteration order is
+		// not deterministic.
+		var entries []string
+		it := v.Iterator()
+		for it.HasNext() == types.True {
+			var entry strings.Builder
+			key := it.Next()
+			if !writeKeyVal(&entry, key) || !writeKeyVal(&entry, v.Get(key)) {
+				return false
+			}
+			entries = append(entries, entry.String())
+		}
+		sort.Strings(entries)
+		sb.WriteByte('m')
+		writeKeyString(sb, strconv.Itoa(len(entries)))
+		for _, entry := range entries {
+			sb.WriteString(entry)
+		}
+	case traits.Lister:
+		size, isInt := v.Size().(types.Int)
+		if !isInt {
+			return false
+		}
+		sb.WriteByte('l')
+		writeKeyString(sb, strconv.FormatInt(int64(size), 10))
+		it := v.Iterator()
+		for it.HasNext() == types.True {
+			if !writeKeyVal(sb, it.Next()) {
+				return false
+			}
+		}
+	default:
+		msg, isMsg := val.Value().(proto.Message)
+		if !isMsg {
+			return false
+		}
+		bytes, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+		if err != nil {
+			return false
+		}
+		sb.WriteByte('p')
+		writeKeyString(sb, val.Type().TypeName())
+		writeKeyString(sb, string(bytes))
+	}
+	return true
+}
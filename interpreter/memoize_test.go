@@ -0,0 +1,63 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+func TestCallKey(t *testing.T) {
+	reg := newTestRegistry(t)
+	distinct := [][]ref.Val{
+		{reg.NativeToValue([]string{"a b"})},
+		{reg.NativeToValue([]string{"a", "b"})},
+		{reg.NativeToValue([]any{[]string{"a"}, "b"})},
+		{reg.NativeToValue([]any{"a", []string{"b"}})},
+		{types.String("a"), types.String("b")},
+		{types.String("a|s:1:b")},
+		{types.Int(1)},
+		{types.Uint(1)},
+		{types.Double(1)},
+		{types.String("1")},
+		{types.Bytes("1")},
+	}
+	keys := map[string]int{}
+	for i, args := range distinct {
+		key, encoded := CallKey("f", args)
+		if !encoded {
+			t.Fatalf("CallKey(%v) could not be encoded", args)
+		}
+		if j, found := keys[key]; found {
+			t.Errorf("CallKey(%v) collides with CallKey(%v): %q", args, distinct[j], key)
+		}
+		keys[key] = i
+	}
+
+	// Maps are encoded independently of their iteration order.
+	m1 := reg.NativeToValue(map[string]int{"a": 1, "b": 2, "c": 3})
+	m2 := reg.NativeToValue(map[string]int{"c": 3, "b": 2, "a": 1})
+	k1, _ := CallKey("f", []ref.Val{m1})
+	k2, _ := CallKey("f", []ref.Val{m2})
+	if k1 != k2 {
+		t.Errorf("CallKey() of equal maps got %q and %q", k1, k2)
+	}
+
+	if _, encoded := CallKey("f", []ref.Val{types.NewErr("error")}); encoded {
+		t.Error("CallKey() encoded an error value")
+	}
+}
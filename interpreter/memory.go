@@ -0,0 +1,92 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"github.com/google/cel-go/common/overloads"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// listElementBytes approximates the memory required to store a value reference within a list.
+	listElementBytes = 16
+
+	// mapEntryBytes approximates the memory required to store a key and value reference within a map.
+	mapEntryBytes = 48
+)
+
+// MemoryObserver provides an observer that tracks the approximate memory allocated for the strings,
+// bytes, lists, maps, and objects constructed during evaluation.
+//
+// Values provided as inputs to the evaluation, such as variables and constants, are not counted.
+func MemoryObserver(tracker *MemoryTracker) EvalObserver {
+	return func(id int64, programStep any, val ref.Val) {
+		switch t := programStep.(type) {
+		case InterpretableConstructor:
+			tracker.allocated += approxAllocatedBytes(val)
+		case InterpretableCall:
+			// List concatenation produces a view over its operands rather than a copy.
+			if t.OverloadID() == overloads.AddList {
+				return
+			}
+			tracker.allocated += approxAllocatedBytes(val)
+		default:
+			return
+		}
+		if tracker.Limit != nil && tracker.allocated > *tracker.Limit {
+			panic(EvalCancelledError{Cause: MemoryLimitExceeded, Message: "operation cancelled: memory limit exceeded"})
+		}
+	}
+}
+
+// MemoryTracker records the approximate number of bytes allocated for values constructed during
+// evaluation.
+type MemoryTracker struct {
+	Limit *uint64
+
+	allocated uint64
+}
+
+// ActualMemory returns the approximate number of bytes allocated during evaluation.
+func (m *MemoryTracker) ActualMemory() uint64 {
+	return m.allocated
+}
+
+// approxAllocatedBytes estimates the memory held by the value, excluding the memory held by the
+// elements of lists and maps which are accounted for when the elements are constructed.
+func approxAllocatedBytes(val ref.Val) uint64 {
+	switch v := val.(type) {
+	case types.String:
+		return uint64(len(v))
+	case types.Bytes:
+		return uint64(len(v))
+	case traits.Lister:
+		if size, ok := v.Size().(types.Int); ok {
+			return uint64(size) * listElementBytes
+		}
+	case traits.Mapper:
+		if size, ok := v.Size().(types.Int); ok {
+			return uint64(size) * mapEntryBytes
+		}
+	}
+	if msg, ok := val.Value().(proto.Message); ok {
+		return uint64(proto.Size(msg))
+	}
+	return 0
+}
@@ -0,0 +1,235 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter/functions"
+)
+
+// OverflowPolicy determines the result of int and uint arithmetic which overflows the range of
+// the type.
+type OverflowPolicy int
+
+const (
+	// OverflowError produces an overflow error, as required by the CEL specification.
+	OverflowError OverflowPolicy = iota
+
+	// OverflowWrap wraps the result around the range of the type, as in two's complement
+	// arithmetic.
+	OverflowWrap
+
+	// OverflowSaturate clamps the result to the minimum or maximum value of the type.
+	OverflowSaturate
+)
+
+// String returns the name of the policy.
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowError:
+		return "error"
+	case OverflowWrap:
+		return "wrap"
+	case OverflowSaturate:
+		return "saturate"
+	}
+	return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+}
+
+// ApplyOverflowPolicy returns an InterpretableDecorator which applies the policy to the results
+// of the int and uint arithmetic operators `+`, `-`, `*`, `/`, `%`, and unary `-`.
+//
+// The implementations of the operators are resolved from the dispatcher and invoked as usual for
+// operands of other types and for arithmetic which does not overflow. Division and modulus by
+// zero remain errors under every policy.
+func ApplyOverflowPolicy(disp Dispatcher, policy OverflowPolicy) InterpretableDecorator {
+	return func(i Interpretable) (Interpretable, error) {
+		if policy == OverflowError {
+			return i, nil
+		}
+		call, ok := i.(InterpretableCall)
+		if !ok {
+			return i, nil
+		}
+		switch call.Function() {
+		case operators.Add, operators.Subtract, operators.Multiply, operators.Divide,
+			operators.Modulo, operators.Negate:
+		default:
+			return i, nil
+		}
+		impl, found := disp.FindOverload(call.OverloadID())
+		if !found {
+			impl, found = disp.FindOverload(call.Function())
+		}
+		if !found {
+			return i, nil
+		}
+		return &evalOverflowCall{InterpretableCall: call, impl: impl, policy: policy}, nil
+	}
+}
+
+// evalOverflowCall evaluates an arithmetic operator with the overflow policy.
+type evalOverflowCall struct {
+	InterpretableCall
+	impl   *functions.Overload
+	policy OverflowPolicy
+}
+
+// Eval implements the Interpretable interface method.
+func (call *evalOverflowCall) Eval(ctx Activation) ref.Val {
+	argVals, errVal := evalCallArgs(ctx, call, resolveCallImpl(ctx, call, call.impl))
+	if errVal != nil {
+		return errVal
+	}
+	return call.applyArgs(ctx, argVals)
+}
+
+// applyArgs implements the callApplier interface method.
+func (call *evalOverflowCall) applyArgs(ctx Activation, argVals []ref.Val) ref.Val {
+	var out ref.Val
+	switch len(argVals) {
+	case 1:
+		if x, ok := argVals[0].(types.Int); ok && call.Function() == operators.Negate {
+			out = call.negateInt(x)
+		}
+	case 2:
+		switch x := argVals[0].(type) {
+		case types.Int:
+			if y, ok := argVals[1].(types.Int); ok {
+				out = call.intArithmetic(x, y)
+			}
+		case types.Uint:
+			if y, ok := argVals[1].(types.Uint); ok {
+				out = call.uintArithmetic(x, y)
+			}
+		}
+	}
+	if out != nil {
+		return out
+	}
+	return applyCall(ctx, call.InterpretableCall, call.impl, argVals)
+}
+
+// negateInt negates an int, returning nil unless the negation overflows.
+func (call *evalOverflowCall) negateInt(x types.Int) ref.Val {
+	if x != math.MinInt64 {
+		return nil
+	}
+	if call.policy == OverflowWrap {
+		return x
+	}
+	return types.Int(math.MaxInt64)
+}
+
+// intArithmetic applies the operator to two ints, returning nil unless the operation overflows.
+func (call *evalOverflowCall) intArithmetic(x, y types.Int) ref.Val {
+	wrap := call.policy == OverflowWrap
+	switch call.Function() {
+	case operators.Add:
+		sum := x + y
+		// Overflow occurred if both operands have a sign which differs from the sign of the sum.
+		if (x^sum)&(y^sum) >= 0 {
+			return nil
+		}
+		if wrap {
+			return sum
+		}
+		return saturateInt(y > 0)
+	case operators.Subtract:
+		diff := x - y
+		// Overflow occurred if the operands differ in sign and the difference differs in sign from
+		// the minuend.
+		if (x^y)&(x^diff) >= 0 {
+			return nil
+		}
+		if wrap {
+			return diff
+		}
+		return saturateInt(y < 0)
+	case operators.Multiply:
+		product := x * y
+		if x == 0 || (product/x == y && !(x == -1 && y == math.MinInt64) &&
+			!(y == -1 && x == math.MinInt64)) {
+			return nil
+		}
+		if wrap {
+			return product
+		}
+		return saturateInt((x < 0) == (y < 0))
+	case operators.Divide:
+		if x != math.MinInt64 || y != -1 {
+			return nil
+		}
+		if wrap {
+			return x
+		}
+		return types.Int(math.MaxInt64)
+	case operators.Modulo:
+		if x != math.MinInt64 || y != -1 {
+			return nil
+		}
+		return types.IntZero
+	}
+	return nil
+}
+
+// uintArithmetic applies the operator to two uints, returning nil unless the operation overflows.
+func (call *evalOverflowCall) uintArithmetic(x, y types.Uint) ref.Val {
+	wrap := call.policy == OverflowWrap
+	switch call.Function() {
+	case operators.Add:
+		sum, carry := bits.Add64(uint64(x), uint64(y), 0)
+		if carry == 0 {
+			return nil
+		}
+		if wrap {
+			return types.Uint(sum)
+		}
+		return types.Uint(math.MaxUint64)
+	case operators.Subtract:
+		diff, borrow := bits.Sub64(uint64(x), uint64(y), 0)
+		if borrow == 0 {
+			return nil
+		}
+		if wrap {
+			return types.Uint(diff)
+		}
+		return types.Uint(0)
+	case operators.Multiply:
+		hi, product := bits.Mul64(uint64(x), uint64(y))
+		if hi == 0 {
+			return nil
+		}
+		if wrap {
+			return types.Uint(product)
+		}
+		return types.Uint(math.MaxUint64)
+	}
+	return nil
+}
+
+// saturateInt returns the maximum int if the overflow was positive, or else the minimum int.
+func saturateInt(positive bool) ref.Val {
+	if positive {
+		return types.Int(math.MaxInt64)
+	}
+	return types.Int(math.MinInt64)
+}
@@ -0,0 +1,152 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"sync"
+
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// EvalPool bounds the number of goroutines used to evaluate independent subexpressions
+// concurrently. A pool may be shared by any number of programs and evaluations.
+type EvalPool struct {
+	tokens chan struct{}
+}
+
+// NewEvalPool creates an EvalPool which runs at most `workers` goroutines at a time in addition to
+// the goroutines which call Eval.
+func NewEvalPool(workers int) *EvalPool {
+	return &EvalPool{tokens: make(chan struct{}, workers)}
+}
+
+// ParallelEval returns an InterpretableDecorator which evaluates the operands of exhaustive logical
+// operators, and the elements of list and map literals, concurrently using the worker pool.
+//
+// The decorator must be applied after the ExhaustiveEval decorator, and any EvalObserver used
+// together with the decorator must be safe for concurrent use. When no worker is available, the
+// subexpression is evaluated by the calling goroutine.
+func ParallelEval(pool *EvalPool) InterpretableDecorator {
+	return func(i Interpretable) (Interpretable, error) {
+		switch inst := i.(type) {
+		case *evalExhaustiveAnd:
+			return &evalParallelAnd{evalExhaustiveAnd: inst, pool: pool}, nil
+		case *evalExhaustiveOr:
+			return &evalParallelOr{evalExhaustiveOr: inst, pool: pool}, nil
+		case *evalList:
+			if len(inst.elems) > 1 {
+				return &evalParallelList{evalList: inst, pool: pool}, nil
+			}
+		case *evalMap:
+			if len(inst.keys) > 0 {
+				return &evalParallelMap{evalMap: inst, pool: pool}, nil
+			}
+		}
+		return i, nil
+	}
+}
+
+// evalAll evaluates the expressions, returning their values in order.
+//
+// A panic raised during the evaluation of an expression, such as an EvalCancelledError, is
+// raised again within the calling goroutine once all evaluations have completed.
+func (p *EvalPool) evalAll(ctx Activation, exprs []Interpretable) []ref.Val {
+	vals := make([]ref.Val, len(exprs))
+	panics := make([]any, len(exprs))
+	var wg sync.WaitGroup
+	for i := len(exprs) - 1; i > 0; i-- {
+		select {
+		case p.tokens <- struct{}{}:
+			wg.Add(1)
+			go func(i int) {
+				defer func() {
+					panics[i] = recover()
+					<-p.tokens
+					wg.Done()
+				}()
+				vals[i] = exprs[i].Eval(ctx)
+			}(i)
+		default:
+			vals[i] = p.evalInline(ctx, exprs[i], &panics[i])
+		}
+	}
+	vals[0] = p.evalInline(ctx, exprs[0], &panics[0])
+	wg.Wait()
+	for _, r := range panics {
+		if r != nil {
+			panic(r)
+		}
+	}
+	return vals
+}
+
+// evalInline evaluates the expression within the calling goroutine, recording any panic so that
+// the concurrent evaluations are awaited before the panic is raised.
+func (p *EvalPool) evalInline(ctx Activation, expr Interpretable, recovered *any) (val ref.Val) {
+	defer func() {
+		*recovered = recover()
+	}()
+	return expr.Eval(ctx)
+}
+
+// evalParallelAnd evaluates the operands of an exhaustive logical and concurrently.
+type evalParallelAnd struct {
+	*evalExhaustiveAnd
+	pool *EvalPool
+}
+
+// Eval implements the Interpretable interface method.
+func (and *evalParallelAnd) Eval(ctx Activation) ref.Val {
+	vals := and.pool.evalAll(ctx, []Interpretable{and.lhs, and.rhs})
+	return and.result(vals[0], vals[1])
+}
+
+// evalParallelOr evaluates the operands of an exhaustive logical or concurrently.
+type evalParallelOr struct {
+	*evalExhaustiveOr
+	pool *EvalPool
+}
+
+// Eval implements the Interpretable interface method.
+func (or *evalParallelOr) Eval(ctx Activation) ref.Val {
+	vals := or.pool.evalAll(ctx, []Interpretable{or.lhs, or.rhs})
+	return or.result(vals[0], vals[1])
+}
+
+// evalParallelList evaluates the elements of a list literal concurrently.
+type evalParallelList struct {
+	*evalList
+	pool *EvalPool
+}
+
+// Eval implements the Interpretable interface method.
+func (l *evalParallelList) Eval(ctx Activation) ref.Val {
+	vals := l.pool.evalAll(ctx, l.elems)
+	return l.build(func(i int) ref.Val { return vals[i] })
+}
+
+// evalParallelMap evaluates the keys and values of a map literal concurrently.
+type evalParallelMap struct {
+	*evalMap
+	pool *EvalPool
+}
+
+// Eval implements the Interpretable interface method.
+func (m *evalParallelMap) Eval(ctx Activation) ref.Val {
+	vals := m.pool.evalAll(ctx, m.InitVals())
+	return m.build(
+		func(i int) ref.Val { return vals[2*i] },
+		func(i int) ref.Val { return vals[2*i+1] })
+}
@@ -0,0 +1,528 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/cel-go/common/types"
+)
+
+// PlanNode describes a node of a planned Interpretable tree after all decorators have been
+// applied, for the purpose of inspecting how an expression will be evaluated.
+type PlanNode struct {
+	// ID is the expression id of the node.
+	ID int64
+
+	// Kind is the name of the Go type which implements the node, e.g. `evalBinary`, or
+	// `evalSetMembership` for a call to `@in` which has been replaced by a set lookup.
+	Kind string
+
+	// Label describes the node, e.g. the function and overload of a call, or the value of a
+	// constant.
+	Label string
+
+	// Role describes the relationship of the node to its parent, e.g. `lhs` or `arg[1]`.
+	Role string
+
+	// Optimized indicates whether the node was produced by an optimization, such as the
+	// set membership tests and flattened concatenations produced by the Optimize decorator.
+	Optimized bool
+
+	Children []*PlanNode
+
+	category planCategory
+}
+
+// planCategory classifies plan nodes for the purpose of computing plan statistics.
+type planCategory int
+
+const (
+	categoryOther planCategory = iota
+	categoryWrapper
+	categoryAttribute
+	categoryCall
+	categoryConstant
+	categoryConstructor
+	categoryFold
+)
+
+// PlanStats summarizes the size of a planned Interpretable tree, for the purpose of estimating the
+// complexity of a program after its decorators have been applied.
+//
+// The nodes introduced by decorators which wrap another node, such as those which observe
+// evaluation state, are not counted, nor are the nodes lowered to bytecode by LowerToBytecode.
+type PlanStats struct {
+	// Nodes is the total number of nodes in the plan.
+	Nodes int
+
+	// Depth is the number of nodes along the longest path from the root of the plan to a leaf.
+	Depth int
+
+	// Attributes is the number of variable and field selection nodes, including presence tests.
+	Attributes int
+
+	// Calls is the number of function and operator calls, including the logical operators.
+	Calls int
+
+	// Constants is the number of constant values, including those produced by folding.
+	Constants int
+
+	// Constructors is the number of list, map, and message constructions.
+	Constructors int
+
+	// Folds is the number of comprehensions.
+	Folds int
+}
+
+// Stats returns the statistics of the plan rooted at the node.
+func (n *PlanNode) Stats() PlanStats {
+	var stats PlanStats
+	stats.Depth = n.collectStats(&stats)
+	return stats
+}
+
+// collectStats accumulates the counts of the nodes rooted at this node, returning the depth of the
+// subtree.
+func (n *PlanNode) collectStats(stats *PlanStats) int {
+	depth := 0
+	for _, child := range n.Children {
+		if d := child.collectStats(stats); d > depth {
+			depth = d
+		}
+	}
+	if n.category == categoryWrapper {
+		return depth
+	}
+	stats.Nodes++
+	switch n.category {
+	case categoryAttribute:
+		stats.Attributes++
+	case categoryCall:
+		stats.Calls++
+	case categoryConstant:
+		stats.Constants++
+	case categoryConstructor:
+		stats.Constructors++
+	case categoryFold:
+		stats.Folds++
+	}
+	return depth + 1
+}
+
+// DescribePlan returns a description of the planned Interpretable tree rooted at the given node.
+//
+// Decorators which wrap a node, such as those which observe evaluation state, appear as nodes
+// with the wrapped node as their only child. Nodes implemented outside of this package are
+// described by their type, and by their arguments when they implement InterpretableCall.
+func DescribePlan(i Interpretable) *PlanNode {
+	return describePlanNode(i, "")
+}
+
+// DOT renders the plan as a Graphviz digraph in which optimized nodes are highlighted.
+func (n *PlanNode) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph plan {\n")
+	sb.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+	n.walk(func(name string, node *PlanNode, parent string) {
+		style := ""
+		if node.Optimized {
+			style = ", style=filled, fillcolor=\"#fff2b3\""
+		}
+		fmt.Fprintf(&sb, "  %s [label=%s%s];\n", name, dotQuote(node.lines("\n")), style)
+		if parent != "" {
+			fmt.Fprintf(&sb, "  %s -> %s", parent, name)
+			if node.Role != "" {
+				fmt.Fprintf(&sb, " [label=%s]", dotQuote(node.Role))
+			}
+			sb.WriteString(";\n")
+		}
+	})
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Mermaid renders the plan as a Mermaid flowchart in which optimized nodes are highlighted.
+func (n *PlanNode) Mermaid() string {
+	var sb strings.Builder
+	sb.WriteString("flowchart TD\n")
+	var optimized []string
+	n.walk(func(name string, node *PlanNode, parent string) {
+		fmt.Fprintf(&sb, "  %s[\"%s\"]\n", name, mermaidEscape(node.lines("<br/>")))
+		if parent != "" {
+			if node.Role != "" {
+				fmt.Fprintf(&sb, "  %s -->|\"%s\"| %s\n", parent, mermaidEscape(node.Role), name)
+			} else {
+				fmt.Fprintf(&sb, "  %s --> %s\n", parent, name)
+			}
+		}
+		if node.Optimized {
+			optimized = append(optimized, name)
+		}
+	})
+	if len(optimized) != 0 {
+		sb.WriteString("  classDef optimized fill:#fff2b3\n")
+		fmt.Fprintf(&sb, "  class %s optimized\n", strings.Join(optimized, ","))
+	}
+	return sb.String()
+}
+
+// walk visits the nodes of the plan in depth-first order, naming each node by its position.
+func (n *PlanNode) walk(visit func(name string, node *PlanNode, parent string)) {
+	count := 0
+	var visitNode func(node *PlanNode, parent string)
+	visitNode = func(node *PlanNode, parent string) {
+		name := "n" + strconv.Itoa(count)
+		count++
+		visit(name, node, parent)
+		for _, child := range node.Children {
+			visitNode(child, name)
+		}
+	}
+	visitNode(n, "")
+}
+
+func (n *PlanNode) lines(sep string) string {
+	lines := []string{n.Kind}
+	if n.Label != "" {
+		lines = append(lines, n.Label)
+	}
+	lines = append(lines, fmt.Sprintf("id: %d", n.ID))
+	return strings.Join(lines, sep)
+}
+
+func dotQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
+
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<br/>", "<br/>", "<", "#lt;", ">", "#gt;").Replace(s)
+}
+
+func describePlanNode(i Interpretable, role string) *PlanNode {
+	n := &PlanNode{ID: i.ID(), Kind: planKind(i), Role: role}
+	child := func(c Interpretable, role string) {
+		if c != nil {
+			n.Children = append(n.Children, describePlanNode(c, role))
+		}
+	}
+	args := func(args []Interpretable) {
+		for idx, arg := range args {
+			child(arg, fmt.Sprintf("arg[%d]", idx))
+		}
+	}
+	switch node := i.(type) {
+	case *evalConst:
+		n.category = categoryConstant
+		n.Label = planValue(node.val)
+	case *evalOr:
+		n.category = categoryCall
+		n.Label = "||"
+		child(node.lhs, "lhs")
+		child(node.rhs, "rhs")
+	case *evalAnd:
+		n.category = categoryCall
+		n.Label = "&&"
+		child(node.lhs, "lhs")
+		child(node.rhs, "rhs")
+	case *evalExhaustiveOr:
+		n.category = categoryCall
+		n.Label = "||"
+		child(node.lhs, "lhs")
+		child(node.rhs, "rhs")
+	case *evalExhaustiveAnd:
+		n.category = categoryCall
+		n.Label = "&&"
+		child(node.lhs, "lhs")
+		child(node.rhs, "rhs")
+	case *evalParallelOr:
+		n.category = categoryCall
+		n.Label = "||"
+		child(node.lhs, "lhs")
+		child(node.rhs, "rhs")
+	case *evalParallelAnd:
+		n.category = categoryCall
+		n.Label = "&&"
+		child(node.lhs, "lhs")
+		child(node.rhs, "rhs")
+	case *evalConcat:
+		n.category = categoryCall
+		n.Label = planCall(node.bin.function, node.bin.overload)
+		n.Optimized = true
+		args(node.args)
+	case *evalSetMembership:
+		n.category = categoryCall
+		n.Label = "@in constant set"
+		n.Optimized = true
+		child(node.arg, "arg[0]")
+	case *evalList:
+		n.category = categoryConstructor
+		for idx, elem := range node.elems {
+			child(elem, fmt.Sprintf("elem[%d]", idx))
+		}
+	case *evalParallelList:
+		n.category = categoryConstructor
+		for idx, elem := range node.elems {
+			child(elem, fmt.Sprintf("elem[%d]", idx))
+		}
+	case *evalMap:
+		n.category = categoryConstructor
+		describeMapEntries(n, node.keys, node.vals)
+	case *evalParallelMap:
+		n.category = categoryConstructor
+		describeMapEntries(n, node.keys, node.vals)
+	case *evalObj:
+		n.category = categoryConstructor
+		n.Label = node.typeName
+		for idx, field := range node.fields {
+			child(node.vals[idx], field)
+		}
+	case *evalFold:
+		n.category = categoryFold
+		n.Label = fmt.Sprintf("iterVar: %s, accuVar: %s", node.iterVar, node.accuVar)
+		child(node.iterRange, "iterRange")
+		child(node.accu, "accuInit")
+		child(node.cond, "loopCondition")
+		child(node.step, "loopStep")
+		child(node.result, "result")
+	case *evalJoinFold:
+		n.category = categoryFold
+		n.Label = fmt.Sprintf("@join iterVar: %s, accuVar: %s", node.iterVar, node.accuVar)
+		n.Optimized = true
+		child(node.iterRange, "iterRange")
+		args(node.parts)
+	case *evalTestOnly:
+		n.category = categoryAttribute
+		n.Label = fmt.Sprintf("has(.%s)", node.field)
+		n.Children = append(n.Children, describeAttribute(node.attr.Attr(), "operand"))
+	case *evalExhaustiveConditional:
+		n.category = categoryCall
+		n.Label = "_?_:_"
+		describeConditional(n, node.attr)
+	case *evalAttr:
+		n.category = categoryAttribute
+		n.Label = planAttribute(node.attr)
+		describeAttributeChildren(n, node.attr)
+	case *evalScalar:
+		n.category = categoryWrapper
+		n.Label = "unboxed scalar evaluation"
+		n.Optimized = true
+		child(node.fallback, "fallback")
+	case *evalBytecode:
+		n.category = categoryWrapper
+		n.Label = fmt.Sprintf("%d instructions", len(node.code))
+		n.Optimized = true
+		for idx, sub := range node.nodes {
+			child(sub, fmt.Sprintf("node[%d]", idx))
+		}
+	case *evalWatch:
+		n.category = categoryWrapper
+		child(node.Interpretable, "")
+	case *evalWatchAttr:
+		n.category = categoryWrapper
+		child(node.InterpretableAttribute, "")
+	case *evalWatchConst:
+		n.category = categoryWrapper
+		child(node.InterpretableConst, "")
+	case *evalWatchConstructor:
+		n.category = categoryWrapper
+		child(node.constructor, "")
+	case *evalTimed:
+		n.category = categoryWrapper
+		child(node.Interpretable, "")
+	case *evalTimedAttr:
+		n.category = categoryWrapper
+		child(node.InterpretableAttribute, "")
+	case *evalTimedConstructor:
+		n.category = categoryWrapper
+		child(node.InterpretableConstructor, "")
+	case *evalProvenance:
+		n.category = categoryWrapper
+		child(node.Interpretable, "")
+	case *evalProvenanceAttr:
+		n.category = categoryWrapper
+		child(node.InterpretableAttribute, "")
+	case *evalProvenanceConst:
+		n.category = categoryWrapper
+		child(node.InterpretableConst, "")
+	case *evalProvenanceConstructor:
+		n.category = categoryWrapper
+		child(node.InterpretableConstructor, "")
+	case *evalIncremental:
+		n.category = categoryWrapper
+		child(node.Interpretable, "")
+	case *evalMemoizedCall:
+		n.category = categoryWrapper
+		child(node.InterpretableCall, "")
+	case *evalGuardedCall:
+		n.category = categoryWrapper
+		child(node.InterpretableCall, "")
+	case *evalLateBoundCall:
+		n.category = categoryWrapper
+		child(node.InterpretableCall, "")
+	case *evalLimitedStringCall:
+		n.category = categoryWrapper
+		child(node.InterpretableCall, "")
+	case InterpretableCall:
+		n.category = categoryCall
+		n.Label = planCall(node.Function(), node.OverloadID())
+		args(node.Args())
+	case InterpretableAttribute:
+		n.category = categoryAttribute
+		n.Label = planAttribute(node.Attr())
+		describeAttributeChildren(n, node.Attr())
+	}
+	return n
+}
+
+func describeMapEntries(n *PlanNode, keys, vals []Interpretable) {
+	for idx, key := range keys {
+		n.Children = append(n.Children,
+			describePlanNode(key, fmt.Sprintf("key[%d]", idx)),
+			describePlanNode(vals[idx], fmt.Sprintf("value[%d]", idx)))
+	}
+}
+
+// describeAttribute describes an attribute which is not itself an Interpretable, such as the
+// branches of a conditional attribute.
+func describeAttribute(attr Attribute, role string) *PlanNode {
+	n := &PlanNode{ID: attr.ID(), Kind: planKind(attr), Label: planAttribute(attr), Role: role,
+		category: categoryAttribute}
+	describeAttributeChildren(n, attr)
+	return n
+}
+
+// describeAttributeChildren adds the expressions on which an attribute depends to the node, such
+// as the operand of a relative attribute and the computed qualifiers of the attribute.
+func describeAttributeChildren(n *PlanNode, attr Attribute) {
+	var quals []Qualifier
+	switch a := unwrapAttribute(attr).(type) {
+	case *absoluteAttribute:
+		quals = a.qualifiers
+	case *maybeAttribute:
+		if len(a.attrs) != 0 {
+			describeAttributeChildren(n, a.attrs[0])
+		}
+	case *relativeAttribute:
+		n.Children = append(n.Children, describePlanNode(a.operand, "operand"))
+		quals = a.qualifiers
+	case *conditionalAttribute:
+		describeConditional(n, a)
+	}
+	for idx, qual := range quals {
+		if q, isAttr := qual.(*attrQualifier); isAttr {
+			n.Children = append(n.Children, describeAttribute(q.Attribute, fmt.Sprintf("qualifier[%d]", idx)))
+		}
+	}
+}
+
+func describeConditional(n *PlanNode, a *conditionalAttribute) {
+	n.Children = append(n.Children,
+		describePlanNode(a.expr, "condition"),
+		describeAttribute(a.truthy, "truthy"),
+		describeAttribute(a.falsy, "falsy"))
+}
+
+// unwrapAttribute returns the attribute wrapped by the attribute decorators of this package.
+func unwrapAttribute(attr Attribute) Attribute {
+	for {
+		switch a := attr.(type) {
+		case *cachedAttribute:
+			attr = a.NamespacedAttribute
+		case *flatAttribute:
+			attr = a.NamespacedAttribute
+		default:
+			return attr
+		}
+	}
+}
+
+// planAttribute formats an attribute as the variable or expression and the qualifiers it selects.
+func planAttribute(attr Attribute) string {
+	var sb strings.Builder
+	var quals []Qualifier
+	switch a := unwrapAttribute(attr).(type) {
+	case *absoluteAttribute:
+		if len(a.namespaceNames) != 0 {
+			sb.WriteString(a.namespaceNames[0])
+		}
+		quals = a.qualifiers
+	case *maybeAttribute:
+		if len(a.attrs) == 0 {
+			return ""
+		}
+		return planAttribute(a.attrs[0])
+	case *relativeAttribute:
+		sb.WriteString("(operand)")
+		quals = a.qualifiers
+	case *conditionalAttribute:
+		return "_?_:_"
+	default:
+		return ""
+	}
+	for _, qual := range quals {
+		sb.WriteString(planQualifier(qual))
+	}
+	return sb.String()
+}
+
+func planQualifier(qual Qualifier) string {
+	switch q := qual.(type) {
+	case *fieldQualifier:
+		return "." + q.Name
+	case *stringQualifier:
+		return "." + q.value
+	case *attrQualifier:
+		return "[(qualifier)]"
+	case ConstantQualifier:
+		return "[" + planValue(q.Value()) + "]"
+	}
+	return "[?]"
+}
+
+func planCall(function, overload string) string {
+	if overload == "" {
+		return function
+	}
+	return fmt.Sprintf("%s [%s]", function, overload)
+}
+
+// planValue formats a constant value, truncating long values.
+func planValue(val any) string {
+	var s string
+	switch v := val.(type) {
+	case types.String:
+		s = strconv.Quote(string(v))
+	default:
+		s = fmt.Sprintf("%v", val)
+	}
+	if len(s) > 40 {
+		s = s[:37] + "..."
+	}
+	return s
+}
+
+// planKind returns the name of the type which implements a node, without its package.
+func planKind(node any) string {
+	kind := fmt.Sprintf("%T", node)
+	kind = strings.TrimPrefix(kind, "*")
+	if idx := strings.LastIndex(kind, "."); idx >= 0 {
+		kind = kind[idx+1:]
+	}
+	return kind
+}
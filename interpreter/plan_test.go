@@ -0,0 +1,159 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/checker"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/containers"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter/functions"
+	"github.com/google/cel-go/parser"
+)
+
+func TestDescribePlan(t *testing.T) {
+	expr := `x in [1, 2, 3] && m.a.b + 'c' + 'd' == 'abcd' && [x].exists(y, y > 0)`
+	plan := DescribePlan(planTestInterpretable(t, expr, Optimize()))
+	kinds := map[string][]*PlanNode{}
+	var visit func(n *PlanNode)
+	visit = func(n *PlanNode) {
+		kinds[n.Kind] = append(kinds[n.Kind], n)
+		for _, c := range n.Children {
+			visit(c)
+		}
+	}
+	visit(plan)
+	if plan.Kind != "evalAnd" || plan.Label != "&&" || len(plan.Children) != 2 {
+		t.Errorf("DescribePlan() got root %+v, wanted a logical and", plan)
+	}
+	if set := kinds["evalSetMembership"]; len(set) != 1 || !set[0].Optimized ||
+		set[0].Label != "@in constant set" || set[0].Children[0].Label != "x" {
+		t.Errorf("DescribePlan() got set membership nodes %v, wanted an optimized @in", set)
+	}
+	if concat := kinds["evalConcat"]; len(concat) != 1 || !concat[0].Optimized || len(concat[0].Children) != 3 {
+		t.Errorf("DescribePlan() got concat nodes %v, wanted a flattened concatenation", concat)
+	}
+	if attrs := kinds["evalAttr"]; len(attrs) == 0 || attrs[0].Label != "x" {
+		t.Errorf("DescribePlan() got attributes %v, wanted x", attrs)
+	}
+	if folds := kinds["evalFold"]; len(folds) != 1 || len(folds[0].Children) != 5 ||
+		folds[0].Children[0].Role != "iterRange" {
+		t.Errorf("DescribePlan() got folds %v, wanted a comprehension", folds)
+	}
+	if calls := kinds["evalBinary"]; len(calls) == 0 {
+		t.Error("DescribePlan() got no binary calls")
+	}
+
+	dot := plan.DOT()
+	for _, want := range []string{
+		"digraph plan {",
+		`n0 [label="evalAnd\n&&\nid: `,
+		`[label="evalSetMembership\n@in constant set\nid: 2", style=filled, fillcolor="#fff2b3"];`,
+		`-> n3 [label="arg[0]"];`,
+		`\"c\"`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT() got %s, wanted it to contain %s", dot, want)
+		}
+	}
+	mermaid := plan.Mermaid()
+	for _, want := range []string{
+		"flowchart TD\n",
+		`n0["evalAnd<br/>&&<br/>id: `,
+		`-->|"arg[0]"| n3`,
+		"#quot;c#quot;",
+		"classDef optimized fill:#fff2b3",
+	} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Mermaid() got %s, wanted it to contain %s", mermaid, want)
+		}
+	}
+}
+
+func TestPlanStats(t *testing.T) {
+	tests := []struct {
+		expr       string
+		decorators []InterpretableDecorator
+		want       PlanStats
+	}{
+		{
+			expr: `x + 1 > 2 && m.a.b == 'c'`,
+			want: PlanStats{Nodes: 9, Depth: 4, Attributes: 2, Calls: 4, Constants: 3},
+		},
+		{
+			// Observers wrap each node without changing the statistics of the plan.
+			expr:       `x + 1 > 2 && m.a.b == 'c'`,
+			decorators: []InterpretableDecorator{Observe(func(int64, any, ref.Val) {})},
+			want:       PlanStats{Nodes: 9, Depth: 4, Attributes: 2, Calls: 4, Constants: 3},
+		},
+		{
+			// The list is folded into a constant.
+			expr:       `x in [1, 2, 3]`,
+			decorators: []InterpretableDecorator{Optimize()},
+			want:       PlanStats{Nodes: 2, Depth: 2, Attributes: 1, Calls: 1},
+		},
+		{
+			expr: `[x].exists(y, y > 0)`,
+			want: PlanStats{Nodes: 13, Depth: 4, Attributes: 5, Calls: 4, Constants: 2, Constructors: 1, Folds: 1},
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			got := DescribePlan(planTestInterpretable(t, tc.expr, tc.decorators...)).Stats()
+			if got != tc.want {
+				t.Errorf("Stats() got %+v, wanted %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func planTestInterpretable(t *testing.T, expr string, decorators ...InterpretableDecorator) Interpretable {
+	t.Helper()
+	cont := containers.DefaultContainer
+	reg := newTestRegistry(t)
+	env := newTestEnv(t, cont, reg)
+	if err := env.Add(
+		decls.NewVar("x", decls.Int),
+		decls.NewVar("m", decls.NewMapType(decls.String, decls.NewMapType(decls.String, decls.String))),
+	); err != nil {
+		t.Fatalf("env.Add() failed: %v", err)
+	}
+	p, err := parser.NewParser(parser.Macros(parser.AllMacros...))
+	if err != nil {
+		t.Fatalf("parser.NewParser() failed: %v", err)
+	}
+	src := common.NewTextSource(expr)
+	parsed, errs := p.Parse(src)
+	if len(errs.GetErrors()) != 0 {
+		t.Fatalf("Parse(%q) failed: %v", expr, errs.ToDisplayString())
+	}
+	checked, errs := checker.Check(parsed, src, env)
+	if len(errs.GetErrors()) != 0 {
+		t.Fatalf("Check(%q) failed: %v", expr, errs.ToDisplayString())
+	}
+	disp := NewDispatcher()
+	disp.Add(functions.StandardOverloads()...)
+	interp := NewInterpreter(disp, cont, reg, reg, NewAttributeFactory(cont, reg, reg))
+	i, err := interp.NewInterpretable(checked, decorators...)
+	if err != nil {
+		t.Fatalf("NewInterpretable(%q) failed: %v", expr, err)
+	}
+	return i
+}
@@ -290,6 +290,18 @@ func (p *planner) planCall(expr *exprpb.Expr) (Interpretable, error) {
 	if fnDef == nil {
 		fnDef, _ = p.disp.FindOverload(fnName)
 	}
+	// Context-aware implementations take precedence regardless of the argument count.
+	if fnDef != nil && fnDef.ContextFunction != nil {
+		return &evalContextCall{
+			id:        expr.GetId(),
+			function:  fnName,
+			overload:  oName,
+			args:      args,
+			trait:     fnDef.OperandTrait,
+			impl:      fnDef.ContextFunction,
+			nonStrict: fnDef.NonStrict,
+		}, nil
+	}
 	switch argCount {
 	case 0:
 		return p.planCallZero(expr, fnName, oName, fnDef)
@@ -498,6 +510,9 @@ func (p *planner) planCallIndex(expr *exprpb.Expr, args []Interpretable, optiona
 	switch ind := ind.(type) {
 	case InterpretableConst:
 		qual, err = p.attrFactory.NewQualifier(opType, expr.GetId(), ind.Value(), optional)
+		if err == nil {
+			qual = precomputeIndex(attr.Attr(), qual)
+		}
 	case InterpretableAttribute:
 		qual, err = p.attrFactory.NewQualifier(opType, expr.GetId(), ind, optional)
 	default:
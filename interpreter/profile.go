@@ -0,0 +1,174 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+)
+
+// Profile records the behavior of an expression over a series of evaluations, indexed by
+// expression id, for use in profile-guided optimization.
+//
+// Profiles are collected with the ProfileObserver, may be merged across the replicas evaluating
+// the same checked expression, and are serialized as JSON:
+//
+//	{"nodes": {"3": {"hits": 10, "true": 9, "false": 1}, "7": {"hits": 4, "sizeMax": 12, ...}}}
+//
+// A Profile is safe for concurrent use.
+type Profile struct {
+	mu    sync.Mutex
+	Nodes map[int64]*NodeProfile `json:"nodes"`
+}
+
+// NodeProfile records the values observed for a single expression node.
+type NodeProfile struct {
+	// Hits is the number of times the node was evaluated.
+	Hits uint64 `json:"hits"`
+
+	// True and False count the boolean results of the node.
+	True  uint64 `json:"true,omitempty"`
+	False uint64 `json:"false,omitempty"`
+
+	// SizeCount, SizeTotal and SizeMax describe the sizes of the string, bytes, list and map
+	// results of the node.
+	SizeCount uint64 `json:"sizeCount,omitempty"`
+	SizeTotal uint64 `json:"sizeTotal,omitempty"`
+	SizeMax   uint64 `json:"sizeMax,omitempty"`
+}
+
+// TrueRatio returns the fraction of evaluations where the node produced true, smoothed so that
+// nodes with few observations tend toward one half.
+func (n NodeProfile) TrueRatio() float64 {
+	return (float64(n.True) + 1) / (float64(n.Hits) + 2)
+}
+
+// FalseRatio returns the fraction of evaluations where the node produced false, smoothed so that
+// nodes with few observations tend toward one half.
+func (n NodeProfile) FalseRatio() float64 {
+	return (float64(n.False) + 1) / (float64(n.Hits) + 2)
+}
+
+// NewProfile returns an empty Profile.
+func NewProfile() *Profile {
+	return &Profile{Nodes: map[int64]*NodeProfile{}}
+}
+
+// ParseProfile parses the JSON serialized form of a Profile.
+func ParseProfile(data []byte) (*Profile, error) {
+	p := NewProfile()
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+	if p.Nodes == nil {
+		p.Nodes = map[int64]*NodeProfile{}
+	}
+	return p, nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p *Profile) MarshalJSON() ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return json.Marshal(struct {
+		Nodes map[int64]*NodeProfile `json:"nodes"`
+	}{Nodes: p.Nodes})
+}
+
+// Node returns a copy of the profile of the expression id, if the node has been observed.
+func (p *Profile) Node(id int64) (NodeProfile, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n, found := p.Nodes[id]
+	if !found {
+		return NodeProfile{}, false
+	}
+	return *n, true
+}
+
+// Merge adds the observations of the other profile to this profile.
+func (p *Profile) Merge(other *Profile) {
+	other.mu.Lock()
+	nodes := make(map[int64]NodeProfile, len(other.Nodes))
+	for id, n := range other.Nodes {
+		nodes[id] = *n
+	}
+	other.mu.Unlock()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id, n := range nodes {
+		node := p.node(id)
+		node.Hits += n.Hits
+		node.True += n.True
+		node.False += n.False
+		node.SizeCount += n.SizeCount
+		node.SizeTotal += n.SizeTotal
+		if n.SizeMax > node.SizeMax {
+			node.SizeMax = n.SizeMax
+		}
+	}
+}
+
+// node returns the mutable profile of the expression id, creating it if necessary.
+//
+// The caller must hold the profile lock.
+func (p *Profile) node(id int64) *NodeProfile {
+	n, found := p.Nodes[id]
+	if !found {
+		n = &NodeProfile{}
+		p.Nodes[id] = n
+	}
+	return n
+}
+
+// ProfileObserver provides an observer which records the hit counts, boolean outcomes, and value
+// sizes of each expression node within the Profile.
+func ProfileObserver(profile *Profile) EvalObserver {
+	return func(id int64, programStep any, val ref.Val) {
+		profile.mu.Lock()
+		defer profile.mu.Unlock()
+		n := profile.node(id)
+		n.Hits++
+		switch v := val.(type) {
+		case types.Bool:
+			if v {
+				n.True++
+			} else {
+				n.False++
+			}
+		case types.String:
+			n.observeSize(uint64(len(v)))
+		case types.Bytes:
+			n.observeSize(uint64(len(v)))
+		case traits.Sizer:
+			if size, ok := v.Size().(types.Int); ok && size >= 0 {
+				n.observeSize(uint64(size))
+			}
+		}
+	}
+}
+
+func (n *NodeProfile) observeSize(size uint64) {
+	n.SizeCount++
+	n.SizeTotal += size
+	if size > n.SizeMax {
+		n.SizeMax = size
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/checker/decls"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestProfileObserver(t *testing.T) {
+	profile := NewProfile()
+	prg, _, err := program(t, &testCase{
+		expr: `names.filter(n, n.startsWith('a')).size() > 1`,
+		env: []*exprpb.Decl{
+			decls.NewVar("names", decls.NewListType(decls.String)),
+		},
+	}, Observe(ProfileObserver(profile)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, names := range [][]string{{"alice", "bob"}, {"adam", "anne", "ann"}} {
+		prg.Eval(mustActivation(t, map[string]any{"names": names}))
+	}
+	// The root expression is evaluated twice, producing false and then true.
+	root, found := profile.Node(prg.ID())
+	if !found || root.Hits != 2 || root.True != 1 || root.False != 1 {
+		t.Errorf("profile.Node(root) got %+v, %v, wanted one true and one false outcome", root, found)
+	}
+	// The 'names' variable is observed with sizes 2 and 3.
+	names, found := profile.Node(1)
+	if !found || names.SizeCount != 2 || names.SizeTotal != 5 || names.SizeMax != 3 {
+		t.Errorf("profile.Node(names) got %+v, %v, wanted sizes 2 and 3", names, found)
+	}
+
+	data, err := json.Marshal(profile)
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	parsed, err := ParseProfile(data)
+	if err != nil {
+		t.Fatalf("ParseProfile() failed: %v", err)
+	}
+	if !reflect.DeepEqual(parsed.Nodes, profile.Nodes) {
+		t.Errorf("ParseProfile() got %v, wanted %v", parsed.Nodes, profile.Nodes)
+	}
+	parsed.Merge(profile)
+	if merged, _ := parsed.Node(1); merged.SizeCount != 4 || merged.SizeMax != 3 {
+		t.Errorf("parsed.Merge() got %+v, wanted 4 size observations with max 3", merged)
+	}
+}
@@ -0,0 +1,309 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// Provenance describes the expressions and the input attributes which contributed to a value.
+type Provenance struct {
+	// ExprIDs lists the ids of the evaluated expressions which contributed to the value, including
+	// the expression which produced it, in ascending order.
+	ExprIDs []int64
+
+	// Inputs lists the input attributes which contributed to the value in sorted order. Each
+	// attribute is formatted as the name of a variable followed by its constant qualifiers, e.g.
+	// `request.headers["x-user"]` or `items[0].price`. The qualifiers following the first
+	// qualifier computed at runtime are omitted.
+	Inputs []string
+}
+
+// ProvenanceTracker records the provenance of the values produced by the expressions evaluated
+// during a single evaluation.
+//
+// The tracker is not thread-safe, and must be reset between Eval() calls.
+type ProvenanceTracker struct {
+	values map[int64]*Provenance
+	result *Provenance
+	frames []*provenanceFrame
+}
+
+// NewProvenanceTracker returns an empty ProvenanceTracker.
+func NewProvenanceTracker() *ProvenanceTracker {
+	return &ProvenanceTracker{values: map[int64]*Provenance{}}
+}
+
+// IDs returns the ids of the expressions with recorded provenance in ascending order.
+func (t *ProvenanceTracker) IDs() []int64 {
+	ids := make([]int64, 0, len(t.values))
+	for id := range t.values {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Value returns the provenance of the value most recently produced by the expression id, if any.
+func (t *ProvenanceTracker) Value(exprID int64) (*Provenance, bool) {
+	p, found := t.values[exprID]
+	return p, found
+}
+
+// Result returns the provenance of the result of the evaluation, or nil if the evaluation has not
+// completed.
+func (t *ProvenanceTracker) Result() *Provenance {
+	return t.result
+}
+
+// Reset clears the recorded provenance.
+func (t *ProvenanceTracker) Reset() {
+	t.values = map[int64]*Provenance{}
+	t.result = nil
+	t.frames = t.frames[:0]
+}
+
+// enter begins the evaluation of an expression.
+func (t *ProvenanceTracker) enter() {
+	t.frames = append(t.frames, &provenanceFrame{
+		ids:    map[int64]struct{}{},
+		inputs: map[string]struct{}{},
+	})
+}
+
+// exit completes the evaluation of the expression id, attributing to its value the provenance of
+// the expressions evaluated since the matching call to enter, along with the input, if any.
+func (t *ProvenanceTracker) exit(id int64, input string) {
+	f := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+	f.ids[id] = struct{}{}
+	if input != "" {
+		f.inputs[input] = struct{}{}
+	}
+	p := f.provenance()
+	t.values[id] = p
+	if len(t.frames) == 0 {
+		t.result = p
+		return
+	}
+	parent := t.frames[len(t.frames)-1]
+	for id := range f.ids {
+		parent.ids[id] = struct{}{}
+	}
+	for input := range f.inputs {
+		parent.inputs[input] = struct{}{}
+	}
+}
+
+// provenanceFrame accumulates the provenance of an expression while it is evaluated.
+type provenanceFrame struct {
+	ids    map[int64]struct{}
+	inputs map[string]struct{}
+}
+
+func (f *provenanceFrame) provenance() *Provenance {
+	p := &Provenance{
+		ExprIDs: make([]int64, 0, len(f.ids)),
+		Inputs:  make([]string, 0, len(f.inputs)),
+	}
+	for id := range f.ids {
+		p.ExprIDs = append(p.ExprIDs, id)
+	}
+	for input := range f.inputs {
+		p.Inputs = append(p.Inputs, input)
+	}
+	sort.Slice(p.ExprIDs, func(i, j int) bool { return p.ExprIDs[i] < p.ExprIDs[j] })
+	sort.Strings(p.Inputs)
+	return p
+}
+
+// TrackProvenance returns an InterpretableDecorator which records within the tracker the
+// provenance of the value produced by each expression node.
+//
+// A value is attributed to the expressions evaluated in order to produce it, so the operands of
+// a short-circuited logical operator which were not evaluated do not contribute to its result.
+// References to comprehension variables are not inputs, though the range of the comprehension
+// contributes to its result.
+//
+// The decorator should be applied after the decorators which inspect the type of the
+// Interpretable they decorate, and is not compatible with the parallel evaluation of
+// subexpressions.
+func TrackProvenance(tracker *ProvenanceTracker) InterpretableDecorator {
+	return func(i Interpretable) (Interpretable, error) {
+		switch inst := i.(type) {
+		case *evalProvenance, *evalProvenanceAttr, *evalProvenanceConst, *evalProvenanceConstructor:
+			return i, nil
+		case InterpretableAttribute:
+			return &evalProvenanceAttr{InterpretableAttribute: inst, tracker: tracker}, nil
+		case InterpretableConst:
+			return &evalProvenanceConst{InterpretableConst: inst, tracker: tracker}, nil
+		case InterpretableConstructor:
+			return &evalProvenanceConstructor{InterpretableConstructor: inst, tracker: tracker}, nil
+		default:
+			return &evalProvenance{Interpretable: i, tracker: tracker}, nil
+		}
+	}
+}
+
+type evalProvenance struct {
+	Interpretable
+	tracker *ProvenanceTracker
+}
+
+// Eval implements the Interpretable interface method.
+func (e *evalProvenance) Eval(vars Activation) ref.Val {
+	e.tracker.enter()
+	val := e.Interpretable.Eval(vars)
+	e.tracker.exit(e.ID(), "")
+	return val
+}
+
+// evalProvenanceAttr records the provenance of an InterpretableAttribute, which must implement
+// the InterpretableAttribute interface by proxy since it may be selected against at a later stage
+// in program planning.
+type evalProvenanceAttr struct {
+	InterpretableAttribute
+	tracker *ProvenanceTracker
+}
+
+// AddQualifier implements the InterpretableAttribute interface method.
+func (e *evalProvenanceAttr) AddQualifier(q Qualifier) (Attribute, error) {
+	_, err := e.InterpretableAttribute.AddQualifier(q)
+	return e, err
+}
+
+// Eval implements the Interpretable interface method.
+func (e *evalProvenanceAttr) Eval(vars Activation) ref.Val {
+	e.tracker.enter()
+	val := e.InterpretableAttribute.Eval(vars)
+	e.tracker.exit(e.ID(), attributeInput(e.Attr(), vars))
+	return val
+}
+
+type evalProvenanceConst struct {
+	InterpretableConst
+	tracker *ProvenanceTracker
+}
+
+// Eval implements the Interpretable interface method.
+func (e *evalProvenanceConst) Eval(vars Activation) ref.Val {
+	e.tracker.enter()
+	e.tracker.exit(e.ID(), "")
+	return e.InterpretableConst.Eval(vars)
+}
+
+type evalProvenanceConstructor struct {
+	InterpretableConstructor
+	tracker *ProvenanceTracker
+}
+
+// Eval implements the Interpretable interface method.
+func (e *evalProvenanceConstructor) Eval(vars Activation) ref.Val {
+	e.tracker.enter()
+	val := e.InterpretableConstructor.Eval(vars)
+	e.tracker.exit(e.ID(), "")
+	return val
+}
+
+// attributeInput returns the input described by the attribute, or the empty string if the
+// attribute refers to a comprehension variable or to no variable at all.
+func attributeInput(attr Attribute, vars Activation) string {
+	var candidates []NamespacedAttribute
+	switch a := attr.(type) {
+	case NamespacedAttribute:
+		candidates = []NamespacedAttribute{a}
+	case *maybeAttribute:
+		candidates = a.attrs
+	default:
+		return ""
+	}
+	for _, c := range candidates {
+		for _, name := range c.CandidateVariableNames() {
+			if isComprehensionVar(vars, name) {
+				return ""
+			}
+			if _, found := vars.ResolveName(name); found {
+				return formatInput(name, c.Qualifiers())
+			}
+		}
+	}
+	return ""
+}
+
+// isComprehensionVar returns whether the name resolves to a comprehension variable, which the
+// folds bind within the activations nearest to the expressions they evaluate.
+func isComprehensionVar(vars Activation, name string) bool {
+	for vars != nil {
+		switch a := vars.(type) {
+		case *varActivation:
+			if a.name == name {
+				return true
+			}
+		case *joinActivation:
+			if a.name == name {
+				return true
+			}
+		default:
+			return false
+		}
+		vars = vars.Parent()
+	}
+	return false
+}
+
+// formatInput formats the name of a variable followed by its leading constant qualifiers.
+func formatInput(name string, quals []Qualifier) string {
+	var sb strings.Builder
+	sb.WriteString(name)
+	for _, q := range quals {
+		c, isConst := q.(ConstantQualifier)
+		if !isConst {
+			break
+		}
+		switch v := c.Value().(type) {
+		case types.String:
+			if isIdentifier(string(v)) {
+				fmt.Fprintf(&sb, ".%s", v)
+			} else {
+				fmt.Fprintf(&sb, "[%q]", v)
+			}
+		case types.Uint:
+			fmt.Fprintf(&sb, "[%du]", v)
+		default:
+			fmt.Fprintf(&sb, "[%v]", v)
+		}
+	}
+	return sb.String()
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/types"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestTrackProvenance(t *testing.T) {
+	tracker := NewProvenanceTracker()
+	prg, _, err := program(t, &testCase{
+		expr: `names.exists(n, n.startsWith(prefix)) && m['a b'][1u] > 0`,
+		env: []*exprpb.Decl{
+			decls.NewVar("names", decls.NewListType(decls.String)),
+			decls.NewVar("prefix", decls.String),
+			decls.NewVar("m", decls.NewMapType(decls.String, decls.NewMapType(decls.Uint, decls.Int))),
+		},
+	}, TrackProvenance(tracker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars := mustActivation(t, map[string]any{
+		"names":  []string{"bob", "alice"},
+		"prefix": "a",
+		"m":      map[string]map[uint64]int{"a b": {1: 1}},
+	})
+	for i := 0; i < 2; i++ {
+		tracker.Reset()
+		out := prg.Eval(vars)
+		if out != types.True {
+			t.Fatalf("prg.Eval() got %v, wanted true", out)
+		}
+		res := tracker.Result()
+		if res == nil {
+			t.Fatal("tracker.Result() returned nil")
+		}
+		wantInputs := []string{`m["a b"][1u]`, "names", "prefix"}
+		if !reflect.DeepEqual(res.Inputs, wantInputs) {
+			t.Errorf("tracker.Result().Inputs got %v, wanted %v", res.Inputs, wantInputs)
+		}
+		root, found := tracker.Value(prg.ID())
+		if !found || root != res {
+			t.Errorf("tracker.Value(%d) got %v, wanted the result provenance", prg.ID(), root)
+		}
+		if len(tracker.IDs()) != len(res.ExprIDs) {
+			t.Errorf("tracker.IDs() got %v, wanted %v", tracker.IDs(), res.ExprIDs)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// NewReplayActivation returns an Activation which supplies the values of attributes from the
+// evaluation state captured by a previous evaluation of the same program, such that the program
+// may be re-evaluated exactly as it was originally evaluated without the variables it referenced.
+//
+// The values of attributes are looked up by expression id, which requires the program to be
+// planned from the same expression as the one which captured the state. The state must have been
+// captured with state tracking or exhaustive evaluation enabled. Attributes which refer to the
+// variables of comprehensions are resolved as usual, while attributes which were not evaluated by
+// the original evaluation produce the same error as a missing variable.
+//
+// The values are copied from the state when the activation is created.
+func NewReplayActivation(state EvalState) Activation {
+	ids := state.IDs()
+	values := make(map[int64]ref.Val, len(ids))
+	for _, id := range ids {
+		if val, found := state.Value(id); found {
+			values[id] = val
+		}
+	}
+	return &replayActivation{values: values}
+}
+
+type replayActivation struct {
+	emptyActivation
+	values map[int64]ref.Val
+}
+
+// findReplayedValue returns the value replayed for the expression id by the nearest replay
+// activation within the chain of activations.
+func findReplayedValue(vars Activation, id int64) (ref.Val, bool) {
+	for vars != nil {
+		switch v := vars.(type) {
+		case *replayActivation:
+			val, found := v.values[id]
+			return val, found
+		case *hierarchicalActivation:
+			// The child of a hierarchical activation is not among its parents.
+			if val, found := findReplayedValue(v.child, id); found {
+				return val, true
+			}
+		}
+		vars = vars.Parent()
+	}
+	return nil, false
+}
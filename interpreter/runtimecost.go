@@ -16,6 +16,7 @@ package interpreter
 
 import (
 	"math"
+	"sync/atomic"
 
 	"github.com/google/cel-go/common"
 	"github.com/google/cel-go/common/overloads"
@@ -75,7 +76,9 @@ func CostObserver(tracker *CostTracker) EvalObserver {
 			tracker.cost++
 		case InterpretableCall:
 			if argVals, ok := tracker.stack.dropArgs(t.Args()); ok {
-				tracker.cost += tracker.costCall(t, argVals, val)
+				callCost := tracker.costCall(t, argVals, val)
+				tracker.cost += callCost
+				tracker.recordCall(t, callCost)
 			}
 		case InterpretableConstructor:
 			tracker.stack.dropArgs(t.InitVals())
@@ -93,6 +96,13 @@ func CostObserver(tracker *CostTracker) EvalObserver {
 		if tracker.Limit != nil && tracker.cost > *tracker.Limit {
 			panic(EvalCancelledError{Cause: CostLimitExceeded, Message: "operation cancelled: actual cost limit exceeded"})
 		}
+		if tracker.Budget != nil && tracker.cost > tracker.charged {
+			delta := tracker.cost - tracker.charged
+			tracker.charged = tracker.cost
+			if !tracker.Budget.draw(delta) {
+				panic(EvalCancelledError{Cause: CostBudgetExceeded, Message: "operation cancelled: cost budget exhausted"})
+			}
+		}
 	}
 	return observer
 }
@@ -101,9 +111,76 @@ func CostObserver(tracker *CostTracker) EvalObserver {
 type CostTracker struct {
 	Estimator ActualCostEstimator
 	Limit     *uint64
+	// Budget, if set, is drawn down by the cost of the evaluation as it accrues.
+	Budget *CostBudget
+
+	cost          uint64
+	charged       uint64
+	stack         refValStack
+	overloadCosts map[string]OverloadCost
+}
 
-	cost  uint64
-	stack refValStack
+// CostBudget is an allowance of runtime cost which is drawn down by the evaluations which share
+// it, such as the evaluations of the rules of a single tenant, and which cancels the evaluation
+// whose cost exhausts it with an EvalCancelledError whose Cause is CostBudgetExceeded.
+//
+// Unlike the Limit of a CostTracker, which bounds the cost of each evaluation, a budget bounds the
+// total cost of all evaluations drawing from it until it is reset. A CostBudget is safe for
+// concurrent use.
+type CostBudget struct {
+	// remaining is accessed atomically, and is never negative.
+	remaining int64
+}
+
+// NewCostBudget returns a CostBudget with the given allowance of runtime cost.
+func NewCostBudget(allowance uint64) *CostBudget {
+	b := &CostBudget{}
+	b.Reset(allowance)
+	return b
+}
+
+// Remaining returns the cost which may still be drawn from the budget.
+func (b *CostBudget) Remaining() uint64 {
+	if rem := atomic.LoadInt64(&b.remaining); rem > 0 {
+		return uint64(rem)
+	}
+	return 0
+}
+
+// Reset replaces the remaining cost of the budget with the given allowance, e.g. at the start of
+// a new billing period.
+func (b *CostBudget) Reset(allowance uint64) {
+	if allowance > math.MaxInt64 {
+		allowance = math.MaxInt64
+	}
+	atomic.StoreInt64(&b.remaining, int64(allowance))
+}
+
+// draw deducts the cost from the budget, and returns false if the budget was exhausted by it.
+func (b *CostBudget) draw(cost uint64) bool {
+	if cost > math.MaxInt64 {
+		cost = math.MaxInt64
+	}
+	for {
+		rem := atomic.LoadInt64(&b.remaining)
+		next := rem - int64(cost)
+		if next < 0 {
+			next = 0
+		}
+		if atomic.CompareAndSwapInt64(&b.remaining, rem, next) {
+			return rem >= int64(cost)
+		}
+	}
+}
+
+// OverloadCost aggregates the runtime cost of the calls to a single function overload.
+type OverloadCost struct {
+	// Function is the name of the function called.
+	Function string
+	// Calls is the number of calls to the overload.
+	Calls uint64
+	// Cost is the sum of the runtime cost of the calls, excluding the cost of their arguments.
+	Cost uint64
 }
 
 // ActualCost returns the runtime cost
@@ -111,6 +188,33 @@ func (c CostTracker) ActualCost() uint64 {
 	return c.cost
 }
 
+// OverloadCosts returns the runtime cost of function calls aggregated by overload id.
+//
+// Calls which were dispatched dynamically without an overload id, as in unchecked expressions, are
+// aggregated by function name.
+func (c CostTracker) OverloadCosts() map[string]OverloadCost {
+	costs := make(map[string]OverloadCost, len(c.overloadCosts))
+	for id, cost := range c.overloadCosts {
+		costs[id] = cost
+	}
+	return costs
+}
+
+func (c *CostTracker) recordCall(call InterpretableCall, cost uint64) {
+	if c.overloadCosts == nil {
+		c.overloadCosts = make(map[string]OverloadCost)
+	}
+	id := call.OverloadID()
+	if id == "" {
+		id = call.Function()
+	}
+	oc := c.overloadCosts[id]
+	oc.Function = call.Function()
+	oc.Calls++
+	oc.Cost += cost
+	c.overloadCosts[id] = oc
+}
+
 func (c CostTracker) costCall(call InterpretableCall, argValues []ref.Val, result ref.Val) uint64 {
 	var cost uint64
 	if c.Estimator != nil {
@@ -0,0 +1,555 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"math"
+	"math/bits"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/overloads"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// NewScalarInterpretable plans a checked expression whose subexpressions are all of type int,
+// uint, double, bool, or string into an Interpretable which computes intermediate results as
+// unboxed Go values, boxing only the final result.
+//
+// Whenever evaluation encounters a condition which would produce an error, such as a missing
+// variable, a variable of an unexpected type, or an arithmetic overflow, the expression is
+// evaluated with the `fallback` Interpretable instead so that the results of evaluation are
+// identical to those of the fallback.
+//
+// The boolean result is false when the expression contains a non-scalar subexpression or a
+// function which is not supported by scalar evaluation, in which case the fallback should be used.
+func NewScalarInterpretable(checked *exprpb.CheckedExpr, fallback Interpretable) (Interpretable, bool) {
+	p := &scalarPlanner{
+		refMap:  checked.GetReferenceMap(),
+		typeMap: checked.GetTypeMap(),
+	}
+	eval, kind, ok := p.plan(checked.GetExpr())
+	if !ok {
+		return nil, false
+	}
+	return &evalScalar{
+		id:       checked.GetExpr().GetId(),
+		kind:     kind,
+		eval:     eval,
+		fallback: fallback,
+	}, true
+}
+
+type scalarKind uint8
+
+const (
+	scalarInt scalarKind = iota + 1
+	scalarUint
+	scalarDouble
+	scalarBool
+	scalarString
+)
+
+// scalar holds an unboxed value whose kind is determined at planning time.
+type scalar struct {
+	i int64
+	u uint64
+	d float64
+	b bool
+	s string
+}
+
+// scalarFunc computes an unboxed value, returning false if the result must be computed by the
+// fallback Interpretable.
+type scalarFunc func(vars Activation) (scalar, bool)
+
+type evalScalar struct {
+	id       int64
+	kind     scalarKind
+	eval     scalarFunc
+	fallback Interpretable
+}
+
+// ID implements the Interpretable interface method.
+func (s *evalScalar) ID() int64 {
+	return s.id
+}
+
+// Eval implements the Interpretable interface method.
+func (s *evalScalar) Eval(vars Activation) ref.Val {
+	v, ok := s.eval(vars)
+	if !ok {
+		return s.fallback.Eval(vars)
+	}
+	switch s.kind {
+	case scalarInt:
+		return types.Int(v.i)
+	case scalarUint:
+		return types.Uint(v.u)
+	case scalarDouble:
+		return types.Double(v.d)
+	case scalarBool:
+		return types.Bool(v.b)
+	default:
+		return types.String(v.s)
+	}
+}
+
+type scalarPlanner struct {
+	refMap  map[int64]*exprpb.Reference
+	typeMap map[int64]*exprpb.Type
+}
+
+// plan returns the scalar function for the expression and the kind of value it produces.
+func (p *scalarPlanner) plan(e *exprpb.Expr) (scalarFunc, scalarKind, bool) {
+	kind, ok := p.kindOf(e)
+	if !ok {
+		return nil, 0, false
+	}
+	switch e.GetExprKind().(type) {
+	case *exprpb.Expr_ConstExpr:
+		return planScalarConst(e.GetConstExpr(), kind)
+	case *exprpb.Expr_IdentExpr:
+		return p.planScalarVar(e, e.GetIdentExpr().GetName(), kind)
+	case *exprpb.Expr_SelectExpr:
+		// Only selections which the type-checker resolved to qualified variable names are scalar.
+		ref, found := p.refMap[e.GetId()]
+		if !found || ref.GetName() == "" || e.GetSelectExpr().GetTestOnly() {
+			return nil, 0, false
+		}
+		return p.planScalarVar(e, ref.GetName(), kind)
+	case *exprpb.Expr_CallExpr:
+		return p.planScalarCall(e, kind)
+	}
+	return nil, 0, false
+}
+
+func (p *scalarPlanner) kindOf(e *exprpb.Expr) (scalarKind, bool) {
+	switch p.typeMap[e.GetId()].GetPrimitive() {
+	case exprpb.Type_INT64:
+		return scalarInt, true
+	case exprpb.Type_UINT64:
+		return scalarUint, true
+	case exprpb.Type_DOUBLE:
+		return scalarDouble, true
+	case exprpb.Type_BOOL:
+		return scalarBool, true
+	case exprpb.Type_STRING:
+		return scalarString, true
+	}
+	return 0, false
+}
+
+func planScalarConst(c *exprpb.Constant, kind scalarKind) (scalarFunc, scalarKind, bool) {
+	var v scalar
+	switch c.GetConstantKind().(type) {
+	case *exprpb.Constant_Int64Value:
+		v.i = c.GetInt64Value()
+	case *exprpb.Constant_Uint64Value:
+		v.u = c.GetUint64Value()
+	case *exprpb.Constant_DoubleValue:
+		v.d = c.GetDoubleValue()
+	case *exprpb.Constant_BoolValue:
+		v.b = c.GetBoolValue()
+	case *exprpb.Constant_StringValue:
+		v.s = c.GetStringValue()
+	default:
+		return nil, 0, false
+	}
+	return func(Activation) (scalar, bool) { return v, true }, kind, true
+}
+
+func (p *scalarPlanner) planScalarVar(e *exprpb.Expr, name string, kind scalarKind) (scalarFunc, scalarKind, bool) {
+	if ref, found := p.refMap[e.GetId()]; found {
+		if ref.GetValue() != nil {
+			return planScalarConst(ref.GetValue(), kind)
+		}
+		if ref.GetName() != "" {
+			name = ref.GetName()
+		}
+	}
+	return func(vars Activation) (scalar, bool) {
+		val, found := vars.ResolveName(name)
+		if !found {
+			return scalar{}, false
+		}
+		return unboxScalar(val, kind)
+	}, kind, true
+}
+
+// unboxScalar converts a variable value into a scalar of the expected kind, returning false if
+// the value is not of the expected kind.
+func unboxScalar(val any, kind scalarKind) (scalar, bool) {
+	var v scalar
+	switch kind {
+	case scalarInt:
+		switch i := val.(type) {
+		case types.Int:
+			v.i = int64(i)
+		case int64:
+			v.i = i
+		case int:
+			v.i = int64(i)
+		case int32:
+			v.i = int64(i)
+		default:
+			return v, false
+		}
+	case scalarUint:
+		switch u := val.(type) {
+		case types.Uint:
+			v.u = uint64(u)
+		case uint64:
+			v.u = u
+		case uint:
+			v.u = uint64(u)
+		case uint32:
+			v.u = uint64(u)
+		default:
+			return v, false
+		}
+	case scalarDouble:
+		switch d := val.(type) {
+		case types.Double:
+			v.d = float64(d)
+		case float64:
+			v.d = d
+		case float32:
+			v.d = float64(d)
+		default:
+			return v, false
+		}
+	case scalarBool:
+		switch b := val.(type) {
+		case types.Bool:
+			v.b = bool(b)
+		case bool:
+			v.b = b
+		default:
+			return v, false
+		}
+	case scalarString:
+		switch s := val.(type) {
+		case types.String:
+			v.s = string(s)
+		case string:
+			v.s = s
+		default:
+			return v, false
+		}
+	}
+	return v, true
+}
+
+func (p *scalarPlanner) planScalarCall(e *exprpb.Expr, kind scalarKind) (scalarFunc, scalarKind, bool) {
+	call := e.GetCallExpr()
+	var argExprs []*exprpb.Expr
+	if call.GetTarget() != nil {
+		argExprs = append(argExprs, call.GetTarget())
+	}
+	argExprs = append(argExprs, call.GetArgs()...)
+	args := make([]scalarFunc, len(argExprs))
+	kinds := make([]scalarKind, len(argExprs))
+	for i, argExpr := range argExprs {
+		arg, argKind, ok := p.plan(argExpr)
+		if !ok {
+			return nil, 0, false
+		}
+		args[i], kinds[i] = arg, argKind
+	}
+
+	switch call.GetFunction() {
+	case operators.LogicalAnd:
+		return planScalarLogical(args[0], args[1], false), kind, true
+	case operators.LogicalOr:
+		return planScalarLogical(args[0], args[1], true), kind, true
+	case operators.Conditional:
+		return planScalarConditional(args[0], args[1], args[2]), kind, true
+	case operators.LogicalNot:
+		arg := args[0]
+		return func(vars Activation) (scalar, bool) {
+			v, ok := arg(vars)
+			return scalar{b: !v.b}, ok
+		}, kind, true
+	}
+
+	overloadIDs := p.refMap[e.GetId()].GetOverloadId()
+	if len(overloadIDs) != 1 {
+		return nil, 0, false
+	}
+	overload := overloadIDs[0]
+	var fn scalarFunc
+	switch len(args) {
+	case 1:
+		impl, found := scalarUnaryOps[overload]
+		if !found {
+			return nil, 0, false
+		}
+		arg := args[0]
+		fn = func(vars Activation) (scalar, bool) {
+			v, ok := arg(vars)
+			if !ok {
+				return v, false
+			}
+			return impl(v)
+		}
+	case 2:
+		impl, found := scalarBinaryOps[overload]
+		if !found {
+			switch overload {
+			case overloads.Equals, overloads.NotEquals:
+				if kinds[0] != kinds[1] {
+					return nil, 0, false
+				}
+				impl = scalarEquals(kinds[0], overload == overloads.NotEquals)
+			default:
+				return nil, 0, false
+			}
+		}
+		lhs, rhs := args[0], args[1]
+		fn = func(vars Activation) (scalar, bool) {
+			l, ok := lhs(vars)
+			if !ok {
+				return l, false
+			}
+			r, ok := rhs(vars)
+			if !ok {
+				return r, false
+			}
+			return impl(l, r)
+		}
+	default:
+		return nil, 0, false
+	}
+	return fn, kind, true
+}
+
+// planScalarLogical returns the short-circuiting logical or when `or` is true, and the logical
+// and otherwise. An argument which requires the fallback is resolved by the fallback, as the
+// commutative error handling of the logical operators depends on the other argument.
+func planScalarLogical(lhs, rhs scalarFunc, or bool) scalarFunc {
+	return func(vars Activation) (scalar, bool) {
+		l, ok := lhs(vars)
+		if !ok || l.b == or {
+			return l, ok
+		}
+		return rhs(vars)
+	}
+}
+
+func planScalarConditional(cond, truthy, falsy scalarFunc) scalarFunc {
+	return func(vars Activation) (scalar, bool) {
+		c, ok := cond(vars)
+		if !ok {
+			return c, false
+		}
+		if c.b {
+			return truthy(vars)
+		}
+		return falsy(vars)
+	}
+}
+
+func scalarEquals(kind scalarKind, negate bool) func(l, r scalar) (scalar, bool) {
+	var eq func(l, r scalar) bool
+	switch kind {
+	case scalarInt:
+		eq = func(l, r scalar) bool { return l.i == r.i }
+	case scalarUint:
+		eq = func(l, r scalar) bool { return l.u == r.u }
+	case scalarDouble:
+		eq = func(l, r scalar) bool { return l.d == r.d }
+	case scalarBool:
+		eq = func(l, r scalar) bool { return l.b == r.b }
+	default:
+		eq = func(l, r scalar) bool { return l.s == r.s }
+	}
+	return func(l, r scalar) (scalar, bool) {
+		return scalar{b: eq(l, r) != negate}, true
+	}
+}
+
+// scalarUnaryOps contains the unboxed implementations of unary standard library overloads.
+var scalarUnaryOps = map[string]func(v scalar) (scalar, bool){
+	overloads.NegateInt64: func(v scalar) (scalar, bool) {
+		return scalar{i: -v.i}, v.i != math.MinInt64
+	},
+	overloads.NegateDouble: func(v scalar) (scalar, bool) {
+		return scalar{d: -v.d}, true
+	},
+	overloads.SizeString:     scalarSize,
+	overloads.SizeStringInst: scalarSize,
+	overloads.IntToInt:       scalarIdentity,
+	overloads.UintToUint:     scalarIdentity,
+	overloads.DoubleToDouble: scalarIdentity,
+	overloads.StringToString: scalarIdentity,
+	overloads.BoolToBool:     scalarIdentity,
+	overloads.IntToDouble: func(v scalar) (scalar, bool) {
+		return scalar{d: float64(v.i)}, true
+	},
+	overloads.UintToDouble: func(v scalar) (scalar, bool) {
+		return scalar{d: float64(v.u)}, true
+	},
+	overloads.IntToUint: func(v scalar) (scalar, bool) {
+		return scalar{u: uint64(v.i)}, v.i >= 0
+	},
+	overloads.UintToInt: func(v scalar) (scalar, bool) {
+		return scalar{i: int64(v.u)}, v.u <= math.MaxInt64
+	},
+	overloads.DoubleToInt: func(v scalar) (scalar, bool) {
+		// The range check is false for NaN values.
+		if v.d > float64(math.MinInt64) && v.d < float64(math.MaxInt64) {
+			return scalar{i: int64(v.d)}, true
+		}
+		return scalar{}, false
+	},
+	overloads.DoubleToUint: func(v scalar) (scalar, bool) {
+		if v.d >= 0 && v.d < math.Ldexp(1.0, 64) {
+			return scalar{u: uint64(v.d)}, true
+		}
+		return scalar{}, false
+	},
+}
+
+func scalarSize(v scalar) (scalar, bool) {
+	return scalar{i: int64(utf8.RuneCountInString(v.s))}, true
+}
+
+func scalarIdentity(v scalar) (scalar, bool) {
+	return v, true
+}
+
+// scalarBinaryOps contains the unboxed implementations of binary standard library overloads.
+//
+// Operations which would produce an error, such as overflows and division by zero, report that
+// the fallback must be used so that the error is identical to that of the standard library.
+var scalarBinaryOps = map[string]func(l, r scalar) (scalar, bool){
+	overloads.AddInt64: func(l, r scalar) (scalar, bool) {
+		sum := l.i + r.i
+		// Overflow occurred if both operands have a sign which differs from the sign of the sum.
+		return scalar{i: sum}, (l.i^sum)&(r.i^sum) >= 0
+	},
+	overloads.AddUint64: func(l, r scalar) (scalar, bool) {
+		sum, carry := bits.Add64(l.u, r.u, 0)
+		return scalar{u: sum}, carry == 0
+	},
+	overloads.AddDouble: func(l, r scalar) (scalar, bool) {
+		return scalar{d: l.d + r.d}, true
+	},
+	overloads.AddString: func(l, r scalar) (scalar, bool) {
+		return scalar{s: l.s + r.s}, true
+	},
+	overloads.SubtractInt64: func(l, r scalar) (scalar, bool) {
+		diff := l.i - r.i
+		// Overflow occurred if the operands differ in sign and the difference differs in sign from
+		// the left operand.
+		return scalar{i: diff}, (l.i^r.i)&(l.i^diff) >= 0
+	},
+	overloads.SubtractUint64: func(l, r scalar) (scalar, bool) {
+		return scalar{u: l.u - r.u}, r.u <= l.u
+	},
+	overloads.SubtractDouble: func(l, r scalar) (scalar, bool) {
+		return scalar{d: l.d - r.d}, true
+	},
+	overloads.MultiplyInt64: func(l, r scalar) (scalar, bool) {
+		if (l.i == -1 && r.i == math.MinInt64) || (r.i == -1 && l.i == math.MinInt64) {
+			return scalar{}, false
+		}
+		product := l.i * r.i
+		return scalar{i: product}, l.i == 0 || product/l.i == r.i
+	},
+	overloads.MultiplyUint64: func(l, r scalar) (scalar, bool) {
+		hi, lo := bits.Mul64(l.u, r.u)
+		return scalar{u: lo}, hi == 0
+	},
+	overloads.MultiplyDouble: func(l, r scalar) (scalar, bool) {
+		return scalar{d: l.d * r.d}, true
+	},
+	overloads.DivideInt64: func(l, r scalar) (scalar, bool) {
+		if r.i == 0 || (l.i == math.MinInt64 && r.i == -1) {
+			return scalar{}, false
+		}
+		return scalar{i: l.i / r.i}, true
+	},
+	overloads.DivideUint64: func(l, r scalar) (scalar, bool) {
+		if r.u == 0 {
+			return scalar{}, false
+		}
+		return scalar{u: l.u / r.u}, true
+	},
+	overloads.DivideDouble: func(l, r scalar) (scalar, bool) {
+		return scalar{d: l.d / r.d}, true
+	},
+	overloads.ModuloInt64: func(l, r scalar) (scalar, bool) {
+		if r.i == 0 || (l.i == math.MinInt64 && r.i == -1) {
+			return scalar{}, false
+		}
+		return scalar{i: l.i % r.i}, true
+	},
+	overloads.ModuloUint64: func(l, r scalar) (scalar, bool) {
+		if r.u == 0 {
+			return scalar{}, false
+		}
+		return scalar{u: l.u % r.u}, true
+	},
+
+	overloads.LessInt64:          func(l, r scalar) (scalar, bool) { return scalar{b: l.i < r.i}, true },
+	overloads.LessEqualsInt64:    func(l, r scalar) (scalar, bool) { return scalar{b: l.i <= r.i}, true },
+	overloads.GreaterInt64:       func(l, r scalar) (scalar, bool) { return scalar{b: l.i > r.i}, true },
+	overloads.GreaterEqualsInt64: func(l, r scalar) (scalar, bool) { return scalar{b: l.i >= r.i}, true },
+
+	overloads.LessUint64:          func(l, r scalar) (scalar, bool) { return scalar{b: l.u < r.u}, true },
+	overloads.LessEqualsUint64:    func(l, r scalar) (scalar, bool) { return scalar{b: l.u <= r.u}, true },
+	overloads.GreaterUint64:       func(l, r scalar) (scalar, bool) { return scalar{b: l.u > r.u}, true },
+	overloads.GreaterEqualsUint64: func(l, r scalar) (scalar, bool) { return scalar{b: l.u >= r.u}, true },
+
+	// NaN values cannot be ordered and produce an error in the standard library.
+	overloads.LessDouble: func(l, r scalar) (scalar, bool) {
+		return scalar{b: l.d < r.d}, !math.IsNaN(l.d) && !math.IsNaN(r.d)
+	},
+	overloads.LessEqualsDouble: func(l, r scalar) (scalar, bool) {
+		return scalar{b: l.d <= r.d}, !math.IsNaN(l.d) && !math.IsNaN(r.d)
+	},
+	overloads.GreaterDouble: func(l, r scalar) (scalar, bool) {
+		return scalar{b: l.d > r.d}, !math.IsNaN(l.d) && !math.IsNaN(r.d)
+	},
+	overloads.GreaterEqualsDouble: func(l, r scalar) (scalar, bool) {
+		return scalar{b: l.d >= r.d}, !math.IsNaN(l.d) && !math.IsNaN(r.d)
+	},
+
+	overloads.LessString:          func(l, r scalar) (scalar, bool) { return scalar{b: l.s < r.s}, true },
+	overloads.LessEqualsString:    func(l, r scalar) (scalar, bool) { return scalar{b: l.s <= r.s}, true },
+	overloads.GreaterString:       func(l, r scalar) (scalar, bool) { return scalar{b: l.s > r.s}, true },
+	overloads.GreaterEqualsString: func(l, r scalar) (scalar, bool) { return scalar{b: l.s >= r.s}, true },
+
+	overloads.LessBool:          func(l, r scalar) (scalar, bool) { return scalar{b: !l.b && r.b}, true },
+	overloads.LessEqualsBool:    func(l, r scalar) (scalar, bool) { return scalar{b: !l.b || r.b}, true },
+	overloads.GreaterBool:       func(l, r scalar) (scalar, bool) { return scalar{b: l.b && !r.b}, true },
+	overloads.GreaterEqualsBool: func(l, r scalar) (scalar, bool) { return scalar{b: l.b || !r.b}, true },
+
+	overloads.ContainsString: func(l, r scalar) (scalar, bool) {
+		return scalar{b: strings.Contains(l.s, r.s)}, true
+	},
+	overloads.StartsWithString: func(l, r scalar) (scalar, bool) {
+		return scalar{b: strings.HasPrefix(l.s, r.s)}, true
+	},
+	overloads.EndsWithString: func(l, r scalar) (scalar, bool) {
+		return scalar{b: strings.HasSuffix(l.s, r.s)}, true
+	},
+}
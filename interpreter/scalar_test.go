@@ -0,0 +1,161 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/checker"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/containers"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/interpreter/functions"
+	"github.com/google/cel-go/parser"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+var scalarTestDecls = []*exprpb.Decl{
+	decls.NewVar("i", decls.Int),
+	decls.NewVar("j", decls.Int),
+	decls.NewVar("u", decls.Uint),
+	decls.NewVar("d", decls.Double),
+	decls.NewVar("b", decls.Bool),
+	decls.NewVar("s", decls.String),
+	decls.NewVar("l", decls.NewListType(decls.Int)),
+}
+
+func TestScalarInterpretable(t *testing.T) {
+	exprs := []string{
+		`i + j * 2 - i / j % 3`,
+		`-i < j && (u + 1u) * 2u > 3u`,
+		`d * 2.0 / 3.0 - -d >= 0.5 || b`,
+		`b ? s + "!" : s`,
+		`s.startsWith("ab") && s.endsWith("c") && s.contains("b") && size(s) == 3 && s.size() < 4`,
+		`s < "b" || s >= "z" || b > false || b <= true && b != b`,
+		`double(i) + double(u) > d && int(d) < 10 && uint(i) >= 0u && int(u) == i`,
+		`uint(d) > 1u && int(i) == i && double(d) == d && string(s) == s && bool(b) == b`,
+		`i / j == 0 || i == j`,
+		`i * j > 0 && u - 2u == 0u`,
+		`!(i > 0) || !(d < 0.0)`,
+		`i < 10 ? (j > 0 ? i : j) : -j`,
+	}
+	inputs := []map[string]any{
+		{"i": 1, "j": 2, "u": uint(2), "d": 1.5, "b": true, "s": "abc"},
+		{"i": int64(-7), "j": int32(3), "u": types.Uint(1), "d": float32(-2.5), "b": false, "s": types.String("zz")},
+		// Division by zero and negative conversions produce errors.
+		{"i": -1, "j": 0, "u": uint(0), "d": -1.0, "b": types.False, "s": ""},
+		// Overflow and NaN values produce errors.
+		{"i": math.MinInt64, "j": -1, "u": uint64(math.MaxUint64), "d": math.NaN(), "b": true, "s": "abc"},
+		{"i": math.MaxInt64, "j": math.MaxInt64, "u": uint(1) << 63, "d": math.Inf(1), "b": true, "s": "z"},
+		// Missing variables and variables of an unexpected type produce errors.
+		{"i": 1, "j": "2", "b": true},
+		{},
+	}
+	for _, expr := range exprs {
+		checked, fallback := scalarTestProgram(t, expr)
+		prg, ok := NewScalarInterpretable(checked, fallback)
+		if !ok {
+			t.Fatalf("NewScalarInterpretable(%q) was not eligible for scalar evaluation", expr)
+		}
+		for _, in := range inputs {
+			vars, err := NewActivation(in)
+			if err != nil {
+				t.Fatalf("NewActivation() failed: %v", err)
+			}
+			want := fallback.Eval(vars)
+			got := prg.Eval(vars)
+			if types.IsUnknownOrError(want) {
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%q.Eval(%v) got %v, wanted %v", expr, in, got, want)
+				}
+			} else if got.Equal(want) != types.True {
+				t.Errorf("%q.Eval(%v) got %v, wanted %v", expr, in, got, want)
+			}
+		}
+	}
+}
+
+func TestScalarInterpretableIneligible(t *testing.T) {
+	exprs := []string{
+		`size(l) > 0`,
+		`l[0] == i`,
+		`i in [1, 2, 3]`,
+		`string(i) == s`,
+		`dyn(i) == j`,
+		`i < d`,
+		`s.matches("a+")`,
+	}
+	for _, expr := range exprs {
+		checked, fallback := scalarTestProgram(t, expr)
+		if _, ok := NewScalarInterpretable(checked, fallback); ok {
+			t.Errorf("NewScalarInterpretable(%q) was eligible for scalar evaluation", expr)
+		}
+	}
+}
+
+func TestScalarInterpretableAllocs(t *testing.T) {
+	checked, fallback := scalarTestProgram(t, `i * 3 + j > 100 && s.startsWith("req") && d < 0.5`)
+	prg, ok := NewScalarInterpretable(checked, fallback)
+	if !ok {
+		t.Fatal("NewScalarInterpretable() was not eligible for scalar evaluation")
+	}
+	vars, err := NewActivation(map[string]any{"i": 100, "j": 7, "s": "request", "d": 0.25})
+	if err != nil {
+		t.Fatalf("NewActivation() failed: %v", err)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		if prg.Eval(vars) != types.True {
+			t.Fatal("prg.Eval() did not return true")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("prg.Eval() allocated %v times, wanted 0", allocs)
+	}
+}
+
+func scalarTestProgram(t *testing.T, expr string) (*exprpb.CheckedExpr, Interpretable) {
+	t.Helper()
+	cont := containers.DefaultContainer
+	reg := newTestRegistry(t)
+	env := newTestEnv(t, cont, reg)
+	if err := env.Add(scalarTestDecls...); err != nil {
+		t.Fatalf("env.Add() failed: %v", err)
+	}
+	p, err := parser.NewParser(parser.Macros(parser.AllMacros...))
+	if err != nil {
+		t.Fatalf("parser.NewParser() failed: %v", err)
+	}
+	src := common.NewTextSource(expr)
+	parsed, errs := p.Parse(src)
+	if len(errs.GetErrors()) != 0 {
+		t.Fatalf("Parse(%q) failed: %v", expr, errs.ToDisplayString())
+	}
+	checked, errs := checker.Check(parsed, src, env)
+	if len(errs.GetErrors()) != 0 {
+		t.Fatalf("Check(%q) failed: %v", expr, errs.ToDisplayString())
+	}
+	disp := NewDispatcher()
+	disp.Add(functions.StandardOverloads()...)
+	interp := NewInterpreter(disp, cont, reg, reg, NewAttributeFactory(cont, reg, reg))
+	fallback, err := interp.NewInterpretable(checked)
+	if err != nil {
+		t.Fatalf("NewInterpretable(%q) failed: %v", expr, err)
+	}
+	return checked, fallback
+}
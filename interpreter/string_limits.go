@@ -0,0 +1,217 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"fmt"
+	"regexp/syntax"
+
+	"github.com/google/cel-go/common/overloads"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// StringLimits bounds the inputs of the standard string functions `matches`, `contains`,
+// `startsWith`, and `endsWith`, so that pathological inputs are rejected before the functions do
+// any work. A zero limit leaves the corresponding input unbounded.
+type StringLimits struct {
+	// MaxInputLength is the maximum length in bytes of each string argument of the functions.
+	MaxInputLength int
+
+	// MaxRegexProgramSize is the maximum number of instructions of the compiled program of a
+	// regular expression passed to `matches`.
+	MaxRegexProgramSize int
+}
+
+// InputLengthError is the error produced when an argument of a standard string function exceeds
+// StringLimits.MaxInputLength.
+type InputLengthError struct {
+	// Function is the name of the function.
+	Function string
+
+	// Length is the length of the argument in bytes.
+	Length int
+
+	// Limit is the configured maximum length.
+	Limit int
+}
+
+// Error implements the error interface method.
+func (e *InputLengthError) Error() string {
+	return fmt.Sprintf("%s: input length %d exceeds limit %d", e.Function, e.Length, e.Limit)
+}
+
+// RegexSizeError is the error produced when a regular expression passed to `matches` compiles to a
+// program which exceeds StringLimits.MaxRegexProgramSize.
+type RegexSizeError struct {
+	// Pattern is the regular expression.
+	Pattern string
+
+	// Size is the number of instructions of the compiled program.
+	Size int
+
+	// Limit is the configured maximum size.
+	Limit int
+}
+
+// Error implements the error interface method.
+func (e *RegexSizeError) Error() string {
+	return fmt.Sprintf("matches: regex program size %d exceeds limit %d", e.Size, e.Limit)
+}
+
+// LimitStrings returns an InterpretableDecorator which enforces the limits on the string arguments
+// of the standard `matches`, `contains`, `startsWith`, and `endsWith` functions. Limits which are
+// exceeded produce an error value wrapping an *InputLengthError or a *RegexSizeError. Constant
+// arguments are written within the expression rather than supplied as inputs, and so their length
+// is not limited.
+//
+// Constant regular expressions are checked when the program is planned, in which case planning
+// fails with a *RegexSizeError. Calls whose arguments are all constant may have been evaluated by
+// the Optimize decorator, and so the decorator should be applied after it.
+func LimitStrings(limits StringLimits) InterpretableDecorator {
+	return func(i Interpretable) (Interpretable, error) {
+		call, ok := i.(InterpretableCall)
+		if !ok || !isLimitedStringCall(call) {
+			return i, nil
+		}
+		var apply func(Activation, []ref.Val) ref.Val
+		switch inst := call.(type) {
+		case *evalBinary:
+			apply = func(_ Activation, argVals []ref.Val) ref.Val {
+				return inst.apply(argVals[0], argVals[1])
+			}
+		case *evalVarArgs:
+			apply = func(_ Activation, argVals []ref.Val) ref.Val {
+				return inst.apply(argVals)
+			}
+		case callApplier:
+			apply = inst.applyArgs
+		default:
+			return i, nil
+		}
+		args := call.Args()
+		checkLength := make([]bool, len(args))
+		for i, arg := range args {
+			_, isConst := arg.(InterpretableConst)
+			checkLength[i] = !isConst && limits.MaxInputLength > 0
+		}
+		checkPattern := call.Function() == overloads.Matches && limits.MaxRegexProgramSize > 0
+		if pattern, isConst := args[1].(InterpretableConst); isConst && checkPattern {
+			if p, isStr := pattern.Value().(types.String); isStr {
+				if err := limits.checkRegex(string(p)); err != nil {
+					return nil, err
+				}
+				checkPattern = false
+			}
+		}
+		return &evalLimitedStringCall{
+			InterpretableCall: call,
+			limits:            limits,
+			checkLength:       checkLength,
+			checkPattern:      checkPattern,
+			apply:             apply,
+		}, nil
+	}
+}
+
+// isLimitedStringCall returns whether the call may invoke one of the limited standard functions.
+func isLimitedStringCall(call InterpretableCall) bool {
+	var stringOverload string
+	switch call.Function() {
+	case overloads.Matches:
+		stringOverload = overloads.MatchesString
+	case overloads.Contains:
+		stringOverload = overloads.ContainsString
+	case overloads.StartsWith:
+		stringOverload = overloads.StartsWithString
+	case overloads.EndsWith:
+		stringOverload = overloads.EndsWithString
+	default:
+		return false
+	}
+	switch call.OverloadID() {
+	case "", call.Function(), stringOverload:
+		return len(call.Args()) == 2
+	}
+	return false
+}
+
+// evalLimitedStringCall evaluates a call to a standard string function after checking its string
+// arguments against the limits.
+type evalLimitedStringCall struct {
+	InterpretableCall
+	limits StringLimits
+	// checkLength indicates which of the arguments must be checked during evaluation as they are
+	// not constants.
+	checkLength []bool
+	// checkPattern indicates whether the regular expression must be checked during evaluation as
+	// it is not a constant.
+	checkPattern bool
+	apply        func(Activation, []ref.Val) ref.Val
+}
+
+// Eval implements the Interpretable interface method.
+func (call *evalLimitedStringCall) Eval(ctx Activation) ref.Val {
+	args := call.Args()
+	argVals := make([]ref.Val, len(args))
+	for i, arg := range args {
+		argVals[i] = arg.Eval(ctx)
+		if types.IsUnknownOrError(argVals[i]) {
+			return argVals[i]
+		}
+	}
+	return call.applyArgs(ctx, argVals)
+}
+
+// applyArgs implements the callApplier interface method.
+func (call *evalLimitedStringCall) applyArgs(ctx Activation, argVals []ref.Val) ref.Val {
+	for i, arg := range argVals {
+		if !call.checkLength[i] {
+			continue
+		}
+		if s, isStr := arg.(types.String); isStr && len(s) > call.limits.MaxInputLength {
+			return types.WrapErr(&InputLengthError{
+				Function: call.Function(),
+				Length:   len(s),
+				Limit:    call.limits.MaxInputLength,
+			})
+		}
+	}
+	if call.checkPattern {
+		if p, isStr := argVals[1].(types.String); isStr {
+			if err := call.limits.checkRegex(string(p)); err != nil {
+				return types.WrapErr(err)
+			}
+		}
+	}
+	return call.apply(ctx, argVals)
+}
+
+// checkRegex returns a *RegexSizeError if the pattern compiles to a program larger than the
+// limit. Invalid patterns are left to be reported by the implementation of `matches`.
+func (limits StringLimits) checkRegex(pattern string) error {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return nil
+	}
+	if len(prog.Inst) > limits.MaxRegexProgramSize {
+		return &RegexSizeError{Pattern: pattern, Size: len(prog.Inst), Limit: limits.MaxRegexProgramSize}
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// NodeTimings records the wall time spent evaluating each expression node over a series of
+// evaluations, indexed by expression id.
+//
+// The duration of a node includes the time spent evaluating its subexpressions. The distribution
+// of the durations of each node is approximated by a uniform sample of bounded size, so the
+// memory used does not grow with the number of evaluations.
+//
+// NodeTimings are safe for concurrent use.
+type NodeTimings struct {
+	mu         sync.Mutex
+	sampleSize int
+	rand       *rand.Rand
+	nodes      map[int64]*nodeTiming
+}
+
+// NodeTiming summarizes the durations recorded for a single expression node.
+type NodeTiming struct {
+	// Count is the number of times the node was evaluated.
+	Count uint64
+
+	// Total and Max are the sum and the maximum of the durations of the evaluations.
+	Total time.Duration
+	Max   time.Duration
+
+	// Samples holds a uniform sample of the durations of the evaluations in ascending order.
+	Samples []time.Duration
+}
+
+// Mean returns the mean duration of the evaluations of the node.
+func (n NodeTiming) Mean() time.Duration {
+	if n.Count == 0 {
+		return 0
+	}
+	return n.Total / time.Duration(n.Count)
+}
+
+// Quantile returns the estimated duration below which the given fraction of evaluations of the
+// node completed, e.g. 0.99 for the 99th percentile.
+func (n NodeTiming) Quantile(q float64) time.Duration {
+	if len(n.Samples) == 0 {
+		return 0
+	}
+	idx := int(q * float64(len(n.Samples)-1))
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(n.Samples) {
+		idx = len(n.Samples) - 1
+	}
+	return n.Samples[idx]
+}
+
+type nodeTiming struct {
+	count   uint64
+	total   time.Duration
+	max     time.Duration
+	samples []time.Duration
+}
+
+// NewNodeTimings returns an empty NodeTimings which retains at most sampleSize durations per
+// expression node.
+func NewNodeTimings(sampleSize int) *NodeTimings {
+	if sampleSize <= 0 {
+		sampleSize = 1
+	}
+	return &NodeTimings{
+		sampleSize: sampleSize,
+		rand:       rand.New(rand.NewSource(time.Now().UnixNano())),
+		nodes:      map[int64]*nodeTiming{},
+	}
+}
+
+// IDs returns the ids of the expression nodes with recorded durations in ascending order.
+func (t *NodeTimings) IDs() []int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ids := make([]int64, 0, len(t.nodes))
+	for id := range t.nodes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Node returns the summary of the durations recorded for the expression id, if any.
+func (t *NodeTimings) Node(id int64) (NodeTiming, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n, found := t.nodes[id]
+	if !found {
+		return NodeTiming{}, false
+	}
+	samples := make([]time.Duration, len(n.samples))
+	copy(samples, n.samples)
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	return NodeTiming{Count: n.count, Total: n.total, Max: n.max, Samples: samples}, true
+}
+
+// record adds a duration for the expression id, using reservoir sampling to retain a uniform
+// sample of the durations.
+func (t *NodeTimings) record(id int64, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n, found := t.nodes[id]
+	if !found {
+		n = &nodeTiming{}
+		t.nodes[id] = n
+	}
+	n.count++
+	n.total += d
+	if d > n.max {
+		n.max = d
+	}
+	if len(n.samples) < t.sampleSize {
+		n.samples = append(n.samples, d)
+		return
+	}
+	if idx := t.rand.Int63n(int64(n.count)); idx < int64(t.sampleSize) {
+		n.samples[idx] = d
+	}
+}
+
+// TimeNodes returns an InterpretableDecorator which records the wall time of the evaluation of
+// each non-constant expression node within the NodeTimings. Evaluations in warm-up mode are not
+// timed.
+//
+// The decorator should be applied after all other decorators, as the decorators which inspect
+// the type of the Interpretable they decorate will not recognize a timed Interpretable.
+func TimeNodes(timings *NodeTimings) InterpretableDecorator {
+	return func(i Interpretable) (Interpretable, error) {
+		switch inst := i.(type) {
+		case InterpretableConst, *evalTimed, *evalTimedAttr, *evalTimedConstructor:
+			return i, nil
+		case InterpretableAttribute:
+			return &evalTimedAttr{InterpretableAttribute: inst, timings: timings}, nil
+		case InterpretableConstructor:
+			return &evalTimedConstructor{InterpretableConstructor: inst, timings: timings}, nil
+		default:
+			return &evalTimed{Interpretable: i, timings: timings}, nil
+		}
+	}
+}
+
+type evalTimed struct {
+	Interpretable
+	timings *NodeTimings
+}
+
+// Eval implements the Interpretable interface method.
+func (e *evalTimed) Eval(vars Activation) ref.Val {
+	start := time.Now()
+	val := e.Interpretable.Eval(vars)
+	if !isWarmup(vars) {
+		e.timings.record(e.ID(), time.Since(start))
+	}
+	return val
+}
+
+// evalTimedAttr times an InterpretableAttribute, which must implement the InterpretableAttribute
+// interface by proxy since it may be selected against at a later stage in program planning.
+type evalTimedAttr struct {
+	InterpretableAttribute
+	timings *NodeTimings
+}
+
+// AddQualifier implements the InterpretableAttribute interface method.
+func (e *evalTimedAttr) AddQualifier(q Qualifier) (Attribute, error) {
+	_, err := e.InterpretableAttribute.AddQualifier(q)
+	return e, err
+}
+
+// Eval implements the Interpretable interface method.
+func (e *evalTimedAttr) Eval(vars Activation) ref.Val {
+	start := time.Now()
+	val := e.InterpretableAttribute.Eval(vars)
+	if !isWarmup(vars) {
+		e.timings.record(e.ID(), time.Since(start))
+	}
+	return val
+}
+
+type evalTimedConstructor struct {
+	InterpretableConstructor
+	timings *NodeTimings
+}
+
+// Eval implements the Interpretable interface method.
+func (e *evalTimedConstructor) Eval(vars Activation) ref.Val {
+	start := time.Now()
+	val := e.InterpretableConstructor.Eval(vars)
+	if !isWarmup(vars) {
+		e.timings.record(e.ID(), time.Since(start))
+	}
+	return val
+}
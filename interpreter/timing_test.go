@@ -0,0 +1,77 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/types"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestTimeNodes(t *testing.T) {
+	timings := NewNodeTimings(4)
+	prg, _, err := program(t, &testCase{
+		expr: `names.exists(n, n.startsWith('a')) && size(names) > 1`,
+		env: []*exprpb.Decl{
+			decls.NewVar("names", decls.NewListType(decls.String)),
+		},
+	}, TimeNodes(timings))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		out := prg.Eval(mustActivation(t, map[string]any{"names": []string{"bob", "alice"}}))
+		if out != types.True {
+			t.Fatalf("prg.Eval() got %v, wanted true", out)
+		}
+	}
+	root, found := timings.Node(prg.ID())
+	if !found {
+		t.Fatalf("timings.Node(%d) not found", prg.ID())
+	}
+	if root.Count != 10 || len(root.Samples) != 4 {
+		t.Errorf("root timing got count %d with %d samples, wanted 10 with 4", root.Count, len(root.Samples))
+	}
+	if root.Max < root.Quantile(0.5) || root.Mean() > root.Max {
+		t.Errorf("root timing got max %v, median %v, mean %v", root.Max, root.Quantile(0.5), root.Mean())
+	}
+	// Constants are not timed, while each of the nodes within the comprehension is.
+	for _, id := range timings.IDs() {
+		n, _ := timings.Node(id)
+		if n.Count == 0 || n.Total < 0 {
+			t.Errorf("timings.Node(%d) got %v", id, n)
+		}
+	}
+	if len(timings.IDs()) < 5 {
+		t.Errorf("timings.IDs() got %v, wanted the non-constant nodes", timings.IDs())
+	}
+}
+
+func TestNodeTimingQuantile(t *testing.T) {
+	n := NodeTiming{Samples: []time.Duration{1, 2, 3, 4, 5}}
+	if q := n.Quantile(0.5); q != 3 {
+		t.Errorf("Quantile(0.5) got %v, wanted 3", q)
+	}
+	if q := n.Quantile(1); q != 5 {
+		t.Errorf("Quantile(1) got %v, wanted 5", q)
+	}
+	if q := (NodeTiming{}).Quantile(0.5); q != 0 {
+		t.Errorf("Quantile(0.5) of no samples got %v, wanted 0", q)
+	}
+}
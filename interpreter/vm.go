@@ -0,0 +1,329 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"sync"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// LowerToBytecode flattens a planned Interpretable tree into a linear sequence of instructions
+// executed by a stack machine, removing the per-node interface dispatch of tree-walking evaluation.
+//
+// Constants, logical operators, equality, function calls, and list literals are lowered into
+// bytecode. All other nodes, such as attributes and comprehensions, are executed as opaque
+// instructions which evaluate the node directly, so the result of evaluation is identical to
+// that of the input Interpretable.
+//
+// Lowering should be the last step of program construction, as decorators which inspect or wrap
+// the planned nodes cannot see beyond the lowered program.
+func LowerToBytecode(i Interpretable) Interpretable {
+	if _, isBytecode := i.(*evalBytecode); isBytecode {
+		return i
+	}
+	c := &bytecodeCompiler{prog: &evalBytecode{id: i.ID()}}
+	c.lower(i)
+	prog := c.prog
+	// If nothing could be lowered, then there's no benefit to the bytecode representation.
+	if len(prog.code) == 1 && prog.code[0].op == opEval {
+		return i
+	}
+	prog.stacks = &sync.Pool{
+		New: func() any {
+			return &vmStack{vals: make([]ref.Val, prog.maxDepth)}
+		},
+	}
+	return prog
+}
+
+type vmOp uint8
+
+const (
+	// opConst pushes the constant at index `arg`.
+	opConst vmOp = iota
+	// opEval pushes the result of evaluating the node at index `arg`.
+	opEval
+	// opUnary pops one argument and pushes the result of the unary call at index `arg`.
+	opUnary
+	// opBinary pops two arguments and pushes the result of the binary call at index `arg`.
+	opBinary
+	// opVarArgs pops the call's arguments and pushes the result of the call at index `arg`.
+	opVarArgs
+	// opList pops the list elements and pushes the list literal at index `arg`.
+	opList
+	// opEq pops two values and pushes whether they are equal.
+	opEq
+	// opNe pops two values and pushes whether they are not equal.
+	opNe
+	// opOrShort jumps to `arg` if the top of the stack is `true`.
+	opOrShort
+	// opOr pops two values and pushes their logical or.
+	opOr
+	// opAndShort jumps to `arg` if the top of the stack is `false`.
+	opAndShort
+	// opAnd pops two values and pushes their logical and.
+	opAnd
+	// opStrict replaces the top `depth` values with the topmost value and jumps to `arg` when the
+	// topmost value is unknown or error.
+	opStrict
+	// opEqConst replaces the top of the stack with whether it equals the constant at index `arg`.
+	opEqConst
+	// opNeConst replaces the top of the stack with whether it differs from the constant at `arg`.
+	opNeConst
+	// opBinaryConst replaces the top of the stack with the result of the binary call at index
+	// `arg` whose right-hand operand is the constant at index `depth`.
+	opBinaryConst
+)
+
+type vmInstr struct {
+	op    vmOp
+	arg   int32
+	depth int32
+}
+
+type evalBytecode struct {
+	id       int64
+	code     []vmInstr
+	consts   []ref.Val
+	nodes    []Interpretable
+	unaries  []*evalUnary
+	binaries []*evalBinary
+	varArgs  []*evalVarArgs
+	lists    []*evalList
+	maxDepth int
+	stacks   *sync.Pool
+}
+
+// vmLocalStackSize is the maximum operand stack depth for which the stack is allocated within the
+// Eval call frame rather than from a pool.
+const vmLocalStackSize = 16
+
+type vmStack struct {
+	vals []ref.Val
+}
+
+// ID implements the Interpretable interface method.
+func (b *evalBytecode) ID() int64 {
+	return b.id
+}
+
+// Eval implements the Interpretable interface method.
+func (b *evalBytecode) Eval(ctx Activation) ref.Val {
+	// Use a stack allocated array for the operand stack when possible as this avoids both the
+	// synchronization cost of the pool and the write barriers of a heap-allocated slice.
+	var local [vmLocalStackSize]ref.Val
+	var vals []ref.Val
+	if b.maxDepth <= vmLocalStackSize {
+		vals = local[:]
+	} else {
+		stack := b.stacks.Get().(*vmStack)
+		defer b.releaseStack(stack)
+		vals = stack.vals
+	}
+	sp := 0
+	code := b.code
+	for pc := 0; pc < len(code); pc++ {
+		in := &code[pc]
+		switch in.op {
+		case opConst:
+			vals[sp] = b.consts[in.arg]
+			sp++
+		case opEval:
+			vals[sp] = b.nodes[in.arg].Eval(ctx)
+			sp++
+		case opUnary:
+			vals[sp-1] = b.unaries[in.arg].apply(vals[sp-1])
+		case opBinary:
+			sp--
+			vals[sp-1] = b.binaries[in.arg].apply(vals[sp-1], vals[sp])
+		case opVarArgs:
+			fn := b.varArgs[in.arg]
+			argc := len(fn.args)
+			args := make([]ref.Val, argc)
+			copy(args, vals[sp-argc:sp])
+			sp -= argc
+			vals[sp] = fn.apply(args)
+			sp++
+		case opList:
+			l := b.lists[in.arg]
+			n := len(l.elems)
+			elems := make([]ref.Val, n)
+			copy(elems, vals[sp-n:sp])
+			sp -= n
+			vals[sp] = l.adapter.NativeToValue(elems)
+			sp++
+		case opEq:
+			sp--
+			vals[sp-1] = equals(vals[sp-1], vals[sp])
+		case opNe:
+			sp--
+			vals[sp-1] = notEquals(vals[sp-1], vals[sp])
+		case opOrShort:
+			if vals[sp-1] == types.True {
+				pc = int(in.arg) - 1
+			}
+		case opOr:
+			sp--
+			vals[sp-1] = logicalOr(vals[sp-1], vals[sp])
+		case opAndShort:
+			if vals[sp-1] == types.False {
+				pc = int(in.arg) - 1
+			}
+		case opAnd:
+			sp--
+			vals[sp-1] = logicalAnd(vals[sp-1], vals[sp])
+		case opEqConst:
+			vals[sp-1] = equals(vals[sp-1], b.consts[in.arg])
+		case opNeConst:
+			vals[sp-1] = notEquals(vals[sp-1], b.consts[in.arg])
+		case opBinaryConst:
+			vals[sp-1] = b.binaries[in.arg].apply(vals[sp-1], b.consts[in.depth])
+		case opStrict:
+			top := vals[sp-1]
+			if types.IsUnknownOrError(top) {
+				sp -= int(in.depth)
+				vals[sp] = top
+				sp++
+				pc = int(in.arg) - 1
+			}
+		}
+	}
+	return vals[0]
+}
+
+// releaseStack clears references to intermediate values and returns the stack to the pool.
+func (b *evalBytecode) releaseStack(stack *vmStack) {
+	for i := range stack.vals {
+		stack.vals[i] = nil
+	}
+	b.stacks.Put(stack)
+}
+
+type bytecodeCompiler struct {
+	prog  *evalBytecode
+	depth int
+}
+
+func (c *bytecodeCompiler) lower(i Interpretable) {
+	switch node := i.(type) {
+	case *evalConst:
+		c.emit(opConst, c.addConst(node.val), 1)
+	case *evalOr:
+		c.lower(node.lhs)
+		short := c.emit(opOrShort, 0, 0)
+		c.lower(node.rhs)
+		c.emit(opOr, 0, -1)
+		c.patch(short)
+	case *evalAnd:
+		c.lower(node.lhs)
+		short := c.emit(opAndShort, 0, 0)
+		c.lower(node.rhs)
+		c.emit(opAnd, 0, -1)
+		c.patch(short)
+	case *evalEq:
+		c.lower(node.lhs)
+		if rhs, isConst := node.rhs.(*evalConst); isConst {
+			c.emit(opEqConst, c.addConst(rhs.val), 0)
+			return
+		}
+		c.lower(node.rhs)
+		c.emit(opEq, 0, -1)
+	case *evalNe:
+		c.lower(node.lhs)
+		if rhs, isConst := node.rhs.(*evalConst); isConst {
+			c.emit(opNeConst, c.addConst(rhs.val), 0)
+			return
+		}
+		c.lower(node.rhs)
+		c.emit(opNe, 0, -1)
+	case *evalUnary:
+		c.lower(node.arg)
+		c.emit(opUnary, int32(len(c.prog.unaries)), 0)
+		c.prog.unaries = append(c.prog.unaries, node)
+	case *evalBinary:
+		c.lower(node.lhs)
+		if rhs, isConst := node.rhs.(*evalConst); isConst {
+			pc := c.emit(opBinaryConst, int32(len(c.prog.binaries)), 0)
+			c.prog.code[pc].depth = c.addConst(rhs.val)
+			c.prog.binaries = append(c.prog.binaries, node)
+			return
+		}
+		c.lower(node.rhs)
+		c.emit(opBinary, int32(len(c.prog.binaries)), -1)
+		c.prog.binaries = append(c.prog.binaries, node)
+	case *evalVarArgs:
+		c.lowerStrictSequence(node.args, !node.nonStrict, func() {
+			c.emit(opVarArgs, int32(len(c.prog.varArgs)), 1-len(node.args))
+			c.prog.varArgs = append(c.prog.varArgs, node)
+		})
+	case *evalList:
+		if node.hasOptionals {
+			c.lowerNode(i)
+			return
+		}
+		c.lowerStrictSequence(node.elems, true, func() {
+			c.emit(opList, int32(len(c.prog.lists)), 1-len(node.elems))
+			c.prog.lists = append(c.prog.lists, node)
+		})
+	default:
+		c.lowerNode(i)
+	}
+}
+
+// lowerStrictSequence lowers a sequence of arguments, followed by the instruction which consumes
+// them. When strict, evaluation terminates with the first unknown or error argument in the same
+// manner as the tree-walking evaluation of the node.
+func (c *bytecodeCompiler) lowerStrictSequence(args []Interpretable, strict bool, consume func()) {
+	var checks []int
+	for n, arg := range args {
+		c.lower(arg)
+		if strict {
+			checks = append(checks, len(c.prog.code))
+			c.prog.code = append(c.prog.code, vmInstr{op: opStrict, depth: int32(n + 1)})
+		}
+	}
+	consume()
+	for _, pc := range checks {
+		c.patch(pc)
+	}
+}
+
+func (c *bytecodeCompiler) addConst(val ref.Val) int32 {
+	c.prog.consts = append(c.prog.consts, val)
+	return int32(len(c.prog.consts) - 1)
+}
+
+func (c *bytecodeCompiler) lowerNode(i Interpretable) {
+	c.emit(opEval, int32(len(c.prog.nodes)), 1)
+	c.prog.nodes = append(c.prog.nodes, i)
+}
+
+// emit appends an instruction which changes the stack depth by `delta` and returns its position.
+func (c *bytecodeCompiler) emit(op vmOp, arg int32, delta int) int {
+	pc := len(c.prog.code)
+	c.prog.code = append(c.prog.code, vmInstr{op: op, arg: arg})
+	c.depth += delta
+	if c.depth > c.prog.maxDepth {
+		c.prog.maxDepth = c.depth
+	}
+	return pc
+}
+
+// patch sets the jump target of the instruction at `pc` to the next instruction to be emitted.
+func (c *bytecodeCompiler) patch(pc int) {
+	c.prog.code[pc].arg = int32(len(c.prog.code))
+}
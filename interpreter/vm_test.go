@@ -0,0 +1,193 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter/functions"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestLowerToBytecode(t *testing.T) {
+	for _, tst := range append(testData, bytecodeTestCases...) {
+		tc := tst
+		if tc.progErr != "" {
+			continue
+		}
+		t.Run(tc.name, func(t *testing.T) {
+			for _, opts := range [][]InterpretableDecorator{{}, {Optimize()}} {
+				prg, vars, err := program(t, &tc, opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := prg.Eval(vars)
+				got := LowerToBytecode(prg).Eval(vars)
+				if types.IsUnknownOrError(want) {
+					if !reflect.DeepEqual(got, want) {
+						t.Errorf("LowerToBytecode().Eval() got %v, wanted %v", got, want)
+					}
+				} else if got.Equal(want) != types.True {
+					t.Errorf("LowerToBytecode().Eval() got %v, wanted %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLowerToBytecodeNoop(t *testing.T) {
+	prg, _, err := program(t, &testCase{
+		expr: `a.b`,
+		env:  []*exprpb.Decl{decls.NewVar("a", decls.NewMapType(decls.String, decls.Int))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if LowerToBytecode(prg) != prg {
+		t.Error("LowerToBytecode() of a single attribute produced a new Interpretable")
+	}
+	lowered := LowerToBytecode(NewConstValue(1, types.True))
+	if LowerToBytecode(lowered) != lowered {
+		t.Error("LowerToBytecode() of a lowered Interpretable produced a new Interpretable")
+	}
+}
+
+var bytecodeTestCases = []testCase{
+	{
+		name: "bytecode_strict_call_error",
+		expr: `[1, 2, 3][x] + 1 > 1 || 1/x == 0`,
+		env:  []*exprpb.Decl{decls.NewVar("x", decls.Int)},
+		in:   map[string]any{"x": 0},
+		err:  "division by zero",
+	},
+	{
+		name: "bytecode_strict_list_error",
+		expr: `size([1, 2/x, 3]) == 3`,
+		env:  []*exprpb.Decl{decls.NewVar("x", decls.Int)},
+		in:   map[string]any{"x": 0},
+		err:  "division by zero",
+	},
+	{
+		name: "bytecode_strict_varargs_error",
+		expr: `concat('a', string(1/x), 'b') == 'ab'`,
+		env: []*exprpb.Decl{
+			decls.NewVar("x", decls.Int),
+			decls.NewFunction("concat",
+				decls.NewOverload("concat_string_string_string",
+					[]*exprpb.Type{decls.String, decls.String, decls.String}, decls.String)),
+		},
+		funcs: []*functions.Overload{
+			{
+				Operator: "concat",
+				Function: concatStrings,
+			},
+		},
+		in:  map[string]any{"x": 0},
+		err: "division by zero",
+	},
+	{
+		name: "bytecode_varargs",
+		expr: `concat('a', string(x), 'b') == 'a1b'`,
+		env: []*exprpb.Decl{
+			decls.NewVar("x", decls.Int),
+			decls.NewFunction("concat",
+				decls.NewOverload("concat_string_string_string",
+					[]*exprpb.Type{decls.String, decls.String, decls.String}, decls.String)),
+		},
+		funcs: []*functions.Overload{
+			{
+				Operator: "concat",
+				Function: concatStrings,
+			},
+		},
+		in: map[string]any{"x": 1},
+	},
+	{
+		name: "bytecode_logical_error_absorbed",
+		expr: `(1/x == 0 && false) || (true || 1/x == 0)`,
+		env:  []*exprpb.Decl{decls.NewVar("x", decls.Int)},
+		in:   map[string]any{"x": 0},
+	},
+	{
+		name: "bytecode_nested_calls",
+		expr: `'abc'.size() - x == 2 && !(x != 1) && -x < 0`,
+		env:  []*exprpb.Decl{decls.NewVar("x", decls.Int)},
+		in:   map[string]any{"x": 1},
+	},
+}
+
+func concatStrings(args ...ref.Val) ref.Val {
+	var sb strings.Builder
+	for _, arg := range args {
+		sb.WriteString(string(arg.(types.String)))
+	}
+	return types.String(sb.String())
+}
+
+func BenchmarkLowerToBytecode(b *testing.B) {
+	terms := make([]string, 50)
+	for i := range terms {
+		terms[i] = fmt.Sprintf("x * %d", i+1)
+	}
+	cmps := make([]string, 50)
+	for i := range cmps {
+		cmps[i] = fmt.Sprintf("x != %d", -i-1)
+	}
+	benchmarks := []testCase{
+		{
+			name: "arithmetic",
+			expr: strings.Join(terms, " + ") + " > 0",
+			env:  []*exprpb.Decl{decls.NewVar("x", decls.Int)},
+			in:   map[string]any{"x": 1},
+		},
+		{
+			name: "boolean_chain",
+			expr: strings.Join(cmps, " && "),
+			env:  []*exprpb.Decl{decls.NewVar("x", decls.Int)},
+			in:   map[string]any{"x": 1},
+		},
+	}
+	for _, bm := range benchmarks {
+		tc := bm
+		prg, vars, err := program(b, &tc)
+		if err != nil {
+			b.Fatal(err)
+		}
+		progs := map[string]Interpretable{
+			"tree":     prg,
+			"bytecode": LowerToBytecode(prg),
+		}
+		for mode, p := range progs {
+			eval := p
+			b.Run(tc.name+"/"+mode, func(b *testing.B) {
+				b.ReportAllocs()
+				var out ref.Val
+				for i := 0; i < b.N; i++ {
+					out = eval.Eval(vars)
+				}
+				if out != types.True {
+					b.Fatalf("Eval() got %v, wanted true", out)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+// NewWarmupActivation returns an Activation which evaluates a program against the input `vars` in
+// warm-up mode, which initializes the internals of the program without the effects of an ordinary
+// evaluation: the values of the evaluation steps are not patched, guarded calls are not guarded,
+// memoized calls are not memoized, and the results of subexpressions are neither cached by
+// incremental evaluation nor timed.
+func NewWarmupActivation(vars Activation) Activation {
+	return &warmupActivation{Activation: vars}
+}
+
+// warmupActivation exposes the special `#warmup` variable so that it may be found from within
+// nested activations such as those of comprehensions.
+type warmupActivation struct {
+	Activation
+}
+
+// ResolveName implements the Activation interface method.
+func (a *warmupActivation) ResolveName(name string) (any, bool) {
+	if name == "#warmup" {
+		return true, true
+	}
+	return a.Activation.ResolveName(name)
+}
+
+// Parent implements the Activation interface method.
+func (a *warmupActivation) Parent() Activation {
+	return a.Activation
+}
+
+// isWarmup returns whether the activation evaluates a program in warm-up mode.
+func isWarmup(vars Activation) bool {
+	if vars == nil {
+		return false
+	}
+	_, found := vars.ResolveName("#warmup")
+	return found
+}
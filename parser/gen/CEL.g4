@@ -55,6 +55,7 @@ member
     | member op='.' (opt='?')? id=IDENTIFIER                        # Select
     | member op='.' id=IDENTIFIER open='(' args=exprList? ')'       # MemberCall
     | member op='[' (opt='?')? index=expr ']'                       # Index
+    | member op='[' lower=expr? ':' upper=expr? (':' step=expr?)? ']' # Slice
     ;
 
 primary
@@ -72,7 +73,12 @@ exprList
     ;
 
 listInit
-    : elems+=optExpr (',' elems+=optExpr)*
+    : elems+=listElement (',' elems+=listElement)*
+    ;
+
+listElement
+    : (opt='?')? e=expr
+    | spread='...' e=expr
     ;
 
 fieldInitializerList
@@ -84,7 +90,12 @@ optField
     ;
 
 mapInitializerList
-    : keys+=optExpr cols+=':' values+=expr (',' keys+=optExpr cols+=':' values+=expr)*
+    : entries+=mapEntry (',' entries+=mapEntry)*
+    ;
+
+mapEntry
+    : key=optExpr col=':' value=expr
+    | spread='...' e=expr
     ;
 
 optExpr
@@ -198,3 +209,4 @@ STRING
 BYTES : ('b' | 'B') STRING;
 
 IDENTIFIER : (LETTER | '_') ( LETTER | DIGIT | '_')*;
+ELLIPSIS : '...';
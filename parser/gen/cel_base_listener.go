@@ -98,6 +98,12 @@ func (s *BaseCELListener) EnterIndex(ctx *IndexContext) {}
 // ExitIndex is called when production Index is exited.
 func (s *BaseCELListener) ExitIndex(ctx *IndexContext) {}
 
+// EnterSlice is called when production Slice is entered.
+func (s *BaseCELListener) EnterSlice(ctx *SliceContext) {}
+
+// ExitSlice is called when production Slice is exited.
+func (s *BaseCELListener) ExitSlice(ctx *SliceContext) {}
+
 // EnterIdentOrGlobalCall is called when production IdentOrGlobalCall is entered.
 func (s *BaseCELListener) EnterIdentOrGlobalCall(ctx *IdentOrGlobalCallContext) {}
 
@@ -146,6 +152,12 @@ func (s *BaseCELListener) EnterListInit(ctx *ListInitContext) {}
 // ExitListInit is called when production listInit is exited.
 func (s *BaseCELListener) ExitListInit(ctx *ListInitContext) {}
 
+// EnterListElement is called when production listElement is entered.
+func (s *BaseCELListener) EnterListElement(ctx *ListElementContext) {}
+
+// ExitListElement is called when production listElement is exited.
+func (s *BaseCELListener) ExitListElement(ctx *ListElementContext) {}
+
 // EnterFieldInitializerList is called when production fieldInitializerList is entered.
 func (s *BaseCELListener) EnterFieldInitializerList(ctx *FieldInitializerListContext) {}
 
@@ -164,6 +176,12 @@ func (s *BaseCELListener) EnterMapInitializerList(ctx *MapInitializerListContext
 // ExitMapInitializerList is called when production mapInitializerList is exited.
 func (s *BaseCELListener) ExitMapInitializerList(ctx *MapInitializerListContext) {}
 
+// EnterMapEntry is called when production mapEntry is entered.
+func (s *BaseCELListener) EnterMapEntry(ctx *MapEntryContext) {}
+
+// ExitMapEntry is called when production mapEntry is exited.
+func (s *BaseCELListener) ExitMapEntry(ctx *MapEntryContext) {}
+
 // EnterOptExpr is called when production optExpr is entered.
 func (s *BaseCELListener) EnterOptExpr(ctx *OptExprContext) {}
 
@@ -59,6 +59,10 @@ func (v *BaseCELVisitor) VisitIndex(ctx *IndexContext) interface{} {
 	return v.VisitChildren(ctx)
 }
 
+func (v *BaseCELVisitor) VisitSlice(ctx *SliceContext) interface{} {
+	return v.VisitChildren(ctx)
+}
+
 func (v *BaseCELVisitor) VisitIdentOrGlobalCall(ctx *IdentOrGlobalCallContext) interface{} {
 	return v.VisitChildren(ctx)
 }
@@ -91,6 +95,10 @@ func (v *BaseCELVisitor) VisitListInit(ctx *ListInitContext) interface{} {
 	return v.VisitChildren(ctx)
 }
 
+func (v *BaseCELVisitor) VisitListElement(ctx *ListElementContext) interface{} {
+	return v.VisitChildren(ctx)
+}
+
 func (v *BaseCELVisitor) VisitFieldInitializerList(ctx *FieldInitializerListContext) interface{} {
 	return v.VisitChildren(ctx)
 }
@@ -103,6 +111,10 @@ func (v *BaseCELVisitor) VisitMapInitializerList(ctx *MapInitializerListContext)
 	return v.VisitChildren(ctx)
 }
 
+func (v *BaseCELVisitor) VisitMapEntry(ctx *MapEntryContext) interface{} {
+	return v.VisitChildren(ctx)
+}
+
 func (v *BaseCELVisitor) VisitOptExpr(ctx *OptExprContext) interface{} {
 	return v.VisitChildren(ctx)
 }
@@ -46,6 +46,9 @@ type CELListener interface {
 	// EnterIndex is called when entering the Index production.
 	EnterIndex(c *IndexContext)
 
+	// EnterSlice is called when entering the Slice production.
+	EnterSlice(c *SliceContext)
+
 	// EnterIdentOrGlobalCall is called when entering the IdentOrGlobalCall production.
 	EnterIdentOrGlobalCall(c *IdentOrGlobalCallContext)
 
@@ -70,6 +73,9 @@ type CELListener interface {
 	// EnterListInit is called when entering the listInit production.
 	EnterListInit(c *ListInitContext)
 
+	// EnterListElement is called when entering the listElement production.
+	EnterListElement(c *ListElementContext)
+
 	// EnterFieldInitializerList is called when entering the fieldInitializerList production.
 	EnterFieldInitializerList(c *FieldInitializerListContext)
 
@@ -79,6 +85,9 @@ type CELListener interface {
 	// EnterMapInitializerList is called when entering the mapInitializerList production.
 	EnterMapInitializerList(c *MapInitializerListContext)
 
+	// EnterMapEntry is called when entering the mapEntry production.
+	EnterMapEntry(c *MapEntryContext)
+
 	// EnterOptExpr is called when entering the optExpr production.
 	EnterOptExpr(c *OptExprContext)
 
@@ -145,6 +154,9 @@ type CELListener interface {
 	// ExitIndex is called when exiting the Index production.
 	ExitIndex(c *IndexContext)
 
+	// ExitSlice is called when exiting the Slice production.
+	ExitSlice(c *SliceContext)
+
 	// ExitIdentOrGlobalCall is called when exiting the IdentOrGlobalCall production.
 	ExitIdentOrGlobalCall(c *IdentOrGlobalCallContext)
 
@@ -169,6 +181,9 @@ type CELListener interface {
 	// ExitListInit is called when exiting the listInit production.
 	ExitListInit(c *ListInitContext)
 
+	// ExitListElement is called when exiting the listElement production.
+	ExitListElement(c *ListElementContext)
+
 	// ExitFieldInitializerList is called when exiting the fieldInitializerList production.
 	ExitFieldInitializerList(c *FieldInitializerListContext)
 
@@ -178,6 +193,9 @@ type CELListener interface {
 	// ExitMapInitializerList is called when exiting the mapInitializerList production.
 	ExitMapInitializerList(c *MapInitializerListContext)
 
+	// ExitMapEntry is called when exiting the mapEntry production.
+	ExitMapEntry(c *MapEntryContext)
+
 	// ExitOptExpr is called when exiting the optExpr production.
 	ExitOptExpr(c *OptExprContext)
 
@@ -35,6 +35,7 @@ func celParserInit() {
 		"", "'=='", "'!='", "'in'", "'<'", "'<='", "'>='", "'>'", "'&&'", "'||'",
 		"'['", "']'", "'{'", "'}'", "'('", "')'", "'.'", "','", "'-'", "'!'",
 		"'?'", "':'", "'+'", "'*'", "'/'", "'%'", "'true'", "'false'", "'null'",
+		"", "", "", "", "", "", "", "", "'...'",
 	}
 	staticData.symbolicNames = []string{
 		"", "EQUALS", "NOT_EQUALS", "IN", "LESS", "LESS_EQUALS", "GREATER_EQUALS",
@@ -42,129 +43,145 @@ func celParserInit() {
 		"RBRACE", "LPAREN", "RPAREN", "DOT", "COMMA", "MINUS", "EXCLAM", "QUESTIONMARK",
 		"COLON", "PLUS", "STAR", "SLASH", "PERCENT", "CEL_TRUE", "CEL_FALSE",
 		"NUL", "WHITESPACE", "COMMENT", "NUM_FLOAT", "NUM_INT", "NUM_UINT",
-		"STRING", "BYTES", "IDENTIFIER",
+		"STRING", "BYTES", "IDENTIFIER", "ELLIPSIS",
 	}
 	staticData.ruleNames = []string{
 		"start", "expr", "conditionalOr", "conditionalAnd", "relation", "calc",
-		"unary", "member", "primary", "exprList", "listInit", "fieldInitializerList",
-		"optField", "mapInitializerList", "optExpr", "literal",
+		"unary", "member", "primary", "exprList", "listInit", "listElement",
+		"fieldInitializerList", "optField", "mapInitializerList", "mapEntry",
+		"optExpr", "literal",
 	}
 	staticData.predictionContextCache = antlr.NewPredictionContextCache()
 	staticData.serializedATN = []int32{
-		4, 1, 36, 251, 2, 0, 7, 0, 2, 1, 7, 1, 2, 2, 7, 2, 2, 3, 7, 3, 2, 4, 7,
+		4, 1, 37, 282, 2, 0, 7, 0, 2, 1, 7, 1, 2, 2, 7, 2, 2, 3, 7, 3, 2, 4, 7,
 		4, 2, 5, 7, 5, 2, 6, 7, 6, 2, 7, 7, 7, 2, 8, 7, 8, 2, 9, 7, 9, 2, 10, 7,
 		10, 2, 11, 7, 11, 2, 12, 7, 12, 2, 13, 7, 13, 2, 14, 7, 14, 2, 15, 7, 15,
-		1, 0, 1, 0, 1, 0, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 3, 1, 42, 8, 1, 1,
-		2, 1, 2, 1, 2, 5, 2, 47, 8, 2, 10, 2, 12, 2, 50, 9, 2, 1, 3, 1, 3, 1, 3,
-		5, 3, 55, 8, 3, 10, 3, 12, 3, 58, 9, 3, 1, 4, 1, 4, 1, 4, 1, 4, 1, 4, 1,
-		4, 5, 4, 66, 8, 4, 10, 4, 12, 4, 69, 9, 4, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5,
-		1, 5, 1, 5, 1, 5, 1, 5, 5, 5, 80, 8, 5, 10, 5, 12, 5, 83, 9, 5, 1, 6, 1,
-		6, 4, 6, 87, 8, 6, 11, 6, 12, 6, 88, 1, 6, 1, 6, 4, 6, 93, 8, 6, 11, 6,
-		12, 6, 94, 1, 6, 3, 6, 98, 8, 6, 1, 7, 1, 7, 1, 7, 1, 7, 1, 7, 1, 7, 3,
-		7, 106, 8, 7, 1, 7, 1, 7, 1, 7, 1, 7, 1, 7, 1, 7, 3, 7, 114, 8, 7, 1, 7,
-		1, 7, 1, 7, 1, 7, 3, 7, 120, 8, 7, 1, 7, 1, 7, 1, 7, 5, 7, 125, 8, 7, 10,
-		7, 12, 7, 128, 9, 7, 1, 8, 3, 8, 131, 8, 8, 1, 8, 1, 8, 1, 8, 3, 8, 136,
-		8, 8, 1, 8, 3, 8, 139, 8, 8, 1, 8, 1, 8, 1, 8, 1, 8, 1, 8, 1, 8, 3, 8,
-		147, 8, 8, 1, 8, 3, 8, 150, 8, 8, 1, 8, 1, 8, 1, 8, 3, 8, 155, 8, 8, 1,
-		8, 3, 8, 158, 8, 8, 1, 8, 1, 8, 3, 8, 162, 8, 8, 1, 8, 1, 8, 1, 8, 5, 8,
-		167, 8, 8, 10, 8, 12, 8, 170, 9, 8, 1, 8, 1, 8, 3, 8, 174, 8, 8, 1, 8,
-		3, 8, 177, 8, 8, 1, 8, 1, 8, 3, 8, 181, 8, 8, 1, 9, 1, 9, 1, 9, 5, 9, 186,
-		8, 9, 10, 9, 12, 9, 189, 9, 9, 1, 10, 1, 10, 1, 10, 5, 10, 194, 8, 10,
-		10, 10, 12, 10, 197, 9, 10, 1, 11, 1, 11, 1, 11, 1, 11, 1, 11, 1, 11, 1,
-		11, 1, 11, 5, 11, 207, 8, 11, 10, 11, 12, 11, 210, 9, 11, 1, 12, 3, 12,
-		213, 8, 12, 1, 12, 1, 12, 1, 13, 1, 13, 1, 13, 1, 13, 1, 13, 1, 13, 1,
-		13, 1, 13, 5, 13, 225, 8, 13, 10, 13, 12, 13, 228, 9, 13, 1, 14, 3, 14,
-		231, 8, 14, 1, 14, 1, 14, 1, 15, 3, 15, 236, 8, 15, 1, 15, 1, 15, 1, 15,
-		3, 15, 241, 8, 15, 1, 15, 1, 15, 1, 15, 1, 15, 1, 15, 1, 15, 3, 15, 249,
-		8, 15, 1, 15, 0, 3, 8, 10, 14, 16, 0, 2, 4, 6, 8, 10, 12, 14, 16, 18, 20,
-		22, 24, 26, 28, 30, 0, 3, 1, 0, 1, 7, 1, 0, 23, 25, 2, 0, 18, 18, 22, 22,
-		281, 0, 32, 1, 0, 0, 0, 2, 35, 1, 0, 0, 0, 4, 43, 1, 0, 0, 0, 6, 51, 1,
-		0, 0, 0, 8, 59, 1, 0, 0, 0, 10, 70, 1, 0, 0, 0, 12, 97, 1, 0, 0, 0, 14,
-		99, 1, 0, 0, 0, 16, 180, 1, 0, 0, 0, 18, 182, 1, 0, 0, 0, 20, 190, 1, 0,
-		0, 0, 22, 198, 1, 0, 0, 0, 24, 212, 1, 0, 0, 0, 26, 216, 1, 0, 0, 0, 28,
-		230, 1, 0, 0, 0, 30, 248, 1, 0, 0, 0, 32, 33, 3, 2, 1, 0, 33, 34, 5, 0,
-		0, 1, 34, 1, 1, 0, 0, 0, 35, 41, 3, 4, 2, 0, 36, 37, 5, 20, 0, 0, 37, 38,
-		3, 4, 2, 0, 38, 39, 5, 21, 0, 0, 39, 40, 3, 2, 1, 0, 40, 42, 1, 0, 0, 0,
-		41, 36, 1, 0, 0, 0, 41, 42, 1, 0, 0, 0, 42, 3, 1, 0, 0, 0, 43, 48, 3, 6,
-		3, 0, 44, 45, 5, 9, 0, 0, 45, 47, 3, 6, 3, 0, 46, 44, 1, 0, 0, 0, 47, 50,
-		1, 0, 0, 0, 48, 46, 1, 0, 0, 0, 48, 49, 1, 0, 0, 0, 49, 5, 1, 0, 0, 0,
-		50, 48, 1, 0, 0, 0, 51, 56, 3, 8, 4, 0, 52, 53, 5, 8, 0, 0, 53, 55, 3,
-		8, 4, 0, 54, 52, 1, 0, 0, 0, 55, 58, 1, 0, 0, 0, 56, 54, 1, 0, 0, 0, 56,
-		57, 1, 0, 0, 0, 57, 7, 1, 0, 0, 0, 58, 56, 1, 0, 0, 0, 59, 60, 6, 4, -1,
-		0, 60, 61, 3, 10, 5, 0, 61, 67, 1, 0, 0, 0, 62, 63, 10, 1, 0, 0, 63, 64,
-		7, 0, 0, 0, 64, 66, 3, 8, 4, 2, 65, 62, 1, 0, 0, 0, 66, 69, 1, 0, 0, 0,
-		67, 65, 1, 0, 0, 0, 67, 68, 1, 0, 0, 0, 68, 9, 1, 0, 0, 0, 69, 67, 1, 0,
-		0, 0, 70, 71, 6, 5, -1, 0, 71, 72, 3, 12, 6, 0, 72, 81, 1, 0, 0, 0, 73,
-		74, 10, 2, 0, 0, 74, 75, 7, 1, 0, 0, 75, 80, 3, 10, 5, 3, 76, 77, 10, 1,
-		0, 0, 77, 78, 7, 2, 0, 0, 78, 80, 3, 10, 5, 2, 79, 73, 1, 0, 0, 0, 79,
-		76, 1, 0, 0, 0, 80, 83, 1, 0, 0, 0, 81, 79, 1, 0, 0, 0, 81, 82, 1, 0, 0,
-		0, 82, 11, 1, 0, 0, 0, 83, 81, 1, 0, 0, 0, 84, 98, 3, 14, 7, 0, 85, 87,
-		5, 19, 0, 0, 86, 85, 1, 0, 0, 0, 87, 88, 1, 0, 0, 0, 88, 86, 1, 0, 0, 0,
-		88, 89, 1, 0, 0, 0, 89, 90, 1, 0, 0, 0, 90, 98, 3, 14, 7, 0, 91, 93, 5,
-		18, 0, 0, 92, 91, 1, 0, 0, 0, 93, 94, 1, 0, 0, 0, 94, 92, 1, 0, 0, 0, 94,
-		95, 1, 0, 0, 0, 95, 96, 1, 0, 0, 0, 96, 98, 3, 14, 7, 0, 97, 84, 1, 0,
-		0, 0, 97, 86, 1, 0, 0, 0, 97, 92, 1, 0, 0, 0, 98, 13, 1, 0, 0, 0, 99, 100,
-		6, 7, -1, 0, 100, 101, 3, 16, 8, 0, 101, 126, 1, 0, 0, 0, 102, 103, 10,
-		3, 0, 0, 103, 105, 5, 16, 0, 0, 104, 106, 5, 20, 0, 0, 105, 104, 1, 0,
-		0, 0, 105, 106, 1, 0, 0, 0, 106, 107, 1, 0, 0, 0, 107, 125, 5, 36, 0, 0,
-		108, 109, 10, 2, 0, 0, 109, 110, 5, 16, 0, 0, 110, 111, 5, 36, 0, 0, 111,
-		113, 5, 14, 0, 0, 112, 114, 3, 18, 9, 0, 113, 112, 1, 0, 0, 0, 113, 114,
-		1, 0, 0, 0, 114, 115, 1, 0, 0, 0, 115, 125, 5, 15, 0, 0, 116, 117, 10,
-		1, 0, 0, 117, 119, 5, 10, 0, 0, 118, 120, 5, 20, 0, 0, 119, 118, 1, 0,
-		0, 0, 119, 120, 1, 0, 0, 0, 120, 121, 1, 0, 0, 0, 121, 122, 3, 2, 1, 0,
-		122, 123, 5, 11, 0, 0, 123, 125, 1, 0, 0, 0, 124, 102, 1, 0, 0, 0, 124,
-		108, 1, 0, 0, 0, 124, 116, 1, 0, 0, 0, 125, 128, 1, 0, 0, 0, 126, 124,
-		1, 0, 0, 0, 126, 127, 1, 0, 0, 0, 127, 15, 1, 0, 0, 0, 128, 126, 1, 0,
-		0, 0, 129, 131, 5, 16, 0, 0, 130, 129, 1, 0, 0, 0, 130, 131, 1, 0, 0, 0,
-		131, 132, 1, 0, 0, 0, 132, 138, 5, 36, 0, 0, 133, 135, 5, 14, 0, 0, 134,
-		136, 3, 18, 9, 0, 135, 134, 1, 0, 0, 0, 135, 136, 1, 0, 0, 0, 136, 137,
-		1, 0, 0, 0, 137, 139, 5, 15, 0, 0, 138, 133, 1, 0, 0, 0, 138, 139, 1, 0,
-		0, 0, 139, 181, 1, 0, 0, 0, 140, 141, 5, 14, 0, 0, 141, 142, 3, 2, 1, 0,
-		142, 143, 5, 15, 0, 0, 143, 181, 1, 0, 0, 0, 144, 146, 5, 10, 0, 0, 145,
-		147, 3, 20, 10, 0, 146, 145, 1, 0, 0, 0, 146, 147, 1, 0, 0, 0, 147, 149,
-		1, 0, 0, 0, 148, 150, 5, 17, 0, 0, 149, 148, 1, 0, 0, 0, 149, 150, 1, 0,
-		0, 0, 150, 151, 1, 0, 0, 0, 151, 181, 5, 11, 0, 0, 152, 154, 5, 12, 0,
-		0, 153, 155, 3, 26, 13, 0, 154, 153, 1, 0, 0, 0, 154, 155, 1, 0, 0, 0,
-		155, 157, 1, 0, 0, 0, 156, 158, 5, 17, 0, 0, 157, 156, 1, 0, 0, 0, 157,
-		158, 1, 0, 0, 0, 158, 159, 1, 0, 0, 0, 159, 181, 5, 13, 0, 0, 160, 162,
-		5, 16, 0, 0, 161, 160, 1, 0, 0, 0, 161, 162, 1, 0, 0, 0, 162, 163, 1, 0,
-		0, 0, 163, 168, 5, 36, 0, 0, 164, 165, 5, 16, 0, 0, 165, 167, 5, 36, 0,
-		0, 166, 164, 1, 0, 0, 0, 167, 170, 1, 0, 0, 0, 168, 166, 1, 0, 0, 0, 168,
-		169, 1, 0, 0, 0, 169, 171, 1, 0, 0, 0, 170, 168, 1, 0, 0, 0, 171, 173,
-		5, 12, 0, 0, 172, 174, 3, 22, 11, 0, 173, 172, 1, 0, 0, 0, 173, 174, 1,
-		0, 0, 0, 174, 176, 1, 0, 0, 0, 175, 177, 5, 17, 0, 0, 176, 175, 1, 0, 0,
-		0, 176, 177, 1, 0, 0, 0, 177, 178, 1, 0, 0, 0, 178, 181, 5, 13, 0, 0, 179,
-		181, 3, 30, 15, 0, 180, 130, 1, 0, 0, 0, 180, 140, 1, 0, 0, 0, 180, 144,
-		1, 0, 0, 0, 180, 152, 1, 0, 0, 0, 180, 161, 1, 0, 0, 0, 180, 179, 1, 0,
-		0, 0, 181, 17, 1, 0, 0, 0, 182, 187, 3, 2, 1, 0, 183, 184, 5, 17, 0, 0,
-		184, 186, 3, 2, 1, 0, 185, 183, 1, 0, 0, 0, 186, 189, 1, 0, 0, 0, 187,
-		185, 1, 0, 0, 0, 187, 188, 1, 0, 0, 0, 188, 19, 1, 0, 0, 0, 189, 187, 1,
-		0, 0, 0, 190, 195, 3, 28, 14, 0, 191, 192, 5, 17, 0, 0, 192, 194, 3, 28,
-		14, 0, 193, 191, 1, 0, 0, 0, 194, 197, 1, 0, 0, 0, 195, 193, 1, 0, 0, 0,
-		195, 196, 1, 0, 0, 0, 196, 21, 1, 0, 0, 0, 197, 195, 1, 0, 0, 0, 198, 199,
-		3, 24, 12, 0, 199, 200, 5, 21, 0, 0, 200, 208, 3, 2, 1, 0, 201, 202, 5,
-		17, 0, 0, 202, 203, 3, 24, 12, 0, 203, 204, 5, 21, 0, 0, 204, 205, 3, 2,
-		1, 0, 205, 207, 1, 0, 0, 0, 206, 201, 1, 0, 0, 0, 207, 210, 1, 0, 0, 0,
-		208, 206, 1, 0, 0, 0, 208, 209, 1, 0, 0, 0, 209, 23, 1, 0, 0, 0, 210, 208,
-		1, 0, 0, 0, 211, 213, 5, 20, 0, 0, 212, 211, 1, 0, 0, 0, 212, 213, 1, 0,
-		0, 0, 213, 214, 1, 0, 0, 0, 214, 215, 5, 36, 0, 0, 215, 25, 1, 0, 0, 0,
-		216, 217, 3, 28, 14, 0, 217, 218, 5, 21, 0, 0, 218, 226, 3, 2, 1, 0, 219,
-		220, 5, 17, 0, 0, 220, 221, 3, 28, 14, 0, 221, 222, 5, 21, 0, 0, 222, 223,
-		3, 2, 1, 0, 223, 225, 1, 0, 0, 0, 224, 219, 1, 0, 0, 0, 225, 228, 1, 0,
-		0, 0, 226, 224, 1, 0, 0, 0, 226, 227, 1, 0, 0, 0, 227, 27, 1, 0, 0, 0,
-		228, 226, 1, 0, 0, 0, 229, 231, 5, 20, 0, 0, 230, 229, 1, 0, 0, 0, 230,
-		231, 1, 0, 0, 0, 231, 232, 1, 0, 0, 0, 232, 233, 3, 2, 1, 0, 233, 29, 1,
-		0, 0, 0, 234, 236, 5, 18, 0, 0, 235, 234, 1, 0, 0, 0, 235, 236, 1, 0, 0,
-		0, 236, 237, 1, 0, 0, 0, 237, 249, 5, 32, 0, 0, 238, 249, 5, 33, 0, 0,
-		239, 241, 5, 18, 0, 0, 240, 239, 1, 0, 0, 0, 240, 241, 1, 0, 0, 0, 241,
-		242, 1, 0, 0, 0, 242, 249, 5, 31, 0, 0, 243, 249, 5, 34, 0, 0, 244, 249,
-		5, 35, 0, 0, 245, 249, 5, 26, 0, 0, 246, 249, 5, 27, 0, 0, 247, 249, 5,
-		28, 0, 0, 248, 235, 1, 0, 0, 0, 248, 238, 1, 0, 0, 0, 248, 240, 1, 0, 0,
-		0, 248, 243, 1, 0, 0, 0, 248, 244, 1, 0, 0, 0, 248, 245, 1, 0, 0, 0, 248,
-		246, 1, 0, 0, 0, 248, 247, 1, 0, 0, 0, 249, 31, 1, 0, 0, 0, 35, 41, 48,
-		56, 67, 79, 81, 88, 94, 97, 105, 113, 119, 124, 126, 130, 135, 138, 146,
-		149, 154, 157, 161, 168, 173, 176, 180, 187, 195, 208, 212, 226, 230, 235,
-		240, 248,
+		2, 16, 7, 16, 2, 17, 7, 17, 1, 0, 1, 0, 1, 0, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+		1, 1, 1, 3, 1, 46, 8, 1, 1, 2, 1, 2, 1, 2, 5, 2, 51, 8, 2, 10, 2, 12, 2,
+		54, 9, 2, 1, 3, 1, 3, 1, 3, 5, 3, 59, 8, 3, 10, 3, 12, 3, 62, 9, 3, 1,
+		4, 1, 4, 1, 4, 1, 4, 1, 4, 1, 4, 5, 4, 70, 8, 4, 10, 4, 12, 4, 73, 9, 4,
+		1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 5, 5, 84, 8, 5, 10,
+		5, 12, 5, 87, 9, 5, 1, 6, 1, 6, 4, 6, 91, 8, 6, 11, 6, 12, 6, 92, 1, 6,
+		1, 6, 4, 6, 97, 8, 6, 11, 6, 12, 6, 98, 1, 6, 3, 6, 102, 8, 6, 1, 7, 1,
+		7, 1, 7, 1, 7, 1, 7, 1, 7, 3, 7, 110, 8, 7, 1, 7, 1, 7, 1, 7, 1, 7, 1,
+		7, 1, 7, 3, 7, 118, 8, 7, 1, 7, 1, 7, 1, 7, 1, 7, 3, 7, 124, 8, 7, 1, 7,
+		1, 7, 1, 7, 1, 7, 1, 7, 1, 7, 3, 7, 132, 8, 7, 1, 7, 1, 7, 3, 7, 136, 8,
+		7, 1, 7, 1, 7, 3, 7, 140, 8, 7, 3, 7, 142, 8, 7, 1, 7, 5, 7, 145, 8, 7,
+		10, 7, 12, 7, 148, 9, 7, 1, 8, 3, 8, 151, 8, 8, 1, 8, 1, 8, 1, 8, 3, 8,
+		156, 8, 8, 1, 8, 3, 8, 159, 8, 8, 1, 8, 1, 8, 1, 8, 1, 8, 1, 8, 1, 8, 3,
+		8, 167, 8, 8, 1, 8, 3, 8, 170, 8, 8, 1, 8, 1, 8, 1, 8, 3, 8, 175, 8, 8,
+		1, 8, 3, 8, 178, 8, 8, 1, 8, 1, 8, 3, 8, 182, 8, 8, 1, 8, 1, 8, 1, 8, 5,
+		8, 187, 8, 8, 10, 8, 12, 8, 190, 9, 8, 1, 8, 1, 8, 3, 8, 194, 8, 8, 1,
+		8, 3, 8, 197, 8, 8, 1, 8, 1, 8, 3, 8, 201, 8, 8, 1, 9, 1, 9, 1, 9, 5, 9,
+		206, 8, 9, 10, 9, 12, 9, 209, 9, 9, 1, 10, 1, 10, 1, 10, 5, 10, 214, 8,
+		10, 10, 10, 12, 10, 217, 9, 10, 1, 11, 3, 11, 220, 8, 11, 1, 11, 1, 11,
+		1, 11, 3, 11, 225, 8, 11, 1, 12, 1, 12, 1, 12, 1, 12, 1, 12, 1, 12, 1,
+		12, 1, 12, 5, 12, 235, 8, 12, 10, 12, 12, 12, 238, 9, 12, 1, 13, 3, 13,
+		241, 8, 13, 1, 13, 1, 13, 1, 14, 1, 14, 1, 14, 5, 14, 248, 8, 14, 10, 14,
+		12, 14, 251, 9, 14, 1, 15, 1, 15, 1, 15, 1, 15, 1, 15, 1, 15, 3, 15, 259,
+		8, 15, 1, 16, 3, 16, 262, 8, 16, 1, 16, 1, 16, 1, 17, 3, 17, 267, 8, 17,
+		1, 17, 1, 17, 1, 17, 3, 17, 272, 8, 17, 1, 17, 1, 17, 1, 17, 1, 17, 1,
+		17, 1, 17, 3, 17, 280, 8, 17, 1, 17, 0, 3, 8, 10, 14, 18, 0, 2, 4, 6, 8,
+		10, 12, 14, 16, 18, 20, 22, 24, 26, 28, 30, 32, 34, 0, 3, 1, 0, 1, 7, 1,
+		0, 23, 25, 2, 0, 18, 18, 22, 22, 318, 0, 36, 1, 0, 0, 0, 2, 39, 1, 0, 0,
+		0, 4, 47, 1, 0, 0, 0, 6, 55, 1, 0, 0, 0, 8, 63, 1, 0, 0, 0, 10, 74, 1,
+		0, 0, 0, 12, 101, 1, 0, 0, 0, 14, 103, 1, 0, 0, 0, 16, 200, 1, 0, 0, 0,
+		18, 202, 1, 0, 0, 0, 20, 210, 1, 0, 0, 0, 22, 224, 1, 0, 0, 0, 24, 226,
+		1, 0, 0, 0, 26, 240, 1, 0, 0, 0, 28, 244, 1, 0, 0, 0, 30, 258, 1, 0, 0,
+		0, 32, 261, 1, 0, 0, 0, 34, 279, 1, 0, 0, 0, 36, 37, 3, 2, 1, 0, 37, 38,
+		5, 0, 0, 1, 38, 1, 1, 0, 0, 0, 39, 45, 3, 4, 2, 0, 40, 41, 5, 20, 0, 0,
+		41, 42, 3, 4, 2, 0, 42, 43, 5, 21, 0, 0, 43, 44, 3, 2, 1, 0, 44, 46, 1,
+		0, 0, 0, 45, 40, 1, 0, 0, 0, 45, 46, 1, 0, 0, 0, 46, 3, 1, 0, 0, 0, 47,
+		52, 3, 6, 3, 0, 48, 49, 5, 9, 0, 0, 49, 51, 3, 6, 3, 0, 50, 48, 1, 0, 0,
+		0, 51, 54, 1, 0, 0, 0, 52, 50, 1, 0, 0, 0, 52, 53, 1, 0, 0, 0, 53, 5, 1,
+		0, 0, 0, 54, 52, 1, 0, 0, 0, 55, 60, 3, 8, 4, 0, 56, 57, 5, 8, 0, 0, 57,
+		59, 3, 8, 4, 0, 58, 56, 1, 0, 0, 0, 59, 62, 1, 0, 0, 0, 60, 58, 1, 0, 0,
+		0, 60, 61, 1, 0, 0, 0, 61, 7, 1, 0, 0, 0, 62, 60, 1, 0, 0, 0, 63, 64, 6,
+		4, -1, 0, 64, 65, 3, 10, 5, 0, 65, 71, 1, 0, 0, 0, 66, 67, 10, 1, 0, 0,
+		67, 68, 7, 0, 0, 0, 68, 70, 3, 8, 4, 2, 69, 66, 1, 0, 0, 0, 70, 73, 1,
+		0, 0, 0, 71, 69, 1, 0, 0, 0, 71, 72, 1, 0, 0, 0, 72, 9, 1, 0, 0, 0, 73,
+		71, 1, 0, 0, 0, 74, 75, 6, 5, -1, 0, 75, 76, 3, 12, 6, 0, 76, 85, 1, 0,
+		0, 0, 77, 78, 10, 2, 0, 0, 78, 79, 7, 1, 0, 0, 79, 84, 3, 10, 5, 3, 80,
+		81, 10, 1, 0, 0, 81, 82, 7, 2, 0, 0, 82, 84, 3, 10, 5, 2, 83, 77, 1, 0,
+		0, 0, 83, 80, 1, 0, 0, 0, 84, 87, 1, 0, 0, 0, 85, 83, 1, 0, 0, 0, 85, 86,
+		1, 0, 0, 0, 86, 11, 1, 0, 0, 0, 87, 85, 1, 0, 0, 0, 88, 102, 3, 14, 7,
+		0, 89, 91, 5, 19, 0, 0, 90, 89, 1, 0, 0, 0, 91, 92, 1, 0, 0, 0, 92, 90,
+		1, 0, 0, 0, 92, 93, 1, 0, 0, 0, 93, 94, 1, 0, 0, 0, 94, 102, 3, 14, 7,
+		0, 95, 97, 5, 18, 0, 0, 96, 95, 1, 0, 0, 0, 97, 98, 1, 0, 0, 0, 98, 96,
+		1, 0, 0, 0, 98, 99, 1, 0, 0, 0, 99, 100, 1, 0, 0, 0, 100, 102, 3, 14, 7,
+		0, 101, 88, 1, 0, 0, 0, 101, 90, 1, 0, 0, 0, 101, 96, 1, 0, 0, 0, 102,
+		13, 1, 0, 0, 0, 103, 104, 6, 7, -1, 0, 104, 105, 3, 16, 8, 0, 105, 146,
+		1, 0, 0, 0, 106, 107, 10, 4, 0, 0, 107, 109, 5, 16, 0, 0, 108, 110, 5,
+		20, 0, 0, 109, 108, 1, 0, 0, 0, 109, 110, 1, 0, 0, 0, 110, 111, 1, 0, 0,
+		0, 111, 145, 5, 36, 0, 0, 112, 113, 10, 3, 0, 0, 113, 114, 5, 16, 0, 0,
+		114, 115, 5, 36, 0, 0, 115, 117, 5, 14, 0, 0, 116, 118, 3, 18, 9, 0, 117,
+		116, 1, 0, 0, 0, 117, 118, 1, 0, 0, 0, 118, 119, 1, 0, 0, 0, 119, 145,
+		5, 15, 0, 0, 120, 121, 10, 2, 0, 0, 121, 123, 5, 10, 0, 0, 122, 124, 5,
+		20, 0, 0, 123, 122, 1, 0, 0, 0, 123, 124, 1, 0, 0, 0, 124, 125, 1, 0, 0,
+		0, 125, 126, 3, 2, 1, 0, 126, 127, 5, 11, 0, 0, 127, 145, 1, 0, 0, 0, 128,
+		129, 10, 1, 0, 0, 129, 131, 5, 10, 0, 0, 130, 132, 3, 2, 1, 0, 131, 130,
+		1, 0, 0, 0, 131, 132, 1, 0, 0, 0, 132, 133, 1, 0, 0, 0, 133, 135, 5, 21,
+		0, 0, 134, 136, 3, 2, 1, 0, 135, 134, 1, 0, 0, 0, 135, 136, 1, 0, 0, 0,
+		136, 141, 1, 0, 0, 0, 137, 139, 5, 21, 0, 0, 138, 140, 3, 2, 1, 0, 139,
+		138, 1, 0, 0, 0, 139, 140, 1, 0, 0, 0, 140, 142, 1, 0, 0, 0, 141, 137,
+		1, 0, 0, 0, 141, 142, 1, 0, 0, 0, 142, 143, 1, 0, 0, 0, 143, 145, 5, 11,
+		0, 0, 144, 106, 1, 0, 0, 0, 144, 112, 1, 0, 0, 0, 144, 120, 1, 0, 0, 0,
+		144, 128, 1, 0, 0, 0, 145, 148, 1, 0, 0, 0, 146, 144, 1, 0, 0, 0, 146,
+		147, 1, 0, 0, 0, 147, 15, 1, 0, 0, 0, 148, 146, 1, 0, 0, 0, 149, 151, 5,
+		16, 0, 0, 150, 149, 1, 0, 0, 0, 150, 151, 1, 0, 0, 0, 151, 152, 1, 0, 0,
+		0, 152, 158, 5, 36, 0, 0, 153, 155, 5, 14, 0, 0, 154, 156, 3, 18, 9, 0,
+		155, 154, 1, 0, 0, 0, 155, 156, 1, 0, 0, 0, 156, 157, 1, 0, 0, 0, 157,
+		159, 5, 15, 0, 0, 158, 153, 1, 0, 0, 0, 158, 159, 1, 0, 0, 0, 159, 201,
+		1, 0, 0, 0, 160, 161, 5, 14, 0, 0, 161, 162, 3, 2, 1, 0, 162, 163, 5, 15,
+		0, 0, 163, 201, 1, 0, 0, 0, 164, 166, 5, 10, 0, 0, 165, 167, 3, 20, 10,
+		0, 166, 165, 1, 0, 0, 0, 166, 167, 1, 0, 0, 0, 167, 169, 1, 0, 0, 0, 168,
+		170, 5, 17, 0, 0, 169, 168, 1, 0, 0, 0, 169, 170, 1, 0, 0, 0, 170, 171,
+		1, 0, 0, 0, 171, 201, 5, 11, 0, 0, 172, 174, 5, 12, 0, 0, 173, 175, 3,
+		28, 14, 0, 174, 173, 1, 0, 0, 0, 174, 175, 1, 0, 0, 0, 175, 177, 1, 0,
+		0, 0, 176, 178, 5, 17, 0, 0, 177, 176, 1, 0, 0, 0, 177, 178, 1, 0, 0, 0,
+		178, 179, 1, 0, 0, 0, 179, 201, 5, 13, 0, 0, 180, 182, 5, 16, 0, 0, 181,
+		180, 1, 0, 0, 0, 181, 182, 1, 0, 0, 0, 182, 183, 1, 0, 0, 0, 183, 188,
+		5, 36, 0, 0, 184, 185, 5, 16, 0, 0, 185, 187, 5, 36, 0, 0, 186, 184, 1,
+		0, 0, 0, 187, 190, 1, 0, 0, 0, 188, 186, 1, 0, 0, 0, 188, 189, 1, 0, 0,
+		0, 189, 191, 1, 0, 0, 0, 190, 188, 1, 0, 0, 0, 191, 193, 5, 12, 0, 0, 192,
+		194, 3, 24, 12, 0, 193, 192, 1, 0, 0, 0, 193, 194, 1, 0, 0, 0, 194, 196,
+		1, 0, 0, 0, 195, 197, 5, 17, 0, 0, 196, 195, 1, 0, 0, 0, 196, 197, 1, 0,
+		0, 0, 197, 198, 1, 0, 0, 0, 198, 201, 5, 13, 0, 0, 199, 201, 3, 34, 17,
+		0, 200, 150, 1, 0, 0, 0, 200, 160, 1, 0, 0, 0, 200, 164, 1, 0, 0, 0, 200,
+		172, 1, 0, 0, 0, 200, 181, 1, 0, 0, 0, 200, 199, 1, 0, 0, 0, 201, 17, 1,
+		0, 0, 0, 202, 207, 3, 2, 1, 0, 203, 204, 5, 17, 0, 0, 204, 206, 3, 2, 1,
+		0, 205, 203, 1, 0, 0, 0, 206, 209, 1, 0, 0, 0, 207, 205, 1, 0, 0, 0, 207,
+		208, 1, 0, 0, 0, 208, 19, 1, 0, 0, 0, 209, 207, 1, 0, 0, 0, 210, 215, 3,
+		22, 11, 0, 211, 212, 5, 17, 0, 0, 212, 214, 3, 22, 11, 0, 213, 211, 1,
+		0, 0, 0, 214, 217, 1, 0, 0, 0, 215, 213, 1, 0, 0, 0, 215, 216, 1, 0, 0,
+		0, 216, 21, 1, 0, 0, 0, 217, 215, 1, 0, 0, 0, 218, 220, 5, 20, 0, 0, 219,
+		218, 1, 0, 0, 0, 219, 220, 1, 0, 0, 0, 220, 221, 1, 0, 0, 0, 221, 225,
+		3, 2, 1, 0, 222, 223, 5, 37, 0, 0, 223, 225, 3, 2, 1, 0, 224, 219, 1, 0,
+		0, 0, 224, 222, 1, 0, 0, 0, 225, 23, 1, 0, 0, 0, 226, 227, 3, 26, 13, 0,
+		227, 228, 5, 21, 0, 0, 228, 236, 3, 2, 1, 0, 229, 230, 5, 17, 0, 0, 230,
+		231, 3, 26, 13, 0, 231, 232, 5, 21, 0, 0, 232, 233, 3, 2, 1, 0, 233, 235,
+		1, 0, 0, 0, 234, 229, 1, 0, 0, 0, 235, 238, 1, 0, 0, 0, 236, 234, 1, 0,
+		0, 0, 236, 237, 1, 0, 0, 0, 237, 25, 1, 0, 0, 0, 238, 236, 1, 0, 0, 0,
+		239, 241, 5, 20, 0, 0, 240, 239, 1, 0, 0, 0, 240, 241, 1, 0, 0, 0, 241,
+		242, 1, 0, 0, 0, 242, 243, 5, 36, 0, 0, 243, 27, 1, 0, 0, 0, 244, 249,
+		3, 30, 15, 0, 245, 246, 5, 17, 0, 0, 246, 248, 3, 30, 15, 0, 247, 245,
+		1, 0, 0, 0, 248, 251, 1, 0, 0, 0, 249, 247, 1, 0, 0, 0, 249, 250, 1, 0,
+		0, 0, 250, 29, 1, 0, 0, 0, 251, 249, 1, 0, 0, 0, 252, 253, 3, 32, 16, 0,
+		253, 254, 5, 21, 0, 0, 254, 255, 3, 2, 1, 0, 255, 259, 1, 0, 0, 0, 256,
+		257, 5, 37, 0, 0, 257, 259, 3, 2, 1, 0, 258, 252, 1, 0, 0, 0, 258, 256,
+		1, 0, 0, 0, 259, 31, 1, 0, 0, 0, 260, 262, 5, 20, 0, 0, 261, 260, 1, 0,
+		0, 0, 261, 262, 1, 0, 0, 0, 262, 263, 1, 0, 0, 0, 263, 264, 3, 2, 1, 0,
+		264, 33, 1, 0, 0, 0, 265, 267, 5, 18, 0, 0, 266, 265, 1, 0, 0, 0, 266,
+		267, 1, 0, 0, 0, 267, 268, 1, 0, 0, 0, 268, 280, 5, 32, 0, 0, 269, 280,
+		5, 33, 0, 0, 270, 272, 5, 18, 0, 0, 271, 270, 1, 0, 0, 0, 271, 272, 1,
+		0, 0, 0, 272, 273, 1, 0, 0, 0, 273, 280, 5, 31, 0, 0, 274, 280, 5, 34,
+		0, 0, 275, 280, 5, 35, 0, 0, 276, 280, 5, 26, 0, 0, 277, 280, 5, 27, 0,
+		0, 278, 280, 5, 28, 0, 0, 279, 266, 1, 0, 0, 0, 279, 269, 1, 0, 0, 0, 279,
+		271, 1, 0, 0, 0, 279, 274, 1, 0, 0, 0, 279, 275, 1, 0, 0, 0, 279, 276,
+		1, 0, 0, 0, 279, 277, 1, 0, 0, 0, 279, 278, 1, 0, 0, 0, 280, 35, 1, 0,
+		0, 0, 42, 45, 52, 60, 71, 83, 85, 92, 98, 101, 109, 117, 123, 131, 135,
+		139, 141, 144, 146, 150, 155, 158, 166, 169, 174, 177, 181, 188, 193, 196,
+		200, 207, 215, 219, 224, 236, 240, 249, 258, 261, 266, 271, 279,
 	}
 	deserializer := antlr.NewATNDeserializer(nil)
 	staticData.atn = deserializer.Deserialize(staticData.serializedATN)
@@ -239,6 +256,7 @@ const (
 	CELParserSTRING         = 34
 	CELParserBYTES          = 35
 	CELParserIDENTIFIER     = 36
+	CELParserELLIPSIS       = 37
 )
 
 // CELParser rules.
@@ -254,11 +272,13 @@ const (
 	CELParserRULE_primary              = 8
 	CELParserRULE_exprList             = 9
 	CELParserRULE_listInit             = 10
-	CELParserRULE_fieldInitializerList = 11
-	CELParserRULE_optField             = 12
-	CELParserRULE_mapInitializerList   = 13
-	CELParserRULE_optExpr              = 14
-	CELParserRULE_literal              = 15
+	CELParserRULE_listElement          = 11
+	CELParserRULE_fieldInitializerList = 12
+	CELParserRULE_optField             = 13
+	CELParserRULE_mapInitializerList   = 14
+	CELParserRULE_mapEntry             = 15
+	CELParserRULE_optExpr              = 16
+	CELParserRULE_literal              = 17
 )
 
 // IStartContext is an interface to support dynamic dispatch.
@@ -389,14 +409,14 @@ func (p *CELParser) Start() (localctx IStartContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(32)
+		p.SetState(36)
 
 		var _x = p.Expr()
 
 		localctx.(*StartContext).e = _x
 	}
 	{
-		p.SetState(33)
+		p.SetState(37)
 		p.Match(CELParserEOF)
 	}
 
@@ -613,37 +633,37 @@ func (p *CELParser) Expr() (localctx IExprContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(35)
+		p.SetState(39)
 
 		var _x = p.ConditionalOr()
 
 		localctx.(*ExprContext).e = _x
 	}
-	p.SetState(41)
+	p.SetState(45)
 	p.GetErrorHandler().Sync(p)
 	_la = p.GetTokenStream().LA(1)
 
 	if _la == CELParserQUESTIONMARK {
 		{
-			p.SetState(36)
+			p.SetState(40)
 
 			var _m = p.Match(CELParserQUESTIONMARK)
 
 			localctx.(*ExprContext).op = _m
 		}
 		{
-			p.SetState(37)
+			p.SetState(41)
 
 			var _x = p.ConditionalOr()
 
 			localctx.(*ExprContext).e1 = _x
 		}
 		{
-			p.SetState(38)
+			p.SetState(42)
 			p.Match(CELParserCOLON)
 		}
 		{
-			p.SetState(39)
+			p.SetState(43)
 
 			var _x = p.Expr()
 
@@ -859,19 +879,19 @@ func (p *CELParser) ConditionalOr() (localctx IConditionalOrContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(43)
+		p.SetState(47)
 
 		var _x = p.ConditionalAnd()
 
 		localctx.(*ConditionalOrContext).e = _x
 	}
-	p.SetState(48)
+	p.SetState(52)
 	p.GetErrorHandler().Sync(p)
 	_la = p.GetTokenStream().LA(1)
 
 	for _la == CELParserLOGICAL_OR {
 		{
-			p.SetState(44)
+			p.SetState(48)
 
 			var _m = p.Match(CELParserLOGICAL_OR)
 
@@ -879,7 +899,7 @@ func (p *CELParser) ConditionalOr() (localctx IConditionalOrContext) {
 		}
 		localctx.(*ConditionalOrContext).ops = append(localctx.(*ConditionalOrContext).ops, localctx.(*ConditionalOrContext).s9)
 		{
-			p.SetState(45)
+			p.SetState(49)
 
 			var _x = p.ConditionalAnd()
 
@@ -887,7 +907,7 @@ func (p *CELParser) ConditionalOr() (localctx IConditionalOrContext) {
 		}
 		localctx.(*ConditionalOrContext).e1 = append(localctx.(*ConditionalOrContext).e1, localctx.(*ConditionalOrContext)._conditionalAnd)
 
-		p.SetState(50)
+		p.SetState(54)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
 	}
@@ -1099,19 +1119,19 @@ func (p *CELParser) ConditionalAnd() (localctx IConditionalAndContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(51)
+		p.SetState(55)
 
 		var _x = p.relation(0)
 
 		localctx.(*ConditionalAndContext).e = _x
 	}
-	p.SetState(56)
+	p.SetState(60)
 	p.GetErrorHandler().Sync(p)
 	_la = p.GetTokenStream().LA(1)
 
 	for _la == CELParserLOGICAL_AND {
 		{
-			p.SetState(52)
+			p.SetState(56)
 
 			var _m = p.Match(CELParserLOGICAL_AND)
 
@@ -1119,7 +1139,7 @@ func (p *CELParser) ConditionalAnd() (localctx IConditionalAndContext) {
 		}
 		localctx.(*ConditionalAndContext).ops = append(localctx.(*ConditionalAndContext).ops, localctx.(*ConditionalAndContext).s8)
 		{
-			p.SetState(53)
+			p.SetState(57)
 
 			var _x = p.relation(0)
 
@@ -1127,7 +1147,7 @@ func (p *CELParser) ConditionalAnd() (localctx IConditionalAndContext) {
 		}
 		localctx.(*ConditionalAndContext).e1 = append(localctx.(*ConditionalAndContext).e1, localctx.(*ConditionalAndContext)._relation)
 
-		p.SetState(58)
+		p.SetState(62)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
 	}
@@ -1348,12 +1368,12 @@ func (p *CELParser) relation(_p int) (localctx IRelationContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(60)
+		p.SetState(64)
 		p.calc(0)
 	}
 
 	p.GetParserRuleContext().SetStop(p.GetTokenStream().LT(-1))
-	p.SetState(67)
+	p.SetState(71)
 	p.GetErrorHandler().Sync(p)
 	_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 3, p.GetParserRuleContext())
 
@@ -1365,13 +1385,13 @@ func (p *CELParser) relation(_p int) (localctx IRelationContext) {
 			_prevctx = localctx
 			localctx = NewRelationContext(p, _parentctx, _parentState)
 			p.PushNewRecursionContext(localctx, _startState, CELParserRULE_relation)
-			p.SetState(62)
+			p.SetState(66)
 
 			if !(p.Precpred(p.GetParserRuleContext(), 1)) {
 				panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 1)", ""))
 			}
 			{
-				p.SetState(63)
+				p.SetState(67)
 
 				var _lt = p.GetTokenStream().LT(1)
 
@@ -1389,12 +1409,12 @@ func (p *CELParser) relation(_p int) (localctx IRelationContext) {
 				}
 			}
 			{
-				p.SetState(64)
+				p.SetState(68)
 				p.relation(2)
 			}
 
 		}
-		p.SetState(69)
+		p.SetState(73)
 		p.GetErrorHandler().Sync(p)
 		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 3, p.GetParserRuleContext())
 	}
@@ -1605,12 +1625,12 @@ func (p *CELParser) calc(_p int) (localctx ICalcContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(71)
+		p.SetState(75)
 		p.Unary()
 	}
 
 	p.GetParserRuleContext().SetStop(p.GetTokenStream().LT(-1))
-	p.SetState(81)
+	p.SetState(85)
 	p.GetErrorHandler().Sync(p)
 	_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 5, p.GetParserRuleContext())
 
@@ -1620,19 +1640,19 @@ func (p *CELParser) calc(_p int) (localctx ICalcContext) {
 				p.TriggerExitRuleEvent()
 			}
 			_prevctx = localctx
-			p.SetState(79)
+			p.SetState(83)
 			p.GetErrorHandler().Sync(p)
 			switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 4, p.GetParserRuleContext()) {
 			case 1:
 				localctx = NewCalcContext(p, _parentctx, _parentState)
 				p.PushNewRecursionContext(localctx, _startState, CELParserRULE_calc)
-				p.SetState(73)
+				p.SetState(77)
 
 				if !(p.Precpred(p.GetParserRuleContext(), 2)) {
 					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 2)", ""))
 				}
 				{
-					p.SetState(74)
+					p.SetState(78)
 
 					var _lt = p.GetTokenStream().LT(1)
 
@@ -1650,20 +1670,20 @@ func (p *CELParser) calc(_p int) (localctx ICalcContext) {
 					}
 				}
 				{
-					p.SetState(75)
+					p.SetState(79)
 					p.calc(3)
 				}
 
 			case 2:
 				localctx = NewCalcContext(p, _parentctx, _parentState)
 				p.PushNewRecursionContext(localctx, _startState, CELParserRULE_calc)
-				p.SetState(76)
+				p.SetState(80)
 
 				if !(p.Precpred(p.GetParserRuleContext(), 1)) {
 					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 1)", ""))
 				}
 				{
-					p.SetState(77)
+					p.SetState(81)
 
 					var _lt = p.GetTokenStream().LT(1)
 
@@ -1681,14 +1701,14 @@ func (p *CELParser) calc(_p int) (localctx ICalcContext) {
 					}
 				}
 				{
-					p.SetState(78)
+					p.SetState(82)
 					p.calc(2)
 				}
 
 			}
 
 		}
-		p.SetState(83)
+		p.SetState(87)
 		p.GetErrorHandler().Sync(p)
 		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 5, p.GetParserRuleContext())
 	}
@@ -1975,27 +1995,27 @@ func (p *CELParser) Unary() (localctx IUnaryContext) {
 
 	var _alt int
 
-	p.SetState(97)
+	p.SetState(101)
 	p.GetErrorHandler().Sync(p)
 	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 8, p.GetParserRuleContext()) {
 	case 1:
 		localctx = NewMemberExprContext(p, localctx)
 		p.EnterOuterAlt(localctx, 1)
 		{
-			p.SetState(84)
+			p.SetState(88)
 			p.member(0)
 		}
 
 	case 2:
 		localctx = NewLogicalNotContext(p, localctx)
 		p.EnterOuterAlt(localctx, 2)
-		p.SetState(86)
+		p.SetState(90)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
 
 		for ok := true; ok; ok = _la == CELParserEXCLAM {
 			{
-				p.SetState(85)
+				p.SetState(89)
 
 				var _m = p.Match(CELParserEXCLAM)
 
@@ -2003,26 +2023,26 @@ func (p *CELParser) Unary() (localctx IUnaryContext) {
 			}
 			localctx.(*LogicalNotContext).ops = append(localctx.(*LogicalNotContext).ops, localctx.(*LogicalNotContext).s19)
 
-			p.SetState(88)
+			p.SetState(92)
 			p.GetErrorHandler().Sync(p)
 			_la = p.GetTokenStream().LA(1)
 		}
 		{
-			p.SetState(90)
+			p.SetState(94)
 			p.member(0)
 		}
 
 	case 3:
 		localctx = NewNegateContext(p, localctx)
 		p.EnterOuterAlt(localctx, 3)
-		p.SetState(92)
+		p.SetState(96)
 		p.GetErrorHandler().Sync(p)
 		_alt = 1
 		for ok := true; ok; ok = _alt != 2 && _alt != antlr.ATNInvalidAltNumber {
 			switch _alt {
 			case 1:
 				{
-					p.SetState(91)
+					p.SetState(95)
 
 					var _m = p.Match(CELParserMINUS)
 
@@ -2034,12 +2054,12 @@ func (p *CELParser) Unary() (localctx IUnaryContext) {
 				panic(antlr.NewNoViableAltException(p, nil, nil, nil, nil, nil))
 			}
 
-			p.SetState(94)
+			p.SetState(98)
 			p.GetErrorHandler().Sync(p)
 			_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 7, p.GetParserRuleContext())
 		}
 		{
-			p.SetState(96)
+			p.SetState(100)
 			p.member(0)
 		}
 
@@ -2443,6 +2463,139 @@ func (s *IndexContext) Accept(visitor antlr.ParseTreeVisitor) interface{} {
 	}
 }
 
+type SliceContext struct {
+	*MemberContext
+	op    antlr.Token
+	lower IExprContext
+	upper IExprContext
+	step  IExprContext
+}
+
+func NewSliceContext(parser antlr.Parser, ctx antlr.ParserRuleContext) *SliceContext {
+	var p = new(SliceContext)
+
+	p.MemberContext = NewEmptyMemberContext()
+	p.parser = parser
+	p.CopyFrom(ctx.(*MemberContext))
+
+	return p
+}
+
+func (s *SliceContext) GetOp() antlr.Token { return s.op }
+
+func (s *SliceContext) SetOp(v antlr.Token) { s.op = v }
+
+func (s *SliceContext) GetLower() IExprContext { return s.lower }
+
+func (s *SliceContext) GetUpper() IExprContext { return s.upper }
+
+func (s *SliceContext) GetStep() IExprContext { return s.step }
+
+func (s *SliceContext) SetLower(v IExprContext) { s.lower = v }
+
+func (s *SliceContext) SetUpper(v IExprContext) { s.upper = v }
+
+func (s *SliceContext) SetStep(v IExprContext) { s.step = v }
+
+func (s *SliceContext) GetRuleContext() antlr.RuleContext {
+	return s
+}
+
+func (s *SliceContext) Member() IMemberContext {
+	var t antlr.RuleContext
+	for _, ctx := range s.GetChildren() {
+		if _, ok := ctx.(IMemberContext); ok {
+			t = ctx.(antlr.RuleContext)
+			break
+		}
+	}
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IMemberContext)
+}
+
+func (s *SliceContext) AllCOLON() []antlr.TerminalNode {
+	return s.GetTokens(CELParserCOLON)
+}
+
+func (s *SliceContext) COLON(i int) antlr.TerminalNode {
+	return s.GetToken(CELParserCOLON, i)
+}
+
+func (s *SliceContext) RPRACKET() antlr.TerminalNode {
+	return s.GetToken(CELParserRPRACKET, 0)
+}
+
+func (s *SliceContext) LBRACKET() antlr.TerminalNode {
+	return s.GetToken(CELParserLBRACKET, 0)
+}
+
+func (s *SliceContext) AllExpr() []IExprContext {
+	children := s.GetChildren()
+	len := 0
+	for _, ctx := range children {
+		if _, ok := ctx.(IExprContext); ok {
+			len++
+		}
+	}
+
+	tst := make([]IExprContext, len)
+	i := 0
+	for _, ctx := range children {
+		if t, ok := ctx.(IExprContext); ok {
+			tst[i] = t.(IExprContext)
+			i++
+		}
+	}
+
+	return tst
+}
+
+func (s *SliceContext) Expr(i int) IExprContext {
+	var t antlr.RuleContext
+	j := 0
+	for _, ctx := range s.GetChildren() {
+		if _, ok := ctx.(IExprContext); ok {
+			if j == i {
+				t = ctx.(antlr.RuleContext)
+				break
+			}
+			j++
+		}
+	}
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IExprContext)
+}
+
+func (s *SliceContext) EnterRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(CELListener); ok {
+		listenerT.EnterSlice(s)
+	}
+}
+
+func (s *SliceContext) ExitRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(CELListener); ok {
+		listenerT.ExitSlice(s)
+	}
+}
+
+func (s *SliceContext) Accept(visitor antlr.ParseTreeVisitor) interface{} {
+	switch t := visitor.(type) {
+	case CELVisitor:
+		return t.VisitSlice(s)
+
+	default:
+		return t.VisitChildren(s)
+	}
+}
+
 func (p *CELParser) Member() (localctx IMemberContext) {
 	return p.member(0)
 }
@@ -2484,14 +2637,14 @@ func (p *CELParser) member(_p int) (localctx IMemberContext) {
 	_prevctx = localctx
 
 	{
-		p.SetState(100)
+		p.SetState(104)
 		p.Primary()
 	}
 
 	p.GetParserRuleContext().SetStop(p.GetTokenStream().LT(-1))
-	p.SetState(126)
+	p.SetState(146)
 	p.GetErrorHandler().Sync(p)
-	_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 13, p.GetParserRuleContext())
+	_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 17, p.GetParserRuleContext())
 
 	for _alt != 2 && _alt != antlr.ATNInvalidAltNumber {
 		if _alt == 1 {
@@ -2499,31 +2652,31 @@ func (p *CELParser) member(_p int) (localctx IMemberContext) {
 				p.TriggerExitRuleEvent()
 			}
 			_prevctx = localctx
-			p.SetState(124)
+			p.SetState(144)
 			p.GetErrorHandler().Sync(p)
-			switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 12, p.GetParserRuleContext()) {
+			switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 16, p.GetParserRuleContext()) {
 			case 1:
 				localctx = NewSelectContext(p, NewMemberContext(p, _parentctx, _parentState))
 				p.PushNewRecursionContext(localctx, _startState, CELParserRULE_member)
-				p.SetState(102)
+				p.SetState(106)
 
-				if !(p.Precpred(p.GetParserRuleContext(), 3)) {
-					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 3)", ""))
+				if !(p.Precpred(p.GetParserRuleContext(), 4)) {
+					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 4)", ""))
 				}
 				{
-					p.SetState(103)
+					p.SetState(107)
 
 					var _m = p.Match(CELParserDOT)
 
 					localctx.(*SelectContext).op = _m
 				}
-				p.SetState(105)
+				p.SetState(109)
 				p.GetErrorHandler().Sync(p)
 				_la = p.GetTokenStream().LA(1)
 
 				if _la == CELParserQUESTIONMARK {
 					{
-						p.SetState(104)
+						p.SetState(108)
 
 						var _m = p.Match(CELParserQUESTIONMARK)
 
@@ -2532,7 +2685,7 @@ func (p *CELParser) member(_p int) (localctx IMemberContext) {
 
 				}
 				{
-					p.SetState(107)
+					p.SetState(111)
 
 					var _m = p.Match(CELParserIDENTIFIER)
 
@@ -2542,39 +2695,39 @@ func (p *CELParser) member(_p int) (localctx IMemberContext) {
 			case 2:
 				localctx = NewMemberCallContext(p, NewMemberContext(p, _parentctx, _parentState))
 				p.PushNewRecursionContext(localctx, _startState, CELParserRULE_member)
-				p.SetState(108)
+				p.SetState(112)
 
-				if !(p.Precpred(p.GetParserRuleContext(), 2)) {
-					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 2)", ""))
+				if !(p.Precpred(p.GetParserRuleContext(), 3)) {
+					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 3)", ""))
 				}
 				{
-					p.SetState(109)
+					p.SetState(113)
 
 					var _m = p.Match(CELParserDOT)
 
 					localctx.(*MemberCallContext).op = _m
 				}
 				{
-					p.SetState(110)
+					p.SetState(114)
 
 					var _m = p.Match(CELParserIDENTIFIER)
 
 					localctx.(*MemberCallContext).id = _m
 				}
 				{
-					p.SetState(111)
+					p.SetState(115)
 
 					var _m = p.Match(CELParserLPAREN)
 
 					localctx.(*MemberCallContext).open = _m
 				}
-				p.SetState(113)
+				p.SetState(117)
 				p.GetErrorHandler().Sync(p)
 				_la = p.GetTokenStream().LA(1)
 
 				if (int64(_la) & ^0x3f) == 0 && ((int64(1)<<_la)&135762105344) != 0 {
 					{
-						p.SetState(112)
+						p.SetState(116)
 
 						var _x = p.ExprList()
 
@@ -2583,32 +2736,32 @@ func (p *CELParser) member(_p int) (localctx IMemberContext) {
 
 				}
 				{
-					p.SetState(115)
+					p.SetState(119)
 					p.Match(CELParserRPAREN)
 				}
 
 			case 3:
 				localctx = NewIndexContext(p, NewMemberContext(p, _parentctx, _parentState))
 				p.PushNewRecursionContext(localctx, _startState, CELParserRULE_member)
-				p.SetState(116)
+				p.SetState(120)
 
-				if !(p.Precpred(p.GetParserRuleContext(), 1)) {
-					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 1)", ""))
+				if !(p.Precpred(p.GetParserRuleContext(), 2)) {
+					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 2)", ""))
 				}
 				{
-					p.SetState(117)
+					p.SetState(121)
 
 					var _m = p.Match(CELParserLBRACKET)
 
 					localctx.(*IndexContext).op = _m
 				}
-				p.SetState(119)
+				p.SetState(123)
 				p.GetErrorHandler().Sync(p)
 				_la = p.GetTokenStream().LA(1)
 
 				if _la == CELParserQUESTIONMARK {
 					{
-						p.SetState(118)
+						p.SetState(122)
 
 						var _m = p.Match(CELParserQUESTIONMARK)
 
@@ -2617,23 +2770,100 @@ func (p *CELParser) member(_p int) (localctx IMemberContext) {
 
 				}
 				{
-					p.SetState(121)
+					p.SetState(125)
 
 					var _x = p.Expr()
 
 					localctx.(*IndexContext).index = _x
 				}
 				{
-					p.SetState(122)
+					p.SetState(126)
+					p.Match(CELParserRPRACKET)
+				}
+
+			case 4:
+				localctx = NewSliceContext(p, NewMemberContext(p, _parentctx, _parentState))
+				p.PushNewRecursionContext(localctx, _startState, CELParserRULE_member)
+				p.SetState(128)
+
+				if !(p.Precpred(p.GetParserRuleContext(), 1)) {
+					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 1)", ""))
+				}
+				{
+					p.SetState(129)
+
+					var _m = p.Match(CELParserLBRACKET)
+
+					localctx.(*SliceContext).op = _m
+				}
+				p.SetState(131)
+				p.GetErrorHandler().Sync(p)
+				_la = p.GetTokenStream().LA(1)
+
+				if (int64(_la) & ^0x3f) == 0 && ((int64(1)<<_la)&135762105344) != 0 {
+					{
+						p.SetState(130)
+
+						var _x = p.Expr()
+
+						localctx.(*SliceContext).lower = _x
+					}
+
+				}
+				{
+					p.SetState(133)
+					p.Match(CELParserCOLON)
+				}
+				p.SetState(135)
+				p.GetErrorHandler().Sync(p)
+				_la = p.GetTokenStream().LA(1)
+
+				if (int64(_la) & ^0x3f) == 0 && ((int64(1)<<_la)&135762105344) != 0 {
+					{
+						p.SetState(134)
+
+						var _x = p.Expr()
+
+						localctx.(*SliceContext).upper = _x
+					}
+
+				}
+				p.SetState(141)
+				p.GetErrorHandler().Sync(p)
+				_la = p.GetTokenStream().LA(1)
+
+				if _la == CELParserCOLON {
+					{
+						p.SetState(137)
+						p.Match(CELParserCOLON)
+					}
+					p.SetState(139)
+					p.GetErrorHandler().Sync(p)
+					_la = p.GetTokenStream().LA(1)
+
+					if (int64(_la) & ^0x3f) == 0 && ((int64(1)<<_la)&135762105344) != 0 {
+						{
+							p.SetState(138)
+
+							var _x = p.Expr()
+
+							localctx.(*SliceContext).step = _x
+						}
+
+					}
+
+				}
+				{
+					p.SetState(143)
 					p.Match(CELParserRPRACKET)
 				}
 
 			}
 
 		}
-		p.SetState(128)
+		p.SetState(148)
 		p.GetErrorHandler().Sync(p)
-		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 13, p.GetParserRuleContext())
+		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 17, p.GetParserRuleContext())
 	}
 
 	return localctx
@@ -3204,19 +3434,19 @@ func (p *CELParser) Primary() (localctx IPrimaryContext) {
 		}
 	}()
 
-	p.SetState(180)
+	p.SetState(200)
 	p.GetErrorHandler().Sync(p)
-	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 25, p.GetParserRuleContext()) {
+	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 29, p.GetParserRuleContext()) {
 	case 1:
 		localctx = NewIdentOrGlobalCallContext(p, localctx)
 		p.EnterOuterAlt(localctx, 1)
-		p.SetState(130)
+		p.SetState(150)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
 
 		if _la == CELParserDOT {
 			{
-				p.SetState(129)
+				p.SetState(149)
 
 				var _m = p.Match(CELParserDOT)
 
@@ -3225,30 +3455,30 @@ func (p *CELParser) Primary() (localctx IPrimaryContext) {
 
 		}
 		{
-			p.SetState(132)
+			p.SetState(152)
 
 			var _m = p.Match(CELParserIDENTIFIER)
 
 			localctx.(*IdentOrGlobalCallContext).id = _m
 		}
-		p.SetState(138)
+		p.SetState(158)
 		p.GetErrorHandler().Sync(p)
 
-		if p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 16, p.GetParserRuleContext()) == 1 {
+		if p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 20, p.GetParserRuleContext()) == 1 {
 			{
-				p.SetState(133)
+				p.SetState(153)
 
 				var _m = p.Match(CELParserLPAREN)
 
 				localctx.(*IdentOrGlobalCallContext).op = _m
 			}
-			p.SetState(135)
+			p.SetState(155)
 			p.GetErrorHandler().Sync(p)
 			_la = p.GetTokenStream().LA(1)
 
 			if (int64(_la) & ^0x3f) == 0 && ((int64(1)<<_la)&135762105344) != 0 {
 				{
-					p.SetState(134)
+					p.SetState(154)
 
 					var _x = p.ExprList()
 
@@ -3257,7 +3487,7 @@ func (p *CELParser) Primary() (localctx IPrimaryContext) {
 
 			}
 			{
-				p.SetState(137)
+				p.SetState(157)
 				p.Match(CELParserRPAREN)
 			}
 
@@ -3267,18 +3497,18 @@ func (p *CELParser) Primary() (localctx IPrimaryContext) {
 		localctx = NewNestedContext(p, localctx)
 		p.EnterOuterAlt(localctx, 2)
 		{
-			p.SetState(140)
+			p.SetState(160)
 			p.Match(CELParserLPAREN)
 		}
 		{
-			p.SetState(141)
+			p.SetState(161)
 
 			var _x = p.Expr()
 
 			localctx.(*NestedContext).e = _x
 		}
 		{
-			p.SetState(142)
+			p.SetState(162)
 			p.Match(CELParserRPAREN)
 		}
 
@@ -3286,19 +3516,19 @@ func (p *CELParser) Primary() (localctx IPrimaryContext) {
 		localctx = NewCreateListContext(p, localctx)
 		p.EnterOuterAlt(localctx, 3)
 		{
-			p.SetState(144)
+			p.SetState(164)
 
 			var _m = p.Match(CELParserLBRACKET)
 
 			localctx.(*CreateListContext).op = _m
 		}
-		p.SetState(146)
+		p.SetState(166)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
 
-		if (int64(_la) & ^0x3f) == 0 && ((int64(1)<<_la)&135763153920) != 0 {
+		if (int64(_la) & ^0x3f) == 0 && ((int64(1)<<_la)&273202107392) != 0 {
 			{
-				p.SetState(145)
+				p.SetState(165)
 
 				var _x = p.ListInit()
 
@@ -3306,19 +3536,19 @@ func (p *CELParser) Primary() (localctx IPrimaryContext) {
 			}
 
 		}
-		p.SetState(149)
+		p.SetState(169)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
 
 		if _la == CELParserCOMMA {
 			{
-				p.SetState(148)
+				p.SetState(168)
 				p.Match(CELParserCOMMA)
 			}
 
 		}
 		{
-			p.SetState(151)
+			p.SetState(171)
 			p.Match(CELParserRPRACKET)
 		}
 
@@ -3326,19 +3556,19 @@ func (p *CELParser) Primary() (localctx IPrimaryContext) {
 		localctx = NewCreateStructContext(p, localctx)
 		p.EnterOuterAlt(localctx, 4)
 		{
-			p.SetState(152)
+			p.SetState(172)
 
 			var _m = p.Match(CELParserLBRACE)
 
 			localctx.(*CreateStructContext).op = _m
 		}
-		p.SetState(154)
+		p.SetState(174)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
 
-		if (int64(_la) & ^0x3f) == 0 && ((int64(1)<<_la)&135763153920) != 0 {
+		if (int64(_la) & ^0x3f) == 0 && ((int64(1)<<_la)&273202107392) != 0 {
 			{
-				p.SetState(153)
+				p.SetState(173)
 
 				var _x = p.MapInitializerList()
 
@@ -3346,32 +3576,32 @@ func (p *CELParser) Primary() (localctx IPrimaryContext) {
 			}
 
 		}
-		p.SetState(157)
+		p.SetState(177)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
 
 		if _la == CELParserCOMMA {
 			{
-				p.SetState(156)
+				p.SetState(176)
 				p.Match(CELParserCOMMA)
 			}
 
 		}
 		{
-			p.SetState(159)
+			p.SetState(179)
 			p.Match(CELParserRBRACE)
 		}
 
 	case 5:
 		localctx = NewCreateMessageContext(p, localctx)
 		p.EnterOuterAlt(localctx, 5)
-		p.SetState(161)
+		p.SetState(181)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
 
 		if _la == CELParserDOT {
 			{
-				p.SetState(160)
+				p.SetState(180)
 
 				var _m = p.Match(CELParserDOT)
 
@@ -3380,20 +3610,20 @@ func (p *CELParser) Primary() (localctx IPrimaryContext) {
 
 		}
 		{
-			p.SetState(163)
+			p.SetState(183)
 
 			var _m = p.Match(CELParserIDENTIFIER)
 
 			localctx.(*CreateMessageContext)._IDENTIFIER = _m
 		}
 		localctx.(*CreateMessageContext).ids = append(localctx.(*CreateMessageContext).ids, localctx.(*CreateMessageContext)._IDENTIFIER)
-		p.SetState(168)
+		p.SetState(188)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
 
 		for _la == CELParserDOT {
 			{
-				p.SetState(164)
+				p.SetState(184)
 
 				var _m = p.Match(CELParserDOT)
 
@@ -3401,7 +3631,7 @@ func (p *CELParser) Primary() (localctx IPrimaryContext) {
 			}
 			localctx.(*CreateMessageContext).ops = append(localctx.(*CreateMessageContext).ops, localctx.(*CreateMessageContext).s16)
 			{
-				p.SetState(165)
+				p.SetState(185)
 
 				var _m = p.Match(CELParserIDENTIFIER)
 
@@ -3409,24 +3639,24 @@ func (p *CELParser) Primary() (localctx IPrimaryContext) {
 			}
 			localctx.(*CreateMessageContext).ids = append(localctx.(*CreateMessageContext).ids, localctx.(*CreateMessageContext)._IDENTIFIER)
 
-			p.SetState(170)
+			p.SetState(190)
 			p.GetErrorHandler().Sync(p)
 			_la = p.GetTokenStream().LA(1)
 		}
 		{
-			p.SetState(171)
+			p.SetState(191)
 
 			var _m = p.Match(CELParserLBRACE)
 
 			localctx.(*CreateMessageContext).op = _m
 		}
-		p.SetState(173)
+		p.SetState(193)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
 
 		if _la == CELParserQUESTIONMARK || _la == CELParserIDENTIFIER {
 			{
-				p.SetState(172)
+				p.SetState(192)
 
 				var _x = p.FieldInitializerList()
 
@@ -3434,19 +3664,19 @@ func (p *CELParser) Primary() (localctx IPrimaryContext) {
 			}
 
 		}
-		p.SetState(176)
+		p.SetState(196)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
 
 		if _la == CELParserCOMMA {
 			{
-				p.SetState(175)
+				p.SetState(195)
 				p.Match(CELParserCOMMA)
 			}
 
 		}
 		{
-			p.SetState(178)
+			p.SetState(198)
 			p.Match(CELParserRBRACE)
 		}
 
@@ -3454,7 +3684,7 @@ func (p *CELParser) Primary() (localctx IPrimaryContext) {
 		localctx = NewConstantLiteralContext(p, localctx)
 		p.EnterOuterAlt(localctx, 6)
 		{
-			p.SetState(179)
+			p.SetState(199)
 			p.Literal()
 		}
 
@@ -3634,24 +3864,24 @@ func (p *CELParser) ExprList() (localctx IExprListContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(182)
+		p.SetState(202)
 
 		var _x = p.Expr()
 
 		localctx.(*ExprListContext)._expr = _x
 	}
 	localctx.(*ExprListContext).e = append(localctx.(*ExprListContext).e, localctx.(*ExprListContext)._expr)
-	p.SetState(187)
+	p.SetState(207)
 	p.GetErrorHandler().Sync(p)
 	_la = p.GetTokenStream().LA(1)
 
 	for _la == CELParserCOMMA {
 		{
-			p.SetState(183)
+			p.SetState(203)
 			p.Match(CELParserCOMMA)
 		}
 		{
-			p.SetState(184)
+			p.SetState(204)
 
 			var _x = p.Expr()
 
@@ -3659,7 +3889,7 @@ func (p *CELParser) ExprList() (localctx IExprListContext) {
 		}
 		localctx.(*ExprListContext).e = append(localctx.(*ExprListContext).e, localctx.(*ExprListContext)._expr)
 
-		p.SetState(189)
+		p.SetState(209)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
 	}
@@ -3674,21 +3904,21 @@ type IListInitContext interface {
 	// GetParser returns the parser.
 	GetParser() antlr.Parser
 
-	// Get_optExpr returns the _optExpr rule contexts.
-	Get_optExpr() IOptExprContext
+	// Get_listElement returns the _listElement rule contexts.
+	Get_listElement() IListElementContext
 
-	// Set_optExpr sets the _optExpr rule contexts.
-	Set_optExpr(IOptExprContext)
+	// Set_listElement sets the _listElement rule contexts.
+	Set_listElement(IListElementContext)
 
 	// GetElems returns the elems rule context list.
-	GetElems() []IOptExprContext
+	GetElems() []IListElementContext
 
 	// SetElems sets the elems rule context list.
-	SetElems([]IOptExprContext)
+	SetElems([]IListElementContext)
 
 	// Getter signatures
-	AllOptExpr() []IOptExprContext
-	OptExpr(i int) IOptExprContext
+	AllListElement() []IListElementContext
+	ListElement(i int) IListElementContext
 	AllCOMMA() []antlr.TerminalNode
 	COMMA(i int) antlr.TerminalNode
 
@@ -3698,9 +3928,9 @@ type IListInitContext interface {
 
 type ListInitContext struct {
 	*antlr.BaseParserRuleContext
-	parser   antlr.Parser
-	_optExpr IOptExprContext
-	elems    []IOptExprContext
+	parser       antlr.Parser
+	_listElement IListElementContext
+	elems        []IListElementContext
 }
 
 func NewEmptyListInitContext() *ListInitContext {
@@ -3725,28 +3955,28 @@ func NewListInitContext(parser antlr.Parser, parent antlr.ParserRuleContext, inv
 
 func (s *ListInitContext) GetParser() antlr.Parser { return s.parser }
 
-func (s *ListInitContext) Get_optExpr() IOptExprContext { return s._optExpr }
+func (s *ListInitContext) Get_listElement() IListElementContext { return s._listElement }
 
-func (s *ListInitContext) Set_optExpr(v IOptExprContext) { s._optExpr = v }
+func (s *ListInitContext) Set_listElement(v IListElementContext) { s._listElement = v }
 
-func (s *ListInitContext) GetElems() []IOptExprContext { return s.elems }
+func (s *ListInitContext) GetElems() []IListElementContext { return s.elems }
 
-func (s *ListInitContext) SetElems(v []IOptExprContext) { s.elems = v }
+func (s *ListInitContext) SetElems(v []IListElementContext) { s.elems = v }
 
-func (s *ListInitContext) AllOptExpr() []IOptExprContext {
+func (s *ListInitContext) AllListElement() []IListElementContext {
 	children := s.GetChildren()
 	len := 0
 	for _, ctx := range children {
-		if _, ok := ctx.(IOptExprContext); ok {
+		if _, ok := ctx.(IListElementContext); ok {
 			len++
 		}
 	}
 
-	tst := make([]IOptExprContext, len)
+	tst := make([]IListElementContext, len)
 	i := 0
 	for _, ctx := range children {
-		if t, ok := ctx.(IOptExprContext); ok {
-			tst[i] = t.(IOptExprContext)
+		if t, ok := ctx.(IListElementContext); ok {
+			tst[i] = t.(IListElementContext)
 			i++
 		}
 	}
@@ -3754,11 +3984,11 @@ func (s *ListInitContext) AllOptExpr() []IOptExprContext {
 	return tst
 }
 
-func (s *ListInitContext) OptExpr(i int) IOptExprContext {
+func (s *ListInitContext) ListElement(i int) IListElementContext {
 	var t antlr.RuleContext
 	j := 0
 	for _, ctx := range s.GetChildren() {
-		if _, ok := ctx.(IOptExprContext); ok {
+		if _, ok := ctx.(IListElementContext); ok {
 			if j == i {
 				t = ctx.(antlr.RuleContext)
 				break
@@ -3771,7 +4001,7 @@ func (s *ListInitContext) OptExpr(i int) IOptExprContext {
 		return nil
 	}
 
-	return t.(IOptExprContext)
+	return t.(IListElementContext)
 }
 
 func (s *ListInitContext) AllCOMMA() []antlr.TerminalNode {
@@ -3839,95 +4069,301 @@ func (p *CELParser) ListInit() (localctx IListInitContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(190)
+		p.SetState(210)
 
-		var _x = p.OptExpr()
+		var _x = p.ListElement()
 
-		localctx.(*ListInitContext)._optExpr = _x
+		localctx.(*ListInitContext)._listElement = _x
 	}
-	localctx.(*ListInitContext).elems = append(localctx.(*ListInitContext).elems, localctx.(*ListInitContext)._optExpr)
-	p.SetState(195)
+	localctx.(*ListInitContext).elems = append(localctx.(*ListInitContext).elems, localctx.(*ListInitContext)._listElement)
+	p.SetState(215)
 	p.GetErrorHandler().Sync(p)
-	_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 27, p.GetParserRuleContext())
+	_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 31, p.GetParserRuleContext())
 
 	for _alt != 2 && _alt != antlr.ATNInvalidAltNumber {
 		if _alt == 1 {
 			{
-				p.SetState(191)
+				p.SetState(211)
 				p.Match(CELParserCOMMA)
 			}
 			{
-				p.SetState(192)
+				p.SetState(212)
 
-				var _x = p.OptExpr()
+				var _x = p.ListElement()
 
-				localctx.(*ListInitContext)._optExpr = _x
+				localctx.(*ListInitContext)._listElement = _x
 			}
-			localctx.(*ListInitContext).elems = append(localctx.(*ListInitContext).elems, localctx.(*ListInitContext)._optExpr)
+			localctx.(*ListInitContext).elems = append(localctx.(*ListInitContext).elems, localctx.(*ListInitContext)._listElement)
 
 		}
-		p.SetState(197)
+		p.SetState(217)
 		p.GetErrorHandler().Sync(p)
-		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 27, p.GetParserRuleContext())
+		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 31, p.GetParserRuleContext())
 	}
 
 	return localctx
 }
 
-// IFieldInitializerListContext is an interface to support dynamic dispatch.
-type IFieldInitializerListContext interface {
+// IListElementContext is an interface to support dynamic dispatch.
+type IListElementContext interface {
 	antlr.ParserRuleContext
 
 	// GetParser returns the parser.
 	GetParser() antlr.Parser
 
-	// GetS21 returns the s21 token.
-	GetS21() antlr.Token
+	// GetOpt returns the opt token.
+	GetOpt() antlr.Token
 
-	// SetS21 sets the s21 token.
-	SetS21(antlr.Token)
+	// GetSpread returns the spread token.
+	GetSpread() antlr.Token
 
-	// GetCols returns the cols token list.
-	GetCols() []antlr.Token
+	// SetOpt sets the opt token.
+	SetOpt(antlr.Token)
 
-	// SetCols sets the cols token list.
-	SetCols([]antlr.Token)
+	// SetSpread sets the spread token.
+	SetSpread(antlr.Token)
 
-	// Get_optField returns the _optField rule contexts.
-	Get_optField() IOptFieldContext
+	// GetE returns the e rule contexts.
+	GetE() IExprContext
 
-	// Get_expr returns the _expr rule contexts.
-	Get_expr() IExprContext
+	// SetE sets the e rule contexts.
+	SetE(IExprContext)
 
-	// Set_optField sets the _optField rule contexts.
-	Set_optField(IOptFieldContext)
+	// Getter signatures
+	Expr() IExprContext
+	QUESTIONMARK() antlr.TerminalNode
+	ELLIPSIS() antlr.TerminalNode
 
-	// Set_expr sets the _expr rule contexts.
-	Set_expr(IExprContext)
+	// IsListElementContext differentiates from other interfaces.
+	IsListElementContext()
+}
 
-	// GetFields returns the fields rule context list.
-	GetFields() []IOptFieldContext
+type ListElementContext struct {
+	*antlr.BaseParserRuleContext
+	parser antlr.Parser
+	opt    antlr.Token
+	e      IExprContext
+	spread antlr.Token
+}
 
-	// GetValues returns the values rule context list.
-	GetValues() []IExprContext
+func NewEmptyListElementContext() *ListElementContext {
+	var p = new(ListElementContext)
+	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(nil, -1)
+	p.RuleIndex = CELParserRULE_listElement
+	return p
+}
 
-	// SetFields sets the fields rule context list.
-	SetFields([]IOptFieldContext)
+func (*ListElementContext) IsListElementContext() {}
 
-	// SetValues sets the values rule context list.
-	SetValues([]IExprContext)
+func NewListElementContext(parser antlr.Parser, parent antlr.ParserRuleContext, invokingState int) *ListElementContext {
+	var p = new(ListElementContext)
 
-	// Getter signatures
-	AllOptField() []IOptFieldContext
-	OptField(i int) IOptFieldContext
-	AllCOLON() []antlr.TerminalNode
-	COLON(i int) antlr.TerminalNode
-	AllExpr() []IExprContext
-	Expr(i int) IExprContext
-	AllCOMMA() []antlr.TerminalNode
-	COMMA(i int) antlr.TerminalNode
+	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(parent, invokingState)
 
-	// IsFieldInitializerListContext differentiates from other interfaces.
+	p.parser = parser
+	p.RuleIndex = CELParserRULE_listElement
+
+	return p
+}
+
+func (s *ListElementContext) GetParser() antlr.Parser { return s.parser }
+
+func (s *ListElementContext) GetOpt() antlr.Token { return s.opt }
+
+func (s *ListElementContext) GetSpread() antlr.Token { return s.spread }
+
+func (s *ListElementContext) SetOpt(v antlr.Token) { s.opt = v }
+
+func (s *ListElementContext) SetSpread(v antlr.Token) { s.spread = v }
+
+func (s *ListElementContext) GetE() IExprContext { return s.e }
+
+func (s *ListElementContext) SetE(v IExprContext) { s.e = v }
+
+func (s *ListElementContext) Expr() IExprContext {
+	var t antlr.RuleContext
+	for _, ctx := range s.GetChildren() {
+		if _, ok := ctx.(IExprContext); ok {
+			t = ctx.(antlr.RuleContext)
+			break
+		}
+	}
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IExprContext)
+}
+
+func (s *ListElementContext) QUESTIONMARK() antlr.TerminalNode {
+	return s.GetToken(CELParserQUESTIONMARK, 0)
+}
+
+func (s *ListElementContext) ELLIPSIS() antlr.TerminalNode {
+	return s.GetToken(CELParserELLIPSIS, 0)
+}
+
+func (s *ListElementContext) GetRuleContext() antlr.RuleContext {
+	return s
+}
+
+func (s *ListElementContext) ToStringTree(ruleNames []string, recog antlr.Recognizer) string {
+	return antlr.TreesStringTree(s, ruleNames, recog)
+}
+
+func (s *ListElementContext) EnterRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(CELListener); ok {
+		listenerT.EnterListElement(s)
+	}
+}
+
+func (s *ListElementContext) ExitRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(CELListener); ok {
+		listenerT.ExitListElement(s)
+	}
+}
+
+func (s *ListElementContext) Accept(visitor antlr.ParseTreeVisitor) interface{} {
+	switch t := visitor.(type) {
+	case CELVisitor:
+		return t.VisitListElement(s)
+
+	default:
+		return t.VisitChildren(s)
+	}
+}
+
+func (p *CELParser) ListElement() (localctx IListElementContext) {
+	this := p
+	_ = this
+
+	localctx = NewListElementContext(p, p.GetParserRuleContext(), p.GetState())
+	p.EnterRule(localctx, 22, CELParserRULE_listElement)
+	var _la int
+
+	defer func() {
+		p.ExitRule()
+	}()
+
+	defer func() {
+		if err := recover(); err != nil {
+			if v, ok := err.(antlr.RecognitionException); ok {
+				localctx.SetException(v)
+				p.GetErrorHandler().ReportError(p, v)
+				p.GetErrorHandler().Recover(p, v)
+			} else {
+				panic(err)
+			}
+		}
+	}()
+
+	p.SetState(224)
+	p.GetErrorHandler().Sync(p)
+
+	switch p.GetTokenStream().LA(1) {
+	case CELParserLBRACKET, CELParserLBRACE, CELParserLPAREN, CELParserDOT, CELParserMINUS, CELParserEXCLAM, CELParserQUESTIONMARK, CELParserCEL_TRUE, CELParserCEL_FALSE, CELParserNUL, CELParserNUM_FLOAT, CELParserNUM_INT, CELParserNUM_UINT, CELParserSTRING, CELParserBYTES, CELParserIDENTIFIER:
+		p.EnterOuterAlt(localctx, 1)
+		p.SetState(219)
+		p.GetErrorHandler().Sync(p)
+		_la = p.GetTokenStream().LA(1)
+
+		if _la == CELParserQUESTIONMARK {
+			{
+				p.SetState(218)
+
+				var _m = p.Match(CELParserQUESTIONMARK)
+
+				localctx.(*ListElementContext).opt = _m
+			}
+
+		}
+		{
+			p.SetState(221)
+
+			var _x = p.Expr()
+
+			localctx.(*ListElementContext).e = _x
+		}
+
+	case CELParserELLIPSIS:
+		p.EnterOuterAlt(localctx, 2)
+		{
+			p.SetState(222)
+
+			var _m = p.Match(CELParserELLIPSIS)
+
+			localctx.(*ListElementContext).spread = _m
+		}
+		{
+			p.SetState(223)
+
+			var _x = p.Expr()
+
+			localctx.(*ListElementContext).e = _x
+		}
+
+	default:
+		panic(antlr.NewNoViableAltException(p, nil, nil, nil, nil, nil))
+	}
+
+	return localctx
+}
+
+// IFieldInitializerListContext is an interface to support dynamic dispatch.
+type IFieldInitializerListContext interface {
+	antlr.ParserRuleContext
+
+	// GetParser returns the parser.
+	GetParser() antlr.Parser
+
+	// GetS21 returns the s21 token.
+	GetS21() antlr.Token
+
+	// SetS21 sets the s21 token.
+	SetS21(antlr.Token)
+
+	// GetCols returns the cols token list.
+	GetCols() []antlr.Token
+
+	// SetCols sets the cols token list.
+	SetCols([]antlr.Token)
+
+	// Get_optField returns the _optField rule contexts.
+	Get_optField() IOptFieldContext
+
+	// Get_expr returns the _expr rule contexts.
+	Get_expr() IExprContext
+
+	// Set_optField sets the _optField rule contexts.
+	Set_optField(IOptFieldContext)
+
+	// Set_expr sets the _expr rule contexts.
+	Set_expr(IExprContext)
+
+	// GetFields returns the fields rule context list.
+	GetFields() []IOptFieldContext
+
+	// GetValues returns the values rule context list.
+	GetValues() []IExprContext
+
+	// SetFields sets the fields rule context list.
+	SetFields([]IOptFieldContext)
+
+	// SetValues sets the values rule context list.
+	SetValues([]IExprContext)
+
+	// Getter signatures
+	AllOptField() []IOptFieldContext
+	OptField(i int) IOptFieldContext
+	AllCOLON() []antlr.TerminalNode
+	COLON(i int) antlr.TerminalNode
+	AllExpr() []IExprContext
+	Expr(i int) IExprContext
+	AllCOMMA() []antlr.TerminalNode
+	COMMA(i int) antlr.TerminalNode
+
+	// IsFieldInitializerListContext differentiates from other interfaces.
 	IsFieldInitializerListContext()
 }
 
@@ -4121,7 +4557,7 @@ func (p *CELParser) FieldInitializerList() (localctx IFieldInitializerListContex
 	_ = this
 
 	localctx = NewFieldInitializerListContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 22, CELParserRULE_fieldInitializerList)
+	p.EnterRule(localctx, 24, CELParserRULE_fieldInitializerList)
 
 	defer func() {
 		p.ExitRule()
@@ -4143,7 +4579,7 @@ func (p *CELParser) FieldInitializerList() (localctx IFieldInitializerListContex
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(198)
+		p.SetState(226)
 
 		var _x = p.OptField()
 
@@ -4151,7 +4587,7 @@ func (p *CELParser) FieldInitializerList() (localctx IFieldInitializerListContex
 	}
 	localctx.(*FieldInitializerListContext).fields = append(localctx.(*FieldInitializerListContext).fields, localctx.(*FieldInitializerListContext)._optField)
 	{
-		p.SetState(199)
+		p.SetState(227)
 
 		var _m = p.Match(CELParserCOLON)
 
@@ -4159,25 +4595,25 @@ func (p *CELParser) FieldInitializerList() (localctx IFieldInitializerListContex
 	}
 	localctx.(*FieldInitializerListContext).cols = append(localctx.(*FieldInitializerListContext).cols, localctx.(*FieldInitializerListContext).s21)
 	{
-		p.SetState(200)
+		p.SetState(228)
 
 		var _x = p.Expr()
 
 		localctx.(*FieldInitializerListContext)._expr = _x
 	}
 	localctx.(*FieldInitializerListContext).values = append(localctx.(*FieldInitializerListContext).values, localctx.(*FieldInitializerListContext)._expr)
-	p.SetState(208)
+	p.SetState(236)
 	p.GetErrorHandler().Sync(p)
-	_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 28, p.GetParserRuleContext())
+	_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 34, p.GetParserRuleContext())
 
 	for _alt != 2 && _alt != antlr.ATNInvalidAltNumber {
 		if _alt == 1 {
 			{
-				p.SetState(201)
+				p.SetState(229)
 				p.Match(CELParserCOMMA)
 			}
 			{
-				p.SetState(202)
+				p.SetState(230)
 
 				var _x = p.OptField()
 
@@ -4185,7 +4621,7 @@ func (p *CELParser) FieldInitializerList() (localctx IFieldInitializerListContex
 			}
 			localctx.(*FieldInitializerListContext).fields = append(localctx.(*FieldInitializerListContext).fields, localctx.(*FieldInitializerListContext)._optField)
 			{
-				p.SetState(203)
+				p.SetState(231)
 
 				var _m = p.Match(CELParserCOLON)
 
@@ -4193,7 +4629,7 @@ func (p *CELParser) FieldInitializerList() (localctx IFieldInitializerListContex
 			}
 			localctx.(*FieldInitializerListContext).cols = append(localctx.(*FieldInitializerListContext).cols, localctx.(*FieldInitializerListContext).s21)
 			{
-				p.SetState(204)
+				p.SetState(232)
 
 				var _x = p.Expr()
 
@@ -4202,9 +4638,9 @@ func (p *CELParser) FieldInitializerList() (localctx IFieldInitializerListContex
 			localctx.(*FieldInitializerListContext).values = append(localctx.(*FieldInitializerListContext).values, localctx.(*FieldInitializerListContext)._expr)
 
 		}
-		p.SetState(210)
+		p.SetState(238)
 		p.GetErrorHandler().Sync(p)
-		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 28, p.GetParserRuleContext())
+		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 34, p.GetParserRuleContext())
 	}
 
 	return localctx
@@ -4306,7 +4742,7 @@ func (p *CELParser) OptField() (localctx IOptFieldContext) {
 	_ = this
 
 	localctx = NewOptFieldContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 24, CELParserRULE_optField)
+	p.EnterRule(localctx, 26, CELParserRULE_optField)
 	var _la int
 
 	defer func() {
@@ -4326,13 +4762,13 @@ func (p *CELParser) OptField() (localctx IOptFieldContext) {
 	}()
 
 	p.EnterOuterAlt(localctx, 1)
-	p.SetState(212)
+	p.SetState(240)
 	p.GetErrorHandler().Sync(p)
 	_la = p.GetTokenStream().LA(1)
 
 	if _la == CELParserQUESTIONMARK {
 		{
-			p.SetState(211)
+			p.SetState(239)
 
 			var _m = p.Match(CELParserQUESTIONMARK)
 
@@ -4341,7 +4777,7 @@ func (p *CELParser) OptField() (localctx IOptFieldContext) {
 
 	}
 	{
-		p.SetState(214)
+		p.SetState(242)
 		p.Match(CELParserIDENTIFIER)
 	}
 
@@ -4355,49 +4791,21 @@ type IMapInitializerListContext interface {
 	// GetParser returns the parser.
 	GetParser() antlr.Parser
 
-	// GetS21 returns the s21 token.
-	GetS21() antlr.Token
-
-	// SetS21 sets the s21 token.
-	SetS21(antlr.Token)
-
-	// GetCols returns the cols token list.
-	GetCols() []antlr.Token
+	// Get_mapEntry returns the _mapEntry rule contexts.
+	Get_mapEntry() IMapEntryContext
 
-	// SetCols sets the cols token list.
-	SetCols([]antlr.Token)
+	// Set_mapEntry sets the _mapEntry rule contexts.
+	Set_mapEntry(IMapEntryContext)
 
-	// Get_optExpr returns the _optExpr rule contexts.
-	Get_optExpr() IOptExprContext
+	// GetEntries returns the entries rule context list.
+	GetEntries() []IMapEntryContext
 
-	// Get_expr returns the _expr rule contexts.
-	Get_expr() IExprContext
-
-	// Set_optExpr sets the _optExpr rule contexts.
-	Set_optExpr(IOptExprContext)
-
-	// Set_expr sets the _expr rule contexts.
-	Set_expr(IExprContext)
-
-	// GetKeys returns the keys rule context list.
-	GetKeys() []IOptExprContext
-
-	// GetValues returns the values rule context list.
-	GetValues() []IExprContext
-
-	// SetKeys sets the keys rule context list.
-	SetKeys([]IOptExprContext)
-
-	// SetValues sets the values rule context list.
-	SetValues([]IExprContext)
+	// SetEntries sets the entries rule context list.
+	SetEntries([]IMapEntryContext)
 
 	// Getter signatures
-	AllOptExpr() []IOptExprContext
-	OptExpr(i int) IOptExprContext
-	AllCOLON() []antlr.TerminalNode
-	COLON(i int) antlr.TerminalNode
-	AllExpr() []IExprContext
-	Expr(i int) IExprContext
+	AllMapEntry() []IMapEntryContext
+	MapEntry(i int) IMapEntryContext
 	AllCOMMA() []antlr.TerminalNode
 	COMMA(i int) antlr.TerminalNode
 
@@ -4407,13 +4815,9 @@ type IMapInitializerListContext interface {
 
 type MapInitializerListContext struct {
 	*antlr.BaseParserRuleContext
-	parser   antlr.Parser
-	_optExpr IOptExprContext
-	keys     []IOptExprContext
-	s21      antlr.Token
-	cols     []antlr.Token
-	_expr    IExprContext
-	values   []IExprContext
+	parser    antlr.Parser
+	_mapEntry IMapEntryContext
+	entries   []IMapEntryContext
 }
 
 func NewEmptyMapInitializerListContext() *MapInitializerListContext {
@@ -4438,93 +4842,28 @@ func NewMapInitializerListContext(parser antlr.Parser, parent antlr.ParserRuleCo
 
 func (s *MapInitializerListContext) GetParser() antlr.Parser { return s.parser }
 
-func (s *MapInitializerListContext) GetS21() antlr.Token { return s.s21 }
-
-func (s *MapInitializerListContext) SetS21(v antlr.Token) { s.s21 = v }
-
-func (s *MapInitializerListContext) GetCols() []antlr.Token { return s.cols }
-
-func (s *MapInitializerListContext) SetCols(v []antlr.Token) { s.cols = v }
-
-func (s *MapInitializerListContext) Get_optExpr() IOptExprContext { return s._optExpr }
-
-func (s *MapInitializerListContext) Get_expr() IExprContext { return s._expr }
-
-func (s *MapInitializerListContext) Set_optExpr(v IOptExprContext) { s._optExpr = v }
-
-func (s *MapInitializerListContext) Set_expr(v IExprContext) { s._expr = v }
-
-func (s *MapInitializerListContext) GetKeys() []IOptExprContext { return s.keys }
-
-func (s *MapInitializerListContext) GetValues() []IExprContext { return s.values }
-
-func (s *MapInitializerListContext) SetKeys(v []IOptExprContext) { s.keys = v }
-
-func (s *MapInitializerListContext) SetValues(v []IExprContext) { s.values = v }
-
-func (s *MapInitializerListContext) AllOptExpr() []IOptExprContext {
-	children := s.GetChildren()
-	len := 0
-	for _, ctx := range children {
-		if _, ok := ctx.(IOptExprContext); ok {
-			len++
-		}
-	}
-
-	tst := make([]IOptExprContext, len)
-	i := 0
-	for _, ctx := range children {
-		if t, ok := ctx.(IOptExprContext); ok {
-			tst[i] = t.(IOptExprContext)
-			i++
-		}
-	}
-
-	return tst
-}
-
-func (s *MapInitializerListContext) OptExpr(i int) IOptExprContext {
-	var t antlr.RuleContext
-	j := 0
-	for _, ctx := range s.GetChildren() {
-		if _, ok := ctx.(IOptExprContext); ok {
-			if j == i {
-				t = ctx.(antlr.RuleContext)
-				break
-			}
-			j++
-		}
-	}
-
-	if t == nil {
-		return nil
-	}
+func (s *MapInitializerListContext) Get_mapEntry() IMapEntryContext { return s._mapEntry }
 
-	return t.(IOptExprContext)
-}
+func (s *MapInitializerListContext) Set_mapEntry(v IMapEntryContext) { s._mapEntry = v }
 
-func (s *MapInitializerListContext) AllCOLON() []antlr.TerminalNode {
-	return s.GetTokens(CELParserCOLON)
-}
+func (s *MapInitializerListContext) GetEntries() []IMapEntryContext { return s.entries }
 
-func (s *MapInitializerListContext) COLON(i int) antlr.TerminalNode {
-	return s.GetToken(CELParserCOLON, i)
-}
+func (s *MapInitializerListContext) SetEntries(v []IMapEntryContext) { s.entries = v }
 
-func (s *MapInitializerListContext) AllExpr() []IExprContext {
+func (s *MapInitializerListContext) AllMapEntry() []IMapEntryContext {
 	children := s.GetChildren()
 	len := 0
 	for _, ctx := range children {
-		if _, ok := ctx.(IExprContext); ok {
+		if _, ok := ctx.(IMapEntryContext); ok {
 			len++
 		}
 	}
 
-	tst := make([]IExprContext, len)
+	tst := make([]IMapEntryContext, len)
 	i := 0
 	for _, ctx := range children {
-		if t, ok := ctx.(IExprContext); ok {
-			tst[i] = t.(IExprContext)
+		if t, ok := ctx.(IMapEntryContext); ok {
+			tst[i] = t.(IMapEntryContext)
 			i++
 		}
 	}
@@ -4532,11 +4871,11 @@ func (s *MapInitializerListContext) AllExpr() []IExprContext {
 	return tst
 }
 
-func (s *MapInitializerListContext) Expr(i int) IExprContext {
+func (s *MapInitializerListContext) MapEntry(i int) IMapEntryContext {
 	var t antlr.RuleContext
 	j := 0
 	for _, ctx := range s.GetChildren() {
-		if _, ok := ctx.(IExprContext); ok {
+		if _, ok := ctx.(IMapEntryContext); ok {
 			if j == i {
 				t = ctx.(antlr.RuleContext)
 				break
@@ -4549,7 +4888,7 @@ func (s *MapInitializerListContext) Expr(i int) IExprContext {
 		return nil
 	}
 
-	return t.(IExprContext)
+	return t.(IMapEntryContext)
 }
 
 func (s *MapInitializerListContext) AllCOMMA() []antlr.TerminalNode {
@@ -4595,7 +4934,7 @@ func (p *CELParser) MapInitializerList() (localctx IMapInitializerListContext) {
 	_ = this
 
 	localctx = NewMapInitializerListContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 26, CELParserRULE_mapInitializerList)
+	p.EnterRule(localctx, 28, CELParserRULE_mapInitializerList)
 
 	defer func() {
 		p.ExitRule()
@@ -4617,68 +4956,280 @@ func (p *CELParser) MapInitializerList() (localctx IMapInitializerListContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(216)
-
-		var _x = p.OptExpr()
-
-		localctx.(*MapInitializerListContext)._optExpr = _x
-	}
-	localctx.(*MapInitializerListContext).keys = append(localctx.(*MapInitializerListContext).keys, localctx.(*MapInitializerListContext)._optExpr)
-	{
-		p.SetState(217)
+		p.SetState(244)
 
-		var _m = p.Match(CELParserCOLON)
+		var _x = p.MapEntry()
 
-		localctx.(*MapInitializerListContext).s21 = _m
+		localctx.(*MapInitializerListContext)._mapEntry = _x
 	}
-	localctx.(*MapInitializerListContext).cols = append(localctx.(*MapInitializerListContext).cols, localctx.(*MapInitializerListContext).s21)
-	{
-		p.SetState(218)
-
-		var _x = p.Expr()
-
-		localctx.(*MapInitializerListContext)._expr = _x
-	}
-	localctx.(*MapInitializerListContext).values = append(localctx.(*MapInitializerListContext).values, localctx.(*MapInitializerListContext)._expr)
-	p.SetState(226)
+	localctx.(*MapInitializerListContext).entries = append(localctx.(*MapInitializerListContext).entries, localctx.(*MapInitializerListContext)._mapEntry)
+	p.SetState(249)
 	p.GetErrorHandler().Sync(p)
-	_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 30, p.GetParserRuleContext())
+	_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 36, p.GetParserRuleContext())
 
 	for _alt != 2 && _alt != antlr.ATNInvalidAltNumber {
 		if _alt == 1 {
 			{
-				p.SetState(219)
+				p.SetState(245)
 				p.Match(CELParserCOMMA)
 			}
 			{
-				p.SetState(220)
+				p.SetState(246)
 
-				var _x = p.OptExpr()
+				var _x = p.MapEntry()
 
-				localctx.(*MapInitializerListContext)._optExpr = _x
+				localctx.(*MapInitializerListContext)._mapEntry = _x
 			}
-			localctx.(*MapInitializerListContext).keys = append(localctx.(*MapInitializerListContext).keys, localctx.(*MapInitializerListContext)._optExpr)
-			{
-				p.SetState(221)
+			localctx.(*MapInitializerListContext).entries = append(localctx.(*MapInitializerListContext).entries, localctx.(*MapInitializerListContext)._mapEntry)
 
-				var _m = p.Match(CELParserCOLON)
+		}
+		p.SetState(251)
+		p.GetErrorHandler().Sync(p)
+		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 36, p.GetParserRuleContext())
+	}
 
-				localctx.(*MapInitializerListContext).s21 = _m
-			}
-			localctx.(*MapInitializerListContext).cols = append(localctx.(*MapInitializerListContext).cols, localctx.(*MapInitializerListContext).s21)
-			{
-				p.SetState(222)
+	return localctx
+}
 
-				var _x = p.Expr()
+// IMapEntryContext is an interface to support dynamic dispatch.
+type IMapEntryContext interface {
+	antlr.ParserRuleContext
+
+	// GetParser returns the parser.
+	GetParser() antlr.Parser
+
+	// GetCol returns the col token.
+	GetCol() antlr.Token
+
+	// GetSpread returns the spread token.
+	GetSpread() antlr.Token
+
+	// SetCol sets the col token.
+	SetCol(antlr.Token)
+
+	// SetSpread sets the spread token.
+	SetSpread(antlr.Token)
+
+	// GetKey returns the key rule contexts.
+	GetKey() IOptExprContext
+
+	// GetValue returns the value rule contexts.
+	GetValue() IExprContext
+
+	// GetE returns the e rule contexts.
+	GetE() IExprContext
+
+	// SetKey sets the key rule contexts.
+	SetKey(IOptExprContext)
+
+	// SetValue sets the value rule contexts.
+	SetValue(IExprContext)
+
+	// SetE sets the e rule contexts.
+	SetE(IExprContext)
+
+	// Getter signatures
+	OptExpr() IOptExprContext
+	COLON() antlr.TerminalNode
+	Expr() IExprContext
+	ELLIPSIS() antlr.TerminalNode
+
+	// IsMapEntryContext differentiates from other interfaces.
+	IsMapEntryContext()
+}
+
+type MapEntryContext struct {
+	*antlr.BaseParserRuleContext
+	parser antlr.Parser
+	key    IOptExprContext
+	col    antlr.Token
+	value  IExprContext
+	spread antlr.Token
+	e      IExprContext
+}
+
+func NewEmptyMapEntryContext() *MapEntryContext {
+	var p = new(MapEntryContext)
+	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(nil, -1)
+	p.RuleIndex = CELParserRULE_mapEntry
+	return p
+}
+
+func (*MapEntryContext) IsMapEntryContext() {}
+
+func NewMapEntryContext(parser antlr.Parser, parent antlr.ParserRuleContext, invokingState int) *MapEntryContext {
+	var p = new(MapEntryContext)
+
+	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(parent, invokingState)
+
+	p.parser = parser
+	p.RuleIndex = CELParserRULE_mapEntry
+
+	return p
+}
+
+func (s *MapEntryContext) GetParser() antlr.Parser { return s.parser }
+
+func (s *MapEntryContext) GetCol() antlr.Token { return s.col }
+
+func (s *MapEntryContext) GetSpread() antlr.Token { return s.spread }
+
+func (s *MapEntryContext) SetCol(v antlr.Token) { s.col = v }
+
+func (s *MapEntryContext) SetSpread(v antlr.Token) { s.spread = v }
+
+func (s *MapEntryContext) GetKey() IOptExprContext { return s.key }
+
+func (s *MapEntryContext) GetValue() IExprContext { return s.value }
+
+func (s *MapEntryContext) GetE() IExprContext { return s.e }
+
+func (s *MapEntryContext) SetKey(v IOptExprContext) { s.key = v }
+
+func (s *MapEntryContext) SetValue(v IExprContext) { s.value = v }
+
+func (s *MapEntryContext) SetE(v IExprContext) { s.e = v }
+
+func (s *MapEntryContext) OptExpr() IOptExprContext {
+	var t antlr.RuleContext
+	for _, ctx := range s.GetChildren() {
+		if _, ok := ctx.(IOptExprContext); ok {
+			t = ctx.(antlr.RuleContext)
+			break
+		}
+	}
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IOptExprContext)
+}
+
+func (s *MapEntryContext) COLON() antlr.TerminalNode {
+	return s.GetToken(CELParserCOLON, 0)
+}
+
+func (s *MapEntryContext) Expr() IExprContext {
+	var t antlr.RuleContext
+	for _, ctx := range s.GetChildren() {
+		if _, ok := ctx.(IExprContext); ok {
+			t = ctx.(antlr.RuleContext)
+			break
+		}
+	}
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IExprContext)
+}
+
+func (s *MapEntryContext) ELLIPSIS() antlr.TerminalNode {
+	return s.GetToken(CELParserELLIPSIS, 0)
+}
+
+func (s *MapEntryContext) GetRuleContext() antlr.RuleContext {
+	return s
+}
+
+func (s *MapEntryContext) ToStringTree(ruleNames []string, recog antlr.Recognizer) string {
+	return antlr.TreesStringTree(s, ruleNames, recog)
+}
+
+func (s *MapEntryContext) EnterRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(CELListener); ok {
+		listenerT.EnterMapEntry(s)
+	}
+}
+
+func (s *MapEntryContext) ExitRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(CELListener); ok {
+		listenerT.ExitMapEntry(s)
+	}
+}
+
+func (s *MapEntryContext) Accept(visitor antlr.ParseTreeVisitor) interface{} {
+	switch t := visitor.(type) {
+	case CELVisitor:
+		return t.VisitMapEntry(s)
+
+	default:
+		return t.VisitChildren(s)
+	}
+}
 
-				localctx.(*MapInitializerListContext)._expr = _x
+func (p *CELParser) MapEntry() (localctx IMapEntryContext) {
+	this := p
+	_ = this
+
+	localctx = NewMapEntryContext(p, p.GetParserRuleContext(), p.GetState())
+	p.EnterRule(localctx, 30, CELParserRULE_mapEntry)
+
+	defer func() {
+		p.ExitRule()
+	}()
+
+	defer func() {
+		if err := recover(); err != nil {
+			if v, ok := err.(antlr.RecognitionException); ok {
+				localctx.SetException(v)
+				p.GetErrorHandler().ReportError(p, v)
+				p.GetErrorHandler().Recover(p, v)
+			} else {
+				panic(err)
 			}
-			localctx.(*MapInitializerListContext).values = append(localctx.(*MapInitializerListContext).values, localctx.(*MapInitializerListContext)._expr)
+		}
+	}()
 
+	p.SetState(258)
+	p.GetErrorHandler().Sync(p)
+
+	switch p.GetTokenStream().LA(1) {
+	case CELParserLBRACKET, CELParserLBRACE, CELParserLPAREN, CELParserDOT, CELParserMINUS, CELParserEXCLAM, CELParserQUESTIONMARK, CELParserCEL_TRUE, CELParserCEL_FALSE, CELParserNUL, CELParserNUM_FLOAT, CELParserNUM_INT, CELParserNUM_UINT, CELParserSTRING, CELParserBYTES, CELParserIDENTIFIER:
+		p.EnterOuterAlt(localctx, 1)
+		{
+			p.SetState(252)
+
+			var _x = p.OptExpr()
+
+			localctx.(*MapEntryContext).key = _x
 		}
-		p.SetState(228)
-		p.GetErrorHandler().Sync(p)
-		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 30, p.GetParserRuleContext())
+		{
+			p.SetState(253)
+
+			var _m = p.Match(CELParserCOLON)
+
+			localctx.(*MapEntryContext).col = _m
+		}
+		{
+			p.SetState(254)
+
+			var _x = p.Expr()
+
+			localctx.(*MapEntryContext).value = _x
+		}
+
+	case CELParserELLIPSIS:
+		p.EnterOuterAlt(localctx, 2)
+		{
+			p.SetState(256)
+
+			var _m = p.Match(CELParserELLIPSIS)
+
+			localctx.(*MapEntryContext).spread = _m
+		}
+		{
+			p.SetState(257)
+
+			var _x = p.Expr()
+
+			localctx.(*MapEntryContext).e = _x
+		}
+
+	default:
+		panic(antlr.NewNoViableAltException(p, nil, nil, nil, nil, nil))
 	}
 
 	return localctx
@@ -4803,7 +5354,7 @@ func (p *CELParser) OptExpr() (localctx IOptExprContext) {
 	_ = this
 
 	localctx = NewOptExprContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 28, CELParserRULE_optExpr)
+	p.EnterRule(localctx, 32, CELParserRULE_optExpr)
 	var _la int
 
 	defer func() {
@@ -4823,13 +5374,13 @@ func (p *CELParser) OptExpr() (localctx IOptExprContext) {
 	}()
 
 	p.EnterOuterAlt(localctx, 1)
-	p.SetState(230)
+	p.SetState(261)
 	p.GetErrorHandler().Sync(p)
 	_la = p.GetTokenStream().LA(1)
 
 	if _la == CELParserQUESTIONMARK {
 		{
-			p.SetState(229)
+			p.SetState(260)
 
 			var _m = p.Match(CELParserQUESTIONMARK)
 
@@ -4838,7 +5389,7 @@ func (p *CELParser) OptExpr() (localctx IOptExprContext) {
 
 	}
 	{
-		p.SetState(232)
+		p.SetState(263)
 
 		var _x = p.Expr()
 
@@ -5312,7 +5863,7 @@ func (p *CELParser) Literal() (localctx ILiteralContext) {
 	_ = this
 
 	localctx = NewLiteralContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 30, CELParserRULE_literal)
+	p.EnterRule(localctx, 34, CELParserRULE_literal)
 	var _la int
 
 	defer func() {
@@ -5331,19 +5882,19 @@ func (p *CELParser) Literal() (localctx ILiteralContext) {
 		}
 	}()
 
-	p.SetState(248)
+	p.SetState(279)
 	p.GetErrorHandler().Sync(p)
-	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 34, p.GetParserRuleContext()) {
+	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 41, p.GetParserRuleContext()) {
 	case 1:
 		localctx = NewIntContext(p, localctx)
 		p.EnterOuterAlt(localctx, 1)
-		p.SetState(235)
+		p.SetState(266)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
 
 		if _la == CELParserMINUS {
 			{
-				p.SetState(234)
+				p.SetState(265)
 
 				var _m = p.Match(CELParserMINUS)
 
@@ -5352,7 +5903,7 @@ func (p *CELParser) Literal() (localctx ILiteralContext) {
 
 		}
 		{
-			p.SetState(237)
+			p.SetState(268)
 
 			var _m = p.Match(CELParserNUM_INT)
 
@@ -5363,7 +5914,7 @@ func (p *CELParser) Literal() (localctx ILiteralContext) {
 		localctx = NewUintContext(p, localctx)
 		p.EnterOuterAlt(localctx, 2)
 		{
-			p.SetState(238)
+			p.SetState(269)
 
 			var _m = p.Match(CELParserNUM_UINT)
 
@@ -5373,13 +5924,13 @@ func (p *CELParser) Literal() (localctx ILiteralContext) {
 	case 3:
 		localctx = NewDoubleContext(p, localctx)
 		p.EnterOuterAlt(localctx, 3)
-		p.SetState(240)
+		p.SetState(271)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
 
 		if _la == CELParserMINUS {
 			{
-				p.SetState(239)
+				p.SetState(270)
 
 				var _m = p.Match(CELParserMINUS)
 
@@ -5388,7 +5939,7 @@ func (p *CELParser) Literal() (localctx ILiteralContext) {
 
 		}
 		{
-			p.SetState(242)
+			p.SetState(273)
 
 			var _m = p.Match(CELParserNUM_FLOAT)
 
@@ -5399,7 +5950,7 @@ func (p *CELParser) Literal() (localctx ILiteralContext) {
 		localctx = NewStringContext(p, localctx)
 		p.EnterOuterAlt(localctx, 4)
 		{
-			p.SetState(243)
+			p.SetState(274)
 
 			var _m = p.Match(CELParserSTRING)
 
@@ -5410,7 +5961,7 @@ func (p *CELParser) Literal() (localctx ILiteralContext) {
 		localctx = NewBytesContext(p, localctx)
 		p.EnterOuterAlt(localctx, 5)
 		{
-			p.SetState(244)
+			p.SetState(275)
 
 			var _m = p.Match(CELParserBYTES)
 
@@ -5421,7 +5972,7 @@ func (p *CELParser) Literal() (localctx ILiteralContext) {
 		localctx = NewBoolTrueContext(p, localctx)
 		p.EnterOuterAlt(localctx, 6)
 		{
-			p.SetState(245)
+			p.SetState(276)
 
 			var _m = p.Match(CELParserCEL_TRUE)
 
@@ -5432,7 +5983,7 @@ func (p *CELParser) Literal() (localctx ILiteralContext) {
 		localctx = NewBoolFalseContext(p, localctx)
 		p.EnterOuterAlt(localctx, 7)
 		{
-			p.SetState(246)
+			p.SetState(277)
 
 			var _m = p.Match(CELParserCEL_FALSE)
 
@@ -5443,7 +5994,7 @@ func (p *CELParser) Literal() (localctx ILiteralContext) {
 		localctx = NewNullContext(p, localctx)
 		p.EnterOuterAlt(localctx, 8)
 		{
-			p.SetState(247)
+			p.SetState(278)
 
 			var _m = p.Match(CELParserNUL)
 
@@ -5518,12 +6069,15 @@ func (p *CELParser) Member_Sempred(localctx antlr.RuleContext, predIndex int) bo
 
 	switch predIndex {
 	case 3:
-		return p.Precpred(p.GetParserRuleContext(), 3)
+		return p.Precpred(p.GetParserRuleContext(), 4)
 
 	case 4:
-		return p.Precpred(p.GetParserRuleContext(), 2)
+		return p.Precpred(p.GetParserRuleContext(), 3)
 
 	case 5:
+		return p.Precpred(p.GetParserRuleContext(), 2)
+
+	case 6:
 		return p.Precpred(p.GetParserRuleContext(), 1)
 
 	default:
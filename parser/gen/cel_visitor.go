@@ -46,6 +46,9 @@ type CELVisitor interface {
 	// Visit a parse tree produced by CELParser#Index.
 	VisitIndex(ctx *IndexContext) interface{}
 
+	// Visit a parse tree produced by CELParser#Slice.
+	VisitSlice(ctx *SliceContext) interface{}
+
 	// Visit a parse tree produced by CELParser#IdentOrGlobalCall.
 	VisitIdentOrGlobalCall(ctx *IdentOrGlobalCallContext) interface{}
 
@@ -70,6 +73,9 @@ type CELVisitor interface {
 	// Visit a parse tree produced by CELParser#listInit.
 	VisitListInit(ctx *ListInitContext) interface{}
 
+	// Visit a parse tree produced by CELParser#listElement.
+	VisitListElement(ctx *ListElementContext) interface{}
+
 	// Visit a parse tree produced by CELParser#fieldInitializerList.
 	VisitFieldInitializerList(ctx *FieldInitializerListContext) interface{}
 
@@ -79,6 +85,9 @@ type CELVisitor interface {
 	// Visit a parse tree produced by CELParser#mapInitializerList.
 	VisitMapInitializerList(ctx *MapInitializerListContext) interface{}
 
+	// Visit a parse tree produced by CELParser#mapEntry.
+	VisitMapEntry(ctx *MapEntryContext) interface{}
+
 	// Visit a parse tree produced by CELParser#optExpr.
 	VisitOptExpr(ctx *OptExprContext) interface{}
 
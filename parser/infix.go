@@ -0,0 +1,175 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	antlr "github.com/antlr/antlr4/runtime/Go/antlr/v4"
+
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/parser/gen"
+)
+
+// InfixPrecedence identifies the precedence of a custom infix operator by the standard operators
+// with which it shares a precedence level.
+type InfixPrecedence int
+
+const (
+	// MultiplicativePrecedence binds as tightly as `*`, `/`, and `%`.
+	MultiplicativePrecedence InfixPrecedence = iota + 1
+
+	// AdditivePrecedence binds as tightly as `+` and `-`.
+	AdditivePrecedence
+
+	// RelationalPrecedence binds as tightly as the relational operators, e.g. `==`, `<`, and `in`.
+	RelationalPrecedence
+)
+
+// InfixOperator describes a custom binary operator symbol, e.g. `=~`, which is parsed as a call
+// to the named function with the left and right operands as its arguments.
+//
+// Custom operators are left-associative and share the precedence of the standard operators at the
+// configured level, so `a + b =~ c` parses as `a + b` matched against `c` when the operator has
+// RelationalPrecedence.
+type InfixOperator struct {
+	// Symbol is the text of the operator, consisting of one or more punctuation characters.
+	Symbol string
+
+	// Function is the name of the function the operator calls.
+	Function string
+
+	// Precedence is the precedence level of the operator.
+	Precedence InfixPrecedence
+}
+
+// standardOperator returns the token type and function name of the standard operator which stands
+// in for the custom operator during parsing.
+func (op InfixOperator) standardOperator() (int, string) {
+	switch op.Precedence {
+	case MultiplicativePrecedence:
+		return gen.CELLexerSTAR, operators.Multiply
+	case AdditivePrecedence:
+		return gen.CELLexerPLUS, operators.Add
+	default:
+		return gen.CELLexerLESS, operators.Less
+	}
+}
+
+// reservedSymbols are the punctuation tokens of the expression language which may not be
+// redefined as custom infix operators.
+var reservedSymbols = map[string]struct{}{
+	"==": {}, "!=": {}, "<": {}, "<=": {}, ">": {}, ">=": {}, "&&": {}, "||": {},
+	"+": {}, "-": {}, "*": {}, "/": {}, "%": {}, "!": {}, "?": {}, ":": {}, ".": {}, ",": {},
+	"?.": {}, "[?": {},
+}
+
+// validate returns an error if the operator cannot be parsed unambiguously.
+func (op InfixOperator) validate() error {
+	if op.Function == "" {
+		return fmt.Errorf("infix operator %q must name a function", op.Symbol)
+	}
+	if op.Precedence < MultiplicativePrecedence || op.Precedence > RelationalPrecedence {
+		return fmt.Errorf("infix operator %q has an invalid precedence: %d", op.Symbol, op.Precedence)
+	}
+	if op.Symbol == "" {
+		return fmt.Errorf("infix operator for function %q must have a symbol", op.Function)
+	}
+	if _, found := reservedSymbols[op.Symbol]; found {
+		return fmt.Errorf("infix operator %q redefines a standard operator", op.Symbol)
+	}
+	for sym := range reservedSymbols {
+		if strings.Contains(sym, op.Symbol) {
+			return fmt.Errorf("infix operator %q is part of the standard operator %q", op.Symbol, sym)
+		}
+	}
+	// A symbol which begins with a standard operator would change the meaning of valid expressions,
+	// e.g. `a <-1` is `a < -1` rather than a use of the operator `<-`.
+	for sym := range reservedSymbols {
+		if strings.HasPrefix(op.Symbol, sym) {
+			return fmt.Errorf("infix operator %q begins with the standard operator %q", op.Symbol, sym)
+		}
+	}
+	if strings.Contains(op.Symbol, "//") {
+		return fmt.Errorf("infix operator %q contains a comment", op.Symbol)
+	}
+	for _, r := range op.Symbol {
+		if !unicode.IsPunct(r) && !unicode.IsSymbol(r) {
+			return fmt.Errorf("infix operator %q must consist of punctuation characters", op.Symbol)
+		}
+		switch r {
+		case '"', '\'', '`', '(', ')', '[', ']', '{', '}':
+			return fmt.Errorf("infix operator %q contains a reserved character: %q", op.Symbol, r)
+		}
+	}
+	return nil
+}
+
+// infixLexer is a token source which lexes custom operators, emitting each as a token of the
+// standard operator with the same precedence whose text is the custom symbol, and defers to the
+// CEL lexer for all other tokens.
+type infixLexer struct {
+	*gen.CELLexer
+	ops []InfixOperator
+}
+
+// NextToken implements the antlr.TokenSource interface method.
+func (l *infixLexer) NextToken() antlr.Token {
+	input := l.GetInputStream()
+	op, found := l.matchOperator(input)
+	if !found {
+		return l.CELLexer.NextToken()
+	}
+	l.TokenStartCharIndex = input.Index()
+	l.TokenStartLine = l.Interpreter.GetLine()
+	l.TokenStartColumn = l.Interpreter.GetCharPositionInLine()
+	for range op.Symbol {
+		l.Interpreter.Consume(input)
+	}
+	tokenType, _ := op.standardOperator()
+	l.SetType(tokenType)
+	l.SetChannel(antlr.TokenDefaultChannel)
+	l.SetText("")
+	return l.Emit()
+}
+
+// matchOperator returns the longest custom operator whose symbol begins at the current position of
+// the input. Operators may not begin with or be contained within a standard token, so an operator
+// which begins at a token boundary is never part of another token.
+func (l *infixLexer) matchOperator(input antlr.CharStream) (InfixOperator, bool) {
+	var match InfixOperator
+	found := false
+	for _, op := range l.ops {
+		if found && len(op.Symbol) <= len(match.Symbol) {
+			continue
+		}
+		i := 1
+		matched := true
+		for _, r := range op.Symbol {
+			if input.LA(i) != int(r) {
+				matched = false
+				break
+			}
+			i++
+		}
+		if matched {
+			match = op
+			found = true
+		}
+	}
+	return match, found
+}
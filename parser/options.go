@@ -25,6 +25,9 @@ type options struct {
 	macros                           map[string]Macro
 	populateMacroCalls               bool
 	enableOptionalSyntax             bool
+	enableSpreadSyntax               bool
+	enableSliceSyntax                bool
+	infixOperators                   []InfixOperator
 }
 
 // Option configures the behavior of the parser.
@@ -125,3 +128,55 @@ func EnableOptionalSyntax(optionalSyntax bool) Option {
 		return nil
 	}
 }
+
+// EnableSpreadSyntax enables the spreading of lists and maps within list and map literals, e.g.
+// `[...a, ...b]` and `{...defaults, 'override': 1}`.
+//
+// List spreads are parsed as list concatenations and map spreads as calls to the
+// `@merge_maps` function, in which the entries of the later map take precedence, so
+// `[...a, b]` parses as `a + [b]` and `{...a, 'k': 1}` as `@merge_maps(a, {'k': 1})`.
+func EnableSpreadSyntax(spreadSyntax bool) Option {
+	return func(opts *options) error {
+		opts.enableSpreadSyntax = spreadSyntax
+		return nil
+	}
+}
+
+// EnableSliceSyntax enables the slicing of values with the index syntax, e.g. `x[a:b]`, `x[a:b:c]`,
+// and `x[::-1]`, where any bound may be omitted.
+//
+// Slices are parsed as calls to the `slice` member function, so `x[a:b]` parses as
+// `x.slice(a, b)`, `x[a:]` as `x.slice(a)`, and `x[:b]` as `x.slice(0, b)`. Slices with a step
+// pass null for their omitted bounds, so `x[a:b:c]` parses as `x.slice(a, b, c)` and `x[::-1]` as
+// `x.slice(null, null, -1)`.
+func EnableSliceSyntax(sliceSyntax bool) Option {
+	return func(opts *options) error {
+		opts.enableSliceSyntax = sliceSyntax
+		return nil
+	}
+}
+
+// InfixOperators adds custom binary operator symbols to the parser, each of which is parsed as a
+// call to the function it names, e.g. `name =~ '^[a-z]+$'` as `matches(name, '^[a-z]+$')`.
+//
+// An operator replaces a previously added operator with the same symbol.
+func InfixOperators(ops ...InfixOperator) Option {
+	return func(opts *options) error {
+		for _, op := range ops {
+			if err := op.validate(); err != nil {
+				return err
+			}
+			replaced := false
+			for i, prev := range opts.infixOperators {
+				if prev.Symbol == op.Symbol {
+					opts.infixOperators[i] = op
+					replaced = true
+				}
+			}
+			if !replaced {
+				opts.infixOperators = append(opts.infixOperators, op)
+			}
+		}
+		return nil
+	}
+}
@@ -99,6 +99,9 @@ func (p *Parser) Parse(source common.Source) (*exprpb.ParsedExpr, *common.Errors
 		errorRecoveryLookaheadTokenLimit: p.errorRecoveryTokenLookaheadLimit,
 		populateMacroCalls:               p.populateMacroCalls,
 		enableOptionalSyntax:             p.enableOptionalSyntax,
+		enableSpreadSyntax:               p.enableSpreadSyntax,
+		enableSliceSyntax:                p.enableSliceSyntax,
+		infixOperators:                   p.infixOperators,
 	}
 	buf, ok := source.(runes.Buffer)
 	if !ok {
@@ -295,6 +298,9 @@ type parser struct {
 	errorRecoveryLookaheadTokenLimit int
 	populateMacroCalls               bool
 	enableOptionalSyntax             bool
+	enableSpreadSyntax               bool
+	enableSliceSyntax                bool
+	infixOperators                   []InfixOperator
 }
 
 var (
@@ -341,7 +347,11 @@ func (p *parser) parse(expr runes.Buffer, desc string) *exprpb.Expr {
 	}()
 
 	lexer.SetInputStream(newCharStream(expr, desc))
-	prsr.SetInputStream(antlr.NewCommonTokenStream(lexer, 0))
+	var tokens antlr.Lexer = lexer
+	if len(p.infixOperators) != 0 {
+		tokens = &infixLexer{CELLexer: lexer, ops: p.infixOperators}
+	}
+	prsr.SetInputStream(antlr.NewCommonTokenStream(tokens, 0))
 
 	lexer.AddErrorListener(p)
 	prsr.AddErrorListener(p)
@@ -421,6 +431,11 @@ func (p *parser) Visit(tree antlr.ParseTree) any {
 		out := p.VisitIndex(tree)
 		p.decrementRecursionDepth()
 		return out
+	case *gen.SliceContext:
+		p.checkAndIncrementRecursionDepth()
+		out := p.VisitSlice(tree)
+		p.decrementRecursionDepth()
+		return out
 	case *gen.UnaryContext:
 		return p.VisitUnary(tree)
 	case *gen.CreateListContext:
@@ -515,7 +530,7 @@ func (p *parser) VisitRelation(ctx *gen.RelationContext) any {
 	if ctx.GetOp() != nil {
 		opText = ctx.GetOp().GetText()
 	}
-	if op, found := operators.Find(opText); found {
+	if op, found := p.findOperator(opText); found {
 		lhs := p.Visit(ctx.Relation(0)).(*exprpb.Expr)
 		opID := p.helper.id(ctx.GetOp())
 		rhs := p.Visit(ctx.Relation(1)).(*exprpb.Expr)
@@ -530,7 +545,7 @@ func (p *parser) VisitCalc(ctx *gen.CalcContext) any {
 	if ctx.GetOp() != nil {
 		opText = ctx.GetOp().GetText()
 	}
-	if op, found := operators.Find(opText); found {
+	if op, found := p.findOperator(opText); found {
 		lhs := p.Visit(ctx.Calc(0)).(*exprpb.Expr)
 		opID := p.helper.id(ctx.GetOp())
 		rhs := p.Visit(ctx.Calc(1)).(*exprpb.Expr)
@@ -539,6 +554,16 @@ func (p *parser) VisitCalc(ctx *gen.CalcContext) any {
 	return p.reportError(ctx, "operator not found")
 }
 
+// findOperator returns the function of the standard or custom binary operator with the given text.
+func (p *parser) findOperator(text string) (string, bool) {
+	for _, op := range p.infixOperators {
+		if op.Symbol == text {
+			return op.Function, true
+		}
+	}
+	return operators.Find(text)
+}
+
 func (p *parser) VisitUnary(ctx *gen.UnaryContext) any {
 	return p.helper.newLiteralString(ctx, "<<error>>")
 }
@@ -614,6 +639,20 @@ func (p *parser) VisitIndex(ctx *gen.IndexContext) any {
 	return p.globalCallOrMacro(opID, operator, target, index)
 }
 
+// Visit a parse tree produced by CELParser#Slice.
+func (p *parser) VisitSlice(ctx *gen.SliceContext) any {
+	target := p.Visit(ctx.Member()).(*exprpb.Expr)
+	// Handle the error case where no valid identifier is specified.
+	if ctx.GetOp() == nil {
+		return p.helper.newExpr(ctx)
+	}
+	if !p.enableSliceSyntax {
+		return p.reportError(ctx.GetOp(), "unsupported syntax '[:'")
+	}
+	opID := p.helper.id(ctx.GetOp())
+	return p.receiverCallOrMacro(opID, sliceFunction, target, p.sliceArgs(ctx)...)
+}
+
 // Visit a parse tree produced by CELParser#CreateMessage.
 func (p *parser) VisitCreateMessage(ctx *gen.CreateMessageContext) any {
 	messageName := ""
@@ -692,7 +731,10 @@ func (p *parser) VisitIdentOrGlobalCall(ctx *gen.IdentOrGlobalCallContext) any {
 // Visit a parse tree produced by CELParser#CreateList.
 func (p *parser) VisitCreateList(ctx *gen.CreateListContext) any {
 	listID := p.helper.id(ctx.GetOp())
-	elems, optionals := p.visitListInit(ctx.GetElems())
+	elems, optionals, spreads := p.visitListInit(ctx.GetElems())
+	if len(spreads) != 0 {
+		return p.spreadList(ctx.GetOp(), elems, optionals, spreads)
+	}
 	return p.helper.newList(listID, elems, optionals...)
 }
 
@@ -703,33 +745,55 @@ func (p *parser) VisitCreateStruct(ctx *gen.CreateStructContext) any {
 	if ctx.GetEntries() != nil {
 		entries = p.Visit(ctx.GetEntries()).([]*exprpb.Expr_CreateStruct_Entry)
 	}
+	if p.enableSpreadSyntax && len(entries) != 0 {
+		spreads := map[int]bool{}
+		for i, entry := range ctx.GetEntries().GetEntries() {
+			if entry.GetSpread() != nil {
+				spreads[i] = true
+			}
+		}
+		if len(spreads) != 0 {
+			return p.spreadMap(ctx.GetOp(), entries, spreads)
+		}
+	}
 	return p.helper.newMap(structID, entries...)
 }
 
 // Visit a parse tree produced by CELParser#mapInitializerList.
 func (p *parser) VisitMapInitializerList(ctx *gen.MapInitializerListContext) any {
-	if ctx == nil || ctx.GetKeys() == nil {
+	if ctx == nil || ctx.GetEntries() == nil {
 		// This is the result of a syntax error handled elswhere, return empty.
 		return []*exprpb.Expr_CreateStruct_Entry{}
 	}
 
-	result := make([]*exprpb.Expr_CreateStruct_Entry, len(ctx.GetCols()))
-	keys := ctx.GetKeys()
-	vals := ctx.GetValues()
-	for i, col := range ctx.GetCols() {
-		colID := p.helper.id(col)
-		if i >= len(keys) || i >= len(vals) {
+	result := make([]*exprpb.Expr_CreateStruct_Entry, len(ctx.GetEntries()))
+	for i, e := range ctx.GetEntries() {
+		if e.GetSpread() != nil {
+			if e.GetE() == nil {
+				// This is the result of a syntax error detected elsewhere.
+				return []*exprpb.Expr_CreateStruct_Entry{}
+			}
+			if !p.enableSpreadSyntax {
+				p.reportError(e.GetSpread(), "unsupported syntax '...'")
+				continue
+			}
+			// The entry holds the spread map, which is merged by spreadMap.
+			result[i] = &exprpb.Expr_CreateStruct_Entry{Value: p.Visit(e.GetE()).(*exprpb.Expr)}
+			continue
+		}
+		if e.GetCol() == nil || e.GetKey() == nil || e.GetValue() == nil {
 			// This is the result of a syntax error detected elsewhere.
 			return []*exprpb.Expr_CreateStruct_Entry{}
 		}
-		optKey := keys[i]
+		colID := p.helper.id(e.GetCol())
+		optKey := e.GetKey()
 		optional := optKey.GetOpt() != nil
 		if !p.enableOptionalSyntax && optional {
 			p.reportError(optKey, "unsupported syntax '?'")
 			continue
 		}
 		key := p.Visit(optKey.GetE()).(*exprpb.Expr)
-		value := p.Visit(vals[i]).(*exprpb.Expr)
+		value := p.Visit(e.GetValue()).(*exprpb.Expr)
 		entry := p.helper.newMapEntry(colID, key, value, optional)
 		result[i] = entry
 	}
@@ -822,17 +886,22 @@ func (p *parser) visitExprList(ctx gen.IExprListContext) []*exprpb.Expr {
 	return p.visitSlice(ctx.GetE())
 }
 
-func (p *parser) visitListInit(ctx gen.IListInitContext) ([]*exprpb.Expr, []int32) {
+func (p *parser) visitListInit(ctx gen.IListInitContext) ([]*exprpb.Expr, []int32, map[int]bool) {
 	if ctx == nil {
-		return []*exprpb.Expr{}, []int32{}
+		return []*exprpb.Expr{}, []int32{}, nil
 	}
 	elements := ctx.GetElems()
 	result := make([]*exprpb.Expr, len(elements))
 	optionals := []int32{}
+	spreads := map[int]bool{}
 	for i, e := range elements {
+		if e.GetE() == nil {
+			// This is the result of a syntax error detected elsewhere.
+			return []*exprpb.Expr{}, []int32{}, nil
+		}
 		ex := p.Visit(e.GetE()).(*exprpb.Expr)
 		if ex == nil {
-			return []*exprpb.Expr{}, []int32{}
+			return []*exprpb.Expr{}, []int32{}, nil
 		}
 		result[i] = ex
 		if e.GetOpt() != nil {
@@ -842,8 +911,15 @@ func (p *parser) visitListInit(ctx gen.IListInitContext) ([]*exprpb.Expr, []int3
 			}
 			optionals = append(optionals, int32(i))
 		}
+		if e.GetSpread() != nil {
+			if !p.enableSpreadSyntax {
+				p.reportError(e.GetSpread(), "unsupported syntax '...'")
+				continue
+			}
+			spreads[i] = true
+		}
 	}
-	return result, optionals
+	return result, optionals, spreads
 }
 
 func (p *parser) visitSlice(expressions []gen.IExprContext) []*exprpb.Expr {
@@ -739,7 +739,7 @@ var testCases = []testInfo{
 	},
 	{
 		I: `{`,
-		E: `ERROR: <input>:1:2: Syntax error: mismatched input '<EOF>' expecting {'[', '{', '}', '(', '.', ',', '-', '!', '?', 'true', 'false', 'null', NUM_FLOAT, NUM_INT, NUM_UINT, STRING, BYTES, IDENTIFIER}
+		E: `ERROR: <input>:1:2: Syntax error: mismatched input '<EOF>' expecting {'[', '{', '}', '(', '.', ',', '-', '!', '?', 'true', 'false', 'null', NUM_FLOAT, NUM_INT, NUM_UINT, STRING, BYTES, IDENTIFIER, '...'}
 		 | {
 		 | .^`,
 	},
@@ -1149,7 +1149,7 @@ var testCases = []testInfo{
 	},
 	{
 		I: "{:a}",
-		E: `ERROR: <input>:1:2: Syntax error: extraneous input ':' expecting {'[', '{', '}', '(', '.', ',', '-', '!', '?', 'true', 'false', 'null', NUM_FLOAT, NUM_INT, NUM_UINT, STRING, BYTES, IDENTIFIER}
+		E: `ERROR: <input>:1:2: Syntax error: extraneous input ':' expecting {'[', '{', '}', '(', '.', ',', '-', '!', '?', 'true', 'false', 'null', NUM_FLOAT, NUM_INT, NUM_UINT, STRING, BYTES, IDENTIFIER, '...'}
 		| {:a}
 		| .^
 	    ERROR: <input>:1:4: Syntax error: mismatched input '}' expecting ':'
@@ -1158,7 +1158,8 @@ var testCases = []testInfo{
 	},
 	{
 		I: "ind[a{b}]",
-		E: `ERROR: <input>:1:8: Syntax error: mismatched input '}' expecting ':'
+		E: `ERROR: <input>:-1:0: error recovery token lookahead limit exceeded: 4
+		ERROR: <input>:1:8: Syntax error: no viable alternative at input '[a{b}'
 		| ind[a{b}]
 		| .......^`,
 	},
@@ -1950,6 +1951,252 @@ func TestParserOptionErrors(t *testing.T) {
 	}
 }
 
+func TestInfixOperators(t *testing.T) {
+	p, err := NewParser(
+		Macros(AllMacros...),
+		InfixOperators(
+			InfixOperator{Symbol: "=~", Function: "matches", Precedence: RelationalPrecedence},
+			InfixOperator{Symbol: "|+|", Function: "concat", Precedence: AdditivePrecedence},
+			InfixOperator{Symbol: "^", Function: "pow", Precedence: MultiplicativePrecedence},
+		),
+	)
+	if err != nil {
+		t.Fatalf("NewParser() failed: %v", err)
+	}
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{in: `name =~ '^[a-z]+$'`, out: `matches(name, "^[a-z]+$")`},
+		{in: `a |+| b =~ c`, out: `matches(concat(a, b), c)`},
+		{in: `a + b ^ c`, out: `_+_(a, pow(b, c))`},
+		{in: `a |+| b + c`, out: `_+_(concat(a, b), c)`},
+		{in: `a == b =~ c`, out: `matches(_==_(a, b), c)`},
+		{in: `a =~ b && c`, out: `_&&_(matches(a, b), c)`},
+		{in: `'a =~ b' =~ r"c =~ d" // e =~ f`, out: `matches("a =~ b", "c =~ d")`},
+		{in: `a<=b`, out: `_<=_(a, b)`},
+		{in: `r+"\"" =~ x`, out: `matches(_+_(r, "\""), x)`},
+		{in: `br'=~' =~ R"=~\" =~ x`, out: `matches(matches(b"=~", "=~\\"), x)`},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.in, func(t *testing.T) {
+			parsed, iss := p.Parse(common.NewTextSource(tc.in))
+			if len(iss.GetErrors()) > 0 {
+				t.Fatalf("Parse(%q) failed: %v", tc.in, iss.ToDisplayString())
+			}
+			out := strings.Join(strings.Fields(debug.ToDebugString(parsed.GetExpr())), " ")
+			out = strings.NewReplacer("( ", "(", " )", ")").Replace(out)
+			if out != tc.out {
+				t.Errorf("Parse(%q) got %s, wanted %s", tc.in, out, tc.out)
+			}
+		})
+	}
+	src := common.NewTextSource(`a =~ `)
+	_, iss := p.Parse(src)
+	if len(iss.GetErrors()) == 0 || !strings.Contains(iss.ToDisplayString(), "a =~") {
+		t.Errorf("Parse(%q) got errors %v, wanted an error referencing the original source", src.Content(), iss.ToDisplayString())
+	}
+	// Syntax errors refer to custom operators by their symbols.
+	src = common.NewTextSource("a\n  =~ =~ b")
+	_, iss = p.Parse(src)
+	wantErr := "ERROR: <input>:2:6: Syntax error: extraneous input '=~' expecting {'[', '{', '(', '.', '-', '!', 'true', 'false', 'null', NUM_FLOAT, NUM_INT, NUM_UINT, STRING, BYTES, IDENTIFIER}\n" +
+		" |   =~ =~ b\n" +
+		" | .....^"
+	if iss.ToDisplayString() != wantErr {
+		t.Errorf("Parse(%q) got errors %q, wanted %q", src.Content(), iss.ToDisplayString(), wantErr)
+	}
+
+	// Operators which share characters with the standard operators do not disturb them.
+	p, err = NewParser(
+		InfixOperators(
+			InfixOperator{Symbol: "&>", Function: "then", Precedence: RelationalPrecedence},
+			InfixOperator{Symbol: "|>", Function: "pipe", Precedence: RelationalPrecedence},
+			InfixOperator{Symbol: "=>", Function: "implies", Precedence: RelationalPrecedence},
+			InfixOperator{Symbol: "~!", Function: "unmatched", Precedence: RelationalPrecedence},
+		),
+	)
+	if err != nil {
+		t.Fatalf("NewParser() failed: %v", err)
+	}
+	for in, want := range map[string]string{
+		`a && b`:  `_&&_(a, b)`,
+		`a || b`:  `_||_(a, b)`,
+		`a == b`:  `_==_(a, b)`,
+		`a != b`:  `_!=_(a, b)`,
+		`a >= b`:  `_>=_(a, b)`,
+		`a&&b|>c`: `_&&_(a, pipe(b, c))`,
+		`a==b=>c`: `implies(_==_(a, b), c)`,
+		`a &> b`:  `then(a, b)`,
+		`a ~! b`:  `unmatched(a, b)`,
+	} {
+		parsed, iss := p.Parse(common.NewTextSource(in))
+		if len(iss.GetErrors()) > 0 {
+			t.Fatalf("Parse(%q) failed: %v", in, iss.ToDisplayString())
+		}
+		out := strings.Join(strings.Fields(debug.ToDebugString(parsed.GetExpr())), " ")
+		out = strings.NewReplacer("( ", "(", " )", ")").Replace(out)
+		if out != want {
+			t.Errorf("Parse(%q) got %s, wanted %s", in, out, want)
+		}
+	}
+}
+
+func TestInfixOperatorsErrors(t *testing.T) {
+	tests := []InfixOperator{
+		{Symbol: "==", Function: "eq", Precedence: RelationalPrecedence},
+		{Symbol: "=~", Precedence: RelationalPrecedence},
+		{Symbol: "=~", Function: "matches"},
+		{Symbol: "~a", Function: "matches", Precedence: RelationalPrecedence},
+		{Symbol: "~(", Function: "matches", Precedence: RelationalPrecedence},
+		{Symbol: "~//", Function: "matches", Precedence: RelationalPrecedence},
+		{Symbol: "&", Function: "and", Precedence: RelationalPrecedence},
+		{Symbol: "|", Function: "or", Precedence: RelationalPrecedence},
+		{Symbol: "=", Function: "eq", Precedence: RelationalPrecedence},
+		{Symbol: "==~", Function: "matches", Precedence: RelationalPrecedence},
+		{Symbol: "<-", Function: "arrow", Precedence: RelationalPrecedence},
+		{Symbol: "-~", Function: "matches", Precedence: RelationalPrecedence},
+		{Symbol: "**", Function: "pow", Precedence: MultiplicativePrecedence},
+		{Symbol: ".~", Function: "matches", Precedence: RelationalPrecedence},
+	}
+	for _, op := range tests {
+		if _, err := NewParser(InfixOperators(op)); err == nil {
+			t.Errorf("NewParser(InfixOperators(%v)) succeeded, wanted error", op)
+		}
+	}
+}
+
+func TestSpreadSyntax(t *testing.T) {
+	p, err := NewParser(Macros(AllMacros...), EnableSpreadSyntax(true))
+	if err != nil {
+		t.Fatalf("NewParser() failed: %v", err)
+	}
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{in: `[...a]`, out: `_+_([], a)`},
+		{in: `[...a, ...b]`, out: `_+_(a, b)`},
+		{in: `[1, ... a, 2, 3]`, out: `_+_(_+_([1], a), [2, 3])`},
+		{in: `[...a[0], b.c]`, out: `_+_(_[_](a, 0), [b.c])`},
+		{in: `{...a}`, out: `@merge_maps({}, a)`},
+		{in: `{...defaults, 'override': 1}`, out: `@merge_maps(defaults, {"override":1})`},
+		{in: `{'k': [...a], ...b}`, out: `@merge_maps({"k":_+_([], a)}, b)`},
+		{in: `Msg{f: [...a]}`, out: `Msg{f:_+_([], a)}`},
+		{in: `['...', "a...b"]`, out: `["...", "a...b"]`},
+		{in: `1 in [...a, 2]`, out: `@in(1, _+_(a, [2]))`},
+		{in: `'x' in{...m}`, out: `@in("x", @merge_maps({}, m))`},
+		{in: `[...in_range[0]]`, out: `_+_([], _[_](in_range, 0))`},
+		{in: `[r+"\"...", ...a]`, out: `_+_([_+_(r, "\"...")], a)`},
+		{in: `[...x ? a : b]`, out: `_+_([], _?_:_(x, a, b))`},
+		{in: `{...m[0], ...{'k': [...a]}}`, out: `@merge_maps(_[_](m, 0), {"k":_+_([], a)})`},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.in, func(t *testing.T) {
+			parsed, iss := p.Parse(common.NewTextSource(tc.in))
+			if len(iss.GetErrors()) > 0 {
+				t.Fatalf("Parse(%q) failed: %v", tc.in, iss.ToDisplayString())
+			}
+			out := strings.Join(strings.Fields(debug.ToDebugString(parsed.GetExpr())), " ")
+			out = strings.NewReplacer("( ", "(", " )", ")", "[ ", "[", " ]", "]", "{ ", "{", " }", "}", ": ", ":").Replace(out)
+			if out != tc.out {
+				t.Errorf("Parse(%q) got %s, wanted %s", tc.in, out, tc.out)
+			}
+		})
+	}
+	disabled, err := NewParser(Macros(AllMacros...))
+	if err != nil {
+		t.Fatalf("NewParser() failed: %v", err)
+	}
+	for _, in := range []string{`[...a]`, `{...a}`} {
+		_, iss := disabled.Parse(common.NewTextSource(in))
+		if !strings.Contains(iss.ToDisplayString(), "unsupported syntax '...'") {
+			t.Errorf("Parse(%s) got %q without spread syntax, wanted unsupported syntax error", in, iss.ToDisplayString())
+		}
+	}
+	for _, in := range []string{`[a...]`, `[1, ...]`, `[...]`, `[..., 1]`, `{...}`, `{'k': 1, ...}`} {
+		if _, iss := p.Parse(common.NewTextSource(in)); len(iss.GetErrors()) == 0 {
+			t.Errorf("Parse(%s) succeeded, wanted error", in)
+		}
+	}
+}
+
+func TestSliceSyntax(t *testing.T) {
+	p, err := NewParser(Macros(AllMacros...), EnableSliceSyntax(true), EnableOptionalSyntax(true))
+	if err != nil {
+		t.Fatalf("NewParser() failed: %v", err)
+	}
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{in: `a[1:2]`, out: `a.slice(1, 2)`},
+		{in: `a[1:-1:2]`, out: `a.slice(1, -1, 2)`},
+		{in: `a[i + 1:]`, out: `a.slice(_+_(i, 1))`},
+		{in: `a[ : n]`, out: `a.slice(0, n)`},
+		{in: `'tacocat'[-3:]`, out: `"tacocat".slice(-3)`},
+		{in: `a.b[0][1:2]`, out: `_[_](a.b, 0).slice(1, 2)`},
+		{in: `a[b[0:1][0]:size(c)]`, out: `a.slice(_[_](b.slice(0, 1), 0), size(c))`},
+		{in: `a[(x ? 1 : 2):3]`, out: `a.slice(_?_:_(x, 1, 2), 3)`},
+		{in: `a[x ? 1 : 2]`, out: `_[_](a, _?_:_(x, 1, 2))`},
+		{in: `a[{'k': 1}['k']:]`, out: `a.slice(_[_]({"k":1}, "k"))`},
+		{in: `a[?b]`, out: `_[?_](a, b)`},
+		{in: `a[m.?k.orValue(0):'x:y'.size()]`, out: `a.slice(_?._(m, "k").orValue(0), "x:y".size())`},
+		{in: `{'k': a[1:]}`, out: `{"k":a.slice(1)}`},
+		{in: `a[x < 1]`, out: `_[_](a, _<_(x, 1))`},
+		{in: `a[(x < 1):2]`, out: `a.slice(_<_(x, 1), 2)`},
+		{in: `a[:]`, out: `a.slice(0)`},
+		{in: `x[::-1]`, out: `x.slice(null, null, -1)`},
+		{in: `a[1::2]`, out: `a.slice(1, null, 2)`},
+		{in: `a[:n:2]`, out: `a.slice(null, n, 2)`},
+		{in: `x[a ? 1 : 2:]`, out: `x.slice(_?_:_(a, 1, 2))`},
+		{in: `a[x ? 1 : 2:3]`, out: `a.slice(_?_:_(x, 1, 2), 3)`},
+		{in: `x[y[1:]:]`, out: `x.slice(y.slice(1))`},
+		{in: `a[x || y:2]`, out: `a.slice(_||_(x, y), 2)`},
+		{in: `a[1:x < y]`, out: `a.slice(1, _<_(x, y))`},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.in, func(t *testing.T) {
+			parsed, iss := p.Parse(common.NewTextSource(tc.in))
+			if len(iss.GetErrors()) > 0 {
+				t.Fatalf("Parse(%q) failed: %v", tc.in, iss.ToDisplayString())
+			}
+			out := strings.Join(strings.Fields(debug.ToDebugString(parsed.GetExpr())), " ")
+			out = strings.NewReplacer("( ", "(", " )", ")", "[ ", "[", " ]", "]", "{ ", "{", " }", "}", ": ", ":").Replace(out)
+			if out != tc.out {
+				t.Errorf("Parse(%q) got %s, wanted %s", tc.in, out, tc.out)
+			}
+		})
+	}
+	disabled, err := NewParser(Macros(AllMacros...))
+	if err != nil {
+		t.Fatalf("NewParser() failed: %v", err)
+	}
+	if _, iss := disabled.Parse(common.NewTextSource(`a[1:2]`)); !strings.Contains(iss.ToDisplayString(), "unsupported syntax '[:'") {
+		t.Errorf("Parse(a[1:2]) got %q without slice syntax, wanted unsupported syntax error", iss.ToDisplayString())
+	}
+	errorTests := []struct {
+		in  string
+		err string
+	}{
+		{in: `a[1:2:3:4]`, err: "mismatched input ':'"},
+		{in: `[1:2]`, err: "mismatched input ':'"},
+		{in: `a[?1:2]`, err: "mismatched input ':'"},
+	}
+	for _, tc := range errorTests {
+		_, iss := p.Parse(common.NewTextSource(tc.in))
+		if len(iss.GetErrors()) == 0 {
+			t.Errorf("Parse(%s) succeeded, wanted error", tc.in)
+			continue
+		}
+		if !strings.Contains(iss.ToDisplayString(), tc.err) {
+			t.Errorf("Parse(%s) got error %s, wanted %s", tc.in, iss.ToDisplayString(), tc.err)
+		}
+	}
+}
+
 func BenchmarkParse(b *testing.B) {
 	p, err := NewParser(
 		Macros(AllMacros...),
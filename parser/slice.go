@@ -0,0 +1,62 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"github.com/google/cel-go/parser/gen"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+)
+
+// sliceFunction is the name of the member function to which slices are desugared.
+const sliceFunction = "slice"
+
+// sliceArgs returns the arguments of the `slice` call to which a slice desugars:
+//
+//   - `x[a:b]` desugars to `x.slice(a, b)`, where an omitted start is 0, and `x[a:]` to
+//     `x.slice(a)`.
+//   - `x[a:b:c]` desugars to `x.slice(a, b, c)`, where omitted bounds are null so that their
+//     defaults depend upon the sign of the step, e.g. `x[::-1]` desugars to
+//     `x.slice(null, null, -1)`.
+func (p *parser) sliceArgs(ctx *gen.SliceContext) []*exprpb.Expr {
+	op := ctx.GetOp()
+	if ctx.GetStep() != nil {
+		return []*exprpb.Expr{
+			p.sliceBound(op, ctx.GetLower()),
+			p.sliceBound(op, ctx.GetUpper()),
+			p.Visit(ctx.GetStep()).(*exprpb.Expr),
+		}
+	}
+	lower := p.helper.newLiteralInt(op, 0)
+	if ctx.GetLower() != nil {
+		lower = p.Visit(ctx.GetLower()).(*exprpb.Expr)
+	}
+	if ctx.GetUpper() == nil {
+		return []*exprpb.Expr{lower}
+	}
+	return []*exprpb.Expr{lower, p.Visit(ctx.GetUpper()).(*exprpb.Expr)}
+}
+
+// sliceBound returns the bound of a slice with a step, which is null when it is omitted.
+func (p *parser) sliceBound(op any, bound gen.IExprContext) *exprpb.Expr {
+	if bound == nil {
+		return p.helper.newLiteral(op,
+			&exprpb.Constant{
+				ConstantKind: &exprpb.Constant_NullValue{
+					NullValue: structpb.NullValue_NULL_VALUE}})
+	}
+	return p.Visit(bound).(*exprpb.Expr)
+}
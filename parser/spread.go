@@ -0,0 +1,92 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"github.com/google/cel-go/common/operators"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// spreadList desugars a list literal containing spread elements into the concatenation of the
+// spread lists and the list literals formed by the elements between them, e.g. `[...a, b]` into
+// `a + [b]`.
+func (p *parser) spreadList(ctx any, elems []*exprpb.Expr, optionals []int32, spreads map[int]bool) *exprpb.Expr {
+	isOptional := map[int]bool{}
+	for _, i := range optionals {
+		isOptional[int(i)] = true
+	}
+	var segments []*exprpb.Expr
+	var literal []*exprpb.Expr
+	var literalOptionals []int32
+	flush := func() {
+		if len(literal) != 0 {
+			segments = append(segments, p.helper.newList(ctx, literal, literalOptionals...))
+		}
+		literal, literalOptionals = nil, nil
+	}
+	for i, e := range elems {
+		if spreads[i] {
+			flush()
+			segments = append(segments, e)
+			continue
+		}
+		if isOptional[i] {
+			literalOptionals = append(literalOptionals, int32(len(literal)))
+		}
+		literal = append(literal, e)
+	}
+	flush()
+	if len(segments) == 1 {
+		// The result is a new list even when the literal consists of a single spread.
+		segments = append([]*exprpb.Expr{p.helper.newList(ctx, []*exprpb.Expr{})}, segments...)
+	}
+	result := segments[0]
+	for _, seg := range segments[1:] {
+		result = p.helper.newGlobalCall(ctx, operators.Add, result, seg)
+	}
+	return result
+}
+
+// spreadMap desugars a map literal containing spread entries into the merge of the spread maps and
+// the map literals formed by the entries between them, e.g. `{...a, 'k': 1}` into
+// `@merge_maps(a, {'k': 1})`, where the entries of later maps take precedence.
+func (p *parser) spreadMap(ctx any, entries []*exprpb.Expr_CreateStruct_Entry, spreads map[int]bool) *exprpb.Expr {
+	var segments []*exprpb.Expr
+	var literal []*exprpb.Expr_CreateStruct_Entry
+	flush := func() {
+		if len(literal) != 0 {
+			segments = append(segments, p.helper.newMap(ctx, literal...))
+		}
+		literal = nil
+	}
+	for i, entry := range entries {
+		if spreads[i] {
+			flush()
+			segments = append(segments, entry.GetValue())
+			continue
+		}
+		literal = append(literal, entry)
+	}
+	flush()
+	if len(segments) == 1 {
+		segments = append([]*exprpb.Expr{p.helper.newMap(ctx)}, segments...)
+	}
+	result := segments[0]
+	for _, seg := range segments[1:] {
+		result = p.helper.newGlobalCall(ctx, operators.MergeMaps, result, seg)
+	}
+	return result
+}
@@ -137,6 +137,9 @@ func (un *unparser) visitCall(expr *exprpb.Expr) error {
 		return un.visitCallBinary(expr)
 	// standard function calls.
 	default:
+		if _, found := un.options.infixOperators[fun]; found && c.GetTarget() == nil && len(c.GetArgs()) == 2 {
+			return un.visitCallBinary(expr)
+		}
 		return un.visitCallFunc(expr)
 	}
 }
@@ -147,19 +150,19 @@ func (un *unparser) visitCallBinary(expr *exprpb.Expr) error {
 	args := c.GetArgs()
 	lhs := args[0]
 	// add parens if the current operator is lower precedence than the lhs expr operator.
-	lhsParen := isComplexOperatorWithRespectTo(fun, lhs)
+	lhsParen := un.isComplexOperatorWithRespectTo(fun, lhs)
 	rhs := args[1]
 	// add parens if the current operator is lower precedence than the rhs expr operator,
 	// or the same precedence and the operator is left recursive.
-	rhsParen := isComplexOperatorWithRespectTo(fun, rhs)
+	rhsParen := un.isComplexOperatorWithRespectTo(fun, rhs)
 	if !rhsParen && isLeftRecursive(fun) {
-		rhsParen = isSamePrecedence(fun, rhs)
+		rhsParen = un.isSamePrecedence(fun, rhs)
 	}
 	err := un.visitMaybeNested(lhs, lhsParen)
 	if err != nil {
 		return err
 	}
-	unmangled, found := operators.FindReverseBinaryOperator(fun)
+	unmangled, found := un.findReverseBinaryOperator(fun)
 	if !found {
 		return fmt.Errorf("cannot unmangle operator: %s", fun)
 	}
@@ -172,7 +175,7 @@ func (un *unparser) visitCallConditional(expr *exprpb.Expr) error {
 	c := expr.GetCallExpr()
 	args := c.GetArgs()
 	// add parens if operand is a conditional itself.
-	nested := isSamePrecedence(operators.Conditional, args[0]) ||
+	nested := un.isSamePrecedence(operators.Conditional, args[0]) ||
 		isComplexOperator(args[0])
 	err := un.visitMaybeNested(args[0], nested)
 	if err != nil {
@@ -181,7 +184,7 @@ func (un *unparser) visitCallConditional(expr *exprpb.Expr) error {
 	un.writeOperatorWithWrapping(operators.Conditional, "?")
 
 	// add parens if operand is a conditional itself.
-	nested = isSamePrecedence(operators.Conditional, args[1]) ||
+	nested = un.isSamePrecedence(operators.Conditional, args[1]) ||
 		isComplexOperator(args[1])
 	err = un.visitMaybeNested(args[1], nested)
 	if err != nil {
@@ -190,7 +193,7 @@ func (un *unparser) visitCallConditional(expr *exprpb.Expr) error {
 
 	un.str.WriteString(" : ")
 	// add parens if operand is a conditional itself.
-	nested = isSamePrecedence(operators.Conditional, args[2]) ||
+	nested = un.isSamePrecedence(operators.Conditional, args[2]) ||
 		isComplexOperator(args[2])
 
 	return un.visitMaybeNested(args[2], nested)
@@ -201,7 +204,7 @@ func (un *unparser) visitCallFunc(expr *exprpb.Expr) error {
 	fun := c.GetFunction()
 	args := c.GetArgs()
 	if c.GetTarget() != nil {
-		nested := isBinaryOrTernaryOperator(c.GetTarget())
+		nested := un.isBinaryOrTernaryOperator(c.GetTarget())
 		err := un.visitMaybeNested(c.GetTarget(), nested)
 		if err != nil {
 			return err
@@ -234,7 +237,7 @@ func (un *unparser) visitCallOptIndex(expr *exprpb.Expr) error {
 func (un *unparser) visitCallIndexInternal(expr *exprpb.Expr, op string) error {
 	c := expr.GetCallExpr()
 	args := c.GetArgs()
-	nested := isBinaryOrTernaryOperator(args[0])
+	nested := un.isBinaryOrTernaryOperator(args[0])
 	err := un.visitMaybeNested(args[0], nested)
 	if err != nil {
 		return err
@@ -342,7 +345,7 @@ func (un *unparser) visitSelectInternal(operand *exprpb.Expr, testOnly bool, op
 	if testOnly {
 		un.str.WriteString("has(")
 	}
-	nested := !testOnly && isBinaryOrTernaryOperator(operand)
+	nested := !testOnly && un.isBinaryOrTernaryOperator(operand)
 	err := un.visitMaybeNested(operand, nested)
 	if err != nil {
 		return err
@@ -440,6 +443,25 @@ func (un *unparser) visitMaybeNested(expr *exprpb.Expr, nested bool) error {
 	return nil
 }
 
+// precedence returns the precedence of the operator, where custom infix operators share the
+// precedence of the standard operators which stand in for them during parsing.
+func (un *unparser) precedence(op string) int {
+	if infix, found := un.options.infixOperators[op]; found {
+		_, std := infix.standardOperator()
+		return operators.Precedence(std)
+	}
+	return operators.Precedence(op)
+}
+
+// findReverseBinaryOperator returns the text representation of a standard or custom binary
+// operator.
+func (un *unparser) findReverseBinaryOperator(op string) (string, bool) {
+	if infix, found := un.options.infixOperators[op]; found {
+		return infix.Symbol, true
+	}
+	return operators.FindReverseBinaryOperator(op)
+}
+
 // isLeftRecursive indicates whether the parser resolves the call in a left-recursive manner as
 // this can have an effect of how parentheses affect the order of operations in the AST.
 func isLeftRecursive(op string) bool {
@@ -450,23 +472,23 @@ func isLeftRecursive(op string) bool {
 // precedence of the (possible) operation represented in the input Expr.
 //
 // If the expr is not a Call, the result is false.
-func isSamePrecedence(op string, expr *exprpb.Expr) bool {
+func (un *unparser) isSamePrecedence(op string, expr *exprpb.Expr) bool {
 	if expr.GetCallExpr() == nil {
 		return false
 	}
 	c := expr.GetCallExpr()
 	other := c.GetFunction()
-	return operators.Precedence(op) == operators.Precedence(other)
+	return un.precedence(op) == un.precedence(other)
 }
 
 // isLowerPrecedence indicates whether the precedence of the input operator is lower precedence
 // than the (possible) operation represented in the input Expr.
 //
 // If the expr is not a Call, the result is false.
-func isLowerPrecedence(op string, expr *exprpb.Expr) bool {
+func (un *unparser) isLowerPrecedence(op string, expr *exprpb.Expr) bool {
 	c := expr.GetCallExpr()
 	other := c.GetFunction()
-	return operators.Precedence(op) < operators.Precedence(other)
+	return un.precedence(op) < un.precedence(other)
 }
 
 // Indicates whether the expr is a complex operator, i.e., a call expression
@@ -481,20 +503,20 @@ func isComplexOperator(expr *exprpb.Expr) bool {
 // Indicates whether it is a complex operation compared to another.
 // expr is *not* considered complex if it is not a call expression or has
 // less than two arguments, or if it has a higher precedence than op.
-func isComplexOperatorWithRespectTo(op string, expr *exprpb.Expr) bool {
+func (un *unparser) isComplexOperatorWithRespectTo(op string, expr *exprpb.Expr) bool {
 	if expr.GetCallExpr() == nil || len(expr.GetCallExpr().GetArgs()) < 2 {
 		return false
 	}
-	return isLowerPrecedence(op, expr)
+	return un.isLowerPrecedence(op, expr)
 }
 
 // Indicate whether this is a binary or ternary operator.
-func isBinaryOrTernaryOperator(expr *exprpb.Expr) bool {
+func (un *unparser) isBinaryOrTernaryOperator(expr *exprpb.Expr) bool {
 	if expr.GetCallExpr() == nil || len(expr.GetCallExpr().GetArgs()) < 2 {
 		return false
 	}
-	_, isBinaryOp := operators.FindReverseBinaryOperator(expr.GetCallExpr().GetFunction())
-	return isBinaryOp || isSamePrecedence(operators.Conditional, expr)
+	_, isBinaryOp := un.findReverseBinaryOperator(expr.GetCallExpr().GetFunction())
+	return isBinaryOp || un.isSamePrecedence(operators.Conditional, expr)
 }
 
 // bytesToOctets converts byte sequences to a string using a three digit octal encoded value
@@ -557,6 +579,7 @@ type unparserOption struct {
 	wrapOnColumn         int
 	operatorsToWrapOn    map[string]bool
 	wrapAfterColumnLimit bool
+	infixOperators       map[string]InfixOperator
 }
 
 // WrapOnColumn wraps the output expression when its string length exceeds a specified limit
@@ -632,3 +655,22 @@ func WrapAfterColumnLimit(wrapAfter bool) UnparserOption {
 		return opt, nil
 	}
 }
+
+// UnparseInfixOperators renders calls to the functions of the custom infix operators with two
+// arguments and no target as binary operations, e.g. `matches(name, '^[a-z]+$')` as
+// `name =~ '^[a-z]+$'`, so that expressions parsed with the InfixOperators option retain their
+// operators.
+func UnparseInfixOperators(ops ...InfixOperator) UnparserOption {
+	return func(opt *unparserOption) (*unparserOption, error) {
+		if opt.infixOperators == nil {
+			opt.infixOperators = make(map[string]InfixOperator, len(ops))
+		}
+		for _, op := range ops {
+			if err := op.validate(); err != nil {
+				return nil, fmt.Errorf("Invalid unparser option. %v", err)
+			}
+			opt.infixOperators[op.Function] = op
+		}
+		return opt, nil
+	}
+}
@@ -463,6 +463,49 @@ func TestUnparse(t *testing.T) {
 	}
 }
 
+func TestUnparseInfixOperators(t *testing.T) {
+	ops := []InfixOperator{
+		{Symbol: "=~", Function: "matches", Precedence: RelationalPrecedence},
+		{Symbol: "|+|", Function: "concat", Precedence: AdditivePrecedence},
+		{Symbol: "^", Function: "pow", Precedence: MultiplicativePrecedence},
+	}
+	prsr, err := NewParser(Macros(AllMacros...), InfixOperators(ops...))
+	if err != nil {
+		t.Fatalf("NewParser() failed: %v", err)
+	}
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{in: `name =~ "^[a-z]+$"`},
+		{in: `(a |+| b) ^ c`},
+		{in: `a |+| b ^ c =~ d`},
+		{in: `a =~ (b =~ c)`},
+		{in: `matches(a, b) && a.matches(b)`, out: `a =~ b && a.matches(b)`},
+		{in: `-(a ^ b)`},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.in, func(t *testing.T) {
+			p, iss := prsr.Parse(common.NewTextSource(tc.in))
+			if len(iss.GetErrors()) > 0 {
+				t.Fatalf("parser.Parse(%s) failed: %v", tc.in, iss.ToDisplayString())
+			}
+			out, err := Unparse(p.GetExpr(), p.GetSourceInfo(), UnparseInfixOperators(ops...))
+			if err != nil {
+				t.Fatalf("Unparse(%s) failed: %v", tc.in, err)
+			}
+			want := tc.in
+			if tc.out != "" {
+				want = tc.out
+			}
+			if out != want {
+				t.Errorf("Unparse() got '%s', wanted '%s'", out, want)
+			}
+		})
+	}
+}
+
 func TestUnparseErrors(t *testing.T) {
 	validConstantExpression := &exprpb.Expr{
 		ExprKind: &exprpb.Expr_ConstExpr{